@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+)
+
+var (
+	adoptService   string
+	adoptName      string
+	adoptWorkspace string
+	adoptYes       bool
+	adoptForce     bool
+)
+
+var adoptFromComposeCmd = &cobra.Command{
+	Use:   "adopt-from-compose <compose.yml>",
+	Short: "Create an instance from a docker-compose service definition",
+	Long: `Create and start a klaus instance from a service in an existing
+docker-compose file.
+
+The following compose fields are translated:
+
+  image        -> image
+  environment  -> envVars (map or KEY=VALUE list form)
+  ports        -> port (the host port published for container port 8080)
+  volumes      -> workspace (the bind mount targeting /workspace)
+
+Any other compose field (command, networks, depends_on, build, ...) has no
+klaus equivalent; it is ignored and reported as a warning.
+
+The instance is named after the service unless --name is given. When the
+service does not publish port 8080, a free port is selected automatically.
+When it does not mount /workspace, --workspace or the current directory is
+used.
+
+Unlike create, adopt-from-compose does not resolve personalities or
+toolchains and does not isolate the workspace in a git worktree: the
+workspace directory is bind-mounted into the container directly, as it
+was under docker compose.
+
+The compose file is translated and validated before an existing instance
+with the same name is replaced.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdoptFromCompose,
+}
+
+func init() {
+	adoptFromComposeCmd.Flags().StringVar(&adoptService, "service", "", "compose service to adopt (required)")
+	adoptFromComposeCmd.Flags().StringVar(&adoptName, "name", "", "instance name (default: the service name)")
+	adoptFromComposeCmd.Flags().StringVar(&adoptWorkspace, "workspace", "", "workspace directory when the service does not mount /workspace (default: current directory)")
+	adoptFromComposeCmd.Flags().BoolVarP(&adoptYes, "yes", "y", false, "auto-confirm replacement of existing instances")
+	adoptFromComposeCmd.Flags().BoolVar(&adoptForce, "force", false, "allow replacing a running instance (prompts for confirmation unless -y is also set)")
+	_ = adoptFromComposeCmd.MarkFlagRequired("service")
+	rootCmd.AddCommand(adoptFromComposeCmd)
+}
+
+func runAdoptFromCompose(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	errOut := cmd.ErrOrStderr()
+
+	name := adoptName
+	if name == "" {
+		name = adoptService
+	}
+	if err := config.ValidateInstanceName(name); err != nil {
+		return err
+	}
+
+	adoption, err := config.AdoptComposeService(args[0], adoptService)
+	if err != nil {
+		return err
+	}
+	for _, w := range adoption.Warnings {
+		_, _ = fmt.Fprintf(errOut, "%s %s\n", yellow("Warning:"), w)
+	}
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+	instancePaths := paths.ForInstance(name)
+
+	// Everything that can fail is checked before an existing instance is
+	// replaced, so a bad compose file never costs the user their instance.
+	cfg, err := prepareAdoptedConfig(adoption, paths, instancePaths, adoptWorkspace)
+	if err != nil {
+		return err
+	}
+	data, err := cfg.Marshal()
+	if err != nil {
+		return fmt.Errorf("serializing config: %w", err)
+	}
+
+	collision, err := instance.CheckCollision(ctx, instancePaths)
+	if err != nil {
+		return fmt.Errorf("checking for existing instance: %w", err)
+	}
+	if err := handleCLICollision(cmd, name, collision, adoptForce, adoptYes, ctx, instancePaths); err != nil {
+		return err
+	}
+
+	if err := config.EnsureDir(instancePaths.InstanceDir); err != nil {
+		return fmt.Errorf("creating instance directory: %w", err)
+	}
+	if err := os.WriteFile(instancePaths.ConfigFile, data, 0o600); err != nil {
+		return fmt.Errorf("writing instance config: %w", err)
+	}
+
+	if err := startInstance(cmd, name, "", instancePaths.ConfigFile); err != nil {
+		if collision != instance.NoCollision {
+			// The previous instance is already gone; keep the adopted
+			// config so the user can fix the cause and start it.
+			return fmt.Errorf("%w\nThe adopted config was kept; run 'klausctl start %s' to retry", err, name)
+		}
+		_ = os.RemoveAll(instancePaths.InstanceDir)
+		return err
+	}
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), name)
+	return nil
+}
+
+// prepareAdoptedConfig completes the translated config with a workspace and
+// port and validates it. The port of the instance being replaced (if any) is
+// not treated as in use.
+func prepareAdoptedConfig(adoption *config.ComposeAdoption, paths, instancePaths *config.Paths, workspace string) (*config.Config, error) {
+	cfg := adoption.Config
+	if cfg.Workspace == "" {
+		cfg.Workspace = workspace
+		if cfg.Workspace == "" {
+			cwd, err := os.Getwd()
+			if err != nil {
+				return nil, fmt.Errorf("determining current directory: %w", err)
+			}
+			cfg.Workspace = cwd
+		}
+	}
+
+	if adoption.HostPort > 0 {
+		used, err := config.UsedPorts(paths)
+		if err != nil {
+			return nil, err
+		}
+		if used[adoption.HostPort] && adoption.HostPort != replacedInstancePort(instancePaths) {
+			return nil, fmt.Errorf("port %d from the compose service is already used by another instance", adoption.HostPort)
+		}
+	} else {
+		port, err := config.NextAvailablePort(paths, 8080)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Port = port
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid adopted config: %w", err)
+	}
+	return cfg, nil
+}
+
+// replacedInstancePort returns the port configured for the existing instance
+// at paths, or zero when there is none.
+func replacedInstancePort(paths *config.Paths) int {
+	existing, err := config.Load(paths.ConfigFile)
+	if err != nil {
+		return 0
+	}
+	return existing.Port
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestAdoptFromComposeRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "adopt-from-compose")
+	for _, name := range []string{"service", "name", "workspace", "yes", "force"} {
+		assertFlagRegistered(t, adoptFromComposeCmd, name)
+	}
+}
+
+func writeInstanceConfig(t *testing.T, paths *config.Paths, port int) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Workspace = t.TempDir()
+	cfg.Port = port
+	data, err := cfg.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.EnsureDir(paths.InstanceDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.ConfigFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPrepareAdoptedConfigPortInUse(t *testing.T) {
+	paths := &config.Paths{InstancesDir: t.TempDir()}
+	writeInstanceConfig(t, paths.ForInstance("other"), 9191)
+
+	adoption := &config.ComposeAdoption{Config: config.DefaultConfig(), HostPort: 9191}
+	adoption.Config.Port = 9191
+
+	_, err := prepareAdoptedConfig(adoption, paths, paths.ForInstance("agent"), t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "already used") {
+		t.Fatalf("expected port conflict error, got: %v", err)
+	}
+}
+
+func TestPrepareAdoptedConfigReplacedInstanceOwnsPort(t *testing.T) {
+	paths := &config.Paths{InstancesDir: t.TempDir()}
+	instancePaths := paths.ForInstance("agent")
+	writeInstanceConfig(t, instancePaths, 9191)
+
+	adoption := &config.ComposeAdoption{Config: config.DefaultConfig(), HostPort: 9191}
+	adoption.Config.Port = 9191
+
+	workspace := t.TempDir()
+	cfg, err := prepareAdoptedConfig(adoption, paths, instancePaths, workspace)
+	if err != nil {
+		t.Fatalf("prepareAdoptedConfig() error = %v", err)
+	}
+	if cfg.Port != 9191 {
+		t.Errorf("Port = %d, want 9191", cfg.Port)
+	}
+	if cfg.Workspace != workspace {
+		t.Errorf("Workspace = %q, want %q", cfg.Workspace, workspace)
+	}
+}
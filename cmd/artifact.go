@@ -7,16 +7,19 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 	"time"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/orchestrator"
+	"github.com/giantswarm/klausctl/pkg/signing"
 )
 
 // validOutputFormats lists the accepted values for --output flags.
@@ -32,6 +35,25 @@ func validateOutputFormat(format string) error {
 	return fmt.Errorf("unsupported output format %q: must be one of %v", format, validOutputFormats)
 }
 
+// validateNameFilter returns an error if pattern is not a valid --filter glob.
+func validateNameFilter(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid --filter pattern %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// matchesNameFilter reports whether the artifact short name matches the
+// --filter glob. An empty pattern matches everything. The pattern must have
+// been checked with validateNameFilter.
+func matchesNameFilter(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
+
 // cachedArtifact describes a locally cached OCI artifact for the list command.
 type cachedArtifact struct {
 	Name     string    `json:"name"`
@@ -53,11 +75,14 @@ func listLocalArtifacts(cacheDir string) ([]cachedArtifact, error) {
 
 	var artifacts []cachedArtifact
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if entry.Name() == orchestrator.BlobsDirName {
 			continue
 		}
-
+		// Plugin entries are symlinks into the shared blob store.
 		dir := filepath.Join(cacheDir, entry.Name())
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
 		cache, err := klausoci.ReadCacheEntry(dir)
 		if err != nil {
 			continue
@@ -94,10 +119,17 @@ type pullFn func(ctx context.Context, client *klausoci.Client, ref, destDir stri
 // pullArtifact pulls an OCI artifact by reference to a cache directory.
 // The artifact is stored at <cacheDir>/<shortName>/. The shortName is
 // extracted from the repository portion of the reference (tag/digest stripped).
-func pullArtifact(ctx context.Context, ref string, cacheDir string, pull pullFn, out io.Writer, outputFmt string) error {
+// Progress goes to errOut in JSON mode so that out only carries the result.
+func pullArtifact(ctx context.Context, ref string, cacheDir string, pull pullFn, out, errOut io.Writer, outputFmt string) error {
 	shortName := klausoci.ShortName(klausoci.RepositoryFromRef(ref))
 	destDir := filepath.Join(cacheDir, shortName)
 
+	progressOut := out
+	if outputFmt == "json" {
+		progressOut = errOut
+	}
+	_, _ = fmt.Fprintf(progressWriter(progressOut), "Pulling %s...\n", ref)
+
 	client := orchestrator.NewDefaultClient()
 	digest, cached, err := pull(ctx, client, ref, destDir)
 	if err != nil {
@@ -140,7 +172,8 @@ type listFn func(ctx context.Context, client *klausoci.Client, opts ...klausoci.
 // listLatestRemoteArtifacts discovers repositories from the registry,
 // resolves the latest semver tag for each, and checks local pull status.
 // The caller provides a typed list function (e.g. client.ListPlugins).
-func listLatestRemoteArtifacts(ctx context.Context, cacheDir, registryBase string, list listFn) ([]remoteArtifactEntry, error) {
+// Artifacts whose short name does not match the filter glob are skipped.
+func listLatestRemoteArtifacts(ctx context.Context, cacheDir, registryBase, filter string, list listFn) ([]remoteArtifactEntry, error) {
 	client := orchestrator.NewDefaultClient()
 
 	artifacts, err := list(ctx, client, klausoci.WithRegistry(registryBase))
@@ -156,6 +189,9 @@ func listLatestRemoteArtifacts(ctx context.Context, cacheDir, registryBase strin
 
 	var entries []remoteArtifactEntry
 	for _, a := range artifacts {
+		if !matchesNameFilter(filter, a.Name) {
+			continue
+		}
 		entry := remoteArtifactEntry{
 			Name: a.Name,
 			Ref:  a.Reference,
@@ -215,13 +251,27 @@ func printRemoteArtifacts(out io.Writer, entries []remoteArtifactEntry, outputFm
 // listOCIArtifacts implements the common list subcommand for OCI-cached artifact
 // types (plugins, personalities). By default it queries the remote registry for
 // the latest available version of each artifact and indicates local cache status.
-// With --local, it shows only locally cached artifacts.
-func listOCIArtifacts(ctx context.Context, out io.Writer, cacheDir, outputFmt, typeName, typePlural string, registries []config.SourceRegistry, local bool, list listFn) error {
+// With --local, it shows only locally cached artifacts. filter is a glob
+// matched against artifact short names; empty lists everything.
+func listOCIArtifacts(ctx context.Context, out io.Writer, cacheDir, outputFmt string, rowFormat *template.Template, typeName, typePlural string, registries []config.SourceRegistry, local bool, filter string, list listFn) error {
+	if err := validateNameFilter(filter); err != nil {
+		return err
+	}
+
 	if local {
-		artifacts, err := listLocalArtifacts(cacheDir)
+		all, err := listLocalArtifacts(cacheDir)
 		if err != nil {
 			return err
 		}
+		var artifacts []cachedArtifact
+		for _, a := range all {
+			if matchesNameFilter(filter, a.Name) {
+				artifacts = append(artifacts, a)
+			}
+		}
+		if rowFormat != nil {
+			return printFormattedRows(out, rowFormat, artifacts)
+		}
 		if len(artifacts) == 0 {
 			return printEmpty(out, outputFmt,
 				fmt.Sprintf("No %s cached locally.", typePlural),
@@ -231,18 +281,18 @@ func listOCIArtifacts(ctx context.Context, out io.Writer, cacheDir, outputFmt, t
 		return printLocalArtifacts(out, artifacts, outputFmt)
 	}
 
-	return listMultiSourceRemoteArtifacts(ctx, out, cacheDir, registries, outputFmt,
-		fmt.Sprintf("No %s found in the remote registry.", typePlural), list)
+	return listMultiSourceRemoteArtifacts(ctx, out, cacheDir, registries, outputFmt, rowFormat,
+		fmt.Sprintf("No %s found in the remote registry.", typePlural), filter, list)
 }
 
 // listMultiSourceRemoteArtifacts aggregates remote artifacts from multiple source registries.
 // When querying multiple sources, failures on individual sources are reported
 // as warnings rather than aborting the entire operation.
-func listMultiSourceRemoteArtifacts(ctx context.Context, out io.Writer, cacheDir string, registries []config.SourceRegistry, outputFmt, emptyMsg string, list listFn) error {
+func listMultiSourceRemoteArtifacts(ctx context.Context, out io.Writer, cacheDir string, registries []config.SourceRegistry, outputFmt string, rowFormat *template.Template, emptyMsg, filter string, list listFn) error {
 	multiSource := len(registries) > 1
 
 	allEntries, warnings, err := config.AggregateFromSources(registries, "artifacts", func(sr config.SourceRegistry) ([]remoteArtifactEntry, error) {
-		entries, err := listLatestRemoteArtifacts(ctx, cacheDir, sr.Registry, list)
+		entries, err := listLatestRemoteArtifacts(ctx, cacheDir, sr.Registry, filter, list)
 		if err != nil {
 			return nil, err
 		}
@@ -257,7 +307,7 @@ func listMultiSourceRemoteArtifacts(ctx context.Context, out io.Writer, cacheDir
 		return err
 	}
 
-	if len(allEntries) == 0 && len(warnings) == 0 {
+	if len(allEntries) == 0 && len(warnings) == 0 && rowFormat == nil {
 		return printEmpty(out, outputFmt, emptyMsg)
 	}
 
@@ -268,7 +318,11 @@ func listMultiSourceRemoteArtifacts(ctx context.Context, out io.Writer, cacheDir
 		return allEntries[i].Name < allEntries[j].Name
 	})
 
-	if len(allEntries) > 0 {
+	if rowFormat != nil {
+		if err := printFormattedRows(out, rowFormat, allEntries); err != nil {
+			return err
+		}
+	} else if len(allEntries) > 0 {
 		if err := printRemoteArtifacts(out, allEntries, outputFmt); err != nil {
 			return err
 		}
@@ -335,6 +389,7 @@ type pushResult struct {
 	Digest    string `json:"digest"`
 	DryRun    bool   `json:"dryRun,omitempty"`
 	Overwrote bool   `json:"overwrote,omitempty"`
+	Signed    bool   `json:"signed,omitempty"`
 }
 
 // pushFn is a callback that performs a typed push and returns
@@ -345,6 +400,8 @@ type pushFn func(ctx context.Context, client *klausoci.Client, sourceDir, ref st
 // pushOpts controls optional behaviour for pushArtifact.
 type pushOpts struct {
 	dryRun bool
+	// signer, when set, signs the pushed manifest digest.
+	signer signing.Signer
 }
 
 // pushArtifact pushes a local directory as an OCI artifact to a registry.
@@ -352,7 +409,9 @@ type pushOpts struct {
 // appropriate client method. Output is formatted as text or JSON.
 //
 // When opts.dryRun is true the push is skipped but validation and
-// overwrite detection still run.
+// overwrite detection still run. With opts.signer the pushed digest is
+// signed; a signing failure fails the push, though the artifact stays in
+// the registry.
 func pushArtifact(ctx context.Context, sourceDir, ref string, push pushFn, out io.Writer, outputFmt string, opts pushOpts) error {
 	shortName := klausoci.ShortName(klausoci.RepositoryFromRef(ref))
 	client := orchestrator.NewDefaultClient()
@@ -382,6 +441,14 @@ func pushArtifact(ctx context.Context, sourceDir, ref string, push pushFn, out i
 		return err
 	}
 
+	signed := false
+	if opts.signer != nil {
+		if err := opts.signer.Sign(ctx, klausoci.RepositoryFromRef(ref), digest); err != nil {
+			return fmt.Errorf("signing %s (pushed as %s): %w", ref, klausoci.TruncateDigest(digest), err)
+		}
+		signed = true
+	}
+
 	if outputFmt == "json" {
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
@@ -390,13 +457,33 @@ func pushArtifact(ctx context.Context, sourceDir, ref string, push pushFn, out i
 			Ref:       ref,
 			Digest:    digest,
 			Overwrote: overwrote,
+			Signed:    signed,
 		})
 	}
 
-	_, _ = fmt.Fprintf(out, "%s: pushed (%s)\n", shortName, klausoci.TruncateDigest(digest))
+	status := "pushed"
+	if signed {
+		status = "pushed and signed"
+	}
+	_, _ = fmt.Fprintf(out, "%s: %s (%s)\n", shortName, status, klausoci.TruncateDigest(digest))
 	return nil
 }
 
+// verifyArtifact resolves ref to its manifest digest and checks the
+// signature of that digest with v. It returns the digest reference, so that
+// exactly the verified manifest is pulled even if the tag moves meanwhile.
+func verifyArtifact(ctx context.Context, client *klausoci.Client, v signing.Verifier, ref string) (string, error) {
+	digest, err := client.Resolve(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	repo := klausoci.RepositoryFromRef(ref)
+	if err := v.Verify(ctx, repo, digest); err != nil {
+		return "", fmt.Errorf("verifying signature of %s: %w", ref, err)
+	}
+	return repo + "@" + digest, nil
+}
+
 // printMetaOpts controls formatting for printArtifactMetaWith.
 type printMetaOpts struct {
 	prefix  string
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	klausoci "github.com/giantswarm/klaus-oci"
+
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
 )
 
 func TestValidateOutputFormat(t *testing.T) {
@@ -60,6 +62,39 @@ func TestListLocalArtifacts(t *testing.T) {
 	}
 }
 
+func TestListLocalArtifactsResolvesBlobPointers(t *testing.T) {
+	dir := t.TempDir()
+
+	blob := filepath.Join(dir, orchestrator.BlobsDirName, "sha256-abc123")
+	if err := os.MkdirAll(blob, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := klausoci.WriteCacheEntry(blob, klausoci.CacheEntry{
+		Digest: "sha256:abc123",
+		Ref:    "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v0.6.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"gs-base", "gs-base-alias"} {
+		if err := os.Symlink(filepath.Join(orchestrator.BlobsDirName, "sha256-abc123"), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	artifacts, err := listLocalArtifacts(dir)
+	if err != nil {
+		t.Fatalf("listLocalArtifacts() error = %v", err)
+	}
+	if len(artifacts) != 2 {
+		t.Fatalf("expected 2 artifacts sharing one blob, got %d: %+v", len(artifacts), artifacts)
+	}
+	for _, a := range artifacts {
+		if a.Digest != "sha256:abc123" {
+			t.Errorf("%s: Digest = %q, want sha256:abc123", a.Name, a.Digest)
+		}
+	}
+}
+
 func TestListLocalArtifactsEmpty(t *testing.T) {
 	dir := t.TempDir()
 
@@ -258,6 +293,121 @@ func TestPushArtifactError(t *testing.T) {
 	}
 }
 
+// fakeSigner records the artifacts it signs and verifies.
+type fakeSigner struct {
+	signed   []string
+	verified []string
+	err      error
+}
+
+func (f *fakeSigner) Sign(_ context.Context, repository, digest string) error {
+	f.signed = append(f.signed, repository+"@"+digest)
+	return f.err
+}
+
+func (f *fakeSigner) Verify(_ context.Context, repository, digest string) error {
+	f.verified = append(f.verified, repository+"@"+digest)
+	return f.err
+}
+
+func TestPushArtifactSigns(t *testing.T) {
+	var buf bytes.Buffer
+	fakePush := func(_ context.Context, _ *klausoci.Client, _, _ string) (string, error) {
+		return "sha256:deadbeef12345678", nil
+	}
+	signer := &fakeSigner{}
+
+	err := pushArtifact(context.Background(), "/tmp/src", "example.com/plugins/gs-base:v1.0.0", fakePush, &buf, "json", pushOpts{signer: signer})
+	if err != nil {
+		t.Fatalf("pushArtifact() error = %v", err)
+	}
+	if len(signer.signed) != 1 || signer.signed[0] != "example.com/plugins/gs-base@sha256:deadbeef12345678" {
+		t.Errorf("signed = %v, want the pushed digest", signer.signed)
+	}
+	var result pushResult
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("JSON parse error: %v", err)
+	}
+	if !result.Signed {
+		t.Error("Signed = false, want true")
+	}
+
+	signer = &fakeSigner{err: fmt.Errorf("no OIDC token")}
+	err = pushArtifact(context.Background(), "/tmp/src", "example.com/plugins/gs-base:v1.0.0", fakePush, &buf, "text", pushOpts{signer: signer})
+	if err == nil || !strings.Contains(err.Error(), "no OIDC token") {
+		t.Errorf("pushArtifact() error = %v, want the signing error", err)
+	}
+}
+
+func TestVerifyArtifactReturnsDigestRef(t *testing.T) {
+	ref := "example.com/plugins/gs-base@sha256:deadbeef12345678"
+	verifier := &fakeSigner{}
+
+	got, err := verifyArtifact(context.Background(), orchestrator.NewDefaultClient(), verifier, ref)
+	if err != nil {
+		t.Fatalf("verifyArtifact() error = %v", err)
+	}
+	if got != ref || len(verifier.verified) != 1 || verifier.verified[0] != ref {
+		t.Errorf("verifyArtifact() = %q, verified %v; want %q", got, verifier.verified, ref)
+	}
+
+	verifier = &fakeSigner{err: fmt.Errorf("no matching signatures")}
+	if _, err := verifyArtifact(context.Background(), orchestrator.NewDefaultClient(), verifier, ref); err == nil {
+		t.Error("verifyArtifact() succeeded with an invalid signature")
+	}
+}
+
+func TestPullArtifactProgress(t *testing.T) {
+	fakePull := func(_ context.Context, _ *klausoci.Client, _, _ string) (string, bool, error) {
+		return "sha256:deadbeef12345678", false, nil
+	}
+	ref := "example.com/plugins/gs-base:v1.0.0"
+
+	tests := []struct {
+		name         string
+		quiet        bool
+		format       string
+		wantProgress bool
+		wantErrOut   string
+	}{
+		{name: "text", format: "text", wantProgress: true},
+		{name: "json", format: "json", wantErrOut: "Pulling " + ref + "...\n"},
+		{name: "quiet text", quiet: true, format: "text"},
+		{name: "quiet json", quiet: true, format: "json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quietFlag = tt.quiet
+			t.Cleanup(func() { quietFlag = false })
+
+			var out, errOut bytes.Buffer
+			if err := pullArtifact(context.Background(), ref, t.TempDir(), fakePull, &out, &errOut, tt.format); err != nil {
+				t.Fatalf("pullArtifact() error = %v", err)
+			}
+
+			if tt.format == "json" {
+				var result pullResult
+				if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+					t.Fatalf("expected only JSON on stdout, got %q: %v", out.String(), err)
+				}
+				if result.Ref != ref {
+					t.Errorf("Ref = %q, want %q", result.Ref, ref)
+				}
+			} else {
+				if !strings.Contains(out.String(), "gs-base: pulled") {
+					t.Errorf("expected result line, got %q", out.String())
+				}
+				if got := strings.Contains(out.String(), "Pulling"); got != tt.wantProgress {
+					t.Errorf("progress on stdout = %v, want %v: %q", got, tt.wantProgress, out.String())
+				}
+			}
+			if errOut.String() != tt.wantErrOut {
+				t.Errorf("stderr = %q, want %q", errOut.String(), tt.wantErrOut)
+			}
+		})
+	}
+}
+
 func TestPushArtifactDryRunText(t *testing.T) {
 	var buf bytes.Buffer
 	pushCalled := false
@@ -747,3 +897,47 @@ func TestFormatAge(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesNameFilter(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "", name: "gs-base", want: true},
+		{pattern: "gs-*", name: "gs-base", want: true},
+		{pattern: "gs-*", name: "gs-", want: true},
+		{pattern: "*-base", name: "gs-base", want: true},
+		{pattern: "gs-?ase", name: "gs-base", want: true},
+		{pattern: "[gh]s-base", name: "hs-base", want: true},
+		{pattern: "gs-base", name: "gs-base", want: true},
+		{pattern: "gs-*", name: "sre", want: false},
+		{pattern: "gs-*", name: "xgs-base", want: false},
+		{pattern: "gs-base", name: "gs-base2", want: false},
+		{pattern: "gs-?", name: "gs-base", want: false},
+		{pattern: "*", name: "nested/name", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.name, func(t *testing.T) {
+			if got := matchesNameFilter(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("matchesNameFilter(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNameFilter(t *testing.T) {
+	for _, pattern := range []string{"", "gs-*", "[a-z]*"} {
+		if err := validateNameFilter(pattern); err != nil {
+			t.Errorf("validateNameFilter(%q) error = %v", pattern, err)
+		}
+	}
+	err := validateNameFilter("gs-[")
+	if err == nil {
+		t.Fatal("expected error for malformed pattern")
+	}
+	if !strings.Contains(err.Error(), "invalid --filter pattern") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
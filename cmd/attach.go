@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// defaultAttachCommand opens bash, or sh in images without bash.
+var defaultAttachCommand = []string{"/bin/sh", "-c", "command -v bash >/dev/null && exec bash || exec sh"}
+
+var attachCmd = &cobra.Command{
+	Use:   "attach <name> [-- command...]",
+	Short: "Open an interactive shell in a running klaus instance",
+	Long: `Open an interactive terminal session in the container of a running klaus
+instance: a shell by default, or the given command, e.g. an interactive
+agent session in an image that ships one:
+
+  klausctl attach dev
+  klausctl attach dev -- claude
+
+The session runs in the container's working directory with the terminal in
+raw mode; window resizes are forwarded. Exiting the command ends the
+session, and a non-zero exit status makes attach fail; Ctrl-P Ctrl-Q
+detaches and leaves the command running. attach needs a terminal and fails
+when stdin is not one.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runAttach,
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+}
+
+func runAttach(cmd *cobra.Command, args []string) error {
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("attach needs an interactive terminal, but stdin is not a terminal")
+	}
+	name := args[0]
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+	if err := config.ValidateInstanceName(name); err != nil {
+		return err
+	}
+
+	inst, err := instance.Load(paths.ForInstance(name))
+	if err != nil {
+		return fmt.Errorf("instance %q is not running", name)
+	}
+	rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
+	if err != nil {
+		return err
+	}
+
+	// Ctrl-C belongs to the session: the terminal is in raw mode, so it
+	// reaches the command in the container rather than klausctl.
+	return attach(context.Background(), rt, name, inst.ContainerName(), args[1:], os.Stdin, cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// attach runs command, or defaultAttachCommand, interactively in the
+// running container of an instance. A non-zero exit status of the command
+// is returned as an error wrapping the *exec.ExitError.
+func attach(ctx context.Context, rt runtime.Runtime, name, containerName string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	status, err := rt.Status(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("checking container status: %w", err)
+	}
+	if status != "running" {
+		return fmt.Errorf("instance %q is not running (status: %s)", name, displayStatus(status))
+	}
+
+	if len(command) == 0 {
+		command = defaultAttachCommand
+	}
+	err = rt.Exec(ctx, containerName, runtime.ExecOptions{
+		Command: command,
+		TTY:     true,
+		Stdin:   stdin,
+		Stdout:  stdout,
+		Stderr:  stderr,
+	})
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("command in instance %q exited: %w", name, err)
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAttachRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "attach")
+}
+
+func TestAttach(t *testing.T) {
+	var out bytes.Buffer
+	rt := &fakeRuntime{status: "running"}
+
+	if err := attach(context.Background(), rt, "dev", "klausctl-dev", nil, strings.NewReader(""), &out, &out); err != nil {
+		t.Fatalf("attach() error = %v", err)
+	}
+	if err := attach(context.Background(), rt, "dev", "klausctl-dev", []string{"claude"}, strings.NewReader(""), &out, &out); err != nil {
+		t.Fatalf("attach() with command error = %v", err)
+	}
+
+	if len(rt.execs) != 2 {
+		t.Fatalf("exec calls = %d, want 2", len(rt.execs))
+	}
+	if !slices.Equal(rt.execs[0].Command, defaultAttachCommand) {
+		t.Errorf("default command = %v, want %v", rt.execs[0].Command, defaultAttachCommand)
+	}
+	if !slices.Equal(rt.execs[1].Command, []string{"claude"}) {
+		t.Errorf("command = %v, want [claude]", rt.execs[1].Command)
+	}
+	if rt.execs[0].Stdout != &out {
+		t.Error("expected the session output to go to the command output")
+	}
+}
+
+func TestAttachPropagatesExitStatus(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	rt := &fakeRuntime{status: "running", execErr: exitErr}
+
+	err := attach(context.Background(), rt, "dev", "klausctl-dev", []string{"false"}, nil, nil, nil)
+	var got *exec.ExitError
+	if !errors.As(err, &got) || got.ExitCode() != 3 {
+		t.Fatalf("attach() error = %v, want exit status 3", err)
+	}
+	if !strings.Contains(err.Error(), `instance "dev"`) {
+		t.Errorf("error %q does not name the instance", err)
+	}
+}
+
+func TestAttachNotRunning(t *testing.T) {
+	for _, status := range []string{"exited", "paused", ""} {
+		rt := &fakeRuntime{status: status}
+		err := attach(context.Background(), rt, "dev", "klausctl-dev", nil, nil, nil, nil)
+		if err == nil || !strings.Contains(err.Error(), "is not running") {
+			t.Errorf("status %q: error = %v, want not running", status, err)
+		}
+		if len(rt.execs) != 0 {
+			t.Errorf("status %q: exec called", status)
+		}
+	}
+}
+
+func TestAttachRequiresTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stdin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	orig := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() { os.Stdin = orig })
+
+	err = runAttach(attachCmd, []string{"dev"})
+	if err == nil || !strings.Contains(err.Error(), "not a terminal") {
+		t.Fatalf("error = %v, want a terminal error", err)
+	}
+}
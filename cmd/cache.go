@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/ocicache"
 )
 
@@ -21,6 +22,8 @@ var (
 	cacheRefreshRegistry string
 	cacheRefreshRepo     string
 	cacheRefreshFormat   string
+
+	cachePathFormat string
 )
 
 var cacheCmd = &cobra.Command{
@@ -31,7 +34,22 @@ registry lookups (catalog, tag lists, reference resolution, blob fetches).
 
 The cache lives under $XDG_CACHE_HOME/klausctl/oci (falling back to
 ~/.cache/klausctl/oci). It is safe to wipe at any time -- the next
-invocation will repopulate what it needs.`,
+invocation will repopulate what it needs.
+
+Pulled plugins and personalities are stored under the config directory by
+default. --cache-dir (or KLAUSCTL_CACHE_DIR) moves them, together with the
+registry cache, to another location, e.g. a larger disk; 'klausctl cache
+path' shows where everything is.`,
+}
+
+var cachePathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show where pulled artifacts and the registry cache are stored",
+	Long: `Print the directories holding pulled plugins and personalities and the
+OCI registry cache, honoring --cache-dir and KLAUSCTL_CACHE_DIR. Toolchains
+are container images kept in the container runtime's image store.`,
+	Args: cobra.NoArgs,
+	RunE: runCachePath,
 }
 
 var cacheInfoCmd = &cobra.Command{
@@ -79,6 +97,8 @@ func init() {
 	cacheCmd.AddCommand(cacheInfoCmd)
 	cacheCmd.AddCommand(cachePruneCmd)
 	cacheCmd.AddCommand(cacheRefreshCmd)
+	cachePathCmd.Flags().StringVar(&cachePathFormat, "output", "text", "output format: text|json")
+	cacheCmd.AddCommand(cachePathCmd)
 	rootCmd.AddCommand(cacheCmd)
 }
 
@@ -184,6 +204,44 @@ func writeRefreshResult(w io.Writer, res *ocicache.RefreshResult, format string)
 	return nil
 }
 
+// cachePaths are the directories reported by cache path.
+type cachePaths struct {
+	Plugins       string `json:"plugins"`
+	Personalities string `json:"personalities"`
+	// RegistryCache is empty when the cache is disabled.
+	RegistryCache string `json:"registryCache"`
+}
+
+func runCachePath(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(cachePathFormat); err != nil {
+		return err
+	}
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	registryCache, err := ocicache.Dir()
+	if err != nil {
+		return err
+	}
+	return writeCachePaths(cmd.OutOrStdout(), cachePaths{
+		Plugins:       paths.PluginsDir,
+		Personalities: paths.PersonalitiesDir,
+		RegistryCache: registryCache,
+	}, cachePathFormat)
+}
+
+func writeCachePaths(w io.Writer, p cachePaths, format string) error {
+	if format == "json" {
+		return writeJSON(w, p)
+	}
+	_, _ = fmt.Fprintf(w, "Plugins:         %s\n", p.Plugins)
+	_, _ = fmt.Fprintf(w, "Personalities:   %s\n", p.Personalities)
+	_, _ = fmt.Fprintf(w, "Registry cache:  %s\n", displayDir(p.RegistryCache))
+	_, _ = fmt.Fprintln(w, "Toolchains:      container runtime image store")
+	return nil
+}
+
 func writeJSON(w io.Writer, v any) error {
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
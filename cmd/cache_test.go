@@ -8,6 +8,9 @@ import (
 	"strings"
 	"testing"
 
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/ocicache"
 )
 
@@ -163,6 +166,7 @@ func TestGlobalFlags_NoCache_BypassesOptions(t *testing.T) {
 		cacheDirFlag = ""
 		noCacheFlag = false
 		ocicache.Reset()
+		config.SetCacheDir("")
 	})
 
 	if !ocicache.Disabled() {
@@ -181,6 +185,7 @@ func TestGlobalFlags_CacheDir_Applied(t *testing.T) {
 	t.Cleanup(func() {
 		cacheDirFlag = ""
 		ocicache.Reset()
+		config.SetCacheDir("")
 	})
 
 	got, err := ocicache.Dir()
@@ -191,3 +196,67 @@ func TestGlobalFlags_CacheDir_Applied(t *testing.T) {
 		t.Errorf("Dir() = %q, want %q", got, dir)
 	}
 }
+
+func TestCachePath_HonorsCacheDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	cacheDirFlag = dir
+	noCacheFlag = false
+	applyCacheFlags()
+	t.Cleanup(func() {
+		cacheDirFlag = ""
+		ocicache.Reset()
+		config.SetCacheDir("")
+	})
+
+	cachePathFormat = "json"
+	t.Cleanup(func() { cachePathFormat = "text" })
+	var out bytes.Buffer
+	cachePathCmd.SetOut(&out)
+	t.Cleanup(func() { cachePathCmd.SetOut(nil) })
+	if err := runCachePath(cachePathCmd, nil); err != nil {
+		t.Fatalf("runCachePath() error = %v", err)
+	}
+
+	var got cachePaths
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out.String(), err)
+	}
+	want := cachePaths{
+		Plugins:       filepath.Join(dir, "plugins"),
+		Personalities: filepath.Join(dir, "personalities"),
+		RegistryCache: dir,
+	}
+	if got != want {
+		t.Errorf("cache path = %+v, want %+v", got, want)
+	}
+}
+
+func TestListLocalArtifacts_CustomCacheDir(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	t.Setenv(config.EnvCacheDir, dir)
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pluginDir := filepath.Join(dir, "plugins", "gs-base")
+	if err := os.MkdirAll(pluginDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := klausoci.WriteCacheEntry(pluginDir, klausoci.CacheEntry{
+		Digest: "sha256:abc123",
+		Ref:    "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v0.6.0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	artifacts, err := listLocalArtifacts(paths.PluginsDir)
+	if err != nil {
+		t.Fatalf("listLocalArtifacts() error = %v", err)
+	}
+	if len(artifacts) != 1 || artifacts[0].Name != "gs-base" {
+		t.Errorf("artifacts = %+v, want gs-base from the custom cache dir", artifacts)
+	}
+}
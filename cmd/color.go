@@ -2,24 +2,37 @@ package cmd
 
 import (
 	"os"
+
+	"golang.org/x/term"
 )
 
 // ANSI color codes for terminal output. Colors are automatically disabled
-// when stdout is not a terminal or the NO_COLOR env var is set.
+// when stdout is not a terminal, the NO_COLOR env var is set or --no-color
+// is given.
 // See: https://no-color.org/
 
 var colorEnabled = detectColor()
 
+// applyColorFlag re-detects colorEnabled once --no-color has been parsed.
+// Registered with cobra.OnInitialize.
+func applyColorFlag() {
+	colorEnabled = detectColor()
+}
+
 func detectColor() bool {
-	// Respect the NO_COLOR standard.
-	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+	if noColorFlag {
 		return false
 	}
-	info, err := os.Stdout.Stat()
-	if err != nil {
+	// Respect the NO_COLOR standard.
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
 		return false
 	}
-	return info.Mode()&os.ModeCharDevice != 0
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd())) // #nosec G115 -- file descriptors fit in an int
 }
 
 func green(s string) string {
@@ -36,6 +49,13 @@ func yellow(s string) string {
 	return "\033[33m" + s + "\033[0m"
 }
 
+func red(s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return "\033[31m" + s + "\033[0m"
+}
+
 func bold(s string) string {
 	if !colorEnabled {
 		return s
@@ -43,6 +63,18 @@ func bold(s string) string {
 	return "\033[1m" + s + "\033[0m"
 }
 
+// instanceColors are the colors of instance name prefixes, cycled through
+// in instance order.
+var instanceColors = []string{"\033[36m", "\033[33m", "\033[32m", "\033[35m", "\033[34m"}
+
+// instanceColor colors s in the color of the i-th instance.
+func instanceColor(i int, s string) string {
+	if !colorEnabled {
+		return s
+	}
+	return instanceColors[i%len(instanceColors)] + s + "\033[0m"
+}
+
 func colorStatus(status string) string {
 	switch status {
 	case "started", "completed", "idle": //nolint:goconst
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNoColorFlag(t *testing.T) {
+	if rootCmd.PersistentFlags().Lookup("no-color") == nil {
+		t.Fatal("expected global --no-color flag to be registered")
+	}
+}
+
+// redirectStdout points os.Stdout at a regular file for the test, as when
+// output is piped or captured.
+func redirectStdout(t *testing.T) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = f
+	t.Cleanup(func() {
+		os.Stdout = orig
+		_ = f.Close()
+		colorEnabled = detectColor()
+	})
+}
+
+func TestDetectColor(t *testing.T) {
+	t.Run("not a terminal", func(t *testing.T) {
+		redirectStdout(t)
+		if detectColor() {
+			t.Error("expected no color when stdout is not a terminal")
+		}
+	})
+	t.Run("NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		if detectColor() {
+			t.Error("expected no color with NO_COLOR set")
+		}
+	})
+	t.Run("--no-color", func(t *testing.T) {
+		noColorFlag = true
+		t.Cleanup(func() { noColorFlag = false })
+		if detectColor() {
+			t.Error("expected no color with --no-color")
+		}
+	})
+}
+
+func TestColorSuppressedInBuffer(t *testing.T) {
+	redirectStdout(t)
+	colorEnabled = true
+	applyColorFlag()
+
+	var buf bytes.Buffer
+	_, _ = fmt.Fprintf(&buf, "%s %s %s %s %s\n",
+		bold("name"), green("ok"), yellow("busy"), red("failed"), colorStatus("completed"))
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("output contains ANSI escapes: %q", buf.String())
+	}
+	if buf.String() != "name ok busy failed completed\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/giantswarm/klausctl/pkg/config"
 )
 
 var completionCmd = &cobra.Command{
@@ -68,3 +71,39 @@ PowerShell:
 func init() {
 	rootCmd.AddCommand(completionCmd)
 }
+
+// flagValues lists the accepted values of enum flags by flag name. Every
+// command with such a flag completes its values, unless the command
+// registers its own completion for it.
+var flagValues = map[string][]string{
+	"output":          validOutputFormats,
+	"permission-mode": config.ValidPermissionModes,
+	"effort":          config.ValidEffortLevels,
+}
+
+// completeValues returns a completion function offering values.
+func completeValues(values ...string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)
+}
+
+// registerFlagValueCompletions registers completion of the flagValues on
+// cmd and its subcommands. It runs once the command tree is complete, as
+// the flags are defined in the init functions of many files.
+func registerFlagValueCompletions(cmd *cobra.Command) {
+	register := func(f *pflag.Flag) {
+		values, ok := flagValues[f.Name]
+		if !ok {
+			return
+		}
+		if _, exists := cmd.GetFlagCompletionFunc(f.Name); exists {
+			return
+		}
+		_ = cmd.RegisterFlagCompletionFunc(f.Name, completeValues(values...))
+	}
+	// Not LocalFlags, which would merge the inherited flags into cmd's.
+	cmd.Flags().VisitAll(register)
+	cmd.PersistentFlags().VisitAll(register)
+	for _, sub := range cmd.Commands() {
+		registerFlagValueCompletions(sub)
+	}
+}
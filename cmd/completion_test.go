@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestFlagValueCompletions(t *testing.T) {
+	registerFlagValueCompletions(rootCmd)
+
+	tests := []struct {
+		cmd  *cobra.Command
+		flag string
+		want []string
+	}{
+		{listCmd, "output", validOutputFormats},
+		{statsCmd, "output", validOutputFormats},
+		{createCmd, "permission-mode", config.ValidPermissionModes},
+		{runCmd, "permission-mode", config.ValidPermissionModes},
+		{sourceExportCmd, "output", []string{"yaml", "json"}},
+		{personalityDescribeCmd, "output", []string{"text", "json", "dot"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cmd.Name()+"/"+tt.flag, func(t *testing.T) {
+			complete, ok := tt.cmd.GetFlagCompletionFunc(tt.flag)
+			if !ok {
+				t.Fatalf("no completion registered for --%s", tt.flag)
+			}
+			got, directive := complete(tt.cmd, nil, "")
+			values := make([]string, len(got))
+			for i, c := range got {
+				values[i] = string(c)
+			}
+			if !slices.Equal(values, tt.want) {
+				t.Errorf("completions = %v, want %v", values, tt.want)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("directive = %v, want NoFileComp", directive)
+			}
+		})
+	}
+}
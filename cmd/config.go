@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 
 	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
 )
 
 var configCmd = &cobra.Command{
@@ -44,17 +47,99 @@ var configPathCmd = &cobra.Command{
 var configValidateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate configuration file syntax",
-	Long:  `Parse and validate the configuration file, reporting any errors.`,
-	RunE:  runConfigValidate,
+	Long: `Parse and validate the configuration file, reporting any errors.
+
+The secrets referenced by secretEnvVars, secretFiles and the managed MCP
+servers of mcpServerRefs are checked against the secret store, so that a
+misspelled secret name is reported here instead of failing the next start.`,
+	RunE: runConfigValidate,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the configuration file",
+	Long: `Print a JSON Schema describing the configuration file: its fields, their
+types, and the allowed values of fields such as claude.permissionMode and
+claude.effort.
+
+Save the schema and point your editor's YAML language server at it to get
+completion and validation while editing the config file, for example with a
+modeline at the top of the file:
+
+  klausctl config schema > ~/.config/klausctl/config.schema.json
+
+  # yaml-language-server: $schema=/home/me/.config/klausctl/config.schema.json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSchema,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long: `Set a value in the configuration file. Keys are dotted paths of config file
+fields; the value is converted to the type of the field and the resulting
+config is validated before it is saved. Comments in the file are kept.
+
+  klausctl config set claude.model opus
+  klausctl config set port 8090
+  klausctl config set envVars.GOFLAGS -mod=mod
+
+Lists and objects such as plugins or mcpServers are edited in the file.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigSet,
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade the configuration file to the current schema version",
+	Long: `Rewrite the configuration file in the current schema version, moving
+and renaming keys that older versions used. The version is recorded in the
+version key of the file; files without it are version 0.
+
+The original file is kept next to it with a .bak suffix. Comments are kept.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long: `Print a value of the resolved configuration, with defaults applied. Keys
+are dotted paths as in config set; objects and lists are printed as YAML.
+
+  klausctl config get claude.model`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
+}
+
+var configDiffDefaultsOutput string
+
+var configDiffDefaultsCmd = &cobra.Command{
+	Use:   "diff-defaults",
+	Short: "Show the configuration fields that differ from the defaults",
+	Long: `Compare the resolved configuration with the default configuration and print
+only the fields it changes, with their default value. Useful when reviewing a
+committed config to focus on its intentional deviations.
+
+  klausctl config diff-defaults
+  klausctl config diff-defaults -o json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigDiffDefaults,
 }
 
 func init() {
 	configShowCmd.Flags().BoolVar(&configShowEffective, "effective", false, "show resolved config with defaults applied")
+	configDiffDefaultsCmd.Flags().StringVarP(&configDiffDefaultsOutput, "output", "o", "text", "output format: text, json")
 
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configPathCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configMigrateCmd)
+	configCmd.AddCommand(configDiffDefaultsCmd)
 	rootCmd.AddCommand(configCmd)
 }
 
@@ -144,19 +229,34 @@ func runConfigValidate(cmd *cobra.Command, _ []string) error {
 
 	out := cmd.OutOrStdout()
 
-	_, err = config.Load(path)
+	cfg, err := config.Load(path)
 	if err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
 
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := orchestrator.CheckSecrets(cfg, paths); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
 	_, _ = fmt.Fprintf(out, "Config file is valid: %s\n", path)
 	return nil
 }
 
+func runConfigSchema(cmd *cobra.Command, _ []string) error {
+	return writeJSON(cmd.OutOrStdout(), config.Schema())
+}
+
 func defaultConfigTemplate() string {
 	return `# klausctl configuration
 # See: https://github.com/giantswarm/klausctl
 
+# Schema version of this file; upgrade older files with 'klausctl config migrate'
+version: 1
+
 # Container runtime (auto-detected if not set)
 # runtime: docker  # or: podman
 
@@ -173,21 +273,81 @@ func defaultConfigTemplate() string {
 # Workspace directory to mount into the container
 workspace: ~/projects
 
+# Workspace used by 'klausctl create' when none is given (default: current directory);
+# "auto" uses the root of the git repository containing the current directory
+# defaultWorkspace: ~/projects
+
 # Host port for the MCP endpoint
 port: 8080
 
+# Port the agent listens on inside the container (default 8080); only needed
+# for custom images that listen elsewhere
+# containerPort: 9000
+
+# Container network: host, bridge, none, or the name of an existing network
+# (e.g. to reach a sidecar). With host, port mapping is ignored and the agent
+# listens on the port above directly.
+# network: my-sidecar-net
+
+# DNS servers (--dns) and /etc/hosts entries (--add-host, hostname:ip) for the
+# container, e.g. to reach internal services by name. Use host-gateway as the
+# IP for the host's address.
+# dns: [10.0.0.53]
+# extraHosts: ["git.corp.example:10.0.0.7"]
+
+# Seconds the agent gets to shut down on stop before it is killed with SIGKILL
+# (default 10). Override per stop with 'klausctl stop --timeout'.
+# stopTimeout: 30
+
+# Container restart policy: no (default), on-failure, unless-stopped, always.
+# Keeps long-lived agents running across crashes and host reboots; stop and
+# delete still remove the container.
+# restartPolicy: unless-stopped
+
+# User the container runs as: your uid:gid by default, so files written to
+# the workspace belong to you. "root" runs as 0:0, "image" keeps the user
+# the image declares, anything else (e.g. "1000:1000") is passed as --user.
+# runAsUser: image
+
+# Container hardening. readOnlyRootfs mounts the root filesystem read-only
+# (--read-only) and adds a tmpfs at /tmp; capDrop and capAdd are passed as
+# --cap-drop and --cap-add.
+# securityOpts:
+#   readOnlyRootfs: true
+#   capDrop: [ALL]
+#   capAdd: [NET_BIND_SERVICE]
+
+# Docker daemon to run the container on, e.g. ssh://user@build-box or
+# tcp://10.0.0.5:2376 (default: DOCKER_HOST, else the local daemon). A
+# remote host must share the klausctl config directory and the workspace
+# at the same paths (runtimeHostSharedPaths), and the MCP port must be
+# published on a reachable interface (publishAddress).
+# runtimeHost: ssh://user@build-box
+# runtimeHostSharedPaths: true
+# publishAddress: 0.0.0.0
+
 # Claude Code agent configuration
 claude:
   # model: sonnet
   # systemPrompt: "You are a helpful coding assistant."
   # maxBudgetUsd: 5.0
   permissionMode: bypassPermissions
+  # Inline Claude settings.json (merged with hooks; inline keys win)
+  # settings:
+  #   includeCoAuthoredBy: false
 
 # Forward host environment variables to the container
-# (ANTHROPIC_API_KEY is always forwarded if set)
+# (ANTHROPIC_API_KEY is also forwarded if set)
 # envForward:
 #   - GITHUB_TOKEN
 
+# Don't forward the host ANTHROPIC_API_KEY, e.g. when it comes from
+# secretEnvVars or a proxy
+# forwardAnthropicKey: false
+
+# Mount the host ssh-agent socket ($SSH_AUTH_SOCK) for git over SSH
+# forwardSSHAgent: true
+
 # Inline skills
 # skills:
 #   api-conventions:
@@ -221,5 +381,164 @@ claude:
 # plugins:
 #   - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/gs-platform
 #     tag: v1.2.0
+
+# Pull plugins and the image one after the other instead of concurrently
+# preStartPull:
+#   sequential: true
 `
 }
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("config file not found: %s\nRun 'klausctl config init' to create one", path)
+		}
+		return fmt.Errorf("reading config: %w", err)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- user-supplied or trusted local path; not exposed to untrusted input
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	updated, err := config.SetValue(data, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Set %s in %s\n", args[0], path)
+	return nil
+}
+
+func runConfigMigrate(cmd *cobra.Command, _ []string) error {
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+	return migrateConfigFile(cmd.OutOrStdout(), path)
+}
+
+// migrateConfigFile upgrades the config file at path to the current schema
+// version, backing up the original to path.bak.
+func migrateConfigFile(out io.Writer, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("config file not found: %s\nRun 'klausctl config init' to create one", path)
+		}
+		return fmt.Errorf("reading config: %w", err)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- user-supplied or trusted local path; not exposed to untrusted input
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	migrated, from, err := config.MigrateConfig(data)
+	if err != nil {
+		return err
+	}
+	if from == config.CurrentConfigVersion {
+		_, _ = fmt.Fprintf(out, "Config file is already at version %d: %s\n", from, path)
+		return nil
+	}
+	if _, err := config.Parse(migrated); err != nil {
+		return fmt.Errorf("migrated config is invalid: %w", err)
+	}
+
+	backup := path + ".bak"
+	if err := os.WriteFile(backup, data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	if err := os.WriteFile(path, migrated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(out, "Migrated %s from version %d to %d (backup: %s)\n", path, from, config.CurrentConfigVersion, backup)
+	return nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	value, err := config.GetValue(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	return printConfigValue(cmd.OutOrStdout(), value)
+}
+
+// printConfigValue prints scalars as plain text and other values as YAML.
+func printConfigValue(out io.Writer, value any) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string, bool, int, int64, uint64, float64:
+		_, _ = fmt.Fprintln(out, v)
+		return nil
+	}
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshaling value: %w", err)
+	}
+	_, _ = fmt.Fprint(out, string(data))
+	return nil
+}
+
+// defaultsChange is a config field that differs from its default.
+type defaultsChange struct {
+	Key     string `json:"key"`
+	Value   any    `json:"value"`
+	Default any    `json:"default"`
+}
+
+func runConfigDiffDefaults(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(configDiffDefaultsOutput); err != nil {
+		return err
+	}
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	changes, err := config.DiffDefaults(cfg)
+	if err != nil {
+		return err
+	}
+	return renderDefaultsDiff(cmd.OutOrStdout(), configDiffDefaultsOutput, changes)
+}
+
+func renderDefaultsDiff(out io.Writer, outputFmt string, changes []config.ConfigChange) error {
+	fields := make([]defaultsChange, 0, len(changes))
+	for _, c := range changes {
+		fields = append(fields, defaultsChange{Key: c.Key, Value: c.To, Default: c.From})
+	}
+	if outputFmt == "json" {
+		return writeJSON(out, fields)
+	}
+
+	if len(fields) == 0 {
+		_, _ = fmt.Fprintln(out, "The configuration matches the defaults.")
+		return nil
+	}
+	for _, f := range fields {
+		_, _ = fmt.Fprintf(out, "%s: %s (default: %s)\n", bold(f.Key), formatDiffValue(f.Value), formatDiffValue(f.Default))
+	}
+	return nil
+}
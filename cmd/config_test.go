@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestConfigSchemaRegistered(t *testing.T) {
+	assertSubcommandsRegistered(t, configCmd, []string{"schema", "set", "get", "migrate", "diff-defaults"})
+	assertFlagRegistered(t, configDiffDefaultsCmd, "output")
+}
+
+func TestConfigSchemaOutput(t *testing.T) {
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	if err := runConfigSchema(cmd, nil); err != nil {
+		t.Fatalf("runConfigSchema() error = %v", err)
+	}
+
+	var schema struct {
+		Schema     string                     `json:"$schema"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &schema); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, out.String())
+	}
+	if schema.Schema == "" {
+		t.Error("expected $schema to be set")
+	}
+	for _, key := range []string{"workspace", "port", "claude", "plugins"} {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("expected property %q in schema", key)
+		}
+	}
+}
+
+func TestPrintConfigValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"string", "opus", "opus\n"},
+		{"int", 8080, "8080\n"},
+		{"nil", nil, ""},
+		{"map", map[string]string{"FOO": "bar"}, "FOO: bar\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := printConfigValue(&out, tt.value); err != nil {
+				t.Fatalf("printConfigValue() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("output = %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "workspace: /tmp/project\nmodel: opus\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := migrateConfigFile(&out, path); err != nil {
+		t.Fatalf("migrateConfigFile() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "from version 0") {
+		t.Errorf("output = %q, want it to report the old version", out.String())
+	}
+
+	backup, err := os.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatalf("reading backup: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("backup = %q, want the original %q", backup, original)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("loading migrated config: %v", err)
+	}
+	if cfg.Claude.Model != "opus" || cfg.Version != config.CurrentConfigVersion {
+		t.Errorf("migrated config: model %q, version %d", cfg.Claude.Model, cfg.Version)
+	}
+
+	out.Reset()
+	if err := migrateConfigFile(&out, path); err != nil {
+		t.Fatalf("second migrateConfigFile() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "already at version") {
+		t.Errorf("output = %q, want it to report the config is current", out.String())
+	}
+}
+
+func TestRenderDefaultsDiff(t *testing.T) {
+	changes := []config.ConfigChange{
+		{Key: "claude.model", To: "opus"},
+		{Key: "port", From: 8080, To: 8090},
+	}
+
+	var out bytes.Buffer
+	if err := renderDefaultsDiff(&out, "text", changes); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"claude.model: opus (default: (unset))", "port: 8090 (default: 8080)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := renderDefaultsDiff(&out, "json", changes); err != nil {
+		t.Fatal(err)
+	}
+	var got []defaultsChange
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got) != 2 || got[0].Key != "claude.model" || got[0].Default != nil || got[1].Value != float64(8090) {
+		t.Errorf("json = %+v", got)
+	}
+	// An unset default is printed as null rather than left out.
+	if !strings.Contains(out.String(), `"default": null`) {
+		t.Errorf("json = %s, want an explicit null default", out.String())
+	}
+
+	out.Reset()
+	if err := renderDefaultsDiff(&out, "json", nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("empty json = %q, want []", out.String())
+	}
+}
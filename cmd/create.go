@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -19,8 +20,11 @@ var (
 	createToolchain         string
 	createPlugins           []string
 	createPort              int
+	createNetwork           string
+	createPlatform          string
 	createEnv               []string
 	createEnvForward        []string
+	createEnvFile           string
 	createSecretEnv         []string
 	createSecretFile        []string
 	createMcpServer         []string
@@ -38,6 +42,7 @@ var (
 	createGPGSign           bool
 	createYes               bool
 	createForce             bool
+	createReplace           bool
 	createGenerateSuffix    bool
 )
 
@@ -46,6 +51,12 @@ var createCmd = &cobra.Command{
 	Short: "Create and start a named klaus instance",
 	Long: `Create and start a named klaus instance.
 
+When no workspace is given, the defaultWorkspace from the klausctl config file
+(see 'klausctl config path') is used, falling back to the current directory.
+The workspace "auto", given or configured, mounts the root of the git
+repository containing the current directory, or the current directory outside
+a repository.
+
 Override flags (--env, --env-forward, --permission-mode, --model, etc.) are
 applied on top of any values defined by the resolved personality. Map-like
 fields (envVars, envForward) are merged; scalar fields (model, permissionMode,
@@ -59,6 +70,12 @@ If a name collision occurs with a stopped instance, you will be prompted to
 replace it (use -y to auto-confirm). If the collision is with a running
 instance, the command aborts unless --force is used.
 
+--replace recreates an existing instance of the same name in one step,
+without prompting: its container is removed and its directory (config,
+state and workspace clone) is moved aside until the new instance has
+started. If creating the new instance fails, the previous instance is
+restored in a stopped state. --replace implies --no-generate-suffix.
+
 MCP server configurations can be supplied via the MCP tool interface
 (mcpServers parameter) or by editing the instance config file directly.`,
 	Args: cobra.RangeArgs(1, 2),
@@ -70,7 +87,10 @@ func init() {
 	createCmd.Flags().StringVar(&createToolchain, "toolchain", "", "toolchain short name or OCI reference")
 	createCmd.Flags().StringSliceVar(&createPlugins, "plugin", nil, "additional plugin short name or OCI reference (repeatable)")
 	createCmd.Flags().IntVar(&createPort, "port", 0, "override auto-selected port")
+	createCmd.Flags().StringVar(&createNetwork, "network", "", `container network: "host", "bridge", "none", or a network name (host ignores port mapping)`)
+	createCmd.Flags().StringVar(&createPlatform, "platform", "", "image platform as os/arch, e.g. linux/amd64 (default: host platform)")
 	createCmd.Flags().StringArrayVar(&createEnv, "env", nil, "environment variable KEY=VALUE (repeatable)")
+	createCmd.Flags().StringVar(&createEnvFile, "env-file", "", "dotenv file with KEY=VALUE lines; --env values take precedence")
 	createCmd.Flags().StringArrayVar(&createEnvForward, "env-forward", nil, "host environment variable name to forward (repeatable)")
 	createCmd.Flags().StringVar(&createPermMode, "permission-mode", "", "Claude permission mode: default, acceptEdits, bypassPermissions, dontAsk, plan, delegate")
 	createCmd.Flags().StringVar(&createModel, "model", "", "Claude model (e.g. sonnet, opus)")
@@ -89,6 +109,7 @@ func init() {
 	createCmd.Flags().BoolVar(&createGPGSign, "gpg-sign", false, "sign agent commits with the host GPG key via a forwarded gpg-agent socket")
 	createCmd.Flags().BoolVarP(&createYes, "yes", "y", false, "auto-confirm replacement of existing instances")
 	createCmd.Flags().BoolVar(&createForce, "force", false, "allow replacing a running instance (prompts for confirmation unless -y is also set)")
+	createCmd.Flags().BoolVar(&createReplace, "replace", false, "recreate an existing instance of the same name, restoring it if the new one fails to start (implies --no-generate-suffix)")
 	createCmd.Flags().BoolVar(&createGenerateSuffix, "generate-suffix", true, "append a random 4-character suffix to the instance name (use --no-generate-suffix to disable)")
 	rootCmd.AddCommand(createCmd)
 }
@@ -106,8 +127,11 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		Toolchain:       createToolchain,
 		Plugins:         createPlugins,
 		Port:            createPort,
+		Network:         createNetwork,
+		Platform:        createPlatform,
 		Env:             createEnv,
 		EnvForward:      createEnvForward,
+		EnvFile:         createEnvFile,
 		SecretEnv:       createSecretEnv,
 		SecretFile:      createSecretFile,
 		McpServer:       createMcpServer,
@@ -126,7 +150,8 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		GPGSign:         createGPGSign,
 		Yes:             createYes,
 		Force:           createForce,
-		GenerateSuffix:  createGenerateSuffix,
+		Replace:         createReplace,
+		GenerateSuffix:  createGenerateSuffix && !createReplace,
 	}
 
 	instanceName, err := cliCreateInstance(context.Background(), cmd, params)
@@ -207,6 +232,32 @@ func cleanupExistingInstance(ctx context.Context, name string, paths *config.Pat
 	return nil
 }
 
+// setAsideExistingInstance removes the container of an existing instance and
+// moves its directory aside, so the instance can be restored if creating its
+// replacement fails.
+func setAsideExistingInstance(ctx context.Context, name string, paths *config.Paths) (*instance.Replacement, error) {
+	inst, _ := instance.Load(paths)
+	if err := cleanupInstanceContainer(ctx, name, inst); err != nil {
+		return nil, fmt.Errorf("removing container of existing instance: %w", err)
+	}
+	return instance.SetAside(paths)
+}
+
+// finishReplacement discards the previous instance when createErr is nil and
+// restores it otherwise. It returns the error to report for the create.
+func finishReplacement(cmd *cobra.Command, name string, r *instance.Replacement, createErr error) error {
+	if createErr == nil {
+		if err := r.Discard(); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		}
+		return nil
+	}
+	if err := r.Restore(); err != nil {
+		return fmt.Errorf("%w (%v)", createErr, err)
+	}
+	return fmt.Errorf("%w; the previous instance %q was restored in a stopped state, run 'klausctl start %s' to start it", createErr, name, name)
+}
+
 // parseGitAuthor parses a "Name <email>" string into separate name and email.
 // Returns empty strings if the input is empty.
 func parseGitAuthor(s string) (name, email string, err error) {
@@ -241,3 +292,20 @@ func parseEnvFlags(envFlags []string) (map[string]string, error) {
 	}
 	return m, nil
 }
+
+// resolveEnvFile makes an --env-file path absolute, so that it keeps working
+// when the instance is started from another directory, and checks that the
+// file parses.
+func resolveEnvFile(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	abs, err := filepath.Abs(config.ExpandPath(path))
+	if err != nil {
+		return "", fmt.Errorf("resolving --env-file: %w", err)
+	}
+	if _, err := config.LoadEnvFile(abs); err != nil {
+		return "", err
+	}
+	return abs, nil
+}
@@ -24,8 +24,11 @@ type CLICreateParams struct {
 	Toolchain       string
 	Plugins         []string
 	Port            int
+	Network         string
+	Platform        string
 	Env             []string
 	EnvForward      []string
+	EnvFile         string
 	SecretEnv       []string
 	SecretFile      []string
 	McpServer       []string
@@ -44,6 +47,7 @@ type CLICreateParams struct {
 	GPGSign         bool
 	Yes             bool
 	Force           bool
+	Replace         bool
 	GenerateSuffix  bool
 }
 
@@ -70,15 +74,6 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		return "", err
 	}
 
-	workspace := params.Workspace
-	if workspace == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return "", fmt.Errorf("determining current directory: %w", err)
-		}
-		workspace = cwd
-	}
-
 	if params.Port < 0 || params.Port > 65535 {
 		return "", fmt.Errorf("port must be between 0 and 65535, got %d", params.Port)
 	}
@@ -91,6 +86,11 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		return "", fmt.Errorf("migrating config layout: %w", err)
 	}
 
+	workspace, err := config.ResolveCreateWorkspace(paths, params.Workspace)
+	if err != nil {
+		return "", err
+	}
+
 	instancePaths := paths.ForInstance(instanceName)
 
 	// Check for name collision with an existing instance.
@@ -99,7 +99,17 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		return "", fmt.Errorf("checking for existing instance: %w", err)
 	}
 
-	if err := handleCLICollision(cmd, instanceName, collision, params.Force, params.Yes, ctx, instancePaths); err != nil {
+	if params.Replace && collision != instance.NoCollision {
+		replacement, err := setAsideExistingInstance(ctx, instanceName, instancePaths)
+		if err != nil {
+			return "", err
+		}
+		// Runs after createAndStartInstance has cleaned up the instance
+		// dir, so it can move the previous instance back into place.
+		defer func() {
+			retErr = finishReplacement(cmd, instanceName, replacement, retErr)
+		}()
+	} else if err := handleCLICollision(cmd, instanceName, collision, params.Force, params.Yes, ctx, instancePaths); err != nil {
 		return "", err
 	}
 
@@ -122,6 +132,11 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		return "", err
 	}
 
+	envFile, err := resolveEnvFile(params.EnvFile)
+	if err != nil {
+		return "", err
+	}
+
 	secretEnvVars, err := parseEnvFlags(params.SecretEnv)
 	if err != nil {
 		return "", fmt.Errorf("parsing --secret-env: %w", err)
@@ -147,6 +162,8 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		Toolchain:            toolchain,
 		Plugins:              plugins,
 		Port:                 params.Port,
+		Network:              params.Network,
+		Platform:             params.Platform,
 		GitAuthorName:        gitName,
 		GitAuthorEmail:       gitEmail,
 		GitCredentialHelper:  params.GitCredHelper,
@@ -154,6 +171,7 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		GitSignCommits:       params.GPGSign,
 		EnvVars:              envVars,
 		EnvForward:           params.EnvForward,
+		EnvFile:              envFile,
 		SecretEnvVars:        secretEnvVars,
 		SecretFiles:          secretFiles,
 		McpServerRefs:        params.McpServer,
@@ -163,43 +181,34 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 		SourceResolver:       resolver,
 		Context:              ctx,
 		Output:               cmd.OutOrStdout(),
-		ResolvePersonality: func(ctx context.Context, ref string, outWriter io.Writer) (*config.ResolvedPersonality, error) {
-			if err := config.EnsureDir(paths.PersonalitiesDir); err != nil {
-				return nil, fmt.Errorf("creating personalities directory: %w", err)
-			}
-			client := orchestrator.NewDefaultClient()
-			pr, err := orchestrator.ResolvePersonality(ctx, client, ref, paths.PersonalitiesDir, outWriter)
-			if err != nil {
-				return nil, err
-			}
-
-			plugins, err := orchestrator.ResolvePluginRefs(ctx, client, pr.Spec.Plugins)
-			if err != nil {
-				return nil, fmt.Errorf("resolving personality plugins: %w", err)
-			}
-
-			image, err := client.ResolveToolchainRef(ctx, pr.Spec.Toolchain.Ref())
-			if err != nil {
-				return nil, fmt.Errorf("resolving personality image: %w", err)
-			}
-
-			return &config.ResolvedPersonality{
-				Plugins: plugins,
-				Image:   image,
-			}, nil
-		},
 	}
 	if params.MaxBudgetSet {
 		opts.MaxBudgetUSD = &params.MaxBudget
 	}
 
+	if err := createAndStartInstance(cmd, paths, opts); err != nil {
+		return "", err
+	}
+	return instanceName, nil
+}
+
+// createAndStartInstance generates the config of a new instance from opts,
+// writes it to the instance directory and starts the instance. Personality
+// references are resolved unless opts.ResolvePersonality is set. On failure
+// the instance directory and workspace clone are removed again.
+func createAndStartInstance(cmd *cobra.Command, paths *config.Paths, opts config.CreateOptions) (retErr error) {
+	instancePaths := paths.ForInstance(opts.Name)
+	if opts.ResolvePersonality == nil {
+		opts.ResolvePersonality = personalityResolver(paths)
+	}
+
 	cfg, err := config.GenerateInstanceConfig(paths, opts)
 	if err != nil {
-		return "", err
+		return err
 	}
 
 	if err := config.EnsureDir(instancePaths.InstanceDir); err != nil {
-		return "", fmt.Errorf("creating instance directory: %w", err)
+		return fmt.Errorf("creating instance directory: %w", err)
 	}
 
 	// Clean up the instance directory if any subsequent step fails.
@@ -221,19 +230,50 @@ func cliCreateInstance(ctx context.Context, cmd *cobra.Command, params CLICreate
 
 	data, err := cfg.Marshal()
 	if err != nil {
-		return "", fmt.Errorf("serializing config: %w", err)
+		return fmt.Errorf("serializing config: %w", err)
 	}
 	if err := os.WriteFile(instancePaths.ConfigFile, data, 0o600); err != nil {
-		return "", fmt.Errorf("writing instance config: %w", err)
+		return fmt.Errorf("writing instance config: %w", err)
 	}
 
 	if err := config.EnsureDir(filepath.Dir(instancePaths.RenderedDir)); err != nil {
-		return "", fmt.Errorf("creating rendered directory parent: %w", err)
+		return fmt.Errorf("creating rendered directory parent: %w", err)
 	}
 
-	if err := startInstance(cmd, instanceName, "", instancePaths.ConfigFile); err != nil {
-		return "", err
-	}
+	return startInstance(cmd, opts.Name, "", instancePaths.ConfigFile)
+}
 
-	return instanceName, nil
+// personalityResolver returns the CreateOptions.ResolvePersonality callback
+// that pulls a personality into the personalities directory of paths and
+// resolves its plugins and toolchain image.
+func personalityResolver(paths *config.Paths) func(context.Context, string, io.Writer) (*config.ResolvedPersonality, error) {
+	return func(ctx context.Context, ref string, outWriter io.Writer) (*config.ResolvedPersonality, error) {
+		if err := config.EnsureDir(paths.PersonalitiesDir); err != nil {
+			return nil, fmt.Errorf("creating personalities directory: %w", err)
+		}
+		mirrors, err := buildMirrorResolver()
+		if err != nil {
+			return nil, err
+		}
+		client := orchestrator.NewDefaultClient()
+		pr, err := orchestrator.ResolvePersonality(ctx, client, mirrors, ref, paths.PersonalitiesDir, outWriter)
+		if err != nil {
+			return nil, err
+		}
+
+		plugins, err := orchestrator.ResolvePluginRefs(ctx, client, pr.Spec.Plugins)
+		if err != nil {
+			return nil, fmt.Errorf("resolving personality plugins: %w", err)
+		}
+
+		image, err := client.ResolveToolchainRef(ctx, pr.Spec.Toolchain.Ref())
+		if err != nil {
+			return nil, fmt.Errorf("resolving personality image: %w", err)
+		}
+
+		return &config.ResolvedPersonality{
+			Plugins: plugins,
+			Image:   image,
+		}, nil
+	}
 }
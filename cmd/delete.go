@@ -139,7 +139,12 @@ func cleanupInstanceContainer(ctx context.Context, instanceName string, inst *in
 	}
 
 	for _, rtName := range runtimeCandidates {
-		rt, err := runtime.New(rtName)
+		// The instance's own runtime may run on a remote host.
+		host := ""
+		if inst != nil && rtName == inst.Runtime {
+			host = inst.RuntimeHost
+		}
+		rt, err := runtime.NewWithHost(rtName, host)
 		if err != nil {
 			continue
 		}
@@ -158,7 +163,7 @@ func stopAndRemoveContainerIfExists(ctx context.Context, rt runtime.Runtime, con
 	}
 
 	if status == "running" { //nolint:goconst
-		if err := rt.Stop(ctx, containerName); err != nil {
+		if err := rt.Stop(ctx, containerName, runtime.DefaultStopTimeout); err != nil {
 			return fmt.Errorf("stopping container: %w", err)
 		}
 	}
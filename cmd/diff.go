@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+var (
+	diffAgainst string
+	diffAll     bool
+	diffOutput  string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <name>",
+	Short: "Compare an instance's config with the current config",
+	Long: `Compare the config an instance was created with against the current config
+file (--config, or the default config), field by field. Use --against to
+compare with another instance's config instead.
+
+An instance keeps running with the config it was started from, so diff shows
+whether it is stale and needs its config updated and a 'klausctl restart'.
+Fields set per instance (workspace, worktreePath, port) are ignored unless
+--all is given.
+
+  klausctl diff dev
+  klausctl diff dev --against staging -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffAgainst, "against", "", "compare with the config of this instance instead of the current config file")
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "include fields that are set per instance")
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "text", "output format: text, json")
+	rootCmd.AddCommand(diffCmd)
+}
+
+// configDiff is the result of comparing an instance's config.
+type configDiff struct {
+	Instance string                `json:"instance"`
+	Against  string                `json:"against"`
+	Changes  []config.ConfigChange `json:"changes"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(diffOutput); err != nil {
+		return err
+	}
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	name := args[0]
+	if err := config.ValidateInstanceName(name); err != nil {
+		return err
+	}
+	instCfg, err := config.Load(paths.ForInstance(name).ConfigFile)
+	if err != nil {
+		return fmt.Errorf("loading config of instance %q: %w", name, err)
+	}
+
+	against, againstPath := diffAgainst, ""
+	if against != "" {
+		if err := config.ValidateInstanceName(against); err != nil {
+			return err
+		}
+		againstPath = paths.ForInstance(against).ConfigFile
+	} else {
+		if againstPath, err = resolvedConfigFile(); err != nil {
+			return err
+		}
+		against = againstPath
+	}
+	otherCfg, err := config.Load(againstPath)
+	if err != nil {
+		return fmt.Errorf("loading config %s: %w", against, err)
+	}
+
+	changes, err := config.DiffConfigs(instCfg, otherCfg)
+	if err != nil {
+		return err
+	}
+	if !diffAll {
+		changes = slices.DeleteFunc(changes, func(c config.ConfigChange) bool {
+			return slices.Contains(config.InstanceKeys, c.Key)
+		})
+	}
+
+	result := configDiff{Instance: name, Against: against, Changes: changes}
+	if result.Changes == nil {
+		result.Changes = []config.ConfigChange{}
+	}
+	return renderConfigDiff(cmd.OutOrStdout(), diffOutput, result)
+}
+
+func renderConfigDiff(out io.Writer, outputFmt string, d configDiff) error {
+	if outputFmt == "json" {
+		return writeJSON(out, d)
+	}
+
+	if len(d.Changes) == 0 {
+		fmt.Fprintf(out, "Instance %q matches %s.\n", d.Instance, d.Against)
+		return nil
+	}
+	fmt.Fprintf(out, "Instance %q differs from %s:\n", d.Instance, d.Against)
+	for _, c := range d.Changes {
+		fmt.Fprintf(out, "  %s: %s -> %s\n", bold(c.Key), formatDiffValue(c.From), formatDiffValue(c.To))
+	}
+	fmt.Fprintf(out, "\nUpdate the instance config and run 'klausctl restart %s' to apply changes.\n", d.Instance)
+	return nil
+}
+
+// formatDiffValue renders a config value on a single line.
+func formatDiffValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(unset)"
+	case string:
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestDiffFlags(t *testing.T) {
+	assertCommandOnRoot(t, "diff")
+	for _, flag := range []string{"against", "all", "output"} {
+		assertFlagRegistered(t, diffCmd, flag)
+	}
+}
+
+func TestRenderConfigDiff(t *testing.T) {
+	d := configDiff{
+		Instance: "dev",
+		Against:  "/home/u/.config/klausctl/config.yaml",
+		Changes: []config.ConfigChange{
+			{Key: "claude.model", From: "sonnet", To: "opus"},
+			{Key: "envForward", To: []any{"GITHUB_TOKEN"}},
+		},
+	}
+
+	var text bytes.Buffer
+	if err := renderConfigDiff(&text, "text", d); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"claude.model", "sonnet -> opus", `(unset) -> ["GITHUB_TOKEN"]`, "klausctl restart dev"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := renderConfigDiff(&out, "json", d); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Changes []struct {
+			Key string `json:"key"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(got.Changes) != 2 || got.Changes[0].Key != "claude.model" || got.Changes[1].Key != "envForward" {
+		t.Errorf("changes = %+v", got.Changes)
+	}
+}
+
+func TestRenderConfigDiffNoChanges(t *testing.T) {
+	var out bytes.Buffer
+	if err := renderConfigDiff(&out, "text", configDiff{Instance: "dev", Against: "staging"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `Instance "dev" matches staging`) {
+		t.Errorf("output = %q", out.String())
+	}
+}
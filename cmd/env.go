@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var (
+	envOutput      string
+	envShowSecrets bool
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env <name>",
+	Short: "Print the environment a klaus instance is started with",
+	Long: `Print the env vars the container of a klaus instance is started with, as
+resolved from its config: the API key, envForward vars from the host
+environment, envFile, envVars and secretEnvVars, later sources overriding
+earlier ones, plus the Git and Claude settings klausctl sets itself.
+
+Values that may hold a credential (secrets, env file values, the API key and
+forwarded host vars) are redacted unless --show-secrets is set. Variables
+set for mounts, such as KLAUS_CONFIG_FILE, are listed by 'klausctl inspect'.
+
+  klausctl env dev
+  klausctl env dev --show-secrets -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInstanceEnv,
+}
+
+func init() {
+	envCmd.Flags().StringVarP(&envOutput, "output", "o", "text", "output format: text, json")
+	envCmd.Flags().BoolVar(&envShowSecrets, "show-secrets", false, "print secret values instead of redacting them")
+
+	rootCmd.AddCommand(envCmd)
+}
+
+func runInstanceEnv(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(envOutput); err != nil {
+		return err
+	}
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	name := args[0]
+	if err := config.ValidateInstanceName(name); err != nil {
+		return err
+	}
+	instPaths := paths.ForInstance(name)
+	cfg, err := config.Load(instPaths.ConfigFile)
+	if err != nil {
+		return fmt.Errorf("loading config of instance %q: %w", name, err)
+	}
+
+	env, err := orchestrator.EffectiveEnv(cfg, instPaths, envShowSecrets)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if envOutput == "json" {
+		return writeJSON(out, env)
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(out, "%s=%s\n", k, env[k])
+	}
+	return nil
+}
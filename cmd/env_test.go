@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvPrintsRedactedEnvironment(t *testing.T) {
+	configHome := filepath.Join(t.TempDir(), "config-home")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("KLAUSCTL_TEST_FORWARDED", "host-token")
+
+	instDir := filepath.Join(configHome, "klausctl", "instances", "dev")
+	if err := os.MkdirAll(instDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "workspace: " + t.TempDir() + "\nenvForward: [KLAUSCTL_TEST_FORWARDED]\nenvVars:\n  PLAIN: visible\n"
+	if err := os.WriteFile(filepath.Join(instDir, "config.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	origOutput, origShow := envOutput, envShowSecrets
+	t.Cleanup(func() { envOutput, envShowSecrets = origOutput, origShow })
+
+	var out bytes.Buffer
+	envCmd.SetOut(&out)
+	t.Cleanup(func() { envCmd.SetOut(nil) })
+	envOutput = "text"
+	if err := runInstanceEnv(envCmd, []string{"dev"}); err != nil {
+		t.Fatalf("runInstanceEnv: %v", err)
+	}
+	for _, want := range []string{"PLAIN=visible\n", "KLAUSCTL_TEST_FORWARDED=<redacted>\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	envOutput, envShowSecrets = "json", true
+	if err := runInstanceEnv(envCmd, []string{"dev"}); err != nil {
+		t.Fatalf("runInstanceEnv -o json: %v", err)
+	}
+	var env map[string]string
+	if err := json.Unmarshal(out.Bytes(), &env); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if env["KLAUSCTL_TEST_FORWARDED"] != "host-token" {
+		t.Errorf("KLAUSCTL_TEST_FORWARDED = %q, want host-token with --show-secrets", env["KLAUSCTL_TEST_FORWARDED"])
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events [name]",
+	Short: "Stream container lifecycle events",
+	Long: `Stream the lifecycle events of an instance's container as JSON lines
+until interrupted, e.g. to diagnose unexpected restarts or OOM kills:
+
+  {"time":"2025-06-01T12:00:00Z","container":"klausctl-dev","action":"oom"}
+  {"time":"2025-06-01T12:00:00Z","container":"klausctl-dev","action":"die","exitCode":137}
+
+Reported actions are start, restart, stop, kill, die, oom, pause and unpause.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runEvents,
+}
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+}
+
+func runEvents(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return err
+	}
+
+	instanceName, err := resolveOptionalInstanceName(args, "events", cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	paths = paths.ForInstance(instanceName)
+
+	inst, err := instance.Load(paths)
+	if err != nil {
+		return err
+	}
+
+	rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	return rt.Events(ctx, inst.ContainerName(), func(ev runtime.Event) error {
+		return enc.Encode(ev)
+	})
+}
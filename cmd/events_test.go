@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	runtimepkg "github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// eventsRuntime is a runtime that reports fixed events.
+type eventsRuntime struct {
+	runtimepkg.Runtime
+	events    []runtimepkg.Event
+	container string
+}
+
+func (r *eventsRuntime) Events(_ context.Context, name string, fn func(runtimepkg.Event) error) error {
+	r.container = name
+	for _, ev := range r.events {
+		if err := fn(ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestEventsRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "events")
+}
+
+func TestRunEventsWritesJSONLines(t *testing.T) {
+	_, cmd := setupStopTest(t, "dev")
+	exitCode := 137
+	at := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	rt := &eventsRuntime{events: []runtimepkg.Event{
+		{Time: at, Container: "klausctl-dev", Action: "oom"},
+		{Time: at, Container: "klausctl-dev", Action: "die", ExitCode: &exitCode},
+	}}
+	newRuntime = func(_, _ string) (runtimepkg.Runtime, error) { return rt, nil }
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	if err := runEvents(cmd, []string{"dev"}); err != nil {
+		t.Fatalf("runEvents() error = %v", err)
+	}
+
+	if rt.container != "klausctl-dev" {
+		t.Errorf("events requested for %q, want klausctl-dev", rt.container)
+	}
+	want := `{"time":"2025-06-01T12:00:00Z","container":"klausctl-dev","action":"oom"}
+{"time":"2025-06-01T12:00:00Z","container":"klausctl-dev","action":"die","exitCode":137}
+`
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRunEventsMissingInstance(t *testing.T) {
+	_, cmd := setupStopTest(t)
+	err := runEvents(cmd, []string{"missing"})
+	if err == nil || !strings.Contains(err.Error(), "no instance found") {
+		t.Errorf("error = %v, want no instance found", err)
+	}
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// formatFuncs are the functions available to --format templates in addition
+// to the text/template builtins.
+var formatFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// parseRowFormat parses a --format Go template that list commands evaluate
+// once per row, as in docker's --format. An empty format returns nil. The
+// template is parsed before any row is printed so that syntax errors never
+// produce partial output.
+func parseRowFormat(format, outputFmt string) (*template.Template, error) {
+	if format == "" {
+		return nil, nil
+	}
+	if outputFmt == "json" {
+		return nil, fmt.Errorf("--format cannot be combined with --output json")
+	}
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// printFormattedRows executes tmpl for each row, writing one line per row.
+func printFormattedRows[T any](out io.Writer, tmpl *template.Template, rows []T) error {
+	for _, row := range rows {
+		if err := tmpl.Execute(out, row); err != nil {
+			return fmt.Errorf("executing --format template: %w", err)
+		}
+		if _, err := fmt.Fprintln(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
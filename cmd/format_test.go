@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+func TestFormatFlags(t *testing.T) {
+	assertFlagRegistered(t, listCmd, "format")
+	assertFlagRegistered(t, pluginListCmd, "format")
+	assertFlagRegistered(t, personalityListCmd, "format")
+	assertFlagRegistered(t, toolchainListCmd, "format")
+}
+
+func TestParseRowFormat(t *testing.T) {
+	tmpl, err := parseRowFormat("", "text")
+	if err != nil || tmpl != nil {
+		t.Errorf("parseRowFormat(\"\") = %v, %v; want nil, nil", tmpl, err)
+	}
+
+	if _, err := parseRowFormat("{{.Name}}", "json"); err == nil || !strings.Contains(err.Error(), "--output json") {
+		t.Errorf("expected error combining --format with json, got %v", err)
+	}
+
+	if _, err := parseRowFormat("{{.Name", "text"); err == nil || !strings.Contains(err.Error(), "invalid --format template") {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
+
+func TestPrintFormattedRows(t *testing.T) {
+	entries := []listEntry{
+		{Name: "dev", Status: "running", Port: 8080},
+		{Name: "staging", Status: "stopped"},
+	}
+
+	tmpl, err := parseRowFormat("{{.Name}}\t{{.Status}}\t{{.Port}}", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := printFormattedRows(&buf, tmpl, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := "dev\trunning\t8080\nstaging\tstopped\t0\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestPrintFormattedRowsJSONFunc(t *testing.T) {
+	tmpl, err := parseRowFormat("{{json .Name}}", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := printFormattedRows(&buf, tmpl, []cachedArtifact{{Name: "gs-base"}}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "\"gs-base\"\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestPrintFormattedRowsUnknownField(t *testing.T) {
+	tmpl, err := parseRowFormat("{{.Nope}}", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = printFormattedRows(&buf, tmpl, []listEntry{{Name: "dev"}})
+	if err == nil || !strings.Contains(err.Error(), "executing --format template") {
+		t.Errorf("expected execution error, got %v", err)
+	}
+}
+
+func TestToolchainListFormat(t *testing.T) {
+	rt := &mockRuntime{
+		images: []runtime.ImageInfo{
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-toolchains/go", Tag: "1.0.0"},
+			{Repository: "docker.io/library/alpine", Tag: "3.19"},
+		},
+	}
+	tmpl, err := parseRowFormat("{{.Repository}}:{{.Tag}}", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	err = toolchainList(context.Background(), &buf, rt, toolchainListOptions{resolver: config.DefaultSourceResolver(), rowFormat: tmpl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "gsoci.azurecr.io/giantswarm/klaus-toolchains/go:1.0.0\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <name>",
+	Short: "Show the full resolved state of a klaus instance",
+	Long: `Print the complete resolved state of a klaus instance as JSON, for debugging:
+its saved config and state, the resolved personality and plugins, the
+container as reported by the runtime including its image ID, and the volumes
+and env vars the container is started with.
+
+Only local state is used; nothing is pulled. Secret env vars and files are
+listed with redacted values, as are the API key and forwarded host env vars.
+
+  klausctl inspect dev
+  klausctl inspect dev | jq .env`,
+	Args: cobra.ExactArgs(1),
+	RunE: runInspect,
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	name := args[0]
+	if err := config.ValidateInstanceName(name); err != nil {
+		return err
+	}
+
+	result, err := orchestrator.Inspect(ctx, paths.ForInstance(name), name, newRuntime)
+	if err != nil {
+		return err
+	}
+	return writeJSON(cmd.OutOrStdout(), result)
+}
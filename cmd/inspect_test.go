@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInspectPrintsJSON(t *testing.T) {
+	configHome := filepath.Join(t.TempDir(), "config-home")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	overrideRuntime(t, &rollbackRuntime{})
+
+	instDir := filepath.Join(configHome, "klausctl", "instances", "dev")
+	if err := os.MkdirAll(instDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "workspace: " + t.TempDir() + "\nsecretEnvVars:\n  GITHUB_TOKEN: gh-token\n"
+	if err := os.WriteFile(filepath.Join(instDir, "config.yaml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	inspectCmd.SetOut(&out)
+	t.Cleanup(func() { inspectCmd.SetOut(nil) })
+	if err := runInspect(inspectCmd, []string{"dev"}); err != nil {
+		t.Fatalf("runInspect: %v", err)
+	}
+
+	var got struct {
+		Name    string            `json:"name"`
+		Env     map[string]string `json:"env"`
+		Volumes []struct {
+			ContainerPath string `json:"containerPath"`
+		} `json:"volumes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if got.Name != "dev" {
+		t.Errorf("name = %q, want dev", got.Name)
+	}
+	if got.Env["GITHUB_TOKEN"] != "<redacted>" {
+		t.Errorf("GITHUB_TOKEN = %q, want it redacted", got.Env["GITHUB_TOKEN"])
+	}
+	if len(got.Volumes) == 0 || got.Volumes[0].ContainerPath != "/workspace" {
+		t.Errorf("volumes = %+v, want the workspace mount first", got.Volumes)
+	}
+}
+
+func TestInspectRejectsInvalidName(t *testing.T) {
+	if err := runInspect(inspectCmd, []string{"../x"}); err == nil {
+		t.Fatal("expected error for an invalid instance name")
+	}
+}
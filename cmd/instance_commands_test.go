@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -307,9 +308,23 @@ func TestCreateGitFlags(t *testing.T) {
 	assertFlagRegistered(t, createCmd, "git-https-instead-of-ssh")
 }
 
+func TestCreateNetworkFlag(t *testing.T) {
+	assertFlagRegistered(t, createCmd, "network")
+}
+
+func TestPlatformFlags(t *testing.T) {
+	assertFlagRegistered(t, createCmd, "platform")
+	assertFlagRegistered(t, startCmd, "platform")
+	assertFlagRegistered(t, startCmd, "image")
+	assertFlagRegistered(t, startCmd, "auto-port")
+	assertFlagRegistered(t, startCmd, "no-warn-floating")
+	assertFlagRegistered(t, toolchainPullCmd, "platform")
+}
+
 func TestCreateCollisionAndSuffixFlags(t *testing.T) {
 	assertFlagRegistered(t, createCmd, "yes")
 	assertFlagRegistered(t, createCmd, "force")
+	assertFlagRegistered(t, createCmd, "replace")
 	assertFlagRegistered(t, createCmd, "generate-suffix")
 }
 
@@ -495,6 +510,57 @@ func TestCreateCollisionStoppedAutoConfirmWithYes(t *testing.T) {
 	}
 }
 
+func TestCreateReplaceRestoresPreviousInstanceOnFailure(t *testing.T) {
+	configHome := filepath.Join(t.TempDir(), "config-home")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	workspace := filepath.Join(t.TempDir(), "workspace")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	instanceDir := filepath.Join(configHome, "klausctl", "instances", "existing")
+	if err := os.MkdirAll(instanceDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	markerFile := filepath.Join(instanceDir, "old-marker.txt")
+	if err := os.WriteFile(markerFile, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(instanceDir, "config.yaml"), []byte("workspace: /tmp\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	createPersonality = ""
+	createToolchain = ""
+	createPlugins = nil
+	createPort = 0
+	createYes = false
+	createForce = false
+	createReplace = true
+	createGenerateSuffix = true
+	createNoIsolate = true
+	createSource = ""
+	t.Cleanup(func() { createReplace = false })
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	// --replace neither prompts nor generates a suffix. runCreate fails at
+	// startInstance (no runtime), so the previous instance is restored.
+	err := runCreate(cmd, []string{"existing", workspace})
+	if err == nil {
+		t.Fatal("expected error from startInstance (no runtime)")
+	}
+	if !strings.Contains(err.Error(), "was restored") {
+		t.Fatalf("expected error to report the restored instance, got: %v", err)
+	}
+	if _, err := os.Stat(markerFile); err != nil {
+		t.Fatalf("expected previous instance to be restored: %v", err)
+	}
+}
+
 func TestHandleCLICollisionRunningWithoutForce(t *testing.T) {
 	cmd := &cobra.Command{}
 	cmd.SetOut(io.Discard)
@@ -513,34 +579,81 @@ func TestHandleCLICollisionRunningWithoutForce(t *testing.T) {
 }
 
 type fakeRuntime struct {
-	status      string
-	stopCalls   int
-	removeCalls int
+	mu           sync.Mutex
+	status       string
+	stopCalls    int
+	stopTimeouts []time.Duration
+	removeCalls  int
+	startCalls   int
+	pauseCalls   int
+	unpauseCalls int
+	execs        []runtimepkg.ExecOptions
+	execErr      error
 }
 
 func (f *fakeRuntime) Name() string { return "fake" }
 func (f *fakeRuntime) Run(_ context.Context, _ runtimepkg.RunOptions) (string, error) {
 	return "", nil
 }
-func (f *fakeRuntime) Stop(_ context.Context, _ string) error {
+func (f *fakeRuntime) Stop(_ context.Context, _ string, timeout time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.stopCalls++
+	f.stopTimeouts = append(f.stopTimeouts, timeout)
 	return nil
 }
 func (f *fakeRuntime) Remove(_ context.Context, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.removeCalls++
 	return nil
 }
+func (f *fakeRuntime) Start(_ context.Context, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.startCalls++
+	return nil
+}
+func (f *fakeRuntime) Pause(_ context.Context, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pauseCalls++
+	return nil
+}
+func (f *fakeRuntime) Unpause(_ context.Context, _ string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unpauseCalls++
+	return nil
+}
 func (f *fakeRuntime) Status(_ context.Context, _ string) (string, error) {
 	return f.status, nil
 }
 func (f *fakeRuntime) Inspect(_ context.Context, _ string) (*runtimepkg.ContainerInfo, error) {
 	return &runtimepkg.ContainerInfo{StartedAt: time.Now()}, nil
 }
-func (f *fakeRuntime) Logs(_ context.Context, _ string, _ bool, _ int) error { return nil }
+func (f *fakeRuntime) Logs(_ context.Context, _ string, _ bool, _ int, _, _ io.Writer) error {
+	return nil
+}
 func (f *fakeRuntime) LogsCapture(_ context.Context, _ string, _ int) (string, error) {
 	return "", nil
 }
-func (f *fakeRuntime) Pull(_ context.Context, _ string, _ io.Writer) error { return nil }
+func (f *fakeRuntime) Pull(_ context.Context, _ string, _ runtimepkg.PullOptions, _ io.Writer) error {
+	return nil
+}
 func (f *fakeRuntime) Images(_ context.Context, _ string) ([]runtimepkg.ImageInfo, error) {
 	return nil, nil
 }
+func (f *fakeRuntime) Version(_ context.Context) (string, error) { return "", nil }
+func (f *fakeRuntime) ListManaged(_ context.Context) ([]runtimepkg.ContainerInfo, error) {
+	return nil, nil
+}
+func (f *fakeRuntime) Events(_ context.Context, _ string, _ func(runtimepkg.Event) error) error {
+	return nil
+}
+func (f *fakeRuntime) Exec(_ context.Context, _ string, opts runtimepkg.ExecOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.execs = append(f.execs, opts)
+	return f.execErr
+}
@@ -15,10 +15,14 @@ import (
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
-var listOutput string
+var (
+	listOutput string
+	listFormat string
+	listSince  string
+	listUntil  string
+)
 
 type listEntry struct {
 	Name        string `json:"name"`
@@ -28,16 +32,35 @@ type listEntry struct {
 	Workspace   string `json:"workspace,omitempty"`
 	Port        int    `json:"port,omitempty"`
 	Uptime      string `json:"uptime,omitempty"`
+
+	// startedAt is the start time from the instance state, zero when the
+	// instance has none.
+	startedAt time.Time
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List klaus instances",
-	RunE:  runList,
+	Long: `List klaus instances with their status.
+
+--format prints each instance with a Go template instead of the table, e.g.
+'{{.Name}}\t{{.Status}}'. The fields are Name, Status, Toolchain,
+Personality, Workspace, Port and Uptime.
+
+--since and --until only list instances started within a window. They take a
+duration before now (e.g. 2h) or an RFC3339 timestamp; instances without a
+start time, such as stopped ones, are left out when either is set.
+
+  klausctl list --since 24h
+  klausctl list --since 2025-06-01T00:00:00Z --until 2025-06-02T00:00:00Z`,
+	RunE: runList,
 }
 
 func init() {
 	listCmd.Flags().StringVarP(&listOutput, "output", "o", "text", "output format: text, json")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "format each instance with a Go template (e.g. '{{.Name}}')")
+	listCmd.Flags().StringVar(&listSince, "since", "", "only instances started at or after this time (duration like 2h, or RFC3339)")
+	listCmd.Flags().StringVar(&listUntil, "until", "", "only instances started at or before this time (duration like 2h, or RFC3339)")
 	rootCmd.AddCommand(listCmd)
 }
 
@@ -45,6 +68,22 @@ func runList(cmd *cobra.Command, _ []string) error {
 	if err := validateOutputFormat(listOutput); err != nil {
 		return err
 	}
+	rowFormat, err := parseRowFormat(listFormat, listOutput)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	since, err := parseTimeFilter("--since", listSince, now)
+	if err != nil {
+		return err
+	}
+	until, err := parseTimeFilter("--until", listUntil, now)
+	if err != nil {
+		return err
+	}
+	if !since.IsZero() && !until.IsZero() && since.After(until) {
+		return fmt.Errorf("--since (%s) is after --until (%s)", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
 
 	paths, err := config.DefaultPaths()
 	if err != nil {
@@ -58,6 +97,13 @@ func runList(cmd *cobra.Command, _ []string) error {
 	if err != nil {
 		return err
 	}
+	if !since.IsZero() || !until.IsZero() {
+		entries = filterByStartTime(entries, since, until)
+	}
+
+	if rowFormat != nil {
+		return printFormattedRows(cmd.OutOrStdout(), rowFormat, entries)
+	}
 
 	if listOutput == "json" {
 		enc := json.NewEncoder(cmd.OutOrStdout())
@@ -128,7 +174,8 @@ func loadListEntries(paths *config.Paths) ([]listEntry, error) {
 		}
 
 		if st, ok := stateByName[name]; ok {
-			rt, err := runtime.New(st.Runtime)
+			item.startedAt = st.StartedAt
+			rt, err := st.NewRuntime()
 			if err == nil {
 				status, err := rt.Status(context.Background(), st.ContainerName())
 				if err == nil && status != "" {
@@ -159,3 +206,43 @@ func valueOrDash(v string) string {
 	}
 	return v
 }
+
+// parseTimeFilter parses the value of a time filter flag: a duration such as
+// "2h", meaning that long before now, or an RFC3339 timestamp. An empty value
+// returns the zero time.
+func parseTimeFilter(flag, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid %s value %q: duration must not be negative", flag, value)
+		}
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s value %q: expected a duration (e.g. 2h) or an RFC3339 timestamp", flag, value)
+	}
+	return t, nil
+}
+
+// filterByStartTime returns the entries started within since and until,
+// both inclusive. A zero bound is open. Entries without a start time are
+// dropped.
+func filterByStartTime(entries []listEntry, since, until time.Time) []listEntry {
+	filtered := make([]listEntry, 0)
+	for _, e := range entries {
+		if e.startedAt.IsZero() {
+			continue
+		}
+		if !since.IsZero() && e.startedAt.Before(since) {
+			continue
+		}
+		if !until.IsZero() && e.startedAt.After(until) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	return filtered
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListTimeFilterFlags(t *testing.T) {
+	assertFlagRegistered(t, listCmd, "since")
+	assertFlagRegistered(t, listCmd, "until")
+}
+
+func TestParseTimeFilter(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{name: "empty", value: "", want: time.Time{}},
+		{name: "duration", value: "2h", want: now.Add(-2 * time.Hour)},
+		{name: "zero duration", value: "0s", want: now},
+		{name: "rfc3339", value: "2025-05-31T08:30:00Z", want: time.Date(2025, 5, 31, 8, 30, 0, 0, time.UTC)},
+		{name: "negative duration", value: "-1h", wantErr: "must not be negative"},
+		{name: "garbage", value: "yesterday", wantErr: "expected a duration"},
+		{name: "date only", value: "2025-05-31", wantErr: "expected a duration"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTimeFilter("--since", tt.value, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTimeFilter() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTimeFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByStartTime(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []listEntry{
+		{Name: "early", startedAt: base.Add(-time.Hour)},
+		{Name: "at-since", startedAt: base},
+		{Name: "middle", startedAt: base.Add(30 * time.Minute)},
+		{Name: "at-until", startedAt: base.Add(time.Hour)},
+		{Name: "late", startedAt: base.Add(2 * time.Hour)},
+		{Name: "stopped"},
+	}
+
+	names := func(entries []listEntry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		since, until time.Time
+		want         []string
+	}{
+		{"window is inclusive", base, base.Add(time.Hour), []string{"at-since", "middle", "at-until"}},
+		{"since only", base.Add(time.Hour), time.Time{}, []string{"at-until", "late"}},
+		{"until only", time.Time{}, base, []string{"early", "at-since"}},
+		{"empty window", base.Add(3 * time.Hour), time.Time{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(filterByStartTime(entries, tt.since, tt.until))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterByStartTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// An empty result is printed as [] in JSON, not null.
+	if got := filterByStartTime(entries, base.Add(3*time.Hour), time.Time{}); got == nil {
+		t.Error("filterByStartTime() = nil, want an empty slice")
+	}
+}
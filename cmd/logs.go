@@ -2,32 +2,71 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/logparse"
+	"github.com/giantswarm/klausctl/pkg/logreplay"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
 	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 var (
 	logsFollow bool
 	logsTail   int
+	logsReplay string
+	logsSpeed  float64
+	logsJSON   bool
+	logsFields []string
+	logsAll    bool
 )
 
 var logsCmd = &cobra.Command{
 	Use:   "logs [name]",
 	Short: "Stream container logs",
-	Long:  `Stream logs from the running klaus container.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runLogs,
+	Long: `Stream logs from the running klaus container.
+
+With --all, the logs of every running instance are streamed together, each
+line prefixed with its instance name; --follow and --tail apply to each
+instance:
+
+  klausctl logs --all -f --tail 20
+
+With --replay, a previously saved log file is played back instead, honoring
+the time between its timestamped lines to simulate a live stream. Save logs
+with timestamps to replay them, e.g.:
+
+  docker logs --timestamps klausctl-dev > dev.log
+  klausctl logs --replay dev.log --speed 10
+
+With --json, the JSON lines emitted by the klaus agent are parsed and
+pretty-printed; other lines are passed through unchanged. Add --field to
+show only the JSON lines whose field has the given value (repeatable, dots
+select nested fields):
+
+  klausctl logs dev --json --field type=assistant`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLogs,
 }
 
 func init() {
 	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "follow log output")
 	logsCmd.Flags().IntVar(&logsTail, "tail", 0, "number of lines to show from the end of the logs (0 = all)")
+	logsCmd.Flags().StringVar(&logsReplay, "replay", "", "play back a saved log file with its original timing instead of reading container logs")
+	logsCmd.Flags().Float64Var(&logsSpeed, "speed", 1, "playback speed multiplier for --replay (e.g. 2 = twice as fast)")
+	logsCmd.Flags().BoolVar(&logsJSON, "json", false, "parse the agent's JSON lines and pretty-print them")
+	logsCmd.Flags().StringArrayVar(&logsFields, "field", nil, "with --json, only show lines whose field has the value (path=value, repeatable)")
+	logsCmd.Flags().BoolVar(&logsAll, "all", false, "stream the logs of every running instance, prefixed with the instance name")
 	rootCmd.AddCommand(logsCmd)
 }
 
@@ -35,6 +74,35 @@ func runLogs(cmd *cobra.Command, args []string) error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	if logsReplay != "" {
+		if len(args) > 0 {
+			return fmt.Errorf("--replay reads a log file and does not take an instance name")
+		}
+		if logsFollow || logsTail != 0 || logsAll {
+			return fmt.Errorf("--replay cannot be combined with --follow, --tail or --all")
+		}
+		return replayLogs(ctx, cmd, logsReplay, logsSpeed)
+	}
+	if cmd.Flags().Changed("speed") {
+		return fmt.Errorf("--speed requires --replay")
+	}
+	if logsJSON && logsFollow {
+		return fmt.Errorf("--json cannot be combined with --follow")
+	}
+	if len(logsFields) > 0 && !logsJSON {
+		return fmt.Errorf("--field requires --json")
+	}
+	if logsAll && logsJSON {
+		return fmt.Errorf("--json cannot be combined with --all")
+	}
+	if logsAll && len(args) > 0 {
+		return fmt.Errorf("--all streams every running instance and does not take an instance name")
+	}
+	filters, err := parseLogFilters(logsFields)
+	if err != nil {
+		return err
+	}
+
 	paths, err := config.DefaultPaths()
 	if err != nil {
 		return err
@@ -43,6 +111,15 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if logsAll {
+		targets := runningLogTargets(ctx, paths)
+		if len(targets) == 0 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "No running instances.")
+			return nil
+		}
+		return streamAllLogs(ctx, cmd.OutOrStdout(), targets, logsFollow, logsTail)
+	}
+
 	instanceName, err := resolveOptionalInstanceName(args, "logs", cmd.ErrOrStderr())
 	if err != nil {
 		return err
@@ -54,10 +131,125 @@ func runLogs(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		return err
 	}
 
-	return rt.Logs(ctx, inst.ContainerName(), logsFollow, logsTail)
+	if logsJSON {
+		logs, err := rt.LogsCapture(ctx, inst.ContainerName(), logsTail)
+		if err != nil {
+			return fmt.Errorf("fetching logs: %w", err)
+		}
+		return printParsedLogs(cmd.OutOrStdout(), strings.NewReader(logs), filters)
+	}
+	return rt.Logs(ctx, inst.ContainerName(), logsFollow, logsTail, cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// logTarget is the container of a running instance streamed by --all.
+type logTarget struct {
+	name      string
+	container string
+	rt        runtime.Runtime
+}
+
+// runningLogTargets returns the instances whose container is running, in
+// name order. Instances whose state cannot be read or whose runtime is not
+// available are skipped.
+func runningLogTargets(ctx context.Context, paths *config.Paths) []logTarget {
+	instances, err := instance.LoadAll(paths)
+	if err != nil {
+		return nil
+	}
+	var targets []logTarget
+	for _, inst := range instances {
+		rt, err := inst.NewRuntime()
+		if err != nil {
+			continue
+		}
+		if status, err := rt.Status(ctx, inst.ContainerName()); err != nil || status != "running" {
+			continue
+		}
+		targets = append(targets, logTarget{name: inst.Name, container: inst.ContainerName(), rt: rt})
+	}
+	return targets
+}
+
+// streamAllLogs streams the logs of all targets to w concurrently, each
+// line prefixed with the instance name in its own color. Lines of different
+// instances are never interleaved.
+func streamAllLogs(ctx context.Context, w io.Writer, targets []logTarget, follow bool, tail int) error {
+	width := 0
+	for _, t := range targets {
+		width = max(width, len(t.name))
+	}
+
+	w = orchestrator.SyncWriter(w)
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pw := newPrefixWriter(w, instanceColor(i, fmt.Sprintf("%-*s |", width, t.name))+" ")
+			err := t.rt.Logs(ctx, t.container, follow, tail, pw, pw)
+			pw.Flush()
+			if err != nil {
+				errs[i] = fmt.Errorf("streaming logs of %q: %w", t.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func parseLogFilters(specs []string) ([]logparse.Filter, error) {
+	filters := make([]logparse.Filter, 0, len(specs))
+	for _, spec := range specs {
+		f, err := logparse.ParseFilter(spec)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// printParsedLogs pretty-prints the JSON lines of r and passes the other
+// lines through. With filters, only the matching JSON lines are printed.
+func printParsedLogs(w io.Writer, r io.Reader, filters []logparse.Filter) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return logparse.Parse(r, func(l logparse.Line) error {
+		if l.Fields == nil {
+			if len(filters) > 0 {
+				return nil
+			}
+			_, err := fmt.Fprintln(w, l.Text)
+			return err
+		}
+		if !logparse.MatchAll(l, filters) {
+			return nil
+		}
+		return enc.Encode(l.Fields)
+	})
+}
+
+// replayLogs plays back the saved log file at path to the command output.
+func replayLogs(ctx context.Context, cmd *cobra.Command, path string, speed float64) error {
+	if speed <= 0 {
+		return fmt.Errorf("--speed must be > 0, got %g", speed)
+	}
+
+	f, err := os.Open(path) // #nosec G304 -- user-supplied log file path
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	err = logreplay.Replay(ctx, f, cmd.OutOrStdout(), logreplay.Options{Speed: speed})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
 }
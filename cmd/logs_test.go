@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	runtimepkg "github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+func TestLogsReplayFlags(t *testing.T) {
+	assertFlagRegistered(t, logsCmd, "replay")
+	assertFlagRegistered(t, logsCmd, "speed")
+}
+
+func TestReplayLogs(t *testing.T) {
+	saved := "2026-01-01T10:00:00Z first\n2026-01-01T10:00:00.01Z second\n"
+	path := filepath.Join(t.TempDir(), "dev.log")
+	if err := os.WriteFile(path, []byte(saved), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	logsCmd.SetOut(&out)
+	t.Cleanup(func() { logsCmd.SetOut(nil) })
+
+	if err := replayLogs(t.Context(), logsCmd, path, 100); err != nil {
+		t.Fatalf("replayLogs() error = %v", err)
+	}
+	if out.String() != saved {
+		t.Errorf("output = %q, want %q", out.String(), saved)
+	}
+}
+
+func TestReplayLogsRejectsInvalidSpeed(t *testing.T) {
+	err := replayLogs(t.Context(), logsCmd, "unused.log", 0)
+	if err == nil || !strings.Contains(err.Error(), "--speed") {
+		t.Fatalf("replayLogs() error = %v, want --speed error", err)
+	}
+}
+
+func TestLogsJSONFlags(t *testing.T) {
+	assertFlagRegistered(t, logsCmd, "json")
+	assertFlagRegistered(t, logsCmd, "field")
+}
+
+func TestPrintParsedLogs(t *testing.T) {
+	logs := "starting\n{\"type\":\"assistant\",\"turn\":1}\n{\"type\":\"result\"}\n"
+
+	var out bytes.Buffer
+	if err := printParsedLogs(&out, strings.NewReader(logs), nil); err != nil {
+		t.Fatalf("printParsedLogs() error = %v", err)
+	}
+	want := "starting\n{\n  \"turn\": 1,\n  \"type\": \"assistant\"\n}\n{\n  \"type\": \"result\"\n}\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+
+	filters, err := parseLogFilters([]string{"type=result"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := printParsedLogs(&out, strings.NewReader(logs), filters); err != nil {
+		t.Fatalf("printParsedLogs() error = %v", err)
+	}
+	if want := "{\n  \"type\": \"result\"\n}\n"; out.String() != want {
+		t.Errorf("filtered output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestLogsAllFlag(t *testing.T) {
+	assertFlagRegistered(t, logsCmd, "all")
+}
+
+// logsRuntime writes canned log lines of a container in Logs.
+type logsRuntime struct {
+	runtimepkg.Runtime
+	logs map[string]string
+	tail []int
+}
+
+func (r *logsRuntime) Logs(_ context.Context, name string, _ bool, tail int, stdout, _ io.Writer) error {
+	r.tail = append(r.tail, tail)
+	logs, ok := r.logs[name]
+	if !ok {
+		return errors.New("no such container")
+	}
+	_, err := io.WriteString(stdout, logs)
+	return err
+}
+
+func TestStreamAllLogs(t *testing.T) {
+	origColor := colorEnabled
+	colorEnabled = false
+	t.Cleanup(func() { colorEnabled = origColor })
+
+	rt := &logsRuntime{logs: map[string]string{
+		"klausctl-dev":    "starting\nready\n",
+		"klausctl-review": "listening\nno newline",
+	}}
+	targets := []logTarget{
+		{name: "dev", container: "klausctl-dev", rt: rt},
+		{name: "review", container: "klausctl-review", rt: rt},
+	}
+
+	var out bytes.Buffer
+	if err := streamAllLogs(context.Background(), &out, targets, false, 5); err != nil {
+		t.Fatalf("streamAllLogs() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	slices.Sort(lines)
+	want := []string{
+		"dev    | ready",
+		"dev    | starting",
+		"review | listening",
+		"review | no newline",
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	if !slices.Equal(rt.tail, []int{5, 5}) {
+		t.Errorf("tail = %v, want 5 for every instance", rt.tail)
+	}
+}
+
+func TestStreamAllLogsReportsFailures(t *testing.T) {
+	rt := &logsRuntime{logs: map[string]string{"klausctl-dev": "ok\n"}}
+	targets := []logTarget{
+		{name: "dev", container: "klausctl-dev", rt: rt},
+		{name: "gone", container: "klausctl-gone", rt: rt},
+	}
+
+	var out bytes.Buffer
+	err := streamAllLogs(context.Background(), &out, targets, false, 0)
+	if err == nil || !strings.Contains(err.Error(), `"gone"`) {
+		t.Fatalf("error = %v, want one naming the failed instance", err)
+	}
+	if !strings.Contains(out.String(), "ok") {
+		t.Errorf("logs of the other instance missing: %q", out.String())
+	}
+}
+
+func TestInstanceColor(t *testing.T) {
+	origColor := colorEnabled
+	t.Cleanup(func() { colorEnabled = origColor })
+
+	colorEnabled = true
+	if instanceColor(0, "dev") == instanceColor(1, "dev") {
+		t.Error("expected consecutive instances to get different colors")
+	}
+	if got, want := instanceColor(len(instanceColors), "dev"), instanceColor(0, "dev"); got != want {
+		t.Errorf("colors do not cycle: %q != %q", got, want)
+	}
+
+	colorEnabled = false
+	if got := instanceColor(3, "dev"); got != "dev" {
+		t.Errorf("instanceColor() without color = %q", got)
+	}
+}
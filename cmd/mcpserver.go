@@ -13,6 +13,8 @@ import (
 
 var mcpserverAddURL string
 var mcpserverAddSecret string
+var mcpserverAddHeaderName string
+var mcpserverAddHeaderFormat string
 
 var mcpserverCmd = &cobra.Command{
 	Use:   "mcpserver",
@@ -22,7 +24,8 @@ var mcpserverCmd = &cobra.Command{
 Managed MCP servers are stored in ~/.config/klausctl/mcpservers.yaml.
 They can be referenced by name via --mcpserver or mcpServerRefs in instance
 configs. At start time, each referenced server is merged into the instance's
-mcpServers config with an optional Bearer token from the secrets store.`,
+mcpServers config with an optional authentication header built from a secret
+in the secrets store, by default "Authorization: Bearer <secret>".`,
 }
 
 var mcpserverAddCmd = &cobra.Command{
@@ -31,7 +34,13 @@ var mcpserverAddCmd = &cobra.Command{
 	Long: `Register a managed MCP server definition.
 
   klausctl mcpserver add muster --url https://muster.example.com/mcp
-  klausctl mcpserver add muster --url https://muster.example.com/mcp --secret muster-token`,
+  klausctl mcpserver add muster --url https://muster.example.com/mcp --secret muster-token
+
+Servers that expect the secret in another header can set its name and a
+format with a single %s standing for the secret:
+
+  klausctl mcpserver add search --url https://search.example.com/mcp \
+    --secret search-key --header-name X-API-Key --header-format '%s'`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMcpserverAdd,
 }
@@ -81,6 +90,8 @@ func init() {
 	mcpserverAddCmd.Flags().StringVar(&mcpserverAddURL, "url", "", "MCP server URL (required)")
 	_ = mcpserverAddCmd.MarkFlagRequired("url")
 	mcpserverAddCmd.Flags().StringVar(&mcpserverAddSecret, "secret", "", "secret name for Bearer token authentication")
+	mcpserverAddCmd.Flags().StringVar(&mcpserverAddHeaderName, "header-name", "", "header the secret is sent in (default: Authorization)")
+	mcpserverAddCmd.Flags().StringVar(&mcpserverAddHeaderFormat, "header-format", "", "header value with %s for the secret (default: \"Bearer %s\")")
 
 	mcpserverCmd.AddCommand(mcpserverAddCmd)
 	mcpserverCmd.AddCommand(mcpserverListCmd)
@@ -101,15 +112,25 @@ func loadMcpServerStore() (*mcpserverstore.Store, error) {
 func runMcpserverAdd(cmd *cobra.Command, args []string) error {
 	name := args[0]
 
+	def := mcpserverstore.McpServerDef{
+		URL:          mcpserverAddURL,
+		Secret:       mcpserverAddSecret,
+		HeaderName:   mcpserverAddHeaderName,
+		HeaderFormat: mcpserverAddHeaderFormat,
+	}
+	if err := def.Validate(); err != nil {
+		return err
+	}
+	if def.Secret == "" && (def.HeaderName != "" || def.HeaderFormat != "") {
+		return fmt.Errorf("--header-name and --header-format require --secret")
+	}
+
 	store, err := loadMcpServerStore()
 	if err != nil {
 		return err
 	}
 
-	store.Add(name, mcpserverstore.McpServerDef{
-		URL:    mcpserverAddURL,
-		Secret: mcpserverAddSecret,
-	})
+	store.Add(name, def)
 
 	if err := store.Save(); err != nil {
 		return err
@@ -148,6 +169,9 @@ func runMcpserverList(cmd *cobra.Command, _ []string) error {
 
 func authLabel(def mcpserverstore.McpServerDef, tokenStore *oauth.TokenStore) string {
 	if def.Secret != "" {
+		if def.HeaderName != "" {
+			return "secret, " + def.HeaderName
+		}
 		return "secret" //nolint:goconst
 	}
 	st := tokenStore.GetToken(def.URL)
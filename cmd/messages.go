@@ -16,7 +16,6 @@ import (
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 var (
@@ -111,7 +110,7 @@ func runMessages(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no klaus instance found for %q; run 'klausctl create %s <workspace>' first", instanceName, instanceName)
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		return err
 	}
@@ -124,7 +123,7 @@ func runMessages(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("instance %q is not running (status: %s); run 'klausctl start %s' first", instanceName, status, instanceName)
 	}
 
-	baseURL := fmt.Sprintf("http://localhost:%d/mcp", inst.Port)
+	baseURL := inst.AgentURL() + "/mcp"
 
 	client := mcpclient.New(buildVersion)
 	defer client.Close()
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause <name>",
+	Short: "Pause a running klaus instance",
+	Long: `Freeze all processes of a running klaus instance to free CPU without
+losing its state. The container, its in-memory state and the agent session
+are kept; resume continues where the agent left off. A paused agent does not
+answer MCP requests.
+
+  klausctl pause dev
+  klausctl resume dev`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPause,
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume <name>",
+	Short: "Resume a paused klaus instance",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runResume,
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(resumeCmd)
+}
+
+func runPause(cmd *cobra.Command, args []string) error {
+	return runSetPaused(cmd, args[0], true)
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	return runSetPaused(cmd, args[0], false)
+}
+
+func runSetPaused(cmd *cobra.Command, name string, pause bool) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+	if err := config.ValidateInstanceName(name); err != nil {
+		return err
+	}
+
+	inst, err := instance.Load(paths.ForInstance(name))
+	if err != nil {
+		return fmt.Errorf("instance %q is not running", name)
+	}
+	rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
+	if err != nil {
+		return err
+	}
+	if err := setPaused(ctx, rt, name, inst.ContainerName(), pause); err != nil {
+		return err
+	}
+
+	if pause {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Instance %q paused.\n", name)
+	} else {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Instance %q resumed.\n", name)
+	}
+	return nil
+}
+
+// setPaused pauses or resumes the container of an instance. Pausing requires
+// a running container and resuming a paused one.
+func setPaused(ctx context.Context, rt runtime.Runtime, name, containerName string, pause bool) error {
+	status, err := rt.Status(ctx, containerName)
+	if err != nil {
+		return fmt.Errorf("checking container status: %w", err)
+	}
+
+	if pause {
+		if status != "running" {
+			return fmt.Errorf("instance %q is not running (status: %s)", name, displayStatus(status))
+		}
+		if err := rt.Pause(ctx, containerName); err != nil {
+			return fmt.Errorf("pausing container: %w", err)
+		}
+		return nil
+	}
+
+	if status != "paused" {
+		return fmt.Errorf("instance %q is not paused (status: %s)", name, displayStatus(status))
+	}
+	if err := rt.Unpause(ctx, containerName); err != nil {
+		return fmt.Errorf("resuming container: %w", err)
+	}
+	return nil
+}
+
+// displayStatus returns the container status for messages, describing a
+// missing container as "not found".
+func displayStatus(status string) string {
+	if status == "" {
+		return "not found"
+	}
+	return status
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPauseCommandsRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "pause")
+	assertCommandOnRoot(t, "resume")
+}
+
+func TestSetPaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      string
+		pause       bool
+		wantPause   int
+		wantUnpause int
+		wantErr     string
+	}{
+		{name: "pause running", status: "running", pause: true, wantPause: 1},
+		{name: "pause paused", status: "paused", pause: true, wantErr: "is not running (status: paused)"},
+		{name: "pause exited", status: "exited", pause: true, wantErr: "is not running (status: exited)"},
+		{name: "pause missing", status: "", pause: true, wantErr: "is not running (status: not found)"},
+		{name: "resume paused", status: "paused", wantUnpause: 1},
+		{name: "resume running", status: "running", wantErr: "is not paused (status: running)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &fakeRuntime{status: tt.status}
+			err := setPaused(context.Background(), rt, "dev", "klausctl-dev", tt.pause)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if rt.pauseCalls != tt.wantPause || rt.unpauseCalls != tt.wantUnpause {
+				t.Errorf("pause calls = %d, unpause calls = %d, want %d and %d", rt.pauseCalls, rt.unpauseCalls, tt.wantPause, tt.wantUnpause)
+			}
+		})
+	}
+}
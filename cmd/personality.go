@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 
 	klausoci "github.com/giantswarm/klaus-oci"
@@ -30,9 +31,14 @@ var (
 	personalityListLocal           bool
 	personalityListSource          string
 	personalityListAll             bool
+	personalityListFilter          string
+	personalityListFormat          string
 	personalityDescribeOut         string
 	personalityDescribeSource      string
 	personalityDescribeDeps        bool
+	personalityDescribeNoResolve   bool
+	personalityDescribeGraph       bool
+	personalityDescribeDepth       int
 )
 
 var personalityCmd = &cobra.Command{
@@ -97,7 +103,11 @@ var personalityListCmd = &cobra.Command{
 By default, discovers personalities from the registry, shows the latest version
 of each, and indicates whether it is cached locally.
 
-With --local, shows only locally cached personalities with full detail.`,
+With --local, shows only locally cached personalities with full detail.
+
+--format prints each personality with a Go template instead of the table, e.g.
+'{{.Name}}\t{{.Ref}}'. The fields are Name, Ref and PulledAt, plus Digest
+with --local and Source when listing several sources.`,
 	RunE: runPersonalityList,
 }
 
@@ -113,7 +123,22 @@ Accepts a short name, short name with tag, or full OCI reference:
   klausctl personality describe gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v0.2.0
 
 Dependencies are resolved automatically in text mode. Use --no-deps to skip.
-In JSON mode, pass --deps to include resolved dependency metadata.`,
+In JSON mode, pass --deps to include resolved dependency metadata.
+
+--resolve-depth limits how far dependencies are followed: 0 resolves none,
+1 the toolchain and plugins the personality references, and 2 (the default)
+also those inherited from the chain of base personalities it extends.
+Dependencies that cannot be resolved are reported as warnings.
+
+Use --no-resolve to print the toolchain and plugin references exactly as
+declared in the artifact, without resolving dependencies. This shows what
+was actually published.
+
+Use --graph to render the resolved dependencies as a tree of the toolchain and
+plugins, or --output dot to render them in Graphviz dot syntax:
+
+  klausctl personality describe sre --graph
+  klausctl personality describe sre -o dot | dot -Tsvg > sre.svg`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPersonalityDescribe,
 }
@@ -140,9 +165,15 @@ func init() {
 	personalityListCmd.Flags().BoolVar(&personalityListLocal, "local", false, "list only locally cached personalities")
 	personalityListCmd.Flags().StringVar(&personalityListSource, "source", "", "list personalities from a specific source only")
 	personalityListCmd.Flags().BoolVar(&personalityListAll, "all", false, "list personalities from all configured sources")
-	personalityDescribeCmd.Flags().StringVarP(&personalityDescribeOut, "output", "o", "text", "output format: text, json")
+	personalityListCmd.Flags().StringVar(&personalityListFilter, "filter", "", "only list personalities whose short name matches this glob (e.g. 'gs-*')")
+	personalityListCmd.Flags().StringVar(&personalityListFormat, "format", "", "format each personality with a Go template (e.g. '{{.Name}}')")
+	personalityDescribeCmd.Flags().StringVarP(&personalityDescribeOut, "output", "o", "text", "output format: text, json, dot (dependency graph for Graphviz)")
+	_ = personalityDescribeCmd.RegisterFlagCompletionFunc("output", completeValues(append(slices.Clone(validOutputFormats), outputDot)...))
 	personalityDescribeCmd.Flags().StringVar(&personalityDescribeSource, "source", "", "resolve against a specific source")
 	personalityDescribeCmd.Flags().BoolVar(&personalityDescribeDeps, "deps", false, "resolve and display dependency metadata (default: auto for text, off for json)")
+	personalityDescribeCmd.Flags().BoolVar(&personalityDescribeNoResolve, "no-resolve", false, "show declared toolchain and plugin references verbatim without resolving dependencies")
+	personalityDescribeCmd.Flags().BoolVar(&personalityDescribeGraph, "graph", false, "show the resolved dependencies as a tree")
+	personalityDescribeCmd.Flags().IntVar(&personalityDescribeDepth, "resolve-depth", orchestrator.ResolveDepthTransitive, "how deep to resolve dependencies: 0 none, 1 direct, 2 transitive (implies --deps when set)")
 
 	personalityCmd.AddCommand(personalityValidateCmd)
 	personalityCmd.AddCommand(personalityPullCmd)
@@ -241,15 +272,21 @@ func resolvePersonalityDeps(ctx context.Context, spec klausoci.Personality, reso
 	var errs []string
 
 	if spec.Toolchain.Repository != "" {
-		ref := resolver.ResolveToolchainRef(spec.Toolchain.Ref())
-		if _, err := client.ResolveToolchainRef(ctx, ref); err != nil {
+		ref, err := resolver.ResolveToolchainRef(spec.Toolchain.Ref())
+		if err == nil {
+			_, err = client.ResolveToolchainRef(ctx, ref)
+		}
+		if err != nil {
 			errs = append(errs, fmt.Sprintf("resolving toolchain %s: %v", spec.Toolchain.Ref(), err))
 		}
 	}
 
 	for _, p := range spec.Plugins {
-		ref := resolver.ResolvePluginRef(p.Ref())
-		if _, err := client.ResolvePluginRef(ctx, ref); err != nil {
+		ref, err := resolver.ResolvePluginRef(p.Ref())
+		if err == nil {
+			_, err = client.ResolvePluginRef(ctx, ref)
+		}
+		if err != nil {
 			errs = append(errs, fmt.Sprintf("resolving plugin %s: %v", p.Ref(), err))
 		}
 	}
@@ -305,7 +342,10 @@ func runPersonalityPush(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ref := resolver.ResolvePersonalityRef(args[1])
+	ref, err := resolver.ResolvePersonalityRef(args[1])
+	if err != nil {
+		return err
+	}
 	if err := validatePushRef(ref); err != nil {
 		return err
 	}
@@ -335,20 +375,27 @@ func runPersonalityPull(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resolved := resolver.ResolvePersonalityRef(args[0])
+	resolved, err := resolver.ResolvePersonalityRef(args[0])
+	if err != nil {
+		return err
+	}
 	client := orchestrator.NewDefaultClient()
 	ref, err := client.ResolvePersonalityRef(ctx, resolved)
 	if err != nil {
 		return err
 	}
 
-	return pullArtifact(ctx, ref, paths.PersonalitiesDir, pullPersonalityFn, cmd.OutOrStdout(), personalityPullOut)
+	return pullArtifact(ctx, ref, paths.PersonalitiesDir, pullPersonalityFn, cmd.OutOrStdout(), cmd.ErrOrStderr(), personalityPullOut)
 }
 
 func runPersonalityList(cmd *cobra.Command, _ []string) error {
 	if err := validateOutputFormat(personalityListOut); err != nil {
 		return err
 	}
+	rowFormat, err := parseRowFormat(personalityListFormat, personalityListOut)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
@@ -363,12 +410,36 @@ func runPersonalityList(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	return listOCIArtifacts(ctx, cmd.OutOrStdout(), paths.PersonalitiesDir, personalityListOut, "personality", "personalities", resolver.PersonalityRegistries(), personalityListLocal, listPersonalitiesFn)
+	return listOCIArtifacts(ctx, cmd.OutOrStdout(), paths.PersonalitiesDir, personalityListOut, rowFormat, "personality", "personalities", resolver.PersonalityRegistries(), personalityListLocal, personalityListFilter, listPersonalitiesFn)
 }
 
 func runPersonalityDescribe(cmd *cobra.Command, args []string) error {
-	if err := validateOutputFormat(personalityDescribeOut); err != nil {
-		return err
+	graph := personalityDescribeGraph || personalityDescribeOut == outputDot
+	if personalityDescribeOut != outputDot {
+		if err := validateOutputFormat(personalityDescribeOut); err != nil {
+			return err
+		}
+	}
+	if personalityDescribeNoResolve && personalityDescribeDeps {
+		return fmt.Errorf("--no-resolve and --deps are mutually exclusive")
+	}
+	if personalityDescribeGraph && personalityDescribeOut == "json" {
+		return fmt.Errorf("--graph cannot be used with --output json")
+	}
+	if graph && personalityDescribeNoResolve {
+		return fmt.Errorf("the dependency graph needs resolved dependencies and cannot be used with --no-resolve")
+	}
+	depthSet := cmd.Flags().Changed("resolve-depth")
+	if depthSet {
+		if err := orchestrator.ValidateResolveDepth(personalityDescribeDepth); err != nil {
+			return err
+		}
+		if personalityDescribeNoResolve && personalityDescribeDepth > orchestrator.ResolveDepthNone {
+			return fmt.Errorf("--no-resolve and --resolve-depth are mutually exclusive")
+		}
+		if graph && personalityDescribeDepth == orchestrator.ResolveDepthNone {
+			return fmt.Errorf("the dependency graph needs resolved dependencies and cannot be used with --resolve-depth 0")
+		}
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -379,7 +450,10 @@ func runPersonalityDescribe(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ref := resolver.ResolvePersonalityRef(args[0])
+	ref, err := resolver.ResolvePersonalityRef(args[0])
+	if err != nil {
+		return err
+	}
 	client := orchestrator.NewDefaultClient()
 	dp, err := client.DescribePersonality(ctx, ref)
 	if err != nil {
@@ -387,28 +461,49 @@ func runPersonalityDescribe(cmd *cobra.Command, args []string) error {
 	}
 
 	resolveDeps := personalityDescribeDeps
-	if !cmd.Flags().Changed("deps") && personalityDescribeOut != "json" {
+	if !cmd.Flags().Changed("deps") && personalityDescribeOut != "json" && !personalityDescribeNoResolve {
+		resolveDeps = true
+	}
+	if graph {
 		resolveDeps = true
 	}
 
-	var deps *klausoci.ResolvedDependencies
+	depth := orchestrator.ResolveDepthNone
 	if resolveDeps {
-		deps, err = client.ResolvePersonalityDeps(ctx, dp.Personality)
-		if err != nil {
-			return fmt.Errorf("resolving dependencies: %w", err)
-		}
+		depth = orchestrator.ResolveDepthTransitive
+	}
+	if depthSet {
+		depth = personalityDescribeDepth
+	}
+	deps, err := orchestrator.ResolvePersonalityDeps(ctx, orchestrator.NewPersonalityDepResolver(client, resolver), dp.Ref, dp.Personality, depth)
+	if err != nil {
+		return fmt.Errorf("resolving dependencies: %w", err)
 	}
 
 	out := cmd.OutOrStdout()
 
+	if graph {
+		printPersonalityGraph(out, cmd.ErrOrStderr(), personalityDescribeOut == outputDot, dp, deps)
+		return nil
+	}
+
 	if personalityDescribeOut == "json" {
+		result := newDescribePersonalityJSON(dp, deps)
+		if personalityDescribeNoResolve {
+			result.Toolchain, result.Plugins = declaredPersonalityRefs(dp)
+		}
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
-		return enc.Encode(newDescribePersonalityJSON(dp, deps))
+		return enc.Encode(result)
 	}
 
 	printArtifactMeta(out, metaFromPersonality(dp))
 
+	if personalityDescribeNoResolve {
+		printDeclaredRefs(out, dp)
+		return nil
+	}
+
 	if dp.Toolchain.Repository != "" {
 		_, _ = fmt.Fprintln(out)
 		_, _ = fmt.Fprintf(out, "%-14s %s\n", "Toolchain:", dp.Toolchain.Ref())
@@ -428,6 +523,51 @@ func runPersonalityDescribe(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// declaredPersonalityRefs returns the toolchain and plugin references of a
+// personality exactly as authored: no short-name expansion and no default
+// tag is applied.
+func declaredPersonalityRefs(dp *klausoci.DescribedPersonality) (toolchain string, plugins []string) {
+	if dp.Toolchain.Repository != "" {
+		toolchain = declaredRef(dp.Toolchain.Repository, dp.Toolchain.Tag, "")
+	}
+	for _, p := range dp.Plugins {
+		plugins = append(plugins, declaredRef(p.Repository, p.Tag, p.Digest))
+	}
+	return toolchain, plugins
+}
+
+// declaredRef joins the parts of a declared reference without normalizing
+// any of them.
+func declaredRef(repository, tag, digest string) string {
+	ref := repository
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if digest != "" {
+		ref += "@" + digest
+	}
+	return ref
+}
+
+// printDeclaredRefs prints the unresolved toolchain and plugin references
+// of a personality.
+func printDeclaredRefs(out io.Writer, dp *klausoci.DescribedPersonality) {
+	toolchain, plugins := declaredPersonalityRefs(dp)
+	if toolchain != "" {
+		_, _ = fmt.Fprintln(out)
+		_, _ = fmt.Fprintf(out, "%-14s %s\n", "Toolchain:", toolchain)
+	}
+	if len(plugins) > 0 {
+		_, _ = fmt.Fprintln(out)
+		_, _ = fmt.Fprintln(out, "Plugins:")
+		for _, p := range plugins {
+			_, _ = fmt.Fprintf(out, "  - %s\n", p)
+		}
+	}
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, "References are shown as declared; dependencies were not resolved.")
+}
+
 // printResolvedDeps prints resolved dependency metadata for a personality.
 func printResolvedDeps(out io.Writer, deps *klausoci.ResolvedDependencies) {
 	if deps == nil {
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// outputDot is the personality describe output format that renders the
+// dependency graph in Graphviz dot syntax.
+const outputDot = "dot"
+
+// depNode is a node of a personality dependency graph.
+type depNode struct {
+	// kind is "personality", "toolchain" or "plugin".
+	kind     string
+	name     string
+	version  string
+	children []depNode
+}
+
+// label returns the kind, name and version of the node on one line.
+func (n depNode) label() string {
+	return strings.TrimSpace(strings.Join([]string{n.kind, n.name, n.version}, " "))
+}
+
+// personalityDepGraph builds the dependency graph of a personality from its
+// resolved dependencies: the personality is the root, with the toolchain and
+// each plugin as children. Dependencies that failed to resolve are not part
+// of the graph; they are reported in deps.Warnings.
+func personalityDepGraph(dp *klausoci.DescribedPersonality, deps *klausoci.ResolvedDependencies) depNode {
+	root := depNode{kind: "personality", name: dp.Name, version: dp.Version}
+	if root.name == "" {
+		root.name = dp.Ref
+	}
+	if deps == nil {
+		return root
+	}
+	if tc := deps.Toolchain; tc != nil {
+		root.children = append(root.children, depNode{kind: "toolchain", name: depName(tc.Name, tc.Ref), version: tc.Version})
+	}
+	for _, p := range deps.Plugins {
+		root.children = append(root.children, depNode{kind: "plugin", name: depName(p.Name, p.Ref), version: p.Version})
+	}
+	return root
+}
+
+// depName returns the artifact name, falling back to its reference for
+// artifacts without a name annotation.
+func depName(name, ref string) string {
+	if name != "" {
+		return name
+	}
+	return ref
+}
+
+// printDepTree renders the graph below root as an indented ASCII tree.
+func printDepTree(out io.Writer, root depNode) {
+	_, _ = fmt.Fprintln(out, root.label())
+	printDepChildren(out, root.children, "")
+}
+
+func printDepChildren(out io.Writer, children []depNode, prefix string) {
+	for i, child := range children {
+		branch, indent := "|-- ", "|   "
+		if i == len(children)-1 {
+			branch, indent = "`-- ", "    "
+		}
+		_, _ = fmt.Fprintf(out, "%s%s%s\n", prefix, branch, child.label())
+		printDepChildren(out, child.children, prefix+indent)
+	}
+}
+
+// printDepDot renders the graph below root as a Graphviz digraph, e.g. for
+// `klausctl personality describe sre -o dot | dot -Tsvg > sre.svg`.
+func printDepDot(out io.Writer, root depNode) {
+	_, _ = fmt.Fprintln(out, "digraph dependencies {")
+	_, _ = fmt.Fprintln(out, "  rankdir=LR;")
+	_, _ = fmt.Fprintln(out, "  node [shape=box];")
+	next := 0
+	writeDotNode(out, root, &next)
+	_, _ = fmt.Fprintln(out, "}")
+}
+
+// writeDotNode writes n and its subtree, numbering nodes in depth-first
+// order so that artifacts appearing twice get separate nodes. It returns the
+// id of n.
+func writeDotNode(out io.Writer, n depNode, next *int) string {
+	id := fmt.Sprintf("n%d", *next)
+	*next++
+	label := n.kind + "\n" + strings.TrimSpace(n.name+" "+n.version)
+	_, _ = fmt.Fprintf(out, "  %s [label=%s];\n", id, strconv.Quote(label))
+	for _, child := range n.children {
+		childID := writeDotNode(out, child, next)
+		_, _ = fmt.Fprintf(out, "  %s -> %s;\n", id, childID)
+	}
+	return id
+}
+
+// printPersonalityGraph renders the dependency graph of a personality as dot
+// or as an ASCII tree. Resolution warnings go to errOut in dot mode so the
+// graph can be piped straight into Graphviz.
+func printPersonalityGraph(out, errOut io.Writer, dot bool, dp *klausoci.DescribedPersonality, deps *klausoci.ResolvedDependencies) {
+	root := personalityDepGraph(dp, deps)
+	warnOut := out
+	if dot {
+		printDepDot(out, root)
+		warnOut = errOut
+	} else {
+		printDepTree(out, root)
+	}
+	if deps == nil {
+		return
+	}
+	for _, w := range deps.Warnings {
+		_, _ = fmt.Fprintf(warnOut, "\nWarning: %s\n", w)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+func graphTestPersonality() (*klausoci.DescribedPersonality, *klausoci.ResolvedDependencies) {
+	dp := &klausoci.DescribedPersonality{
+		ArtifactInfo: klausoci.ArtifactInfo{Ref: "example.com/sre:v0.2.0"},
+		Personality:  klausoci.Personality{Name: "sre", Version: "v0.2.0"},
+	}
+	deps := &klausoci.ResolvedDependencies{
+		Toolchain: &klausoci.DescribedToolchain{
+			ArtifactInfo: klausoci.ArtifactInfo{Ref: "example.com/go:v1.0.0"},
+			Toolchain:    klausoci.Toolchain{Name: "go", Version: "v1.0.0"},
+		},
+		Plugins: []klausoci.DescribedPlugin{
+			{
+				ArtifactInfo: klausoci.ArtifactInfo{Ref: "example.com/gs-base:v0.1.0"},
+				Plugin:       klausoci.Plugin{Name: "gs-base", Version: "v0.1.0"},
+			},
+			{
+				ArtifactInfo: klausoci.ArtifactInfo{Ref: "example.com/gs-sre:v0.3.0"},
+				Plugin:       klausoci.Plugin{Name: "gs-sre", Version: "v0.3.0"},
+			},
+		},
+		Warnings: []string{"plugin gs-kube: not found"},
+	}
+	return dp, deps
+}
+
+func TestPersonalityDescribeGraphFlag(t *testing.T) {
+	assertFlagRegistered(t, personalityDescribeCmd, "graph")
+}
+
+func TestPrintDepTree(t *testing.T) {
+	dp, deps := graphTestPersonality()
+
+	var buf bytes.Buffer
+	printPersonalityGraph(&buf, &buf, false, dp, deps)
+
+	want := "personality sre v0.2.0\n" +
+		"|-- toolchain go v1.0.0\n" +
+		"|-- plugin gs-base v0.1.0\n" +
+		"`-- plugin gs-sre v0.3.0\n" +
+		"\nWarning: plugin gs-kube: not found\n"
+	if buf.String() != want {
+		t.Errorf("tree =\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestPrintDepTreeNested(t *testing.T) {
+	root := depNode{kind: "personality", name: "sre", children: []depNode{
+		{kind: "plugin", name: "a", children: []depNode{{kind: "plugin", name: "b"}}},
+		{kind: "plugin", name: "c"},
+	}}
+
+	var buf bytes.Buffer
+	printDepTree(&buf, root)
+
+	want := "personality sre\n" +
+		"|-- plugin a\n" +
+		"|   `-- plugin b\n" +
+		"`-- plugin c\n"
+	if buf.String() != want {
+		t.Errorf("tree =\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestPrintDepDot(t *testing.T) {
+	dp, deps := graphTestPersonality()
+
+	var out, errOut bytes.Buffer
+	printPersonalityGraph(&out, &errOut, true, dp, deps)
+	dot := out.String()
+
+	if !strings.HasPrefix(dot, "digraph dependencies {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("expected a digraph, got:\n%s", dot)
+	}
+	if strings.Contains(dot, "Warning") {
+		t.Errorf("expected warnings to stay out of the dot output, got:\n%s", dot)
+	}
+	if !strings.Contains(errOut.String(), "plugin gs-kube: not found") {
+		t.Errorf("expected warning on stderr, got: %q", errOut.String())
+	}
+
+	// Every line between the braces is a statement, node or edge, and every
+	// edge connects declared nodes.
+	nodeRe := regexp.MustCompile(`^  (n\d+) \[label="(.*)"\];$`)
+	edgeRe := regexp.MustCompile(`^  (n\d+) -> (n\d+);$`)
+	nodes := map[string]string{}
+	var edges [][2]string
+	lines := strings.Split(strings.TrimSuffix(dot, "\n"), "\n")
+	for _, line := range lines[1 : len(lines)-1] {
+		switch {
+		case line == "  rankdir=LR;" || line == "  node [shape=box];":
+		case nodeRe.MatchString(line):
+			m := nodeRe.FindStringSubmatch(line)
+			nodes[m[1]] = m[2]
+		case edgeRe.MatchString(line):
+			m := edgeRe.FindStringSubmatch(line)
+			edges = append(edges, [2]string{m[1], m[2]})
+		default:
+			t.Errorf("unexpected dot statement %q", line)
+		}
+	}
+	for _, e := range edges {
+		if _, ok := nodes[e[0]]; !ok {
+			t.Errorf("edge from undeclared node %s", e[0])
+		}
+		if _, ok := nodes[e[1]]; !ok {
+			t.Errorf("edge to undeclared node %s", e[1])
+		}
+	}
+
+	if len(nodes) != 4 || len(edges) != 3 {
+		t.Fatalf("got %d nodes and %d edges, want 4 and 3:\n%s", len(nodes), len(edges), dot)
+	}
+	labels := strings.Join([]string{nodes["n0"], nodes["n1"], nodes["n2"], nodes["n3"]}, "|")
+	for _, want := range []string{`personality\nsre v0.2.0`, `toolchain\ngo v1.0.0`, `plugin\ngs-base v0.1.0`, `plugin\ngs-sre v0.3.0`} {
+		if !strings.Contains(labels, want) {
+			t.Errorf("missing node %q in %s", want, labels)
+		}
+	}
+	for _, e := range edges {
+		if e[0] != "n0" {
+			t.Errorf("expected all dependencies to hang off the personality, got edge %v", e)
+		}
+	}
+}
@@ -146,9 +146,12 @@ func TestPersonalityFlagsRegistered(t *testing.T) {
 	assertFlagRegistered(t, personalityPushCmd, "dry-run")
 	assertFlagRegistered(t, personalityListCmd, "output")
 	assertFlagRegistered(t, personalityListCmd, "local")
+	assertFlagRegistered(t, personalityListCmd, "filter")
+	assertFlagRegistered(t, personalityDescribeCmd, "resolve-depth")
 	assertFlagRegistered(t, personalityDescribeCmd, "output")
 	assertFlagRegistered(t, personalityDescribeCmd, "source")
 	assertFlagRegistered(t, personalityDescribeCmd, "deps")
+	assertFlagRegistered(t, personalityDescribeCmd, "no-resolve")
 }
 
 func TestValidatePersonalityDepsNoDeps(t *testing.T) {
@@ -200,6 +203,45 @@ func TestResolvePersonalityDepsCollectsAllErrors(t *testing.T) {
 	}
 }
 
+func TestDeclaredPersonalityRefs(t *testing.T) {
+	dp := &klausoci.DescribedPersonality{
+		Personality: klausoci.Personality{
+			Name:      "sre",
+			Toolchain: klausoci.ToolchainReference{Repository: "go"},
+			Plugins: []klausoci.PluginReference{
+				{Repository: "gs-base", Tag: "v0.1.0"},
+				{Repository: "example.com/plugins/gs-sre", Digest: "sha256:abc"},
+			},
+		},
+	}
+
+	toolchain, plugins := declaredPersonalityRefs(dp)
+	if toolchain != "go" {
+		t.Errorf("toolchain = %q, want %q (unresolved)", toolchain, "go")
+	}
+	want := []string{"gs-base:v0.1.0", "example.com/plugins/gs-sre@sha256:abc"}
+	if len(plugins) != len(want) {
+		t.Fatalf("plugins = %v, want %v", plugins, want)
+	}
+	for i := range want {
+		if plugins[i] != want[i] {
+			t.Errorf("plugins[%d] = %q, want %q", i, plugins[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	printDeclaredRefs(&buf, dp)
+	output := buf.String()
+	for _, s := range []string{"Toolchain:     go\n", "  - gs-base:v0.1.0\n", "not resolved"} {
+		if !strings.Contains(output, s) {
+			t.Errorf("output missing %q\ngot:\n%s", s, output)
+		}
+	}
+	if strings.Contains(output, "Resolved") {
+		t.Errorf("expected no resolved dependency output, got:\n%s", output)
+	}
+}
+
 func TestPrintResolvedDeps(t *testing.T) {
 	deps := &klausoci.ResolvedDependencies{
 		Toolchain: &klausoci.DescribedToolchain{
@@ -16,19 +16,28 @@ import (
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/orchestrator"
+	"github.com/giantswarm/klausctl/pkg/signing"
 )
 
 var (
 	pluginValidateOut    string
 	pluginPullOut        string
 	pluginPullSource     string
+	pluginPullVerify     bool
+	pluginPullKey        string
+	pluginPullIdentity   string
+	pluginPullIssuer     string
 	pluginPushOut        string
 	pluginPushSource     string
 	pluginPushDryRun     bool
+	pluginPushSign       bool
+	pluginPushKey        string
 	pluginListOut        string
 	pluginListLocal      bool
 	pluginListSource     string
 	pluginListAll        bool
+	pluginListFilter     string
+	pluginListFormat     string
 	pluginDescribeOut    string
 	pluginDescribeSource string
 )
@@ -67,7 +76,17 @@ Accepts a short name, short name with tag, or full OCI reference:
 
   klausctl plugin pull gs-base              (resolves latest version)
   klausctl plugin pull gs-base:v0.0.7       (specific version)
-  klausctl plugin pull gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v0.0.7`,
+  klausctl plugin pull gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v0.0.7
+
+With --verify-signature the plugin's cosign signature is checked before it
+is pulled, against the public key given with --key or, without a key, a
+keyless Sigstore signature issued to --certificate-identity by
+--certificate-oidc-issuer; both are required for keyless verification. The
+verified digest is pulled. Requires cosign.
+
+  klausctl plugin pull gs-base --verify-signature \
+    --certificate-identity https://github.com/giantswarm/klaus-plugins/.github/workflows/release.yaml@refs/heads/main \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPluginPull,
 }
@@ -83,7 +102,13 @@ commands/, or .mcp.json) and a .claude-plugin/plugin.json manifest.
 Accepts a full OCI reference with tag or a short name with tag:
 
   klausctl plugin push ./my-plugin gs-base:v1.0.0
-  klausctl plugin push ./my-plugin gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v1.0.0`,
+  klausctl plugin push ./my-plugin gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v1.0.0
+
+With --sign the pushed artifact is signed with cosign and the signature is
+stored in the registry: keyless through the Sigstore OIDC flow, or with the
+private key given with --key (a file or KMS URI). Requires cosign.
+
+  klausctl plugin push ./my-plugin gs-base:v1.0.0 --sign --key cosign.key`,
 	Args: cobra.ExactArgs(2),
 	RunE: runPluginPush,
 }
@@ -96,7 +121,11 @@ var pluginListCmd = &cobra.Command{
 By default, discovers plugins from the registry, shows the latest version
 of each, and indicates whether it is cached locally.
 
-With --local, shows only locally cached plugins with full detail.`,
+With --local, shows only locally cached plugins with full detail.
+
+--format prints each plugin with a Go template instead of the table, e.g.
+'{{.Name}}\t{{.Ref}}'. The fields are Name, Ref and PulledAt, plus Digest
+with --local and Source when listing several sources.`,
 	RunE: runPluginList,
 }
 
@@ -125,13 +154,21 @@ func init() {
 	pluginValidateCmd.Flags().StringVarP(&pluginValidateOut, "output", "o", "text", "output format: text, json")
 	pluginPullCmd.Flags().StringVarP(&pluginPullOut, "output", "o", "text", "output format: text, json")
 	pluginPullCmd.Flags().StringVar(&pluginPullSource, "source", "", "resolve against a specific source")
+	pluginPullCmd.Flags().BoolVar(&pluginPullVerify, "verify-signature", false, "verify the cosign signature before pulling")
+	pluginPullCmd.Flags().StringVar(&pluginPullKey, "key", "", "public key to verify the signature against (default: keyless)")
+	pluginPullCmd.Flags().StringVar(&pluginPullIdentity, "certificate-identity", "", "identity a keyless signature must be issued to")
+	pluginPullCmd.Flags().StringVar(&pluginPullIssuer, "certificate-oidc-issuer", "", "OIDC issuer a keyless signature must come from")
 	pluginPushCmd.Flags().StringVarP(&pluginPushOut, "output", "o", "text", "output format: text, json")
 	pluginPushCmd.Flags().StringVar(&pluginPushSource, "source", "", "use a specific source registry for the push destination")
 	pluginPushCmd.Flags().BoolVar(&pluginPushDryRun, "dry-run", false, "validate and resolve without pushing")
+	pluginPushCmd.Flags().BoolVar(&pluginPushSign, "sign", false, "sign the pushed artifact with cosign")
+	pluginPushCmd.Flags().StringVar(&pluginPushKey, "key", "", "private key to sign with, a file or KMS URI (default: keyless)")
 	pluginListCmd.Flags().StringVarP(&pluginListOut, "output", "o", "text", "output format: text, json")
 	pluginListCmd.Flags().BoolVar(&pluginListLocal, "local", false, "list only locally cached plugins")
 	pluginListCmd.Flags().StringVar(&pluginListSource, "source", "", "list plugins from a specific source only")
 	pluginListCmd.Flags().BoolVar(&pluginListAll, "all", false, "list plugins from all configured sources")
+	pluginListCmd.Flags().StringVar(&pluginListFilter, "filter", "", "only list plugins whose short name matches this glob (e.g. 'gs-*')")
+	pluginListCmd.Flags().StringVar(&pluginListFormat, "format", "", "format each plugin with a Go template (e.g. '{{.Name}}')")
 	pluginDescribeCmd.Flags().StringVarP(&pluginDescribeOut, "output", "o", "text", "output format: text, json")
 	pluginDescribeCmd.Flags().StringVar(&pluginDescribeSource, "source", "", "resolve against a specific source")
 
@@ -190,14 +227,9 @@ func validatePluginDir(dir string, out io.Writer, outputFmt string) error {
 	return nil
 }
 
-// pullPluginFn wraps the typed PullPlugin method for use with pullArtifact.
-var pullPluginFn pullFn = func(ctx context.Context, client *klausoci.Client, ref, destDir string) (string, bool, error) {
-	result, err := client.PullPlugin(ctx, ref, destDir)
-	if err != nil {
-		return "", false, err
-	}
-	return result.Digest, result.Cached, nil
-}
+// pullPluginFn pulls a plugin into the shared plugin cache for use with
+// pullArtifact.
+var pullPluginFn pullFn = orchestrator.PullPlugin
 
 // listPluginsFn wraps the typed ListPlugins method for use with listLatestRemoteArtifacts.
 var listPluginsFn listFn = func(ctx context.Context, client *klausoci.Client, opts ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
@@ -221,6 +253,9 @@ func runPluginPush(cmd *cobra.Command, args []string) error {
 	if err := validateOutputFormat(pluginPushOut); err != nil {
 		return err
 	}
+	if pluginPushKey != "" && !pluginPushSign {
+		return fmt.Errorf("--key requires --sign")
+	}
 
 	dir := args[0]
 	if err := validatePluginDir(dir, io.Discard, "text"); err != nil {
@@ -235,18 +270,31 @@ func runPluginPush(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ref := resolver.ResolvePluginRef(args[1])
+	ref, err := resolver.ResolvePluginRef(args[1])
+	if err != nil {
+		return err
+	}
 	if err := validatePushRef(ref); err != nil {
 		return err
 	}
 
-	return pushArtifact(ctx, dir, ref, pushPluginFn, cmd.OutOrStdout(), pluginPushOut, pushOpts{dryRun: pluginPushDryRun})
+	opts := pushOpts{dryRun: pluginPushDryRun}
+	if pluginPushSign {
+		opts.signer = signing.Cosign{Key: pluginPushKey, Output: cmd.ErrOrStderr()}
+	}
+	return pushArtifact(ctx, dir, ref, pushPluginFn, cmd.OutOrStdout(), pluginPushOut, opts)
 }
 
 func runPluginPull(cmd *cobra.Command, args []string) error {
 	if err := validateOutputFormat(pluginPullOut); err != nil {
 		return err
 	}
+	if (pluginPullKey != "" || pluginPullIdentity != "" || pluginPullIssuer != "") && !pluginPullVerify {
+		return fmt.Errorf("--key, --certificate-identity and --certificate-oidc-issuer require --verify-signature")
+	}
+	if pluginPullVerify && pluginPullKey == "" && (pluginPullIdentity == "" || pluginPullIssuer == "") {
+		return fmt.Errorf("--verify-signature requires --key or both --certificate-identity and --certificate-oidc-issuer")
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
@@ -265,20 +313,38 @@ func runPluginPull(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	resolved := resolver.ResolvePluginRef(args[0])
+	resolved, err := resolver.ResolvePluginRef(args[0])
+	if err != nil {
+		return err
+	}
 	client := orchestrator.NewDefaultClient()
 	ref, err := client.ResolvePluginRef(ctx, resolved)
 	if err != nil {
 		return err
 	}
+	if pluginPullVerify {
+		ref, err = verifyArtifact(ctx, client, signing.Cosign{
+			Key:      pluginPullKey,
+			Identity: pluginPullIdentity,
+			Issuer:   pluginPullIssuer,
+			Output:   cmd.ErrOrStderr(),
+		}, ref)
+		if err != nil {
+			return err
+		}
+	}
 
-	return pullArtifact(ctx, ref, paths.PluginsDir, pullPluginFn, cmd.OutOrStdout(), pluginPullOut)
+	return pullArtifact(ctx, ref, paths.PluginsDir, pullPluginFn, cmd.OutOrStdout(), cmd.ErrOrStderr(), pluginPullOut)
 }
 
 func runPluginList(cmd *cobra.Command, _ []string) error {
 	if err := validateOutputFormat(pluginListOut); err != nil {
 		return err
 	}
+	rowFormat, err := parseRowFormat(pluginListFormat, pluginListOut)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
@@ -293,7 +359,7 @@ func runPluginList(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	return listOCIArtifacts(ctx, cmd.OutOrStdout(), paths.PluginsDir, pluginListOut, "plugin", "plugins", resolver.PluginRegistries(), pluginListLocal, listPluginsFn)
+	return listOCIArtifacts(ctx, cmd.OutOrStdout(), paths.PluginsDir, pluginListOut, rowFormat, "plugin", "plugins", resolver.PluginRegistries(), pluginListLocal, pluginListFilter, listPluginsFn)
 }
 
 func runPluginDescribe(cmd *cobra.Command, args []string) error {
@@ -309,7 +375,10 @@ func runPluginDescribe(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ref := resolver.ResolvePluginRef(args[0])
+	ref, err := resolver.ResolvePluginRef(args[0])
+	if err != nil {
+		return err
+	}
 	client := orchestrator.NewDefaultClient()
 	dp, err := client.DescribePlugin(ctx, ref)
 	if err != nil {
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var (
+	pluginDiffOut    string
+	pluginDiffSource string
+)
+
+var pluginDiffCmd = &cobra.Command{
+	Use:   "diff <reference> <reference>",
+	Short: "Compare two plugin versions",
+	Long: `Describe two plugin references and print what changed between them:
+metadata such as version, description and digest, and the added and removed
+skills, commands, agents, MCP servers and LSP servers.
+
+Plugin content is not downloaded. References are resolved as in describe:
+
+  klausctl plugin diff gs-base:v0.1.0 gs-base:v0.2.0`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPluginDiff,
+}
+
+// pluginFieldChange is a changed metadata field of a plugin.
+type pluginFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// pluginListChange lists the entries of a capability that were added and
+// removed.
+type pluginListChange struct {
+	Capability string   `json:"capability"`
+	Added      []string `json:"added,omitempty"`
+	Removed    []string `json:"removed,omitempty"`
+}
+
+// pluginDiff is the result of comparing two plugin references.
+type pluginDiff struct {
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	Metadata     []pluginFieldChange `json:"metadata"`
+	Capabilities []pluginListChange  `json:"capabilities"`
+}
+
+func init() {
+	pluginDiffCmd.Flags().StringVarP(&pluginDiffOut, "output", "o", "text", "output format: text, json")
+	pluginDiffCmd.Flags().StringVar(&pluginDiffSource, "source", "", "resolve against a specific source")
+
+	pluginCmd.AddCommand(pluginDiffCmd)
+}
+
+func runPluginDiff(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(pluginDiffOut); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	resolver, err := buildSourceResolver(pluginDiffSource)
+	if err != nil {
+		return err
+	}
+
+	client := orchestrator.NewDefaultClient()
+	described := make([]describePluginJSON, 0, len(args))
+	for _, arg := range args {
+		ref, err := resolver.ResolvePluginRef(arg)
+		if err != nil {
+			return err
+		}
+		dp, err := client.DescribePlugin(ctx, ref)
+		if err != nil {
+			return err
+		}
+		described = append(described, newDescribePluginJSON(dp))
+	}
+
+	diff := diffPlugins(described[0], described[1])
+	if pluginDiffOut == "json" {
+		return writeJSON(cmd.OutOrStdout(), diff)
+	}
+	printPluginDiff(cmd.OutOrStdout(), diff)
+	return nil
+}
+
+// diffPlugins compares the described metadata and capabilities of two
+// plugins. Fields and capabilities without changes are omitted.
+func diffPlugins(from, to describePluginJSON) pluginDiff {
+	diff := pluginDiff{
+		From:         from.Ref,
+		To:           to.Ref,
+		Metadata:     []pluginFieldChange{},
+		Capabilities: []pluginListChange{},
+	}
+
+	fields := []struct {
+		name     string
+		from, to string
+	}{
+		{"name", from.Name, to.Name},
+		{"version", from.Version, to.Version},
+		{"description", from.Description, to.Description},
+		{"author", from.Author, to.Author},
+		{"homepage", from.Homepage, to.Homepage},
+		{"repository", from.Repository, to.Repository},
+		{"license", from.License, to.License},
+		{"digest", from.Digest, to.Digest},
+		{"hooks", strconv.FormatBool(from.HasHooks), strconv.FormatBool(to.HasHooks)},
+	}
+	for _, f := range fields {
+		if f.from != f.to {
+			diff.Metadata = append(diff.Metadata, pluginFieldChange{Field: f.name, From: f.from, To: f.to})
+		}
+	}
+
+	lists := []struct {
+		name     string
+		from, to []string
+	}{
+		{"keywords", from.Keywords, to.Keywords},
+		{"skills", from.Skills, to.Skills},
+		{"commands", from.Commands, to.Commands},
+		{"agents", from.Agents, to.Agents},
+		{"mcpServers", from.MCPServers, to.MCPServers},
+		{"lspServers", from.LSPServers, to.LSPServers},
+	}
+	for _, l := range lists {
+		change := pluginListChange{
+			Capability: l.name,
+			Added:      missingFrom(l.from, l.to),
+			Removed:    missingFrom(l.to, l.from),
+		}
+		if len(change.Added) > 0 || len(change.Removed) > 0 {
+			diff.Capabilities = append(diff.Capabilities, change)
+		}
+	}
+	return diff
+}
+
+// missingFrom returns the sorted entries of items that are not in base.
+func missingFrom(base, items []string) []string {
+	var missing []string
+	for _, item := range items {
+		if !slices.Contains(base, item) && !slices.Contains(missing, item) {
+			missing = append(missing, item)
+		}
+	}
+	slices.Sort(missing)
+	return missing
+}
+
+// pluginCapabilityLabels are the headings of capabilities in text output, as
+// in plugin describe.
+var pluginCapabilityLabels = map[string]string{
+	"keywords":   "Keywords",
+	"skills":     "Skills",
+	"commands":   "Commands",
+	"agents":     "Agents",
+	"mcpServers": "MCP Servers",
+	"lspServers": "LSP Servers",
+}
+
+// printPluginDiff renders a plugin diff with + and - markers for added and
+// removed entries.
+func printPluginDiff(out io.Writer, diff pluginDiff) {
+	_, _ = fmt.Fprintf(out, "--- %s\n+++ %s\n", diff.From, diff.To)
+	if len(diff.Metadata) == 0 && len(diff.Capabilities) == 0 {
+		_, _ = fmt.Fprintln(out, "\nNo differences.")
+		return
+	}
+
+	if len(diff.Metadata) > 0 {
+		_, _ = fmt.Fprintln(out, "\nMetadata:")
+		for _, c := range diff.Metadata {
+			_, _ = fmt.Fprintf(out, "  %-12s %s -> %s\n", c.Field+":", displayDiffValue(c.From), displayDiffValue(c.To))
+		}
+	}
+	for _, c := range diff.Capabilities {
+		_, _ = fmt.Fprintf(out, "\n%s:\n", pluginCapabilityLabels[c.Capability])
+		for _, name := range c.Added {
+			_, _ = fmt.Fprintf(out, "  %s %s\n", green("+"), name)
+		}
+		for _, name := range c.Removed {
+			_, _ = fmt.Fprintf(out, "  %s %s\n", red("-"), name)
+		}
+	}
+}
+
+// displayDiffValue shows empty metadata values as "(none)".
+func displayDiffValue(v string) string {
+	if v == "" {
+		return "(none)"
+	}
+	return v
+}
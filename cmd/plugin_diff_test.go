@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testPluginVersions() (describePluginJSON, describePluginJSON) {
+	from := describePluginJSON{
+		describeBaseJSON: describeBaseJSON{Name: "gs-base", Version: "v0.1.0", Ref: "example.com/gs-base:v0.1.0", Digest: "sha256:aaa"},
+		Skills:           []string{"k8s", "helm"},
+		Commands:         []string{"deploy"},
+	}
+	to := describePluginJSON{
+		describeBaseJSON: describeBaseJSON{Name: "gs-base", Version: "v0.2.0", Ref: "example.com/gs-base:v0.2.0", Digest: "sha256:bbb"},
+		Skills:           []string{"k8s", "flux"},
+		Commands:         []string{"deploy"},
+		HasHooks:         true,
+		MCPServers:       []string{"github"},
+	}
+	return from, to
+}
+
+func TestDiffPlugins(t *testing.T) {
+	diff := diffPlugins(testPluginVersions())
+
+	wantFields := []string{"version", "digest", "hooks"}
+	if len(diff.Metadata) != len(wantFields) {
+		t.Fatalf("Metadata = %+v, want fields %v", diff.Metadata, wantFields)
+	}
+	for i, f := range wantFields {
+		if diff.Metadata[i].Field != f {
+			t.Errorf("Metadata[%d].Field = %q, want %q", i, diff.Metadata[i].Field, f)
+		}
+	}
+
+	if len(diff.Capabilities) != 2 {
+		t.Fatalf("Capabilities = %+v, want skills and mcpServers", diff.Capabilities)
+	}
+	skills := diff.Capabilities[0]
+	if skills.Capability != "skills" || strings.Join(skills.Added, ",") != "flux" || strings.Join(skills.Removed, ",") != "helm" {
+		t.Errorf("skills change = %+v", skills)
+	}
+	if mcp := diff.Capabilities[1]; mcp.Capability != "mcpServers" || len(mcp.Added) != 1 || len(mcp.Removed) != 0 {
+		t.Errorf("mcpServers change = %+v", mcp)
+	}
+}
+
+func TestDiffPluginsIdentical(t *testing.T) {
+	from, _ := testPluginVersions()
+	diff := diffPlugins(from, from)
+	if len(diff.Metadata) != 0 || len(diff.Capabilities) != 0 {
+		t.Errorf("diff = %+v, want no changes", diff)
+	}
+
+	var out bytes.Buffer
+	printPluginDiff(&out, diff)
+	if !strings.Contains(out.String(), "No differences.") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	var jsonOut bytes.Buffer
+	if err := writeJSON(&jsonOut, diff); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if caps, ok := decoded["capabilities"].([]any); !ok || len(caps) != 0 {
+		t.Errorf("capabilities = %v, want empty list", decoded["capabilities"])
+	}
+}
+
+func TestPrintPluginDiff(t *testing.T) {
+	var out bytes.Buffer
+	printPluginDiff(&out, diffPlugins(testPluginVersions()))
+	got := out.String()
+
+	for _, want := range []string{
+		"--- example.com/gs-base:v0.1.0",
+		"+++ example.com/gs-base:v0.2.0",
+		"v0.1.0 -> v0.2.0",
+		"Skills:",
+		"+ flux",
+		"- helm",
+		"MCP Servers:",
+		"+ github",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Commands:") {
+		t.Errorf("unchanged commands listed:\n%s", got)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+var pluginEnableCmd = &cobra.Command{
+	Use:   "enable <name>",
+	Short: "Enable a disabled plugin of the config",
+	Long: `Enable a plugin of the config file that was disabled with 'klausctl plugin
+disable', so it is pulled and mounted again on the next start. The plugin is
+matched by its short name or full repository.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPluginSetEnabled(cmd, args[0], true)
+	},
+}
+
+var pluginDisableCmd = &cobra.Command{
+	Use:   "disable <name>",
+	Short: "Disable a plugin without removing it from the config",
+	Long: `Keep a plugin in the config file but skip pulling and mounting it, until it
+is enabled again. The plugin is matched by its short name or full repository:
+
+  klausctl plugin disable gs-platform`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPluginSetEnabled(cmd, args[0], false)
+	},
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginEnableCmd)
+	pluginCmd.AddCommand(pluginDisableCmd)
+}
+
+func runPluginSetEnabled(cmd *cobra.Command, name string, enabled bool) error {
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := editConfigFile(path, func(data []byte) ([]byte, error) {
+		return config.SetPluginEnabled(data, name, enabled)
+	}); err != nil {
+		return err
+	}
+	verb := "Disabled"
+	if enabled {
+		verb = "Enabled"
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s %s in %s\n", verb, name, path)
+	return nil
+}
@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var (
+	pluginOutdatedOut   string
+	pluginOutdatedLocal bool
+)
+
+var pluginOutdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "List plugins with a newer version in the registry",
+	Long: `Compare the plugins of the config file with the latest semver tag of their
+repository and list those with a newer version available.
+
+The current version of a plugin is its pinned tag or, for an unpinned plugin,
+the version of its locally cached copy. Plugins locked to a digest and
+unpinned plugins that were never pulled are skipped, since there is nothing
+to compare. With --local the locally cached plugins are checked instead of
+the config file.`,
+	Example: `  klausctl plugin outdated
+  klausctl plugin outdated --local -o json`,
+	Args: cobra.NoArgs,
+	RunE: runPluginOutdated,
+}
+
+// listPluginTags lists the tags of a plugin repository. It is a variable so
+// tests can run without a registry.
+var listPluginTags = func(ctx context.Context, repository string) ([]string, error) {
+	return orchestrator.NewDefaultClient().List(ctx, repository)
+}
+
+// outdatedPlugin is a plugin with a newer version in the registry.
+type outdatedPlugin struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	Current    string `json:"current"`
+	Latest     string `json:"latest"`
+}
+
+// pluginVersion is a plugin repository with the version in use.
+type pluginVersion struct {
+	repository string
+	current    string
+}
+
+func init() {
+	pluginOutdatedCmd.Flags().StringVarP(&pluginOutdatedOut, "output", "o", "text", "output format: text, json")
+	pluginOutdatedCmd.Flags().BoolVar(&pluginOutdatedLocal, "local", false, "check the locally cached plugins instead of the config file")
+
+	pluginCmd.AddCommand(pluginOutdatedCmd)
+}
+
+func runPluginOutdated(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(pluginOutdatedOut); err != nil {
+		return err
+	}
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+
+	var plugins []pluginVersion
+	if pluginOutdatedLocal {
+		plugins, err = cachedPluginVersions(paths.PluginsDir)
+		if err != nil {
+			return err
+		}
+	} else {
+		path, err := resolvedConfigFile()
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		plugins = configPluginVersions(cfg, paths.PluginsDir)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	outdated, checkErr := findOutdatedPlugins(ctx, plugins)
+	if err := writeOutdatedPlugins(cmd.OutOrStdout(), outdated, pluginOutdatedOut); err != nil {
+		return err
+	}
+	return checkErr
+}
+
+// configPluginVersions returns the plugins of cfg with their pinned tag or,
+// when unpinned, the tag of their cached copy in pluginsDir.
+func configPluginVersions(cfg *config.Config, pluginsDir string) []pluginVersion {
+	var plugins []pluginVersion
+	for _, p := range cfg.Plugins {
+		if p.Digest != "" {
+			continue
+		}
+		current := p.Tag
+		if current == "" || current == "latest" {
+			current = ""
+			dir := filepath.Join(pluginsDir, klausoci.ShortName(p.Repository))
+			if entry, err := klausoci.ReadCacheEntry(dir); err == nil {
+				current = refTag(entry.Ref)
+			}
+		}
+		if current == "" {
+			continue
+		}
+		plugins = append(plugins, pluginVersion{repository: p.Repository, current: current})
+	}
+	return plugins
+}
+
+// cachedPluginVersions returns the plugins cached in pluginsDir with the
+// tag they were pulled at.
+func cachedPluginVersions(pluginsDir string) ([]pluginVersion, error) {
+	cached, err := listLocalArtifacts(pluginsDir)
+	if err != nil {
+		return nil, err
+	}
+	var plugins []pluginVersion
+	for _, a := range cached {
+		if tag := refTag(a.Ref); tag != "" {
+			plugins = append(plugins, pluginVersion{repository: klausoci.RepositoryFromRef(a.Ref), current: tag})
+		}
+	}
+	return plugins, nil
+}
+
+// refTag returns the tag of ref, or "" when it has none or is a digest
+// reference.
+func refTag(ref string) string {
+	if strings.Contains(ref, "@") {
+		return ""
+	}
+	_, tag := klausoci.SplitNameTag(ref)
+	return tag
+}
+
+// findOutdatedPlugins looks up the latest semver tag of each plugin and
+// returns those whose current version is older, sorted by name. A plugin
+// whose tags cannot be listed is skipped and its error joined into the
+// returned error.
+func findOutdatedPlugins(ctx context.Context, plugins []pluginVersion) ([]outdatedPlugin, error) {
+	outdated := []outdatedPlugin{}
+	var errs []error
+	for _, p := range plugins {
+		tags, err := listPluginTags(ctx, p.repository)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing tags of %s: %w", p.repository, err))
+			continue
+		}
+		latest := klausoci.LatestSemverTag(tags)
+		if !isNewerVersion(p.current, latest) {
+			continue
+		}
+		outdated = append(outdated, outdatedPlugin{
+			Name:       klausoci.ShortName(p.repository),
+			Repository: p.repository,
+			Current:    p.current,
+			Latest:     latest,
+		})
+	}
+	sort.Slice(outdated, func(i, j int) bool {
+		return outdated[i].Name < outdated[j].Name
+	})
+	return outdated, errors.Join(errs...)
+}
+
+// isNewerVersion reports whether latest is a higher semver version than
+// current. A current version that is not semver is never outdated.
+func isNewerVersion(current, latest string) bool {
+	if latest == "" || klausoci.LatestSemverTag([]string{current}) == "" {
+		return false
+	}
+	return klausoci.LatestSemverTag([]string{current, latest}) != current
+}
+
+func writeOutdatedPlugins(w io.Writer, outdated []outdatedPlugin, format string) error {
+	if format == "json" {
+		return writeJSON(w, outdated)
+	}
+	if len(outdated) == 0 {
+		_, _ = fmt.Fprintln(w, "All plugins are up to date.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tCURRENT\tLATEST")
+	for _, p := range outdated {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", p.Name, p.Current, green(p.Latest))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestPluginOutdatedRegistered(t *testing.T) {
+	assertSubcommandsRegistered(t, pluginCmd, []string{"outdated"})
+	assertFlagRegistered(t, pluginOutdatedCmd, "output")
+	assertFlagRegistered(t, pluginOutdatedCmd, "local")
+}
+
+func TestIsNewerVersion(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		want            bool
+	}{
+		{current: "v1.0.0", latest: "v1.1.0", want: true},
+		{current: "v1.1.0", latest: "v1.1.0"},
+		{current: "1.1.0", latest: "v1.1.0"},
+		{current: "v2.0.0", latest: "v1.1.0"},
+		{current: "main", latest: "v1.1.0"},
+		{current: "v1.0.0", latest: ""},
+	}
+	for _, tt := range tests {
+		if got := isNewerVersion(tt.current, tt.latest); got != tt.want {
+			t.Errorf("isNewerVersion(%q, %q) = %v, want %v", tt.current, tt.latest, got, tt.want)
+		}
+	}
+}
+
+func TestConfigPluginVersions(t *testing.T) {
+	cfg := &config.Config{Plugins: []config.Plugin{
+		{Repository: "reg.example.com/plugins/pinned", Tag: "v1.0.0"},
+		{Repository: "reg.example.com/plugins/locked", Digest: "sha256:abc"},
+		{Repository: "reg.example.com/plugins/never-pulled"},
+	}}
+
+	got := configPluginVersions(cfg, t.TempDir())
+	if len(got) != 1 || got[0] != (pluginVersion{repository: "reg.example.com/plugins/pinned", current: "v1.0.0"}) {
+		t.Errorf("configPluginVersions() = %+v, want only the pinned plugin", got)
+	}
+}
+
+func TestFindOutdatedPlugins(t *testing.T) {
+	orig := listPluginTags
+	t.Cleanup(func() { listPluginTags = orig })
+	listPluginTags = func(_ context.Context, repository string) ([]string, error) {
+		switch repository {
+		case "reg.example.com/plugins/zeta", "reg.example.com/plugins/alpha":
+			return []string{"v1.0.0", "v1.2.0", "latest"}, nil
+		case "reg.example.com/plugins/current":
+			return []string{"v1.0.0"}, nil
+		default:
+			return nil, errors.New("unauthorized")
+		}
+	}
+
+	outdated, err := findOutdatedPlugins(context.Background(), []pluginVersion{
+		{repository: "reg.example.com/plugins/zeta", current: "v1.1.0"},
+		{repository: "reg.example.com/plugins/current", current: "v1.0.0"},
+		{repository: "reg.example.com/plugins/private", current: "v1.0.0"},
+		{repository: "reg.example.com/plugins/alpha", current: "v0.9.0"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "plugins/private") {
+		t.Errorf("error = %v, want the failed lookup", err)
+	}
+
+	var out bytes.Buffer
+	if err := writeOutdatedPlugins(&out, outdated, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var got []outdatedPlugin
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	want := []outdatedPlugin{
+		{Name: "alpha", Repository: "reg.example.com/plugins/alpha", Current: "v0.9.0", Latest: "v1.2.0"},
+		{Name: "zeta", Repository: "reg.example.com/plugins/zeta", Current: "v1.1.0", Latest: "v1.2.0"},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("outdated = %+v, want %+v", got, want)
+	}
+}
+
+func TestWriteOutdatedPluginsUpToDate(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeOutdatedPlugins(&out, []outdatedPlugin{}, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "All plugins are up to date.\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var pluginPinCmd = &cobra.Command{
+	Use:   "pin <name> <version>",
+	Short: "Lock a configured plugin to a version",
+	Long: `Lock a plugin of the config file to a tag or digest, so that later starts
+pull exactly that version. The plugin is matched by its short name or full
+repository, and the version must exist in the registry:
+
+  klausctl plugin pin gs-platform v1.2.0
+  klausctl plugin pin gs-platform sha256:4f1c...`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPluginPin,
+}
+
+var pluginUnpinCmd = &cobra.Command{
+	Use:   "unpin <name>",
+	Short: "Clear the locked version of a configured plugin",
+	Long: `Remove the tag and digest of a plugin of the config file, so that the
+latest version is resolved on start.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPluginUnpin,
+}
+
+// resolvePluginVersion checks that ref exists in the registry. It is a
+// variable so tests can run without a registry.
+var resolvePluginVersion = func(ctx context.Context, ref string) error {
+	_, err := orchestrator.NewDefaultClient().Resolve(ctx, ref)
+	return err
+}
+
+func init() {
+	pluginCmd.AddCommand(pluginPinCmd)
+	pluginCmd.AddCommand(pluginUnpinCmd)
+}
+
+func runPluginPin(cmd *cobra.Command, args []string) error {
+	name, version := args[0], args[1]
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	p, err := cfg.FindPlugin(name)
+	if err != nil {
+		return err
+	}
+
+	ref := p.Repository + ":" + version
+	if config.IsDigest(version) {
+		ref = p.Repository + "@" + version
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := resolvePluginVersion(ctx, ref); err != nil {
+		return fmt.Errorf("version %s of plugin %s not found: %w", version, name, err)
+	}
+
+	if err := editConfigFile(path, func(data []byte) ([]byte, error) {
+		return config.PinPlugin(data, name, version)
+	}); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Pinned %s to %s in %s\n", name, version, path)
+	return nil
+}
+
+func runPluginUnpin(cmd *cobra.Command, args []string) error {
+	path, err := resolvedConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := editConfigFile(path, func(data []byte) ([]byte, error) {
+		return config.UnpinPlugin(data, args[0])
+	}); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Unpinned %s in %s\n", args[0], path)
+	return nil
+}
+
+// editConfigFile rewrites the config file at path with the result of edit,
+// keeping its permissions.
+func editConfigFile(path string, edit func([]byte) ([]byte, error)) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("config file not found: %s\nRun 'klausctl config init' to create one", path)
+		}
+		return fmt.Errorf("reading config: %w", err)
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- user-supplied or trusted local path; not exposed to untrusted input
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	updated, err := edit(data)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, updated, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestPluginPinValidatesVersionInRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "workspace: /tmp/test\nplugins:\n  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/gs-platform\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	origCfgFile, origResolve := cfgFile, resolvePluginVersion
+	t.Cleanup(func() { cfgFile, resolvePluginVersion = origCfgFile, origResolve })
+	cfgFile = path
+
+	var resolved []string
+	resolvePluginVersion = func(_ context.Context, ref string) error {
+		resolved = append(resolved, ref)
+		if ref != "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-platform:v1.2.0" {
+			return errors.New("not found")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	pluginPinCmd.SetOut(&out)
+	if err := runPluginPin(pluginPinCmd, []string{"gs-platform", "v9.9.9"}); err == nil {
+		t.Fatal("pinning a version missing from the registry succeeded")
+	}
+	if err := runPluginPin(pluginPinCmd, []string{"gs-platform", "v1.2.0"}); err != nil {
+		t.Fatalf("runPluginPin() error = %v", err)
+	}
+	if len(resolved) != 2 {
+		t.Errorf("resolved refs = %v, want two lookups", resolved)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Plugins[0].Tag; got != "v1.2.0" {
+		t.Errorf("Tag = %q, want v1.2.0", got)
+	}
+
+	pluginUnpinCmd.SetOut(&out)
+	if err := runPluginUnpin(pluginUnpinCmd, []string{"gs-platform"}); err != nil {
+		t.Fatalf("runPluginUnpin() error = %v", err)
+	}
+	cfg, err = config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Plugins[0].Tag; got != "" {
+		t.Errorf("Tag after unpin = %q, want empty", got)
+	}
+}
+
+func TestPluginDisableEnable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := "workspace: /tmp/test\nplugins:\n  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/gs-platform\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	origCfgFile := cfgFile
+	t.Cleanup(func() { cfgFile = origCfgFile })
+	cfgFile = path
+
+	var out bytes.Buffer
+	pluginDisableCmd.SetOut(&out)
+	if err := pluginDisableCmd.RunE(pluginDisableCmd, []string{"gs-platform"}); err != nil {
+		t.Fatalf("plugin disable error = %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Plugins[0].IsEnabled() {
+		t.Error("plugin still enabled after disable")
+	}
+
+	pluginEnableCmd.SetOut(&out)
+	if err := pluginEnableCmd.RunE(pluginEnableCmd, []string{"gs-platform"}); err != nil {
+		t.Fatalf("plugin enable error = %v", err)
+	}
+	cfg, err = config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Plugins[0].IsEnabled() {
+		t.Error("plugin still disabled after enable")
+	}
+}
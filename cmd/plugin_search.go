@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/ocicache"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var pluginSearchOut string
+
+var pluginSearchCmd = &cobra.Command{
+	Use:   "search <term>",
+	Short: "Search plugins of all sources by keyword",
+	Long: `Search the plugin registries of all configured sources for a term.
+
+The term is matched case-insensitively against each plugin's name, keywords
+and description. Matches in the name rank first, then matches in the
+keywords, then matches in the description.
+
+Plugin metadata is described without downloading content and reused for
+10 minutes, so repeated searches do not query every plugin again.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPluginSearch,
+}
+
+// pluginSearchMetaTTL is how long plugin search reuses described metadata.
+const pluginSearchMetaTTL = 10 * time.Minute
+
+// Ranks of plugin search matches; higher ranks sort first.
+const (
+	searchRankDescription = iota + 1
+	searchRankKeyword
+	searchRankName
+)
+
+// pluginSearchResult is a plugin matching a search term.
+type pluginSearchResult struct {
+	Source      string   `json:"source,omitempty"`
+	Name        string   `json:"name"`
+	Ref         string   `json:"ref"`
+	Description string   `json:"description,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	// Match is where the term was found: name, keywords or description.
+	Match string `json:"match"`
+	rank  int
+}
+
+// pluginSearchMeta is the plugin metadata search matches against.
+type pluginSearchMeta struct {
+	Description string    `json:"description,omitempty"`
+	Keywords    []string  `json:"keywords,omitempty"`
+	FetchedAt   time.Time `json:"fetchedAt"`
+}
+
+// describePluginMetaFn describes the plugin at ref.
+type describePluginMetaFn func(ctx context.Context, ref string) (pluginSearchMeta, error)
+
+// pluginMetaCache holds described plugin metadata by reference. It is
+// persisted to path; an empty path keeps it in memory only.
+type pluginMetaCache struct {
+	path    string
+	entries map[string]pluginSearchMeta
+	dirty   bool
+}
+
+// loadPluginMetaCache reads the cache at path. A missing or unreadable
+// cache starts empty.
+func loadPluginMetaCache(path string) *pluginMetaCache {
+	c := &pluginMetaCache{path: path, entries: make(map[string]pluginSearchMeta)}
+	if path == "" {
+		return c
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path is inside the klausctl cache directory
+	if err != nil {
+		return c
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = make(map[string]pluginSearchMeta)
+	}
+	return c
+}
+
+// describe returns the metadata of ref from the cache when it is younger
+// than pluginSearchMetaTTL, and otherwise describes and caches it.
+func (c *pluginMetaCache) describe(ctx context.Context, ref string, now time.Time, fn describePluginMetaFn) (pluginSearchMeta, error) {
+	if meta, ok := c.entries[ref]; ok && now.Sub(meta.FetchedAt) < pluginSearchMetaTTL {
+		return meta, nil
+	}
+	meta, err := fn(ctx, ref)
+	if err != nil {
+		return pluginSearchMeta{}, err
+	}
+	meta.FetchedAt = now
+	c.entries[ref] = meta
+	c.dirty = true
+	return meta, nil
+}
+
+// save writes the cache when it changed, dropping expired entries.
+func (c *pluginMetaCache) save(now time.Time) error {
+	if c.path == "" || !c.dirty {
+		return nil
+	}
+	for ref, meta := range c.entries {
+		if now.Sub(meta.FetchedAt) >= pluginSearchMetaTTL {
+			delete(c.entries, ref)
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o750); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return os.WriteFile(c.path, data, 0o600)
+}
+
+func init() {
+	pluginSearchCmd.Flags().StringVarP(&pluginSearchOut, "output", "o", "text", "output format: text, json")
+
+	pluginCmd.AddCommand(pluginSearchCmd)
+}
+
+func runPluginSearch(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(pluginSearchOut); err != nil {
+		return err
+	}
+	term := strings.TrimSpace(args[0])
+	if term == "" {
+		return fmt.Errorf("search term must not be empty")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	resolver, err := buildListSourceResolver("", true)
+	if err != nil {
+		return err
+	}
+
+	var cachePath string
+	if dir, err := ocicache.Dir(); err == nil && dir != "" {
+		cachePath = filepath.Join(dir, "plugin-search.json")
+	}
+	cache := loadPluginMetaCache(cachePath)
+
+	client := orchestrator.NewDefaultClient()
+	describe := func(ctx context.Context, ref string) (pluginSearchMeta, error) {
+		dp, err := client.DescribePlugin(ctx, ref)
+		if err != nil {
+			return pluginSearchMeta{}, err
+		}
+		return pluginSearchMeta{Description: dp.Description, Keywords: dp.Keywords}, nil
+	}
+
+	results, warnings, err := searchPlugins(ctx, paths.PluginsDir, resolver.PluginRegistries(), term, cache, listPluginsFn, describe)
+	if err != nil {
+		return err
+	}
+	if err := cache.save(time.Now()); err != nil {
+		warnings = append(warnings, fmt.Sprintf("saving plugin metadata cache: %v", err))
+	}
+
+	out := cmd.OutOrStdout()
+	if len(results) == 0 {
+		err = printEmpty(out, pluginSearchOut, fmt.Sprintf("No plugins match %q.", term))
+	} else {
+		err = printPluginSearchResults(out, results, pluginSearchOut)
+	}
+	for _, w := range warnings {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %s\n", w)
+	}
+	return err
+}
+
+// searchPlugins lists the plugins of every registry and returns those
+// matching term, best matches first. Plugins that cannot be described are
+// still matched by name and reported as warnings, as are registries that
+// cannot be listed while others can.
+func searchPlugins(ctx context.Context, cacheDir string, registries []config.SourceRegistry, term string, cache *pluginMetaCache, list listFn, describe describePluginMetaFn) ([]pluginSearchResult, []string, error) {
+	now := time.Now()
+	multiSource := len(registries) > 1
+
+	// Every described plugin is aggregated and matched afterwards, so that a
+	// source without matches does not count as failed.
+	var describeWarnings []string
+	described, warnings, err := config.AggregateFromSources(registries, "plugins", func(sr config.SourceRegistry) ([]pluginSearchResult, error) {
+		entries, err := listLatestRemoteArtifacts(ctx, cacheDir, sr.Registry, "", list)
+		if err != nil {
+			return nil, err
+		}
+		plugins := make([]pluginSearchResult, 0, len(entries))
+		for _, e := range entries {
+			meta, err := cache.describe(ctx, e.Ref, now, describe)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return nil, err
+				}
+				describeWarnings = append(describeWarnings, fmt.Sprintf("describing %s: %v", e.Ref, err))
+			}
+			p := pluginSearchResult{
+				Name:        e.Name,
+				Ref:         e.Ref,
+				Description: meta.Description,
+				Keywords:    meta.Keywords,
+			}
+			if multiSource {
+				p.Source = sr.Source
+			}
+			plugins = append(plugins, p)
+		}
+		return plugins, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results []pluginSearchResult
+	for _, p := range described {
+		p.rank, p.Match = matchPluginSearch(term, p.Name, p.Description, p.Keywords)
+		if p.rank > 0 {
+			results = append(results, p)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].rank != results[j].rank {
+			return results[i].rank > results[j].rank
+		}
+		if results[i].Source != results[j].Source {
+			return results[i].Source < results[j].Source
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results, append(warnings, describeWarnings...), nil
+}
+
+// matchPluginSearch returns the rank of the best match of term in the
+// plugin's name, keywords and description, and where it matched. A rank of
+// 0 means no match.
+func matchPluginSearch(term, name, description string, keywords []string) (int, string) {
+	term = strings.ToLower(term)
+	if strings.Contains(strings.ToLower(name), term) {
+		return searchRankName, "name"
+	}
+	for _, k := range keywords {
+		if strings.Contains(strings.ToLower(k), term) {
+			return searchRankKeyword, "keywords"
+		}
+	}
+	if strings.Contains(strings.ToLower(description), term) {
+		return searchRankDescription, "description"
+	}
+	return 0, ""
+}
+
+// printPluginSearchResults prints search results in table or JSON format.
+func printPluginSearchResults(out io.Writer, results []pluginSearchResult, outputFmt string) error {
+	if outputFmt == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	multiSource := results[0].Source != ""
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if multiSource {
+		_, _ = fmt.Fprintln(w, "SOURCE\tNAME\tREF\tMATCH\tDESCRIPTION")
+	} else {
+		_, _ = fmt.Fprintln(w, "NAME\tREF\tMATCH\tDESCRIPTION")
+	}
+	for _, r := range results {
+		if multiSource {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Source, r.Name, r.Ref, r.Match, r.Description)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.Ref, r.Match, r.Description)
+		}
+	}
+	return w.Flush()
+}
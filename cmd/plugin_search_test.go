@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestPluginSearchRegistered(t *testing.T) {
+	assertFlagRegistered(t, pluginSearchCmd, "output")
+}
+
+func TestSearchPlugins(t *testing.T) {
+	list := func(_ context.Context, _ *klausoci.Client, _ ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+		return []klausoci.ListEntry{
+			{Name: "gs-base", Reference: "example.com/gs-base:v1.0.0"},
+			{Name: "flux", Reference: "example.com/flux:v1.0.0"},
+			{Name: "kubectl-helm", Reference: "example.com/kubectl-helm:v1.0.0"},
+			{Name: "docs", Reference: "example.com/docs:v1.0.0"},
+			{Name: "broken", Reference: "example.com/broken:v1.0.0"},
+		}, nil
+	}
+	meta := map[string]pluginSearchMeta{
+		"example.com/gs-base:v1.0.0":      {Description: "Base skills for Helm and Kubernetes"},
+		"example.com/flux:v1.0.0":         {Description: "GitOps", Keywords: []string{"HELM", "gitops"}},
+		"example.com/kubectl-helm:v1.0.0": {Description: "kubectl and helm"},
+		"example.com/docs:v1.0.0":         {Description: "Writing docs"},
+	}
+	describe := func(_ context.Context, ref string) (pluginSearchMeta, error) {
+		m, ok := meta[ref]
+		if !ok {
+			return pluginSearchMeta{}, errors.New("manifest unknown")
+		}
+		return m, nil
+	}
+
+	registries := []config.SourceRegistry{{Source: "giantswarm", Registry: "example.com"}}
+	results, warnings, err := searchPlugins(context.Background(), t.TempDir(), registries, "helm", loadPluginMetaCache(""), list, describe)
+	if err != nil {
+		t.Fatalf("searchPlugins() error = %v", err)
+	}
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name+":"+r.Match)
+	}
+	want := []string{"kubectl-helm:name", "flux:keywords", "gs-base:description"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("results = %v, want %v", got, want)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %v, want one for the broken plugin", warnings)
+	}
+}
+
+func TestPluginMetaCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plugin-search.json")
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	calls := 0
+	describe := func(_ context.Context, _ string) (pluginSearchMeta, error) {
+		calls++
+		return pluginSearchMeta{Description: "Base"}, nil
+	}
+
+	cache := loadPluginMetaCache(path)
+	if _, err := cache.describe(context.Background(), "example.com/gs-base:v1.0.0", now, describe); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.save(now); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+
+	cache = loadPluginMetaCache(path)
+	meta, err := cache.describe(context.Background(), "example.com/gs-base:v1.0.0", now.Add(time.Minute), describe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || meta.Description != "Base" {
+		t.Errorf("fresh entry: calls = %d, meta = %+v, want cached metadata", calls, meta)
+	}
+
+	if _, err := cache.describe(context.Background(), "example.com/gs-base:v1.0.0", now.Add(pluginSearchMetaTTL), describe); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expired entry: calls = %d, want 2", calls)
+	}
+}
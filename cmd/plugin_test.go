@@ -13,7 +13,7 @@ import (
 )
 
 func TestPluginSubcommandsRegistered(t *testing.T) {
-	assertSubcommandsRegistered(t, pluginCmd, []string{"validate", "pull", "push", "list", "describe"})
+	assertSubcommandsRegistered(t, pluginCmd, []string{"validate", "pull", "push", "list", "describe", "diff", "pin", "unpin", "enable", "disable"})
 }
 
 func TestPluginCommandRegisteredOnRoot(t *testing.T) {
@@ -142,15 +142,28 @@ func TestValidatePluginDirJSONOutput(t *testing.T) {
 func TestPluginFlagsRegistered(t *testing.T) {
 	assertFlagRegistered(t, pluginValidateCmd, "output")
 	assertFlagRegistered(t, pluginPullCmd, "output")
+	assertFlagRegistered(t, pluginPullCmd, "certificate-identity")
+	assertFlagRegistered(t, pluginPullCmd, "certificate-oidc-issuer")
 	assertFlagRegistered(t, pluginPushCmd, "output")
 	assertFlagRegistered(t, pluginPushCmd, "source")
 	assertFlagRegistered(t, pluginPushCmd, "dry-run")
 	assertFlagRegistered(t, pluginListCmd, "output")
 	assertFlagRegistered(t, pluginListCmd, "local")
+	assertFlagRegistered(t, pluginListCmd, "filter")
 	assertFlagRegistered(t, pluginDescribeCmd, "output")
 	assertFlagRegistered(t, pluginDescribeCmd, "source")
 }
 
+func TestPluginPullKeylessVerifyRequiresIdentity(t *testing.T) {
+	t.Cleanup(func() { pluginPullVerify, pluginPullIdentity, pluginPullIssuer = false, "", "" })
+	pluginPullVerify, pluginPullIdentity = true, "dev@example.com"
+
+	err := runPluginPull(pluginPullCmd, []string{"gs-base"})
+	if err == nil || !strings.Contains(err.Error(), "--certificate-oidc-issuer") {
+		t.Errorf("runPluginPull() error = %v, want missing issuer", err)
+	}
+}
+
 func TestPrintPluginComponents(t *testing.T) {
 	dp := &klausoci.DescribedPlugin{
 		Plugin: klausoci.Plugin{
@@ -14,7 +14,6 @@ import (
 	"github.com/giantswarm/klausctl/pkg/agentclient"
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 var (
@@ -91,7 +90,7 @@ func runPrompt(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no klaus instance found for %q; run 'klausctl create %s <workspace>' first", instanceName, instanceName)
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		return err
 	}
@@ -104,11 +103,15 @@ func runPrompt(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("instance %q is not running (status: %s); run 'klausctl start %s' first", instanceName, status, instanceName)
 	}
 
-	agentURL := fmt.Sprintf("http://localhost:%d", inst.Port)
+	agentURL := inst.AgentURL()
 	httpClient := &http.Client{}
 
 	if promptBlocking {
-		return runPromptBlocking(ctx, out, httpClient, agentURL, instanceName)
+		if err := runPromptBlocking(ctx, out, httpClient, agentURL, instanceName); err != nil {
+			return err
+		}
+		recordUsage(ctx, inst, paths)
+		return nil
 	}
 
 	return runPromptNonBlocking(ctx, out, httpClient, agentURL, instanceName)
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/ocicache"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+var (
+	pruneAll    bool
+	pruneDryRun bool
+	pruneFormat string
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove stopped containers, stale instance state and stale cache entries",
+	Long: `Clean up everything klausctl leaves behind, in one go:
+
+  - klausctl-managed containers that are not running (created, exited or
+    dead), with every installed runtime,
+  - instance state whose container no longer exists, including the state
+    of the containers removed above; the instance configs are kept, and
+  - with --all, stale entries of the registry cache (see 'klausctl cache
+    prune') and plugin versions that no plugin and no remaining container
+    uses anymore.
+
+Running containers and containers of other tools are never touched. Unlike
+'klausctl reconcile', prune does not ask before removing; use --dry-run to
+see what it would remove.`,
+	Args: cobra.NoArgs,
+	RunE: runPrune,
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneAll, "all", false, "also remove stale registry cache entries and unused plugin versions")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "only report what would be removed")
+	pruneCmd.Flags().StringVarP(&pruneFormat, "output", "o", "text", "output format: text|json")
+	rootCmd.AddCommand(pruneCmd)
+}
+
+// prunableStatuses are the container states of docker and podman in which
+// a container is not running and can be removed.
+var prunableStatuses = map[string]bool{
+	"created":    true,
+	"configured": true,
+	"exited":     true,
+	"stopped":    true,
+	"dead":       true,
+}
+
+// prunedContainer is a container removed, or to be removed, by prune.
+type prunedContainer struct {
+	Name    string `json:"name"`
+	Runtime string `json:"runtime"`
+	Status  string `json:"status"`
+	// Instance is empty for a container without an instance label.
+	Instance string `json:"instance,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// prunedInstance is an instance whose stale state was cleared, or is to be
+// cleared, by prune.
+type prunedInstance struct {
+	Name      string `json:"name"`
+	Container string `json:"container"`
+	Error     string `json:"error,omitempty"`
+}
+
+// pruneReport is the outcome of prune.
+type pruneReport struct {
+	DryRun     bool              `json:"dryRun"`
+	Containers []prunedContainer `json:"containers"`
+	Instances  []prunedInstance  `json:"instances"`
+	// Cache is only set with --all.
+	Cache *ocicache.PruneResult `json:"cache,omitempty"`
+	// PluginBlobs are the unused plugin blobs, only set with --all.
+	PluginBlobs []string `json:"pluginBlobs,omitempty"`
+
+	// mounted holds the host paths mounted by the containers that are
+	// kept.
+	mounted map[string]bool
+}
+
+func runPrune(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(pruneFormat); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+	runtimes, err := installedRuntimes()
+	if err != nil {
+		return err
+	}
+
+	report, pruneErr := pruneManaged(ctx, paths, runtimes, pruneDryRun)
+	if report == nil {
+		return pruneErr
+	}
+	if pruneAll {
+		res, err := ocicache.Prune(ocicache.PruneOptions{DryRun: pruneDryRun})
+		if err != nil {
+			pruneErr = errors.Join(pruneErr, fmt.Errorf("pruning registry cache: %w", err))
+		}
+		report.Cache = res
+
+		blobs, err := orchestrator.PrunePluginBlobs(paths.PluginsDir, report.mounted, pruneDryRun)
+		if err != nil {
+			pruneErr = errors.Join(pruneErr, fmt.Errorf("pruning plugin blobs: %w", err))
+		}
+		report.PluginBlobs = blobs
+	}
+
+	if err := writePruneReport(cmd.OutOrStdout(), report, pruneFormat); err != nil {
+		return err
+	}
+	return pruneErr
+}
+
+// pruneManaged removes the managed containers of runtimes that are not
+// running, then clears the state of instances whose container is gone. A
+// failed removal is recorded in the report and joined into the returned
+// error; the report is nil only when the containers could not be listed.
+func pruneManaged(ctx context.Context, paths *config.Paths, runtimes []runtime.Runtime, dryRun bool) (*pruneReport, error) {
+	report := &pruneReport{
+		DryRun:     dryRun,
+		Containers: []prunedContainer{},
+		Instances:  []prunedInstance{},
+		mounted:    map[string]bool{},
+	}
+	var errs []error
+
+	byName := make(map[string]runtime.Runtime, len(runtimes))
+	// removed holds the pruned containers by runtime and name, so that a
+	// dry run reports the state of their instances as well.
+	removed := map[string]bool{}
+	for _, rt := range runtimes {
+		byName[rt.Name()] = rt
+
+		containers, err := rt.ListManaged(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing containers via %s: %w", rt.Name(), err)
+		}
+		for _, c := range containers {
+			if !prunableStatuses[c.Status] {
+				for _, m := range c.Mounts {
+					report.mounted[m] = true
+				}
+				continue
+			}
+			pc := prunedContainer{
+				Name:     c.Name,
+				Runtime:  rt.Name(),
+				Status:   c.Status,
+				Instance: c.Labels[runtime.LabelInstance],
+			}
+			if !dryRun {
+				if err := rt.Remove(ctx, c.Name); err != nil {
+					err = fmt.Errorf("removing container %s: %w", c.Name, err)
+					pc.Error = err.Error()
+					errs = append(errs, err)
+				}
+			}
+			if pc.Error == "" {
+				removed[rt.Name()+"/"+c.Name] = true
+			} else {
+				for _, m := range c.Mounts {
+					report.mounted[m] = true
+				}
+			}
+			report.Containers = append(report.Containers, pc)
+		}
+	}
+
+	instances, err := instance.LoadAll(paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range instances {
+		rt := byName[inst.Runtime]
+		if rt == nil {
+			// The runtime that started the container is not installed, so
+			// whether the container still exists is unknown.
+			continue
+		}
+		container := inst.ContainerName()
+		if !removed[rt.Name()+"/"+container] {
+			status, err := rt.Status(ctx, container)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("checking container of %q: %w", inst.Name, err))
+				continue
+			}
+			if status != "" {
+				continue
+			}
+		}
+		pi := prunedInstance{Name: inst.Name, Container: container}
+		if !dryRun {
+			if err := instance.Clear(paths.ForInstance(inst.Name)); err != nil {
+				err = fmt.Errorf("clearing state of %q: %w", inst.Name, err)
+				pi.Error = err.Error()
+				errs = append(errs, err)
+			}
+		}
+		report.Instances = append(report.Instances, pi)
+	}
+
+	return report, errors.Join(errs...)
+}
+
+func writePruneReport(w io.Writer, report *pruneReport, format string) error {
+	if format == "json" {
+		return writeJSON(w, report)
+	}
+
+	cacheEntries := report.Cache != nil && report.Cache.FilesRemoved > 0
+	if len(report.Containers) == 0 && len(report.Instances) == 0 && !cacheEntries && len(report.PluginBlobs) == 0 {
+		_, _ = fmt.Fprintln(w, "Nothing to prune.")
+		return nil
+	}
+
+	removeVerb, clearVerb, pruneVerb := "Removed", "Cleared", "Pruned"
+	if report.DryRun {
+		removeVerb, clearVerb, pruneVerb = "Would remove", "Would clear", "Would prune"
+	}
+	for _, c := range report.Containers {
+		_, _ = fmt.Fprintf(w, "%s container %s (%s, %s)\n", removeVerb, c.Name, c.Runtime, c.Status)
+		if c.Error != "" {
+			_, _ = fmt.Fprintf(w, "  %s %s\n", red("failed:"), c.Error)
+		}
+	}
+	for _, i := range report.Instances {
+		_, _ = fmt.Fprintf(w, "%s stale state of instance %q (container %s)\n", clearVerb, i.Name, i.Container)
+		if i.Error != "" {
+			_, _ = fmt.Fprintf(w, "  %s %s\n", red("failed:"), i.Error)
+		}
+	}
+	if cacheEntries {
+		_, _ = fmt.Fprintf(w, "%s %d stale cache entries (%s) from %s\n",
+			pruneVerb, report.Cache.FilesRemoved, humanBytes(report.Cache.BytesRemoved), displayDir(report.Cache.Dir))
+	}
+	for _, b := range report.PluginBlobs {
+		_, _ = fmt.Fprintf(w, "%s unused plugin blob %s\n", removeVerb, b)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/klausctl/pkg/instance"
+	runtimepkg "github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+func TestPruneRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "prune")
+	assertFlagRegistered(t, pruneCmd, "all")
+	assertFlagRegistered(t, pruneCmd, "dry-run")
+	assertFlagRegistered(t, pruneCmd, "output")
+}
+
+func TestPruneManaged(t *testing.T) {
+	paths, rt := setupReconcileTest(t)
+	running := &instance.Instance{Name: "busy", Runtime: "docker", StartedAt: time.Now()}
+	if err := running.Save(paths.ForInstance("busy")); err != nil {
+		t.Fatal(err)
+	}
+	rt.containers = append(rt.containers, runtimepkg.ContainerInfo{
+		Name: "klausctl-busy", Status: "running", Labels: runtimepkg.ManagedLabels("busy"),
+		Mounts: []string{"/plugins/blobs/sha256-aaa"},
+	})
+
+	report, err := pruneManaged(context.Background(), paths, []runtimepkg.Runtime{rt}, true)
+	if err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if len(rt.removed) != 0 {
+		t.Fatalf("dry run removed %v", rt.removed)
+	}
+	if !report.mounted["/plugins/blobs/sha256-aaa"] {
+		t.Errorf("mounts of the running container not kept: %v", report.mounted)
+	}
+
+	var out bytes.Buffer
+	if err := writePruneReport(&out, report, "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Would remove container klausctl-live",
+		"Would remove container klausctl-gone",
+		"Would remove container klausctl-unlabelled",
+		`Would clear stale state of instance "live"`,
+		`Would clear stale state of instance "stale"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("dry run output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "busy") {
+		t.Errorf("dry run output mentions the running instance:\n%s", out.String())
+	}
+
+	report, err = pruneManaged(context.Background(), paths, []runtimepkg.Runtime{rt}, false)
+	if err != nil {
+		t.Fatalf("pruneManaged() error = %v", err)
+	}
+	slices.Sort(rt.removed)
+	if want := []string{"klausctl-gone", "klausctl-live", "klausctl-unlabelled"}; !slices.Equal(rt.removed, want) {
+		t.Errorf("removed = %v, want %v", rt.removed, want)
+	}
+	for _, name := range []string{"live", "stale"} {
+		if _, err := os.Stat(paths.ForInstance(name).InstanceFile); !os.IsNotExist(err) {
+			t.Errorf("state of %q still exists: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(paths.ForInstance("busy").InstanceFile); err != nil {
+		t.Errorf("state of the running instance removed: %v", err)
+	}
+	if _, err := os.Stat(paths.ForInstance("live").InstanceDir); err != nil {
+		t.Errorf("instance directory removed: %v", err)
+	}
+
+	out.Reset()
+	if err := writePruneReport(&out, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded pruneReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if decoded.DryRun || len(decoded.Containers) != 3 || len(decoded.Instances) != 2 {
+		t.Errorf("json report = %+v", decoded)
+	}
+}
+
+func TestWritePruneReportNothing(t *testing.T) {
+	var out bytes.Buffer
+	if err := writePruneReport(&out, &pruneReport{}, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Nothing to prune.\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
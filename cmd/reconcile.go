@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+var (
+	reconcileYes    bool
+	reconcileDryRun bool
+)
+
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Remove orphaned containers and stale instance state",
+	Long: `Reconcile the containers klausctl manages with the instance directories.
+
+klausctl labels every container it starts with klausctl.managed=true and
+klausctl.instance=<name>. reconcile lists these containers with every
+installed runtime and reports:
+
+  - orphaned containers, whose instance no longer exists, and
+  - stale instance state, whose container no longer exists.
+
+It asks before removing each of them. Use --yes to remove them without
+asking, or --dry-run to only report them.`,
+	Args: cobra.NoArgs,
+	RunE: runReconcile,
+}
+
+func init() {
+	reconcileCmd.Flags().BoolVarP(&reconcileYes, "yes", "y", false, "remove orphans without asking")
+	reconcileCmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "only report orphans, do not remove them")
+	rootCmd.AddCommand(reconcileCmd)
+}
+
+// reconcileAction is an orphan found by reconcile and how to remove it.
+type reconcileAction struct {
+	desc   string
+	remove func(context.Context) error
+}
+
+func runReconcile(cmd *cobra.Command, _ []string) error {
+	if reconcileYes && reconcileDryRun {
+		return fmt.Errorf("--yes and --dry-run are mutually exclusive")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	runtimes, err := installedRuntimes()
+	if err != nil {
+		return err
+	}
+
+	actions, err := findOrphans(ctx, paths, runtimes)
+	if err != nil {
+		return err
+	}
+	return applyReconcile(ctx, cmd, actions)
+}
+
+// installedRuntimes returns a runtime for each of docker and podman that is
+// installed, and an error when neither is.
+func installedRuntimes() ([]runtime.Runtime, error) {
+	var runtimes []runtime.Runtime
+	for _, name := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(name); err != nil {
+			continue
+		}
+		rt, err := newRuntime(name, "")
+		if err != nil {
+			return nil, err
+		}
+		runtimes = append(runtimes, rt)
+	}
+	if len(runtimes) == 0 {
+		return nil, fmt.Errorf("no container runtime found; install docker or podman")
+	}
+	return runtimes, nil
+}
+
+// findOrphans returns the managed containers of runtimes whose instance
+// directory is gone and the instance state files whose container is gone.
+func findOrphans(ctx context.Context, paths *config.Paths, runtimes []runtime.Runtime) ([]reconcileAction, error) {
+	var actions []reconcileAction
+
+	byName := make(map[string]runtime.Runtime, len(runtimes))
+	for _, rt := range runtimes {
+		byName[rt.Name()] = rt
+
+		containers, err := rt.ListManaged(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing containers via %s: %w", rt.Name(), err)
+		}
+		for _, c := range containers {
+			exists, err := instanceExists(paths, c.Labels[runtime.LabelInstance])
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+			actions = append(actions, reconcileAction{
+				desc: fmt.Sprintf("Container %s (%s, %s) belongs to no instance", c.Name, rt.Name(), c.Status),
+				remove: func(ctx context.Context) error {
+					return stopAndRemoveContainerIfExists(ctx, rt, c.Name)
+				},
+			})
+		}
+	}
+
+	instances, err := instance.LoadAll(paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range instances {
+		rt := byName[inst.Runtime]
+		if rt == nil {
+			// The runtime that started the container is not installed, so
+			// whether the container still exists is unknown.
+			continue
+		}
+		status, err := rt.Status(ctx, inst.ContainerName())
+		if err != nil {
+			return nil, fmt.Errorf("checking container of %q: %w", inst.Name, err)
+		}
+		if status != "" {
+			continue
+		}
+		instPaths := paths.ForInstance(inst.Name)
+		actions = append(actions, reconcileAction{
+			desc: fmt.Sprintf("Instance %q has state but its container %s is gone", inst.Name, inst.ContainerName()),
+			remove: func(context.Context) error {
+				return instance.Clear(instPaths)
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+// instanceExists reports whether the directory of the named instance
+// exists. An empty name, from a container without an instance label, never
+// exists.
+func instanceExists(paths *config.Paths, name string) (bool, error) {
+	if name == "" {
+		return false, nil
+	}
+	if _, err := os.Stat(paths.ForInstance(name).InstanceDir); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// applyReconcile reports actions and, unless --dry-run is set, removes the
+// orphans the user confirms.
+func applyReconcile(ctx context.Context, cmd *cobra.Command, actions []reconcileAction) error {
+	out := cmd.OutOrStdout()
+	if len(actions) == 0 {
+		_, _ = fmt.Fprintln(out, "Nothing to reconcile.")
+		return nil
+	}
+
+	reader := bufio.NewReader(cmd.InOrStdin())
+	var errs []error
+	for _, a := range actions {
+		if reconcileDryRun {
+			_, _ = fmt.Fprintf(out, "%s.\n", a.desc)
+			continue
+		}
+		if !reconcileYes {
+			_, _ = fmt.Fprintf(out, "%s. Remove it? [y/N]: ", a.desc)
+			answer, err := reader.ReadString('\n')
+			if err != nil {
+				return err
+			}
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				continue
+			}
+		} else {
+			_, _ = fmt.Fprintf(out, "%s.\n", a.desc)
+		}
+		if err := a.remove(ctx); err != nil {
+			errs = append(errs, err)
+			_, _ = fmt.Fprintf(out, "  %s %v\n", red("failed:"), err)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "  %s\n", green("removed"))
+	}
+	return errors.Join(errs...)
+}
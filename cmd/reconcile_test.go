@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	runtimepkg "github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// reconcileRuntime is a runtime with a fixed set of managed containers.
+type reconcileRuntime struct {
+	runtimepkg.Runtime
+	containers []runtimepkg.ContainerInfo
+	removed    []string
+}
+
+func (r *reconcileRuntime) Name() string { return "docker" }
+func (r *reconcileRuntime) ListManaged(_ context.Context) ([]runtimepkg.ContainerInfo, error) {
+	return r.containers, nil
+}
+func (r *reconcileRuntime) Status(_ context.Context, name string) (string, error) {
+	for _, c := range r.containers {
+		if c.Name == name {
+			return c.Status, nil
+		}
+	}
+	return "", nil
+}
+func (r *reconcileRuntime) Remove(_ context.Context, name string) error {
+	r.removed = append(r.removed, name)
+	return nil
+}
+
+func TestReconcileRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "reconcile")
+	assertFlagRegistered(t, reconcileCmd, "yes")
+	assertFlagRegistered(t, reconcileCmd, "dry-run")
+}
+
+// setupReconcileTest creates the instances "live" with a container and
+// "stale" without one, next to containers of the deleted instance "gone"
+// and of no instance at all.
+func setupReconcileTest(t *testing.T) (*config.Paths, *reconcileRuntime) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(t.TempDir(), "config-home"))
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"live", "stale"} {
+		inst := &instance.Instance{Name: name, Runtime: "docker", StartedAt: time.Now()}
+		if err := inst.Save(paths.ForInstance(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rt := &reconcileRuntime{containers: []runtimepkg.ContainerInfo{
+		{Name: "klausctl-live", Status: "exited", Labels: runtimepkg.ManagedLabels("live")},
+		{Name: "klausctl-gone", Status: "exited", Labels: runtimepkg.ManagedLabels("gone")},
+		{Name: "klausctl-unlabelled", Status: "created", Labels: map[string]string{runtimepkg.LabelManaged: "true"}},
+	}}
+	return paths, rt
+}
+
+func TestFindOrphans(t *testing.T) {
+	paths, rt := setupReconcileTest(t)
+
+	actions, err := findOrphans(context.Background(), paths, []runtimepkg.Runtime{rt})
+	if err != nil {
+		t.Fatalf("findOrphans() error = %v", err)
+	}
+	var descs []string
+	for _, a := range actions {
+		descs = append(descs, a.desc)
+	}
+	got := strings.Join(descs, "\n")
+	for _, want := range []string{"klausctl-gone", "klausctl-unlabelled", `Instance "stale"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("actions %q do not mention %s", got, want)
+		}
+	}
+	if strings.Contains(got, "live") {
+		t.Errorf("actions %q mention the live instance", got)
+	}
+}
+
+func TestApplyReconcile(t *testing.T) {
+	paths, rt := setupReconcileTest(t)
+	actions, err := findOrphans(context.Background(), paths, []runtimepkg.Runtime{rt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { reconcileYes, reconcileDryRun = false, false })
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+
+	reconcileDryRun = true
+	if err := applyReconcile(context.Background(), cmd, actions); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if len(rt.removed) != 0 {
+		t.Fatalf("dry run removed %v", rt.removed)
+	}
+
+	// Confirm the first container and the stale state, skip the second
+	// container.
+	reconcileDryRun = false
+	cmd.SetIn(strings.NewReader("y\nn\nyes\n"))
+	if err := applyReconcile(context.Background(), cmd, actions); err != nil {
+		t.Fatalf("applyReconcile() error = %v", err)
+	}
+	if len(rt.removed) != 1 || rt.removed[0] != "klausctl-gone" {
+		t.Errorf("removed = %v, want [klausctl-gone]", rt.removed)
+	}
+	if _, err := os.Stat(paths.ForInstance("stale").InstanceFile); !os.IsNotExist(err) {
+		t.Errorf("stale instance state still exists: %v", err)
+	}
+	if _, err := os.Stat(paths.ForInstance("live").InstanceFile); err != nil {
+		t.Errorf("live instance state removed: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/secret"
+)
+
+var (
+	registryLoginUsername      string
+	registryLoginPasswordStdin bool
+	registryListOutput         string
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage OCI registry credentials",
+	Long: `Commands for managing the credentials klausctl uses to access OCI registries.
+
+Credentials are kept in the secret store (~/.config/klausctl/secrets.yaml)
+under registry.<host> and are used in addition to the Docker and Podman
+config files. They are ignored when KLAUSCTL_REGISTRY_AUTH is set.`,
+}
+
+var registryLoginCmd = &cobra.Command{
+	Use:   "login <registry>",
+	Short: "Store credentials for a registry",
+	Long: `Store a username and password or token for a registry.
+
+The password is read from stdin:
+
+  echo "$GITHUB_TOKEN" | klausctl registry login ghcr.io -u octocat --password-stdin`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRegistryLogin,
+}
+
+var registryLogoutCmd = &cobra.Command{
+	Use:   "logout <registry>",
+	Short: "Remove stored credentials for a registry",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRegistryLogout,
+}
+
+var registryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registries with stored credentials",
+	Long:  `List the registries with stored credentials and their usernames. Passwords are never displayed.`,
+	Args:  cobra.NoArgs,
+	RunE:  runRegistryList,
+}
+
+func init() {
+	registryLoginCmd.Flags().StringVarP(&registryLoginUsername, "username", "u", "", "registry username")
+	registryLoginCmd.Flags().BoolVar(&registryLoginPasswordStdin, "password-stdin", false, "read the password from stdin")
+	_ = registryLoginCmd.MarkFlagRequired("username")
+	registryListCmd.Flags().StringVarP(&registryListOutput, "output", "o", "text", "output format: text, json")
+
+	registryCmd.AddCommand(registryLoginCmd)
+	registryCmd.AddCommand(registryLogoutCmd)
+	registryCmd.AddCommand(registryListCmd)
+	rootCmd.AddCommand(registryCmd)
+}
+
+func runRegistryLogin(cmd *cobra.Command, args []string) error {
+	if !registryLoginPasswordStdin {
+		return fmt.Errorf("the password must be provided with --password-stdin")
+	}
+	password, err := readPassword(cmd.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	store, err := loadSecretStore()
+	if err != nil {
+		return err
+	}
+	if err := store.SetRegistryAuth(args[0], registryLoginUsername, password); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Credentials for %s saved.\n", args[0])
+	return nil
+}
+
+// readPassword reads a password from the first line of r.
+func readPassword(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	var password string
+	if scanner.Scan() {
+		password = strings.TrimSpace(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("reading from stdin: %w", err)
+	}
+	if password == "" {
+		return "", fmt.Errorf("no password provided on stdin")
+	}
+	return password, nil
+}
+
+func runRegistryLogout(cmd *cobra.Command, args []string) error {
+	store, err := loadSecretStore()
+	if err != nil {
+		return err
+	}
+	if err := store.DeleteRegistryAuth(args[0]); err != nil {
+		return err
+	}
+	if err := store.Save(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Credentials for %s removed.\n", args[0])
+	return nil
+}
+
+func runRegistryList(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(registryListOutput); err != nil {
+		return err
+	}
+	store, err := loadSecretStore()
+	if err != nil {
+		return err
+	}
+	creds, err := store.RegistryCredentials()
+	if err != nil {
+		return err
+	}
+	return writeRegistryCredentials(cmd.OutOrStdout(), creds, registryListOutput)
+}
+
+func writeRegistryCredentials(w io.Writer, creds []secret.RegistryCredential, format string) error {
+	if format == "json" {
+		return writeJSON(w, creds)
+	}
+	if len(creds) == 0 {
+		_, _ = fmt.Fprintln(w, "No registry credentials stored.")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "REGISTRY\tUSERNAME")
+	for _, c := range creds {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\n", c.Registry, c.Username)
+	}
+	return tw.Flush()
+}
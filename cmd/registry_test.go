@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/secret"
+)
+
+func TestRegistryCommandsRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "registry")
+	for _, name := range []string{"login", "logout", "list"} {
+		found := false
+		for _, c := range registryCmd.Commands() {
+			if c.Name() == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected registry %s subcommand", name)
+		}
+	}
+	assertFlagRegistered(t, registryLoginCmd, "username")
+	assertFlagRegistered(t, registryLoginCmd, "password-stdin")
+	assertFlagRegistered(t, registryListCmd, "output")
+}
+
+func TestReadPassword(t *testing.T) {
+	got, err := readPassword(strings.NewReader("s3cret\n"))
+	if err != nil || got != "s3cret" {
+		t.Errorf("readPassword = %q, %v", got, err)
+	}
+	if _, err := readPassword(strings.NewReader("\n")); err == nil {
+		t.Error("expected error for empty password")
+	}
+}
+
+func TestWriteRegistryCredentials(t *testing.T) {
+	creds := []secret.RegistryCredential{{Registry: "ghcr.io", Username: "octocat"}}
+
+	var text bytes.Buffer
+	if err := writeRegistryCredentials(&text, creds, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "ghcr.io") || !strings.Contains(text.String(), "octocat") {
+		t.Errorf("text output = %q", text.String())
+	}
+
+	var out bytes.Buffer
+	if err := writeRegistryCredentials(&out, creds, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []secret.RegistryCredential
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 1 || decoded[0] != creds[0] {
+		t.Errorf("json output = %v", decoded)
+	}
+
+	var empty bytes.Buffer
+	if err := writeRegistryCredentials(&empty, nil, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(empty.String(), "No registry credentials") {
+		t.Errorf("empty output = %q", empty.String())
+	}
+}
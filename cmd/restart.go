@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/agentclient"
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var (
+	restartAll         bool
+	restartSelector    string
+	restartRolling     bool
+	restartMaxParallel int
+	restartWait        bool
+)
+
+var restartCmd = &cobra.Command{
+	Use:   "restart [name]",
+	Short: "Restart a klaus instance",
+	Long: `Stop a klaus instance and start it again from its config, e.g. to pick up
+a new base image or config changes. The agent transcript is archived before
+the container is stopped, as with 'klausctl stop'.
+
+With --all, every running instance is restarted; --selector limits this to the
+instances whose name matches a glob pattern. By default all selected instances
+are restarted at once. Use --rolling to restart them in waves of at most
+--max-parallel instances instead, avoiding a resource spike; the next wave
+starts once the previous one has finished. With --wait, an instance only
+counts as restarted once its agent responds, so each wave also waits for its
+agents to become ready.
+
+  klausctl restart dev --wait
+  klausctl restart --all --rolling --max-parallel 2 --wait
+  klausctl restart --all --selector 'feature-*'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestart,
+}
+
+func init() {
+	restartCmd.Flags().BoolVar(&restartAll, "all", false, "restart all running instances")
+	restartCmd.Flags().StringVar(&restartSelector, "selector", "", "with --all, only restart instances whose name matches this glob pattern")
+	restartCmd.Flags().BoolVar(&restartRolling, "rolling", false, "with --all, restart instances in waves of --max-parallel")
+	restartCmd.Flags().IntVar(&restartMaxParallel, "max-parallel", 1, "with --rolling, the number of instances restarted per wave")
+	restartCmd.Flags().BoolVar(&restartWait, "wait", false, "wait for each restarted agent to become ready")
+	rootCmd.AddCommand(restartCmd)
+}
+
+func runRestart(cmd *cobra.Command, args []string) error {
+	if restartAll && len(args) > 0 {
+		return fmt.Errorf("--all cannot be used with an instance name")
+	}
+	if !restartAll && (restartSelector != "" || restartRolling) {
+		return fmt.Errorf("--selector and --rolling require --all")
+	}
+	if cmd.Flags().Changed("max-parallel") {
+		if !restartRolling {
+			return fmt.Errorf("--max-parallel requires --rolling")
+		}
+		if restartMaxParallel < 1 {
+			return fmt.Errorf("--max-parallel must be >= 1, got %d", restartMaxParallel)
+		}
+	}
+	if restartSelector != "" {
+		if _, err := path.Match(restartSelector, ""); err != nil {
+			return fmt.Errorf("invalid --selector pattern %q: %w", restartSelector, err)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	if !restartAll {
+		name, err := resolveOptionalInstanceName(args, "restart", cmd.ErrOrStderr())
+		if err != nil {
+			return err
+		}
+		return restartInstance(ctx, cmd, paths, name, restartWait)
+	}
+
+	instances, err := selectInstances(paths, restartSelector)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(instances) == 0 {
+		_, _ = fmt.Fprintln(out, "No klaus instances to restart.")
+		return nil
+	}
+
+	waveSize := len(instances)
+	if restartRolling {
+		waveSize = restartMaxParallel
+	}
+	return restartAllInstances(ctx, out, instances, waveSize, func(ctx context.Context, inst *instance.Instance) error {
+		// Start output of concurrent restarts would interleave; only the
+		// per-instance result is reported.
+		sub := &cobra.Command{}
+		sub.SetOut(io.Discard)
+		sub.SetErr(io.Discard)
+		return restartInstance(ctx, sub, paths, inst.Name, restartWait)
+	})
+}
+
+// selectInstances returns the running instances whose name matches the glob
+// selector (all when empty), sorted by name.
+func selectInstances(paths *config.Paths, selector string) ([]*instance.Instance, error) {
+	all, err := instance.LoadAll(paths)
+	if err != nil {
+		return nil, err
+	}
+	var selected []*instance.Instance
+	for _, inst := range all {
+		if selector != "" {
+			if ok, _ := path.Match(selector, inst.Name); !ok {
+				continue
+			}
+		}
+		selected = append(selected, inst)
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Name < selected[j].Name
+	})
+	return selected, nil
+}
+
+// restartAllInstances restarts instances in waves of waveSize and reports the
+// result of each instance as it finishes.
+func restartAllInstances(ctx context.Context, out io.Writer, instances []*instance.Instance, waveSize int, restart func(context.Context, *instance.Instance) error) error {
+	if waveSize < len(instances) {
+		_, _ = fmt.Fprintf(out, "Restarting %d instances, %d at a time...\n", len(instances), waveSize)
+	} else {
+		_, _ = fmt.Fprintf(out, "Restarting %d instances...\n", len(instances))
+	}
+
+	out = orchestrator.SyncWriter(out)
+	errs := instance.Waves(ctx, instances, waveSize, func(ctx context.Context, inst *instance.Instance) error {
+		err := restart(ctx, inst)
+		if err != nil {
+			_, _ = fmt.Fprintf(out, "  %s: %s %v\n", inst.Name, yellow("failed:"), err)
+		} else {
+			_, _ = fmt.Fprintf(out, "  %s: restarted\n", inst.Name)
+		}
+		return err
+	})
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d instances failed to restart", failed, len(instances))
+	}
+	_, _ = fmt.Fprintln(out, green("All klaus instances restarted."))
+	return nil
+}
+
+// restartInstance stops the container of the named instance, if any, and
+// starts the instance again. With wait, it returns once the agent responds.
+func restartInstance(ctx context.Context, cmd *cobra.Command, paths *config.Paths, name string, wait bool) error {
+	instPaths := paths.ForInstance(name)
+
+	if inst, err := instance.Load(instPaths); err == nil {
+		rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
+		if err != nil {
+			return err
+		}
+		if status, err := rt.Status(ctx, inst.ContainerName()); err == nil && status == "running" {
+			recordUsage(ctx, inst, paths)
+			archiveBeforeStop(ctx, inst, paths)
+		}
+		if err := stopAndRemoveContainerIfExists(ctx, rt, inst.ContainerName()); err != nil {
+			return err
+		}
+		if err := instance.Clear(instPaths); err != nil {
+			return fmt.Errorf("clearing instance state: %w", err)
+		}
+	}
+
+	if err := startInstance(cmd, name, "", ""); err != nil {
+		return err
+	}
+	if !wait {
+		return nil
+	}
+
+	inst, err := instance.Load(instPaths)
+	if err != nil {
+		return fmt.Errorf("loading instance state after restart: %w", err)
+	}
+	probe, err := config.LoadReadinessProbe(instPaths.ConfigFile)
+	if err != nil {
+		return err
+	}
+	agentURL := inst.AgentURL()
+	if err := agentclient.WaitForProbe(ctx, &http.Client{}, agentURL, probe); err != nil {
+		return fmt.Errorf("waiting for instance %q to become ready: %w", name, err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Instance %q is ready.\n", name)
+	return nil
+}
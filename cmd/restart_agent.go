@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/agentclient"
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/mcpclient"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// agentRestartCommand is the fallback for agents without a restart tool:
+// it sends SIGHUP to PID 1 of the container, the klaus agent, which starts
+// a new session on that signal. Whether it did is checked against the
+// session ID reported by the agent's /status endpoint.
+var agentRestartCommand = []string{"kill", "-HUP", "1"}
+
+var (
+	// agentRestartTimeout bounds the wait for the agent to report a new
+	// session after agentRestartCommand.
+	agentRestartTimeout = 30 * time.Second
+	// agentRestartPoll is the interval between /status polls.
+	agentRestartPoll = time.Second
+)
+
+var restartAgentCmd = &cobra.Command{
+	Use:   "restart-agent [name]",
+	Short: "Reset the agent of a klaus instance without restarting its container",
+	Long: `Reset the session of the klaus agent of a running instance while keeping
+the container and its mounts. This is faster than 'klausctl restart' when
+only the agent state is stuck.
+
+The agent is asked to restart through its MCP restart tool. When the agent
+does not offer that tool or does not respond, klausctl falls back to running
+'kill -HUP 1' in the container, which signals the agent to reset, and waits
+for the agent's /status endpoint to report a new session. When it does not,
+use 'klausctl restart' to restart the container.
+
+  klausctl restart-agent dev`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestartAgent,
+}
+
+// callAgentRestart calls the restart tool of the agent at baseURL. It is a
+// variable so tests can run without an agent.
+var callAgentRestart = func(ctx context.Context, name, baseURL string) (*mcp.CallToolResult, error) {
+	client := mcpclient.New(buildVersion)
+	defer client.Close()
+	return client.Restart(ctx, name, baseURL)
+}
+
+func init() {
+	rootCmd.AddCommand(restartAgentCmd)
+}
+
+func runRestartAgent(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	name, err := resolveOptionalInstanceName(args, "restart-agent", cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+
+	inst, err := instance.Load(paths.ForInstance(name))
+	if err != nil {
+		return fmt.Errorf("no klaus instance found for %q; run 'klausctl create %s <workspace>' first", name, name)
+	}
+	rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
+	if err != nil {
+		return err
+	}
+
+	return restartAgent(ctx, rt, inst, cmd.OutOrStdout(), cmd.ErrOrStderr())
+}
+
+// fetchAgentSession returns the session ID reported by the /status
+// endpoint of the agent at baseURL. It is a variable so tests can run
+// without an agent.
+var fetchAgentSession = func(ctx context.Context, baseURL string) (string, error) {
+	status, err := agentclient.FetchStatus(ctx, &http.Client{Timeout: 3 * time.Second}, baseURL)
+	if err != nil {
+		return "", err
+	}
+	return status.Agent.SessionID, nil
+}
+
+// restartAgent resets the agent of inst through its restart tool, or by
+// running agentRestartCommand in the container when the tool is
+// unavailable and confirming that the agent started a new session.
+func restartAgent(ctx context.Context, rt runtime.Runtime, inst *instance.Instance, out, errOut io.Writer) error {
+	status, err := rt.Status(ctx, inst.ContainerName())
+	if err != nil {
+		return fmt.Errorf("instance %q: unable to determine status: %w", inst.Name, err)
+	}
+	if status != "running" {
+		return fmt.Errorf("instance %q is not running (status: %s); run 'klausctl start %s' first", inst.Name, displayStatus(status), inst.Name)
+	}
+
+	result, err := callAgentRestart(ctx, inst.Name, inst.AgentURL()+"/mcp")
+	if err == nil && result.IsError {
+		err = fmt.Errorf("%s", mcpclient.ExtractText(result))
+	}
+	if err == nil {
+		_, _ = fmt.Fprintf(out, "Agent of instance %q restarted.\n", inst.Name)
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(errOut, "%s agent restart tool unavailable (%v); signalling the agent instead\n", yellow("Warning:"), err)
+	// An unreachable agent has no known session; any session it reports
+	// afterwards counts as new.
+	before, err := fetchAgentSession(ctx, inst.AgentURL())
+	known := err == nil
+	if err := rt.Exec(ctx, inst.ContainerName(), runtime.ExecOptions{
+		Command: agentRestartCommand,
+		Stdout:  out,
+		Stderr:  errOut,
+	}); err != nil {
+		return fmt.Errorf("restarting agent of instance %q: %w", inst.Name, err)
+	}
+	if err := waitForNewAgentSession(ctx, inst.AgentURL(), before, known); err != nil {
+		return fmt.Errorf("agent of instance %q did not restart: %w; run 'klausctl restart %s' to restart the container", inst.Name, err, inst.Name)
+	}
+	_, _ = fmt.Fprintf(out, "Agent of instance %q restarted.\n", inst.Name)
+	return nil
+}
+
+// waitForNewAgentSession polls the agent at baseURL until it reports a
+// session other than before, or any session when before is not known.
+func waitForNewAgentSession(ctx context.Context, baseURL, before string, known bool) error {
+	ctx, cancel := context.WithTimeout(ctx, agentRestartTimeout)
+	defer cancel()
+	for {
+		session, err := fetchAgentSession(ctx, baseURL)
+		if err == nil && (!known || session != before) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("agent unreachable after %s: %w", agentRestartTimeout, err)
+			}
+			return fmt.Errorf("session %q unchanged after %s", before, agentRestartTimeout)
+		case <-time.After(agentRestartPoll):
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klausctl/pkg/instance"
+)
+
+func TestRestartAgentRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "restart-agent")
+}
+
+func stubAgentRestart(t *testing.T, result *mcp.CallToolResult, err error) *[]string {
+	t.Helper()
+	orig := callAgentRestart
+	t.Cleanup(func() { callAgentRestart = orig })
+	var urls []string
+	callAgentRestart = func(_ context.Context, _, baseURL string) (*mcp.CallToolResult, error) {
+		urls = append(urls, baseURL)
+		return result, err
+	}
+	return &urls
+}
+
+// stubAgentSessions makes fetchAgentSession report sessions in order,
+// repeating the last one, and shortens the restart wait.
+func stubAgentSessions(t *testing.T, sessions ...string) {
+	t.Helper()
+	origFetch, origTimeout, origPoll := fetchAgentSession, agentRestartTimeout, agentRestartPoll
+	t.Cleanup(func() {
+		fetchAgentSession, agentRestartTimeout, agentRestartPoll = origFetch, origTimeout, origPoll
+	})
+	agentRestartTimeout, agentRestartPoll = 50*time.Millisecond, time.Millisecond
+	fetchAgentSession = func(context.Context, string) (string, error) {
+		s := sessions[0]
+		if len(sessions) > 1 {
+			sessions = sessions[1:]
+		}
+		return s, nil
+	}
+}
+
+func TestRestartAgentUsesTool(t *testing.T) {
+	urls := stubAgentRestart(t, mcp.NewToolResultText("restarted"), nil)
+	rt := &fakeRuntime{status: "running"}
+	inst := &instance.Instance{Name: "dev", Runtime: "docker", Port: 8080}
+
+	var out bytes.Buffer
+	if err := restartAgent(context.Background(), rt, inst, &out, &out); err != nil {
+		t.Fatalf("restartAgent() error = %v", err)
+	}
+	if !slices.Equal(*urls, []string{"http://localhost:8080/mcp"}) {
+		t.Errorf("restart tool called at %v", *urls)
+	}
+	if len(rt.execs) != 0 {
+		t.Errorf("expected no exec fallback, got %v", rt.execs)
+	}
+}
+
+func TestRestartAgentFallsBackToExec(t *testing.T) {
+	for name, stub := range map[string]struct {
+		result *mcp.CallToolResult
+		err    error
+	}{
+		"unreachable":  {err: errors.New("connection refused")},
+		"unknown tool": {result: mcp.NewToolResultError("tool restart not found")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			stubAgentRestart(t, stub.result, stub.err)
+			stubAgentSessions(t, "s1", "s1", "s2")
+			rt := &fakeRuntime{status: "running"}
+			inst := &instance.Instance{Name: "dev", Runtime: "docker", Port: 8080}
+
+			var out, errOut bytes.Buffer
+			if err := restartAgent(context.Background(), rt, inst, &out, &errOut); err != nil {
+				t.Fatalf("restartAgent() error = %v", err)
+			}
+			if len(rt.execs) != 1 || !slices.Equal(rt.execs[0].Command, agentRestartCommand) {
+				t.Fatalf("execs = %v, want %v", rt.execs, agentRestartCommand)
+			}
+			if rt.execs[0].TTY || rt.execs[0].Stdin != nil {
+				t.Error("expected a non-interactive exec")
+			}
+			if !strings.Contains(errOut.String(), "restart tool unavailable") {
+				t.Errorf("stderr = %q, want fallback warning", errOut.String())
+			}
+		})
+	}
+}
+
+func TestRestartAgentFallbackUnconfirmed(t *testing.T) {
+	stubAgentRestart(t, nil, errors.New("connection refused"))
+	stubAgentSessions(t, "s1")
+	rt := &fakeRuntime{status: "running"}
+	inst := &instance.Instance{Name: "dev", Runtime: "docker", Port: 8080}
+
+	err := restartAgent(context.Background(), rt, inst, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "did not restart") {
+		t.Errorf("error = %v, want unconfirmed restart", err)
+	}
+}
+
+func TestRestartAgentNotRunning(t *testing.T) {
+	urls := stubAgentRestart(t, nil, nil)
+	rt := &fakeRuntime{status: "exited"}
+	inst := &instance.Instance{Name: "dev", Runtime: "docker", Port: 8080}
+
+	err := restartAgent(context.Background(), rt, inst, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "is not running") {
+		t.Errorf("error = %v, want not running", err)
+	}
+	if len(*urls) != 0 || len(rt.execs) != 0 {
+		t.Error("expected no restart attempt")
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/instance"
+	runtimepkg "github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+func TestRestartFlags(t *testing.T) {
+	assertCommandOnRoot(t, "restart")
+	for _, flag := range []string{"all", "selector", "rolling", "max-parallel", "wait"} {
+		assertFlagRegistered(t, restartCmd, flag)
+	}
+}
+
+func TestRestartFlagValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		all     bool
+		rolling bool
+		set     map[string]string
+		want    string
+	}{
+		{name: "all with name", args: []string{"dev"}, all: true, want: "--all cannot be used"},
+		{name: "rolling without all", rolling: true, want: "require --all"},
+		{name: "max-parallel without rolling", all: true, set: map[string]string{"max-parallel": "2"}, want: "requires --rolling"},
+		{name: "zero max-parallel", all: true, rolling: true, set: map[string]string{"max-parallel": "0"}, want: "must be >= 1"},
+		{name: "bad selector", all: true, set: map[string]string{"selector": "["}, want: "invalid --selector"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restartAll, restartRolling = tt.all, tt.rolling
+			restartSelector, restartMaxParallel = "", 1
+			t.Cleanup(func() {
+				restartAll, restartRolling = false, false
+				restartSelector, restartMaxParallel = "", 1
+			})
+
+			cmd := &cobra.Command{}
+			cmd.Flags().StringVar(&restartSelector, "selector", "", "")
+			cmd.Flags().IntVar(&restartMaxParallel, "max-parallel", 1, "")
+			for k, v := range tt.set {
+				if err := cmd.Flags().Set(k, v); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := runRestart(cmd, tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("runRestart() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// restartTestInstances returns n instances named inst-0 .. inst-<n-1>.
+func restartTestInstances(n int) []*instance.Instance {
+	instances := make([]*instance.Instance, n)
+	for i := range instances {
+		instances[i] = &instance.Instance{Name: fmt.Sprintf("inst-%d", i), Runtime: "docker"}
+	}
+	return instances
+}
+
+func TestRestartAllRollingHonorsMaxParallel(t *testing.T) {
+	instances := restartTestInstances(5)
+	rt := &fakeRuntime{status: "running"}
+
+	var mu sync.Mutex
+	var running, peak int
+	restart := func(ctx context.Context, inst *instance.Instance) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		err := rt.Stop(ctx, inst.ContainerName(), runtimepkg.DefaultStopTimeout)
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return err
+	}
+
+	var out bytes.Buffer
+	if err := restartAllInstances(context.Background(), &out, instances, 2, restart); err != nil {
+		t.Fatalf("restartAllInstances() error = %v", err)
+	}
+
+	if peak > 2 {
+		t.Errorf("peak concurrent restarts = %d, want <= 2", peak)
+	}
+	if rt.stopCalls != 5 {
+		t.Errorf("stop calls = %d, want 5", rt.stopCalls)
+	}
+	for _, inst := range instances {
+		if !strings.Contains(out.String(), inst.Name+": restarted") {
+			t.Errorf("missing result for %s in:\n%s", inst.Name, out.String())
+		}
+	}
+	if !strings.Contains(out.String(), "2 at a time") {
+		t.Errorf("expected rolling progress, got:\n%s", out.String())
+	}
+}
+
+func TestRestartAllReportsFailures(t *testing.T) {
+	instances := restartTestInstances(3)
+
+	restart := func(_ context.Context, inst *instance.Instance) error {
+		if inst.Name == "inst-1" {
+			return errors.New("port in use")
+		}
+		return nil
+	}
+
+	var out bytes.Buffer
+	err := restartAllInstances(context.Background(), &out, instances, 1, restart)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 instances failed") {
+		t.Fatalf("restartAllInstances() error = %v, want 1 of 3 failed", err)
+	}
+	if !strings.Contains(out.String(), "inst-1:") || !strings.Contains(out.String(), "port in use") {
+		t.Errorf("expected failure of inst-1 to be reported, got:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "inst-2: restarted") {
+		t.Errorf("expected later waves to run after a failure, got:\n%s", out.String())
+	}
+}
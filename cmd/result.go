@@ -14,7 +14,6 @@ import (
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 var (
@@ -99,7 +98,7 @@ func runResult(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no klaus instance found for %q; run 'klausctl create %s <workspace>' first", instanceName, instanceName)
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		return err
 	}
@@ -112,7 +111,7 @@ func runResult(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("instance %q is not running (status: %s); run 'klausctl start %s' first", instanceName, status, instanceName)
 	}
 
-	baseURL := fmt.Sprintf("http://localhost:%d/mcp", inst.Port)
+	baseURL := inst.AgentURL() + "/mcp"
 
 	client := mcpclient.New(buildVersion)
 	defer client.Close()
@@ -129,7 +128,7 @@ func runResult(cmd *cobra.Command, args []string) error {
 
 	result := parseResultResponse(instanceName, toolResult)
 
-	return renderResultOutput(out, result)
+	return renderResultOutput(out, resultOutput, result)
 }
 
 // parseResultResponse extracts status, message_count and result_text from the
@@ -165,8 +164,8 @@ func parseResultResponse(instanceName string, toolResult *mcp.CallToolResult) re
 	}
 }
 
-func renderResultOutput(out io.Writer, result resultCLIResult) error {
-	if resultOutput == "json" {
+func renderResultOutput(out io.Writer, outputFmt string, result resultCLIResult) error {
+	if outputFmt == "json" {
 		enc := json.NewEncoder(out)
 		enc.SetIndent("", "  ")
 		return enc.Encode(result)
@@ -208,9 +208,8 @@ func TestRenderResultOutput_Text(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			resultOutput = "text" //nolint:goconst
 			var buf bytes.Buffer
-			err := renderResultOutput(&buf, tt.result)
+			err := renderResultOutput(&buf, "text", tt.result) //nolint:goconst
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -225,9 +224,6 @@ func TestRenderResultOutput_Text(t *testing.T) {
 }
 
 func TestRenderResultOutput_JSON(t *testing.T) {
-	resultOutput = "json"
-	t.Cleanup(func() { resultOutput = "text" })
-
 	result := resultCLIResult{
 		Instance:     "dev",
 		Status:       "completed",
@@ -236,7 +232,7 @@ func TestRenderResultOutput_JSON(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	err := renderResultOutput(&buf, result)
+	err := renderResultOutput(&buf, "json", result)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -24,10 +25,12 @@ type rollbackRuntime struct {
 
 	removeCalls []string
 	stopCalls   []string
+	runOpts     []runtimepkg.RunOptions
 }
 
 func (r *rollbackRuntime) Name() string { return "fake" }
 func (r *rollbackRuntime) Run(_ context.Context, opts runtimepkg.RunOptions) (string, error) {
+	r.runOpts = append(r.runOpts, opts)
 	if r.runErr != nil {
 		return "", r.runErr
 	}
@@ -37,7 +40,7 @@ func (r *rollbackRuntime) Run(_ context.Context, opts runtimepkg.RunOptions) (st
 	}
 	return id, nil
 }
-func (r *rollbackRuntime) Stop(_ context.Context, name string) error {
+func (r *rollbackRuntime) Stop(_ context.Context, name string, _ time.Duration) error {
 	r.stopCalls = append(r.stopCalls, name)
 	return nil
 }
@@ -45,22 +48,37 @@ func (r *rollbackRuntime) Remove(_ context.Context, name string) error {
 	r.removeCalls = append(r.removeCalls, name)
 	return r.removeErr
 }
+func (r *rollbackRuntime) Start(_ context.Context, _ string) error   { return nil }
+func (r *rollbackRuntime) Pause(_ context.Context, _ string) error   { return nil }
+func (r *rollbackRuntime) Unpause(_ context.Context, _ string) error { return nil }
 func (r *rollbackRuntime) Status(_ context.Context, _ string) (string, error) {
 	return "", nil
 }
 func (r *rollbackRuntime) Inspect(_ context.Context, _ string) (*runtimepkg.ContainerInfo, error) {
 	return nil, fmt.Errorf("not found")
 }
-func (r *rollbackRuntime) Logs(_ context.Context, _ string, _ bool, _ int) error { return nil }
+func (r *rollbackRuntime) Logs(_ context.Context, _ string, _ bool, _ int, _, _ io.Writer) error {
+	return nil
+}
 func (r *rollbackRuntime) LogsCapture(_ context.Context, _ string, _ int) (string, error) {
 	return "", nil
 }
-func (r *rollbackRuntime) Pull(_ context.Context, _ string, _ io.Writer) error {
+func (r *rollbackRuntime) Pull(_ context.Context, _ string, _ runtimepkg.PullOptions, _ io.Writer) error {
 	return r.pullErr
 }
 func (r *rollbackRuntime) Images(_ context.Context, _ string) ([]runtimepkg.ImageInfo, error) {
 	return nil, nil
 }
+func (r *rollbackRuntime) Version(_ context.Context) (string, error) { return "", nil }
+func (r *rollbackRuntime) ListManaged(_ context.Context) ([]runtimepkg.ContainerInfo, error) {
+	return nil, nil
+}
+func (r *rollbackRuntime) Events(_ context.Context, _ string, _ func(runtimepkg.Event) error) error {
+	return nil
+}
+func (r *rollbackRuntime) Exec(_ context.Context, _ string, _ runtimepkg.ExecOptions) error {
+	return nil
+}
 
 // setupCreateEnv prepares a temp config home and workspace directory and
 // resets global create flags. Returns (configHome, workspace).
@@ -102,7 +120,7 @@ func setupCreateEnv(t *testing.T) (string, string) {
 func overrideRuntime(t *testing.T, rt *rollbackRuntime) {
 	t.Helper()
 	orig := newRuntime
-	newRuntime = func(_ string) (runtimepkg.Runtime, error) { return rt, nil }
+	newRuntime = func(_, _ string) (runtimepkg.Runtime, error) { return rt, nil }
 	t.Cleanup(func() { newRuntime = orig })
 }
 
@@ -270,3 +288,45 @@ func TestStartInstanceRollbackRemovesContainerOnSaveFailure(t *testing.T) {
 		t.Fatalf("expected Remove call for %q, got: %v", expectedContainer, rt.removeCalls)
 	}
 }
+
+// TestStartInstanceImageFlagOverridesConfig verifies that --image replaces
+// the image resolved from the instance config.
+func TestStartInstanceImageFlagOverridesConfig(t *testing.T) {
+	configHome := filepath.Join(t.TempDir(), "config-home")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	workspace := filepath.Join(t.TempDir(), "workspace")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	instanceDir := filepath.Join(configHome, "klausctl", "instances", "image-flag")
+	if err := os.MkdirAll(instanceDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	configContent := fmt.Sprintf("workspace: %s\nport: 9999\ntoolchain: fake-image:latest\n", workspace)
+	if err := os.WriteFile(filepath.Join(instanceDir, "config.yaml"), []byte(configContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rt := &rollbackRuntime{runErr: fmt.Errorf("stop here")}
+	overrideRuntime(t, rt)
+
+	orig := startImage
+	t.Cleanup(func() { startImage = orig })
+	startImage = "registry.example.com/custom/klaus:v1"
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+
+	if err := startInstance(cmd, "image-flag", "", ""); err == nil {
+		t.Fatal("expected error from container run failure")
+	}
+	if len(rt.runOpts) != 1 {
+		t.Fatalf("expected one Run call, got %d", len(rt.runOpts))
+	}
+	if got := rt.runOpts[0].Image; got != startImage {
+		t.Errorf("Run image = %q, want %q", got, startImage)
+	}
+}
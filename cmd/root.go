@@ -2,17 +2,29 @@
 package cmd
 
 import (
+	"io"
+
 	"github.com/spf13/cobra"
 
+	"github.com/giantswarm/klausctl/pkg/cabundle"
+	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/ocicache"
 )
 
 // applyCacheFlags propagates the global --cache-dir / --no-cache flag state
-// into the ocicache package. Registered with cobra.OnInitialize so it fires
+// into the ocicache package, and --cache-dir into the artifact paths. Registered with cobra.OnInitialize so it fires
 // on every Execute() regardless of whether a subcommand defines its own
 // PreRun hook (a child's PreRun otherwise shadows the root's).
 func applyCacheFlags() {
 	ocicache.Configure(cacheDirFlag, noCacheFlag)
+	config.SetCacheDir(cacheDirFlag)
+}
+
+// applyCAFlags trusts the CA certificates of --ca-file and --ca-dir. An
+// unreadable CA file aborts the command, since every registry and MCP
+// connection would fail with a less clear TLS error otherwise.
+func applyCAFlags() {
+	cobra.CheckErr(cabundle.Configure(caFileFlag, caDirFlag))
 }
 
 var (
@@ -23,12 +35,24 @@ var (
 	// cfgFile is the optional path to the config file (overrides default).
 	cfgFile string
 
-	// cacheDirFlag overrides the on-disk OCI cache directory. Empty means
-	// "use the XDG-derived default".
+	// cacheDirFlag overrides the on-disk OCI cache directory, which then
+	// also holds the pulled plugins and personalities. Empty means "use the
+	// defaults".
 	cacheDirFlag string
 
 	// noCacheFlag bypasses the OCI cache for this invocation.
 	noCacheFlag bool
+
+	// caFileFlag and caDirFlag add CA certificates trusted for registry
+	// and MCP TLS connections.
+	caFileFlag string
+	caDirFlag  string
+
+	// quietFlag suppresses progress output; final results are still printed.
+	quietFlag bool
+
+	// noColorFlag disables colored output, like the NO_COLOR env var.
+	noColorFlag bool
 )
 
 // SetBuildInfo sets the build metadata for version display.
@@ -51,15 +75,29 @@ local-mode counterpart to the Helm chart and the klaus-operator.`,
 	SilenceErrors: true,
 }
 
+// progressWriter returns the writer for progress lines such as "Pulling
+// ...": w itself, or io.Discard when --quiet is set.
+func progressWriter(w io.Writer) io.Writer {
+	if quietFlag {
+		return io.Discard
+	}
+	return w
+}
+
 // Execute runs the root command.
 func Execute() error {
+	registerFlagValueCompletions(rootCmd)
 	return rootCmd.Execute()
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ~/.config/klausctl/instances/default/config.yaml)")
-	rootCmd.PersistentFlags().StringVar(&cacheDirFlag, "cache-dir", "", "override OCI cache directory (default: $XDG_CACHE_HOME/klausctl/oci)")
+	rootCmd.PersistentFlags().StringVar(&cacheDirFlag, "cache-dir", "", "override the OCI cache directory, which then also holds pulled plugins and personalities (also set via KLAUSCTL_CACHE_DIR)")
 	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "bypass the OCI cache for this invocation (also set via KLAUSCTL_NO_CACHE=1)")
+	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "suppress progress output and only print the final result")
+	rootCmd.PersistentFlags().BoolVar(&noColorFlag, "no-color", false, "disable colored output (also set via NO_COLOR; off when stdout is not a terminal)")
+	rootCmd.PersistentFlags().StringVar(&caFileFlag, "ca-file", "", "PEM CA bundle to trust for registry and MCP TLS (also set via KLAUSCTL_CA_BUNDLE)")
+	rootCmd.PersistentFlags().StringVar(&caDirFlag, "ca-dir", "", "directory of PEM CA certificates (.pem, .crt, .cer) to trust for registry and MCP TLS")
 
-	cobra.OnInitialize(applyCacheFlags)
+	cobra.OnInitialize(applyColorFlag, applyCacheFlags, applyCAFlags)
 }
@@ -23,6 +23,7 @@ var (
 	runPort              int
 	runEnv               []string
 	runEnvForward        []string
+	runEnvFile           string
 	runSecretEnv         []string
 	runSecretFile        []string
 	runMcpServer         []string
@@ -78,6 +79,7 @@ func init() {
 	runCmd.Flags().StringSliceVar(&runPlugins, "plugin", nil, "additional plugin short name or OCI reference (repeatable)")
 	runCmd.Flags().IntVar(&runPort, "port", 0, "override auto-selected port")
 	runCmd.Flags().StringArrayVar(&runEnv, "env", nil, "environment variable KEY=VALUE (repeatable)")
+	runCmd.Flags().StringVar(&runEnvFile, "env-file", "", "dotenv file with KEY=VALUE lines; --env values take precedence")
 	runCmd.Flags().StringArrayVar(&runEnvForward, "env-forward", nil, "host environment variable name to forward (repeatable)")
 	runCmd.Flags().StringVar(&runPermMode, "permission-mode", "", "Claude permission mode: default, acceptEdits, bypassPermissions, dontAsk, plan, delegate")
 	runCmd.Flags().StringVar(&runModel, "model", "", "Claude model (e.g. sonnet, opus)")
@@ -131,6 +133,7 @@ func runRun(cmd *cobra.Command, args []string) error {
 		Port:            runPort,
 		Env:             runEnv,
 		EnvForward:      runEnvForward,
+		EnvFile:         runEnvFile,
 		SecretEnv:       runSecretEnv,
 		SecretFile:      runSecretFile,
 		McpServer:       runMcpServer,
@@ -170,10 +173,15 @@ func runRun(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("loading instance state after create: %w", err)
 	}
 
-	agentURL := fmt.Sprintf("http://localhost:%d", inst.Port)
+	probe, err := config.LoadReadinessProbe(instancePaths.ConfigFile)
+	if err != nil {
+		return err
+	}
+
+	agentURL := inst.AgentURL()
 	httpClient := &http.Client{}
 
-	if err := agentclient.WaitForReady(ctx, httpClient, agentURL); err != nil {
+	if err := agentclient.WaitForProbe(ctx, httpClient, agentURL, probe); err != nil {
 		return fmt.Errorf("waiting for instance %q to become ready: %w", instanceName, err)
 	}
 
@@ -17,6 +17,7 @@ func TestRunCommandHasAllCreateFlags(t *testing.T) {
 		"port",
 		"env",
 		"env-forward",
+		"env-file",
 		"permission-mode",
 		"model",
 		"system-prompt",
@@ -21,7 +21,15 @@ var secretCmd = &cobra.Command{
 
 Secrets are stored in ~/.config/klausctl/secrets.yaml with owner-only
 permissions (0600). They can be referenced by name in instance configs
-via secretEnvVars, secretFiles, and mcpServerRefs.`,
+via secretEnvVars, secretFiles, and mcpServerRefs.
+
+Instead of the value itself, an entry in secrets.yaml can name an external
+provider that is queried whenever the secret is used, so that the value is
+never written to disk:
+
+  github-token: {env: GITHUB_TOKEN}
+  db-password: {file: ~/.secrets/db-password}
+  slack-token: {command: ["op", "read", "op://dev/slack/token"]}`,
 }
 
 var secretSetCmd = &cobra.Command{
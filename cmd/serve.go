@@ -1,6 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	mcpserver "github.com/mark3labs/mcp-go/server"
 	"github.com/spf13/cobra"
 
@@ -13,11 +25,12 @@ import (
 	workspacetools "github.com/giantswarm/klausctl/internal/tools/workspace"
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
+	"github.com/giantswarm/klausctl/pkg/secret"
 )
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
-	Short: "Run the MCP server over stdio",
+	Short: "Run the MCP server over stdio or HTTP",
 	Long: `Run an MCP (Model Context Protocol) server over stdio, exposing klausctl's
 container lifecycle and artifact management as MCP tools.
 
@@ -30,16 +43,75 @@ Configure in your IDE:
     {"mcpServers":{"klausctl":{"command":"klausctl","args":["serve"]}}}
 
   Claude Code (settings):
-    {"mcpServers":{"klausctl":{"command":"klausctl","args":["serve"]}}}`,
+    {"mcpServers":{"klausctl":{"command":"klausctl","args":["serve"]}}}
+
+With --http, the same tools are served over the streamable HTTP transport
+(which streams responses as SSE) at /mcp on that address instead of stdio,
+so that remote clients can drive klausctl:
+
+  klausctl serve --http localhost:8090 --auth-secret klausctl-token
+
+With --auth-secret, requests must carry the value of that secret (see
+'klausctl secret set') as a bearer token. Without it, anyone who can reach
+the address can manage instances and mount host directories into them, so
+an address other than localhost is refused unless --insecure is given.
+
+With --metrics-addr (or KLAUSCTL_METRICS_ADDR), Prometheus metrics are served
+over HTTP at /metrics on that address: tool calls per tool, instances by
+container status, and image and plugin pull durations.
+
+With OTEL_EXPORTER_OTLP_ENDPOINT set, instance creation, starts and stops
+are traced with OpenTelemetry and exported over OTLP/HTTP, including the
+OCI pulls and container runtime calls they make.
+
+On SIGTERM or SIGINT, in-flight tool calls are cancelled and the server
+shuts down. With --stop-on-exit, the instances it started and that are still
+running are then stopped and removed, their transcripts archived first, so
+that killing the server does not leave orphaned containers.`,
 	SilenceUsage: true,
 	RunE:         runServe,
 }
 
+// metricsAddrEnv is the environment variable that enables the metrics
+// endpoint when --metrics-addr is not set.
+const metricsAddrEnv = "KLAUSCTL_METRICS_ADDR"
+
+// serveShutdownTimeout bounds the shutdown hooks, such as stopping the
+// instances started by the server.
+const serveShutdownTimeout = time.Minute
+
+// serveHTTPPath is the path of the MCP endpoint with --http.
+const serveHTTPPath = "/mcp"
+
+var (
+	serveMetricsAddr string
+	serveStopOnExit  bool
+	serveHTTPAddr    string
+	serveAuthSecret  string
+	serveInsecure    bool
+)
+
 func init() {
+	serveCmd.Flags().StringVar(&serveHTTPAddr, "http", "", "serve MCP over HTTP at "+serveHTTPPath+" on this address, e.g. localhost:8090, instead of stdio")
+	serveCmd.Flags().StringVar(&serveAuthSecret, "auth-secret", "", "with --http, require the value of this secret as a bearer token")
+	serveCmd.Flags().BoolVar(&serveInsecure, "insecure", false, "with --http, allow listening on a non-loopback address without --auth-secret")
+	serveCmd.Flags().StringVar(&serveMetricsAddr, "metrics-addr", "", "serve Prometheus metrics at /metrics on this address, e.g. localhost:9090 (also set via "+metricsAddrEnv+")")
+	serveCmd.Flags().BoolVar(&serveStopOnExit, "stop-on-exit", false, "stop the instances started by the server when it exits")
+
 	rootCmd.AddCommand(serveCmd)
 }
 
 func runServe(_ *cobra.Command, _ []string) error {
+	if serveAuthSecret != "" && serveHTTPAddr == "" {
+		return fmt.Errorf("--auth-secret requires --http")
+	}
+	if serveInsecure && serveHTTPAddr == "" {
+		return fmt.Errorf("--insecure requires --http")
+	}
+	if serveHTTPAddr != "" && serveAuthSecret == "" && !serveInsecure && !isLoopbackAddr(serveHTTPAddr) {
+		return fmt.Errorf("refusing to serve MCP on %s without authentication: use --auth-secret, a localhost address, or --insecure", serveHTTPAddr)
+	}
+
 	paths, err := config.DefaultPaths()
 	if err != nil {
 		return err
@@ -48,6 +120,17 @@ func runServe(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
+	var authToken string
+	if serveAuthSecret != "" {
+		store, err := secret.Load(paths.SecretsFile)
+		if err != nil {
+			return err
+		}
+		if authToken, err = store.Get(serveAuthSecret); err != nil {
+			return err
+		}
+	}
+
 	sourceCfg, err := config.LoadSourceConfig(paths.SourcesFile)
 	if err != nil {
 		return err
@@ -62,21 +145,162 @@ func runServe(_ *cobra.Command, _ []string) error {
 	}
 	serverCtx.SetSourceConfig(sourceCfg)
 
-	mcpSrv := mcpserver.NewMCPServer(
-		"klausctl",
-		buildVersion,
+	tracer, shutdownTracing, err := server.NewTracer(context.Background())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Warning: flushing traces: %v", err)
+		}
+	}()
+	serverCtx.Tracer = tracer
+
+	opts := []mcpserver.ServerOption{
 		mcpserver.WithToolCapabilities(false),
 		mcpserver.WithInstructions(serverInstructions()),
-	)
+	}
 
-	instancetools.RegisterTools(mcpSrv, serverCtx)
-	artifacttools.RegisterTools(mcpSrv, serverCtx)
-	cachetools.RegisterTools(mcpSrv, serverCtx)
-	mustertools.RegisterTools(mcpSrv, serverCtx)
-	gatewaytools.RegisterTools(mcpSrv, serverCtx)
-	workspacetools.RegisterTools(mcpSrv, serverCtx)
+	metricsAddr := serveMetricsAddr
+	if metricsAddr == "" {
+		metricsAddr = os.Getenv(metricsAddrEnv)
+	}
+	if metricsAddr != "" {
+		serverCtx.Metrics = server.NewMetrics()
+		opts = append(opts, mcpserver.WithToolHandlerMiddleware(serverCtx.Metrics.ToolMiddleware))
+		stop, err := serveMetrics(metricsAddr, serverCtx)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
+	mcpSrv := newMCPServer(serverCtx, opts...)
 
-	return mcpserver.ServeStdio(mcpSrv)
+	if serveStopOnExit {
+		serverCtx.OnShutdown(func(ctx context.Context) error {
+			return instancetools.StopStartedInstances(ctx, serverCtx)
+		})
+	}
+
+	// Tool calls run with contexts derived from ctx, so a signal cancels
+	// them before the shutdown hooks run.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+	var serveErr error
+	if serveHTTPAddr != "" {
+		if authToken == "" {
+			log.Printf("Warning: serving MCP over HTTP on %s without authentication; use --auth-secret", serveHTTPAddr)
+		}
+		serveErr = serveMCPHTTP(ctx, serveHTTPAddr, newServeHTTPHandler(mcpSrv, authToken))
+	} else {
+		serveErr = mcpserver.NewStdioServer(mcpSrv).Listen(ctx, os.Stdin, os.Stdout)
+	}
+	if errors.Is(serveErr, context.Canceled) {
+		serveErr = nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := serverCtx.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Warning: shutting down: %v", err)
+	}
+	return serveErr
+}
+
+// newMCPServer creates the klausctl MCP server with all tools registered
+// against sc.
+func newMCPServer(sc *server.ServerContext, opts ...mcpserver.ServerOption) *mcpserver.MCPServer {
+	mcpSrv := mcpserver.NewMCPServer("klausctl", buildVersion, opts...)
+
+	instancetools.RegisterTools(mcpSrv, sc)
+	artifacttools.RegisterTools(mcpSrv, sc)
+	cachetools.RegisterTools(mcpSrv, sc)
+	mustertools.RegisterTools(mcpSrv, sc)
+	gatewaytools.RegisterTools(mcpSrv, sc)
+	workspacetools.RegisterTools(mcpSrv, sc)
+	return mcpSrv
+}
+
+// newServeHTTPHandler serves mcpSrv over the streamable HTTP transport at
+// serveHTTPPath. A non-empty token is required as a bearer token.
+func newServeHTTPHandler(mcpSrv *mcpserver.MCPServer, token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(serveHTTPPath, mcpserver.NewStreamableHTTPServer(mcpSrv, mcpserver.WithEndpointPath(serveHTTPPath)))
+	if token == "" {
+		return mux
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackAddr reports whether the host of addr is localhost or a
+// loopback IP. An address without a host listens on all interfaces.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveMCPHTTP serves handler on addr until ctx is done, then shuts the
+// server down. In-flight requests are cancelled with ctx.
+func serveMCPHTTP(ctx context.Context, addr string, handler http.Handler) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listening for MCP on %s: %w", addr, err)
+	}
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	log.Printf("Serving MCP at http://%s%s", ln.Addr(), serveHTTPPath)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = srv.Shutdown(shutdownCtx)
+	return ctx.Err()
+}
+
+// serveMetrics serves the metrics endpoint of sc on addr in the background
+// and returns a function that shuts it down. Stdout carries the MCP
+// protocol, so serving errors are logged to stderr.
+func serveMetrics(addr string, sc *server.ServerContext) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening for metrics on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(server.MetricsPath, sc.MetricsHandler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: metrics endpoint stopped: %v", err)
+		}
+	}()
+	return func() { _ = srv.Close() }, nil
 }
 
 func serverInstructions() string {
@@ -1,7 +1,105 @@
 package cmd
 
-import "testing"
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	mcpclient "github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klausctl/internal/server"
+	"github.com/giantswarm/klausctl/pkg/config"
+)
 
 func TestServeCommandRegistered(t *testing.T) {
 	assertCommandOnRoot(t, "serve")
+	assertFlagRegistered(t, serveCmd, "metrics-addr")
+	assertFlagRegistered(t, serveCmd, "stop-on-exit")
+	assertFlagRegistered(t, serveCmd, "http")
+	assertFlagRegistered(t, serveCmd, "auth-secret")
+	assertFlagRegistered(t, serveCmd, "insecure")
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"localhost:8090":   true,
+		"127.0.0.1:8090":   true,
+		"[::1]:8090":       true,
+		":8090":            false,
+		"0.0.0.0:8090":     false,
+		"192.168.1.5:8090": false,
+		"example.com:8090": false,
+		"localhost":        false,
+	} {
+		if got := isLoopbackAddr(addr); got != want {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestServeRefusesUnauthenticatedNonLoopback(t *testing.T) {
+	origAddr, origInsecure := serveHTTPAddr, serveInsecure
+	t.Cleanup(func() { serveHTTPAddr, serveInsecure = origAddr, origInsecure })
+	serveHTTPAddr, serveInsecure = ":8090", false
+
+	err := runServe(nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "without authentication") {
+		t.Fatalf("error = %v, want refusal", err)
+	}
+}
+
+func TestServeHTTPListsTools(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(t.TempDir(), "config-home"))
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcpSrv := newMCPServer(&server.ServerContext{Paths: paths})
+	ts := httptest.NewServer(newServeHTTPHandler(mcpSrv, "s3cret"))
+	t.Cleanup(ts.Close)
+
+	resp, err := http.Post(ts.URL+serveHTTPPath, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	ctx := context.Background()
+	client, err := mcpclient.NewStreamableHttpClient(ts.URL+serveHTTPPath,
+		transport.WithHTTPHeaders(map[string]string{"Authorization": "Bearer s3cret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	if err := client.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	initReq := mcp.InitializeRequest{}
+	initReq.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	if _, err := client.Initialize(ctx, initReq); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	tools, err := client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	for _, want := range []string{"klaus_create", "klaus_list", "klaus_plugin_list", "klaus_source_list"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("tools %v missing %s", names, want)
+		}
+	}
 }
@@ -1,7 +1,11 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -15,11 +19,19 @@ var (
 	sourceAddPersonalities string
 	sourceAddPlugins       string
 	sourceAddDefault       bool
+	sourceSetDefaultUnset  bool
+	sourceAddForce         bool
+	sourceAddMirrors       []string
 
 	sourceUpdateRegistry      string
 	sourceUpdateToolchains    string
 	sourceUpdatePersonalities string
 	sourceUpdatePlugins       string
+	sourceUpdateMirrors       []string
+
+	sourceExportOut            string
+	sourceExportFile           string
+	sourceExportIncludeBuiltin bool
 )
 
 var sourceCmd = &cobra.Command{
@@ -49,10 +61,20 @@ Artifact type paths are derived by convention from the registry base:
   - Personalities: <registry>/klaus-personalities/<name>
   - Plugins:       <registry>/klaus-plugins/<name>
 
-Use --toolchains, --personalities, or --plugins to override individual paths.`,
+Use --toolchains, --personalities, or --plugins to override individual paths.
+
+Use --mirror to add registry bases that serve the same artifacts, e.g. for
+air-gapped or geo-distributed setups. Pulls try the mirrors in order and fall
+back to the registry.
+
+With --default, the new source replaces the current default source for
+short-name resolution. You are asked to confirm the switch unless --force is
+set, and the source that lost default status is reported.`,
 	Example: `  klausctl source add my-team --registry my-registry.io/my-team
   klausctl source add my-team --registry my-registry.io/my-team --default
-  klausctl source add custom --registry custom.io/org --toolchains custom.io/org/tools`,
+  klausctl source add my-team --registry my-registry.io/my-team --default --force
+  klausctl source add custom --registry custom.io/org --toolchains custom.io/org/tools
+  klausctl source add my-team --registry my-registry.io/my-team --mirror mirror.internal/my-team`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSourceAdd,
 }
@@ -63,10 +85,12 @@ var sourceUpdateCmd = &cobra.Command{
 	Long: `Update the registry URL or artifact path overrides for an existing source.
 
 Only the flags you provide are changed; other fields are preserved.
-Use "-" as a value to clear an override back to the convention-based default.`,
+Use "-" as a value to clear an override back to the convention-based default.
+--mirror replaces all mirrors of the source; --mirror - removes them.`,
 	Example: `  klausctl source update my-team --registry new-registry.io/my-team
   klausctl source update my-team --toolchains new-registry.io/my-team/custom-tools
-  klausctl source update my-team --toolchains -`,
+  klausctl source update my-team --toolchains -
+  klausctl source update my-team --mirror mirror-eu.internal/my-team --mirror mirror-us.internal/my-team`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSourceUpdate,
 }
@@ -79,12 +103,38 @@ var sourceRemoveCmd = &cobra.Command{
 	RunE:  runSourceRemove,
 }
 
+var sourceRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a source",
+	Long: `Rename a named source, keeping its registry, path overrides and default
+status. The built-in "giantswarm" source cannot be renamed.
+
+Instance configs store fully resolved artifact references rather than source
+names, so existing instances are not affected. Update any --source flags in
+scripts to the new name.`,
+	Example: `  klausctl source rename my-team platform`,
+	Args:    cobra.ExactArgs(2),
+	RunE:    runSourceRename,
+}
+
 var sourceSetDefaultCmd = &cobra.Command{
 	Use:   "set-default <name>",
 	Short: "Set the default source",
-	Long:  `Set the named source as the default for short-name resolution.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runSourceSetDefault,
+	Long: `Set the named source as the default for short-name resolution.
+
+With --unset, no source is the default: short names such as "go" are no
+longer resolved and artifacts must be given by their full reference or with
+--source. The built-in source does not become the default again until a
+default is set.`,
+	Example: `  klausctl source set-default my-team
+  klausctl source set-default --unset`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if sourceSetDefaultUnset {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: runSourceSetDefault,
 }
 
 var sourceShowCmd = &cobra.Command{
@@ -96,25 +146,49 @@ registry paths for toolchains, personalities, and plugins.`,
 	RunE: runSourceShow,
 }
 
+var sourceExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the configured sources",
+	Long: `Write the configured sources in the sources file format, to share a team's
+registry configuration or keep it under version control.
+
+The built-in "giantswarm" source is left out unless --include-builtin is set
+or it has been customized, since klausctl always adds it back.`,
+	Args: cobra.NoArgs,
+	RunE: runSourceExport,
+}
+
 func init() {
 	sourceAddCmd.Flags().StringVar(&sourceAddRegistry, "registry", "", "registry base URL (required)")
 	sourceAddCmd.Flags().StringVar(&sourceAddToolchains, "toolchains", "", "override toolchain registry path")
 	sourceAddCmd.Flags().StringVar(&sourceAddPersonalities, "personalities", "", "override personality registry path")
 	sourceAddCmd.Flags().StringVar(&sourceAddPlugins, "plugins", "", "override plugin registry path")
 	sourceAddCmd.Flags().BoolVar(&sourceAddDefault, "default", false, "set as the default source")
+	sourceAddCmd.Flags().BoolVar(&sourceAddForce, "force", false, "replace the current default source without confirmation")
+	sourceAddCmd.Flags().StringArrayVar(&sourceAddMirrors, "mirror", nil, "mirror registry base to pull from before the registry (repeatable)")
 	_ = sourceAddCmd.MarkFlagRequired("registry")
 
 	sourceUpdateCmd.Flags().StringVar(&sourceUpdateRegistry, "registry", "", "update registry base URL")
 	sourceUpdateCmd.Flags().StringVar(&sourceUpdateToolchains, "toolchains", "", "update toolchain registry path override")
 	sourceUpdateCmd.Flags().StringVar(&sourceUpdatePersonalities, "personalities", "", "update personality registry path override")
 	sourceUpdateCmd.Flags().StringVar(&sourceUpdatePlugins, "plugins", "", "update plugin registry path override")
+	sourceUpdateCmd.Flags().StringArrayVar(&sourceUpdateMirrors, "mirror", nil, "replace the mirror registry bases (repeatable; \"-\" removes all)")
+
+	sourceSetDefaultCmd.Flags().BoolVar(&sourceSetDefaultUnset, "unset", false, "clear the default source")
+
+	sourceExportCmd.Flags().StringVarP(&sourceExportOut, "output", "o", "yaml", "output format: yaml, json")
+	_ = sourceExportCmd.RegisterFlagCompletionFunc("output", completeValues("yaml", "json"))
+	sourceExportCmd.Flags().StringVar(&sourceExportFile, "file", "", "write to this file instead of stdout")
+	sourceExportCmd.Flags().BoolVar(&sourceExportIncludeBuiltin, "include-builtin", false, "include the built-in source")
 
 	sourceCmd.AddCommand(sourceListCmd)
 	sourceCmd.AddCommand(sourceAddCmd)
 	sourceCmd.AddCommand(sourceUpdateCmd)
 	sourceCmd.AddCommand(sourceRemoveCmd)
+	sourceCmd.AddCommand(sourceRenameCmd)
 	sourceCmd.AddCommand(sourceSetDefaultCmd)
 	sourceCmd.AddCommand(sourceShowCmd)
+	sourceCmd.AddCommand(sourceExportCmd)
 	rootCmd.AddCommand(sourceCmd)
 }
 
@@ -126,6 +200,16 @@ func loadSourceConfig() (*config.SourceConfig, error) {
 	return config.LoadSourceConfig(paths.SourcesFile)
 }
 
+// lockSourceConfig loads the sources config for an update, holding its
+// lock until the returned config is unlocked.
+func lockSourceConfig() (*config.SourceConfig, error) {
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return nil, err
+	}
+	return config.LockSourceConfig(paths.SourcesFile)
+}
+
 // buildSourceResolver creates a SourceResolver from the current sources config.
 // If sourceFilter is non-empty, the resolver is restricted to that single source.
 // Otherwise the resolver uses the default source only (for pull/create).
@@ -134,15 +218,26 @@ func buildSourceResolver(sourceFilter string) (*config.SourceResolver, error) {
 	if err != nil {
 		return nil, err
 	}
-	resolver := config.NewSourceResolver(sc.Sources)
+	resolver := sc.Resolver()
 	if sourceFilter != "" {
 		return resolver.ForSource(sourceFilter)
 	}
 	return resolver.DefaultOnly(), nil
 }
 
+// buildMirrorResolver creates a SourceResolver over all configured sources,
+// so that pulls find the mirrors of whichever source an artifact is from.
+func buildMirrorResolver() (*config.SourceResolver, error) {
+	sc, err := loadSourceConfig()
+	if err != nil {
+		return nil, err
+	}
+	return sc.Resolver(), nil
+}
+
 // buildListSourceResolver creates a SourceResolver for list commands.
-// --all returns all sources, --source filters to one, default shows only the default source.
+// --all returns all sources, --source filters to one, default shows only the
+// default source, or all sources when no default is set.
 func buildListSourceResolver(sourceFilter string, all bool) (*config.SourceResolver, error) {
 	if sourceFilter != "" && all {
 		return nil, fmt.Errorf("--source and --all are mutually exclusive")
@@ -151,11 +246,11 @@ func buildListSourceResolver(sourceFilter string, all bool) (*config.SourceResol
 	if err != nil {
 		return nil, err
 	}
-	resolver := config.NewSourceResolver(sc.Sources)
+	resolver := sc.Resolver()
 	if sourceFilter != "" {
 		return resolver.ForSource(sourceFilter)
 	}
-	if all {
+	if all || sc.NoDefault {
 		return resolver, nil
 	}
 	return resolver.DefaultOnly(), nil
@@ -197,10 +292,11 @@ func runSourceList(cmd *cobra.Command, _ []string) error {
 }
 
 func runSourceAdd(cmd *cobra.Command, args []string) error {
-	sc, err := loadSourceConfig()
+	sc, err := lockSourceConfig()
 	if err != nil {
 		return err
 	}
+	defer func() { _ = sc.Unlock() }()
 
 	s := config.Source{
 		Name:          args[0],
@@ -208,13 +304,24 @@ func runSourceAdd(cmd *cobra.Command, args []string) error {
 		Toolchains:    sourceAddToolchains,
 		Personalities: sourceAddPersonalities,
 		Plugins:       sourceAddPlugins,
+		Mirrors:       sourceAddMirrors,
 	}
 
 	if err := sc.Add(s); err != nil {
 		return err
 	}
 
+	var demoted *config.Source
 	if sourceAddDefault {
+		if prev := sc.Default(); prev != nil {
+			p := *prev
+			demoted = &p
+			if !sourceAddForce {
+				if err := confirmDefaultSwitch(cmd, p, s); err != nil {
+					return err
+				}
+			}
+		}
 		if err := sc.SetDefault(s.Name); err != nil {
 			return err
 		}
@@ -224,21 +331,49 @@ func runSourceAdd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Added source %q (%s)\n", s.Name, s.Registry)
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Added source %q (%s)\n", s.Name, s.Registry)
+	if demoted != nil {
+		_, _ = fmt.Fprintf(out, "Source %q is no longer the default; short names now resolve against %q\n", demoted.Name, s.Name)
+	}
+	return nil
+}
+
+// confirmDefaultSwitch asks the user to confirm that next replaces prev as
+// the default source. Demoting the built-in source additionally warns that
+// short names will resolve against a different registry.
+func confirmDefaultSwitch(cmd *cobra.Command, prev, next config.Source) error {
+	if prev.Name == config.DefaultSourceName {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s short names will resolve against %s instead of the built-in %s registry (%s)\n",
+			yellow("Warning:"), next.Registry, config.DefaultSourceName, prev.Registry)
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Source %q is currently the default and will be demoted. Continue? [y/N]: ", prev.Name)
+	reader := bufio.NewReader(cmd.InOrStdin())
+	answer, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("confirming default source change (use --force to skip): %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("source add cancelled")
+	}
 	return nil
 }
 
 func runSourceUpdate(cmd *cobra.Command, args []string) error {
-	sc, err := loadSourceConfig()
+	sc, err := lockSourceConfig()
 	if err != nil {
 		return err
 	}
+	defer func() { _ = sc.Unlock() }()
 
 	patch := config.Source{
 		Registry:      sourceUpdateRegistry,
 		Toolchains:    sourceUpdateToolchains,
 		Personalities: sourceUpdatePersonalities,
 		Plugins:       sourceUpdatePlugins,
+		Mirrors:       sourceUpdateMirrors,
 	}
 
 	if err := sc.Update(args[0], patch); err != nil {
@@ -254,10 +389,11 @@ func runSourceUpdate(cmd *cobra.Command, args []string) error {
 }
 
 func runSourceRemove(cmd *cobra.Command, args []string) error {
-	sc, err := loadSourceConfig()
+	sc, err := lockSourceConfig()
 	if err != nil {
 		return err
 	}
+	defer func() { _ = sc.Unlock() }()
 
 	if err := sc.Remove(args[0]); err != nil {
 		return err
@@ -271,13 +407,35 @@ func runSourceRemove(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runSourceRename(cmd *cobra.Command, args []string) error {
+	sc, err := lockSourceConfig()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sc.Unlock() }()
+
+	if err := sc.Rename(args[0], args[1]); err != nil {
+		return err
+	}
+
+	if err := sc.Save(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Renamed source %q to %q\n", args[0], args[1])
+	return nil
+}
+
 func runSourceSetDefault(cmd *cobra.Command, args []string) error {
-	sc, err := loadSourceConfig()
+	sc, err := lockSourceConfig()
 	if err != nil {
 		return err
 	}
+	defer func() { _ = sc.Unlock() }()
 
-	if err := sc.SetDefault(args[0]); err != nil {
+	if sourceSetDefaultUnset {
+		sc.ClearDefault()
+	} else if err := sc.SetDefault(args[0]); err != nil {
 		return err
 	}
 
@@ -285,6 +443,10 @@ func runSourceSetDefault(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if sourceSetDefaultUnset {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Default source cleared; short names must now be qualified")
+		return nil
+	}
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Default source set to %q\n", args[0])
 	return nil
 }
@@ -310,5 +472,39 @@ func runSourceShow(cmd *cobra.Command, args []string) error {
 	_, _ = fmt.Fprintf(w, "Toolchains:\t%s\n", s.ToolchainRegistry())
 	_, _ = fmt.Fprintf(w, "Personalities:\t%s\n", s.PersonalityRegistry())
 	_, _ = fmt.Fprintf(w, "Plugins:\t%s\n", s.PluginRegistry())
+	if len(s.Mirrors) > 0 {
+		_, _ = fmt.Fprintf(w, "Mirrors:\t%s\n", strings.Join(s.Mirrors, ", "))
+	}
 	return w.Flush()
 }
+
+func runSourceExport(cmd *cobra.Command, _ []string) error {
+	if sourceExportOut != "yaml" && sourceExportOut != "json" {
+		return fmt.Errorf("unsupported output format %q: must be one of [yaml json]", sourceExportOut)
+	}
+	sc, err := loadSourceConfig()
+	if err != nil {
+		return err
+	}
+	exported := sc.Export(sourceExportIncludeBuiltin)
+
+	var buf bytes.Buffer
+	if sourceExportOut == "json" {
+		err = writeJSON(&buf, exported)
+	} else {
+		err = exported.Encode(&buf)
+	}
+	if err != nil {
+		return err
+	}
+
+	if sourceExportFile == "" {
+		_, err = cmd.OutOrStdout().Write(buf.Bytes())
+		return err
+	}
+	if err := os.WriteFile(sourceExportFile, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("writing %s: %w", sourceExportFile, err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Exported %d source(s) to %s.\n", len(exported.Sources), sourceExportFile)
+	return nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+)
+
+var (
+	sourcePingType   string
+	sourcePingOutput string
+)
+
+var sourcePingCmd = &cobra.Command{
+	Use:   "ping <short-name>",
+	Short: "Show which sources a short name resolves to",
+	Long: `Expand a short name against every configured source and check whether
+the resulting reference exists in the registry.
+
+Short names are resolved against the default source, so this shows where
+'klausctl plugin pull gs-base' would pull from and which other sources also
+have the artifact:
+
+  klausctl source ping gs-base
+  klausctl source ping sre --type personality -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSourcePing,
+}
+
+// sourcePing is the resolution of a short name against one source.
+type sourcePing struct {
+	// Default marks the source short names resolve against.
+	Default bool `json:"default"`
+	// Ref is the short name expanded with the source's registry.
+	Ref string `json:"ref"`
+	// Resolved is Ref with the tag it resolves to, when it exists.
+	Resolved string `json:"resolved,omitempty"`
+	Exists   bool   `json:"exists"`
+	Error    string `json:"error,omitempty"`
+}
+
+// resolveArtifactRef resolves ref in the registry: the latest semver tag
+// when ref has no tag or digest, otherwise ref itself. It is a variable so
+// tests can run without a registry.
+var resolveArtifactRef = func(ctx context.Context, ref string) (string, error) {
+	client := orchestrator.NewDefaultClient()
+	if klausoci.RepositoryFromRef(ref) == ref {
+		return client.ResolveLatestVersion(ctx, ref)
+	}
+	if _, err := client.Resolve(ctx, ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+func init() {
+	sourcePingCmd.Flags().StringVar(&sourcePingType, "type", "plugin", "artifact type: plugin, personality, toolchain")
+	sourcePingCmd.Flags().StringVarP(&sourcePingOutput, "output", "o", "text", "output format: text, json")
+	sourceCmd.AddCommand(sourcePingCmd)
+}
+
+func runSourcePing(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(sourcePingOutput); err != nil {
+		return err
+	}
+	expand, err := sourceRefExpander(sourcePingType)
+	if err != nil {
+		return err
+	}
+	sc, err := loadSourceConfig()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	resolver := sc.Resolver()
+	sources := resolver.Sources()
+	pings := make(map[string]sourcePing, len(sources))
+	for _, s := range sources {
+		single, err := resolver.ForSource(s.Name)
+		if err != nil {
+			return err
+		}
+		ref, err := expand(single, args[0])
+		if err != nil {
+			return err
+		}
+		ping := sourcePing{Default: s.Default, Ref: ref}
+		resolved, err := resolveArtifactRef(ctx, ping.Ref)
+		if err != nil {
+			ping.Error = err.Error()
+		} else {
+			ping.Resolved = resolved
+			ping.Exists = true
+		}
+		pings[s.Name] = ping
+	}
+
+	out := cmd.OutOrStdout()
+	if sourcePingOutput == "json" {
+		return writeJSON(out, pings)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SOURCE\tREF\tEXISTS\tDEFAULT")
+	for _, s := range sources {
+		ping := pings[s.Name]
+		ref, exists, def := ping.Ref, "no", ""
+		if ping.Exists {
+			ref, exists = ping.Resolved, green("yes")
+		}
+		if ping.Default {
+			def = "*"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, ref, exists, def)
+	}
+	return w.Flush()
+}
+
+// sourceRefExpander returns the function expanding a short name of the
+// given artifact type with a source's registry.
+func sourceRefExpander(artifactType string) (func(*config.SourceResolver, string) (string, error), error) {
+	switch artifactType {
+	case "plugin":
+		return (*config.SourceResolver).ResolvePluginRef, nil
+	case "personality":
+		return (*config.SourceResolver).ResolvePersonalityRef, nil
+	case "toolchain":
+		return (*config.SourceResolver).ResolveToolchainRef, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q: must be one of [plugin personality toolchain]", artifactType)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSourcePingRegistered(t *testing.T) {
+	assertSubcommandsRegistered(t, sourceCmd, []string{"ping"})
+	assertFlagRegistered(t, sourcePingCmd, "type")
+	assertFlagRegistered(t, sourcePingCmd, "output")
+}
+
+func TestSourcePingResolvesAgainstEverySource(t *testing.T) {
+	setupSourceAddTest(t, true)
+	sourceAddDefault = false
+	if err := runSourceAdd(&cobra.Command{}, []string{"team"}); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := resolveArtifactRef
+	t.Cleanup(func() {
+		resolveArtifactRef = orig
+		sourcePingType = "plugin"
+		sourcePingOutput = "text"
+	})
+	resolveArtifactRef = func(_ context.Context, ref string) (string, error) {
+		if strings.HasPrefix(ref, "reg.example.com/team/") {
+			return "", fmt.Errorf("repository not found")
+		}
+		return ref + ":v1.0.0", nil
+	}
+
+	sourcePingType = "personality"
+	sourcePingOutput = "json"
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runSourcePing(cmd, []string{"sre"}); err != nil {
+		t.Fatalf("runSourcePing() error = %v", err)
+	}
+
+	var pings map[string]sourcePing
+	if err := json.Unmarshal(out.Bytes(), &pings); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	gs, team := pings["giantswarm"], pings["team"]
+	if !gs.Default || !gs.Exists || !strings.HasSuffix(gs.Resolved, "/sre:v1.0.0") {
+		t.Errorf("giantswarm = %+v, want the existing default", gs)
+	}
+	if team.Default || team.Exists || team.Ref != "reg.example.com/team/klaus-personalities/sre" || team.Error == "" {
+		t.Errorf("team = %+v, want a missing personality with an error", team)
+	}
+}
+
+func TestSourcePingRejectsUnknownType(t *testing.T) {
+	t.Cleanup(func() { sourcePingType = "plugin" })
+	sourcePingType = "image"
+	if err := runSourcePing(&cobra.Command{}, []string{"x"}); err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Errorf("runSourcePing() error = %v, want unsupported type", err)
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestSourceRenameRegistered(t *testing.T) {
+	assertSubcommandsRegistered(t, sourceCmd, []string{"rename"})
+}
+
+func TestSourceAddFlags(t *testing.T) {
+	assertFlagRegistered(t, sourceAddCmd, "default")
+	assertFlagRegistered(t, sourceAddCmd, "force")
+	assertFlagRegistered(t, sourceAddCmd, "mirror")
+	assertFlagRegistered(t, sourceUpdateCmd, "mirror")
+	assertFlagRegistered(t, sourceExportCmd, "include-builtin")
+	assertFlagRegistered(t, sourceSetDefaultCmd, "unset")
+}
+
+func TestSourceSetDefaultUnset(t *testing.T) {
+	setupSourceAddTest(t, true)
+	sourceSetDefaultUnset = true
+	t.Cleanup(func() { sourceSetDefaultUnset = false })
+
+	if err := sourceSetDefaultCmd.Args(sourceSetDefaultCmd, []string{"team"}); err == nil {
+		t.Error("--unset with a source name should be rejected")
+	}
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runSourceSetDefault(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "cleared") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	resolver, err := buildSourceResolver("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolver.ResolveToolchainRef("go"); err == nil {
+		t.Error("short name resolved without a default source")
+	}
+	resolver, err = buildSourceResolver(config.DefaultSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := resolver.ResolveToolchainRef("go"); err != nil {
+		t.Errorf("short name with --source error = %v", err)
+	}
+}
+
+// setupSourceAddTest points the config at a temp dir and resets the source
+// add flags to a --default add of "team".
+func setupSourceAddTest(t *testing.T, force bool) {
+	t.Helper()
+	configHome := filepath.Join(t.TempDir(), "config-home")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	if err := os.MkdirAll(filepath.Join(configHome, "klausctl"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	sourceAddRegistry = "reg.example.com/team"
+	sourceAddToolchains = ""
+	sourceAddPersonalities = ""
+	sourceAddPlugins = ""
+	sourceAddDefault = true
+	sourceAddForce = force
+	t.Cleanup(func() {
+		sourceAddDefault = false
+		sourceAddForce = false
+	})
+}
+
+func TestSourceAddDefaultReportsDemotedSource(t *testing.T) {
+	setupSourceAddTest(t, false)
+
+	var out, errOut bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetIn(strings.NewReader("y\n"))
+
+	if err := runSourceAdd(cmd, []string{"team"}); err != nil {
+		t.Fatalf("runSourceAdd() error = %v", err)
+	}
+
+	if !strings.Contains(out.String(), `Source "giantswarm" is currently the default and will be demoted`) {
+		t.Errorf("expected confirmation prompt, got: %s", out.String())
+	}
+	if !strings.Contains(out.String(), `Source "giantswarm" is no longer the default`) {
+		t.Errorf("expected demoted source to be reported, got: %s", out.String())
+	}
+	if !strings.Contains(errOut.String(), "short names will resolve against reg.example.com/team") {
+		t.Errorf("expected warning about built-in source, got: %s", errOut.String())
+	}
+
+	sc, err := loadSourceConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := sc.Default(); d == nil || d.Name != "team" {
+		t.Errorf("default source = %v, want team", d)
+	}
+}
+
+func TestSourceAddDefaultDeclined(t *testing.T) {
+	setupSourceAddTest(t, false)
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	cmd.SetIn(strings.NewReader("n\n"))
+
+	err := runSourceAdd(cmd, []string{"team"})
+	if err == nil || !strings.Contains(err.Error(), "cancelled") {
+		t.Fatalf("runSourceAdd() error = %v, want cancelled", err)
+	}
+
+	sc, err := loadSourceConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Get("team") != nil {
+		t.Error("expected source not to be saved after declining")
+	}
+	if d := sc.Default(); d == nil || d.Name != config.DefaultSourceName {
+		t.Errorf("default source = %v, want %s", d, config.DefaultSourceName)
+	}
+}
+
+func TestSourceAddDefaultForceSkipsConfirmation(t *testing.T) {
+	setupSourceAddTest(t, true)
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	cmd.SetErr(io.Discard)
+	cmd.SetIn(strings.NewReader(""))
+
+	if err := runSourceAdd(cmd, []string{"team"}); err != nil {
+		t.Fatalf("runSourceAdd() error = %v", err)
+	}
+	if strings.Contains(out.String(), "Continue?") {
+		t.Errorf("expected no prompt with --force, got: %s", out.String())
+	}
+	if !strings.Contains(out.String(), `Source "giantswarm" is no longer the default`) {
+		t.Errorf("expected demoted source to be reported, got: %s", out.String())
+	}
+}
+
+func TestSourceExport(t *testing.T) {
+	setupSourceAddTest(t, true)
+	sourceAddDefault = false
+	if err := runSourceAdd(&cobra.Command{}, []string{"team"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sourceExportOut = "yaml"
+		sourceExportFile = ""
+		sourceExportIncludeBuiltin = false
+	})
+
+	sourceExportOut = "yaml"
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&out)
+	if err := runSourceExport(cmd, nil); err != nil {
+		t.Fatalf("runSourceExport() error = %v", err)
+	}
+	if got := out.String(); !strings.Contains(got, "name: team") || strings.Contains(got, config.DefaultSourceName) {
+		t.Errorf("yaml export = %q, want team without the builtin", got)
+	}
+
+	sourceExportOut = "json"
+	sourceExportIncludeBuiltin = true
+	sourceExportFile = filepath.Join(t.TempDir(), "sources.json")
+	if err := runSourceExport(cmd, nil); err != nil {
+		t.Fatalf("runSourceExport() error = %v", err)
+	}
+	data, err := os.ReadFile(sourceExportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, `"name": "team"`) || !strings.Contains(got, `"name": "giantswarm"`) {
+		t.Errorf("json export = %q, want team and the builtin", got)
+	}
+}
+
+func TestBuildSourceResolverForSource(t *testing.T) {
+	setupSourceAddTest(t, true)
+	sourceAddDefault = false
+	if err := runSourceAdd(&cobra.Command{}, []string{"team"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver, err := buildSourceResolver("team")
+	if err != nil {
+		t.Fatalf("buildSourceResolver() error = %v", err)
+	}
+	got, err := resolver.ResolvePersonalityRef("sre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "reg.example.com/team/klaus-personalities/sre"; got != want {
+		t.Errorf("ResolvePersonalityRef() = %q, want %q", got, want)
+	}
+
+	if _, err := buildSourceResolver("nonexistent"); err == nil || !strings.Contains(err.Error(), "nonexistent") {
+		t.Errorf("buildSourceResolver(nonexistent) error = %v, want unknown source", err)
+	}
+}
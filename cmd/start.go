@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"time"
@@ -17,7 +18,15 @@ import (
 	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
-var startWorkspace string
+var (
+	startWorkspace      string
+	startNoParallelPull bool
+	startPull           string
+	startPlatform       string
+	startImage          string
+	startAutoPort       bool
+	startNoWarnFloating bool
+)
 
 var startCmd = &cobra.Command{
 	Use:   "start [name]",
@@ -28,15 +37,46 @@ This command:
   1. Loads configuration from ~/.config/klausctl/instances/<name>/config.yaml
   2. Resolves personality (if configured): pulls the OCI artifact, merges
      plugins, applies image override, and prepares SOUL.md
-  3. Pulls OCI plugins (personality + instance-level)
-  4. Renders configuration files (skills, settings, MCP config)
-  5. Starts a container with the correct env vars, mounts, and ports`,
+  3. Renders configuration files (skills, settings, MCP config)
+  4. Pulls OCI plugins (personality + instance-level) and the container
+     image concurrently
+  5. Starts a container with the correct env vars, mounts, and ports
+
+Use --no-parallel-pull (or preStartPull.sequential in the config) to pull
+plugins and the image one after the other, e.g. for rate-limited registries.
+
+The image is pulled on every start by default. Use --pull (or pullPolicy in
+the config) set to ifNotPresent to only pull missing images, or never to use
+the local image without contacting the registry, e.g. when offline.
+
+Use --platform (or platform in the config), e.g. linux/amd64, to pull and
+run the image of another architecture under emulation.
+
+Use --image to run another image for a quick experiment without editing the
+config. It takes precedence over the config and the personality's toolchain;
+like image in the config, a name without "/" is a toolchain short name.
+
+Before the container starts, the host port is checked by briefly binding it,
+so a port taken by another process fails with a clear error instead of a
+runtime "port already allocated" error. Use --auto-port to start on the next
+free port instead.
+
+An image, personality or plugin referenced without a version tag or by the
+latest tag resolves to whatever version is newest at start time. Such
+floating references are reported as warnings; --no-warn-floating silences
+them.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runStart,
 }
 
 func init() {
 	startCmd.Flags().StringVar(&startWorkspace, "workspace", "", "workspace directory to mount (overrides config file)")
+	startCmd.Flags().StringVar(&startPull, "pull", "", "image pull policy: always, ifNotPresent, never (overrides pullPolicy in the config)")
+	startCmd.Flags().StringVar(&startImage, "image", "", "container image to run (overrides the config and the personality's toolchain)")
+	startCmd.Flags().StringVar(&startPlatform, "platform", "", "image platform as os/arch, e.g. linux/amd64 (overrides platform in the config)")
+	startCmd.Flags().BoolVar(&startNoParallelPull, "no-parallel-pull", false, "pull plugins and the image sequentially instead of concurrently")
+	startCmd.Flags().BoolVar(&startAutoPort, "auto-port", false, "start on the next free port when the configured port is in use")
+	startCmd.Flags().BoolVar(&startNoWarnFloating, "no-warn-floating", false, "do not warn about references by a floating tag such as latest")
 	rootCmd.AddCommand(startCmd)
 }
 
@@ -54,7 +94,7 @@ func runStart(cmd *cobra.Command, args []string) error {
 }
 
 // newRuntime creates a container runtime. Tests override this to inject a fake.
-var newRuntime = runtime.New
+var newRuntime = runtime.NewWithHost
 
 func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPathOverride string) (retErr error) {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -62,6 +102,7 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 
 	out := cmd.OutOrStdout()
 	errOut := cmd.ErrOrStderr()
+	progress := progressWriter(out)
 
 	paths, err := config.DefaultPaths()
 	if err != nil {
@@ -83,6 +124,39 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 	}
 
 	applyWorkspaceOverride(cfg, workspaceOverride)
+	if startImage != "" {
+		cfg.SetImage(startImage)
+	}
+	if startPull != "" {
+		if err := config.ValidatePullPolicy(startPull); err != nil {
+			return err
+		}
+		cfg.PullPolicy = startPull
+	}
+	if startPlatform != "" {
+		if err := config.ValidatePlatform(startPlatform); err != nil {
+			return err
+		}
+		cfg.Platform = startPlatform
+	}
+
+	// A hand-edited config may name a toolchain by its short name.
+	resolver, err := buildSourceResolver("")
+	if err != nil {
+		return err
+	}
+	if err := cfg.ResolveImageShortName(resolver); err != nil {
+		return err
+	}
+	if !startNoWarnFloating {
+		for _, w := range orchestrator.FloatingRefWarnings(cfg) {
+			_, _ = fmt.Fprintf(errOut, "%s %s\n", yellow("Warning:"), w)
+		}
+	}
+	mirrors, err := buildMirrorResolver()
+	if err != nil {
+		return err
+	}
 
 	workspace := config.ResolveWorkspacePath(cfg.Workspace, paths.ReposDir)
 	if _, err := os.Stat(workspace); err != nil {
@@ -101,14 +175,14 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 	}
 
 	// Detect or validate container runtime.
-	rt, err := newRuntime(cfg.Runtime)
+	rt, err := newRuntime(cfg.Runtime, cfg.RuntimeHost)
 	if err != nil {
 		return err
 	}
 	if cfg.Runtime == "" {
-		_, _ = fmt.Fprintf(out, "Auto-detected %s runtime (set 'runtime' in config to override).\n", bold(rt.Name()))
+		_, _ = fmt.Fprintf(progress, "Auto-detected %s runtime (set 'runtime' in config to override).\n", bold(rt.Name()))
 	} else {
-		_, _ = fmt.Fprintf(out, "Using %s runtime.\n", rt.Name())
+		_, _ = fmt.Fprintf(progress, "Using %s runtime.\n", rt.Name())
 	}
 
 	// Derive the instance name and container name consistently.
@@ -120,23 +194,30 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 		status, sErr := rt.Status(ctx, inst.ContainerName())
 		if sErr == nil && status == "running" { //nolint:goconst
 			return fmt.Errorf(
-				"instance %q is already running (container: %s, MCP: http://localhost:%d)\nUse 'klausctl stop %s' to stop it first",
-				inst.Name, inst.ContainerName(), inst.Port,
+				"instance %q is already running (container: %s, MCP: %s)\nUse 'klausctl stop %s' to stop it first",
+				inst.Name, inst.ContainerName(), inst.AgentURL(),
 				inst.Name,
 			)
 		}
-		// Clean up stale container.
-		_ = rt.Remove(ctx, inst.ContainerName())
+		if sErr == nil && status == "paused" {
+			return fmt.Errorf("instance %q is paused\nUse 'klausctl resume %s' to resume it", inst.Name, inst.Name)
+		}
+		// Clear stale state. A stopped container is reused or replaced
+		// when the container is started below.
 		_ = instance.Clear(paths)
 	}
 
+	if err := ensureHostPort(cfg, paths, startAutoPort, progress); err != nil {
+		return err
+	}
+
 	// Resolve personality if configured. This pulls the personality artifact,
 	// merges its plugins with the user's, and optionally overrides the image.
 	client := orchestrator.NewDefaultClient()
 
 	var personalityDir string
 	if cfg.Personality != "" {
-		_, _ = fmt.Fprintln(out, "Resolving personality...")
+		_, _ = fmt.Fprintln(progress, "Resolving personality...")
 
 		resolvedRef, err := client.ResolvePersonalityRef(ctx, cfg.Personality)
 		if err != nil {
@@ -148,14 +229,20 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 			return fmt.Errorf("creating personalities directory: %w", err)
 		}
 
-		pr, err := orchestrator.ResolvePersonality(ctx, client, cfg.Personality, paths.PersonalitiesDir, out)
+		pr, err := orchestrator.ResolvePersonality(ctx, client, mirrors, cfg.Personality, paths.PersonalitiesDir, progress)
 		if err != nil {
 			return fmt.Errorf("resolving personality: %w", err)
 		}
 		personalityDir = pr.Dir
 
-		// Merge personality plugins with user plugins (user wins on conflict).
+		// Merge personality plugins and env with the user's (user wins on conflict).
 		cfg.Plugins = orchestrator.MergePlugins(pr.Spec.Plugins, cfg.Plugins)
+		if err := orchestrator.MergePersonalityEnv(pr, cfg); err != nil {
+			return err
+		}
+		for _, w := range pr.Warnings {
+			_, _ = fmt.Fprintf(errOut, "%s %s\n", yellow("Warning:"), w)
+		}
 
 		// Use personality toolchain if the user didn't explicitly set one.
 		if !cfg.ImageExplicitlySet() && pr.Spec.Toolchain.Repository != "" {
@@ -175,7 +262,7 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 	// so the registered klaus-gateway entry in mcpservers.yaml can flow
 	// through the usual mcpServerRefs -> mcpServers path.
 	if cfg.Requires.Gateway.Enabled {
-		_, _ = fmt.Fprintln(out, "Ensuring klaus-gateway bridge is running...")
+		_, _ = fmt.Fprintln(progress, "Ensuring klaus-gateway bridge is running...")
 		if _, err := gatewaybridge.EnsureRunning(ctx, paths, gatewaybridge.Options{
 			WithAgentGateway: cfg.Requires.Gateway.WithAgentGateway,
 		}); err != nil {
@@ -191,41 +278,54 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 		return err
 	}
 
+	if err := orchestrator.ExpandSystemPrompts(cfg, instanceName); err != nil {
+		return err
+	}
+
 	// Render configuration files.
 	r := renderer.New(paths)
 	if err := r.Render(cfg); err != nil {
 		return fmt.Errorf("rendering config: %w", err)
 	}
 
-	// Pull OCI plugins.
-	if len(cfg.Plugins) > 0 {
-		_, _ = fmt.Fprintln(out, "Pulling plugins...")
-		if err := orchestrator.PullPlugins(ctx, client, cfg.Plugins, paths.PluginsDir, out); err != nil {
-			return fmt.Errorf("pulling plugins: %w", err)
-		}
-	}
-
 	// Build container run options.
 	runOpts, err := orchestrator.BuildRunOptions(cfg, paths, containerName, image, personalityDir)
 	if err != nil {
 		return fmt.Errorf("building run options: %w", err)
 	}
+	runOpts.Labels = runtime.ManagedLabels(instanceName)
+	if w := cfg.NetworkWarning(); w != "" {
+		_, _ = fmt.Fprintf(out, "%s %s\n", yellow("Warning:"), w)
+	}
+	if w := orchestrator.SSHAgentWarning(cfg); w != "" {
+		_, _ = fmt.Fprintf(out, "%s %s\n", yellow("Warning:"), w)
+	}
 
-	// Pull the image with streamed progress. If the pull fails but the
-	// image is already cached locally (e.g. expired registry credentials),
-	// continue with the cached copy.
-	_, _ = fmt.Fprintf(out, "Pulling %s...\n", image)
-	if err := rt.Pull(ctx, image, out); err != nil {
-		images, imgErr := rt.Images(ctx, image)
-		if imgErr != nil || len(images) == 0 {
-			return fmt.Errorf("pulling image: %w", err)
+	// Pull OCI plugins and the image. Both pulls are independent and run
+	// concurrently unless disabled; progress shares one writer.
+	pullOut := orchestrator.SyncWriter(progress)
+	var pullPlugins orchestrator.PullFunc
+	if len(cfg.Plugins) > 0 {
+		pullPlugins = func(ctx context.Context) error {
+			_, _ = fmt.Fprintln(pullOut, "Pulling plugins...")
+			if err := orchestrator.PullPlugins(ctx, client, mirrors, cfg.Plugins, paths.PluginsDir, pullOut); err != nil {
+				return fmt.Errorf("pulling plugins: %w", err)
+			}
+			return nil
 		}
-		_, _ = fmt.Fprintln(out, "Pull failed, using locally cached image.")
+	}
+	pullImage := func(ctx context.Context) error {
+		return orchestrator.PullImage(ctx, rt, image, cfg.PullPolicy, cfg.Platform, pullOut)
+	}
+	parallel := !startNoParallelPull && !cfg.PreStartPull.Sequential
+	if err := orchestrator.PreStartPull(ctx, parallel, pullPlugins, pullImage); err != nil {
+		return err
 	}
 
-	// Start container.
-	_, _ = fmt.Fprintln(out, "Starting klaus container...")
-	containerID, err := rt.Run(ctx, runOpts)
+	// Start container, reusing one kept by 'klausctl stop --keep' when it
+	// still matches the config.
+	_, _ = fmt.Fprintln(progress, "Starting klaus container...")
+	containerID, reused, err := orchestrator.RunOrReuse(ctx, rt, runOpts)
 	if err != nil {
 		// The container may exist in "created" state even though Run returned
 		// an error (e.g. port conflict detected after container creation).
@@ -234,6 +334,9 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 		_ = rt.Remove(context.Background(), containerName)
 		return fmt.Errorf("starting container: %w", err)
 	}
+	if reused {
+		_, _ = fmt.Fprintf(progress, "Reused kept container %s.\n", containerName)
+	}
 
 	// Roll back the container if any subsequent step fails. Use a fresh
 	// context so cleanup succeeds even if the user pressed Ctrl+C.
@@ -250,15 +353,17 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 		effectiveWorkspace = cfg.WorktreePath
 	}
 	inst = &instance.Instance{
-		UUID:        instance.NewUUID(),
-		Name:        instanceName,
-		ContainerID: containerID,
-		Runtime:     rt.Name(),
-		Personality: cfg.Personality,
-		Image:       image,
-		Port:        cfg.Port,
-		Workspace:   effectiveWorkspace,
-		StartedAt:   time.Now(),
+		UUID:          instance.NewUUID(),
+		Name:          instanceName,
+		ContainerID:   containerID,
+		Runtime:       rt.Name(),
+		RuntimeHost:   runtime.ResolveHost(rt.Name(), cfg.RuntimeHost),
+		Personality:   cfg.Personality,
+		Image:         image,
+		Port:          cfg.Port,
+		Workspace:     effectiveWorkspace,
+		StartedAt:     time.Now(),
+		RestartPolicy: cfg.RestartPolicy,
 	}
 	if err := inst.Save(paths); err != nil {
 		return fmt.Errorf("saving instance state: %w", err)
@@ -273,11 +378,11 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 	_, _ = fmt.Fprintf(out, "  Container:   %s\n", containerName)
 	_, _ = fmt.Fprintf(out, "  Image:       %s\n", image)
 	_, _ = fmt.Fprintf(out, "  Workspace:   %s\n", inst.Workspace)
-	_, _ = fmt.Fprintf(out, "  MCP:         http://localhost:%d\n", cfg.Port)
+	_, _ = fmt.Fprintf(out, "  MCP:         %s\n", inst.AgentURL())
 
 	// Warn about missing API key after the success context so it doesn't
 	// appear before the user knows what's happening.
-	if os.Getenv("ANTHROPIC_API_KEY") == "" {
+	if cfg.ForwardsAnthropicKey() && os.Getenv("ANTHROPIC_API_KEY") == "" {
 		_, _ = fmt.Fprintf(errOut, "\n%s ANTHROPIC_API_KEY is not set; the claude agent may fail to authenticate.\n", yellow("Warning:"))
 	}
 
@@ -285,6 +390,25 @@ func startInstance(cmd *cobra.Command, instanceName, workspaceOverride, configPa
 	return nil
 }
 
+// ensureHostPort checks that the host port of cfg is free by briefly binding
+// it. A busy port is an error, or with autoPort is replaced by the next free
+// port. Ports on a remote runtime host cannot be probed and are not checked.
+func ensureHostPort(cfg *config.Config, paths *config.Paths, autoPort bool, out io.Writer) error {
+	if runtime.RemoteHostName(cfg.RuntimeHost) != "" || config.IsPortAvailable(cfg.Port) {
+		return nil
+	}
+	if !autoPort {
+		return fmt.Errorf("port %d is already in use on the host; free it, change port in the config, or use --auto-port to start on a free port", cfg.Port)
+	}
+	port, err := config.NextAvailablePort(paths, cfg.Port+1)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Port %d is in use; using port %d.\n", cfg.Port, port)
+	cfg.Port = port
+	return nil
+}
+
 func applyWorkspaceOverride(cfg *config.Config, workspaceOverride string) {
 	if workspaceOverride != "" {
 		cfg.Workspace = workspaceOverride
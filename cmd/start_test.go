@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"bytes"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
 )
 
 func TestStartSubcommandRegistered(t *testing.T) {
@@ -23,3 +30,79 @@ func TestStartWorkspaceFlag(t *testing.T) {
 		t.Fatal("expected --workspace flag to be registered")
 	}
 }
+
+func TestStartNoParallelPullFlag(t *testing.T) {
+	f := startCmd.Flags().Lookup("no-parallel-pull")
+	if f == nil {
+		t.Fatal("expected --no-parallel-pull flag to be registered")
+	}
+	if f.DefValue != "false" {
+		t.Errorf("expected --no-parallel-pull to default to false, got %q", f.DefValue)
+	}
+}
+
+func TestStartPullFlag(t *testing.T) {
+	f := startCmd.Flags().Lookup("pull")
+	if f == nil {
+		t.Fatal("expected --pull flag to be registered")
+	}
+	if f.DefValue != "" {
+		t.Errorf("expected --pull to default to the config's policy, got %q", f.DefValue)
+	}
+}
+
+func TestQuietFlag(t *testing.T) {
+	f := rootCmd.PersistentFlags().Lookup("quiet")
+	if f == nil {
+		t.Fatal("expected global --quiet flag to be registered")
+	}
+	if f.Shorthand != "q" {
+		t.Errorf("expected --quiet shorthand -q, got %q", f.Shorthand)
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	var buf bytes.Buffer
+	if w := progressWriter(&buf); w != &buf {
+		t.Error("expected progress to go to the given writer by default")
+	}
+
+	quietFlag = true
+	t.Cleanup(func() { quietFlag = false })
+	if w := progressWriter(&buf); w != io.Discard {
+		t.Error("expected progress to be discarded with --quiet")
+	}
+}
+
+func TestEnsureHostPort(t *testing.T) {
+	ln, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	busy := ln.Addr().(*net.TCPAddr).Port
+
+	paths := &config.Paths{InstancesDir: filepath.Join(t.TempDir(), "instances")}
+
+	cfg := &config.Config{Port: busy}
+	err = ensureHostPort(cfg, paths, false, io.Discard)
+	if err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Fatalf("error = %v, want port in use", err)
+	}
+
+	var out bytes.Buffer
+	if err := ensureHostPort(cfg, paths, true, &out); err != nil {
+		t.Fatalf("ensureHostPort() with auto-port error = %v", err)
+	}
+	if cfg.Port == busy {
+		t.Errorf("expected a port other than the busy port %d", busy)
+	}
+	if !strings.Contains(out.String(), "is in use; using port") {
+		t.Errorf("output = %q, want the port switch", out.String())
+	}
+
+	remote := &config.Config{Port: busy, RuntimeHost: "ssh://build.example.com"}
+	if err := ensureHostPort(remote, paths, false, io.Discard); err != nil {
+		t.Errorf("remote runtime host: error = %v, want no local check", err)
+	}
+}
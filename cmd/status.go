@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -14,10 +17,12 @@ import (
 	"github.com/giantswarm/klausctl/pkg/agentclient"
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
-var statusOutput string
+var (
+	statusOutput string
+	statusAll    bool
+)
 
 var statusCmd = &cobra.Command{
 	Use:   "status [name]",
@@ -26,13 +31,20 @@ var statusCmd = &cobra.Command{
 
 Returns exit code 1 when no instance is running, making it usable in scripts:
 
-  if klausctl status >/dev/null 2>&1; then echo "running"; fi`,
+  if klausctl status >/dev/null 2>&1; then echo "running"; fi
+
+--all shows the status, agent status and uptime of every instance instead,
+probing them in parallel. It always exits 0; instances that cannot be
+probed are listed with the error.
+
+  klausctl status --all -o json`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runStatus,
 }
 
 func init() {
 	statusCmd.Flags().StringVarP(&statusOutput, "output", "o", "text", "output format: text, json")
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "show the status of all instances")
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -50,8 +62,15 @@ type statusInfo struct {
 	Agent        string `json:"agent,omitempty"`
 	Session      string `json:"session,omitempty"`
 	MessageCount int    `json:"message_count,omitempty"`
+	// RestartPolicy is set when the container restarts automatically.
+	RestartPolicy string `json:"restart_policy,omitempty"`
+	// Error is set by --all when the instance could not be probed.
+	Error string `json:"error,omitempty"`
 }
 
+// statusConcurrency bounds the instances probed at once by --all.
+const statusConcurrency = 8
+
 // agentStatusHTTPClient is the HTTP client used for agent status queries.
 // Overridden in tests.
 var agentStatusHTTPClient *http.Client
@@ -70,6 +89,13 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("migrating config layout: %w", err)
 	}
 
+	if statusAll {
+		if len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with an instance name")
+		}
+		return runStatusAll(ctx, out, paths)
+	}
+
 	instanceName, err := resolveOptionalInstanceName(args, "status", cmd.ErrOrStderr())
 	if err != nil {
 		return err
@@ -81,17 +107,74 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no klaus instance found for %q; run 'klausctl start %s' to start one", instanceName, instanceName)
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	info, err := collectStatus(ctx, inst, cmd.ErrOrStderr())
 	if err != nil {
 		return err
 	}
+	status, containerName := info.Status, info.Container
+
+	if statusOutput == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	// Text output.
+	var statusColor string
+	if status == "running" {
+		statusColor = green(status)
+	} else {
+		statusColor = yellow(status)
+	}
+
+	_, _ = fmt.Fprintf(out, "Instance:    %s\n", inst.Name)
+	_, _ = fmt.Fprintf(out, "Status:      %s\n", statusColor)
+	if inst.Personality != "" {
+		_, _ = fmt.Fprintf(out, "Personality: %s\n", inst.Personality)
+	}
+	_, _ = fmt.Fprintf(out, "Container:   %s\n", containerName)
+	_, _ = fmt.Fprintf(out, "Runtime:     %s\n", inst.Runtime)
+	_, _ = fmt.Fprintf(out, "Image:       %s\n", inst.Image)
+	_, _ = fmt.Fprintf(out, "Workspace:   %s\n", inst.Workspace)
+	if info.RestartPolicy != "" {
+		_, _ = fmt.Fprintf(out, "Restart:     %s\n", info.RestartPolicy)
+	}
+
+	if status == "running" {
+		_, _ = fmt.Fprintf(out, "MCP:         %s\n", info.MCP)
+		if info.Uptime != "" {
+			_, _ = fmt.Fprintf(out, "Uptime:      %s\n", info.Uptime)
+		}
+		if info.Agent != "" {
+			agentLine := info.Agent
+			if info.MessageCount > 0 {
+				agentLine = fmt.Sprintf("%s (%d messages)", info.Agent, info.MessageCount)
+			}
+			_, _ = fmt.Fprintf(out, "Agent:       %s\n", agentLine)
+		}
+		if info.Session != "" {
+			_, _ = fmt.Fprintf(out, "Session:     %s\n", info.Session)
+		}
+	}
+
+	return nil
+}
+
+// collectStatus queries the runtime and the agent for the status of a
+// started instance. Problems that leave the status usable, such as an
+// agent that does not answer, are written to warn.
+func collectStatus(ctx context.Context, inst *instance.Instance, warn io.Writer) (statusInfo, error) {
+	rt, err := inst.NewRuntime()
+	if err != nil {
+		return statusInfo{}, err
+	}
 
 	containerName := inst.ContainerName()
 
 	// Get container status.
 	status, err := rt.Status(ctx, containerName)
 	if err != nil || status == "" {
-		return fmt.Errorf("instance %q has stale state (container no longer exists); run 'klausctl start %s' to start a new one", inst.Name, inst.Name)
+		return statusInfo{}, fmt.Errorf("instance %q has stale state (container no longer exists); run 'klausctl start %s' to start a new one", inst.Name, inst.Name)
 	}
 
 	info := statusInfo{
@@ -103,14 +186,17 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		Image:       inst.Image,
 		Workspace:   inst.Workspace,
 	}
+	if inst.RestartPolicy != "no" {
+		info.RestartPolicy = inst.RestartPolicy
+	}
 
 	if status == "running" {
-		info.MCP = fmt.Sprintf("http://localhost:%d", inst.Port)
+		info.MCP = inst.AgentURL()
 
 		// Try to get uptime from the runtime, fall back to saved state.
 		cInfo, inspectErr := rt.Inspect(ctx, containerName)
 		if inspectErr != nil {
-			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s could not inspect container: %v\n", yellow("Warning:"), inspectErr)
+			_, _ = fmt.Fprintf(warn, "%s could not inspect container: %v\n", yellow("Warning:"), inspectErr)
 		}
 
 		switch {
@@ -131,7 +217,7 @@ func runStatus(cmd *cobra.Command, args []string) error {
 
 		agentResp, agentErr := agentclient.FetchStatus(agentCtx, httpClient, info.MCP)
 		if agentErr != nil {
-			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s could not query agent status: %v\n", yellow("Warning:"), agentErr)
+			_, _ = fmt.Fprintf(warn, "%s could not query agent status: %v\n", yellow("Warning:"), agentErr)
 		} else {
 			info.Agent = agentResp.Agent.Status
 			info.MessageCount = agentResp.Agent.MessageCount
@@ -139,48 +225,112 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	return info, nil
+}
+
+// runStatusAll prints the status of every instance with a config. Started
+// instances are probed in parallel; instances without state are stopped.
+func runStatusAll(ctx context.Context, out io.Writer, paths *config.Paths) error {
+	if err := validateOutputFormat(statusOutput); err != nil {
+		return err
+	}
+	infos, err := collectAllStatuses(ctx, paths)
+	if err != nil {
+		return err
+	}
+
 	if statusOutput == "json" {
-		enc := json.NewEncoder(out)
-		enc.SetIndent("", "  ")
-		return enc.Encode(info)
+		return writeJSON(out, infos)
+	}
+	if len(infos) == 0 {
+		_, _ = fmt.Fprintln(out, "No instances found. Use 'klausctl create <name>' to create one.")
+		return nil
 	}
+	return writeStatusTable(out, infos)
+}
 
-	// Text output.
-	var statusColor string
-	if status == "running" {
-		statusColor = green(status)
-	} else {
-		statusColor = yellow(status)
+// collectAllStatuses returns the status of every instance with a config,
+// sorted by name.
+func collectAllStatuses(ctx context.Context, paths *config.Paths) ([]statusInfo, error) {
+	dirEntries, err := os.ReadDir(paths.InstancesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []statusInfo{}, nil
+		}
+		return nil, fmt.Errorf("reading instances directory: %w", err)
 	}
 
-	_, _ = fmt.Fprintf(out, "Instance:    %s\n", inst.Name)
-	_, _ = fmt.Fprintf(out, "Status:      %s\n", statusColor)
-	if inst.Personality != "" {
-		_, _ = fmt.Fprintf(out, "Personality: %s\n", inst.Personality)
+	stateByName := map[string]*instance.Instance{}
+	states, err := instance.LoadAll(paths)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range states {
+		stateByName[st.Name] = st
 	}
-	_, _ = fmt.Fprintf(out, "Container:   %s\n", containerName)
-	_, _ = fmt.Fprintf(out, "Runtime:     %s\n", inst.Runtime)
-	_, _ = fmt.Fprintf(out, "Image:       %s\n", inst.Image)
-	_, _ = fmt.Fprintf(out, "Workspace:   %s\n", inst.Workspace)
 
-	if status == "running" {
-		_, _ = fmt.Fprintf(out, "MCP:         %s\n", info.MCP)
-		if info.Uptime != "" {
-			_, _ = fmt.Fprintf(out, "Uptime:      %s\n", info.Uptime)
+	infos := make([]statusInfo, 0, len(dirEntries))
+	var started []*instance.Instance
+	index := map[string]int{}
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			continue
 		}
-		if info.Agent != "" {
-			agentLine := info.Agent
-			if info.MessageCount > 0 {
-				agentLine = fmt.Sprintf("%s (%d messages)", info.Agent, info.MessageCount)
-			}
-			_, _ = fmt.Fprintf(out, "Agent:       %s\n", agentLine)
+		name := entry.Name()
+		cfg, err := config.Load(paths.ForInstance(name).ConfigFile)
+		if err != nil {
+			// Skip malformed/incomplete directories.
+			continue
 		}
-		if info.Session != "" {
-			_, _ = fmt.Fprintf(out, "Session:     %s\n", info.Session)
+		if st, ok := stateByName[name]; ok {
+			started = append(started, st)
+			index[name] = len(infos)
 		}
+		infos = append(infos, statusInfo{
+			Instance:  name,
+			Status:    "stopped",
+			Container: instance.ContainerName(name),
+			Runtime:   cfg.Runtime,
+			Image:     cfg.Image,
+			Workspace: cfg.Workspace,
+		})
 	}
 
-	return nil
+	// Each probe writes its own slot of infos, so no locking is needed.
+	// Warnings are dropped: they would interleave, and a failed agent
+	// query shows as an empty AGENT column.
+	_ = instance.ForEach(ctx, started, statusConcurrency, func(ctx context.Context, inst *instance.Instance) error {
+		i := index[inst.Name]
+		info, err := collectStatus(ctx, inst, io.Discard)
+		if err != nil {
+			infos[i].Error = err.Error()
+			return nil
+		}
+		infos[i] = info
+		return nil
+	})
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Instance < infos[j].Instance
+	})
+	return infos, nil
+}
+
+// writeStatusTable prints one row per instance for --all.
+func writeStatusTable(out io.Writer, infos []statusInfo) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tSTATUS\tAGENT\tUPTIME\tMCP\tERROR")
+	for _, info := range infos {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			info.Instance,
+			info.Status,
+			valueOrDash(info.Agent),
+			valueOrDash(info.Uptime),
+			valueOrDash(info.MCP),
+			valueOrDash(info.Error),
+		)
+	}
+	return w.Flush()
 }
 
 // formatDuration formats a duration in a human-readable way.
@@ -2,16 +2,21 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
 )
 
 func TestStatusCommandRegistered(t *testing.T) {
@@ -285,3 +290,54 @@ func TestStatusStoppedNoAgentOutput(t *testing.T) {
 		t.Error("stopped instance should not have Session line")
 	}
 }
+
+func TestStatusAllFlagRegistered(t *testing.T) {
+	assertFlagRegistered(t, statusCmd, "all")
+}
+
+func TestStatusAllListsStoppedInstances(t *testing.T) {
+	paths := &config.Paths{InstancesDir: t.TempDir()}
+	for _, name := range []string{"b", "a"} {
+		dir := filepath.Join(paths.InstancesDir, name)
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("workspace: /tmp\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	infos, err := collectAllStatuses(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("collectAllStatuses: %v", err)
+	}
+	if len(infos) != 2 || infos[0].Instance != "a" || infos[1].Instance != "b" {
+		t.Fatalf("infos = %+v, want a and b sorted by name", infos)
+	}
+	for _, info := range infos {
+		if info.Status != "stopped" || info.Container != "klausctl-"+info.Instance {
+			t.Errorf("info = %+v, want stopped klausctl-%s", info, info.Instance)
+		}
+	}
+}
+
+func TestWriteStatusTable(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeStatusTable(&buf, []statusInfo{
+		{Instance: "dev", Status: "running", Agent: "idle", Uptime: "5m0s", MCP: "http://localhost:8082"},
+		{Instance: "old", Status: "stopped", Error: "stale state"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+	if !strings.Contains(lines[1], "idle") || !strings.Contains(lines[1], "http://localhost:8082") {
+		t.Errorf("running row missing agent or MCP: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "stale state") {
+		t.Errorf("stopped row missing error: %q", lines[2])
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/signal"
 	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,25 +16,42 @@ import (
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
 	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 var stopCmd = &cobra.Command{
 	Use:   "stop [name]",
 	Short: "Stop the running klaus instance",
-	Long:  `Stop and remove the running klaus container.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runStop,
+	Long: `Stop and remove the running klaus container.
+
+The agent is sent SIGTERM and gets a grace period to shut down before it is
+killed with SIGKILL. The grace period is the stopTimeout of the instance config
+(10 seconds by default); --timeout overrides it for this stop.
+
+With --keep, the container is stopped but not removed, so its logs stay
+available (e.g. 'docker logs klausctl-<name>'). The next start reuses the kept
+container as long as the config still produces the same container, and
+replaces it otherwise.
+
+With --all, instances are stopped concurrently and a failure to stop one
+instance does not keep the others from being stopped.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runStop,
 }
 
 var (
 	stopAll       bool
 	stopNoArchive bool
+	stopKeep      bool
+	stopTimeout   time.Duration
 )
 
 func init() {
 	stopCmd.Flags().BoolVar(&stopAll, "all", false, "stop all instances")
 	stopCmd.Flags().BoolVar(&stopNoArchive, "no-archive", false, "skip archiving the agent transcript before stopping")
+	stopCmd.Flags().BoolVar(&stopKeep, "keep", false, "stop the container without removing it, so the next start reuses it")
+	stopCmd.Flags().DurationVar(&stopTimeout, "timeout", 0, "grace period before the agent is killed (e.g. 30s); defaults to the configured stopTimeout")
 	rootCmd.AddCommand(stopCmd)
 }
 
@@ -55,8 +73,16 @@ func runStop(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--all cannot be used with an instance name")
 	}
 
+	timeout := runtime.DefaultStopTimeout
+	if cmd.Flags().Changed("timeout") {
+		if stopTimeout < 0 {
+			return fmt.Errorf("--timeout must be >= 0, got %s", stopTimeout)
+		}
+		timeout = stopTimeout
+	}
+
 	if stopAll {
-		return stopAllInstances(ctx, out, paths)
+		return stopAllInstances(ctx, out, paths, timeout)
 	}
 
 	instanceName, err := resolveOptionalInstanceName(args, "stop", cmd.ErrOrStderr())
@@ -73,7 +99,7 @@ func runStop(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
 	if err != nil {
 		return err
 	}
@@ -88,19 +114,27 @@ func runStop(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Archive transcript before stopping.
-	if status == "running" && !stopNoArchive { //nolint:goconst
+	// Record usage and archive the transcript before stopping.
+	if status == "running" { //nolint:goconst
+		recordUsage(ctx, inst, paths)
+	}
+	if status == "running" && !stopNoArchive {
 		archiveBeforeStop(ctx, inst, paths)
 	}
 
 	// Stop the container if running.
 	if status == "running" {
 		_, _ = fmt.Fprintf(out, "Stopping %s...\n", containerName)
-		if err := rt.Stop(ctx, containerName); err != nil {
+		if err := rt.Stop(ctx, containerName, timeout); err != nil {
 			return fmt.Errorf("stopping container: %w", err)
 		}
 	}
 
+	if stopKeep {
+		_, _ = fmt.Fprintln(out, green("Klaus instance stopped; container kept."))
+		return nil
+	}
+
 	// Remove the container.
 	_, _ = fmt.Fprintf(out, "Removing %s...\n", containerName)
 	if err := rt.Remove(ctx, containerName); err != nil {
@@ -116,7 +150,7 @@ func runStop(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func stopAllInstances(ctx context.Context, out io.Writer, paths *config.Paths) error {
+func stopAllInstances(ctx context.Context, out io.Writer, paths *config.Paths, timeout time.Duration) error {
 	instances, err := instance.LoadAll(paths)
 	if err != nil {
 		return err
@@ -130,40 +164,59 @@ func stopAllInstances(ctx context.Context, out io.Writer, paths *config.Paths) e
 		return instances[i].Name < instances[j].Name
 	})
 
-	for _, inst := range instances {
-		rt, err := runtime.New(inst.Runtime)
-		if err != nil {
-			return err
-		}
-		name := inst.ContainerName()
-		status, err := rt.Status(ctx, name)
-		if err != nil || status == "" {
-			_ = instance.Clear(paths.ForInstance(inst.Name))
-			continue
-		}
-		// Archive before stopping.
-		if status == "running" && !stopNoArchive {
-			archiveBeforeStop(ctx, inst, paths)
-		}
-		if status == "running" {
-			_, _ = fmt.Fprintf(out, "Stopping %s...\n", name)
-			if err := rt.Stop(ctx, name); err != nil {
-				return fmt.Errorf("stopping %s: %w", name, err)
-			}
-		}
-		_, _ = fmt.Fprintf(out, "Removing %s...\n", name)
-		if err := rt.Remove(ctx, name); err != nil {
-			return fmt.Errorf("removing %s: %w", name, err)
-		}
-		if err := instance.Clear(paths.ForInstance(inst.Name)); err != nil {
-			return fmt.Errorf("clearing state for %s: %w", inst.Name, err)
-		}
+	out = orchestrator.SyncWriter(out)
+	err = instance.ForEach(ctx, instances, instance.DefaultConcurrency, func(ctx context.Context, inst *instance.Instance) error {
+		return stopInstanceOfAll(ctx, out, paths, inst, timeout)
+	})
+	if err != nil {
+		return fmt.Errorf("not all instances could be stopped:\n%w", err)
 	}
 
 	_, _ = fmt.Fprintln(out, green("All klaus instances stopped."))
 	return nil
 }
 
+// stopInstanceOfAll stops and removes the container of one instance for
+// stop --all and clears its state; with --keep the container and state are
+// kept. It runs concurrently with the other instances, so out must be safe
+// for concurrent use.
+func stopInstanceOfAll(ctx context.Context, out io.Writer, paths *config.Paths, inst *instance.Instance, timeout time.Duration) error {
+	rt, err := newRuntime(inst.Runtime, inst.RuntimeHost)
+	if err != nil {
+		return fmt.Errorf("%s: %w", inst.Name, err)
+	}
+	name := inst.ContainerName()
+	status, err := rt.Status(ctx, name)
+	if err != nil || status == "" {
+		_ = instance.Clear(paths.ForInstance(inst.Name))
+		return nil
+	}
+	// Record usage and archive before stopping.
+	if status == "running" {
+		recordUsage(ctx, inst, paths)
+	}
+	if status == "running" && !stopNoArchive {
+		archiveBeforeStop(ctx, inst, paths)
+	}
+	if status == "running" {
+		_, _ = fmt.Fprintf(out, "Stopping %s...\n", name)
+		if err := rt.Stop(ctx, name, timeout); err != nil {
+			return fmt.Errorf("stopping %s: %w", name, err)
+		}
+	}
+	if stopKeep {
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "Removing %s...\n", name)
+	if err := rt.Remove(ctx, name); err != nil {
+		return fmt.Errorf("removing %s: %w", name, err)
+	}
+	if err := instance.Clear(paths.ForInstance(inst.Name)); err != nil {
+		return fmt.Errorf("clearing state for %s: %w", inst.Name, err)
+	}
+	return nil
+}
+
 // archiveBeforeStop captures the agent transcript. Best-effort: logs and
 // continues on failure so the stop operation is never blocked.
 func archiveBeforeStop(ctx context.Context, inst *instance.Instance, paths *config.Paths) {
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	runtimepkg "github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+func TestStopFlags(t *testing.T) {
+	assertFlagRegistered(t, stopCmd, "all")
+	assertFlagRegistered(t, stopCmd, "no-archive")
+	assertFlagRegistered(t, stopCmd, "timeout")
+	assertFlagRegistered(t, stopCmd, "keep")
+}
+
+// setupStopTest saves running instances with the given names, installs a
+// fakeRuntime as the runtime factory and returns it with a command carrying
+// the stop flags.
+func setupStopTest(t *testing.T, names ...string) (*fakeRuntime, *cobra.Command) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(t.TempDir(), "config-home"))
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		inst := &instance.Instance{Name: name, Runtime: "docker", StartedAt: time.Now()}
+		if err := inst.Save(paths.ForInstance(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rt := &fakeRuntime{status: "running"}
+	orig := newRuntime
+	newRuntime = func(_, _ string) (runtimepkg.Runtime, error) { return rt, nil }
+
+	stopAll = false
+	stopNoArchive = true
+	stopKeep = false
+	stopTimeout = 0
+	t.Cleanup(func() {
+		newRuntime = orig
+		stopAll = false
+		stopNoArchive = false
+		stopKeep = false
+		stopTimeout = 0
+	})
+
+	cmd := &cobra.Command{}
+	cmd.Flags().DurationVar(&stopTimeout, "timeout", 0, "")
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return rt, cmd
+}
+
+func TestStopTimeoutReachesRuntime(t *testing.T) {
+	rt, cmd := setupStopTest(t, "dev")
+	if err := cmd.Flags().Set("timeout", "30s"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runStop(cmd, []string{"dev"}); err != nil {
+		t.Fatalf("runStop() error = %v", err)
+	}
+
+	if len(rt.stopTimeouts) != 1 || rt.stopTimeouts[0] != 30*time.Second {
+		t.Errorf("stop timeouts = %v, want [30s]", rt.stopTimeouts)
+	}
+}
+
+func TestStopWithoutTimeoutUsesContainerDefault(t *testing.T) {
+	rt, cmd := setupStopTest(t, "dev")
+
+	if err := runStop(cmd, []string{"dev"}); err != nil {
+		t.Fatalf("runStop() error = %v", err)
+	}
+
+	if len(rt.stopTimeouts) != 1 || rt.stopTimeouts[0] != runtimepkg.DefaultStopTimeout {
+		t.Errorf("stop timeouts = %v, want the container default", rt.stopTimeouts)
+	}
+}
+
+func TestStopAllStopsEveryInstanceWithTimeout(t *testing.T) {
+	rt, cmd := setupStopTest(t, "a", "b", "c", "d", "e", "f")
+	stopAll = true
+	if err := cmd.Flags().Set("timeout", "5s"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runStop(cmd, nil); err != nil {
+		t.Fatalf("runStop() error = %v", err)
+	}
+
+	if rt.stopCalls != 6 || rt.removeCalls != 6 {
+		t.Errorf("stop/remove calls = %d/%d, want 6/6", rt.stopCalls, rt.removeCalls)
+	}
+	for _, timeout := range rt.stopTimeouts {
+		if timeout != 5*time.Second {
+			t.Errorf("stop timeout = %v, want 5s", timeout)
+		}
+	}
+}
+
+func TestStopRejectsNegativeTimeout(t *testing.T) {
+	_, cmd := setupStopTest(t, "dev")
+	if err := cmd.Flags().Set("timeout", "-1s"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runStop(cmd, []string{"dev"}); err == nil {
+		t.Fatal("expected error for negative --timeout")
+	}
+}
+
+func TestStopKeepLeavesContainerAndState(t *testing.T) {
+	rt, cmd := setupStopTest(t, "dev")
+	stopKeep = true
+
+	if err := runStop(cmd, []string{"dev"}); err != nil {
+		t.Fatalf("runStop() error = %v", err)
+	}
+
+	if rt.stopCalls != 1 || rt.removeCalls != 0 {
+		t.Errorf("stop/remove calls = %d/%d, want 1/0", rt.stopCalls, rt.removeCalls)
+	}
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := instance.Load(paths.ForInstance("dev")); err != nil {
+		t.Errorf("instance state cleared: %v", err)
+	}
+}
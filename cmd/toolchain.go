@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"text/template"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 	"github.com/spf13/cobra"
@@ -27,11 +28,14 @@ var (
 	toolchainValidateOut    string
 	toolchainPullOut        string
 	toolchainPullSource     string
+	toolchainPullPlatform   string
 	toolchainListOut        string
 	toolchainListWide       bool
 	toolchainListLocal      bool
 	toolchainListSource     string
 	toolchainListAll        bool
+	toolchainListFilter     string
+	toolchainListFormat     string
 	toolchainDescribeOut    string
 	toolchainDescribeSource string
 )
@@ -54,7 +58,11 @@ var toolchainListCmd = &cobra.Command{
 By default, discovers toolchain images from the registry, shows the latest
 version of each, and indicates whether it has been pulled locally.
 
-With --local, shows Docker/Podman images matching the klaus-* naming pattern.`,
+With --local, shows Docker/Podman images matching the klaus-* naming pattern.
+
+--format prints each toolchain with a Go template instead of the table, e.g.
+'{{.Name}}\t{{.Ref}}'. Remote rows have the fields Name, Ref, PulledAt and
+Source; with --local, rows have Repository, Tag, ID, CreatedSince and Size.`,
 	RunE: runToolchainList,
 }
 
@@ -121,11 +129,14 @@ func init() {
 	toolchainValidateCmd.Flags().StringVarP(&toolchainValidateOut, "output", "o", "text", "output format: text, json")
 	toolchainPullCmd.Flags().StringVarP(&toolchainPullOut, "output", "o", "text", "output format: text, json")
 	toolchainPullCmd.Flags().StringVar(&toolchainPullSource, "source", "", "resolve against a specific source")
+	toolchainPullCmd.Flags().StringVar(&toolchainPullPlatform, "platform", "", "pull the image for a platform as os/arch, e.g. linux/amd64")
 	toolchainListCmd.Flags().StringVarP(&toolchainListOut, "output", "o", "text", "output format: text, json")
 	toolchainListCmd.Flags().BoolVar(&toolchainListWide, "wide", false, "show additional columns (ID, size) in --local mode")
 	toolchainListCmd.Flags().BoolVar(&toolchainListLocal, "local", false, "list only locally pulled toolchain images")
 	toolchainListCmd.Flags().StringVar(&toolchainListSource, "source", "", "list toolchains from a specific source only")
 	toolchainListCmd.Flags().BoolVar(&toolchainListAll, "all", false, "list toolchains from all configured sources")
+	toolchainListCmd.Flags().StringVar(&toolchainListFilter, "filter", "", "only list toolchains whose short name matches this glob (e.g. 'gs-*')")
+	toolchainListCmd.Flags().StringVar(&toolchainListFormat, "format", "", "format each toolchain with a Go template (e.g. '{{.Name}}')")
 
 	toolchainInitCmd.Flags().StringVar(&toolchainInitName, "name", "", "toolchain name (required)")
 	toolchainInitCmd.Flags().StringVar(&toolchainInitDir, "dir", "", "output directory (default: ./klaus-<name>)")
@@ -154,10 +165,18 @@ func runToolchainList(cmd *cobra.Command, _ []string) error {
 	if err := validateOutputFormat(toolchainListOut); err != nil {
 		return err
 	}
+	rowFormat, err := parseRowFormat(toolchainListFormat, toolchainListOut)
+	if err != nil {
+		return err
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
+	if err := validateNameFilter(toolchainListFilter); err != nil {
+		return err
+	}
+
 	out := cmd.OutOrStdout()
 
 	resolver, err := buildListSourceResolver(toolchainListSource, toolchainListAll)
@@ -171,13 +190,15 @@ func runToolchainList(cmd *cobra.Command, _ []string) error {
 			return err
 		}
 		return toolchainList(ctx, out, rt, toolchainListOptions{
-			output:   toolchainListOut,
-			wide:     toolchainListWide,
-			resolver: resolver,
+			output:    toolchainListOut,
+			wide:      toolchainListWide,
+			filter:    toolchainListFilter,
+			resolver:  resolver,
+			rowFormat: rowFormat,
 		})
 	}
 
-	return runToolchainListRemote(ctx, out, resolver)
+	return runToolchainListRemote(ctx, out, resolver, rowFormat)
 }
 
 // listToolchainsFn wraps the typed ListToolchains method for use with listLatestRemoteArtifacts.
@@ -189,17 +210,21 @@ var listToolchainsFn listFn = func(ctx context.Context, client *klausoci.Client,
 // resolves the latest semver tag and digest for each, and checks local
 // pull status. Toolchains don't use the OCI cache directory so cacheDir
 // is empty, which means the PULLED column will always show "-".
-func runToolchainListRemote(ctx context.Context, out io.Writer, resolver *config.SourceResolver) error {
+func runToolchainListRemote(ctx context.Context, out io.Writer, resolver *config.SourceResolver, rowFormat *template.Template) error {
 	registries := resolver.ToolchainRegistries()
-	return listMultiSourceRemoteArtifacts(ctx, out, "", registries, toolchainListOut,
-		"No toolchain images found in the remote registry.", listToolchainsFn)
+	return listMultiSourceRemoteArtifacts(ctx, out, "", registries, toolchainListOut, rowFormat,
+		"No toolchain images found in the remote registry.", toolchainListFilter, listToolchainsFn)
 }
 
 // toolchainListOptions controls output formatting for the toolchain list.
 type toolchainListOptions struct {
-	output   string
-	wide     bool
+	output string
+	wide   bool
+	// filter is a glob matched against the image short name.
+	filter   string
 	resolver *config.SourceResolver
+	// rowFormat, when set, replaces the table with a per-row template.
+	rowFormat *template.Template
 }
 
 // toolchainList lists locally cached toolchain images using the given runtime.
@@ -213,6 +238,9 @@ func toolchainList(ctx context.Context, out io.Writer, rt runtime.Runtime, opts
 
 	var images []runtime.ImageInfo
 	for _, img := range all {
+		if !matchesNameFilter(opts.filter, klausoci.ShortName(img.Repository)) {
+			continue
+		}
 		for _, sr := range registries {
 			if strings.HasPrefix(img.Repository, sr.Registry+"/") {
 				images = append(images, img)
@@ -221,6 +249,10 @@ func toolchainList(ctx context.Context, out io.Writer, rt runtime.Runtime, opts
 		}
 	}
 
+	if opts.rowFormat != nil {
+		return printFormattedRows(out, opts.rowFormat, images)
+	}
+
 	if len(images) == 0 {
 		return printEmpty(out, opts.output,
 			"No toolchain images found locally.",
@@ -298,6 +330,9 @@ func runToolchainPull(cmd *cobra.Command, args []string) error {
 	if err := validateOutputFormat(toolchainPullOut); err != nil {
 		return err
 	}
+	if err := config.ValidatePlatform(toolchainPullPlatform); err != nil {
+		return err
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
@@ -314,15 +349,19 @@ func runToolchainPull(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ref := resolver.ResolveToolchainRef(args[0])
+	ref, err := resolver.ResolveToolchainRef(args[0])
+	if err != nil {
+		return err
+	}
 
 	progressOut := out
 	if toolchainPullOut == "json" {
 		progressOut = cmd.ErrOrStderr()
 	}
+	progressOut = progressWriter(progressOut)
 
 	_, _ = fmt.Fprintf(progressOut, "Pulling %s...\n", ref)
-	if err := rt.Pull(ctx, ref, progressOut); err != nil {
+	if err := rt.Pull(ctx, ref, runtime.PullOptions{Platform: toolchainPullPlatform}, progressOut); err != nil {
 		return fmt.Errorf("pulling image: %w", err)
 	}
 
@@ -352,7 +391,10 @@ func runToolchainDescribe(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	ref := resolver.ResolveToolchainRef(args[0])
+	ref, err := resolver.ResolveToolchainRef(args[0])
+	if err != nil {
+		return err
+	}
 	client := orchestrator.NewDefaultClient()
 	dt, err := client.DescribeToolchain(ctx, ref)
 	if err != nil {
@@ -11,6 +11,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	klausoci "github.com/giantswarm/klaus-oci"
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/runtime"
@@ -18,26 +21,46 @@ import (
 
 // mockRuntime implements runtime.Runtime for testing.
 type mockRuntime struct {
-	images []runtime.ImageInfo
-	err    error
+	images     []runtime.ImageInfo
+	err        error
+	version    string
+	versionErr error
+	logs       string
 }
 
 func (m *mockRuntime) Name() string                                                { return "mock" }
 func (m *mockRuntime) Run(_ context.Context, _ runtime.RunOptions) (string, error) { return "", nil }
-func (m *mockRuntime) Stop(_ context.Context, _ string) error                      { return nil }
+func (m *mockRuntime) Stop(_ context.Context, _ string, _ time.Duration) error     { return nil }
 func (m *mockRuntime) Remove(_ context.Context, _ string) error                    { return nil }
+func (m *mockRuntime) Start(_ context.Context, _ string) error                     { return nil }
+func (m *mockRuntime) Pause(_ context.Context, _ string) error                     { return nil }
+func (m *mockRuntime) Unpause(_ context.Context, _ string) error                   { return nil }
 func (m *mockRuntime) Status(_ context.Context, _ string) (string, error)          { return "", nil }
 func (m *mockRuntime) Inspect(_ context.Context, _ string) (*runtime.ContainerInfo, error) {
 	return nil, nil
 }
-func (m *mockRuntime) Pull(_ context.Context, _ string, _ io.Writer) error   { return nil }
-func (m *mockRuntime) Logs(_ context.Context, _ string, _ bool, _ int) error { return nil }
+func (m *mockRuntime) Pull(_ context.Context, _ string, _ runtime.PullOptions, _ io.Writer) error {
+	return nil
+}
+func (m *mockRuntime) Logs(_ context.Context, _ string, _ bool, _ int, _, _ io.Writer) error {
+	return nil
+}
 func (m *mockRuntime) LogsCapture(_ context.Context, _ string, _ int) (string, error) {
-	return "", nil
+	return m.logs, nil
 }
 func (m *mockRuntime) Images(_ context.Context, _ string) ([]runtime.ImageInfo, error) {
 	return m.images, m.err
 }
+func (m *mockRuntime) Version(_ context.Context) (string, error) { return m.version, m.versionErr }
+func (m *mockRuntime) ListManaged(_ context.Context) ([]runtime.ContainerInfo, error) {
+	return nil, nil
+}
+func (m *mockRuntime) Events(_ context.Context, _ string, _ func(runtime.Event) error) error {
+	return nil
+}
+func (m *mockRuntime) Exec(_ context.Context, _ string, _ runtime.ExecOptions) error {
+	return nil
+}
 
 func TestSubcommandsRegistered(t *testing.T) {
 	assertCommandOnRoot(t, "toolchain")
@@ -57,6 +80,10 @@ func TestToolchainListLocalFlag(t *testing.T) {
 	assertFlagRegistered(t, toolchainListCmd, "local")
 }
 
+func TestToolchainListFilterFlag(t *testing.T) {
+	assertFlagRegistered(t, toolchainListCmd, "filter")
+}
+
 func TestScaffoldFiles(t *testing.T) {
 	files := scaffoldFiles("go")
 
@@ -239,6 +266,39 @@ func TestToolchainListWithImages(t *testing.T) {
 	}
 }
 
+func TestToolchainListFilter(t *testing.T) {
+	rt := &mockRuntime{
+		images: []runtime.ImageInfo{
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-toolchains/go", Tag: "1.0.0"},
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-toolchains/golangci", Tag: "1.1.0"},
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-toolchains/python", Tag: "2.1.0"},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := toolchainList(context.Background(), &buf, rt, toolchainListOptions{filter: "go*", resolver: config.DefaultSourceResolver()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "klaus-toolchains/go ") || !strings.Contains(output, "klaus-toolchains/golangci") {
+		t.Errorf("expected go and golangci toolchains, got:\n%s", output)
+	}
+	if strings.Contains(output, "python") {
+		t.Errorf("expected python toolchain to be filtered out, got:\n%s", output)
+	}
+
+	buf.Reset()
+	err = toolchainList(context.Background(), &buf, rt, toolchainListOptions{filter: "rust*", resolver: config.DefaultSourceResolver()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No toolchain images found locally") {
+		t.Errorf("expected empty-state message when nothing matches, got:\n%s", buf.String())
+	}
+}
+
 func TestToolchainListEmpty(t *testing.T) {
 	rt := &mockRuntime{}
 
@@ -408,3 +468,82 @@ func TestToolchainFlagsRegistered(t *testing.T) {
 	assertFlagRegistered(t, toolchainDescribeCmd, "output")
 	assertFlagRegistered(t, toolchainDescribeCmd, "source")
 }
+
+// stubListToolchains replaces the remote toolchain listing with fn and
+// restores it when the test ends.
+func stubListToolchains(t *testing.T, fn listFn) {
+	t.Helper()
+	orig := listToolchainsFn
+	listToolchainsFn = fn
+	t.Cleanup(func() { listToolchainsFn = orig })
+}
+
+func twoToolchainSources() *config.SourceResolver {
+	return config.NewSourceResolver([]config.Source{
+		{Name: "giantswarm", Registry: "gsoci.azurecr.io/giantswarm", Default: true},
+		{Name: "team", Registry: "reg.example.com/team"},
+	})
+}
+
+func TestToolchainListRemoteAggregatesSources(t *testing.T) {
+	calls := 0
+	stubListToolchains(t, func(_ context.Context, _ *klausoci.Client, _ ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+		calls++
+		return []klausoci.ListEntry{{Name: "go", Reference: fmt.Sprintf("registry-%d/klaus-go:v1.0.0", calls)}}, nil
+	})
+
+	var out bytes.Buffer
+	if err := runToolchainListRemote(t.Context(), &out, twoToolchainSources(), nil); err != nil {
+		t.Fatalf("runToolchainListRemote() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("listed %d registries, want 2", calls)
+	}
+	for _, want := range []string{"SOURCE", "giantswarm", "team", "registry-1/klaus-go:v1.0.0", "registry-2/klaus-go:v1.0.0"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestToolchainListRemoteSingleSource(t *testing.T) {
+	calls := 0
+	stubListToolchains(t, func(_ context.Context, _ *klausoci.Client, _ ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+		calls++
+		return []klausoci.ListEntry{{Name: "go", Reference: "reg.example.com/team/klaus-go:v1.0.0"}}, nil
+	})
+
+	resolver, err := twoToolchainSources().ForSource("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := runToolchainListRemote(t.Context(), &out, resolver, nil); err != nil {
+		t.Fatalf("runToolchainListRemote() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("listed %d registries, want 1", calls)
+	}
+	if strings.Contains(out.String(), "SOURCE") {
+		t.Errorf("single-source output should not have a SOURCE column:\n%s", out.String())
+	}
+}
+
+func TestToolchainListRemoteWarnsOnFailedSource(t *testing.T) {
+	calls := 0
+	stubListToolchains(t, func(_ context.Context, _ *klausoci.Client, _ ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("unauthorized")
+		}
+		return []klausoci.ListEntry{{Name: "go", Reference: "gsoci.azurecr.io/giantswarm/klaus-go:v1.0.0"}}, nil
+	})
+
+	var out bytes.Buffer
+	if err := runToolchainListRemote(t.Context(), &out, twoToolchainSources(), nil); err != nil {
+		t.Fatalf("runToolchainListRemote() error = %v", err)
+	}
+	if !strings.Contains(out.String(), `Warning: source "team"`) || !strings.Contains(out.String(), "unauthorized") {
+		t.Errorf("output missing warning for the failed source:\n%s", out.String())
+	}
+}
@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/orchestrator"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+var (
+	upFile   string
+	downFile string
+)
+
+var upCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Create and start all instances of a stack file",
+	Long: `Bring up the instances listed in a stack file, so that a team's fixed set of
+agents can be started with one command:
+
+  instances:
+    reviewer:
+      workspace: ./app
+      personality: code-reviewer
+    docs:
+      workspace: giantswarm/docs
+      model: sonnet
+      envVars:
+        DOCS_ONLY: "1"
+
+Instance fields match the flags of create; relative workspace and envFile
+paths are resolved against the directory of the stack file. Instances are
+created without a name suffix, and ports are picked for instances without one.
+
+Instances that already exist are started with their current config, and
+running ones are left alone; delete an instance to recreate it from the stack
+file. Instances are brought up concurrently and a failure of one does not keep
+the others from coming up. A summary is printed at the end.`,
+	Args: cobra.NoArgs,
+	RunE: runUp,
+}
+
+var downCmd = &cobra.Command{
+	Use:   "down",
+	Short: "Stop all instances of a stack file",
+	Long: `Stop the instances listed in a stack file (see up). The instances are kept
+and can be brought up again; use delete to remove them.`,
+	Args: cobra.NoArgs,
+	RunE: runDown,
+}
+
+func init() {
+	upCmd.Flags().StringVarP(&upFile, "file", "f", config.DefaultStackFile, "stack file listing the instances")
+	downCmd.Flags().StringVarP(&downFile, "file", "f", config.DefaultStackFile, "stack file listing the instances")
+
+	rootCmd.AddCommand(upCmd)
+	rootCmd.AddCommand(downCmd)
+}
+
+// Outcomes of bringing up a stack instance.
+const (
+	stackCreated = "created"
+	stackStarted = "started"
+	stackRunning = "running"
+	stackFailed  = "failed"
+)
+
+// stackResult is the outcome of bringing up one instance of a stack.
+type stackResult struct {
+	Name   string
+	Status string
+	Port   int
+	Err    error
+}
+
+func runUp(cmd *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	stack, err := config.LoadStack(upFile)
+	if err != nil {
+		return err
+	}
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	names := stack.Names()
+	collisions := make([]instance.CollisionState, len(names))
+	var toCreate []*config.CreateOptions
+	creates := make(map[string]*config.CreateOptions)
+	for i, name := range names {
+		collisions[i], err = instance.CheckCollision(ctx, paths.ForInstance(name))
+		if err != nil {
+			return fmt.Errorf("checking for existing instance %q: %w", name, err)
+		}
+		if collisions[i] == instance.NoCollision {
+			opts := stack.Instances[name].CreateOptions(name)
+			creates[name] = &opts
+			toCreate = append(toCreate, &opts)
+		}
+	}
+	if err := assignStackPorts(paths, toCreate); err != nil {
+		return err
+	}
+
+	out := orchestrator.SyncWriter(cmd.OutOrStdout())
+	results := make([]stackResult, len(names))
+	sem := make(chan struct{}, instance.DefaultConcurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = upStackInstance(ctx, out, paths, name, collisions[i], creates[name])
+		}()
+	}
+	wg.Wait()
+
+	_, _ = fmt.Fprintln(cmd.OutOrStdout())
+	printStackSummary(cmd.OutOrStdout(), results)
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Name, r.Err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("not all instances could be brought up:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// upStackInstance creates and starts one instance of a stack, or starts it
+// when it exists and is not running. opts is nil for existing instances. It
+// runs concurrently with the other instances and writes its output to out
+// prefixed with the instance name, so out must be safe for concurrent use.
+func upStackInstance(ctx context.Context, out io.Writer, paths *config.Paths, name string, collision instance.CollisionState, opts *config.CreateOptions) stackResult {
+	result := stackResult{Name: name}
+	w := newPrefixWriter(out, name+" | ")
+	defer w.Flush()
+
+	sub := &cobra.Command{}
+	sub.SetOut(w)
+	sub.SetErr(w)
+
+	var err error
+	switch collision {
+	case instance.CollisionRunning:
+		result.Status = stackRunning
+	case instance.CollisionStopped:
+		result.Status = stackStarted
+		err = startInstance(sub, name, "", "")
+	default:
+		result.Status = stackCreated
+		err = createStackInstance(ctx, sub, paths, opts)
+	}
+	if err != nil {
+		result.Status = stackFailed
+		result.Err = err
+		return result
+	}
+
+	if inst, err := instance.Load(paths.ForInstance(name)); err == nil {
+		result.Port = inst.Port
+	}
+	return result
+}
+
+// createStackInstance resolves the artifact references, workspace and env
+// file of a new stack instance as create does and creates it.
+func createStackInstance(ctx context.Context, cmd *cobra.Command, paths *config.Paths, opts *config.CreateOptions) error {
+	resolver, err := buildSourceResolver("")
+	if err != nil {
+		return err
+	}
+	opts.Personality, opts.Toolchain, opts.Plugins, err = orchestrator.ResolveCreateRefs(ctx, resolver, opts.Personality, opts.Toolchain, opts.Plugins)
+	if err != nil {
+		return err
+	}
+	opts.Workspace, err = config.ResolveCreateWorkspace(paths, opts.Workspace)
+	if err != nil {
+		return err
+	}
+	if opts.EnvFile != "" {
+		if _, err := config.LoadEnvFile(opts.EnvFile); err != nil {
+			return err
+		}
+	}
+
+	opts.SourceResolver = resolver
+	opts.Context = ctx
+	opts.Output = cmd.OutOrStdout()
+	return createAndStartInstance(cmd, paths, *opts)
+}
+
+// assignStackPorts sets a distinct free port on the options without one.
+// The instances are created concurrently, and each would otherwise pick the
+// same lowest free port.
+func assignStackPorts(paths *config.Paths, opts []*config.CreateOptions) error {
+	used, err := config.UsedPorts(paths)
+	if err != nil {
+		return err
+	}
+	for _, o := range opts {
+		if o.Port > 0 {
+			used[o.Port] = true
+		}
+	}
+
+	port := 8080
+	for _, o := range opts {
+		if o.Port > 0 {
+			continue
+		}
+		for port <= 65535 && (used[port] || !config.IsPortAvailable(port)) {
+			port++
+		}
+		if port > 65535 {
+			return fmt.Errorf("no available ports in range 8080-65535")
+		}
+		o.Port = port
+		used[port] = true
+	}
+	return nil
+}
+
+// printStackSummary prints the outcome of each stack instance as a table.
+func printStackSummary(out io.Writer, results []stackResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tSTATUS\tMCP")
+	for _, r := range results {
+		mcp := "-"
+		if r.Port > 0 {
+			mcp = "http://localhost:" + strconv.Itoa(r.Port)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.Status, mcp)
+	}
+	_ = w.Flush()
+}
+
+func runDown(cmd *cobra.Command, _ []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	out := cmd.OutOrStdout()
+
+	stack, err := config.LoadStack(downFile)
+	if err != nil {
+		return err
+	}
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	var instances []*instance.Instance
+	for _, name := range stack.Names() {
+		inst, err := instance.Load(paths.ForInstance(name))
+		if err != nil {
+			continue
+		}
+		instances = append(instances, inst)
+	}
+	if len(instances) == 0 {
+		_, _ = fmt.Fprintln(out, "No klaus instances of the stack are running.")
+		return nil
+	}
+
+	out = orchestrator.SyncWriter(out)
+	err = instance.ForEach(ctx, instances, instance.DefaultConcurrency, func(ctx context.Context, inst *instance.Instance) error {
+		return stopInstanceOfAll(ctx, out, paths, inst, runtime.DefaultStopTimeout)
+	})
+	if err != nil {
+		return fmt.Errorf("not all instances could be stopped:\n%w", err)
+	}
+
+	_, _ = fmt.Fprintln(out, green("Klaus stack stopped."))
+	return nil
+}
+
+// prefixWriter writes each line to w with a prefix, so that the output of
+// instances brought up concurrently can be told apart. An incomplete last
+// line is held back until Flush.
+type prefixWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func newPrefixWriter(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{w: w, prefix: prefix}
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if _, err := fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf[:i]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// Flush writes a buffered incomplete line.
+func (p *prefixWriter) Flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.buf) > 0 {
+		_, _ = fmt.Fprintf(p.w, "%s%s\n", p.prefix, p.buf)
+		p.buf = nil
+	}
+}
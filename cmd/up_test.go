@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestUpDownRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "up")
+	assertCommandOnRoot(t, "down")
+	assertFlagRegistered(t, upCmd, "file")
+	assertFlagRegistered(t, downCmd, "file")
+}
+
+func TestAssignStackPorts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(t.TempDir(), "config-home"))
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []*config.CreateOptions{{Name: "a"}, {Name: "b", Port: 8080}, {Name: "c"}}
+	if err := assignStackPorts(paths, opts); err != nil {
+		t.Fatalf("assignStackPorts() error = %v", err)
+	}
+
+	seen := make(map[int]string)
+	for _, o := range opts {
+		if o.Port == 0 {
+			t.Errorf("%s: no port assigned", o.Name)
+		}
+		if other, ok := seen[o.Port]; ok {
+			t.Errorf("%s and %s share port %d", other, o.Name, o.Port)
+		}
+		seen[o.Port] = o.Name
+	}
+	if opts[1].Port != 8080 {
+		t.Errorf("explicit port changed to %d", opts[1].Port)
+	}
+}
+
+func TestPrintStackSummary(t *testing.T) {
+	var out bytes.Buffer
+	printStackSummary(&out, []stackResult{
+		{Name: "docs", Status: stackCreated, Port: 8081},
+		{Name: "reviewer", Status: stackFailed, Err: errors.New("boom")},
+	})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 3 || fields[1] != "created" || fields[2] != "http://localhost:8081" {
+		t.Errorf("row = %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 3 || fields[1] != "failed" || fields[2] != "-" {
+		t.Errorf("row = %q", lines[2])
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newPrefixWriter(&out, "dev | ")
+	_, _ = w.Write([]byte("Pulling image...\nStart"))
+	_, _ = w.Write([]byte("ing\nDone"))
+	w.Flush()
+
+	want := "dev | Pulling image...\ndev | Starting\ndev | Done\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestDownStopsStackInstances(t *testing.T) {
+	rt, cmd := setupStopTest(t, "a", "b", "other")
+	stackFile := filepath.Join(t.TempDir(), "klaus-stack.yaml")
+	if err := os.WriteFile(stackFile, []byte("instances:\n  a: {}\n  b: {}\n  missing: {}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	downFile = stackFile
+	t.Cleanup(func() { downFile = config.DefaultStackFile })
+
+	if err := runDown(cmd, nil); err != nil {
+		t.Fatalf("runDown() error = %v", err)
+	}
+	if rt.stopCalls != 2 || rt.removeCalls != 2 {
+		t.Errorf("stop/remove calls = %d/%d, want 2/2", rt.stopCalls, rt.removeCalls)
+	}
+}
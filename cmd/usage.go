@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/mcpclient"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+	"github.com/giantswarm/klausctl/pkg/usage"
+)
+
+var (
+	usageOutput      string
+	usageInputPrice  float64
+	usageOutputPrice float64
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage <name>",
+	Short: "Show token usage and spend of an instance",
+	Long: `Show the token usage and spend of an instance: the recorded history of
+its agent runs, and the totals reported in the logs of its container.
+
+klausctl records the usage and cost of each agent run in usage.jsonl in the
+instance directory when it sees the run complete (prompt --blocking, wait,
+the MCP prompt and run tools) and before stopping the instance, so the
+history outlives the container.
+
+For the container logs, the cost reported by completed runs is used as-is.
+Usage of a run that has not finished yet is estimated with --input-price and
+--output-price (USD per million tokens; cache tokens are priced as input).
+The logs are only read while the instance has a container.
+
+Examples:
+
+  klausctl usage dev
+  klausctl usage dev -o json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runUsage,
+}
+
+func init() {
+	usageCmd.Flags().StringVarP(&usageOutput, "output", "o", "text", "output format: text, json")
+	usageCmd.Flags().Float64Var(&usageInputPrice, "input-price", usage.DefaultPricing.InputPerMTok, "USD per million input tokens for estimating unpriced usage")
+	usageCmd.Flags().Float64Var(&usageOutputPrice, "output-price", usage.DefaultPricing.OutputPerMTok, "USD per million output tokens for estimating unpriced usage")
+	rootCmd.AddCommand(usageCmd)
+}
+
+// usageCLIResult is the JSON representation of the usage command output.
+type usageCLIResult struct {
+	Instance string `json:"instance"`
+	// Summary is nil when the instance has no container to read logs from.
+	*usage.Summary
+	EstimatedCostUSD float64        `json:"estimated_cost_usd"`
+	MaxBudgetUSD     float64        `json:"max_budget_usd,omitempty"`
+	History          *usage.History `json:"history"`
+}
+
+func runUsage(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(usageOutput); err != nil {
+		return err
+	}
+	if usageInputPrice < 0 || usageOutputPrice < 0 {
+		return fmt.Errorf("--input-price and --output-price must be >= 0")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	instanceName := args[0]
+	if err := config.ValidateInstanceName(instanceName); err != nil {
+		return err
+	}
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+	paths = paths.ForInstance(instanceName)
+	if _, err := os.Stat(paths.InstanceDir); err != nil {
+		return fmt.Errorf("no klaus instance found for %q", instanceName)
+	}
+
+	history, err := usage.LoadHistory(paths.UsageFile)
+	if err != nil {
+		return err
+	}
+
+	var maxBudget float64
+	if cfg, err := config.Load(paths.ConfigFile); err == nil {
+		maxBudget = cfg.Claude.MaxBudgetUSD
+	}
+
+	// A stopped instance has no state and no container, only its history.
+	result := &usageCLIResult{Instance: instanceName, MaxBudgetUSD: maxBudget}
+	if inst, err := instance.Load(paths); err == nil {
+		rt, err := inst.NewRuntime()
+		if err != nil {
+			return err
+		}
+		result, err = collectUsage(ctx, rt, inst, maxBudget, usage.Pricing{
+			InputPerMTok:  usageInputPrice,
+			OutputPerMTok: usageOutputPrice,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	result.History = history
+
+	out := cmd.OutOrStdout()
+	if usageOutput == "json" {
+		return writeJSON(out, result)
+	}
+	printUsage(out, result)
+	return nil
+}
+
+// collectUsage reads the full container logs of inst and sums their usage.
+func collectUsage(ctx context.Context, rt runtime.Runtime, inst *instance.Instance, maxBudget float64, pricing usage.Pricing) (*usageCLIResult, error) {
+	logs, err := rt.LogsCapture(ctx, inst.ContainerName(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("reading logs: %w", err)
+	}
+
+	summary, err := usage.Parse(strings.NewReader(logs))
+	if err != nil {
+		return nil, err
+	}
+
+	return &usageCLIResult{
+		Instance:         inst.Name,
+		Summary:          summary,
+		EstimatedCostUSD: summary.EstimatedCostUSD(pricing),
+		MaxBudgetUSD:     maxBudget,
+	}, nil
+}
+
+func printUsage(out io.Writer, r *usageCLIResult) {
+	if r.History != nil && len(r.History.Runs) > 0 {
+		printUsageHistory(out, r.History)
+		_, _ = fmt.Fprintln(out)
+	}
+	if r.Summary == nil {
+		if r.History == nil || len(r.History.Runs) == 0 {
+			_, _ = fmt.Fprintf(out, "No usage recorded for %s.\n", r.Instance)
+		}
+		return
+	}
+	if r.Runs == 0 && r.Responses == 0 {
+		_, _ = fmt.Fprintf(out, "No usage events found in the logs of %s.\n", r.Instance)
+		return
+	}
+
+	_, _ = fmt.Fprintf(out, "%-15s %s\n", "Instance:", r.Instance)
+	_, _ = fmt.Fprintf(out, "%-15s %d (%d model responses)\n", "Runs:", r.Runs, r.Responses)
+	_, _ = fmt.Fprintf(out, "%-15s %d\n", "Input tokens:", r.InputTokens)
+	_, _ = fmt.Fprintf(out, "%-15s %d\n", "Output tokens:", r.OutputTokens)
+	if r.CacheCreationInputTokens > 0 || r.CacheReadInputTokens > 0 {
+		_, _ = fmt.Fprintf(out, "%-15s %d written, %d read\n", "Cache tokens:", r.CacheCreationInputTokens, r.CacheReadInputTokens)
+	}
+	_, _ = fmt.Fprintf(out, "%-15s $%.4f\n", "Reported cost:", r.ReportedCostUSD)
+	_, _ = fmt.Fprintf(out, "%-15s $%.4f\n", "Estimated cost:", r.EstimatedCostUSD)
+	if r.MaxBudgetUSD > 0 {
+		_, _ = fmt.Fprintf(out, "%-15s $%.2f per run\n", "Budget:", r.MaxBudgetUSD)
+	}
+}
+
+// printUsageHistory prints the recorded runs, oldest first, and their
+// totals.
+func printUsageHistory(out io.Writer, h *usage.History) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tSTATUS\tINPUT\tOUTPUT\tCOST")
+	for _, run := range h.Runs {
+		cost := "-"
+		if run.CostUSD != nil {
+			cost = fmt.Sprintf("$%.4f", *run.CostUSD)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n",
+			run.Time.Local().Format(time.DateTime), run.Status, run.InputTokens, run.OutputTokens, cost)
+	}
+	_, _ = fmt.Fprintf(tw, "TOTAL (%d runs)\t\t%d\t%d\t$%.4f\n", len(h.Runs), h.InputTokens, h.OutputTokens, h.CostUSD)
+	_ = tw.Flush()
+}
+
+// recordUsage appends the last run of the agent of inst to its usage
+// history. Best-effort: logs and continues on failure.
+func recordUsage(ctx context.Context, inst *instance.Instance, paths *config.Paths) {
+	client := mcpclient.New(buildVersion)
+	defer client.Close()
+
+	usageFile := paths.ForInstance(inst.Name).UsageFile
+	if err := usage.Record(ctx, client, inst.Name, inst.AgentURL()+"/mcp", usageFile); err != nil {
+		log.Printf("Warning: failed to record usage of %q: %v", inst.Name, err)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/usage"
+)
+
+func TestUsageCommandRegistered(t *testing.T) {
+	assertCommandOnRoot(t, "usage")
+	for _, name := range []string{"output", "input-price", "output-price"} {
+		assertFlagRegistered(t, usageCmd, name)
+	}
+}
+
+func TestCollectUsage(t *testing.T) {
+	rt := &mockRuntime{logs: `{"type":"assistant","message":{"usage":{"input_tokens":100,"output_tokens":10}}}
+{"type":"result","total_cost_usd":0.1,"usage":{"input_tokens":100,"output_tokens":10}}
+`}
+	inst := &instance.Instance{Name: "dev"}
+
+	result, err := collectUsage(context.Background(), rt, inst, 5, usage.DefaultPricing)
+	if err != nil {
+		t.Fatalf("collectUsage() error = %v", err)
+	}
+	if result.InputTokens != 100 || result.OutputTokens != 10 {
+		t.Errorf("tokens = %d/%d, want 100/10", result.InputTokens, result.OutputTokens)
+	}
+
+	var buf bytes.Buffer
+	printUsage(&buf, result)
+	for _, want := range []string{"Instance:       dev", "Input tokens:   100", "Reported cost:  $0.1000", "Budget:         $5.00 per run"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, result); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"instance", "input_tokens", "output_tokens", "reported_cost_usd", "estimated_cost_usd", "max_budget_usd"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("JSON missing %q: %s", key, buf.String())
+		}
+	}
+}
+
+func TestPrintUsageEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	printUsage(&buf, &usageCLIResult{Instance: "dev", Summary: &usage.Summary{}})
+	if !strings.Contains(buf.String(), "No usage events found") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestPrintUsageHistoryOnly(t *testing.T) {
+	cost := 0.25
+	history := &usage.History{
+		Runs: []usage.Run{
+			{Time: time.Now(), Status: "completed", CostUSD: &cost, Tokens: usage.Tokens{InputTokens: 150, OutputTokens: 30}},
+			{Time: time.Now(), Status: "error", Tokens: usage.Tokens{InputTokens: 10, OutputTokens: 1}},
+		},
+		Tokens:  usage.Tokens{InputTokens: 160, OutputTokens: 31},
+		CostUSD: cost,
+	}
+
+	var buf bytes.Buffer
+	printUsage(&buf, &usageCLIResult{Instance: "dev", History: history})
+	for _, want := range []string{"completed", "$0.2500", "TOTAL (2 runs)", "160"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "logs") {
+		t.Errorf("output mentions logs of a stopped instance:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := writeJSON(&buf, &usageCLIResult{Instance: "dev", History: history}); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		History usage.History `json:"history"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.History.Runs) != 2 || decoded.History.CostUSD != cost {
+		t.Errorf("JSON history = %+v", decoded.History)
+	}
+}
+
+func TestPrintUsageNothingRecorded(t *testing.T) {
+	var buf bytes.Buffer
+	printUsage(&buf, &usageCLIResult{Instance: "dev", History: &usage.History{}})
+	if !strings.Contains(buf.String(), "No usage recorded") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
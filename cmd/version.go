@@ -1,25 +1,139 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"runtime/debug"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
+// klausOCIModule is the module path of the OCI library whose version is
+// reported by the version command.
+const klausOCIModule = "github.com/giantswarm/klaus-oci"
+
+// runtimeVersionTimeout bounds how long the version command waits for the
+// container runtime CLI to answer.
+const runtimeVersionTimeout = 5 * time.Second
+
+var versionOut string
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show version information",
-	Long:  `Display the klausctl version, commit, and build date.`,
-	Run:   runVersion,
+	Long: `Display the klausctl version, commit, and build date, together with the
+klaus-oci library version and the detected container runtime and its version.
+
+Use --output json for machine-readable output, e.g. when filing bug reports.`,
+	RunE: runVersion,
+}
+
+// versionInfo is the JSON representation of the version command output.
+type versionInfo struct {
+	Version  string              `json:"version"`
+	Commit   string              `json:"commit"`
+	Date     string              `json:"date"`
+	KlausOCI string              `json:"klausOci"`
+	Runtime  *runtimeVersionInfo `json:"runtime"`
+}
+
+// runtimeVersionInfo describes the detected container runtime. Error is set
+// instead of Version when the runtime is missing or did not answer.
+type runtimeVersionInfo struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
 }
 
 func init() {
+	versionCmd.Flags().StringVarP(&versionOut, "output", "o", "text", "output format: text, json")
 	rootCmd.AddCommand(versionCmd)
 }
 
-func runVersion(cmd *cobra.Command, _ []string) {
+func runVersion(cmd *cobra.Command, _ []string) error {
+	if err := validateOutputFormat(versionOut); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), runtimeVersionTimeout)
+	defer cancel()
+
+	rt, rtErr := loadRuntime()
+	info := collectVersionInfo(ctx, rt, rtErr)
+
 	out := cmd.OutOrStdout()
-	_, _ = fmt.Fprintf(out, "klausctl %s\n", buildVersion)
-	_, _ = fmt.Fprintf(out, "  commit: %s\n", buildCommit)
-	_, _ = fmt.Fprintf(out, "  built:  %s\n", buildDate)
+	if versionOut == "json" {
+		return writeJSON(out, info)
+	}
+	printVersionInfo(out, info)
+	return nil
+}
+
+// collectVersionInfo gathers build metadata and queries rt for its version.
+// rtErr is the error from detecting the runtime, if any.
+func collectVersionInfo(ctx context.Context, rt runtime.Runtime, rtErr error) versionInfo {
+	info := versionInfo{
+		Version:  buildVersion,
+		Commit:   buildCommit,
+		Date:     buildDate,
+		KlausOCI: moduleVersion(klausOCIModule),
+		Runtime:  &runtimeVersionInfo{},
+	}
+
+	if rtErr != nil {
+		info.Runtime.Error = rtErr.Error()
+		return info
+	}
+
+	info.Runtime.Name = rt.Name()
+	v, err := rt.Version(ctx)
+	if err != nil {
+		info.Runtime.Error = err.Error()
+	} else {
+		info.Runtime.Version = v
+	}
+	return info
+}
+
+func printVersionInfo(out io.Writer, info versionInfo) {
+	_, _ = fmt.Fprintf(out, "klausctl %s\n", info.Version)
+	_, _ = fmt.Fprintf(out, "  commit:    %s\n", info.Commit)
+	_, _ = fmt.Fprintf(out, "  built:     %s\n", info.Date)
+	_, _ = fmt.Fprintf(out, "  klaus-oci: %s\n", info.KlausOCI)
+
+	rt := info.Runtime
+	switch {
+	case rt.Error != "" && rt.Name == "":
+		_, _ = fmt.Fprintf(out, "  runtime:   unavailable (%s)\n", rt.Error)
+	case rt.Error != "":
+		_, _ = fmt.Fprintf(out, "  runtime:   %s (version unknown: %s)\n", rt.Name, rt.Error)
+	default:
+		_, _ = fmt.Fprintf(out, "  runtime:   %s %s\n", rt.Name, rt.Version)
+	}
+}
+
+// moduleVersion returns the version of the given dependency module as
+// recorded in the binary's build info, or "unknown".
+func moduleVersion(path string) string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range bi.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version == "" {
+			return "unknown"
+		}
+		return dep.Version
+	}
+	return "unknown"
 }
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVersionOutputFlag(t *testing.T) {
+	assertFlagRegistered(t, versionCmd, "output")
+}
+
+func TestCollectVersionInfo(t *testing.T) {
+	rt := &mockRuntime{version: "27.1.1"}
+	info := collectVersionInfo(context.Background(), rt, nil)
+
+	if info.Version != buildVersion || info.Commit != buildCommit || info.Date != buildDate {
+		t.Errorf("build info = %q/%q/%q", info.Version, info.Commit, info.Date)
+	}
+	if info.KlausOCI == "" {
+		t.Error("expected klaus-oci version to be set")
+	}
+	if info.Runtime.Name != "mock" || info.Runtime.Version != "27.1.1" {
+		t.Errorf("Runtime = %+v", info.Runtime)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, info); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"version", "commit", "date", "klausOci", "runtime"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("JSON output missing %q key: %s", key, buf.String())
+		}
+	}
+}
+
+func TestCollectVersionInfoRuntimeErrors(t *testing.T) {
+	info := collectVersionInfo(context.Background(), nil, errors.New("no container runtime found"))
+	var buf bytes.Buffer
+	printVersionInfo(&buf, info)
+	if !strings.Contains(buf.String(), "runtime:   unavailable (no container runtime found)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	info = collectVersionInfo(context.Background(), &mockRuntime{versionErr: errors.New("exit 1")}, nil)
+	buf.Reset()
+	printVersionInfo(&buf, info)
+	if !strings.Contains(buf.String(), "runtime:   mock (version unknown: exit 1)") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/mcpclient"
+)
+
+var (
+	waitTimeout time.Duration
+	waitOutput  string
+)
+
+// waitPollInterval and waitMaxPollInterval bound the backoff between agent
+// status polls. Tests shorten them.
+var (
+	waitPollInterval    = 2 * time.Second
+	waitMaxPollInterval = 10 * time.Second
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [name]",
+	Short: "Wait for a klaus instance to finish its task",
+	Long: `Block until the agent of a running klaus instance is done with its current
+prompt, then print its result as 'klausctl result' does.
+
+The agent status is polled until it reports idle, completed, error or failed.
+If --timeout elapses first, wait exits non-zero. Use --timeout 0 to wait
+indefinitely.
+
+  klausctl create foo ~/src/foo && klausctl prompt foo -m "Fix the tests" \
+    && klausctl wait foo && klausctl logs foo
+  klausctl wait foo --timeout 5m -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWait,
+}
+
+func init() {
+	waitCmd.Flags().DurationVar(&waitTimeout, "timeout", 30*time.Minute, "maximum time to wait for the agent (0 waits indefinitely)")
+	waitCmd.Flags().StringVarP(&waitOutput, "output", "o", "text", "output format: text, json")
+	rootCmd.AddCommand(waitCmd)
+}
+
+func runWait(cmd *cobra.Command, args []string) error {
+	if err := validateOutputFormat(waitOutput); err != nil {
+		return err
+	}
+	if waitTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative, got %s", waitTimeout)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return err
+	}
+	if err := config.MigrateLayout(paths); err != nil {
+		return fmt.Errorf("migrating config layout: %w", err)
+	}
+
+	instanceName, err := resolveOptionalInstanceName(args, "wait", cmd.ErrOrStderr())
+	if err != nil {
+		return err
+	}
+	paths = paths.ForInstance(instanceName)
+
+	inst, err := instance.Load(paths)
+	if err != nil {
+		return fmt.Errorf("no klaus instance found for %q; run 'klausctl create %s <workspace>' first", instanceName, instanceName)
+	}
+
+	rt, err := inst.NewRuntime()
+	if err != nil {
+		return err
+	}
+
+	status, err := rt.Status(ctx, inst.ContainerName())
+	if err != nil {
+		return fmt.Errorf("instance %q: unable to determine status: %w", instanceName, err)
+	}
+	if status != "running" { //nolint:goconst
+		return fmt.Errorf("instance %q is not running (status: %s); run 'klausctl start %s' first", instanceName, status, instanceName)
+	}
+
+	baseURL := inst.AgentURL() + "/mcp"
+
+	client := mcpclient.New(buildVersion)
+	defer client.Close()
+
+	agentStatus := func(ctx context.Context) (string, error) {
+		res, err := client.Status(ctx, instanceName, baseURL)
+		if err != nil {
+			return "", err
+		}
+		return mcpclient.ParseStatusField(res), nil
+	}
+	if _, err := waitForAgent(ctx, agentStatus, waitTimeout); err != nil {
+		return fmt.Errorf("waiting for instance %q: %w", instanceName, err)
+	}
+	recordUsage(ctx, inst, paths)
+
+	toolResult, err := client.Result(ctx, instanceName, baseURL, false)
+	if err != nil {
+		return fmt.Errorf("fetching result from %q: %w", instanceName, err)
+	}
+	return renderResultOutput(cmd.OutOrStdout(), waitOutput, parseResultResponse(instanceName, toolResult))
+}
+
+// isAgentIdle reports whether an agent with the given status has no prompt
+// in progress.
+func isAgentIdle(status string) bool {
+	return status == "idle" || mcpclient.IsTerminalStatus(status)
+}
+
+// waitForAgent polls status with backoff until the agent is done and returns
+// its final status. Errors from status, e.g. while the agent is still coming
+// up, are retried. A timeout of 0 waits until ctx is cancelled.
+func waitForAgent(ctx context.Context, status func(context.Context) (string, error), timeout time.Duration) (string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	poll := waitPollInterval
+	var last string
+	var lastErr error
+	for {
+		s, err := status(ctx)
+		if err == nil && isAgentIdle(s) {
+			return s, nil
+		}
+		last, lastErr = s, err
+
+		select {
+		case <-ctx.Done():
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ctx.Err()
+			}
+			if lastErr != nil {
+				return "", fmt.Errorf("timed out after %s: %w", timeout, lastErr)
+			}
+			return "", fmt.Errorf("timed out after %s (agent status: %s)", timeout, last)
+		case <-time.After(poll):
+		}
+		poll = min(poll*2, waitMaxPollInterval)
+	}
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitFlags(t *testing.T) {
+	assertCommandOnRoot(t, "wait")
+	for _, flag := range []string{"timeout", "output"} {
+		assertFlagRegistered(t, waitCmd, flag)
+	}
+}
+
+// fastWaitPolling shortens the status poll backoff for the test.
+func fastWaitPolling(t *testing.T) {
+	t.Helper()
+	interval, maxInterval := waitPollInterval, waitMaxPollInterval
+	waitPollInterval, waitMaxPollInterval = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() {
+		waitPollInterval, waitMaxPollInterval = interval, maxInterval
+	})
+}
+
+func TestWaitForAgentPollsUntilDone(t *testing.T) {
+	fastWaitPolling(t)
+
+	tests := []struct {
+		name     string
+		statuses []string
+		want     string
+	}{
+		{name: "completes", statuses: []string{"busy", "busy", "completed"}, want: "completed"},
+		{name: "becomes idle", statuses: []string{"busy", "idle"}, want: "idle"},
+		{name: "fails", statuses: []string{"busy", "failed"}, want: "failed"},
+		{name: "already done", statuses: []string{"completed"}, want: "completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			status := func(context.Context) (string, error) {
+				s := tt.statuses[min(calls, len(tt.statuses)-1)]
+				calls++
+				return s, nil
+			}
+
+			got, err := waitForAgent(context.Background(), status, time.Second)
+			if err != nil {
+				t.Fatalf("waitForAgent() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("waitForAgent() = %q, want %q", got, tt.want)
+			}
+			if calls != len(tt.statuses) {
+				t.Errorf("status polled %d times, want %d", calls, len(tt.statuses))
+			}
+		})
+	}
+}
+
+func TestWaitForAgentRetriesStatusErrors(t *testing.T) {
+	fastWaitPolling(t)
+
+	calls := 0
+	status := func(context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", errors.New("connection refused")
+		}
+		return "completed", nil
+	}
+
+	got, err := waitForAgent(context.Background(), status, time.Second)
+	if err != nil || got != "completed" {
+		t.Fatalf("waitForAgent() = %q, %v; want completed after retries", got, err)
+	}
+}
+
+func TestWaitForAgentTimeout(t *testing.T) {
+	fastWaitPolling(t)
+
+	status := func(context.Context) (string, error) { return "busy", nil }
+
+	_, err := waitForAgent(context.Background(), status, 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "busy") {
+		t.Fatalf("waitForAgent() error = %v, want timeout mentioning the last status", err)
+	}
+}
+
+func TestWaitForAgentCancelled(t *testing.T) {
+	fastWaitPolling(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	status := func(context.Context) (string, error) {
+		cancel()
+		return "busy", nil
+	}
+
+	_, err := waitForAgent(ctx, status, 0)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("waitForAgent() error = %v, want context.Canceled", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
@@ -19,9 +20,17 @@ import (
 type ServerContext struct {
 	Paths     *config.Paths
 	MCPClient *mcpclient.Client
+	// Metrics collects the counters of the metrics endpoint; nil when the
+	// endpoint is disabled.
+	Metrics *Metrics
+	// Tracer traces instance lifecycle operations; nil disables tracing
+	// (see NewTracer).
+	Tracer trace.Tracer
 
 	mu           sync.RWMutex
 	sourceConfig *config.SourceConfig
+
+	lifecycle lifecycle
 }
 
 // InstancePaths returns config paths scoped to a named instance.
@@ -36,9 +45,14 @@ func (sc *ServerContext) LoadInstanceConfig(name string) (*config.Config, error)
 }
 
 // DetectRuntime creates a Runtime from the given config, auto-detecting
-// when the config runtime field is empty.
+// when the config runtime field is empty. Its calls are traced when tracing
+// is enabled.
 func (sc *ServerContext) DetectRuntime(cfg *config.Config) (runtime.Runtime, error) {
-	return runtime.New(cfg.Runtime)
+	rt, err := runtime.NewWithHost(cfg.Runtime, cfg.RuntimeHost)
+	if err != nil {
+		return nil, err
+	}
+	return sc.TraceRuntime(rt), nil
 }
 
 // SetSourceConfig sets the loaded source configuration.
@@ -81,7 +95,7 @@ func (sc *ServerContext) SourceResolver() *config.SourceResolver {
 	if sc.sourceConfig == nil {
 		return config.DefaultSourceResolver()
 	}
-	return config.NewSourceResolver(sc.sourceConfig.Sources)
+	return sc.sourceConfig.Resolver()
 }
 
 // JSONResult serializes v as indented JSON and returns it as an MCP text result.
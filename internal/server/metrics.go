@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+
+	"github.com/giantswarm/klausctl/pkg/instance"
+)
+
+// MetricsPath is the HTTP path the metrics endpoint is served on.
+const MetricsPath = "/metrics"
+
+// instanceStatusTimeout bounds the container status lookups of a scrape.
+const instanceStatusTimeout = 10 * time.Second
+
+// Metrics collects the counters exposed by the MCP server's metrics
+// endpoint in the Prometheus text format. A nil *Metrics records nothing,
+// so handlers can record unconditionally.
+type Metrics struct {
+	mu        sync.Mutex
+	toolCalls map[string]uint64
+	pulls     map[string]*durationSummary
+}
+
+// durationSummary is the count and total of observed durations.
+type durationSummary struct {
+	count uint64
+	sum   time.Duration
+}
+
+// NewMetrics returns an empty metrics collector.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		toolCalls: make(map[string]uint64),
+		pulls:     make(map[string]*durationSummary),
+	}
+}
+
+// IncToolCall counts a call of the named tool.
+func (m *Metrics) IncToolCall(tool string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.toolCalls[tool]++
+}
+
+// ObservePull records how long a pull of the given kind ("image" or
+// "plugins") took.
+func (m *Metrics) ObservePull(kind string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.pulls[kind]
+	if !ok {
+		s = &durationSummary{}
+		m.pulls[kind] = s
+	}
+	s.count++
+	s.sum += d
+}
+
+// ToolMiddleware counts every tool call before passing it to next.
+func (m *Metrics) ToolMiddleware(next mcpserver.ToolHandlerFunc) mcpserver.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		m.IncToolCall(req.Params.Name)
+		return next(ctx, req)
+	}
+}
+
+// write prints the metrics and the given instance counts by status in the
+// Prometheus text exposition format.
+func (m *Metrics) write(w io.Writer, instances map[string]int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP klausctl_mcp_tool_calls_total Number of MCP tool calls by tool.\n")
+	b.WriteString("# TYPE klausctl_mcp_tool_calls_total counter\n")
+	for _, tool := range sortedKeys(m.toolCalls) {
+		fmt.Fprintf(&b, "klausctl_mcp_tool_calls_total{tool=%q} %d\n", tool, m.toolCalls[tool])
+	}
+
+	b.WriteString("# HELP klausctl_instances Number of klaus instances by container status.\n")
+	b.WriteString("# TYPE klausctl_instances gauge\n")
+	for _, status := range sortedKeys(instances) {
+		fmt.Fprintf(&b, "klausctl_instances{status=%q} %d\n", status, instances[status])
+	}
+
+	b.WriteString("# HELP klausctl_pull_duration_seconds Duration of image and plugin pulls before an instance starts.\n")
+	b.WriteString("# TYPE klausctl_pull_duration_seconds summary\n")
+	for _, kind := range sortedKeys(m.pulls) {
+		s := m.pulls[kind]
+		fmt.Fprintf(&b, "klausctl_pull_duration_seconds_sum{kind=%q} %g\n", kind, s.sum.Seconds())
+		fmt.Fprintf(&b, "klausctl_pull_duration_seconds_count{kind=%q} %d\n", kind, s.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// MetricsHandler serves sc.Metrics together with the current instance
+// counts by container status. Instances whose container does not exist
+// are counted as "missing", those whose runtime cannot be queried as
+// "unknown".
+func (sc *ServerContext) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instances, err := sc.instanceStatusCounts(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		metrics := sc.Metrics
+		if metrics == nil {
+			metrics = NewMetrics()
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = metrics.write(w, instances)
+	})
+}
+
+// instanceStatusCounts counts the instances by the status of their
+// container.
+func (sc *ServerContext) instanceStatusCounts(ctx context.Context) (map[string]int, error) {
+	instances, err := instance.LoadAll(sc.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("loading instances: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, instanceStatusTimeout)
+	defer cancel()
+
+	counts := make(map[string]int)
+	for _, inst := range instances {
+		status := "unknown"
+		if rt, err := inst.NewRuntime(); err == nil {
+			if s, err := rt.Status(ctx, inst.ContainerName()); err == nil {
+				status = s
+				if status == "" {
+					status = "missing"
+				}
+			}
+		}
+		counts[status]++
+	}
+	return counts, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestMetricsHandlerServesMetricNames(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(t.TempDir(), "config-home"))
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := &ServerContext{Paths: paths, Metrics: NewMetrics()}
+
+	handler := sc.Metrics.ToolMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	var req mcp.CallToolRequest
+	req.Params.Name = "klaus_list"
+	for range 2 {
+		if _, err := handler(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sc.Metrics.ObservePull("image", 1500*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	sc.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE klausctl_mcp_tool_calls_total counter",
+		`klausctl_mcp_tool_calls_total{tool="klaus_list"} 2`,
+		"# TYPE klausctl_instances gauge",
+		"# TYPE klausctl_pull_duration_seconds summary",
+		`klausctl_pull_duration_seconds_sum{kind="image"} 1.5`,
+		`klausctl_pull_duration_seconds_count{kind="image"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestNilMetricsRecordsNothing(t *testing.T) {
+	var m *Metrics
+	m.IncToolCall("klaus_list")
+	m.ObservePull("image", time.Second)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Page is the paginated envelope of a list result.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextOffset is the offset of the next page; absent on the last page.
+	NextOffset int `json:"nextOffset,omitempty"`
+	// Total is the number of items across all pages.
+	Total int `json:"total"`
+}
+
+// PageRequest is the window of a list result a tool call asked for.
+type PageRequest struct {
+	// Limit is the maximum number of items to return; 0 returns all
+	// remaining items.
+	Limit  int
+	Offset int
+	// Paginated is set when the call passed limit or offset. Only then is
+	// the result wrapped in a Page, so clients that do not paginate keep
+	// getting a plain JSON array.
+	Paginated bool
+}
+
+// WithPagination adds the optional limit and offset arguments read by
+// PageRequestFrom to a list tool.
+func WithPagination() mcp.ToolOption {
+	limit := mcp.WithNumber("limit", mcp.Description("Maximum number of items to return; the result is then wrapped in {items, nextOffset, total} (default: all)"))
+	offset := mcp.WithNumber("offset", mcp.Description("Number of items to skip, e.g. the nextOffset of the previous page (default: 0)"))
+	return func(t *mcp.Tool) {
+		limit(t)
+		offset(t)
+	}
+}
+
+// PageRequestFrom reads the limit and offset arguments of req.
+func PageRequestFrom(req mcp.CallToolRequest) (PageRequest, error) {
+	args := req.GetArguments()
+	_, hasLimit := args["limit"]
+	_, hasOffset := args["offset"]
+
+	p := PageRequest{
+		Limit:     int(req.GetFloat("limit", 0)),
+		Offset:    int(req.GetFloat("offset", 0)),
+		Paginated: hasLimit || hasOffset,
+	}
+	if p.Limit < 0 {
+		return PageRequest{}, fmt.Errorf("limit must be >= 0, got %d", p.Limit)
+	}
+	if p.Offset < 0 {
+		return PageRequest{}, fmt.Errorf("offset must be >= 0, got %d", p.Offset)
+	}
+	return p, nil
+}
+
+// Paginate returns the window of items selected by p. An offset past the end
+// yields an empty page.
+func Paginate[T any](items []T, p PageRequest) Page[T] {
+	total := len(items)
+	start := min(p.Offset, total)
+	end := total
+	if p.Limit > 0 {
+		end = min(start+p.Limit, total)
+	}
+
+	page := Page[T]{Items: items[start:end], Total: total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if end < total {
+		page.NextOffset = end
+	}
+	return page
+}
+
+// PaginatedJSONResult is JSONResult for list tools: it returns items as a
+// JSON array, or the Page selected by p when the call asked for pagination.
+func PaginatedJSONResult[T any](items []T, p PageRequest) (*mcp.CallToolResult, error) {
+	if !p.Paginated {
+		return JSONResult(items)
+	}
+	return JSONResult(Paginate(items, p))
+}
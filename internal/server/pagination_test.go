@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4}
+
+	tests := []struct {
+		name       string
+		limit      int
+		offset     int
+		want       []int
+		nextOffset int
+	}{
+		{name: "first page", limit: 2, want: []int{0, 1}, nextOffset: 2},
+		{name: "middle page", limit: 2, offset: 2, want: []int{2, 3}, nextOffset: 4},
+		{name: "last page", limit: 2, offset: 4, want: []int{4}},
+		{name: "exact last page", limit: 5, want: []int{0, 1, 2, 3, 4}},
+		{name: "no limit", offset: 3, want: []int{3, 4}},
+		{name: "offset past end", limit: 2, offset: 7, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := Paginate(items, PageRequest{Limit: tt.limit, Offset: tt.offset, Paginated: true})
+			if !slices.Equal(page.Items, tt.want) {
+				t.Errorf("Items = %v, want %v", page.Items, tt.want)
+			}
+			if page.NextOffset != tt.nextOffset {
+				t.Errorf("NextOffset = %d, want %d", page.NextOffset, tt.nextOffset)
+			}
+			if page.Total != len(items) {
+				t.Errorf("Total = %d, want %d", page.Total, len(items))
+			}
+		})
+	}
+}
+
+func TestPaginateEmptyItemsMarshalAsArray(t *testing.T) {
+	data, err := json.Marshal(Paginate[string](nil, PageRequest{Limit: 10, Paginated: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"items":[],"total":0}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestPageRequestFrom(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]any
+		want    PageRequest
+		wantErr bool
+	}{
+		{name: "no arguments", args: nil, want: PageRequest{}},
+		{name: "limit", args: map[string]any{"limit": float64(10)}, want: PageRequest{Limit: 10, Paginated: true}},
+		{name: "offset only", args: map[string]any{"offset": float64(20)}, want: PageRequest{Offset: 20, Paginated: true}},
+		{name: "negative limit", args: map[string]any{"limit": float64(-1)}, wantErr: true},
+		{name: "negative offset", args: map[string]any{"offset": float64(-5)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: tt.args}}
+			got, err := PageRequestFrom(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PageRequestFrom() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PageRequestFrom() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
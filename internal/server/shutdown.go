@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// lifecycle tracks the instances started by this server and the hooks run
+// when it shuts down.
+type lifecycle struct {
+	mu      sync.Mutex
+	started map[string]bool
+	hooks   []func(context.Context) error
+}
+
+// TrackInstance records that the named instance was started by this
+// server, so that shutdown hooks can stop it.
+func (sc *ServerContext) TrackInstance(name string) {
+	sc.lifecycle.mu.Lock()
+	defer sc.lifecycle.mu.Unlock()
+	if sc.lifecycle.started == nil {
+		sc.lifecycle.started = map[string]bool{}
+	}
+	sc.lifecycle.started[name] = true
+}
+
+// UntrackInstance forgets the named instance, e.g. once it was stopped or
+// deleted through a tool.
+func (sc *ServerContext) UntrackInstance(name string) {
+	sc.lifecycle.mu.Lock()
+	defer sc.lifecycle.mu.Unlock()
+	delete(sc.lifecycle.started, name)
+}
+
+// TrackedInstances returns the names of the instances started by this
+// server that are still tracked, sorted.
+func (sc *ServerContext) TrackedInstances() []string {
+	sc.lifecycle.mu.Lock()
+	defer sc.lifecycle.mu.Unlock()
+	names := make([]string, 0, len(sc.lifecycle.started))
+	for name := range sc.lifecycle.started {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// OnShutdown registers hook to run by Shutdown.
+func (sc *ServerContext) OnShutdown(hook func(context.Context) error) {
+	sc.lifecycle.mu.Lock()
+	defer sc.lifecycle.mu.Unlock()
+	sc.lifecycle.hooks = append(sc.lifecycle.hooks, hook)
+}
+
+// Shutdown runs the registered hooks in reverse order of registration.
+// All hooks run even when one fails; their errors are joined.
+func (sc *ServerContext) Shutdown(ctx context.Context) error {
+	sc.lifecycle.mu.Lock()
+	hooks := slices.Clone(sc.lifecycle.hooks)
+	sc.lifecycle.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTrackInstance(t *testing.T) {
+	sc := &ServerContext{}
+	sc.TrackInstance("b")
+	sc.TrackInstance("a")
+	sc.TrackInstance("b")
+	if got := sc.TrackedInstances(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("TrackedInstances() = %v, want [a b]", got)
+	}
+
+	sc.UntrackInstance("a")
+	if got := sc.TrackedInstances(); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("TrackedInstances() after untrack = %v, want [b]", got)
+	}
+}
+
+func TestShutdownRunsHooksInReverseOrder(t *testing.T) {
+	sc := &ServerContext{}
+	var order []string
+	sc.OnShutdown(func(context.Context) error {
+		order = append(order, "first")
+		return errors.New("first failed")
+	})
+	sc.OnShutdown(func(context.Context) error {
+		order = append(order, "second")
+		return nil
+	})
+
+	err := sc.Shutdown(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "first failed") {
+		t.Errorf("Shutdown() error = %v, want the failing hook's error", err)
+	}
+	if !slices.Equal(order, []string{"second", "first"}) {
+		t.Errorf("hooks ran in order %v, want [second first]", order)
+	}
+}
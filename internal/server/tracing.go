@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// EnvOTLPEndpoint is the standard OpenTelemetry env var naming the OTLP
+// collector endpoint. Tracing is enabled only when it is set.
+const EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+
+// TracerName is the instrumentation name of the MCP server's spans.
+const TracerName = "github.com/giantswarm/klausctl"
+
+// NewTracer returns a tracer exporting spans over OTLP/HTTP to
+// OTEL_EXPORTER_OTLP_ENDPOINT, and a function that flushes and shuts down
+// the export. Without the env var the tracer is nil, which disables
+// tracing. The exporter reads the other OTEL_EXPORTER_OTLP_* variables
+// itself.
+func NewTracer(ctx context.Context) (trace.Tracer, func(context.Context) error, error) {
+	if os.Getenv(EnvOTLPEndpoint) == "" {
+		return nil, func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	return tp.Tracer(TracerName), tp.Shutdown, nil
+}
+
+// StartSpan starts a span named name with sc.Tracer. Without a tracer the
+// span is a no-op, so handlers can trace unconditionally.
+func (sc *ServerContext) StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := sc.Tracer
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer(TracerName)
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records err, if any, on span and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceRuntime wraps rt so that its container lifecycle and image calls
+// are traced as children of the span in their context. Without a tracer rt
+// is returned as is.
+func (sc *ServerContext) TraceRuntime(rt runtime.Runtime) runtime.Runtime {
+	if sc.Tracer == nil {
+		return rt
+	}
+	return &tracedRuntime{Runtime: rt, sc: sc}
+}
+
+// tracedRuntime traces the calls of the embedded runtime made while
+// creating, starting and stopping instances. Other calls pass through.
+type tracedRuntime struct {
+	runtime.Runtime
+	sc *ServerContext
+}
+
+func (r *tracedRuntime) start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("runtime.name", r.Name()))
+	return r.sc.StartSpan(ctx, "runtime."+op, attrs...)
+}
+
+func (r *tracedRuntime) Run(ctx context.Context, opts runtime.RunOptions) (_ string, err error) {
+	ctx, span := r.start(ctx, "run", attribute.String("container.name", opts.Name), attribute.String("container.image", opts.Image))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Run(ctx, opts)
+}
+
+func (r *tracedRuntime) Stop(ctx context.Context, name string, timeout time.Duration) (err error) {
+	ctx, span := r.start(ctx, "stop", attribute.String("container.name", name))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Stop(ctx, name, timeout)
+}
+
+func (r *tracedRuntime) Start(ctx context.Context, name string) (err error) {
+	ctx, span := r.start(ctx, "start", attribute.String("container.name", name))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Start(ctx, name)
+}
+
+func (r *tracedRuntime) Remove(ctx context.Context, name string) (err error) {
+	ctx, span := r.start(ctx, "remove", attribute.String("container.name", name))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Remove(ctx, name)
+}
+
+func (r *tracedRuntime) Status(ctx context.Context, name string) (_ string, err error) {
+	ctx, span := r.start(ctx, "status", attribute.String("container.name", name))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Status(ctx, name)
+}
+
+func (r *tracedRuntime) Inspect(ctx context.Context, name string) (_ *runtime.ContainerInfo, err error) {
+	ctx, span := r.start(ctx, "inspect", attribute.String("container.name", name))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Inspect(ctx, name)
+}
+
+func (r *tracedRuntime) Pull(ctx context.Context, image string, opts runtime.PullOptions, w io.Writer) (err error) {
+	ctx, span := r.start(ctx, "pull", attribute.String("container.image", image))
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Pull(ctx, image, opts, w)
+}
+
+func (r *tracedRuntime) Images(ctx context.Context, filter string) (_ []runtime.ImageInfo, err error) {
+	ctx, span := r.start(ctx, "images")
+	defer func() { EndSpan(span, err) }()
+	return r.Runtime.Images(ctx, filter)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// stubRuntime is a runtime whose Run fails.
+type stubRuntime struct {
+	runtime.Runtime
+}
+
+func (stubRuntime) Name() string { return "docker" }
+
+func (stubRuntime) Run(_ context.Context, _ runtime.RunOptions) (string, error) {
+	return "", errors.New("no space left")
+}
+
+func TestTraceRuntimeRecordsChildSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	sc := &ServerContext{Tracer: tp.Tracer(TracerName)}
+
+	ctx, span := sc.StartSpan(context.Background(), "instance.start")
+	rt := sc.TraceRuntime(stubRuntime{})
+	_, err := rt.Run(ctx, runtime.RunOptions{Name: "klausctl-dev", Image: "klaus:v1"})
+	EndSpan(span, err)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	run := spans[0]
+	if run.Name != "runtime.run" {
+		t.Errorf("first span = %q, want runtime.run", run.Name)
+	}
+	if run.Parent.SpanID() != spans[1].SpanContext.SpanID() {
+		t.Error("runtime.run is not a child of instance.start")
+	}
+	if len(run.Events) == 0 {
+		t.Error("runtime.run did not record the error")
+	}
+}
+
+func TestTracingDisabledWithoutTracer(t *testing.T) {
+	sc := &ServerContext{}
+	rt := stubRuntime{}
+	if got := sc.TraceRuntime(rt); got != runtime.Runtime(rt) {
+		t.Error("TraceRuntime() wrapped the runtime without a tracer")
+	}
+	_, span := sc.StartSpan(context.Background(), "instance.create")
+	if span.IsRecording() {
+		t.Error("span is recording without a tracer")
+	}
+	EndSpan(span, errors.New("ignored"))
+
+	t.Setenv(EnvOTLPEndpoint, "")
+	tracer, shutdown, err := NewTracer(context.Background())
+	if err != nil || tracer != nil {
+		t.Errorf("NewTracer() = %v, %v, want nil tracer", tracer, err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+}
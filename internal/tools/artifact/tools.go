@@ -42,6 +42,7 @@ func RegisterTools(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	registerSourceAdd(s, sc)
 	registerSourceUpdate(s, sc)
 	registerSourceRemove(s, sc)
+	registerSourceRename(s, sc)
 	registerSourceSetDefault(s, sc)
 }
 
@@ -51,6 +52,7 @@ func registerToolchainList(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithBoolean("remote", mcp.Description("List from remote registry instead of local cache (default: false)")),
 		mcp.WithString("source", mcp.Description("Filter to a specific source name")),
 		mcp.WithBoolean("all", mcp.Description("List from all configured sources (default: default source only)")),
+		server.WithPagination(),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleToolchainList(ctx, req, sc)
@@ -63,6 +65,7 @@ func registerPersonalityList(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithBoolean("remote", mcp.Description("List from remote registry instead of local cache (default: false)")),
 		mcp.WithString("source", mcp.Description("Filter to a specific source name")),
 		mcp.WithBoolean("all", mcp.Description("List from all configured sources (default: default source only)")),
+		server.WithPagination(),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handlePersonalityList(ctx, req, sc)
@@ -75,6 +78,7 @@ func registerPluginList(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithBoolean("remote", mcp.Description("List from remote registry instead of local cache (default: false)")),
 		mcp.WithString("source", mcp.Description("Filter to a specific source name")),
 		mcp.WithBoolean("all", mcp.Description("List from all configured sources (default: default source only)")),
+		server.WithPagination(),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handlePluginList(ctx, req, sc)
@@ -114,15 +118,19 @@ func handleToolchainList(ctx context.Context, req mcp.CallToolRequest, sc *serve
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	page, err := server.PageRequestFrom(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	if remote {
-		return toolchainListRemote(ctx, resolver)
+		return toolchainListRemote(ctx, resolver, page)
 	}
 
-	return toolchainListLocal(ctx, sc, resolver)
+	return toolchainListLocal(ctx, sc, resolver, page)
 }
 
-func toolchainListLocal(ctx context.Context, sc *server.ServerContext, resolver *config.SourceResolver) (*mcp.CallToolResult, error) {
+func toolchainListLocal(ctx context.Context, sc *server.ServerContext, resolver *config.SourceResolver, page server.PageRequest) (*mcp.CallToolResult, error) {
 	rt, err := sc.DetectRuntime(&config.Config{})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("detecting runtime: %v", err)), nil
@@ -148,16 +156,23 @@ func toolchainListLocal(ctx context.Context, sc *server.ServerContext, resolver
 			}
 		}
 	}
+	// The runtime lists images in no particular order; pages need a stable one.
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Tag < entries[j].Tag
+	})
 
-	return server.JSONResult(entries)
+	return server.PaginatedJSONResult(entries, page)
 }
 
-func toolchainListRemote(ctx context.Context, resolver *config.SourceResolver) (*mcp.CallToolResult, error) {
+func toolchainListRemote(ctx context.Context, resolver *config.SourceResolver, page server.PageRequest) (*mcp.CallToolResult, error) {
 	entries, err := listRemoteFromRegistries(ctx, resolver.ToolchainRegistries(), "toolchains", listToolchainsFn)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	return server.JSONResult(entries)
+	return server.PaginatedJSONResult(entries, page)
 }
 
 func handlePersonalityList(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
@@ -167,19 +182,24 @@ func handlePersonalityList(ctx context.Context, req mcp.CallToolRequest, sc *ser
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	page, err := server.PageRequestFrom(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if remote {
 		entries, err := listRemoteFromRegistries(ctx, resolver.PersonalityRegistries(), "personalities", listPersonalitiesFn)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
-		return server.JSONResult(entries)
+		return server.PaginatedJSONResult(entries, page)
 	}
 
 	artifacts, err := listLocalArtifacts(sc.Paths.PersonalitiesDir)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("listing local personalities: %v", err)), nil
 	}
-	return server.JSONResult(artifacts)
+	return server.PaginatedJSONResult(artifacts, page)
 }
 
 func handlePluginList(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
@@ -189,19 +209,24 @@ func handlePluginList(ctx context.Context, req mcp.CallToolRequest, sc *server.S
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	page, err := server.PageRequestFrom(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	if remote {
 		entries, err := listRemoteFromRegistries(ctx, resolver.PluginRegistries(), "plugins", listPluginsFn)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
-		return server.JSONResult(entries)
+		return server.PaginatedJSONResult(entries, page)
 	}
 
 	artifacts, err := listLocalArtifacts(sc.Paths.PluginsDir)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("listing local plugins: %v", err)), nil
 	}
-	return server.JSONResult(artifacts)
+	return server.PaginatedJSONResult(artifacts, page)
 }
 
 // --- Describe tools ---
@@ -228,7 +253,10 @@ func handlePluginDescribe(ctx context.Context, req mcp.CallToolRequest, sc *serv
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	resolved := resolver.ResolvePluginRef(ref)
+	resolved, err := resolver.ResolvePluginRef(ref)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	client := orchestrator.NewDefaultClient()
 	dp, err := client.DescribePlugin(ctx, resolved)
 	if err != nil {
@@ -244,6 +272,7 @@ func registerPersonalityDescribe(s *mcpserver.MCPServer, sc *server.ServerContex
 		mcp.WithString("ref", mcp.Required(), mcp.Description("Personality reference: short name, name:tag, or full OCI reference")),
 		mcp.WithString("source", mcp.Description("Resolve against a specific source")),
 		mcp.WithBoolean("deps", mcp.Description("Resolve and include dependency metadata in the response (default: false)")),
+		mcp.WithNumber("resolveDepth", mcp.Description("How deep to resolve dependencies when deps is set: 0 none, 1 direct, 2 transitive through extended base personalities (default: 2)")),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handlePersonalityDescribe(ctx, req, sc)
@@ -266,7 +295,10 @@ func handlePersonalityDescribe(ctx context.Context, req mcp.CallToolRequest, sc
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	resolved := resolver.ResolvePersonalityRef(ref)
+	resolved, err := resolver.ResolvePersonalityRef(ref)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	client := orchestrator.NewDefaultClient()
 	dp, err := client.DescribePersonality(ctx, resolved)
 	if err != nil {
@@ -274,7 +306,8 @@ func handlePersonalityDescribe(ctx context.Context, req mcp.CallToolRequest, sc
 	}
 
 	if req.GetBool("deps", false) {
-		deps, err := client.ResolvePersonalityDeps(ctx, dp.Personality)
+		depth := req.GetInt("resolveDepth", orchestrator.ResolveDepthTransitive)
+		deps, err := orchestrator.ResolvePersonalityDeps(ctx, orchestrator.NewPersonalityDepResolver(client, resolver), dp.Ref, dp.Personality, depth)
 		if err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("resolving dependencies: %v", err)), nil
 		}
@@ -309,7 +342,10 @@ func handleToolchainDescribe(ctx context.Context, req mcp.CallToolRequest, sc *s
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	resolved := resolver.ResolveToolchainRef(ref)
+	resolved, err := resolver.ResolveToolchainRef(ref)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 	client := orchestrator.NewDefaultClient()
 	dt, err := client.DescribeToolchain(ctx, resolved)
 	if err != nil {
@@ -349,10 +385,14 @@ func listLocalArtifacts(cacheDir string) ([]cachedArtifact, error) {
 
 	var artifacts []cachedArtifact
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if entry.Name() == orchestrator.BlobsDirName {
 			continue
 		}
+		// Plugin entries are symlinks into the shared blob store.
 		dir := filepath.Join(cacheDir, entry.Name())
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
 		cache, err := klausoci.ReadCacheEntry(dir)
 		if err != nil {
 			continue
@@ -393,12 +433,20 @@ var listPluginsFn listFn = func(ctx context.Context, client *klausoci.Client, op
 
 // listRemoteFromRegistries aggregates remote artifacts from multiple source registries.
 // When querying multiple sources, failures on individual sources are collected
-// rather than aborting the entire operation.
+// rather than aborting the entire operation. The entries are sorted by name
+// across sources, so that pages of the result do not jump between sources;
+// entries of the same name keep the source order.
 func listRemoteFromRegistries(ctx context.Context, registries []config.SourceRegistry, artifactType string, list listFn) ([]remoteArtifactEntry, error) {
 	entries, _, err := config.AggregateFromSources(registries, artifactType, func(sr config.SourceRegistry) ([]remoteArtifactEntry, error) {
 		return listLatestRemote(ctx, sr.Registry, list)
 	})
-	return entries, err
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries, nil
 }
 
 // listLatestRemote discovers repositories from the registry, resolves the
@@ -450,6 +498,8 @@ func registerMcpServerAdd(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithString("name", mcp.Required(), mcp.Description("Server name")),
 		mcp.WithString("url", mcp.Required(), mcp.Description("MCP server URL")),
 		mcp.WithString("secret", mcp.Description("Secret name for Bearer token authentication")),
+		mcp.WithString("headerName", mcp.Description("Header the secret is sent in (default: Authorization)")),
+		mcp.WithString("headerFormat", mcp.Description("Header value with %s for the secret (default: \"Bearer %s\")")),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleMcpServerAdd(ctx, req, sc)
@@ -465,17 +515,25 @@ func handleMcpServerAdd(_ context.Context, req mcp.CallToolRequest, sc *server.S
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
-	secretName := req.GetString("secret", "")
+	def := mcpserverstore.McpServerDef{
+		URL:          url,
+		Secret:       req.GetString("secret", ""),
+		HeaderName:   req.GetString("headerName", ""),
+		HeaderFormat: req.GetString("headerFormat", ""),
+	}
+	if err := def.Validate(); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if def.Secret == "" && (def.HeaderName != "" || def.HeaderFormat != "") {
+		return mcp.NewToolResultError("headerName and headerFormat require secret"), nil
+	}
 
 	store, err := mcpserverstore.Load(sc.Paths.McpServersFile)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("loading MCP servers: %v", err)), nil
 	}
 
-	store.Add(name, mcpserverstore.McpServerDef{
-		URL:    url,
-		Secret: secretName,
-	})
+	store.Add(name, def)
 
 	if err := store.Save(); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("saving MCP servers: %v", err)), nil
@@ -497,9 +555,11 @@ func registerMcpServerList(s *mcpserver.MCPServer, sc *server.ServerContext) {
 }
 
 type mcpServerEntry struct {
-	Name   string `json:"name"`
-	URL    string `json:"url"`
-	Secret string `json:"secret,omitempty"`
+	Name         string `json:"name"`
+	URL          string `json:"url"`
+	Secret       string `json:"secret,omitempty"`
+	HeaderName   string `json:"headerName,omitempty"`
+	HeaderFormat string `json:"headerFormat,omitempty"`
 }
 
 func handleMcpServerList(_ context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
@@ -514,9 +574,11 @@ func handleMcpServerList(_ context.Context, _ mcp.CallToolRequest, sc *server.Se
 	for _, name := range names {
 		def := all[name]
 		entries = append(entries, mcpServerEntry{
-			Name:   name,
-			URL:    def.URL,
-			Secret: def.Secret,
+			Name:         name,
+			URL:          def.URL,
+			Secret:       def.Secret,
+			HeaderName:   def.HeaderName,
+			HeaderFormat: def.HeaderFormat,
 		})
 	}
 
@@ -716,6 +778,7 @@ func registerSourceAdd(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithString("personalities", mcp.Description("Override personality registry path")),
 		mcp.WithString("plugins", mcp.Description("Override plugin registry path")),
 		mcp.WithBoolean("default", mcp.Description("Set as the default source")),
+		mcp.WithBoolean("confirm", mcp.Description("Confirm demoting the current default source; required with default: true when another source is the default")),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleSourceAdd(ctx, req, sc)
@@ -732,10 +795,11 @@ func handleSourceAdd(_ context.Context, req mcp.CallToolRequest, sc *server.Serv
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	cfg, err := config.LoadSourceConfig(sc.Paths.SourcesFile)
+	cfg, err := config.LockSourceConfig(sc.Paths.SourcesFile)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("loading sources: %v", err)), nil
 	}
+	defer func() { _ = cfg.Unlock() }()
 
 	src := config.Source{
 		Name:          name,
@@ -749,16 +813,20 @@ func handleSourceAdd(_ context.Context, req mcp.CallToolRequest, sc *server.Serv
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	var demoted *config.Source
 	if req.GetBool("default", false) {
+		if prev := cfg.Default(); prev != nil {
+			p := *prev
+			demoted = &p
+			if !req.GetBool("confirm", false) {
+				return mcp.NewToolResultError(fmt.Sprintf("source %q is currently the default and would be demoted; set confirm: true to proceed", p.Name)), nil
+			}
+		}
 		if err := cfg.SetDefault(name); err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
 	}
 
-	if err := config.EnsureDir(sc.Paths.ConfigDir); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("creating config directory: %v", err)), nil
-	}
-
 	if err := cfg.SaveTo(sc.Paths.SourcesFile); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("saving sources: %v", err)), nil
 	}
@@ -767,10 +835,17 @@ func handleSourceAdd(_ context.Context, req mcp.CallToolRequest, sc *server.Serv
 		return mcp.NewToolResultError(fmt.Sprintf("reloading sources: %v", err)), nil
 	}
 
-	return server.JSONResult(map[string]string{
+	result := map[string]string{
 		"name":   name,
 		"status": "added",
-	})
+	}
+	if demoted != nil {
+		result["demoted"] = demoted.Name
+		if demoted.Name == config.DefaultSourceName {
+			result["warning"] = fmt.Sprintf("short names now resolve against %s instead of the built-in %s registry (%s)", registry, config.DefaultSourceName, demoted.Registry)
+		}
+	}
+	return server.JSONResult(result)
 }
 
 func registerSourceUpdate(s *mcpserver.MCPServer, sc *server.ServerContext) {
@@ -793,10 +868,11 @@ func handleSourceUpdate(_ context.Context, req mcp.CallToolRequest, sc *server.S
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	cfg, err := config.LoadSourceConfig(sc.Paths.SourcesFile)
+	cfg, err := config.LockSourceConfig(sc.Paths.SourcesFile)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("loading sources: %v", err)), nil
 	}
+	defer func() { _ = cfg.Unlock() }()
 
 	patch := config.Source{
 		Registry:      req.GetString("registry", ""),
@@ -839,10 +915,11 @@ func handleSourceRemove(_ context.Context, req mcp.CallToolRequest, sc *server.S
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	cfg, err := config.LoadSourceConfig(sc.Paths.SourcesFile)
+	cfg, err := config.LockSourceConfig(sc.Paths.SourcesFile)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("loading sources: %v", err)), nil
 	}
+	defer func() { _ = cfg.Unlock() }()
 
 	if err := cfg.Remove(name); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -862,6 +939,52 @@ func handleSourceRemove(_ context.Context, req mcp.CallToolRequest, sc *server.S
 	})
 }
 
+func registerSourceRename(s *mcpserver.MCPServer, sc *server.ServerContext) {
+	tool := mcp.NewTool("klaus_source_rename",
+		mcp.WithDescription("Rename an artifact source, keeping its registry, overrides and default status (the built-in giantswarm source cannot be renamed)"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Current source name")),
+		mcp.WithString("newName", mcp.Required(), mcp.Description("New source name")),
+	)
+	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleSourceRename(ctx, req, sc)
+	})
+}
+
+func handleSourceRename(_ context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	name, err := req.RequireString("name")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	newName, err := req.RequireString("newName")
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cfg, err := config.LockSourceConfig(sc.Paths.SourcesFile)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("loading sources: %v", err)), nil
+	}
+	defer func() { _ = cfg.Unlock() }()
+
+	if err := cfg.Rename(name, newName); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := cfg.SaveTo(sc.Paths.SourcesFile); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("saving sources: %v", err)), nil
+	}
+
+	if err := sc.ReloadSourceConfig(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("reloading sources: %v", err)), nil
+	}
+
+	return server.JSONResult(map[string]string{
+		"name":    newName,
+		"oldName": name,
+		"status":  "renamed",
+	})
+}
+
 func registerSourceSetDefault(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	tool := mcp.NewTool("klaus_source_set_default",
 		mcp.WithDescription("Set a source as the default for short-name resolution"),
@@ -878,10 +1001,11 @@ func handleSourceSetDefault(_ context.Context, req mcp.CallToolRequest, sc *serv
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	cfg, err := config.LoadSourceConfig(sc.Paths.SourcesFile)
+	cfg, err := config.LockSourceConfig(sc.Paths.SourcesFile)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("loading sources: %v", err)), nil
 	}
+	defer func() { _ = cfg.Unlock() }()
 
 	if err := cfg.SetDefault(name); err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
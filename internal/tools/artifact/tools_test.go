@@ -1,11 +1,15 @@
 package artifact
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	klausoci "github.com/giantswarm/klaus-oci"
+	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
 
 	"github.com/giantswarm/klausctl/internal/server"
@@ -134,3 +138,139 @@ func TestListLocalArtifacts_SkipsDirsWithoutCache(t *testing.T) {
 		t.Errorf("expected empty list (no cache entries), got %d artifacts", len(artifacts))
 	}
 }
+
+func sourceAddRequest(args map[string]any) mcp.CallToolRequest {
+	return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}}
+}
+
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	if result == nil || len(result.Content) == 0 {
+		t.Fatal("result has no content")
+	}
+	text, ok := result.Content[0].(mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected TextContent, got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+func TestHandleSourceAddDefaultRequiresConfirm(t *testing.T) {
+	sc := testServerContext(t)
+
+	result, err := handleSourceAdd(context.Background(), sourceAddRequest(map[string]any{
+		"name":     "team",
+		"registry": "reg.example.com/team",
+		"default":  true,
+	}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatalf("expected error result, got: %s", resultText(t, result))
+	}
+	if text := resultText(t, result); !strings.Contains(text, "confirm: true") {
+		t.Errorf("expected error mentioning confirm: true, got: %s", text)
+	}
+	if _, err := os.Stat(sc.Paths.SourcesFile); !os.IsNotExist(err) {
+		t.Errorf("expected sources file not to be written, stat err = %v", err)
+	}
+}
+
+func TestHandleSourceAddDefaultReportsDemoted(t *testing.T) {
+	sc := testServerContext(t)
+
+	result, err := handleSourceAdd(context.Background(), sourceAddRequest(map[string]any{
+		"name":     "team",
+		"registry": "reg.example.com/team",
+		"default":  true,
+		"confirm":  true,
+	}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal([]byte(resultText(t, result)), &got); err != nil {
+		t.Fatalf("parsing result: %v", err)
+	}
+	if got["demoted"] != config.DefaultSourceName {
+		t.Errorf("demoted = %q, want %q", got["demoted"], config.DefaultSourceName)
+	}
+	if !strings.Contains(got["warning"], "reg.example.com/team") {
+		t.Errorf("expected warning about built-in source, got %q", got["warning"])
+	}
+	if d := sc.SourceConfig().Default(); d == nil || d.Name != "team" {
+		t.Errorf("default source = %v, want team", d)
+	}
+}
+
+func TestHandlePluginListRemotePaging(t *testing.T) {
+	sc := testServerContext(t)
+	sources := config.DefaultSourceConfig()
+	if err := sources.Add(config.Source{Name: "team", Registry: "reg.example.com/team"}); err != nil {
+		t.Fatal(err)
+	}
+	sc.SetSourceConfig(sources)
+
+	// The default source lists first, then the team source.
+	listed := [][]klausoci.ListEntry{
+		{{Name: "zeta", Reference: "gs/zeta:v1.0.0"}, {Name: "alpha", Reference: "gs/alpha:v1.0.0"}},
+		{{Name: "beta", Reference: "team/beta:v1.0.0"}},
+	}
+	orig := listPluginsFn
+	t.Cleanup(func() { listPluginsFn = orig })
+
+	tests := []struct {
+		name       string
+		limit      float64
+		offset     float64
+		want       []string
+		nextOffset int
+	}{
+		{name: "first page", limit: 2, want: []string{"alpha", "beta"}, nextOffset: 2},
+		{name: "last page", limit: 2, offset: 2, want: []string{"zeta"}},
+		{name: "exact fit", limit: 3, want: []string{"alpha", "beta", "zeta"}},
+		{name: "offset past end", limit: 2, offset: 3, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			listPluginsFn = func(context.Context, *klausoci.Client, ...klausoci.ListOption) ([]klausoci.ListEntry, error) {
+				calls++
+				return listed[calls-1], nil
+			}
+
+			result, err := handlePluginList(context.Background(), sourceAddRequest(map[string]any{
+				"remote": true,
+				"all":    true,
+				"limit":  tt.limit,
+				"offset": tt.offset,
+			}), sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", resultText(t, result))
+			}
+
+			var page server.Page[remoteArtifactEntry]
+			if err := json.Unmarshal([]byte(resultText(t, result)), &page); err != nil {
+				t.Fatalf("parsing result: %v", err)
+			}
+			names := []string{}
+			for _, e := range page.Items {
+				names = append(names, e.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("items = %v, want %v", names, tt.want)
+			}
+			if page.NextOffset != tt.nextOffset || page.Total != 3 {
+				t.Errorf("nextOffset = %d, total = %d; want %d, 3", page.NextOffset, page.Total, tt.nextOffset)
+			}
+		})
+	}
+}
@@ -16,7 +16,6 @@ import (
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 func registerPrompt(s *mcpserver.MCPServer, sc *server.ServerContext) {
@@ -95,6 +94,7 @@ func handlePrompt(ctx context.Context, req mcp.CallToolRequest, sc *server.Serve
 		go func() {
 			for range compCh {
 			}
+			mcpRecordUsage(streamCtx, name, baseURL, sc)
 		}()
 		return server.JSONResult(promptResult{
 			Instance: name,
@@ -104,6 +104,7 @@ func handlePrompt(ctx context.Context, req mcp.CallToolRequest, sc *server.Serve
 
 	for range compCh {
 	}
+	mcpRecordUsage(ctx, name, baseURL, sc)
 
 	resultResp, err := sc.MCPClient.Result(ctx, name, baseURL, false)
 	if err != nil {
@@ -412,7 +413,7 @@ func agentBaseURL(ctx context.Context, name string, sc *server.ServerContext) (s
 		return "", fmt.Errorf("instance %q not found; use klaus_create first: %w", name, err)
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		return "", fmt.Errorf("runtime error for %q: %w", name, err)
 	}
@@ -422,18 +423,18 @@ func agentBaseURL(ctx context.Context, name string, sc *server.ServerContext) (s
 		return "", fmt.Errorf("instance %q is not running (status: %s); use klaus_start first", name, status)
 	}
 
-	return fmt.Sprintf("http://localhost:%d/mcp", inst.Port), nil
+	return inst.AgentURL() + "/mcp", nil
 }
 
 // queryAgentStatus probes the agent's internal status through its MCP endpoint.
 // Returns the parsed status string or empty if the agent is unreachable.
-func queryAgentStatus(ctx context.Context, name string, port int, sc *server.ServerContext) string {
-	baseURL := fmt.Sprintf("http://localhost:%d/mcp", port)
+func queryAgentStatus(ctx context.Context, inst *instance.Instance, sc *server.ServerContext) string {
+	baseURL := inst.AgentURL() + "/mcp"
 
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	result, err := sc.MCPClient.Status(ctx, name, baseURL)
+	result, err := sc.MCPClient.Status(ctx, inst.Name, baseURL)
 	if err != nil {
 		return ""
 	}
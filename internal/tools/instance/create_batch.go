@@ -0,0 +1,166 @@
+package instance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/giantswarm/klausctl/internal/server"
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+const (
+	// createBatchConcurrency bounds how many instances klaus_create_batch
+	// creates at once; each create pulls artifacts and starts a container.
+	createBatchConcurrency = 4
+	// maxCreateBatch bounds the number of instances of one batch.
+	maxCreateBatch = 32
+)
+
+// batchCreate creates one instance of a batch. It is a variable so tests
+// can run without a container runtime.
+var batchCreate = mcpCreateInstance
+
+func registerCreateBatch(s *mcpserver.MCPServer, sc *server.ServerContext) {
+	tool := mcp.NewTool("klaus_create_batch",
+		mcp.WithDescription("Create several klaus instances in one call, a few at a time. A failed create does not roll back the others; every spec gets its own result with either the created instance or the error"),
+		mcp.WithArray("instances", mcp.Required(),
+			mcp.Description(fmt.Sprintf("Instance specs, each an object taking the klaus_create arguments (name is required); at most %d", maxCreateBatch)),
+			mcp.Items(map[string]any{"type": "object"}),
+		),
+	)
+	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleCreateBatch(ctx, req, sc)
+	})
+}
+
+// batchCreateResult is the outcome of one spec of a batch, in the order of
+// the request.
+type batchCreateResult struct {
+	// Name is the requested instance name; the created instance may have a
+	// suffix, see Result.
+	Name   string        `json:"name"`
+	Result *createResult `json:"result,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+func handleCreateBatch(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	specs, ok := req.GetArguments()["instances"].([]any)
+	if !ok || len(specs) == 0 {
+		return mcp.NewToolResultError("instances must be a non-empty array of instance specs"), nil
+	}
+	if len(specs) > maxCreateBatch {
+		return mcp.NewToolResultError(fmt.Sprintf("at most %d instances can be created in one batch, got %d", maxCreateBatch, len(specs))), nil
+	}
+
+	results := make([]batchCreateResult, len(specs))
+	params := make([]*mcpCreateParams, len(specs))
+	seen := map[string]int{}
+	for i, spec := range specs {
+		p, err := parseBatchSpec(spec)
+		if p != nil {
+			results[i].Name = p.name
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		// Without a suffix, two specs of the same name would race for the
+		// same instance directory.
+		if !p.generateSuffix || p.replace {
+			if j, dup := seen[p.name]; dup {
+				results[i].Error = fmt.Sprintf("instance %q is also created by spec %d of this batch", p.name, j)
+				continue
+			}
+			seen[p.name] = i
+		}
+		params[i] = p
+	}
+
+	if err := assignBatchPorts(sc.Paths, params); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Each create writes its own slot of results, so no locking is needed.
+	sem := make(chan struct{}, createBatchConcurrency)
+	var wg sync.WaitGroup
+	for i, p := range params {
+		if p == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i].Error = ctx.Err().Error()
+				return
+			}
+			defer func() { <-sem }()
+
+			ctx, span := sc.StartSpan(ctx, "instance.create", attribute.String("instance.name", p.name))
+			result, err := batchCreate(ctx, p, sc)
+			server.EndSpan(span, err)
+			if err != nil {
+				results[i].Error = err.Error()
+				return
+			}
+			results[i].Result = result
+		}()
+	}
+	wg.Wait()
+
+	return server.JSONResult(results)
+}
+
+// parseBatchSpec parses one instance spec with the klaus_create argument
+// parser. The returned params carry the name whenever it was given, even
+// with an error.
+func parseBatchSpec(spec any) (*mcpCreateParams, error) {
+	args, ok := spec.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("instance spec must be an object, got %T", spec)
+	}
+	p, err := parseMCPCreateParams(mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: args}})
+	if err != nil {
+		name, _ := args["name"].(string)
+		return &mcpCreateParams{name: name}, err
+	}
+	return p, nil
+}
+
+// assignBatchPorts gives every spec without a port its own free port.
+// Concurrent creates would otherwise all pick the same lowest free port,
+// since none of them has written its config yet.
+func assignBatchPorts(paths *config.Paths, params []*mcpCreateParams) error {
+	taken := map[int]bool{}
+	for _, p := range params {
+		if p != nil && p.port > 0 {
+			taken[p.port] = true
+		}
+	}
+	next := 8080
+	for _, p := range params {
+		if p == nil || p.port > 0 {
+			continue
+		}
+		for {
+			port, err := config.NextAvailablePort(paths, next)
+			if err != nil {
+				return err
+			}
+			next = port + 1
+			if !taken[port] {
+				p.port = port
+				taken[port] = true
+				break
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package instance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/giantswarm/klausctl/internal/server"
+)
+
+func stubBatchCreate(t *testing.T, fn func(context.Context, *mcpCreateParams, *server.ServerContext) (*createResult, error)) {
+	t.Helper()
+	orig := batchCreate
+	batchCreate = fn
+	t.Cleanup(func() { batchCreate = orig })
+}
+
+func TestHandleCreateBatchReportsPartialFailures(t *testing.T) {
+	sc := testServerContext(t)
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	stubBatchCreate(t, func(_ context.Context, p *mcpCreateParams, _ *server.ServerContext) (*createResult, error) {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		if p.name == "broken" {
+			return nil, errors.New("resolving refs: toolchain not found")
+		}
+		return &createResult{Instance: p.name, Status: "running", Port: p.port}, nil
+	})
+
+	specs := []any{
+		map[string]any{"name": "a", "generateSuffix": false},
+		map[string]any{"name": "broken"},
+		map[string]any{"workspace": "/tmp"},
+		map[string]any{"name": "a", "generateSuffix": false},
+		"not-an-object",
+		map[string]any{"name": "b", "port": float64(9100)},
+	}
+	for range 6 {
+		specs = append(specs, map[string]any{"name": "worker"})
+	}
+
+	result, err := handleCreateBatch(context.Background(), callToolRequest(map[string]any{"instances": specs}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", extractResultText(t, result))
+	}
+
+	var got []batchCreateResult
+	if err := json.Unmarshal([]byte(extractResultText(t, result)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(specs) {
+		t.Fatalf("got %d results, want %d", len(got), len(specs))
+	}
+
+	wantErr := map[int]string{1: "toolchain not found", 2: "name", 3: "also created by spec 0", 4: "must be an object"}
+	ports := map[int]bool{}
+	for i, r := range got {
+		if want, ok := wantErr[i]; ok {
+			if !strings.Contains(r.Error, want) || r.Result != nil {
+				t.Errorf("result %d = %+v, want error containing %q", i, r, want)
+			}
+			continue
+		}
+		if r.Error != "" || r.Result == nil {
+			t.Errorf("result %d = %+v, want a created instance", i, r)
+			continue
+		}
+		if ports[r.Result.Port] {
+			t.Errorf("port %d assigned twice", r.Result.Port)
+		}
+		ports[r.Result.Port] = true
+	}
+	if got[5].Result.Port != 9100 {
+		t.Errorf("explicit port = %d, want 9100", got[5].Result.Port)
+	}
+	if maxRunning > createBatchConcurrency {
+		t.Errorf("%d creates ran at once, want at most %d", maxRunning, createBatchConcurrency)
+	}
+}
+
+func TestHandleCreateBatchRejectsInvalidBatch(t *testing.T) {
+	sc := testServerContext(t)
+	tooMany := make([]any, maxCreateBatch+1)
+	for i := range tooMany {
+		tooMany[i] = map[string]any{"name": "w"}
+	}
+
+	for name, args := range map[string]map[string]any{
+		"missing":  {},
+		"empty":    {"instances": []any{}},
+		"too many": {"instances": tooMany},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := handleCreateBatch(context.Background(), callToolRequest(args), sc)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.IsError {
+				t.Errorf("expected an error result, got %s", extractResultText(t, result))
+			}
+		})
+	}
+}
@@ -2,12 +2,15 @@ package instance
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/giantswarm/klausctl/internal/server"
 	"github.com/giantswarm/klausctl/pkg/config"
@@ -22,9 +25,11 @@ type mcpCreateParams struct {
 	generateSuffix bool
 	force          bool
 	confirm        bool
+	replace        bool
 	workspace      string
 	personality    string
 	toolchain      string
+	image          string
 	pluginArgs     []string
 	sourceFilter   string
 	envVars        map[string]string
@@ -34,6 +39,8 @@ type mcpCreateParams struct {
 	envForward     []string
 	mcpServerRefs  []string
 	port           int
+	platform       string
+	network        string
 	gitAuthorName  string
 	gitAuthorEmail string
 	gitCredHelper  string
@@ -46,6 +53,8 @@ type mcpCreateParams struct {
 	model          string
 	systemPrompt   string
 	maxBudgetUSD   *float64
+	idempotencyKey string
+	start          bool
 }
 
 // parseMCPCreateParams extracts common create parameters from an MCP request.
@@ -58,15 +67,6 @@ func parseMCPCreateParams(req mcp.CallToolRequest) (*mcpCreateParams, error) {
 		return nil, err
 	}
 
-	workspace := req.GetString("workspace", "")
-	if workspace == "" {
-		cwd, err := os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("determining current directory: %v", err)
-		}
-		workspace = cwd
-	}
-
 	args := req.GetArguments()
 	envVars, err := extractStringMap(args, "envVars")
 	if err != nil {
@@ -100,9 +100,11 @@ func parseMCPCreateParams(req mcp.CallToolRequest) (*mcpCreateParams, error) {
 		generateSuffix: req.GetBool("generateSuffix", true),
 		force:          req.GetBool("force", false),
 		confirm:        req.GetBool("confirm", false),
-		workspace:      workspace,
+		replace:        req.GetBool("replace", false),
+		workspace:      req.GetString("workspace", ""),
 		personality:    req.GetString("personality", ""),
 		toolchain:      req.GetString("toolchain", ""),
+		image:          req.GetString("image", ""),
 		pluginArgs:     req.GetStringSlice("plugin", nil),
 		sourceFilter:   req.GetString("source", ""),
 		envVars:        envVars,
@@ -112,6 +114,8 @@ func parseMCPCreateParams(req mcp.CallToolRequest) (*mcpCreateParams, error) {
 		envForward:     req.GetStringSlice("envForward", nil),
 		mcpServerRefs:  req.GetStringSlice("mcpServerRefs", nil),
 		port:           port,
+		network:        req.GetString("network", ""),
+		platform:       req.GetString("platform", ""),
 		gitAuthorName:  gitAuthorName,
 		gitAuthorEmail: gitAuthorEmail,
 		gitCredHelper:  req.GetString("gitCredentialHelper", ""),
@@ -122,7 +126,9 @@ func parseMCPCreateParams(req mcp.CallToolRequest) (*mcpCreateParams, error) {
 		noFetch:        req.GetBool("noFetch", false),
 		permissionMode: req.GetString("permissionMode", ""),
 		model:          req.GetString("model", ""),
-		systemPrompt:   req.GetString("systemPrompt", ""),
+		systemPrompt:   orchestrator.EscapeSystemPrompt(req.GetString("systemPrompt", "")),
+		idempotencyKey: req.GetString("idempotencyKey", ""),
+		start:          req.GetBool("start", true),
 	}
 
 	if _, ok := args["maxBudgetUsd"]; ok {
@@ -136,9 +142,27 @@ func parseMCPCreateParams(req mcp.CallToolRequest) (*mcpCreateParams, error) {
 // mcpCreateInstance creates and starts a new instance from MCP parameters.
 // It handles name suffix generation, collision detection, config generation,
 // directory setup, and starting the container. Returns the create result.
-func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.ServerContext) (*createResult, error) {
+// With params.start unset the config files are rendered but the container
+// is not started; the result then has status "created".
+func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.ServerContext) (_ *createResult, retErr error) {
+	// A retried create returns the result of the original call rather than
+	// creating a duplicate or failing on the name collision.
+	if params.idempotencyKey != "" {
+		rec, err := instance.FindCreateRecord(sc.Paths, params.idempotencyKey)
+		if err != nil {
+			return nil, fmt.Errorf("looking up idempotency key: %v", err)
+		}
+		if rec != nil {
+			var result createResult
+			if err := json.Unmarshal(rec.Result, &result); err != nil {
+				return nil, fmt.Errorf("parsing create result of instance %q: %v", rec.Name, err)
+			}
+			return &result, nil
+		}
+	}
+
 	name := params.name
-	if params.generateSuffix {
+	if params.generateSuffix && !params.replace {
 		suffixed, err := instance.AppendSuffix(name)
 		if err != nil {
 			return nil, fmt.Errorf("generating name suffix: %v", err)
@@ -150,6 +174,11 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 		return nil, err
 	}
 
+	workspace, err := config.ResolveCreateWorkspace(sc.Paths, params.workspace)
+	if err != nil {
+		return nil, err
+	}
+
 	instancePaths := sc.InstancePaths(name)
 
 	// Check for name collision before expensive network calls.
@@ -158,7 +187,15 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 		return nil, fmt.Errorf("checking for existing instance: %v", err)
 	}
 
-	if err := handleMCPCollision(ctx, name, collision, params.force, params.confirm, instancePaths, sc); err != nil {
+	if params.replace && collision != instance.NoCollision {
+		replacement, err := mcpSetAsideExistingInstance(ctx, name, instancePaths)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			retErr = mcpFinishReplacement(name, replacement, retErr)
+		}()
+	} else if err := handleMCPCollision(ctx, name, collision, params.force, params.confirm, instancePaths, sc); err != nil {
 		return nil, err
 	}
 
@@ -177,7 +214,7 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 
 	createOpts := config.CreateOptions{
 		Name:                 name,
-		Workspace:            params.workspace,
+		Workspace:            workspace,
 		Mode:                 params.mode,
 		NoIsolate:            params.noIsolate,
 		NoFetch:              params.noFetch,
@@ -185,6 +222,9 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 		Toolchain:            toolchain,
 		Plugins:              pluginArgs,
 		Port:                 params.port,
+		Network:              params.network,
+		Platform:             params.platform,
+		Image:                params.image,
 		GitAuthorName:        params.gitAuthorName,
 		GitAuthorEmail:       params.gitAuthorEmail,
 		GitCredentialHelper:  params.gitCredHelper,
@@ -202,12 +242,14 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 		MaxBudgetUSD:         params.maxBudgetUSD,
 		Context:              ctx,
 		Output:               io.Discard,
-		ResolvePersonality: func(ctx context.Context, ref string, w io.Writer) (*config.ResolvedPersonality, error) {
+		ResolvePersonality: func(ctx context.Context, ref string, w io.Writer) (_ *config.ResolvedPersonality, err error) {
+			ctx, span := sc.StartSpan(ctx, "pull.personality", attribute.String("personality.ref", ref))
+			defer func() { server.EndSpan(span, err) }()
 			if err := config.EnsureDir(sc.Paths.PersonalitiesDir); err != nil {
 				return nil, fmt.Errorf("creating personalities directory: %w", err)
 			}
 			client := orchestrator.NewDefaultClient()
-			pr, err := orchestrator.ResolvePersonality(ctx, client, ref, sc.Paths.PersonalitiesDir, io.Discard)
+			pr, err := orchestrator.ResolvePersonality(ctx, client, sc.SourceResolver(), ref, sc.Paths.PersonalitiesDir, io.Discard)
 			if err != nil {
 				return nil, err
 			}
@@ -247,7 +289,11 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 		return nil, fmt.Errorf("creating rendered directory parent: %v", err)
 	}
 
-	result, err := startExistingInstance(ctx, name, sc)
+	start := startExistingInstance
+	if !params.start {
+		start = prepareInstance
+	}
+	result, err := start(ctx, name, sc)
 	if err != nil {
 		if cfg.WorktreePath != "" {
 			_ = worktree.Remove(cfg.Workspace, cfg.WorktreePath)
@@ -255,5 +301,11 @@ func mcpCreateInstance(ctx context.Context, params *mcpCreateParams, sc *server.
 		_ = os.RemoveAll(instancePaths.InstanceDir)
 		return nil, err
 	}
+
+	if params.idempotencyKey != "" {
+		if err := instance.SaveCreateRecord(instancePaths, params.idempotencyKey, result); err != nil {
+			log.Printf("Warning: recording idempotency key for %q: %v", name, err)
+		}
+	}
 	return result, nil
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/giantswarm/klausctl/internal/remotesurface"
 	"github.com/giantswarm/klausctl/internal/server"
 	"github.com/giantswarm/klausctl/pkg/agentclient"
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
 )
 
@@ -20,7 +22,7 @@ func registerRun(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithDescription("Create a new klaus instance, wait for it to become ready, and send a prompt — all in one operation"),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Instance name")),
 		mcp.WithString("message", mcp.Required(), mcp.Description("Prompt message to send to the agent after the instance is ready")),
-		mcp.WithString("workspace", mcp.Description("Workspace directory (default: current working directory)")),
+		mcp.WithString("workspace", mcp.Description("Workspace directory (default: defaultWorkspace from the klausctl config, else the current working directory)")),
 		mcp.WithString("personality", mcp.Description("Personality short name or OCI reference")),
 		mcp.WithString("toolchain", mcp.Description("Toolchain short name or OCI reference")),
 		mcp.WithArray("plugin", mcp.Description("Additional plugin short names or OCI references")),
@@ -39,6 +41,7 @@ func registerRun(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithBoolean("noIsolate", mcp.Description("Skip git worktree creation and bind-mount workspace directly (default: false)")),
 		mcp.WithBoolean("noFetch", mcp.Description("Skip git fetch origin before cloning the workspace (default: false)")),
 		mcp.WithNumber("port", mcp.Description("Override auto-selected host port for the instance MCP endpoint (0 or omitted = auto-select starting from 8080)")),
+		mcp.WithString("platform", mcp.Description(`Image platform as os/arch, e.g. "linux/amd64" to run amd64 images on arm64 hosts (default: host platform)`)),
 		mcp.WithString("gitAuthor", mcp.Description("Git author identity as \"Name <email>\"; sets GIT_AUTHOR_NAME/GIT_COMMITTER_NAME and GIT_AUTHOR_EMAIL/GIT_COMMITTER_EMAIL in the container")),
 		mcp.WithString("gitCredentialHelper", mcp.Description("Git credential helper (currently only \"gh\" is supported, which configures git to call \"gh auth git-credential\" for github.com)")),
 		mcp.WithBoolean("gitHttpsInsteadOfSsh", mcp.Description("Rewrite SSH git URLs (git@github.com:...) to HTTPS via container-local gitconfig (default: false)")),
@@ -121,10 +124,17 @@ func handleRun(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerCo
 		return mcp.NewToolResultError(stepErr.Error()), nil
 	}
 
-	agentURL := fmt.Sprintf("http://localhost:%d", createRes.Port)
+	agentURL := createRes.MCP
+	if agentURL == "" {
+		agentURL = instance.AgentURL("", createRes.Port)
+	}
 	httpClient := &http.Client{}
 
-	if err := agentclient.WaitForReady(ctx, httpClient, agentURL); err != nil {
+	probe, err := config.LoadReadinessProbe(instancePaths.ConfigFile)
+	if err != nil {
+		return cleanupOnError(err)
+	}
+	if err := agentclient.WaitForProbe(ctx, httpClient, agentURL, probe); err != nil {
 		return cleanupOnError(fmt.Errorf("waiting for instance %q to become ready: %v", name, err))
 	}
 
@@ -152,6 +162,7 @@ func handleRun(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerCo
 		go func() {
 			for range compCh {
 			}
+			mcpRecordUsage(streamCtx, name, agentURL+"/mcp", sc)
 		}()
 		return server.JSONResult(runResult{
 			Instance:  name,
@@ -167,7 +178,8 @@ func handleRun(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerCo
 	for range compCh {
 	}
 
-	baseURL := fmt.Sprintf("http://localhost:%d/mcp", createRes.Port)
+	baseURL := agentURL + "/mcp"
+	mcpRecordUsage(ctx, name, baseURL, sc)
 	resultResp, err := sc.MCPClient.Result(ctx, name, baseURL, false)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("fetching result from %q: %v", name, err)), nil
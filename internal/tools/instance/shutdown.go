@@ -0,0 +1,46 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klausctl/internal/server"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// stopOnExit stops and removes one instance when the server shuts down,
+// archiving its transcript first. It is a variable so tests can run
+// without a container runtime.
+var stopOnExit = func(ctx context.Context, name string, sc *server.ServerContext) error {
+	result, err := stopOne(ctx, name, sc, false, false, runtime.DefaultStopTimeout)
+	if err != nil {
+		return err
+	}
+	if result.IsError && len(result.Content) > 0 {
+		return errors.New(mcp.GetTextFromContent(result.Content[0]))
+	}
+	return nil
+}
+
+// StopStartedInstances stops the instances this server started that were
+// not stopped or deleted through a tool since, a few at a time. It is meant
+// as a shutdown hook (see server.ServerContext.OnShutdown), so that killing
+// the server does not leave orphaned containers behind.
+func StopStartedInstances(ctx context.Context, sc *server.ServerContext) error {
+	names := sc.TrackedInstances()
+	targets := make([]*instance.Instance, len(names))
+	for i, name := range names {
+		targets[i] = &instance.Instance{Name: name}
+	}
+	return instance.ForEach(ctx, targets, instance.DefaultConcurrency, func(ctx context.Context, inst *instance.Instance) error {
+		if err := stopOnExit(ctx, inst.Name, sc); err != nil {
+			return fmt.Errorf("stopping instance %q: %w", inst.Name, err)
+		}
+		sc.UntrackInstance(inst.Name)
+		return nil
+	})
+}
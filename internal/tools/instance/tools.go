@@ -14,29 +14,35 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/giantswarm/klausctl/internal/server"
 	"github.com/giantswarm/klausctl/pkg/archive"
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/logparse"
 	"github.com/giantswarm/klausctl/pkg/orchestrator"
 	"github.com/giantswarm/klausctl/pkg/renderer"
 	"github.com/giantswarm/klausctl/pkg/runtime"
+	"github.com/giantswarm/klausctl/pkg/usage"
 	"github.com/giantswarm/klausctl/pkg/worktree"
 )
 
 // RegisterTools registers all instance lifecycle tools on the MCP server.
 func RegisterTools(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	registerCreate(s, sc)
+	registerCreateBatch(s, sc)
 	registerStart(s, sc)
 	registerStop(s, sc)
 	registerDelete(s, sc)
 	registerStatus(s, sc)
+	registerInspect(s, sc)
 	registerLogs(s, sc)
 	registerList(s, sc)
 	registerPrompt(s, sc)
@@ -55,11 +61,12 @@ func RegisterTools(s *mcpserver.MCPServer, sc *server.ServerContext) {
 
 func registerCreate(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	tool := mcp.NewTool("klaus_create",
-		mcp.WithDescription("Create and start a new klaus instance"),
+		mcp.WithDescription("Create and start a new klaus instance (set start: false to only create it)"),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Instance name")),
-		mcp.WithString("workspace", mcp.Description("Workspace directory (default: current working directory)")),
+		mcp.WithString("workspace", mcp.Description("Workspace directory, or \"auto\" for the git root of the current working directory (default: defaultWorkspace from the klausctl config, else the current working directory)")),
 		mcp.WithString("personality", mcp.Description("Personality short name or OCI reference")),
 		mcp.WithString("toolchain", mcp.Description("Toolchain short name or OCI reference")),
+		mcp.WithString("image", mcp.Description("Container image to run, overriding the personality's toolchain; cannot be combined with toolchain")),
 		mcp.WithArray("plugin", mcp.Description("Additional plugin short names or OCI references")),
 		mcp.WithString("source", mcp.Description("Resolve artifact short names against a specific source")),
 		mcp.WithObject("envVars", mcp.Description("Environment variable key-value pairs to set in the container (merged with any existing envVars from the resolved config)")),
@@ -75,6 +82,8 @@ func registerCreate(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithString("mode", mcp.Description(`Operating mode: "agent" (default, autonomous coding, new process per prompt) or "chat" (interactive, persistent process, saved sessions)`)),
 		mcp.WithBoolean("noIsolate", mcp.Description("Skip git worktree creation and bind-mount workspace directly (default: false)")),
 		mcp.WithNumber("port", mcp.Description("Override auto-selected host port for the instance MCP endpoint (0 or omitted = auto-select starting from 8080)")),
+		mcp.WithString("network", mcp.Description(`Container network: "host", "bridge", "none", or the name of an existing network (default: runtime default). With "host", no port is published and the agent listens on the instance port directly`)),
+		mcp.WithString("platform", mcp.Description(`Image platform as os/arch, e.g. "linux/amd64" to run amd64 images on arm64 hosts (default: host platform)`)),
 		mcp.WithString("gitAuthor", mcp.Description("Git author identity as \"Name <email>\"; sets GIT_AUTHOR_NAME/GIT_COMMITTER_NAME and GIT_AUTHOR_EMAIL/GIT_COMMITTER_EMAIL in the container")),
 		mcp.WithString("gitCredentialHelper", mcp.Description("Git credential helper (currently only \"gh\" is supported, which configures git to call \"gh auth git-credential\" for github.com)")),
 		mcp.WithBoolean("gitHttpsInsteadOfSsh", mcp.Description("Rewrite SSH git URLs (git@github.com:...) to HTTPS via container-local gitconfig (default: false)")),
@@ -82,6 +91,9 @@ func registerCreate(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithBoolean("generateSuffix", mcp.Description("Append a random 4-character suffix to the instance name to avoid collisions (default: true)")),
 		mcp.WithBoolean("force", mcp.Description("Allow replacing a running instance; requires confirm: true as well")),
 		mcp.WithBoolean("confirm", mcp.Description("Confirm replacement of an existing instance; required when a name collision is detected")),
+		mcp.WithBoolean("replace", mcp.Description("Recreate an existing instance of the same name in one call, without force/confirm: its container is removed and its directory is moved aside until the new instance has started, and restored (stopped) if creation fails. Implies generateSuffix: false")),
+		mcp.WithString("idempotencyKey", mcp.Description("Client-chosen request ID that makes retries safe: if an existing instance was created with the same key, its original create result is returned instead of creating another instance. The key expires when that instance is deleted")),
+		mcp.WithBoolean("start", mcp.Description(`Start the container after creating the instance (default: true). When false, the config is saved and rendered and the result has status "created"; use klaus_start to launch it`)),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleCreate(ctx, req, sc)
@@ -104,6 +116,8 @@ func registerStop(s *mcpserver.MCPServer, sc *server.ServerContext) {
 		mcp.WithString("name", mcp.Description("Instance name (required unless all=true)")),
 		mcp.WithBoolean("all", mcp.Description("Stop all instances")),
 		mcp.WithBoolean("noArchive", mcp.Description("Skip archiving the agent transcript before stopping (default: false)")),
+		mcp.WithBoolean("keep", mcp.Description("Stop the container without removing it, so the next start reuses it (default: false)")),
+		mcp.WithNumber("timeout", mcp.Description("Seconds the agent gets to shut down before it is killed (default: the instance's stopTimeout, or 10)")),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleStop(ctx, req, sc)
@@ -123,19 +137,31 @@ func registerDelete(s *mcpserver.MCPServer, sc *server.ServerContext) {
 
 func registerStatus(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	tool := mcp.NewTool("klaus_status",
-		mcp.WithDescription("Return instance status as JSON"),
-		mcp.WithString("name", mcp.Required(), mcp.Description("Instance name")),
+		mcp.WithDescription("Return instance status as JSON; status is e.g. running, paused or stopped. Without a name, or with all=true, return an array with the status of every instance"),
+		mcp.WithString("name", mcp.Description("Instance name; omit to return the status of all instances")),
+		mcp.WithBoolean("all", mcp.Description("Return the status of all instances (default: false)")),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleStatus(ctx, req, sc)
 	})
 }
 
+func registerInspect(s *mcpserver.MCPServer, sc *server.ServerContext) {
+	tool := mcp.NewTool("klaus_inspect",
+		mcp.WithDescription("Return the full resolved state of an instance as JSON: saved config and state, resolved personality and plugins, container info, volumes and env vars (secrets redacted)"),
+		mcp.WithString("name", mcp.Required(), mcp.Description("Instance name")),
+	)
+	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleInspect(ctx, req, sc)
+	})
+}
+
 func registerLogs(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	tool := mcp.NewTool("klaus_logs",
 		mcp.WithDescription("Return recent container log lines"),
 		mcp.WithString("name", mcp.Required(), mcp.Description("Instance name")),
 		mcp.WithNumber("tail", mcp.Description("Number of lines from end (default: 100)")),
+		mcp.WithBoolean("parse", mcp.Description("Return an array with the agent's JSON lines as objects and other lines as strings")),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleLogs(ctx, req, sc)
@@ -145,6 +171,7 @@ func registerLogs(s *mcpserver.MCPServer, sc *server.ServerContext) {
 func registerList(s *mcpserver.MCPServer, sc *server.ServerContext) {
 	tool := mcp.NewTool("klaus_list",
 		mcp.WithDescription("List all instances with status, toolchain, personality, workspace, port, and uptime as JSON"),
+		server.WithPagination(),
 	)
 	s.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		return handleList(ctx, req, sc)
@@ -159,7 +186,9 @@ func handleCreate(ctx context.Context, req mcp.CallToolRequest, sc *server.Serve
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	ctx, span := sc.StartSpan(ctx, "instance.create", attribute.String("instance.name", params.name))
 	result, err := mcpCreateInstance(ctx, params, sc)
+	server.EndSpan(span, err)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -215,6 +244,32 @@ func mcpCleanupExistingInstance(ctx context.Context, name string, paths *config.
 	return nil
 }
 
+// mcpSetAsideExistingInstance removes the container of an existing instance
+// and moves its directory aside, so the instance can be restored if creating
+// its replacement fails.
+func mcpSetAsideExistingInstance(ctx context.Context, name string, paths *config.Paths) (*instance.Replacement, error) {
+	inst, _ := instance.Load(paths)
+	if err := cleanupContainer(ctx, name, inst); err != nil {
+		return nil, fmt.Errorf("removing container of existing instance: %v", err)
+	}
+	return instance.SetAside(paths)
+}
+
+// mcpFinishReplacement discards the previous instance when createErr is nil
+// and restores it otherwise. It returns the error to report for the create.
+func mcpFinishReplacement(name string, r *instance.Replacement, createErr error) error {
+	if createErr == nil {
+		if err := r.Discard(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		return nil
+	}
+	if err := r.Restore(); err != nil {
+		return fmt.Errorf("%v (%v)", createErr, err)
+	}
+	return fmt.Errorf("%v; the previous instance %q was restored in a stopped state, use klaus_start to start it", createErr, name)
+}
+
 // extractStringMap extracts a map[string]string from MCP request arguments.
 func extractStringMap(args map[string]any, key string) (map[string]string, error) {
 	raw, ok := args[key]
@@ -266,6 +321,12 @@ func handleStop(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerC
 	name := req.GetString("name", "")
 	all := req.GetBool("all", false)
 	noArchive := req.GetBool("noArchive", false)
+	keep := req.GetBool("keep", false)
+
+	timeout := runtime.DefaultStopTimeout
+	if secs := req.GetFloat("timeout", -1); secs >= 0 {
+		timeout = time.Duration(secs * float64(time.Second))
+	}
 
 	if name == "" && !all {
 		return mcp.NewToolResultError("either name or all=true is required"), nil
@@ -274,11 +335,18 @@ func handleStop(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerC
 		return mcp.NewToolResultError("name and all=true are mutually exclusive"), nil
 	}
 
+	ctx, span := sc.StartSpan(ctx, "instance.stop", attribute.String("instance.name", name), attribute.Bool("instance.all", all))
+	defer span.End()
+
 	if all {
-		return stopAll(ctx, sc, noArchive)
+		return stopAll(ctx, sc, noArchive, keep, timeout)
 	}
 
-	return stopOne(ctx, name, sc, noArchive)
+	result, err := stopOne(ctx, name, sc, noArchive, keep, timeout)
+	if err == nil && !result.IsError {
+		sc.UntrackInstance(name)
+	}
+	return result, err
 }
 
 func handleDelete(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
@@ -322,6 +390,7 @@ func handleDelete(ctx context.Context, req mcp.CallToolRequest, sc *server.Serve
 	if err := os.RemoveAll(paths.InstanceDir); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("deleting instance directory: %v", err)), nil
 	}
+	sc.UntrackInstance(name)
 
 	return server.JSONResult(map[string]string{
 		"instance": name,
@@ -340,12 +409,26 @@ type statusResult struct {
 	Workspace   string `json:"workspace"`
 	MCP         string `json:"mcp,omitempty"`
 	Uptime      string `json:"uptime,omitempty"`
+	// RestartPolicy is set when the container restarts automatically.
+	RestartPolicy string `json:"restart_policy,omitempty"`
+	// Error is set in the all-instances view when the instance could not
+	// be probed.
+	Error string `json:"error,omitempty"`
 }
 
+// statusConcurrency bounds the instances probed at once by klaus_status
+// with all=true. Probes are short runtime and agent queries, so more of
+// them run in parallel than lifecycle operations.
+const statusConcurrency = 8
+
 func handleStatus(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	name, err := req.RequireString("name")
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+	name := req.GetString("name", "")
+	all := req.GetBool("all", false)
+	if name != "" && all {
+		return mcp.NewToolResultError("name and all are mutually exclusive"), nil
+	}
+	if name == "" {
+		return handleStatusAll(ctx, sc)
 	}
 
 	paths := sc.InstancePaths(name)
@@ -355,25 +438,76 @@ func handleStatus(ctx context.Context, req mcp.CallToolRequest, sc *server.Serve
 		if cfgErr != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("no instance found for %q; use klaus_create to create one", name)), nil
 		}
-		return server.JSONResult(statusResult{
-			Instance:  name,
-			Status:    "stopped",
-			Container: instance.ContainerName(name),
-			Runtime:   cfg.Runtime,
-			Image:     cfg.Image,
-			Workspace: cfg.Workspace,
-		})
+		return server.JSONResult(stoppedStatus(name, cfg))
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	result, err := instanceStatus(ctx, inst, sc)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	return server.JSONResult(result)
+}
+
+// handleStatusAll returns the status of every instance with a config,
+// sorted by name. The instances with state are probed in parallel; a probe
+// that fails is reported in the entry's error rather than failing the call.
+func handleStatusAll(ctx context.Context, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	entries, err := loadInstanceEntries(sc)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	results := make([]statusResult, len(entries))
+	var started []*instance.Instance
+	index := map[string]int{}
+	for i, e := range entries {
+		results[i] = stoppedStatus(e.name, e.cfg)
+		if e.state != nil {
+			started = append(started, e.state)
+			index[e.state.Name] = i
+		}
+	}
+
+	// Each probe writes its own slot of results, so no locking is needed.
+	_ = instance.ForEach(ctx, started, statusConcurrency, func(ctx context.Context, inst *instance.Instance) error {
+		i := index[inst.Name]
+		result, err := instanceStatus(ctx, inst, sc)
+		if err != nil {
+			results[i].Error = err.Error()
+			return nil
+		}
+		results[i] = result
+		return nil
+	})
+
+	return server.JSONResult(results)
+}
+
+// stoppedStatus is the status of an instance that has a config but no
+// container.
+func stoppedStatus(name string, cfg *config.Config) statusResult {
+	return statusResult{
+		Instance:  name,
+		Status:    "stopped",
+		Container: instance.ContainerName(name),
+		Runtime:   cfg.Runtime,
+		Image:     cfg.Image,
+		Workspace: cfg.Workspace,
+	}
+}
+
+// instanceStatus queries the runtime and the agent for the status of a
+// started instance.
+func instanceStatus(ctx context.Context, inst *instance.Instance, sc *server.ServerContext) (statusResult, error) {
+	rt, err := inst.NewRuntime()
+	if err != nil {
+		return statusResult{}, err
+	}
 
 	containerName := inst.ContainerName()
 	status, err := rt.Status(ctx, containerName)
 	if err != nil || status == "" {
-		return mcp.NewToolResultError(fmt.Sprintf("instance %q has stale state (container no longer exists); use klaus_create to start a new one", name)), nil
+		return statusResult{}, fmt.Errorf("instance %q has stale state (container no longer exists); use klaus_create to start a new one", inst.Name)
 	}
 
 	result := statusResult{
@@ -385,49 +519,106 @@ func handleStatus(ctx context.Context, req mcp.CallToolRequest, sc *server.Serve
 		Image:       inst.Image,
 		Workspace:   inst.Workspace,
 	}
+	if inst.RestartPolicy != "no" {
+		result.RestartPolicy = inst.RestartPolicy
+	}
 
-	if status == "running" { //nolint:goconst
-		result.MCP = fmt.Sprintf("http://localhost:%d", inst.Port)
+	if status == "running" || status == "paused" { //nolint:goconst
+		result.MCP = inst.AgentURL()
 		if info, err := rt.Inspect(ctx, containerName); err == nil && !info.StartedAt.IsZero() {
 			result.Uptime = formatDuration(time.Since(info.StartedAt))
 		} else if !inst.StartedAt.IsZero() {
 			result.Uptime = formatDuration(time.Since(inst.StartedAt))
 		}
-		if agentStatus := queryAgentStatus(ctx, inst.Name, inst.Port, sc); agentStatus != "" {
+	}
+	switch status {
+	case "running":
+		if agentStatus := queryAgentStatus(ctx, inst, sc); agentStatus != "" {
 			result.AgentStatus = agentStatus
 		}
+	case "paused":
+		// A paused agent cannot answer; querying it would only time out.
+		result.AgentStatus = "paused"
 	}
 
-	return server.JSONResult(result)
+	return result, nil
 }
 
-func handleLogs(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+func handleInspect(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
 	name, err := req.RequireString("name")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	tail := int(req.GetFloat("tail", 100))
+	result, err := orchestrator.Inspect(ctx, sc.InstancePaths(name), name, runtime.NewWithHost)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	return server.JSONResult(result)
+}
 
-	paths := sc.InstancePaths(name)
-	inst, err := instance.Load(paths)
+func handleLogs(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	name, err := req.RequireString("name")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("no instance found for %q", name)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	tail := int(req.GetFloat("tail", 100))
+
+	rt, containerName, err := logsRuntime(ctx, name, sc)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	logs, err := rt.LogsCapture(ctx, inst.ContainerName(), tail)
+	logs, err := rt.LogsCapture(ctx, containerName, tail)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("fetching logs: %v", err)), nil
 	}
 
+	if req.GetBool("parse", false) {
+		lines := []logparse.Line{}
+		if err := logparse.Parse(strings.NewReader(logs), func(l logparse.Line) error {
+			lines = append(lines, l)
+			return nil
+		}); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return server.JSONResult(lines)
+	}
 	return mcp.NewToolResultText(logs), nil
 }
 
+// probeRuntime returns the named local runtime for logsRuntime. It is a
+// variable so tests can run without docker or podman.
+var probeRuntime = func(name string) (runtime.Runtime, error) {
+	return runtime.New(name)
+}
+
+// logsRuntime returns the runtime and container of the named instance.
+// When its state cannot be loaded, e.g. after the state file was lost,
+// docker and podman are probed for the instance's container instead.
+func logsRuntime(ctx context.Context, name string, sc *server.ServerContext) (runtime.Runtime, string, error) {
+	if inst, err := instance.Load(sc.InstancePaths(name)); err == nil {
+		if rt, err := inst.NewRuntime(); err == nil {
+			return rt, inst.ContainerName(), nil
+		}
+	}
+
+	containerName := instance.ContainerName(name)
+	candidates := uniqueRuntimes(nil)
+	for _, rtName := range candidates {
+		rt, err := probeRuntime(rtName)
+		if err != nil {
+			continue
+		}
+		if status, err := rt.Status(ctx, containerName); err == nil && status != "" {
+			return rt, containerName, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no instance found for %q: no saved state, and neither %s has a container named %s",
+		name, strings.Join(candidates, " nor "), containerName)
+}
+
 type listEntry struct {
 	Name        string `json:"name"`
 	Status      string `json:"status"`
@@ -438,39 +629,22 @@ type listEntry struct {
 	Uptime      string `json:"uptime,omitempty"`
 }
 
-func handleList(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
-	dirEntries, err := os.ReadDir(sc.Paths.InstancesDir)
+func handleList(ctx context.Context, req mcp.CallToolRequest, sc *server.ServerContext) (*mcp.CallToolResult, error) {
+	page, err := server.PageRequestFrom(req)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return server.JSONResult([]listEntry{})
-		}
-		return mcp.NewToolResultError(fmt.Sprintf("reading instances directory: %v", err)), nil
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	stateByName := map[string]*instance.Instance{}
-	states, err := instance.LoadAll(sc.Paths)
+	entries, err := loadInstanceEntries(sc)
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("loading instance states: %v", err)), nil
-	}
-	for _, st := range states {
-		stateByName[st.Name] = st
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	list := make([]listEntry, 0, len(dirEntries))
-	for _, entry := range dirEntries {
-		if !entry.IsDir() {
-			continue
-		}
-		name := entry.Name()
-		instPaths := sc.InstancePaths(name)
-
-		cfg, err := config.Load(instPaths.ConfigFile)
-		if err != nil {
-			continue
-		}
-
+	list := make([]listEntry, 0, len(entries))
+	for _, e := range entries {
+		cfg := e.cfg
 		item := listEntry{
-			Name:        name,
+			Name:        e.name,
 			Status:      "stopped",
 			Toolchain:   klausoci.ShortName(klausoci.RepositoryFromRef(cmp.Or(cfg.Toolchain, cfg.Image))),
 			Personality: klausoci.ShortName(klausoci.RepositoryFromRef(cfg.Personality)),
@@ -478,8 +652,8 @@ func handleList(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerCon
 			Port:        cfg.Port,
 		}
 
-		if st, ok := stateByName[name]; ok {
-			rt, err := runtime.New(st.Runtime)
+		if st := e.state; st != nil {
+			rt, err := st.NewRuntime()
 			if err == nil {
 				status, err := rt.Status(ctx, st.ContainerName())
 				if err == nil && status != "" {
@@ -498,122 +672,261 @@ func handleList(ctx context.Context, _ mcp.CallToolRequest, sc *server.ServerCon
 		list = append(list, item)
 	}
 
-	sort.Slice(list, func(i, j int) bool {
-		return list[i].Name < list[j].Name
-	})
+	return server.PaginatedJSONResult(list, page)
+}
+
+// instanceEntry is an instance with a config, and its saved state if it
+// has been started.
+type instanceEntry struct {
+	name  string
+	cfg   *config.Config
+	state *instance.Instance
+}
+
+// loadInstanceEntries returns the instances that have a config, sorted by
+// name. Directories without a loadable config are skipped.
+func loadInstanceEntries(sc *server.ServerContext) ([]instanceEntry, error) {
+	dirEntries, err := os.ReadDir(sc.Paths.InstancesDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading instances directory: %w", err)
+	}
+
+	stateByName := map[string]*instance.Instance{}
+	states, err := instance.LoadAll(sc.Paths)
+	if err != nil {
+		return nil, fmt.Errorf("loading instance states: %w", err)
+	}
+	for _, st := range states {
+		stateByName[st.Name] = st
+	}
+
+	entries := make([]instanceEntry, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		cfg, err := config.Load(sc.InstancePaths(name).ConfigFile)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, instanceEntry{name: name, cfg: cfg, state: stateByName[name]})
+	}
 
-	return server.JSONResult(list)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries, nil
 }
 
 // --- Helpers ---
 
 type createResult struct {
-	Instance    string `json:"instance"`
-	Status      string `json:"status"`
-	Container   string `json:"container"`
-	Image       string `json:"image"`
-	Workspace   string `json:"workspace"`
-	Port        int    `json:"port"`
+	Instance  string `json:"instance"`
+	Status    string `json:"status"`
+	Container string `json:"container"`
+	Image     string `json:"image"`
+	Workspace string `json:"workspace"`
+	Port      int    `json:"port"`
+	// MCP is the agent's base URL once the instance is running.
+	MCP         string `json:"mcp,omitempty"`
 	Personality string `json:"personality,omitempty"`
+	// Warnings lists non-fatal problems found while resolving the
+	// personality, e.g. plugins that could not be found.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
-// startExistingInstance loads config for a named instance and starts its
-// container. Used by both create and start handlers.
-func startExistingInstance(ctx context.Context, name string, sc *server.ServerContext) (*createResult, error) {
+// loadInstanceConfig loads the config of a named instance and checks that
+// its workspace exists. It returns the config and the host workspace path.
+func loadInstanceConfig(name string, sc *server.ServerContext) (*config.Config, string, error) {
 	paths := sc.InstancePaths(name)
 	cfg, err := config.Load(paths.ConfigFile)
 	if err != nil {
-		return nil, fmt.Errorf("loading config for %q: %w", name, err)
+		return nil, "", fmt.Errorf("loading config for %q: %w", name, err)
+	}
+	if err := cfg.ResolveImageShortName(sc.SourceResolver()); err != nil {
+		return nil, "", err
 	}
 
 	workspace := config.ResolveWorkspacePath(cfg.Workspace, sc.Paths.ReposDir)
 	if _, err := os.Stat(workspace); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, fmt.Errorf("workspace directory does not exist: %s", workspace)
+			return nil, "", fmt.Errorf("workspace directory does not exist: %s", workspace)
 		}
-		return nil, fmt.Errorf("checking workspace directory: %w", err)
+		return nil, "", fmt.Errorf("checking workspace directory: %w", err)
 	}
 	if cfg.WorktreePath != "" {
 		if _, err := os.Stat(cfg.WorktreePath); err != nil {
 			if errors.Is(err, os.ErrNotExist) {
-				return nil, fmt.Errorf("workspace clone directory does not exist: %s", cfg.WorktreePath)
+				return nil, "", fmt.Errorf("workspace clone directory does not exist: %s", cfg.WorktreePath)
 			}
-			return nil, fmt.Errorf("checking workspace clone directory: %w", err)
-		}
-	}
-
-	rt, err := runtime.New(cfg.Runtime)
-	if err != nil {
-		return nil, err
-	}
-
-	containerName := instance.ContainerName(name)
-
-	// Clean up stale containers.
-	inst, err := instance.Load(paths)
-	if err == nil && inst.Name != "" {
-		status, sErr := rt.Status(ctx, inst.ContainerName())
-		if sErr == nil && status == "running" {
-			return nil, fmt.Errorf("instance %q is already running (container: %s, MCP: http://localhost:%d)", inst.Name, inst.ContainerName(), inst.Port)
+			return nil, "", fmt.Errorf("checking workspace clone directory: %w", err)
 		}
-		_ = rt.Remove(ctx, inst.ContainerName())
-		_ = instance.Clear(paths)
 	}
+	return cfg, workspace, nil
+}
 
-	client := orchestrator.NewDefaultClient()
-
-	// Resolve personality if configured.
-	var personalityDir string
+// renderInstance resolves the personality, secret refs and system prompts
+// of cfg and renders its config files. It returns the local personality
+// directory, and the floating references of cfg and the warnings found while
+// resolving the personality as warnings.
+func renderInstance(ctx context.Context, client *klausoci.Client, resolver *config.SourceResolver, name string, cfg *config.Config, paths *config.Paths) (string, []string, error) {
+	personalityDir := ""
+	warnings := orchestrator.FloatingRefWarnings(cfg)
 	if cfg.Personality != "" {
 		if err := config.EnsureDir(paths.PersonalitiesDir); err != nil {
-			return nil, fmt.Errorf("creating personalities directory: %w", err)
+			return "", nil, fmt.Errorf("creating personalities directory: %w", err)
 		}
-		pr, err := orchestrator.ResolvePersonality(ctx, client, cfg.Personality, paths.PersonalitiesDir, io.Discard)
+		pr, err := orchestrator.ResolvePersonality(ctx, client, resolver, cfg.Personality, paths.PersonalitiesDir, io.Discard)
 		if err != nil {
-			return nil, fmt.Errorf("resolving personality: %w", err)
+			return "", nil, fmt.Errorf("resolving personality: %w", err)
 		}
 		personalityDir = pr.Dir
 		cfg.Plugins = orchestrator.MergePlugins(pr.Spec.Plugins, cfg.Plugins)
+		if err := orchestrator.MergePersonalityEnv(pr, cfg); err != nil {
+			return "", nil, err
+		}
+		warnings = append(warnings, pr.Warnings...)
 		if !cfg.ImageExplicitlySet() && pr.Spec.Toolchain.Repository != "" {
 			resolved, err := client.ResolveToolchainRef(ctx, pr.Spec.Toolchain.Ref())
 			if err != nil {
-				return nil, fmt.Errorf("resolving personality image: %w", err)
+				return "", nil, fmt.Errorf("resolving personality image: %w", err)
 			}
 			cfg.Image = resolved
 		}
 	}
 
-	image := orchestrator.ResolveDefaultImage(ctx, client, cfg.Image, io.Discard)
-	cfg.Image = image
-
 	if err := orchestrator.ResolveSecretRefs(cfg, paths); err != nil {
-		return nil, err
+		return "", nil, err
+	}
+
+	if err := orchestrator.ExpandSystemPrompts(cfg, name); err != nil {
+		return "", nil, err
 	}
 
 	r := renderer.New(paths)
 	if err := r.Render(cfg); err != nil {
-		return nil, fmt.Errorf("rendering config: %w", err)
+		return "", nil, fmt.Errorf("rendering config: %w", err)
 	}
+	return personalityDir, warnings, nil
+}
 
-	if len(cfg.Plugins) > 0 {
-		if err := orchestrator.PullPlugins(ctx, client, cfg.Plugins, paths.PluginsDir, io.Discard); err != nil {
-			return nil, fmt.Errorf("pulling plugins: %w", err)
+// prepareInstance renders the config files of a newly created instance
+// without starting its container. A later start renders them again.
+func prepareInstance(ctx context.Context, name string, sc *server.ServerContext) (*createResult, error) {
+	cfg, workspace, err := loadInstanceConfig(name, sc)
+	if err != nil {
+		return nil, err
+	}
+	_, warnings, err := renderInstance(ctx, orchestrator.NewDefaultClient(), sc.SourceResolver(), name, cfg, sc.InstancePaths(name))
+	if err != nil {
+		return nil, err
+	}
+
+	return &createResult{
+		Instance:    name,
+		Status:      "created",
+		Container:   instance.ContainerName(name),
+		Image:       cfg.Image,
+		Workspace:   workspace,
+		Port:        cfg.Port,
+		Personality: cfg.Personality,
+		Warnings:    warnings,
+	}, nil
+}
+
+// startExistingInstance loads config for a named instance and starts its
+// container. Used by both create and start handlers.
+func startExistingInstance(ctx context.Context, name string, sc *server.ServerContext) (_ *createResult, err error) {
+	ctx, span := sc.StartSpan(ctx, "instance.start", attribute.String("instance.name", name))
+	defer func() {
+		if err == nil {
+			sc.TrackInstance(name)
 		}
+		server.EndSpan(span, err)
+	}()
+
+	paths := sc.InstancePaths(name)
+	cfg, workspace, err := loadInstanceConfig(name, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := sc.DetectRuntime(cfg)
+	if err != nil {
+		return nil, err
 	}
 
+	containerName := instance.ContainerName(name)
+
+	// Clear stale state. A stopped container is reused or replaced when the
+	// container is started below.
+	inst, err := instance.Load(paths)
+	if err == nil && inst.Name != "" {
+		status, sErr := rt.Status(ctx, inst.ContainerName())
+		if sErr == nil && status == "running" {
+			return nil, fmt.Errorf("instance %q is already running (container: %s, MCP: %s)", inst.Name, inst.ContainerName(), inst.AgentURL())
+		}
+		if sErr == nil && status == "paused" {
+			return nil, fmt.Errorf("instance %q is paused; resume it with 'klausctl resume %s'", inst.Name, inst.Name)
+		}
+		_ = instance.Clear(paths)
+	}
+
+	client := orchestrator.NewDefaultClient()
+
+	personalityDir, warnings, err := renderInstance(ctx, client, sc.SourceResolver(), name, cfg, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	image := orchestrator.ResolveDefaultImage(ctx, client, cfg.Image, io.Discard)
+	cfg.Image = image
+
 	runOpts, err := orchestrator.BuildRunOptions(cfg, paths, containerName, image, personalityDir)
 	if err != nil {
 		return nil, fmt.Errorf("building run options: %w", err)
 	}
+	runOpts.Labels = runtime.ManagedLabels(name)
+	if w := cfg.NetworkWarning(); w != "" {
+		log.Printf("Warning: %s", w)
+	}
+	if w := orchestrator.SSHAgentWarning(cfg); w != "" {
+		log.Printf("Warning: %s", w)
+	}
 
-	if err := rt.Pull(ctx, image, io.Discard); err != nil {
-		images, imgErr := rt.Images(ctx, image)
-		if imgErr != nil || len(images) == 0 {
-			return nil, fmt.Errorf("pulling image: %w", err)
+	var pullPlugins orchestrator.PullFunc
+	if len(cfg.Plugins) > 0 {
+		pullPlugins = func(ctx context.Context) (err error) {
+			start := time.Now()
+			ctx, span := sc.StartSpan(ctx, "pull.plugins", attribute.Int("plugins.count", len(cfg.Plugins)))
+			defer func() {
+				sc.Metrics.ObservePull("plugins", time.Since(start))
+				server.EndSpan(span, err)
+			}()
+			if err := orchestrator.PullPlugins(ctx, client, sc.SourceResolver(), cfg.Plugins, paths.PluginsDir, io.Discard); err != nil {
+				return fmt.Errorf("pulling plugins: %w", err)
+			}
+			return nil
 		}
 	}
+	pullImage := func(ctx context.Context) (err error) {
+		start := time.Now()
+		ctx, span := sc.StartSpan(ctx, "pull.image", attribute.String("container.image", image))
+		defer func() {
+			sc.Metrics.ObservePull("image", time.Since(start))
+			server.EndSpan(span, err)
+		}()
+		return orchestrator.PullImage(ctx, rt, image, cfg.PullPolicy, cfg.Platform, io.Discard)
+	}
+	if err := orchestrator.PreStartPull(ctx, !cfg.PreStartPull.Sequential, pullPlugins, pullImage); err != nil {
+		return nil, err
+	}
 
-	containerID, err := rt.Run(ctx, runOpts)
+	containerID, _, err := orchestrator.RunOrReuse(ctx, rt, runOpts)
 	if err != nil {
 		return nil, fmt.Errorf("starting container: %w", err)
 	}
@@ -623,15 +936,17 @@ func startExistingInstance(ctx context.Context, name string, sc *server.ServerCo
 		effectiveWorkspace = cfg.WorktreePath
 	}
 	inst = &instance.Instance{
-		UUID:        instance.NewUUID(),
-		Name:        name,
-		ContainerID: containerID,
-		Runtime:     rt.Name(),
-		Personality: cfg.Personality,
-		Image:       image,
-		Port:        cfg.Port,
-		Workspace:   effectiveWorkspace,
-		StartedAt:   time.Now(),
+		UUID:          instance.NewUUID(),
+		Name:          name,
+		ContainerID:   containerID,
+		Runtime:       rt.Name(),
+		RuntimeHost:   runtime.ResolveHost(rt.Name(), cfg.RuntimeHost),
+		Personality:   cfg.Personality,
+		Image:         image,
+		Port:          cfg.Port,
+		Workspace:     effectiveWorkspace,
+		StartedAt:     time.Now(),
+		RestartPolicy: cfg.RestartPolicy,
 	}
 	if err := inst.Save(paths); err != nil {
 		return nil, fmt.Errorf("saving instance state: %w", err)
@@ -644,11 +959,13 @@ func startExistingInstance(ctx context.Context, name string, sc *server.ServerCo
 		Image:       image,
 		Workspace:   workspace,
 		Port:        cfg.Port,
+		MCP:         inst.AgentURL(),
 		Personality: cfg.Personality,
+		Warnings:    warnings,
 	}, nil
 }
 
-func stopOne(ctx context.Context, name string, sc *server.ServerContext, noArchive bool) (*mcp.CallToolResult, error) {
+func stopOne(ctx context.Context, name string, sc *server.ServerContext, noArchive, keep bool, timeout time.Duration) (*mcp.CallToolResult, error) {
 	paths := sc.InstancePaths(name)
 	inst, err := instance.Load(paths)
 	if err != nil {
@@ -658,10 +975,11 @@ func stopOne(ctx context.Context, name string, sc *server.ServerContext, noArchi
 		})
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
+	rt = sc.TraceRuntime(rt)
 
 	containerName := inst.ContainerName()
 	status, err := rt.Status(ctx, containerName)
@@ -673,16 +991,25 @@ func stopOne(ctx context.Context, name string, sc *server.ServerContext, noArchi
 		})
 	}
 
-	// Archive before stopping.
+	// Record usage and archive before stopping.
+	if status == "running" {
+		mcpRecordUsage(ctx, inst.Name, inst.AgentURL()+"/mcp", sc)
+	}
 	if status == "running" && !noArchive {
 		mcpArchiveBeforeCleanup(ctx, inst, sc)
 	}
 
 	if status == "running" {
-		if err := rt.Stop(ctx, containerName); err != nil {
+		if err := rt.Stop(ctx, containerName, timeout); err != nil {
 			return mcp.NewToolResultError(fmt.Sprintf("stopping container: %v", err)), nil
 		}
 	}
+	if keep {
+		return server.JSONResult(map[string]string{
+			"instance": name,
+			"status":   "stopped (container kept)",
+		})
+	}
 	if err := rt.Remove(ctx, containerName); err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("removing container: %v", err)), nil
 	}
@@ -696,40 +1023,56 @@ func stopOne(ctx context.Context, name string, sc *server.ServerContext, noArchi
 	})
 }
 
-func stopAll(ctx context.Context, sc *server.ServerContext, noArchive bool) (*mcp.CallToolResult, error) {
+func stopAll(ctx context.Context, sc *server.ServerContext, noArchive, keep bool, timeout time.Duration) (*mcp.CallToolResult, error) {
 	instances, err := instance.LoadAll(sc.Paths)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("loading instances: %v", err)), nil
 	}
 
+	var mu sync.Mutex
 	stopped := make([]string, 0, len(instances))
-	for _, inst := range instances {
-		rt, err := runtime.New(inst.Runtime)
+	err = instance.ForEach(ctx, instances, instance.DefaultConcurrency, func(ctx context.Context, inst *instance.Instance) error {
+		rt, err := inst.NewRuntime()
 		if err != nil {
-			continue
+			return nil
 		}
+		rt = sc.TraceRuntime(rt)
 		containerName := inst.ContainerName()
 		status, err := rt.Status(ctx, containerName)
 		if err != nil || status == "" {
 			_ = instance.Clear(sc.InstancePaths(inst.Name))
-			continue
+			return nil
+		}
+		// Record usage and archive before stopping.
+		if status == "running" {
+			mcpRecordUsage(ctx, inst.Name, inst.AgentURL()+"/mcp", sc)
 		}
-		// Archive before stopping.
 		if status == "running" && !noArchive {
 			mcpArchiveBeforeCleanup(ctx, inst, sc)
 		}
 		if status == "running" {
-			if err := rt.Stop(ctx, containerName); err != nil {
-				return mcp.NewToolResultError(fmt.Sprintf("stopping %s: %v", containerName, err)), nil
+			if err := rt.Stop(ctx, containerName, timeout); err != nil {
+				return fmt.Errorf("stopping %s: %w", containerName, err)
 			}
 		}
-		if err := rt.Remove(ctx, containerName); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("removing %s: %v", containerName, err)), nil
-		}
-		if err := instance.Clear(sc.InstancePaths(inst.Name)); err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("clearing state for %s: %v", inst.Name, err)), nil
+		if !keep {
+			if err := rt.Remove(ctx, containerName); err != nil {
+				return fmt.Errorf("removing %s: %w", containerName, err)
+			}
+			if err := instance.Clear(sc.InstancePaths(inst.Name)); err != nil {
+				return fmt.Errorf("clearing state for %s: %w", inst.Name, err)
+			}
 		}
+		sc.UntrackInstance(inst.Name)
+		mu.Lock()
 		stopped = append(stopped, inst.Name)
+		mu.Unlock()
+		return nil
+	})
+	sort.Strings(stopped)
+
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("not all instances could be stopped (stopped: %s):\n%v", strings.Join(stopped, ", "), err)), nil
 	}
 
 	return server.JSONResult(map[string]any{
@@ -746,7 +1089,12 @@ func cleanupContainer(ctx context.Context, name string, inst *instance.Instance)
 
 	candidates := uniqueRuntimes(inst)
 	for _, rtName := range candidates {
-		rt, err := runtime.New(rtName)
+		// The instance's own runtime may run on a remote host.
+		host := ""
+		if inst != nil && rtName == inst.Runtime {
+			host = inst.RuntimeHost
+		}
+		rt, err := runtime.NewWithHost(rtName, host)
 		if err != nil {
 			continue
 		}
@@ -755,7 +1103,7 @@ func cleanupContainer(ctx context.Context, name string, inst *instance.Instance)
 			continue
 		}
 		if status == "running" {
-			if err := rt.Stop(ctx, containerName); err != nil {
+			if err := rt.Stop(ctx, containerName, runtime.DefaultStopTimeout); err != nil {
 				return fmt.Errorf("stopping container via %s: %w", rtName, err)
 			}
 		}
@@ -814,6 +1162,14 @@ func mcpArchiveBeforeCleanup(ctx context.Context, inst *instance.Instance, sc *s
 	}
 }
 
+// mcpRecordUsage appends the last run of the agent at baseURL to the usage
+// history of the named instance. Best-effort: logs and continues.
+func mcpRecordUsage(ctx context.Context, name, baseURL string, sc *server.ServerContext) {
+	if err := usage.Record(ctx, sc.MCPClient, name, baseURL, sc.InstancePaths(name).UsageFile); err != nil {
+		log.Printf("Warning: failed to record usage of %q: %v", name, err)
+	}
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%ds", int(d.Seconds()))
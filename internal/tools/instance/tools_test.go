@@ -3,19 +3,28 @@ package instance
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	mcpserver "github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"gopkg.in/yaml.v3"
 
 	"github.com/giantswarm/klausctl/internal/server"
 	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
 	"github.com/giantswarm/klausctl/pkg/mcpclient"
+	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 // hideContainerRuntimes points PATH at an empty directory so runtime auto
@@ -62,6 +71,61 @@ func TestHandleListEmpty(t *testing.T) {
 	assertJSONArray(t, result, 0)
 }
 
+func TestHandleListPaginated(t *testing.T) {
+	sc := testServerContext(t)
+
+	for _, name := range []string{"a", "b", "c"} {
+		instanceDir := filepath.Join(sc.Paths.InstancesDir, name)
+		if err := os.MkdirAll(instanceDir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.DefaultConfig()
+		cfg.Image = "example.com/test:v1"
+		cfg.Workspace = "/tmp"
+		data, err := cfg.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(instanceDir, "config.yaml"), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := callToolRequest(map[string]any{"limit": float64(2), "offset": float64(1)})
+	result, err := handleList(context.Background(), req, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var page struct {
+		Items      []listEntry `json:"items"`
+		NextOffset int         `json:"nextOffset"`
+		Total      int         `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(extractResultText(t, result)), &page); err != nil {
+		t.Fatalf("expected paginated envelope: %v", err)
+	}
+	if page.Total != 3 {
+		t.Errorf("total = %d, want 3", page.Total)
+	}
+	if len(page.Items) != 2 || page.Items[0].Name != "b" || page.Items[1].Name != "c" {
+		t.Errorf("items = %+v, want b and c", page.Items)
+	}
+	if page.NextOffset != 0 {
+		t.Errorf("nextOffset = %d, want none on the last page", page.NextOffset)
+	}
+}
+
+func TestHandleListNegativeLimit(t *testing.T) {
+	sc := testServerContext(t)
+
+	result, err := handleList(context.Background(), callToolRequest(map[string]any{"limit": float64(-1)}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIsError(t, result)
+}
+
 func TestHandleStatusMissingInstance(t *testing.T) {
 	sc := testServerContext(t)
 
@@ -112,6 +176,68 @@ func TestHandleStatusStoppedInstance(t *testing.T) {
 	}
 }
 
+func TestHandleStatusAll(t *testing.T) {
+	sc := testServerContext(t)
+
+	for _, name := range []string{"b", "a"} {
+		instanceDir := filepath.Join(sc.Paths.InstancesDir, name)
+		if err := os.MkdirAll(instanceDir, 0o750); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.DefaultConfig()
+		cfg.Image = "example.com/test:v1"
+		cfg.Workspace = "/tmp"
+		data, err := cfg.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(instanceDir, "config.yaml"), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range []map[string]any{{}, {"all": true}} {
+		result, err := handleStatus(context.Background(), callToolRequest(args), sc)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var statuses []statusResult
+		if err := json.Unmarshal([]byte(extractResultText(t, result)), &statuses); err != nil {
+			t.Fatalf("expected JSON array: %v", err)
+		}
+		if len(statuses) != 2 || statuses[0].Instance != "a" || statuses[1].Instance != "b" {
+			t.Fatalf("statuses = %+v, want a and b sorted by name", statuses)
+		}
+		for _, st := range statuses {
+			if st.Status != "stopped" {
+				t.Errorf("status of %s = %q, want stopped", st.Instance, st.Status)
+			}
+		}
+	}
+}
+
+func TestHandleStatusAllEmpty(t *testing.T) {
+	sc := testServerContext(t)
+
+	result, err := handleStatus(context.Background(), callToolRequest(map[string]any{"all": true}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertJSONArray(t, result, 0)
+}
+
+func TestHandleStatusNameAndAllMutuallyExclusive(t *testing.T) {
+	sc := testServerContext(t)
+
+	req := callToolRequest(map[string]any{"name": "dev", "all": true})
+	result, err := handleStatus(context.Background(), req, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIsError(t, result)
+}
+
 func TestHandleLogsMissingInstance(t *testing.T) {
 	sc := testServerContext(t)
 
@@ -124,6 +250,88 @@ func TestHandleLogsMissingInstance(t *testing.T) {
 	assertIsError(t, result)
 }
 
+// logsRuntimeStub is a runtime that has one container with fixed logs.
+type logsRuntimeStub struct {
+	runtime.Runtime
+	name      string
+	container string
+	logs      string
+}
+
+func (r *logsRuntimeStub) Name() string { return r.name }
+
+func (r *logsRuntimeStub) Status(_ context.Context, containerName string) (string, error) {
+	if containerName == r.container {
+		return "running", nil
+	}
+	return "", nil
+}
+
+func (r *logsRuntimeStub) LogsCapture(_ context.Context, containerName string, _ int) (string, error) {
+	if containerName != r.container {
+		return "", fmt.Errorf("no such container: %s", containerName)
+	}
+	return r.logs, nil
+}
+
+func stubProbeRuntime(t *testing.T, runtimes ...*logsRuntimeStub) {
+	t.Helper()
+	orig := probeRuntime
+	t.Cleanup(func() { probeRuntime = orig })
+	probeRuntime = func(name string) (runtime.Runtime, error) {
+		for _, rt := range runtimes {
+			if rt.name == name {
+				return rt, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported runtime %q", name)
+	}
+}
+
+func TestHandleLogsProbesRuntimesWithoutState(t *testing.T) {
+	sc := testServerContext(t)
+	stubProbeRuntime(t,
+		&logsRuntimeStub{name: "docker"},
+		&logsRuntimeStub{name: "podman", container: "klausctl-dev", logs: "hello\n{\"type\":\"assistant\"}\n"},
+	)
+
+	result, err := handleLogs(context.Background(), callToolRequest(map[string]any{"name": "dev"}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", extractResultText(t, result))
+	}
+	if got := extractResultText(t, result); got != "hello\n{\"type\":\"assistant\"}\n" {
+		t.Errorf("logs = %q", got)
+	}
+
+	result, err = handleLogs(context.Background(), callToolRequest(map[string]any{"name": "dev", "parse": true}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := extractResultText(t, result); !strings.Contains(got, `"hello"`) || !strings.Contains(got, `"type": "assistant"`) {
+		t.Errorf("parsed logs = %s", got)
+	}
+}
+
+func TestHandleLogsNoContainerInAnyRuntime(t *testing.T) {
+	sc := testServerContext(t)
+	stubProbeRuntime(t, &logsRuntimeStub{name: "docker"}, &logsRuntimeStub{name: "podman"})
+
+	result, err := handleLogs(context.Background(), callToolRequest(map[string]any{"name": "dev"}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIsError(t, result)
+	text := extractResultText(t, result)
+	for _, want := range []string{"docker", "podman", "klausctl-dev"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("error %q does not mention %s", text, want)
+		}
+	}
+}
+
 func TestHandleDeleteMissingInstance(t *testing.T) {
 	sc := testServerContext(t)
 
@@ -263,6 +471,27 @@ func TestHandleCreateInvalidName(t *testing.T) {
 	assertIsError(t, result)
 }
 
+func TestHandleCreateUnknownSource(t *testing.T) {
+	sc := testServerContext(t)
+
+	req := callToolRequest(map[string]any{
+		"name":           "dev",
+		"workspace":      t.TempDir(),
+		"personality":    "sre",
+		"source":         "nonexistent",
+		"generateSuffix": false,
+	})
+	result, err := handleCreate(context.Background(), req, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertIsError(t, result)
+	if text := extractResultText(t, result); !strings.Contains(text, "nonexistent") {
+		t.Errorf("error should name the unknown source, got: %s", text)
+	}
+}
+
 func TestHandleCreateDuplicateInstance(t *testing.T) {
 	sc := testServerContext(t)
 
@@ -296,6 +525,69 @@ func TestHandleCreateDuplicateInstance(t *testing.T) {
 	}
 }
 
+func TestHandleCreateIdempotencyKeyReturnsOriginalResult(t *testing.T) {
+	sc := testServerContext(t)
+
+	workspace := filepath.Join(t.TempDir(), "ws")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first create succeeded and recorded its result under the key.
+	instPaths := sc.InstancePaths("existing")
+	if err := os.MkdirAll(instPaths.InstanceDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(instPaths.ConfigFile, []byte("workspace: /tmp\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	original := createResult{
+		Instance:  "existing",
+		Status:    "running",
+		Container: "klausctl-existing",
+		Image:     "example.com/test:v1",
+		Workspace: workspace,
+		Port:      8080,
+	}
+	if err := instance.SaveCreateRecord(instPaths, "retry-1", original); err != nil {
+		t.Fatal(err)
+	}
+
+	args := map[string]any{
+		"name":           "existing",
+		"workspace":      workspace,
+		"generateSuffix": false,
+		"idempotencyKey": "retry-1",
+	}
+	for attempt := 1; attempt <= 2; attempt++ {
+		result, err := handleCreate(context.Background(), callToolRequest(args), sc)
+		if err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", attempt, err)
+		}
+		if result.IsError {
+			t.Fatalf("attempt %d: expected original result, got error: %s", attempt, extractResultText(t, result))
+		}
+		var got createResult
+		if err := json.Unmarshal([]byte(extractResultText(t, result)), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, original) {
+			t.Errorf("attempt %d: result = %+v, want %+v", attempt, got, original)
+		}
+	}
+
+	// A different key still hits the name collision.
+	args["idempotencyKey"] = "retry-2"
+	result, err := handleCreate(context.Background(), callToolRequest(args), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertIsError(t, result)
+	if text := extractResultText(t, result); !strings.Contains(text, "already exists") {
+		t.Fatalf("expected collision error, got: %s", text)
+	}
+}
+
 func TestHandleCreateMCPCollisionStoppedWithoutConfirm(t *testing.T) {
 	sc := testServerContext(t)
 
@@ -375,6 +667,50 @@ func TestHandleCreateMCPCollisionStoppedWithConfirm(t *testing.T) {
 	}
 }
 
+func TestHandleCreateMCPReplaceRestoresOnFailure(t *testing.T) {
+	sc := testServerContext(t)
+	hideContainerRuntimes(t)
+
+	workspace := filepath.Join(t.TempDir(), "ws")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	instanceDir := filepath.Join(sc.Paths.InstancesDir, "stopped")
+	if err := os.MkdirAll(instanceDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	markerFile := filepath.Join(instanceDir, "old-marker.txt")
+	if err := os.WriteFile(markerFile, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(instanceDir, "config.yaml"), []byte("workspace: /tmp\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// replace needs neither confirm nor generateSuffix: false.
+	req := callToolRequest(map[string]any{
+		"name":      "stopped",
+		"workspace": workspace,
+		"replace":   true,
+	})
+	result, err := handleCreate(context.Background(), req, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Create fails at startExistingInstance (no runtime), so the previous
+	// instance must be moved back into place.
+	assertIsError(t, result)
+	text := extractResultText(t, result)
+	if !strings.Contains(text, "was restored") {
+		t.Fatalf("expected error to report the restored instance, got: %s", text)
+	}
+	if _, err := os.Stat(markerFile); err != nil {
+		t.Fatalf("expected previous instance to be restored: %v", err)
+	}
+}
+
 func TestHandleCreateMCPCollisionSuffixAvoidsCollision(t *testing.T) {
 	sc := testServerContext(t)
 	hideContainerRuntimes(t)
@@ -569,6 +905,51 @@ func TestHandleCreateGitHttpsInsteadOfSsh(t *testing.T) {
 	}
 }
 
+func TestHandleCreateWithoutStart(t *testing.T) {
+	sc := testServerContext(t)
+	// Without a runtime, any attempt to create the container fails.
+	hideContainerRuntimes(t)
+
+	workspace := filepath.Join(t.TempDir(), "ws")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	req := callToolRequest(map[string]any{
+		"name":           "later",
+		"workspace":      workspace,
+		"generateSuffix": false,
+		"noIsolate":      true,
+		"start":          false,
+	})
+	result, err := handleCreate(context.Background(), req, sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected tool error: %s", extractResultText(t, result))
+	}
+
+	var got createResult
+	if err := json.Unmarshal([]byte(extractResultText(t, result)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Instance != "later" || got.Status != "created" {
+		t.Errorf("result = %+v, want instance later with status created", got)
+	}
+
+	paths := sc.InstancePaths("later")
+	if _, err := os.Stat(paths.ConfigFile); err != nil {
+		t.Errorf("config not persisted: %v", err)
+	}
+	if _, err := os.Stat(paths.RenderedDir); err != nil {
+		t.Errorf("config files not rendered: %v", err)
+	}
+	if _, err := instance.Load(paths); err == nil {
+		t.Error("expected no instance state, as no container was started")
+	}
+}
+
 func TestHandleCreateModeChat(t *testing.T) {
 	sc := testServerContext(t)
 
@@ -877,3 +1258,140 @@ func extractResultText(t *testing.T, result *mcp.CallToolResult) string {
 	}
 	return textContent.Text
 }
+
+func TestCreateResultWarnings(t *testing.T) {
+	data, err := json.Marshal(createResult{Instance: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "warnings") {
+		t.Errorf("expected warnings to be omitted when empty, got %s", data)
+	}
+
+	data, err = json.Marshal(createResult{Instance: "dev", Warnings: []string{"plugin gs-sre: not found in registry"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	warnings, ok := got["warnings"].([]any)
+	if !ok || len(warnings) != 1 || warnings[0] != "plugin gs-sre: not found in registry" {
+		t.Errorf("warnings = %v", got["warnings"])
+	}
+}
+
+func TestRenderInstanceWarnsAboutFloatingRefs(t *testing.T) {
+	sc := testServerContext(t)
+	cfg, err := config.Parse([]byte(`workspace: /tmp
+plugins:
+  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, warnings, err := renderInstance(context.Background(), nil, sc.SourceResolver(), "dev", cfg, sc.InstancePaths("dev"))
+	if err != nil {
+		t.Fatalf("renderInstance() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "plugin gs-base uses a floating tag") {
+		t.Errorf("warnings = %q, want the floating plugin", warnings)
+	}
+}
+
+func TestHandleCreateRecordsSpans(t *testing.T) {
+	sc := testServerContext(t)
+	// Without a runtime, starting the container fails after the create
+	// flow reached startExistingInstance.
+	hideContainerRuntimes(t)
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	sc.Tracer = tp.Tracer(server.TracerName)
+
+	workspace := filepath.Join(t.TempDir(), "ws")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	req := callToolRequest(map[string]any{
+		"name":           "traced",
+		"workspace":      workspace,
+		"generateSuffix": false,
+		"noIsolate":      true,
+	})
+	if _, err := handleCreate(context.Background(), req, sc); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, s := range exporter.GetSpans() {
+		spans[s.Name] = s
+	}
+	create, ok := spans["instance.create"]
+	if !ok {
+		t.Fatalf("no instance.create span in %v", exporter.GetSpans())
+	}
+	start, ok := spans["instance.start"]
+	if !ok {
+		t.Fatalf("no instance.start span in %v", exporter.GetSpans())
+	}
+	if start.Parent.SpanID() != create.SpanContext.SpanID() {
+		t.Error("instance.start is not a child of instance.create")
+	}
+	if start.Status.Code != codes.Error {
+		t.Errorf("instance.start status = %v, want error without a runtime", start.Status.Code)
+	}
+}
+
+func TestStopStartedInstancesStopsTrackedInstances(t *testing.T) {
+	sc := testServerContext(t)
+	sc.TrackInstance("dev")
+	sc.TrackInstance("ci")
+	sc.TrackInstance("gone")
+	sc.UntrackInstance("gone")
+
+	var mu sync.Mutex
+	var stopped []string
+	orig := stopOnExit
+	t.Cleanup(func() { stopOnExit = orig })
+	stopOnExit = func(_ context.Context, name string, _ *server.ServerContext) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = append(stopped, name)
+		return nil
+	}
+
+	sc.OnShutdown(func(ctx context.Context) error {
+		return StopStartedInstances(ctx, sc)
+	})
+	if err := sc.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	sort.Strings(stopped)
+	if strings.Join(stopped, ",") != "ci,dev" {
+		t.Errorf("stopped = %v, want [ci dev]", stopped)
+	}
+	if tracked := sc.TrackedInstances(); len(tracked) != 0 {
+		t.Errorf("still tracked after shutdown: %v", tracked)
+	}
+}
+
+func TestHandleStopUntracksInstance(t *testing.T) {
+	sc := testServerContext(t)
+	sc.TrackInstance("dev")
+
+	result, err := handleStop(context.Background(), callToolRequest(map[string]any{"name": "dev"}), sc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", extractResultText(t, result))
+	}
+	if tracked := sc.TrackedInstances(); len(tracked) != 0 {
+		t.Errorf("still tracked after stop: %v", tracked)
+	}
+}
@@ -91,3 +91,55 @@ func WaitForReady(ctx context.Context, client *http.Client, baseURL string) erro
 		}
 	}
 }
+
+// Probe configures a readiness probe against the agent's HTTP port.
+type Probe struct {
+	// Path is the HTTP path to poll, e.g. "/".
+	Path string
+	// Interval is the time between polls.
+	Interval time.Duration
+	// Timeout is how long to poll before giving up.
+	Timeout time.Duration
+}
+
+// WaitForProbe polls baseURL+probe.Path every probe.Interval until it
+// responds with a non-5xx status, probe.Timeout elapses or the context is
+// cancelled. The zero Probe falls back to WaitForReady.
+func WaitForProbe(ctx context.Context, client *http.Client, baseURL string, probe Probe) error {
+	if probe == (Probe{}) {
+		return WaitForReady(ctx, client, baseURL)
+	}
+
+	url := baseURL + probe.Path
+	deadline := time.Now().Add(probe.Timeout)
+
+	for {
+		if probeReady(ctx, client, url) {
+			return nil
+		}
+		if time.Now().Add(probe.Interval).After(deadline) {
+			return fmt.Errorf("timed out after %s waiting for agent at %s", probe.Timeout, url)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(probe.Interval):
+		}
+	}
+}
+
+// probeReady reports whether url responds with a non-5xx status.
+func probeReady(ctx context.Context, client *http.Client, url string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxStatusResponseBytes))
+	_ = resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
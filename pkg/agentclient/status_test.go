@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -116,3 +117,40 @@ func TestFetchStatusIdleAgent(t *testing.T) {
 		t.Errorf("session_id = %q, want empty", got.Agent.SessionID)
 	}
 }
+
+func TestWaitForProbeReadyAfterPolls(t *testing.T) {
+	const readyAfter = 3
+	var polls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			http.NotFound(w, r)
+			return
+		}
+		if polls.Add(1) < readyAfter {
+			http.Error(w, "starting", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	probe := Probe{Path: "/healthz", Interval: 10 * time.Millisecond, Timeout: 5 * time.Second}
+	if err := WaitForProbe(context.Background(), srv.Client(), srv.URL, probe); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := polls.Load(); got != readyAfter {
+		t.Errorf("polls = %d, want %d", got, readyAfter)
+	}
+}
+
+func TestWaitForProbeTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "starting", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	probe := Probe{Path: "/", Interval: 10 * time.Millisecond, Timeout: 50 * time.Millisecond}
+	if err := WaitForProbe(context.Background(), srv.Client(), srv.URL, probe); err == nil {
+		t.Fatal("expected timeout error")
+	}
+}
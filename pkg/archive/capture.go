@@ -20,7 +20,7 @@ func Capture(ctx context.Context, client *mcpclient.Client, inst *instance.Insta
 		return nil // already archived
 	}
 
-	baseURL := fmt.Sprintf("http://localhost:%d/mcp", inst.Port)
+	baseURL := inst.AgentURL() + "/mcp"
 
 	toolResult, err := client.Result(ctx, inst.Name, baseURL, true)
 	if err != nil {
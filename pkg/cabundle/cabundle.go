@@ -0,0 +1,122 @@
+// Package cabundle configures additional CA certificates that klausctl
+// trusts for TLS connections to registries and MCP servers, for private
+// registries and corporate proxies using internal CAs.
+//
+// Like pkg/ocicache, the configuration is process-wide: cmd/root.go calls
+// Configure once after global flags are parsed. The klaus-oci client talks
+// to registries through http.DefaultClient, so Configure installs the CAs
+// on http.DefaultTransport; HTTPClient returns a dedicated client for code
+// that builds its own, such as pkg/mcpclient.
+package cabundle
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// EnvBundle is the env var naming a PEM CA bundle file. It is the env
+// counterpart of the --ca-file flag.
+const EnvBundle = "KLAUSCTL_CA_BUNDLE"
+
+var (
+	mu     sync.RWMutex
+	tlsCfg *tls.Config
+)
+
+// Configure trusts the PEM certificates in file and in the .pem, .crt and
+// .cer files of dir in addition to the system roots. An empty file falls
+// back to KLAUSCTL_CA_BUNDLE; with neither a file nor a dir, the system
+// roots are used alone. It fails when a CA file cannot be read or holds no
+// certificates.
+func Configure(file, dir string) error {
+	file = strings.TrimSpace(file)
+	if file == "" {
+		file = strings.TrimSpace(os.Getenv(EnvBundle))
+	}
+	dir = strings.TrimSpace(dir)
+
+	var cfg *tls.Config
+	if file != "" || dir != "" {
+		pool, err := LoadPool(file, dir)
+		if err != nil {
+			return err
+		}
+		cfg = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	tlsCfg = cfg
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.TLSClientConfig = cfg
+	}
+	return nil
+}
+
+// LoadPool returns the system cert pool extended with the certificates in
+// file and dir (see Configure). Either may be empty.
+func LoadPool(file, dir string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+
+	files := make([]string, 0, 1)
+	if file != "" {
+		files = append(files, file)
+	}
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA directory: %w", err)
+		}
+		for _, e := range entries {
+			switch strings.ToLower(filepath.Ext(e.Name())) {
+			case ".pem", ".crt", ".cer":
+				if !e.IsDir() {
+					files = append(files, filepath.Join(dir, e.Name()))
+				}
+			}
+		}
+	}
+
+	for _, f := range files {
+		data, err := os.ReadFile(f) // #nosec G304 -- user-supplied CA path
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("CA file %s contains no PEM certificates", f)
+		}
+	}
+	return pool, nil
+}
+
+// TLSConfig returns the configured TLS settings, or nil when no custom CAs
+// are configured.
+func TLSConfig() *tls.Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	if tlsCfg == nil {
+		return nil
+	}
+	return tlsCfg.Clone()
+}
+
+// HTTPClient returns an HTTP client trusting the configured CAs, or nil
+// when no custom CAs are configured so callers keep their default client.
+func HTTPClient() *http.Client {
+	cfg := TLSConfig()
+	if cfg == nil {
+		return nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	return &http.Client{Transport: transport}
+}
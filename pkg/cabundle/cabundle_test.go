@@ -0,0 +1,99 @@
+package cabundle
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeServerCA writes the certificate of srv as a PEM file in dir.
+func writeServerCA(t *testing.T, srv *httptest.Server, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func resetConfig(t *testing.T) {
+	t.Helper()
+	t.Setenv(EnvBundle, "")
+	t.Cleanup(func() { _ = Configure("", "") })
+}
+
+func TestHTTPClientTrustsCustomCA(t *testing.T) {
+	resetConfig(t)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	if HTTPClient() != nil {
+		t.Fatal("HTTPClient() without custom CAs should be nil")
+	}
+
+	if err := Configure(writeServerCA(t, srv, t.TempDir()), ""); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	client := HTTPClient()
+	if client == nil {
+		t.Fatal("HTTPClient() = nil after Configure")
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET with custom CA: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// The default client picks the CAs up as well, for klaus-oci.
+	resp, err = http.DefaultClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("GET with default client: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestConfigureFromDirAndEnv(t *testing.T) {
+	resetConfig(t)
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	dir := t.TempDir()
+	writeServerCA(t, srv, dir)
+	if err := Configure("", dir); err != nil {
+		t.Fatalf("Configure(dir) error = %v", err)
+	}
+	if TLSConfig() == nil {
+		t.Error("TLSConfig() = nil after Configure(dir)")
+	}
+
+	t.Setenv(EnvBundle, writeServerCA(t, srv, t.TempDir()))
+	if err := Configure("", ""); err != nil {
+		t.Fatalf("Configure() with %s error = %v", EnvBundle, err)
+	}
+	if TLSConfig() == nil {
+		t.Errorf("TLSConfig() = nil with %s set", EnvBundle)
+	}
+}
+
+func TestConfigureFailsOnBadCAFile(t *testing.T) {
+	resetConfig(t)
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	if err := Configure(missing, ""); err == nil || !strings.Contains(err.Error(), "missing.pem") {
+		t.Errorf("Configure(missing) error = %v, want one naming the file", err)
+	}
+
+	garbage := filepath.Join(t.TempDir(), "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(garbage, ""); err == nil {
+		t.Error("Configure(garbage) succeeded, want an error")
+	}
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeFile is the subset of the docker-compose file format that klausctl
+// understands when adopting a service.
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+// composeService is a single compose service definition. Only the fields that
+// map onto a klaus instance are decoded explicitly; everything else is kept in
+// Extra so that it can be reported as unsupported.
+type composeService struct {
+	Image       string         `yaml:"image"`
+	Environment yaml.Node      `yaml:"environment"`
+	Ports       []yaml.Node    `yaml:"ports"`
+	Volumes     []yaml.Node    `yaml:"volumes"`
+	Extra       map[string]any `yaml:",inline"`
+}
+
+// ComposeAdoption is the result of translating a compose service into a
+// klaus instance config.
+type ComposeAdoption struct {
+	// Config is the translated instance config. Defaults are applied but the
+	// config is not validated; callers still need to set a port when
+	// HostPort is zero and call Validate.
+	Config *Config
+	// HostPort is the host port published for the container's port 8080,
+	// or zero when the service does not publish it.
+	HostPort int
+	// Warnings lists compose features that were ignored or only partially
+	// translated.
+	Warnings []string
+}
+
+// AdoptComposeService reads the compose file at path and translates the named
+// service into a klaus instance config. Relative bind mount sources are
+// resolved against the directory containing the compose file, matching
+// docker compose behaviour.
+func AdoptComposeService(path, service string) (*ComposeAdoption, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- user-supplied or trusted local path; not exposed to untrusted input
+	if err != nil {
+		return nil, fmt.Errorf("reading compose file: %w", err)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("resolving compose file directory: %w", err)
+	}
+
+	return TranslateComposeService(data, service, baseDir)
+}
+
+// TranslateComposeService translates the named service of a compose document
+// into a klaus instance config. baseDir is used to resolve relative bind
+// mount sources.
+func TranslateComposeService(data []byte, service, baseDir string) (*ComposeAdoption, error) {
+	var cf composeFile
+	if err := yaml.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("parsing compose file: %w", err)
+	}
+
+	svc, ok := cf.Services[service]
+	if !ok {
+		names := make([]string, 0, len(cf.Services))
+		for name := range cf.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("compose file defines no services")
+		}
+		return nil, fmt.Errorf("service %q not found in compose file; available: %s", service, strings.Join(names, ", "))
+	}
+
+	result := &ComposeAdoption{Config: DefaultConfig()}
+	cfg := result.Config
+
+	if svc.Image != "" {
+		cfg.Image = svc.Image
+		cfg.imageFromConfig = true
+	} else {
+		result.warnf("service has no image; using the default klaus image")
+	}
+
+	env, forward, err := composeEnvironment(&svc.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if len(env) > 0 {
+		cfg.EnvVars = env
+	}
+	for _, name := range forward {
+		cfg.EnvForward = append(cfg.EnvForward, name)
+		result.warnf("environment variable %q has no value; it is forwarded from the host via envForward", name)
+	}
+
+	for _, node := range svc.Ports {
+		result.adoptPort(node)
+	}
+
+	for _, node := range svc.Volumes {
+		if err := result.adoptVolume(node, baseDir); err != nil {
+			return nil, err
+		}
+	}
+
+	extra := make([]string, 0, len(svc.Extra))
+	for key := range svc.Extra {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	for _, key := range extra {
+		result.warnf("compose field %q is not supported and was ignored", key)
+	}
+
+	return result, nil
+}
+
+func (a *ComposeAdoption) warnf(format string, args ...any) {
+	a.Warnings = append(a.Warnings, fmt.Sprintf(format, args...))
+}
+
+// composeEnvironment decodes the environment block, which compose allows in
+// either map ("KEY: value") or list ("KEY=value") form. Entries without a
+// value ("KEY:" or a bare "KEY") pass the variable through from the host and
+// are returned separately as forward names.
+func composeEnvironment(node *yaml.Node) (env map[string]string, forward []string, err error) {
+	switch node.Kind {
+	case 0:
+		return nil, nil, nil
+	case yaml.MappingNode:
+		var m map[string]*string
+		if err := node.Decode(&m); err != nil {
+			return nil, nil, fmt.Errorf("parsing environment: %w", err)
+		}
+		env = make(map[string]string, len(m))
+		for k, v := range m {
+			if v == nil {
+				forward = append(forward, k)
+				continue
+			}
+			env[k] = *v
+		}
+		sort.Strings(forward)
+		return env, forward, nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := node.Decode(&list); err != nil {
+			return nil, nil, fmt.Errorf("parsing environment: %w", err)
+		}
+		env = make(map[string]string, len(list))
+		for _, kv := range list {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				forward = append(forward, k)
+				continue
+			}
+			env[k] = v
+		}
+		return env, forward, nil
+	default:
+		return nil, nil, fmt.Errorf("environment must be a map or a list")
+	}
+}
+
+// adoptPort maps a compose port entry onto the instance port. Only a host
+// mapping to container port 8080 (the klaus MCP endpoint) is meaningful.
+// Entries that cannot be translated are reported as warnings.
+func (a *ComposeAdoption) adoptPort(node yaml.Node) {
+	if node.Kind != yaml.ScalarNode {
+		a.warnf("long-form port definitions are not supported and were ignored")
+		return
+	}
+
+	spec := node.Value
+	if i := strings.Index(spec, "/"); i >= 0 {
+		spec = spec[:i]
+	}
+
+	// An IPv6 host IP is bracketed ("[::1]:9191:8080") and contains colons
+	// of its own, so strip it before splitting the remaining fields.
+	hostIP := ""
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]:")
+		if end < 0 {
+			a.warnf("port %q could not be parsed and was ignored", node.Value)
+			return
+		}
+		hostIP = spec[1:end]
+		spec = spec[end+2:]
+	}
+
+	parts := strings.Split(spec, ":")
+	switch {
+	case len(parts) == 1:
+		a.warnf("port %q publishes no host port and was ignored", node.Value)
+		return
+	case len(parts) == 3 && hostIP == "":
+		hostIP = parts[0]
+		parts = parts[1:]
+	case len(parts) != 2:
+		a.warnf("port %q could not be parsed and was ignored", node.Value)
+		return
+	}
+	hostPart, containerPart := parts[0], parts[1]
+
+	containerStart, containerEnd, err := parsePortRange(containerPart)
+	if err != nil || containerStart > 8080 || containerEnd < 8080 {
+		a.warnf("port %q does not target the klaus MCP port 8080 and was ignored", node.Value)
+		return
+	}
+	if a.HostPort != 0 {
+		a.warnf("port %q ignored; host port %d is already mapped to 8080", node.Value, a.HostPort)
+		return
+	}
+
+	hostStart, hostEnd, err := parsePortRange(hostPart)
+	if err != nil {
+		a.warnf("host port in %q is not a number (variable interpolation is not supported); a free port will be selected", node.Value)
+		return
+	}
+
+	port := hostStart
+	switch {
+	case containerStart != containerEnd && hostEnd-hostStart != containerEnd-containerStart:
+		a.warnf("port range %q maps ranges of different sizes and was ignored", node.Value)
+		return
+	case containerStart != containerEnd:
+		port = hostStart + 8080 - containerStart
+		a.warnf("only the mapping for port 8080 was adopted from port range %q", node.Value)
+	case hostStart != hostEnd:
+		a.warnf("host port range in %q is not supported; using port %d", node.Value, hostStart)
+	}
+
+	if hostIP != "" {
+		a.warnf("host IP in port %q is not supported; klaus binds to 127.0.0.1", node.Value)
+	}
+	a.HostPort = port
+	a.Config.Port = port
+}
+
+// parsePortRange parses a compose port or port range ("8080" or
+// "8080-8090") and returns its inclusive bounds.
+func parsePortRange(s string) (start, end int, err error) {
+	lo, hi, isRange := strings.Cut(s, "-")
+	start, err = strconv.Atoi(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err = strconv.Atoi(hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("invalid port range %q", s)
+	}
+	return start, end, nil
+}
+
+// adoptVolume maps a compose bind mount targeting /workspace onto the
+// instance workspace. Other mounts have no klaus equivalent.
+func (a *ComposeAdoption) adoptVolume(node yaml.Node, baseDir string) error {
+	if node.Kind != yaml.ScalarNode {
+		a.warnf("long-form volume definitions are not supported and were ignored")
+		return nil
+	}
+
+	parts := strings.Split(node.Value, ":")
+	if len(parts) < 2 {
+		a.warnf("anonymous volume %q is not supported and was ignored", node.Value)
+		return nil
+	}
+
+	source, target := parts[0], parts[1]
+	if target != "/workspace" {
+		a.warnf("volume %q does not target /workspace and was ignored", node.Value)
+		return nil
+	}
+	if !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, "~") {
+		a.warnf("named volume %q cannot be used as a workspace and was ignored", node.Value)
+		return nil
+	}
+	if a.Config.Workspace != "" {
+		a.warnf("volume %q ignored; workspace is already set to %s", node.Value, a.Config.Workspace)
+		return nil
+	}
+	if len(parts) > 2 {
+		a.warnf("mount options in %q are not supported and were ignored", node.Value)
+	}
+
+	source = ExpandPath(source)
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(baseDir, source)
+	}
+	a.Config.Workspace = filepath.Clean(source)
+	return nil
+}
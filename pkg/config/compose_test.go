@@ -0,0 +1,194 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranslateComposeServiceMinimal(t *testing.T) {
+	compose := `
+services:
+  agent:
+    image: ghcr.io/example/klaus:v1.2.3
+    environment:
+      FOO: bar
+      EMPTY:
+    ports:
+      - "9191:8080"
+    volumes:
+      - ./src:/workspace
+`
+	got, err := TranslateComposeService([]byte(compose), "agent", "/projects/demo")
+	if err != nil {
+		t.Fatalf("TranslateComposeService() error = %v", err)
+	}
+	if len(got.Warnings) != 1 || !strings.Contains(got.Warnings[0], `"EMPTY"`) {
+		t.Errorf("expected a single envForward warning for EMPTY, got: %v", got.Warnings)
+	}
+
+	cfg := got.Config
+	if cfg.Image != "ghcr.io/example/klaus:v1.2.3" {
+		t.Errorf("Image = %q", cfg.Image)
+	}
+	if !cfg.ImageExplicitlySet() {
+		t.Error("expected compose image to count as explicitly set")
+	}
+	if cfg.EnvVars["FOO"] != "bar" {
+		t.Errorf("EnvVars[FOO] = %q, want %q", cfg.EnvVars["FOO"], "bar")
+	}
+	if _, ok := cfg.EnvVars["EMPTY"]; ok {
+		t.Error("expected null environment value not to be set as a fixed value")
+	}
+	if len(cfg.EnvForward) != 1 || cfg.EnvForward[0] != "EMPTY" {
+		t.Errorf("EnvForward = %v, want [EMPTY]", cfg.EnvForward)
+	}
+	if got.HostPort != 9191 || cfg.Port != 9191 {
+		t.Errorf("HostPort = %d, Port = %d, want 9191", got.HostPort, cfg.Port)
+	}
+	if cfg.Workspace != "/projects/demo/src" {
+		t.Errorf("Workspace = %q, want %q", cfg.Workspace, "/projects/demo/src")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("translated config does not validate: %v", err)
+	}
+}
+
+func TestTranslateComposeServiceListEnvironment(t *testing.T) {
+	compose := `
+services:
+  agent:
+    image: klaus
+    environment:
+      - A=1
+      - B=two=2
+      - FORWARDED
+`
+	got, err := TranslateComposeService([]byte(compose), "agent", "/tmp")
+	if err != nil {
+		t.Fatalf("TranslateComposeService() error = %v", err)
+	}
+	if got.Config.EnvVars["A"] != "1" || got.Config.EnvVars["B"] != "two=2" {
+		t.Errorf("EnvVars = %v", got.Config.EnvVars)
+	}
+	if _, ok := got.Config.EnvVars["FORWARDED"]; ok {
+		t.Error("expected bare environment name not to be set as a fixed value")
+	}
+	if len(got.Config.EnvForward) != 1 || got.Config.EnvForward[0] != "FORWARDED" {
+		t.Errorf("EnvForward = %v, want [FORWARDED]", got.Config.EnvForward)
+	}
+	if !strings.Contains(strings.Join(got.Warnings, "\n"), `"FORWARDED"`) {
+		t.Errorf("expected warning for forwarded variable, got: %v", got.Warnings)
+	}
+}
+
+func TestTranslateComposeServicePorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		port     string
+		wantPort int
+		wantWarn string
+	}{
+		{name: "plain", port: "9191:8080", wantPort: 9191},
+		{name: "protocol", port: "9191:8080/tcp", wantPort: 9191},
+		{name: "ipv4 host ip", port: "127.0.0.1:9191:8080", wantPort: 9191, wantWarn: "host IP"},
+		{name: "ipv6 host ip", port: "[::1]:9191:8080", wantPort: 9191, wantWarn: "host IP"},
+		{name: "range containing 8080", port: "9000-9001:8079-8080", wantPort: 9001, wantWarn: "only the mapping"},
+		{name: "range mismatch", port: "9000-9002:8079-8080", wantWarn: "different sizes"},
+		{name: "host range", port: "9000-9005:8080", wantPort: 9000, wantWarn: "host port range"},
+		{name: "range without 8080", port: "9000-9001:3000-3001", wantWarn: "does not target"},
+		{name: "interpolated host port", port: "${PORT}:8080", wantWarn: "not a number"},
+		{name: "container only", port: "8080", wantWarn: "publishes no host port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compose := "services:\n  agent:\n    image: klaus\n    ports:\n      - \"" + tt.port + "\"\n"
+			got, err := TranslateComposeService([]byte(compose), "agent", "/tmp")
+			if err != nil {
+				t.Fatalf("TranslateComposeService() error = %v", err)
+			}
+			if got.HostPort != tt.wantPort {
+				t.Errorf("HostPort = %d, want %d", got.HostPort, tt.wantPort)
+			}
+			joined := strings.Join(got.Warnings, "\n")
+			if tt.wantWarn == "" && joined != "" {
+				t.Errorf("unexpected warnings: %s", joined)
+			}
+			if tt.wantWarn != "" && !strings.Contains(joined, tt.wantWarn) {
+				t.Errorf("expected warning containing %q, got: %s", tt.wantWarn, joined)
+			}
+		})
+	}
+}
+
+func TestTranslateComposeServiceWarnsOnUnsupported(t *testing.T) {
+	compose := `
+services:
+  agent:
+    image: klaus
+    command: ["sleep", "infinity"]
+    depends_on: [db]
+    ports:
+      - "5432:5432"
+    volumes:
+      - data:/var/lib/data
+`
+	got, err := TranslateComposeService([]byte(compose), "agent", "/tmp")
+	if err != nil {
+		t.Fatalf("TranslateComposeService() error = %v", err)
+	}
+
+	joined := strings.Join(got.Warnings, "\n")
+	for _, want := range []string{`"command"`, `"depends_on"`, `"5432:5432"`, `"data:/var/lib/data"`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected warning mentioning %s, got:\n%s", want, joined)
+		}
+	}
+	if got.HostPort != 0 {
+		t.Errorf("HostPort = %d, want 0", got.HostPort)
+	}
+	if got.Config.Workspace != "" {
+		t.Errorf("Workspace = %q, want empty", got.Config.Workspace)
+	}
+}
+
+func TestTranslateComposeServiceNotFound(t *testing.T) {
+	compose := `
+services:
+  web:
+    image: nginx
+`
+	_, err := TranslateComposeService([]byte(compose), "agent", "/tmp")
+	if err == nil {
+		t.Fatal("expected error for missing service")
+	}
+	if !strings.Contains(err.Error(), "web") {
+		t.Errorf("expected available services in error, got: %v", err)
+	}
+}
+
+func TestAdoptComposeServiceResolvesRelativeToFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "compose.yml")
+	compose := `
+services:
+  agent:
+    image: klaus
+    volumes:
+      - .:/workspace
+`
+	if err := os.WriteFile(path, []byte(compose), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := AdoptComposeService(path, "agent")
+	if err != nil {
+		t.Fatalf("AdoptComposeService() error = %v", err)
+	}
+	if got.Config.Workspace != dir {
+		t.Errorf("Workspace = %q, want %q", got.Config.Workspace, dir)
+	}
+}
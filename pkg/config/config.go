@@ -3,13 +3,19 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 const (
@@ -30,10 +36,34 @@ func IsDefaultImage(image string) bool {
 // The structure intentionally mirrors the Helm chart values so that knowledge transfers
 // between local, standalone, and operator-managed modes.
 type Config struct {
+	// Version is the schema version of the config file; see
+	// CurrentConfigVersion and 'klausctl config migrate'. Files without it
+	// are version 0.
+	Version int `yaml:"version,omitempty"`
+
 	// Runtime is the container runtime: "docker" or "podman".
 	// Auto-detected if empty.
 	Runtime string `yaml:"runtime,omitempty"`
 
+	// RuntimeHost is the daemon the runtime runs containers on, e.g.
+	// "ssh://user@build-box" or "tcp://10.0.0.5:2376". Empty uses
+	// DOCKER_HOST, or the local daemon. A remote host needs
+	// RuntimeHostSharedPaths and, unless the network is "host",
+	// PublishAddress.
+	RuntimeHost string `yaml:"runtimeHost,omitempty"`
+
+	// RuntimeHostSharedPaths declares that the klausctl config directory
+	// and the workspace exist at the same paths on a remote RuntimeHost,
+	// e.g. through a shared network home. The rendered config, plugins,
+	// personality and secrets are bind-mounted from local paths, so
+	// containers are not started on a remote host without it.
+	RuntimeHostSharedPaths bool `yaml:"runtimeHostSharedPaths,omitempty"`
+
+	// PublishAddress is the host interface address the agent port is
+	// published on. Empty binds to 127.0.0.1; "0.0.0.0" exposes it on all
+	// interfaces, which a remote RuntimeHost needs to reach the agent.
+	PublishAddress string `yaml:"publishAddress,omitempty"`
+
 	// Personality is an OCI reference to a personality artifact that defines
 	// the AI's identity (SOUL.md) and a curated set of plugins. Instance-level
 	// config (image, plugins) composes with and can override personality values.
@@ -49,14 +79,71 @@ type Config struct {
 	// Workspace is the host directory to mount into the container at /workspace.
 	Workspace string `yaml:"workspace"`
 
+	// DefaultWorkspace is the workspace directory used by create when none is
+	// given, instead of the current directory. It is only read from the
+	// klausctl config file (see `klausctl config path`); "~" is expanded.
+	// "auto" uses the root of the git repository containing the current
+	// directory.
+	DefaultWorkspace string `yaml:"defaultWorkspace,omitempty"`
+
 	// WorktreePath is the path to the local git clone created for this instance.
 	// When set, this path is bind-mounted instead of Workspace, and Workspace
 	// stores the original repository path for clone lifecycle management.
 	WorktreePath string `yaml:"worktreePath,omitempty"`
 
-	// Port is the host port mapped to the container's MCP endpoint (8080).
+	// WorkspaceMode is the mount mode of the workspace: "rw" (the default),
+	// "ro" to keep the agent from modifying it, or "cached" and "delegated",
+	// the read-write consistency modes that speed up bind mounts on Docker
+	// Desktop for macOS.
+	WorkspaceMode string `yaml:"workspaceMode,omitempty"`
+
+	// Port is the host port mapped to the container's MCP endpoint
+	// (ContainerPort).
 	Port int `yaml:"port"`
 
+	// ContainerPort is the port the agent listens on inside the container,
+	// for custom images that do not listen on the default 8080. Zero uses
+	// 8080. It is ignored with host networking, where the agent listens on
+	// Port directly.
+	ContainerPort int `yaml:"containerPort,omitempty"`
+
+	// Network is the container network mode passed to the runtime as
+	// --network: "host", "bridge", "none", or the name of an existing network
+	// (e.g. to reach a sidecar). Empty uses the runtime default. With "host",
+	// no port is published and the agent listens on Port directly.
+	Network string `yaml:"network,omitempty"`
+
+	// DNS lists DNS server addresses for the container (--dns), e.g. to
+	// resolve internal hostnames. Empty uses the runtime default.
+	DNS []string `yaml:"dns,omitempty"`
+
+	// ExtraHosts adds "hostname:ip" entries to the container's /etc/hosts
+	// (--add-host). The IP may be "host-gateway" for the host's address.
+	ExtraHosts []string `yaml:"extraHosts,omitempty"`
+
+	// StopTimeout is the number of seconds the agent gets to shut down
+	// after SIGTERM before the runtime kills it with SIGKILL. Zero uses the
+	// runtime default of 10 seconds.
+	StopTimeout int `yaml:"stopTimeout,omitempty"`
+
+	// RestartPolicy is the container restart policy passed to the runtime
+	// as --restart: "no" (the default), "on-failure", "unless-stopped" or
+	// "always". Useful for long-lived agents that should come back after a
+	// crash or host reboot; stopping or deleting the instance still removes
+	// its container.
+	RestartPolicy string `yaml:"restartPolicy,omitempty"`
+
+	// RunAsUser is the user the container runs as. Empty runs it as the
+	// current host uid:gid, so files written to bind mounts belong to the
+	// host user. "root" runs it as 0:0, "image" keeps the user the image
+	// declares, and any other value, such as "1000:1000" or a user name, is
+	// passed to the runtime as --user.
+	RunAsUser string `yaml:"runAsUser,omitempty"`
+
+	// SecurityOpts hardens the container, e.g. for shared or production
+	// hosts.
+	SecurityOpts SecurityOpts `yaml:"securityOpts,omitempty"`
+
 	// Claude contains Claude Code agent configuration.
 	Claude ClaudeConfig `yaml:"claude,omitempty"`
 
@@ -76,18 +163,63 @@ type Config struct {
 	HookScripts map[string]string `yaml:"hookScripts,omitempty"`
 
 	// McpServers defines MCP server entries rendered to .mcp.json format.
+	// Any value may be {"secretRef": "<name>"}, which is replaced by the
+	// named secret from the secret store when the instance starts, so that
+	// tokens stay out of the config file.
 	McpServers map[string]any `yaml:"mcpServers,omitempty"`
 
 	// Plugins references OCI plugins pulled before container start.
 	Plugins []Plugin `yaml:"plugins,omitempty"`
 
+	// InlinePlugins defines plugins in the config itself, keyed by name.
+	// They are rendered to a directory and mounted like pulled plugins,
+	// without a registry, which suits local experimentation.
+	InlinePlugins map[string]InlinePlugin `yaml:"inlinePlugins,omitempty"`
+
+	// PreStartPull controls how the image and plugins are pulled before the
+	// container starts.
+	PreStartPull PreStartPullConfig `yaml:"preStartPull,omitempty"`
+
+	// Health configures the readiness probe run against the instance port
+	// when waiting for a started instance to become ready.
+	Health HealthConfig `yaml:"health,omitempty"`
+
+	// PullPolicy controls whether the image is pulled at start: "always"
+	// (the default) pulls on every start and falls back to a local copy when
+	// the pull fails, "ifNotPresent" only pulls images that are not local,
+	// and "never" uses the local image and fails when there is none.
+	PullPolicy string `yaml:"pullPolicy,omitempty"`
+
+	// Platform selects the image platform as "os/arch[/variant]", e.g.
+	// "linux/amd64" to run amd64-only images under emulation on Apple
+	// Silicon. It is passed to the runtime as --platform when pulling and
+	// running the image. Empty uses the host platform.
+	Platform string `yaml:"platform,omitempty"`
+
 	// EnvForward lists host environment variable names to forward to the container.
-	// ANTHROPIC_API_KEY is always forwarded if set.
+	// ANTHROPIC_API_KEY is also forwarded if set, unless ForwardAnthropicKey
+	// is false.
 	EnvForward []string `yaml:"envForward,omitempty"`
 
+	// ForwardAnthropicKey controls whether the host ANTHROPIC_API_KEY is
+	// forwarded to the container automatically. Defaults to true; set it to
+	// false when the key comes from secretEnvVars or a proxy, so the host
+	// value does not leak into the container.
+	ForwardAnthropicKey *bool `yaml:"forwardAnthropicKey,omitempty"`
+
 	// EnvVars sets explicit environment variable key-value pairs in the container.
 	EnvVars map[string]string `yaml:"envVars,omitempty"`
 
+	// EnvFile is the path to a dotenv file whose KEY=VALUE lines are added
+	// to the container environment at start time. Entries in EnvVars take
+	// precedence.
+	EnvFile string `yaml:"envFile,omitempty"`
+
+	// ForwardSSHAgent mounts the host ssh-agent socket ($SSH_AUTH_SOCK) into
+	// the container and points SSH_AUTH_SOCK at it, so git over SSH can use
+	// the host keys without copying them.
+	ForwardSSHAgent bool `yaml:"forwardSSHAgent,omitempty"`
+
 	// SecretEnvVars maps container env var names to secret store names.
 	// At start time each secret is resolved and injected as an env var.
 	SecretEnvVars map[string]string `yaml:"secretEnvVars,omitempty"`
@@ -134,6 +266,13 @@ type GatewayRequirement struct {
 	WithAgentGateway bool `yaml:"withAgentgateway,omitempty"`
 }
 
+// PreStartPullConfig controls the pulls that run before container start.
+type PreStartPullConfig struct {
+	// Sequential pulls plugins first and then the image instead of pulling
+	// both concurrently. Useful for rate-limited registries.
+	Sequential bool `yaml:"sequential,omitempty"`
+}
+
 // ImageExplicitlySet reports whether the Image field was explicitly set in the
 // config file (before defaults were applied). When false, a personality's image
 // takes precedence.
@@ -141,13 +280,36 @@ func (c *Config) ImageExplicitlySet() bool {
 	return c.imageFromConfig
 }
 
+// SetImage overrides Image and marks it as explicitly set, so that a
+// personality's image does not take precedence.
+func (c *Config) SetImage(image string) {
+	c.Image = image
+	c.imageFromConfig = true
+}
+
+// ResolveImageShortName expands an Image that is a bare toolchain short name
+// such as "go" or "go:v1.2.0", e.g. from a hand-edited config file, to a
+// toolchain reference of the resolver's default source. Images containing a
+// "/" are left unchanged.
+func (c *Config) ResolveImageShortName(resolver *SourceResolver) error {
+	image, err := resolver.ResolveToolchainRef(c.Image)
+	if err != nil {
+		return fmt.Errorf("resolving image: %w", err)
+	}
+	c.Image = image
+	return nil
+}
+
 // ClaudeConfig contains Claude Code agent configuration, mirroring the Helm values.claude section.
 type ClaudeConfig struct {
 	// Model is the Claude model (e.g. "sonnet", "opus", "claude-sonnet-4-20250514").
 	Model string `yaml:"model,omitempty"`
-	// SystemPrompt overrides the default system prompt.
+	// SystemPrompt overrides the default system prompt. It is expanded
+	// as a Go template at start time with {{.Workspace}},
+	// {{.InstanceName}} and {{env "VAR"}}.
 	SystemPrompt string `yaml:"systemPrompt,omitempty"`
-	// AppendSystemPrompt appends to the default system prompt.
+	// AppendSystemPrompt appends to the default system prompt. It is
+	// expanded like SystemPrompt.
 	AppendSystemPrompt string `yaml:"appendSystemPrompt,omitempty"`
 	// MaxTurns limits agentic turns per prompt; 0 means unlimited.
 	MaxTurns int `yaml:"maxTurns,omitempty"`
@@ -183,14 +345,22 @@ type ClaudeConfig struct {
 	// JsonSchema provides a JSON schema for structured output.
 	JsonSchema string `yaml:"jsonSchema,omitempty"`
 	// SettingsFile is an alternative to inline hooks -- a path to a settings.json.
-	// Mutually exclusive with Hooks.
+	// Mutually exclusive with Hooks and Settings.
 	SettingsFile string `yaml:"settingsFile,omitempty"`
+	// Settings is an inline Claude settings.json object rendered to
+	// rendered/settings.json. When Hooks are also set, the generated hooks
+	// are merged in; inline settings win on conflicting keys, including
+	// per-event entries under "hooks".
+	Settings map[string]any `yaml:"settings,omitempty"`
 	// SettingSources controls setting source precedence.
 	SettingSources string `yaml:"settingSources,omitempty"`
 	// LoadAdditionalDirsMemory enables loading CLAUDE.md memory files from
 	// additional directories. Defaults to true, matching the Helm chart default.
 	LoadAdditionalDirsMemory *bool `yaml:"loadAdditionalDirsMemory,omitempty"`
-	// AddDirs are additional directories for skills and agents.
+	// AddDirs are additional directories for skills and agents, as paths
+	// in the container. Entries prefixed with "host:", e.g. "host:~/skills",
+	// are host directories: they must exist and are mounted read-only at
+	// the same path in the container.
 	AddDirs []string `yaml:"addDirs,omitempty"`
 	// PluginDirs are directories to load plugins from.
 	PluginDirs []string `yaml:"pluginDirs,omitempty"`
@@ -291,15 +461,87 @@ type Plugin struct {
 	Repository string `yaml:"repository"`
 	Tag        string `yaml:"tag,omitempty"`
 	Digest     string `yaml:"digest,omitempty"`
+	// Enabled set to false keeps the plugin in the config without pulling
+	// or mounting it. Defaults to true.
+	Enabled *bool `yaml:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether the plugin is pulled and mounted (see Enabled).
+func (p Plugin) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
 }
 
-// validPermissionModes lists valid permission mode values.
-var validPermissionModes = []string{
+// InlinePlugin defines the content of a plugin inline in the config.
+type InlinePlugin struct {
+	// Description is written to the plugin manifest.
+	Description string `yaml:"description,omitempty"`
+	// Skills are rendered as skills/<name>/SKILL.md.
+	Skills map[string]Skill `yaml:"skills,omitempty"`
+	// Commands maps slash command names to their markdown, rendered as
+	// commands/<name>.md.
+	Commands map[string]string `yaml:"commands,omitempty"`
+}
+
+// ValidPermissionModes lists valid permission mode values.
+var ValidPermissionModes = []string{
 	"default", "acceptEdits", "bypassPermissions", "dontAsk", "plan", "delegate",
 }
 
-// validEffortLevels lists valid effort level values.
-var validEffortLevels = []string{"low", "medium", "high"}
+// ValidEffortLevels lists valid effort level values.
+var ValidEffortLevels = []string{"low", "medium", "high"}
+
+// validRestartPolicies lists valid container restart policy values.
+var validRestartPolicies = []string{"no", "on-failure", "unless-stopped", "always"}
+
+// Special RunAsUser values; see Config.RunAsUser.
+const (
+	RunAsUserRoot  = "root"
+	RunAsUserImage = "image"
+)
+
+// Image pull policies; see Config.PullPolicy.
+const (
+	PullPolicyAlways       = "always"
+	PullPolicyIfNotPresent = "ifNotPresent"
+	PullPolicyNever        = "never"
+)
+
+// Workspace mount modes; see Config.WorkspaceMode.
+const (
+	WorkspaceModeReadWrite = "rw"
+	WorkspaceModeReadOnly  = "ro"
+	WorkspaceModeCached    = "cached"
+	WorkspaceModeDelegated = "delegated"
+)
+
+// validWorkspaceModes lists valid workspace mount mode values.
+var validWorkspaceModes = []string{WorkspaceModeReadWrite, WorkspaceModeReadOnly, WorkspaceModeCached, WorkspaceModeDelegated}
+
+// validPullPolicies lists valid image pull policy values.
+var validPullPolicies = []string{PullPolicyAlways, PullPolicyIfNotPresent, PullPolicyNever}
+
+// ValidatePullPolicy returns an error if policy is not a valid image pull
+// policy. The empty string selects the default and is valid.
+func ValidatePullPolicy(policy string) error {
+	if policy == "" {
+		return nil
+	}
+	return validateOneOf("pull policy", policy, validPullPolicies)
+}
+
+// platformRegexp matches "os/arch[/variant]" platforms. The values are not
+// checked against the platforms the runtime supports.
+var platformRegexp = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+
+// ValidatePlatform returns an error if platform is not of the form
+// "os/arch[/variant]". The empty string selects the host platform and is
+// valid.
+func ValidatePlatform(platform string) error {
+	if platform == "" || platformRegexp.MatchString(platform) {
+		return nil
+	}
+	return fmt.Errorf("invalid platform %q: expected os/arch[/variant], e.g. linux/amd64", platform)
+}
 
 // Load reads and parses the configuration file. If path is empty, the default
 // path (~/.config/klausctl/config.yaml) is used.
@@ -320,7 +562,12 @@ func Load(path string) (*Config, error) {
 		}
 		return nil, fmt.Errorf("reading config: %w", err)
 	}
+	return Parse(data)
+}
 
+// Parse parses config file contents, applies defaults, and validates the
+// result.
+func Parse(data []byte) (*Config, error) {
 	cfg := &Config{}
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parsing config: %w", err)
@@ -366,22 +613,86 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("worktreePath must be an absolute path, got %q", c.WorktreePath)
 	}
 
+	if c.Version > CurrentConfigVersion {
+		return fmt.Errorf("config version %d is newer than the supported version %d; upgrade klausctl", c.Version, CurrentConfigVersion)
+	}
+
 	if c.Port < 1 || c.Port > 65535 {
 		return fmt.Errorf("port must be between 1 and 65535, got %d", c.Port)
 	}
 
+	if c.ContainerPort < 0 || c.ContainerPort > 65535 {
+		return fmt.Errorf("containerPort must be between 1 and 65535 (0 for the default), got %d", c.ContainerPort)
+	}
+
 	if c.Runtime != "" && c.Runtime != "docker" && c.Runtime != "podman" {
 		return fmt.Errorf("runtime must be 'docker' or 'podman', got %q", c.Runtime)
 	}
 
+	if err := runtime.ValidateHost(c.RuntimeHost); err != nil {
+		return err
+	}
+
+	if c.PublishAddress != "" && net.ParseIP(c.PublishAddress) == nil {
+		return fmt.Errorf("invalid publishAddress %q: must be an IP address", c.PublishAddress)
+	}
+
+	if err := validateNetwork(c.Network); err != nil {
+		return err
+	}
+
+	if err := validateDNS(c.DNS); err != nil {
+		return err
+	}
+
+	if err := validateExtraHosts(c.ExtraHosts); err != nil {
+		return err
+	}
+
+	if c.StopTimeout < 0 {
+		return fmt.Errorf("stopTimeout must be >= 0, got %d", c.StopTimeout)
+	}
+
+	if c.RestartPolicy != "" {
+		if err := validateOneOf("restart policy", c.RestartPolicy, validRestartPolicies); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidatePullPolicy(c.PullPolicy); err != nil {
+		return err
+	}
+
+	if c.WorkspaceMode != "" {
+		if err := validateOneOf("workspace mode", c.WorkspaceMode, validWorkspaceModes); err != nil {
+			return err
+		}
+	}
+
+	if err := ValidatePlatform(c.Platform); err != nil {
+		return err
+	}
+
+	if err := c.SecurityOpts.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Health.validate(); err != nil {
+		return err
+	}
+
+	if strings.ContainsAny(c.RunAsUser, " \t\n") {
+		return fmt.Errorf("invalid runAsUser %q: must not contain whitespace", c.RunAsUser)
+	}
+
 	if c.Claude.PermissionMode != "" {
-		if err := validateOneOf("permission mode", c.Claude.PermissionMode, validPermissionModes); err != nil {
+		if err := validateOneOf("permission mode", c.Claude.PermissionMode, ValidPermissionModes); err != nil {
 			return err
 		}
 	}
 
 	if c.Claude.Effort != "" {
-		if err := validateOneOf("effort level", c.Claude.Effort, validEffortLevels); err != nil {
+		if err := validateOneOf("effort level", c.Claude.Effort, ValidEffortLevels); err != nil {
 			return err
 		}
 	}
@@ -394,6 +705,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("hooks and claude.settingsFile are mutually exclusive; use one or the other")
 	}
 
+	if len(c.Claude.Settings) > 0 && c.Claude.SettingsFile != "" {
+		return fmt.Errorf("claude.settings and claude.settingsFile are mutually exclusive; use one or the other")
+	}
+
+	if err := validateInlineSettings(c.Claude.Settings); err != nil {
+		return err
+	}
+
 	if c.Claude.MaxBudgetUSD < 0 {
 		return fmt.Errorf("maxBudgetUsd must be >= 0, got %f", c.Claude.MaxBudgetUSD)
 	}
@@ -417,19 +736,55 @@ func (c *Config) Validate() error {
 		if p.Repository == "" {
 			return fmt.Errorf("plugin repository is required")
 		}
+		if _, ok := c.InlinePlugins[path.Base(p.Repository)]; ok {
+			return fmt.Errorf("inline plugin %q has the same name as plugin %s", path.Base(p.Repository), p.Repository)
+		}
 	}
 
 	return nil
 }
 
+// ForwardsAnthropicKey reports whether the host ANTHROPIC_API_KEY is
+// forwarded to the container (see ForwardAnthropicKey).
+func (c *Config) ForwardsAnthropicKey() bool {
+	return c.ForwardAnthropicKey == nil || *c.ForwardAnthropicKey
+}
+
 // DefaultConfig returns a minimal default configuration with all defaults applied.
 // Note: Workspace must be set by the caller before the config can pass Validate().
 func DefaultConfig() *Config {
-	cfg := &Config{}
+	cfg := &Config{Version: CurrentConfigVersion}
 	cfg.applyDefaults()
 	return cfg
 }
 
+// DefaultContainerPort is the port the klaus image listens on inside the
+// container.
+const DefaultContainerPort = 8080
+
+// AgentPort returns the port the agent listens on inside the container.
+// It is ContainerPort, or DefaultContainerPort when unset, unless the
+// container shares the host network, in which case no port is published and
+// the agent listens on Port directly.
+func (c *Config) AgentPort() int {
+	if c.Network == NetworkHost {
+		return c.Port
+	}
+	if c.ContainerPort != 0 {
+		return c.ContainerPort
+	}
+	return DefaultContainerPort
+}
+
+// NetworkWarning returns a warning about the effect of the network mode on
+// port mapping, or "" when there is nothing to warn about.
+func (c *Config) NetworkWarning() string {
+	if c.Network != NetworkHost {
+		return ""
+	}
+	return fmt.Sprintf("network %q ignores port mapping; the agent listens on host port %d directly, on all host interfaces", NetworkHost, c.Port)
+}
+
 // Marshal serializes the config to YAML.
 func (c *Config) Marshal() ([]byte, error) {
 	return yaml.Marshal(c)
@@ -443,3 +798,111 @@ func validateOneOf(name, value string, valid []string) error {
 	}
 	return fmt.Errorf("invalid %s %q; valid values: %s", name, value, strings.Join(valid, ", "))
 }
+
+// NetworkHost is the network mode that shares the host network stack.
+const NetworkHost = "host"
+
+// builtinNetworks are the network modes every runtime provides.
+var builtinNetworks = []string{NetworkHost, "bridge", "none"}
+
+// networkNameRegexp matches network names accepted by docker and podman,
+// optionally prefixed with a mode such as "container:".
+var networkNameRegexp = regexp.MustCompile(`^([a-z]+:)?[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// SecurityOpts are container security options.
+type SecurityOpts struct {
+	// ReadOnlyRootfs mounts the container root filesystem read-only
+	// (--read-only). A tmpfs is mounted at /tmp so the agent keeps a
+	// writable scratch directory; bind mounts such as /workspace stay
+	// writable.
+	ReadOnlyRootfs bool `yaml:"readOnlyRootfs,omitempty"`
+	// CapDrop lists Linux capabilities to drop (--cap-drop), e.g. "ALL".
+	CapDrop []string `yaml:"capDrop,omitempty"`
+	// CapAdd lists Linux capabilities to add (--cap-add), e.g.
+	// "NET_BIND_SERVICE".
+	CapAdd []string `yaml:"capAdd,omitempty"`
+}
+
+// capabilityRegexp matches capability names, with or without the CAP_
+// prefix, and the special value "ALL". Names are not checked against the
+// list of known capabilities, which differs between kernels and runtimes.
+var capabilityRegexp = regexp.MustCompile(`^(?i)(CAP_)?[A-Z][A-Z0-9_]*$`)
+
+func (s SecurityOpts) validate() error {
+	if err := validateCapabilities("capDrop", s.CapDrop); err != nil {
+		return err
+	}
+	return validateCapabilities("capAdd", s.CapAdd)
+}
+
+func validateCapabilities(field string, caps []string) error {
+	for _, c := range caps {
+		if !capabilityRegexp.MatchString(c) {
+			return fmt.Errorf("invalid capability %q in securityOpts.%s: expected a name such as NET_ADMIN or ALL", c, field)
+		}
+	}
+	return nil
+}
+
+// validateNetwork checks the network mode loosely: any network name is
+// allowed, but malformed names and miscapitalised built-in modes are rejected
+// since they are almost certainly typos.
+func validateNetwork(network string) error {
+	if network == "" {
+		return nil
+	}
+	if !networkNameRegexp.MatchString(network) {
+		return fmt.Errorf("invalid network %q: must be %s, or a network name", network, strings.Join(builtinNetworks, ", "))
+	}
+	for _, b := range builtinNetworks {
+		if network != b && strings.EqualFold(network, b) {
+			return fmt.Errorf("invalid network %q: did you mean %q?", network, b)
+		}
+	}
+	return nil
+}
+
+// validateDNS checks that every DNS server is an IP address, which is all
+// docker and podman accept.
+func validateDNS(servers []string) error {
+	for _, s := range servers {
+		if net.ParseIP(s) == nil {
+			return fmt.Errorf("invalid dns server %q: must be an IP address", s)
+		}
+	}
+	return nil
+}
+
+// hostGateway is the extra host IP that runtimes replace with the host's
+// address.
+const hostGateway = "host-gateway"
+
+// validateExtraHosts checks that every extra host is "hostname:ip". The
+// hostname ends at the first colon, so IPv6 addresses need no brackets.
+func validateExtraHosts(hosts []string) error {
+	for _, h := range hosts {
+		name, ip, ok := strings.Cut(h, ":")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid extra host %q: expected hostname:ip", h)
+		}
+		if ip != hostGateway && net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid extra host %q: %q is not an IP address or %s", h, ip, hostGateway)
+		}
+	}
+	return nil
+}
+
+// validateInlineSettings checks that claude.settings can be rendered to
+// settings.json. Catching this at load time gives a clearer error than a
+// marshal failure when the instance starts.
+func validateInlineSettings(settings map[string]any) error {
+	if hooks, ok := settings["hooks"]; ok {
+		if _, isMap := hooks.(map[string]any); !isMap {
+			return fmt.Errorf("claude.settings.hooks must be an object keyed by hook event")
+		}
+	}
+	if _, err := json.Marshal(settings); err != nil {
+		return fmt.Errorf("claude.settings cannot be rendered as JSON (object keys must be strings): %w", err)
+	}
+	return nil
+}
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/giantswarm/klausctl/pkg/agentclient"
 )
 
 func TestLoadValidConfig(t *testing.T) {
@@ -126,6 +129,174 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "runtime must be",
 		},
+		{
+			name: "network host",
+			cfg:  Config{Workspace: "/tmp", Port: 8080, Network: "host"},
+		},
+		{
+			name: "custom network",
+			cfg:  Config{Workspace: "/tmp", Port: 8080, Network: "my_project-net.1"},
+		},
+		{
+			name: "container network mode",
+			cfg:  Config{Workspace: "/tmp", Port: 8080, Network: "container:sidecar"},
+		},
+		{
+			name:    "network with whitespace",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Network: "my net"},
+			wantErr: true,
+			errMsg:  "invalid network",
+		},
+		{
+			name:    "miscapitalised builtin network",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Network: "Host"},
+			wantErr: true,
+			errMsg:  `did you mean "host"`,
+		},
+		{
+			name:    "negative stop timeout",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, StopTimeout: -1},
+			wantErr: true,
+			errMsg:  "stopTimeout must be >= 0",
+		},
+		{
+			name:    "invalid container port",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, ContainerPort: 70000},
+			wantErr: true,
+			errMsg:  "containerPort must be between 1 and 65535",
+		},
+		{
+			name:    "invalid run as user",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, RunAsUser: "1000 1000"},
+			wantErr: true,
+			errMsg:  "invalid runAsUser",
+		},
+		{
+			name:    "valid runtime host",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, RuntimeHost: "ssh://me@build-box"},
+			wantErr: false,
+		},
+		{
+			name:    "valid publish address",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, PublishAddress: "0.0.0.0"},
+			wantErr: false,
+		},
+		{
+			name:    "invalid publish address",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, PublishAddress: "build-box"},
+			wantErr: true,
+			errMsg:  "invalid publishAddress",
+		},
+		{
+			name:    "invalid runtime host",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, RuntimeHost: "build-box"},
+			wantErr: true,
+			errMsg:  "invalid runtime host",
+		},
+		{
+			name:    "valid restart policy",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, RestartPolicy: "unless-stopped"},
+			wantErr: false,
+		},
+		{
+			name: "valid security opts",
+			cfg: Config{Workspace: "/tmp", Port: 8080, SecurityOpts: SecurityOpts{
+				ReadOnlyRootfs: true, CapDrop: []string{"ALL"}, CapAdd: []string{"net_bind_service", "CAP_CHOWN"},
+			}},
+		},
+		{
+			name:    "invalid capability",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, SecurityOpts: SecurityOpts{CapAdd: []string{"--privileged"}}},
+			wantErr: true,
+			errMsg:  "securityOpts.capAdd",
+		},
+		{
+			name: "valid health probe",
+			cfg: Config{Workspace: "/tmp", Port: 8080, Health: HealthConfig{
+				ReadinessPath: "/healthz", ReadinessInterval: "500ms", ReadinessTimeout: "5m",
+			}},
+		},
+		{
+			name:    "invalid readiness interval",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Health: HealthConfig{ReadinessInterval: "soon"}},
+			wantErr: true,
+			errMsg:  "health.readinessInterval",
+		},
+		{
+			name:    "non-positive readiness timeout",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Health: HealthConfig{ReadinessTimeout: "0s"}},
+			wantErr: true,
+			errMsg:  "health.readinessTimeout must be positive",
+		},
+		{
+			name:    "relative readiness path",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Health: HealthConfig{ReadinessPath: "healthz"}},
+			wantErr: true,
+			errMsg:  "health.readinessPath",
+		},
+		{
+			name: "valid dns and extra hosts",
+			cfg: Config{Workspace: "/tmp", Port: 8080, DNS: []string{"10.0.0.53", "fd00::53"}, ExtraHosts: []string{
+				"git.corp:10.0.0.7", "v6.corp:fd00::7", "host.docker.internal:host-gateway",
+			}},
+		},
+		{
+			name:    "invalid dns server",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, DNS: []string{"dns.corp"}},
+			wantErr: true,
+			errMsg:  "invalid dns server",
+		},
+		{
+			name:    "extra host without ip",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, ExtraHosts: []string{"git.corp"}},
+			wantErr: true,
+			errMsg:  "expected hostname:ip",
+		},
+		{
+			name:    "extra host with invalid ip",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, ExtraHosts: []string{"git.corp:10.0.0"}},
+			wantErr: true,
+			errMsg:  "not an IP address",
+		},
+		{
+			name:    "invalid restart policy",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, RestartPolicy: "sometimes"},
+			wantErr: true,
+			errMsg:  "restart policy",
+		},
+		{
+			name:    "valid pull policy",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, PullPolicy: "ifNotPresent"},
+			wantErr: false,
+		},
+		{
+			name:    "valid workspace mode",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, WorkspaceMode: "delegated"},
+			wantErr: false,
+		},
+		{
+			name:    "invalid workspace mode",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, WorkspaceMode: "readonly"},
+			wantErr: true,
+			errMsg:  "workspace mode",
+		},
+		{
+			name:    "invalid pull policy",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, PullPolicy: "missing"},
+			wantErr: true,
+			errMsg:  "pull policy",
+		},
+		{
+			name:    "valid platform",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Platform: "linux/arm64/v8"},
+			wantErr: false,
+		},
+		{
+			name:    "invalid platform",
+			cfg:     Config{Workspace: "/tmp", Port: 8080, Platform: "amd64"},
+			wantErr: true,
+			errMsg:  "os/arch",
+		},
 		{
 			name: "invalid permission mode",
 			cfg: Config{
@@ -179,6 +350,16 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "plugin repository is required",
 		},
+		{
+			name: "inline plugin named like a plugin",
+			cfg: Config{
+				Workspace: "/tmp", Port: 8080,
+				Plugins:       []Plugin{{Repository: "example.com/plugins/gs-base"}},
+				InlinePlugins: map[string]InlinePlugin{"gs-base": {}},
+			},
+			wantErr: true,
+			errMsg:  "same name as plugin",
+		},
 		{
 			name: "hooks and settingsFile mutually exclusive",
 			cfg: Config{
@@ -191,6 +372,47 @@ func TestValidate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "mutually exclusive",
 		},
+		{
+			name: "inline settings and settingsFile mutually exclusive",
+			cfg: Config{
+				Workspace: "/tmp", Port: 8080,
+				Claude: ClaudeConfig{
+					Settings:     map[string]any{"model": "opus"},
+					SettingsFile: "/path/to/settings.json",
+				},
+			},
+			wantErr: true,
+			errMsg:  "mutually exclusive",
+		},
+		{
+			name: "inline settings hooks not an object",
+			cfg: Config{
+				Workspace: "/tmp", Port: 8080,
+				Claude: ClaudeConfig{Settings: map[string]any{"hooks": []any{"x"}}},
+			},
+			wantErr: true,
+			errMsg:  "claude.settings.hooks must be an object",
+		},
+		{
+			name: "inline settings null hooks",
+			cfg: Config{
+				Workspace: "/tmp", Port: 8080,
+				Claude: ClaudeConfig{Settings: map[string]any{"hooks": nil}},
+			},
+			wantErr: true,
+			errMsg:  "claude.settings.hooks must be an object",
+		},
+		{
+			name: "inline settings with non-string keys",
+			cfg: Config{
+				Workspace: "/tmp", Port: 8080,
+				Claude: ClaudeConfig{Settings: map[string]any{
+					"permissions": map[any]any{true: "allow"},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "cannot be rendered as JSON",
+		},
 		{
 			name: "personality with whitespace",
 			cfg: Config{
@@ -408,3 +630,15 @@ func TestMarshal(t *testing.T) {
 		t.Fatal("Marshal() returned empty data")
 	}
 }
+
+func TestHealthConfigReadinessProbe(t *testing.T) {
+	if got := (HealthConfig{}).ReadinessProbe(); got != (agentclient.Probe{}) {
+		t.Errorf("empty health config probe = %+v, want zero probe", got)
+	}
+
+	got := HealthConfig{ReadinessTimeout: "30s"}.ReadinessProbe()
+	want := agentclient.Probe{Path: DefaultReadinessPath, Interval: DefaultReadinessInterval, Timeout: 30 * time.Second}
+	if got != want {
+		t.Errorf("probe = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// InstanceKeys are the config fields that are specific to each instance and
+// therefore expected to differ from the config it was created from.
+var InstanceKeys = []string{"workspace", "worktreePath", "port"}
+
+// ConfigChange is a config field that differs between two configs.
+type ConfigChange struct {
+	// Key is the dotted YAML path of the field, e.g. "claude.model".
+	Key string `json:"key"`
+	// From and To are the values of the field in the two configs; nil when
+	// the field is unset on that side.
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// DiffConfigs compares two configs field by field, as they would be written
+// to a config file, and returns the changes sorted by key. Nested objects
+// are compared key by key; lists and scalars are compared as whole values.
+func DiffConfigs(from, to *Config) ([]ConfigChange, error) {
+	fromFields, err := configFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := configFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	for key, fromVal := range fromFields {
+		if toVal, ok := toFields[key]; !ok || !reflect.DeepEqual(fromVal, toVal) {
+			changes = append(changes, ConfigChange{Key: key, From: fromVal, To: toVal})
+		}
+	}
+	for key, toVal := range toFields {
+		if _, ok := fromFields[key]; !ok {
+			changes = append(changes, ConfigChange{Key: key, To: toVal})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes, nil
+}
+
+// DiffDefaults compares cfg with DefaultConfig and returns the fields cfg
+// sets to a non-default value, sorted by key. From holds the default value.
+// The schema version of the file is not a setting and is not compared.
+func DiffDefaults(cfg *Config) ([]ConfigChange, error) {
+	defaults := DefaultConfig()
+	defaults.Version = cfg.Version
+	return DiffConfigs(defaults, cfg)
+}
+
+// configFields flattens the YAML form of cfg into a map of dotted keys to
+// leaf values.
+func configFields(cfg *Config) (map[string]any, error) {
+	data, err := cfg.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	var tree map[string]any
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("parsing marshaled config: %w", err)
+	}
+	fields := make(map[string]any)
+	flattenFields(fields, "", tree)
+	return fields, nil
+}
+
+func flattenFields(fields map[string]any, prefix string, tree map[string]any) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]any); ok && len(sub) > 0 {
+			flattenFields(fields, key, sub)
+			continue
+		}
+		fields[key] = v
+	}
+}
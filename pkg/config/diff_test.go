@@ -0,0 +1,69 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	from := &Config{
+		Workspace:  "/src/a",
+		Port:       8080,
+		EnvForward: []string{"GITHUB_TOKEN"},
+	}
+	from.Claude.Model = "sonnet"
+	from.Claude.PermissionMode = "bypassPermissions"
+
+	to := &Config{
+		Workspace:     "/src/a",
+		Port:          8081,
+		EnvForward:    []string{"GITHUB_TOKEN", "NPM_TOKEN"},
+		RestartPolicy: "always",
+	}
+	to.Claude.PermissionMode = "bypassPermissions"
+
+	changes, err := DiffConfigs(from, to)
+	if err != nil {
+		t.Fatalf("DiffConfigs: %v", err)
+	}
+	want := []ConfigChange{
+		{Key: "claude.model", From: "sonnet"},
+		{Key: "envForward", From: []any{"GITHUB_TOKEN"}, To: []any{"GITHUB_TOKEN", "NPM_TOKEN"}},
+		{Key: "port", From: 8080, To: 8081},
+		{Key: "restartPolicy", To: "always"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffConfigs() =\n%#v\nwant\n%#v", changes, want)
+	}
+}
+
+func TestDiffDefaults(t *testing.T) {
+	cfg, err := Parse([]byte("workspace: /src/a\nport: 8080\nclaude:\n  model: opus\n  mode: agent\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := DiffDefaults(cfg)
+	if err != nil {
+		t.Fatalf("DiffDefaults: %v", err)
+	}
+	want := []ConfigChange{
+		{Key: "claude.model", To: "opus"},
+		{Key: "workspace", From: "", To: "/src/a"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("DiffDefaults() =\n%#v\nwant\n%#v", changes, want)
+	}
+}
+
+func TestDiffConfigsEqual(t *testing.T) {
+	cfg := &Config{Workspace: "/src/a", Port: 8080}
+	cfg.Claude.Model = "opus"
+	changes, err := DiffConfigs(cfg, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("DiffConfigs(cfg, cfg) = %v, want no changes", changes)
+	}
+}
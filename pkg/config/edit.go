@@ -0,0 +1,199 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lookupKey returns the type of the config value at a dotted key such as
+// "port", "claude.model" or "envVars.FOO". Struct fields are matched by their
+// key in the config file; map keys and the contents of free-form values are
+// not checked.
+func lookupKey(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+	for _, seg := range strings.Split(key, ".") {
+		if seg == "" {
+			return nil, fmt.Errorf("invalid key %q", key)
+		}
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			field, ok := structFieldByKey(t, seg)
+			if !ok {
+				return nil, fmt.Errorf("unknown config key %q", key)
+			}
+			t = field.Type
+		case reflect.Map:
+			t = t.Elem()
+		case reflect.Interface:
+			// Free-form values such as mcpServers entries accept any key.
+		default:
+			return nil, fmt.Errorf("unknown config key %q", key)
+		}
+	}
+	return t, nil
+}
+
+// structFieldByKey returns the field of struct type t serialized as key.
+func structFieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.IsExported() && yamlFieldName(f) == key {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// GetValue returns the value of cfg at a dotted key (see SetValue). Unset
+// pointers and interface values are returned as nil.
+func GetValue(cfg *Config, key string) (any, error) {
+	if _, err := lookupKey(key); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(*cfg)
+	for _, seg := range strings.Split(key, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return nil, fmt.Errorf("%s is not set", key)
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			field, _ := structFieldByKey(v.Type(), seg)
+			v = v.FieldByIndex(field.Index)
+		case reflect.Map:
+			v = v.MapIndex(reflect.ValueOf(seg))
+			if !v.IsValid() {
+				return nil, fmt.Errorf("%s is not set", key)
+			}
+		default:
+			return nil, fmt.Errorf("%s is not set", key)
+		}
+	}
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), nil
+}
+
+// SetValue sets a dotted key in the config file contents data to raw and
+// returns the updated contents. raw is converted to the type of the target
+// field, so "port" takes an integer and "claude.maxBudgetUsd" a number. Only
+// scalar values can be set; lists and objects are edited in the file.
+// Comments and the other keys are kept, and the result must be a valid
+// config.
+func SetValue(data []byte, key, raw string) ([]byte, error) {
+	t, err := lookupKey(key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := scalarNode(t, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if err := setNode(doc.Content[0], strings.Split(key, "."), value); err != nil {
+		return nil, fmt.Errorf("setting %s: %w", key, err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	if _, err := Parse(buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scalarNode converts raw to a YAML scalar of type t.
+func scalarNode(t reflect.Type, raw string) (*yaml.Node, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	node := &yaml.Node{Kind: yaml.ScalarNode, Value: raw}
+	switch t.Kind() {
+	case reflect.String:
+		node.Tag = "!!str"
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false, got %q", raw)
+		}
+		node.Tag, node.Value = "!!bool", strconv.FormatBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer, got %q", raw)
+		}
+		node.Tag, node.Value = "!!int", strconv.FormatInt(n, 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("expected a non-negative integer, got %q", raw)
+		}
+		node.Tag, node.Value = "!!int", strconv.FormatUint(n, 10)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, fmt.Errorf("expected a number, got %q", raw)
+		}
+		node.Tag, node.Value = "!!float", strconv.FormatFloat(f, 'g', -1, t.Bits())
+	case reflect.Interface:
+		// Free-form values keep the type YAML infers for raw.
+	default:
+		return nil, fmt.Errorf("only scalar values can be set; edit the config file to change a %s", t.Kind())
+	}
+	return node, nil
+}
+
+// setNode sets the value at path below the mapping node m, creating
+// intermediate mappings as needed.
+func setNode(m *yaml.Node, path []string, value *yaml.Node) error {
+	if m.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected a mapping")
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			m.Content[i+1] = value
+			return nil
+		}
+		return setNode(m.Content[i+1], path[1:], value)
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: path[0]}
+	if len(path) == 1 {
+		m.Content = append(m.Content, keyNode, value)
+		return nil
+	}
+	child := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, keyNode, child)
+	return setNode(child, path[1:], value)
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const editBaseConfig = `# klausctl config
+workspace: /tmp/test # project checkout
+claude:
+  model: sonnet
+`
+
+func TestSetValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name:  "top-level int",
+			key:   "port",
+			value: "9090",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Port != 9090 {
+					t.Errorf("Port = %d, want 9090", cfg.Port)
+				}
+			},
+		},
+		{
+			name:  "nested string replaces existing value",
+			key:   "claude.model",
+			value: "opus",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Claude.Model != "opus" {
+					t.Errorf("Claude.Model = %q, want opus", cfg.Claude.Model)
+				}
+			},
+		},
+		{
+			name:  "nested float",
+			key:   "claude.maxBudgetUsd",
+			value: "2.5",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Claude.MaxBudgetUSD != 2.5 {
+					t.Errorf("Claude.MaxBudgetUSD = %v, want 2.5", cfg.Claude.MaxBudgetUSD)
+				}
+			},
+		},
+		{
+			name:  "bool pointer",
+			key:   "claude.loadAdditionalDirsMemory",
+			value: "false",
+			check: func(t *testing.T, cfg *Config) {
+				if v := cfg.Claude.LoadAdditionalDirsMemory; v == nil || *v {
+					t.Errorf("Claude.LoadAdditionalDirsMemory = %v, want false", v)
+				}
+			},
+		},
+		{
+			name:  "string that looks like a number",
+			key:   "envVars.GOMAXPROCS",
+			value: "4",
+			check: func(t *testing.T, cfg *Config) {
+				if got := cfg.EnvVars["GOMAXPROCS"]; got != "4" {
+					t.Errorf("EnvVars[GOMAXPROCS] = %q, want 4", got)
+				}
+			},
+		},
+		{
+			name:  "creates intermediate mappings",
+			key:   "git.authorName",
+			value: "Jane Doe",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Git.AuthorName != "Jane Doe" {
+					t.Errorf("Git.AuthorName = %q, want Jane Doe", cfg.Git.AuthorName)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := SetValue([]byte(editBaseConfig), tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("SetValue() error = %v", err)
+			}
+			cfg, err := Parse(out)
+			if err != nil {
+				t.Fatalf("Parse() error = %v\n%s", err, out)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestSetValuePreservesComments(t *testing.T) {
+	out, err := SetValue([]byte(editBaseConfig), "claude.model", "opus")
+	if err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	for _, want := range []string{"# klausctl config", "# project checkout", "model: opus"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestSetValueEmptyFile(t *testing.T) {
+	out, err := SetValue(nil, "workspace", "/tmp/ws")
+	if err != nil {
+		t.Fatalf("SetValue() error = %v", err)
+	}
+	cfg, err := Parse(out)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if cfg.Workspace != "/tmp/ws" {
+		t.Errorf("Workspace = %q, want /tmp/ws", cfg.Workspace)
+	}
+}
+
+func TestSetValueErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{"unknown top-level key", "prot", "8080", `unknown config key "prot"`},
+		{"unknown nested key", "claude.modle", "opus", `unknown config key "claude.modle"`},
+		{"key below scalar", "port.number", "1", `unknown config key "port.number"`},
+		{"empty segment", "claude..model", "opus", "invalid key"},
+		{"invalid int", "port", "eighty", "expected an integer"},
+		{"invalid bool", "claude.loadAdditionalDirsMemory", "maybe", "expected true or false"},
+		{"invalid float", "claude.maxBudgetUsd", "lots", "expected a number"},
+		{"non-scalar target", "plugins", "x", "only scalar values can be set"},
+		{"fails validation", "port", "70000", "port must be between 1 and 65535"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SetValue([]byte(editBaseConfig), tt.key, tt.value)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	cfg, err := Parse([]byte(editBaseConfig + "envVars:\n  FOO: bar\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"workspace", "/tmp/test"},
+		{"port", 8080},
+		{"claude.model", "sonnet"},
+		{"claude.loadAdditionalDirsMemory", true},
+		{"envVars.FOO", "bar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := GetValue(cfg, tt.key)
+			if err != nil {
+				t.Fatalf("GetValue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GetValue(cfg, "envVars.MISSING"); err == nil || !strings.Contains(err.Error(), "is not set") {
+		t.Errorf("expected not set error, got %v", err)
+	}
+	if _, err := GetValue(cfg, "claude.unknown"); err == nil || !strings.Contains(err.Error(), "unknown config key") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+}
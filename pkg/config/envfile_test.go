@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	input := `# comment
+FOO=bar
+
+export EXPORTED=1
+SPACED = value with spaces   # trailing comment
+HASH=a#b
+EMPTY=
+SINGLE='literal \n $HOME # not a comment'
+DOUBLE="line1\nline2 \"quoted\" # kept"
+DOUBLE_COMMENT="x" # comment
+FOO=override
+`
+	env, err := ParseEnvFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseEnvFile: %v", err)
+	}
+
+	want := map[string]string{
+		"FOO":            "override",
+		"EXPORTED":       "1",
+		"SPACED":         "value with spaces",
+		"HASH":           "a#b",
+		"EMPTY":          "",
+		"SINGLE":         `literal \n $HOME # not a comment`,
+		"DOUBLE":         "line1\nline2 \"quoted\" # kept",
+		"DOUBLE_COMMENT": "x",
+	}
+	if len(env) != len(want) {
+		t.Errorf("got %d entries, want %d: %v", len(env), len(want), env)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing equals":     "FOO\n",
+		"invalid key":        "1FOO=bar\n",
+		"unterminated quote": "FOO=\"bar\n",
+		"text after quote":   "FOO='bar' baz\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseEnvFile(strings.NewReader("OK=1\n" + input))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), "line 2") {
+				t.Errorf("error %q does not name the line", err)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("FOO=bar\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	env, err := LoadEnvFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["FOO"] != "bar" {
+		t.Errorf("FOO = %q, want bar", env["FOO"])
+	}
+
+	_, err = LoadEnvFile(filepath.Join(t.TempDir(), "missing.env"))
+	if err == nil || !strings.Contains(err.Error(), "env file not found") {
+		t.Errorf("expected env file not found error, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -26,6 +27,16 @@ type CreateOptions struct {
 	Plugins     []string
 	Port        int
 
+	// Network is the container network mode (see Config.Network).
+	Network string
+
+	// Platform is the image platform (see Config.Platform).
+	Platform string
+
+	// Image overrides the container image, taking precedence over the
+	// personality's toolchain. It cannot be combined with Toolchain.
+	Image string
+
 	// Mode selects the operating mode: "agent" (default) for autonomous
 	// coding or "chat" for interactive conversation.
 	Mode string
@@ -50,6 +61,7 @@ type CreateOptions struct {
 	// Override fields applied after personality resolution.
 	EnvVars        map[string]string
 	EnvForward     []string
+	EnvFile        string
 	McpServers     map[string]any
 	SecretEnvVars  map[string]string
 	SecretFiles    map[string]string
@@ -79,6 +91,76 @@ type ResolvedPersonality struct {
 	Image   string
 }
 
+// WorkspaceAuto is the workspace, or defaultWorkspace, that selects the root
+// of the git repository containing the current directory, so that creating
+// an instance from a subdirectory still mounts the whole repository.
+const WorkspaceAuto = "auto"
+
+// ResolveCreateWorkspace returns the workspace to create an instance with.
+// An explicit workspace is returned as is. Otherwise the defaultWorkspace of
+// the klausctl config file (paths.ConfigFile) is used, falling back to the
+// current directory when none is set. WorkspaceAuto, given or configured,
+// resolves to the git root of the current directory, or the current
+// directory outside a repository.
+func ResolveCreateWorkspace(paths *Paths, workspace string) (string, error) {
+	if workspace == "" {
+		def, err := LoadDefaultWorkspace(paths.ConfigFile)
+		if err != nil {
+			return "", err
+		}
+		workspace = def
+	}
+	if workspace != "" && workspace != WorkspaceAuto {
+		return workspace, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("determining current directory: %w", err)
+	}
+	if workspace == WorkspaceAuto {
+		if root, ok := GitRoot(cwd); ok {
+			return root, nil
+		}
+	}
+	return cwd, nil
+}
+
+// LoadDefaultWorkspace reads defaultWorkspace from the config file at path
+// and expands "~". It returns "" when the file does not exist or sets no
+// default, WorkspaceAuto as is, and an error when the configured directory
+// does not exist.
+func LoadDefaultWorkspace(path string) (string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- klausctl config file path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return "", fmt.Errorf("parsing config: %w", err)
+	}
+	if cfg.DefaultWorkspace == "" || cfg.DefaultWorkspace == WorkspaceAuto {
+		return cfg.DefaultWorkspace, nil
+	}
+
+	dir := ExpandPath(cfg.DefaultWorkspace)
+	info, err := os.Stat(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("defaultWorkspace %s does not exist; fix it in %s or pass a workspace", dir, path)
+		}
+		return "", fmt.Errorf("checking defaultWorkspace: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("defaultWorkspace %s is not a directory", dir)
+	}
+	return dir, nil
+}
+
 // GenerateInstanceConfig builds a per-instance configuration from create options.
 func GenerateInstanceConfig(paths *Paths, opts CreateOptions) (*Config, error) {
 	if err := ValidateInstanceName(opts.Name); err != nil {
@@ -148,18 +230,36 @@ func GenerateInstanceConfig(paths *Paths, opts CreateOptions) (*Config, error) {
 		resolver = DefaultSourceResolver()
 	}
 
+	if opts.Image != "" && opts.Toolchain != "" {
+		return nil, fmt.Errorf("image and toolchain are mutually exclusive")
+	}
 	toolchainExplicitlySet := opts.Toolchain != ""
 	if opts.Personality != "" {
-		cfg.Personality = resolver.ResolvePersonalityRef(opts.Personality)
+		personality, err := resolver.ResolvePersonalityRef(opts.Personality)
+		if err != nil {
+			return nil, fmt.Errorf("resolving personality: %w", err)
+		}
+		cfg.Personality = personality
 	}
 
 	if toolchainExplicitlySet {
-		cfg.Toolchain = resolver.ResolveToolchainRef(opts.Toolchain)
+		toolchain, err := resolver.ResolveToolchainRef(opts.Toolchain)
+		if err != nil {
+			return nil, fmt.Errorf("resolving toolchain: %w", err)
+		}
+		cfg.Toolchain = toolchain
 		cfg.Image = cfg.Toolchain
 	}
+	if opts.Image != "" {
+		cfg.SetImage(opts.Image)
+	}
 
 	for _, pluginRef := range opts.Plugins {
-		cfg.Plugins = append(cfg.Plugins, ParsePluginRefWith(pluginRef, resolver))
+		plugin, err := ParsePluginRefWith(pluginRef, resolver)
+		if err != nil {
+			return nil, fmt.Errorf("resolving plugin: %w", err)
+		}
+		cfg.Plugins = append(cfg.Plugins, plugin)
 	}
 
 	if opts.Port > 0 {
@@ -182,6 +282,9 @@ func GenerateInstanceConfig(paths *Paths, opts CreateOptions) (*Config, error) {
 		cfg.Port = port
 	}
 
+	cfg.Network = opts.Network
+	cfg.Platform = opts.Platform
+
 	if cfg.Personality != "" && opts.ResolvePersonality != nil {
 		ctx := opts.Context
 		if ctx == nil {
@@ -194,7 +297,7 @@ func GenerateInstanceConfig(paths *Paths, opts CreateOptions) (*Config, error) {
 		}
 
 		cfg.Plugins = mergePlugins(resolved.Plugins, cfg.Plugins)
-		if !toolchainExplicitlySet && resolved.Image != "" {
+		if !toolchainExplicitlySet && opts.Image == "" && resolved.Image != "" {
 			cfg.Image = resolved.Image
 		}
 	}
@@ -235,6 +338,10 @@ func applyCreateOverrides(cfg *Config, opts CreateOptions) {
 		cfg.EnvForward = slices.Compact(cfg.EnvForward)
 	}
 
+	if opts.EnvFile != "" {
+		cfg.EnvFile = opts.EnvFile
+	}
+
 	for k, v := range opts.McpServers {
 		if cfg.McpServers == nil {
 			cfg.McpServers = make(map[string]any, len(opts.McpServers))
@@ -369,13 +476,18 @@ func UsedPorts(paths *Paths) (map[int]bool, error) {
 // ParsePluginRef resolves a plugin reference into config.Plugin fields
 // using the default built-in source.
 func ParsePluginRef(ref string) Plugin {
-	return ParsePluginRefWith(ref, DefaultSourceResolver())
+	// The built-in source is always a default, so short names resolve.
+	plugin, _ := ParsePluginRefWith(ref, DefaultSourceResolver())
+	return plugin
 }
 
 // ParsePluginRefWith resolves a plugin reference into config.Plugin fields
 // using the provided SourceResolver.
-func ParsePluginRefWith(ref string, resolver *SourceResolver) Plugin {
-	resolved := resolver.ResolvePluginRef(ref)
+func ParsePluginRefWith(ref string, resolver *SourceResolver) (Plugin, error) {
+	resolved, err := resolver.ResolvePluginRef(ref)
+	if err != nil {
+		return Plugin{}, err
+	}
 	repository, suffix := splitNameSuffix(resolved)
 
 	plugin := Plugin{Repository: repository}
@@ -388,7 +500,7 @@ func ParsePluginRefWith(ref string, resolver *SourceResolver) Plugin {
 		}
 	}
 
-	return plugin
+	return plugin, nil
 }
 
 func mergePlugins(personalityPlugins, userPlugins []Plugin) []Plugin {
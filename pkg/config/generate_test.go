@@ -59,6 +59,26 @@ func TestGenerateInstanceConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateInstanceConfig_Platform(t *testing.T) {
+	base := t.TempDir()
+	paths := &Paths{
+		ConfigDir:    base,
+		InstancesDir: filepath.Join(base, "instances"),
+	}
+
+	cfg, err := GenerateInstanceConfig(paths, CreateOptions{Name: "dev", Workspace: base, Platform: "linux/amd64"})
+	if err != nil {
+		t.Fatalf("GenerateInstanceConfig() returned error: %v", err)
+	}
+	if cfg.Platform != "linux/amd64" {
+		t.Errorf("Platform = %q, want linux/amd64", cfg.Platform)
+	}
+
+	if _, err := GenerateInstanceConfig(paths, CreateOptions{Name: "dev", Workspace: base, Platform: "x86"}); err == nil {
+		t.Error("expected an error for an invalid platform")
+	}
+}
+
 func TestGenerateInstanceConfig_PortConflict(t *testing.T) {
 	base := t.TempDir()
 	workspace := filepath.Join(base, "workspace")
@@ -133,6 +153,69 @@ func TestGenerateInstanceConfig_ResolvedPersonalityMergesPlugins(t *testing.T) {
 	}
 }
 
+func TestGenerateInstanceConfig_ImageOverridesPersonality(t *testing.T) {
+	base := t.TempDir()
+	workspace := filepath.Join(base, "workspace")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := &Paths{
+		ConfigDir:        base,
+		InstancesDir:     filepath.Join(base, "instances"),
+		PluginsDir:       filepath.Join(base, "plugins"),
+		PersonalitiesDir: filepath.Join(base, "personalities"),
+	}
+
+	cfg, err := GenerateInstanceConfig(paths, CreateOptions{
+		Name:        "dev",
+		Workspace:   workspace,
+		Personality: "sre",
+		Image:       "registry.example.com/custom/klaus:v1",
+		Context:     context.Background(),
+		ResolvePersonality: func(_ context.Context, _ string, _ io.Writer) (*ResolvedPersonality, error) {
+			return &ResolvedPersonality{
+				Image: "gsoci.azurecr.io/giantswarm/klaus-personality-image:latest",
+			}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("GenerateInstanceConfig() returned error: %v", err)
+	}
+
+	if cfg.Image != "registry.example.com/custom/klaus:v1" {
+		t.Fatalf("expected explicit image to win, got %s", cfg.Image)
+	}
+	if !cfg.ImageExplicitlySet() {
+		t.Fatal("expected explicit image to be marked as explicitly set")
+	}
+}
+
+func TestGenerateInstanceConfig_ImageAndToolchainConflict(t *testing.T) {
+	base := t.TempDir()
+	workspace := filepath.Join(base, "workspace")
+	if err := os.MkdirAll(workspace, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := &Paths{
+		ConfigDir:        base,
+		InstancesDir:     filepath.Join(base, "instances"),
+		PluginsDir:       filepath.Join(base, "plugins"),
+		PersonalitiesDir: filepath.Join(base, "personalities"),
+	}
+
+	_, err := GenerateInstanceConfig(paths, CreateOptions{
+		Name:      "dev",
+		Workspace: workspace,
+		Image:     "registry.example.com/custom/klaus:v1",
+		Toolchain: "go",
+	})
+	if err == nil {
+		t.Fatal("expected error for image and toolchain together")
+	}
+}
+
 func TestNextAvailablePort(t *testing.T) {
 	base := t.TempDir()
 	instDir := filepath.Join(base, "instances", "one")
@@ -574,3 +657,105 @@ func TestParsePluginRef(t *testing.T) {
 		t.Fatalf("unexpected tag: %s", p.Tag)
 	}
 }
+
+func TestResolveCreateWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	defaultDir := filepath.Join(home, "projects")
+	if err := os.MkdirAll(defaultDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig := func(t *testing.T, content string) *Paths {
+		t.Helper()
+		paths := &Paths{ConfigFile: filepath.Join(t.TempDir(), "config.yaml")}
+		if content != "" {
+			if err := os.WriteFile(paths.ConfigFile, []byte(content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return paths
+	}
+
+	tests := []struct {
+		name      string
+		config    string
+		workspace string
+		want      string
+		wantErr   string
+	}{
+		{name: "explicit workspace wins", config: "defaultWorkspace: ~/projects\n", workspace: "/explicit", want: "/explicit"},
+		{name: "default workspace with tilde", config: "defaultWorkspace: ~/projects\n", want: defaultDir},
+		{name: "absolute default workspace", config: "defaultWorkspace: " + defaultDir + "\n", want: defaultDir},
+		{name: "no config file falls back to cwd", want: cwd},
+		{name: "config without default falls back to cwd", config: "workspace: /tmp\n", want: cwd},
+		{name: "missing default workspace", config: "defaultWorkspace: ~/missing\n", wantErr: "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := writeConfig(t, tt.config)
+			got, err := ResolveCreateWorkspace(paths, tt.workspace)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ResolveCreateWorkspace() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveCreateWorkspace() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCreateWorkspace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCreateWorkspaceAuto(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.Mkdir(filepath.Join(repo, ".git"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	subdir := filepath.Join(repo, "cmd", "tool")
+	if err := os.MkdirAll(subdir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+
+	tests := []struct {
+		name      string
+		cwd       string
+		config    string
+		workspace string
+		want      string
+	}{
+		{name: "auto in a subdirectory", cwd: subdir, workspace: WorkspaceAuto, want: repo},
+		{name: "auto at the root", cwd: repo, workspace: WorkspaceAuto, want: repo},
+		{name: "default workspace auto", cwd: subdir, config: "defaultWorkspace: auto\n", want: repo},
+		{name: "auto outside a repository", cwd: outside, workspace: WorkspaceAuto, want: outside},
+		{name: "no workspace keeps cwd", cwd: subdir, want: subdir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := &Paths{ConfigFile: filepath.Join(t.TempDir(), "config.yaml")}
+			if tt.config != "" {
+				if err := os.WriteFile(paths.ConfigFile, []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Chdir(tt.cwd)
+
+			got, err := ResolveCreateWorkspace(paths, tt.workspace)
+			if err != nil {
+				t.Fatalf("ResolveCreateWorkspace() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveCreateWorkspace() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/klausctl/pkg/agentclient"
+)
+
+// Readiness probe defaults applied to a configured health section.
+const (
+	DefaultReadinessPath     = "/"
+	DefaultReadinessInterval = time.Second
+	DefaultReadinessTimeout  = 2 * time.Minute
+)
+
+// HealthConfig configures the readiness probe klausctl runs against the
+// instance port after starting it. When no field is set, klausctl polls the
+// agent's /status endpoint.
+type HealthConfig struct {
+	// ReadinessPath is the HTTP path that is polled until it answers with a
+	// non-5xx status. Defaults to "/".
+	ReadinessPath string `yaml:"readinessPath,omitempty"`
+	// ReadinessInterval is the time between polls, as a Go duration such
+	// as "500ms". Defaults to 1s.
+	ReadinessInterval string `yaml:"readinessInterval,omitempty"`
+	// ReadinessTimeout is how long to poll before giving up, as a Go
+	// duration such as "5m". Defaults to 2m.
+	ReadinessTimeout string `yaml:"readinessTimeout,omitempty"`
+}
+
+// ReadinessProbe returns the probe described by h with defaults applied,
+// or the zero probe when h is empty. h must have passed validation.
+func (h HealthConfig) ReadinessProbe() agentclient.Probe {
+	if h == (HealthConfig{}) {
+		return agentclient.Probe{}
+	}
+	probe := agentclient.Probe{
+		Path:     h.ReadinessPath,
+		Interval: DefaultReadinessInterval,
+		Timeout:  DefaultReadinessTimeout,
+	}
+	if probe.Path == "" {
+		probe.Path = DefaultReadinessPath
+	}
+	if d, err := time.ParseDuration(h.ReadinessInterval); err == nil {
+		probe.Interval = d
+	}
+	if d, err := time.ParseDuration(h.ReadinessTimeout); err == nil {
+		probe.Timeout = d
+	}
+	return probe
+}
+
+// LoadReadinessProbe loads the config file at path and returns its
+// readiness probe.
+func LoadReadinessProbe(path string) (agentclient.Probe, error) {
+	cfg, err := Load(path)
+	if err != nil {
+		return agentclient.Probe{}, err
+	}
+	return cfg.Health.ReadinessProbe(), nil
+}
+
+func (h HealthConfig) validate() error {
+	if h.ReadinessPath != "" && !strings.HasPrefix(h.ReadinessPath, "/") {
+		return fmt.Errorf("health.readinessPath must start with /, got %q", h.ReadinessPath)
+	}
+	if err := validatePositiveDuration("health.readinessInterval", h.ReadinessInterval); err != nil {
+		return err
+	}
+	return validatePositiveDuration("health.readinessTimeout", h.ReadinessTimeout)
+}
+
+// validatePositiveDuration checks that value is empty or a positive Go
+// duration.
+func validatePositiveDuration(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	if d <= 0 {
+		return fmt.Errorf("%s must be positive, got %q", name, value)
+	}
+	return nil
+}
@@ -2,10 +2,33 @@ package config
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
+// GitRoot returns the root of the git repository containing startDir: the
+// nearest directory, starting at startDir and walking up, that has a .git
+// directory or file (as in worktrees and submodules). It reports false when
+// startDir is not inside a repository.
+func GitRoot(startDir string) (string, bool) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
 // HostGitIdentity returns the git identity configured on the host
 // (git config user.name / user.email), evaluated from dir so that
 // per-repository identities take precedence over the global one.
@@ -63,3 +63,22 @@ func TestHostSigningKey_FromGitConfig(t *testing.T) {
 		t.Errorf("key = %q, want %q", key, "CAFEBABE")
 	}
 }
+
+func TestGitRoot(t *testing.T) {
+	// A worktree or submodule has a .git file instead of a directory.
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, ".git"), []byte("gitdir: /elsewhere\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(nested, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok := GitRoot(nested); !ok || got != repo {
+		t.Errorf("GitRoot(%q) = %q, %v, want %q, true", nested, got, ok, repo)
+	}
+	if got, ok := GitRoot(t.TempDir()); ok {
+		t.Errorf("GitRoot(outside) = %q, true, want not found", got)
+	}
+}
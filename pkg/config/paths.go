@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	ws "github.com/giantswarm/klausctl/pkg/workspace"
 )
@@ -25,12 +26,20 @@ type Paths struct {
 	RenderedDir string
 	// ExtensionsDir is the rendered extensions directory (skills, agents).
 	ExtensionsDir string
-	// PluginsDir is where OCI plugins are stored.
+	// PluginsDir is where OCI plugins are stored (~/.config/klausctl/plugins,
+	// or plugins/ under the cache directory override; see SetCacheDir).
 	PluginsDir string
-	// PersonalitiesDir is where OCI personalities are stored.
+	// PersonalitiesDir is where OCI personalities are stored, next to
+	// PluginsDir.
 	PersonalitiesDir string
 	// InstanceFile is the path to the instance state file.
 	InstanceFile string
+	// IdempotencyFile records the idempotency key of the create call that
+	// made the instance. Unlike InstanceFile it survives stop.
+	IdempotencyFile string
+	// UsageFile is the token usage and cost history of the instance's agent
+	// runs, one JSON object per line.
+	UsageFile string
 	// ArchivesDir is the directory for archived instance transcripts.
 	ArchivesDir string
 	// SecretsFile is the path to the secrets store (~/.config/klausctl/secrets.yaml).
@@ -81,6 +90,41 @@ type Paths struct {
 	AuthDir string
 }
 
+// EnvCacheDir is the env var that relocates the pulled plugins and
+// personalities. It is shared with the OCI registry cache (see
+// ocicache.EnvCacheDir), so one setting moves all cached artifacts.
+const EnvCacheDir = "KLAUSCTL_CACHE_DIR"
+
+var (
+	cacheDirMu sync.Mutex
+	cacheDir   string
+)
+
+// SetCacheDir relocates the pulled plugins and personalities to
+// plugins/ and personalities/ under dir for this process, as the global
+// --cache-dir flag does. It takes precedence over EnvCacheDir; an empty dir
+// falls back to it.
+func SetCacheDir(dir string) {
+	cacheDirMu.Lock()
+	defer cacheDirMu.Unlock()
+	cacheDir = strings.TrimSpace(dir)
+}
+
+// cacheDirOverride returns the cache directory set with SetCacheDir or
+// EnvCacheDir, or "" when the artifacts stay in the config directory.
+func cacheDirOverride() string {
+	cacheDirMu.Lock()
+	dir := cacheDir
+	cacheDirMu.Unlock()
+	if dir == "" {
+		dir = strings.TrimSpace(os.Getenv(EnvCacheDir))
+	}
+	if dir == "" {
+		return ""
+	}
+	return filepath.Clean(ExpandPath(dir))
+}
+
 // DefaultPaths returns the default paths using XDG conventions.
 // It returns an error if the user home directory cannot be determined
 // and XDG_CONFIG_HOME is not set.
@@ -98,6 +142,11 @@ func DefaultPaths() (*Paths, error) {
 		sourcesFile = filepath.Clean(override)
 	}
 
+	artifactsDir := base
+	if override := cacheDirOverride(); override != "" {
+		artifactsDir = override
+	}
+
 	musterDir := filepath.Join(base, "muster")
 	gatewayDir := filepath.Join(base, "gateway")
 
@@ -108,9 +157,11 @@ func DefaultPaths() (*Paths, error) {
 		InstanceDir:             defaultInstanceDir,
 		RenderedDir:             filepath.Join(defaultInstanceDir, "rendered"),
 		ExtensionsDir:           filepath.Join(defaultInstanceDir, "rendered", "extensions"),
-		PluginsDir:              filepath.Join(base, "plugins"),
-		PersonalitiesDir:        filepath.Join(base, "personalities"),
+		PluginsDir:              filepath.Join(artifactsDir, "plugins"),
+		PersonalitiesDir:        filepath.Join(artifactsDir, "personalities"),
 		InstanceFile:            filepath.Join(defaultInstanceDir, "instance.json"),
+		IdempotencyFile:         filepath.Join(defaultInstanceDir, "idempotency.json"),
+		UsageFile:               filepath.Join(defaultInstanceDir, "usage.jsonl"),
 		ArchivesDir:             filepath.Join(base, "archives"),
 		TokensDir:               filepath.Join(base, "tokens"),
 		SecretsFile:             filepath.Join(base, "secrets.yaml"),
@@ -195,6 +246,8 @@ func (p *Paths) ForInstance(name string) *Paths {
 		PluginsDir:              p.PluginsDir,
 		PersonalitiesDir:        p.PersonalitiesDir,
 		InstanceFile:            filepath.Join(instDir, "instance.json"),
+		IdempotencyFile:         filepath.Join(instDir, "idempotency.json"),
+		UsageFile:               filepath.Join(instDir, "usage.jsonl"),
 		ArchivesDir:             p.ArchivesDir,
 		TokensDir:               p.TokensDir,
 		SecretsFile:             p.SecretsFile,
@@ -269,6 +322,13 @@ func expandArtifactRef(ref, base string) string {
 	return base + "/" + name + suffix
 }
 
+// isShortName reports whether ref is a short name that expandArtifactRef
+// expands with a registry base.
+func isShortName(ref string) bool {
+	ref = strings.TrimSpace(ref)
+	return ref != "" && !strings.Contains(ref, "/")
+}
+
 func splitNameSuffix(ref string) (string, string) {
 	if idx := strings.Index(ref, "@"); idx >= 0 {
 		return ref[:idx], ref[idx:]
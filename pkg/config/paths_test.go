@@ -48,6 +48,46 @@ func TestDefaultPathsRespectsXDG(t *testing.T) {
 	}
 }
 
+func TestDefaultPathsCacheDirOverride(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	paths, err := DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(configDir, "klausctl", "plugins"); paths.PluginsDir != want {
+		t.Errorf("PluginsDir = %q, want %q", paths.PluginsDir, want)
+	}
+
+	envDir := t.TempDir()
+	t.Setenv(EnvCacheDir, envDir)
+	paths, err = DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(envDir, "plugins"); paths.PluginsDir != want {
+		t.Errorf("PluginsDir with %s = %q, want %q", EnvCacheDir, paths.PluginsDir, want)
+	}
+	if want := filepath.Join(envDir, "personalities"); paths.PersonalitiesDir != want {
+		t.Errorf("PersonalitiesDir with %s = %q, want %q", EnvCacheDir, paths.PersonalitiesDir, want)
+	}
+	if got := paths.ForInstance("dev").PluginsDir; got != paths.PluginsDir {
+		t.Errorf("ForInstance().PluginsDir = %q, want %q", got, paths.PluginsDir)
+	}
+
+	flagDir := t.TempDir()
+	SetCacheDir(flagDir)
+	t.Cleanup(func() { SetCacheDir("") })
+	paths, err = DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(flagDir, "plugins"); paths.PluginsDir != want {
+		t.Errorf("PluginsDir with SetCacheDir = %q, want %q", paths.PluginsDir, want)
+	}
+}
+
 func TestExpandPath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	if err != nil {
@@ -324,7 +364,7 @@ func TestResolveRefs(t *testing.T) {
 
 	tests := []struct {
 		name string
-		fn   func(string) string
+		fn   func(string) (string, error)
 		ref  string
 		want string
 	}{
@@ -380,7 +420,10 @@ func TestResolveRefs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.fn(tt.ref)
+			got, err := tt.fn(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if got != tt.want {
 				t.Errorf("got %q, want %q", got, tt.want)
 			}
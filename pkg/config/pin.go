@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FindPlugin returns the configured plugin whose repository or short name
+// (the last repository path segment, e.g. "gs-platform") is name.
+func (c *Config) FindPlugin(name string) (*Plugin, error) {
+	for i := range c.Plugins {
+		p := &c.Plugins[i]
+		if p.Repository == name || path.Base(p.Repository) == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("plugin %q is not configured", name)
+}
+
+// IsDigest reports whether a plugin version is a manifest digest such as
+// "sha256:..." rather than a tag.
+func IsDigest(version string) bool {
+	return strings.Contains(version, ":")
+}
+
+// PinPlugin returns the config file contents data with the plugin named
+// name locked to version: a digest sets the plugin's digest, anything else
+// its tag, and the other of the two is cleared.
+func PinPlugin(data []byte, name, version string) ([]byte, error) {
+	if version == "" {
+		return nil, fmt.Errorf("version is required")
+	}
+	return editPlugin(data, name, func(p *Plugin) {
+		p.Tag, p.Digest = version, ""
+		if IsDigest(version) {
+			p.Tag, p.Digest = "", version
+		}
+	})
+}
+
+// UnpinPlugin returns the config file contents data with the tag and digest
+// of the plugin named name cleared, so the latest version is resolved.
+func UnpinPlugin(data []byte, name string) ([]byte, error) {
+	return editPlugin(data, name, func(p *Plugin) {
+		p.Tag, p.Digest = "", ""
+	})
+}
+
+// SetPluginEnabled returns the config file contents data with the plugin
+// named name enabled or disabled. Enabling clears the field, since plugins
+// are enabled by default.
+func SetPluginEnabled(data []byte, name string, enabled bool) ([]byte, error) {
+	return editPlugin(data, name, func(p *Plugin) {
+		p.Enabled = nil
+		if !enabled {
+			p.Enabled = &enabled
+		}
+	})
+}
+
+// editPlugin applies edit to the plugin named name in data and marshals the
+// result. The config is validated but defaults are not applied, so they are
+// not written to the file.
+func editPlugin(data []byte, name string, edit func(*Plugin)) ([]byte, error) {
+	if _, err := Parse(data); err != nil {
+		return nil, err
+	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+	p, err := cfg.FindPlugin(name)
+	if err != nil {
+		return nil, err
+	}
+	edit(p)
+	out, err := cfg.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+const pinBaseConfig = `workspace: /tmp/test
+plugins:
+  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/gs-platform
+  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base
+    tag: v0.1.0
+`
+
+func TestPinUnpinPluginRoundTrip(t *testing.T) {
+	pinned, err := PinPlugin([]byte(pinBaseConfig), "gs-platform", "v1.2.0")
+	if err != nil {
+		t.Fatalf("PinPlugin() error = %v", err)
+	}
+	cfg, err := Parse(pinned)
+	if err != nil {
+		t.Fatalf("parsing pinned config: %v", err)
+	}
+	if p := cfg.Plugins[0]; p.Tag != "v1.2.0" || p.Digest != "" {
+		t.Errorf("pinned plugin = %+v, want tag v1.2.0", p)
+	}
+	if p := cfg.Plugins[1]; p.Tag != "v0.1.0" {
+		t.Errorf("other plugin tag = %q, want v0.1.0", p.Tag)
+	}
+
+	const digest = "sha256:0123456789abcdef"
+	pinned, err = PinPlugin(pinned, "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-platform", digest)
+	if err != nil {
+		t.Fatalf("PinPlugin(digest) error = %v", err)
+	}
+	cfg, err = Parse(pinned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cfg.Plugins[0]; p.Tag != "" || p.Digest != digest {
+		t.Errorf("digest-pinned plugin = %+v, want only digest %s", p, digest)
+	}
+
+	unpinned, err := UnpinPlugin(pinned, "gs-platform")
+	if err != nil {
+		t.Fatalf("UnpinPlugin() error = %v", err)
+	}
+	cfg, err = Parse(unpinned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := cfg.Plugins[0]; p.Tag != "" || p.Digest != "" {
+		t.Errorf("unpinned plugin = %+v, want no tag or digest", p)
+	}
+
+	// Defaults are applied when loading, not written to the file.
+	if strings.Contains(string(unpinned), "bypassPermissions") {
+		t.Errorf("unpinned config contains defaults:\n%s", unpinned)
+	}
+}
+
+func TestPinPluginErrors(t *testing.T) {
+	if _, err := PinPlugin([]byte(pinBaseConfig), "gs-missing", "v1.0.0"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("PinPlugin(unknown) error = %v, want not configured", err)
+	}
+	if _, err := PinPlugin([]byte(pinBaseConfig), "gs-platform", ""); err == nil {
+		t.Error("PinPlugin() with empty version succeeded")
+	}
+	if _, err := UnpinPlugin([]byte("plugins: []\n"), "gs-platform"); err == nil {
+		t.Error("UnpinPlugin() on an invalid config succeeded")
+	}
+}
+
+func TestSetPluginEnabled(t *testing.T) {
+	disabled, err := SetPluginEnabled([]byte(pinBaseConfig), "gs-base", false)
+	if err != nil {
+		t.Fatalf("SetPluginEnabled(false) error = %v", err)
+	}
+	cfg, err := Parse(disabled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Plugins[0].IsEnabled() || cfg.Plugins[1].IsEnabled() {
+		t.Errorf("plugins = %+v, want only gs-base disabled", cfg.Plugins)
+	}
+	if cfg.Plugins[1].Tag != "v0.1.0" {
+		t.Errorf("disabled plugin tag = %q, want v0.1.0 kept", cfg.Plugins[1].Tag)
+	}
+
+	enabled, err := SetPluginEnabled(disabled, "gs-base", true)
+	if err != nil {
+		t.Fatalf("SetPluginEnabled(true) error = %v", err)
+	}
+	if strings.Contains(string(enabled), "enabled:") {
+		t.Errorf("enabled plugin still has the field:\n%s", enabled)
+	}
+
+	if _, err := SetPluginEnabled([]byte(pinBaseConfig), "missing", false); err == nil {
+		t.Error("disabling an unconfigured plugin succeeded")
+	}
+}
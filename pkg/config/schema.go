@@ -0,0 +1,139 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// schemaDialect is the JSON Schema dialect of the schema returned by Schema.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// schemaEnums lists the allowed values of string fields by schema path, the
+// dotted yaml keys of the field with "*" for map values and "[]" for list
+// items. They reuse the slices Validate checks against so the schema cannot
+// drift from the loader.
+var schemaEnums = map[string][]string{
+	"runtime":                 {"docker", "podman"},
+	"restartPolicy":           validRestartPolicies,
+	"pullPolicy":              validPullPolicies,
+	"workspaceMode":           validWorkspaceModes,
+	"claude.permissionMode":   ValidPermissionModes,
+	"claude.effort":           ValidEffortLevels,
+	"git.credentialHelper":    validCredentialHelpers,
+	"agents.*.permissionMode": ValidPermissionModes,
+}
+
+// schemaMinimums and schemaMaximums bound numeric fields by schema path,
+// mirroring the range checks in Validate.
+var (
+	schemaMinimums = map[string]float64{
+		"version":             0,
+		"port":                1,
+		"containerPort":       0,
+		"stopTimeout":         0,
+		"claude.maxTurns":     0,
+		"claude.maxBudgetUsd": 0,
+	}
+	schemaMaximums = map[string]float64{
+		"port":          65535,
+		"containerPort": 65535,
+	}
+)
+
+// Schema returns a JSON Schema describing the config file, generated from
+// the Config struct: object keys follow the yaml tags, Go types map to JSON
+// types, and known valid values become enums. Unknown keys are rejected so
+// editors flag typos, which the loader silently ignores.
+//
+// The result is suitable for encoding/json and for YAML language servers
+// (e.g. `# yaml-language-server: $schema=<file>`).
+func Schema() map[string]any {
+	s := typeSchema(reflect.TypeOf(Config{}), "")
+	s["$schema"] = schemaDialect
+	s["title"] = "klausctl config"
+	return s
+}
+
+// typeSchema returns the schema of t found at path.
+func typeSchema(t reflect.Type, path string) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem(), path)
+	case reflect.String:
+		s := map[string]any{"type": "string"}
+		if enum, ok := schemaEnums[path]; ok {
+			s["enum"] = enum
+		}
+		return s
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withBounds(map[string]any{"type": "integer"}, path)
+	case reflect.Float32, reflect.Float64:
+		return withBounds(map[string]any{"type": "number"}, path)
+	case reflect.Slice, reflect.Array:
+		return map[string]any{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), path+"[]"),
+		}
+	case reflect.Map:
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), schemaPath(path, "*")),
+		}
+	case reflect.Struct:
+		return structSchema(t, path)
+	default:
+		// Interfaces (any) accept every value.
+		return map[string]any{}
+	}
+}
+
+// structSchema describes the exported, yaml-visible fields of t.
+func structSchema(t reflect.Type, path string) map[string]any {
+	properties := map[string]any{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := yamlFieldName(f)
+		if name == "-" {
+			continue
+		}
+		properties[name] = typeSchema(f.Type, schemaPath(path, name))
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// yamlFieldName returns the key of struct field f in the config file, or "-"
+// for fields that are not serialized.
+func yamlFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+	if name == "" {
+		name = strings.ToLower(f.Name)
+	}
+	return name
+}
+
+func withBounds(s map[string]any, path string) map[string]any {
+	if v, ok := schemaMinimums[path]; ok {
+		s["minimum"] = v
+	}
+	if v, ok := schemaMaximums[path]; ok {
+		s["maximum"] = v
+	}
+	return s
+}
+
+func schemaPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// schemaAt walks the properties of s along a dotted yaml key path.
+func schemaAt(t *testing.T, s map[string]any, path string) map[string]any {
+	t.Helper()
+	for _, key := range strings.Split(path, ".") {
+		props, ok := s["properties"].(map[string]any)
+		if !ok {
+			t.Fatalf("schema at %q has no properties", path)
+		}
+		s, ok = props[key].(map[string]any)
+		if !ok {
+			t.Fatalf("schema has no property %q (path %q)", key, path)
+		}
+	}
+	return s
+}
+
+func TestSchemaCoversConfigFields(t *testing.T) {
+	s := Schema()
+	if s["$schema"] != schemaDialect {
+		t.Errorf("$schema = %v, want %s", s["$schema"], schemaDialect)
+	}
+
+	cfgType := reflect.TypeOf(Config{})
+	for i := range cfgType.NumField() {
+		f := cfgType.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		schemaAt(t, s, name)
+	}
+	if _, ok := s["properties"].(map[string]any)["imageFromConfig"]; ok {
+		t.Error("schema must not describe unexported fields")
+	}
+}
+
+func TestSchemaTypes(t *testing.T) {
+	s := Schema()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "workspace", want: "string"},
+		{path: "port", want: "integer"},
+		{path: "claude", want: "object"},
+		{path: "claude.maxBudgetUsd", want: "number"},
+		{path: "claude.loadAdditionalDirsMemory", want: "boolean"},
+		{path: "claude.tools", want: "array"},
+		{path: "envVars", want: "object"},
+		{path: "plugins", want: "array"},
+	}
+	for _, tt := range tests {
+		if got := schemaAt(t, s, tt.path)["type"]; got != tt.want {
+			t.Errorf("type of %s = %v, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	plugin := schemaAt(t, s, "plugins")["items"].(map[string]any)
+	if _, ok := plugin["properties"].(map[string]any)["repository"]; !ok {
+		t.Error("expected plugin items to describe repository")
+	}
+	envVars := schemaAt(t, s, "envVars")["additionalProperties"].(map[string]any)
+	if envVars["type"] != "string" {
+		t.Errorf("envVars values type = %v, want string", envVars["type"])
+	}
+}
+
+func TestSchemaEnumsAndBounds(t *testing.T) {
+	s := Schema()
+
+	enums := map[string][]string{
+		"claude.permissionMode": ValidPermissionModes,
+		"claude.effort":         ValidEffortLevels,
+		"git.credentialHelper":  validCredentialHelpers,
+		"runtime":               {"docker", "podman"},
+	}
+	for path, want := range enums {
+		got, _ := schemaAt(t, s, path)["enum"].([]string)
+		if !slices.Equal(got, want) {
+			t.Errorf("enum of %s = %v, want %v", path, got, want)
+		}
+	}
+
+	agent := schemaAt(t, s, "agents")["additionalProperties"].(map[string]any)
+	mode := agent["properties"].(map[string]any)["permissionMode"].(map[string]any)
+	if !slices.Equal(mode["enum"].([]string), ValidPermissionModes) {
+		t.Errorf("agents.*.permissionMode enum = %v", mode["enum"])
+	}
+
+	port := schemaAt(t, s, "port")
+	if port["minimum"] != 1.0 || port["maximum"] != 65535.0 {
+		t.Errorf("port bounds = %v..%v, want 1..65535", port["minimum"], port["maximum"])
+	}
+}
+
+func TestSchemaIsValidJSON(t *testing.T) {
+	data, err := json.Marshal(Schema())
+	if err != nil {
+		t.Fatalf("marshaling schema: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if decoded["additionalProperties"] != false {
+		t.Error("expected unknown top-level keys to be rejected")
+	}
+}
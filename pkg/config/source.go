@@ -1,14 +1,19 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/klausctl/pkg/filelock"
 )
 
 const (
@@ -24,12 +29,29 @@ const (
 
 // Source is a named OCI registry providing toolchains, personalities, and/or plugins.
 type Source struct {
-	Name          string `yaml:"name"`
-	Registry      string `yaml:"registry"`
-	Default       bool   `yaml:"default,omitempty"`
-	Toolchains    string `yaml:"toolchains,omitempty"`
-	Personalities string `yaml:"personalities,omitempty"`
-	Plugins       string `yaml:"plugins,omitempty"`
+	Name          string `yaml:"name" json:"name"`
+	Registry      string `yaml:"registry" json:"registry"`
+	Default       bool   `yaml:"default,omitempty" json:"default,omitempty"`
+	Toolchains    string `yaml:"toolchains,omitempty" json:"toolchains,omitempty"`
+	Personalities string `yaml:"personalities,omitempty" json:"personalities,omitempty"`
+	Plugins       string `yaml:"plugins,omitempty" json:"plugins,omitempty"`
+	// Mirrors are registry bases serving the same artifacts as Registry.
+	// Pulls try them in order before falling back to Registry.
+	Mirrors []string `yaml:"mirrors,omitempty" json:"mirrors,omitempty"`
+}
+
+// MirrorRefs returns ref rewritten to each mirror of the source, in order.
+// It returns nil when ref does not point into the source's registry.
+func (s Source) MirrorRefs(ref string) []string {
+	prefix := s.Registry + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return nil
+	}
+	refs := make([]string, 0, len(s.Mirrors))
+	for _, m := range s.Mirrors {
+		refs = append(refs, strings.TrimSuffix(m, "/")+"/"+strings.TrimPrefix(ref, prefix))
+	}
+	return refs
 }
 
 // ToolchainRegistry returns the toolchain base path for this source.
@@ -61,8 +83,16 @@ func (s Source) PluginRegistry() string {
 
 // SourceConfig holds the list of configured sources.
 type SourceConfig struct {
-	Sources []Source `yaml:"sources"`
-	path    string
+	// NoDefault records that the user cleared the default source, so that
+	// short names must be qualified and the built-in source does not become
+	// the default again.
+	NoDefault bool     `yaml:"noDefault,omitempty" json:"noDefault,omitempty"`
+	Sources   []Source `yaml:"sources" json:"sources"`
+	path      string
+	// lock is held on the file at lockedPath from LockSourceConfig until
+	// Unlock.
+	lock       *filelock.Lock
+	lockedPath string
 }
 
 // SourceRegistry pairs a source name with a registry base path.
@@ -124,6 +154,38 @@ func LoadSourceConfig(path string) (*SourceConfig, error) {
 	return sc, nil
 }
 
+// LockSourceConfig acquires the file lock of the sources file at path and
+// then loads it like LoadSourceConfig. Holding the lock across the whole
+// load-modify-save cycle keeps concurrent writers from losing each other's
+// changes; Save writes under the held lock. The caller must call Unlock.
+func LockSourceConfig(path string) (*SourceConfig, error) {
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("creating sources config directory: %w", err)
+	}
+	lock, err := filelock.Acquire(filelock.LockPath(path))
+	if err != nil {
+		return nil, err
+	}
+	sc, err := LoadSourceConfig(path)
+	if err != nil {
+		_ = lock.Unlock()
+		return nil, err
+	}
+	sc.lock, sc.lockedPath = lock, path
+	return sc, nil
+}
+
+// Unlock releases the lock acquired by LockSourceConfig. It does nothing
+// for a source config loaded without it.
+func (sc *SourceConfig) Unlock() error {
+	if sc.lock == nil {
+		return nil
+	}
+	err := sc.lock.Unlock()
+	sc.lock, sc.lockedPath = nil, ""
+	return err
+}
+
 // Save writes the source config to disk.
 func (sc *SourceConfig) Save() error {
 	if sc.path == "" {
@@ -132,19 +194,58 @@ func (sc *SourceConfig) Save() error {
 	return sc.SaveTo(sc.path)
 }
 
-// SaveTo writes the source config to the specified path.
+// SaveTo writes the source config to the specified path, holding a file
+// lock so that concurrent writers do not interleave. The lock held since
+// LockSourceConfig is used when path is the file it was loaded from.
 func (sc *SourceConfig) SaveTo(path string) error {
-	data, err := yaml.Marshal(sc)
-	if err != nil {
-		return fmt.Errorf("serializing sources config: %w", err)
+	var buf bytes.Buffer
+	if err := sc.Encode(&buf); err != nil {
+		return err
 	}
-	if err := os.WriteFile(path, data, 0o600); err != nil {
+	write := filelock.WriteFile
+	if sc.lock != nil && path == sc.lockedPath {
+		write = filelock.ReplaceFile
+	}
+	if err := write(path, buf.Bytes(), 0o600); err != nil {
 		return fmt.Errorf("writing sources config: %w", err)
 	}
 	sc.path = path
 	return nil
 }
 
+// Encode writes the source config to w in the sources file format.
+func (sc *SourceConfig) Encode(w io.Writer) error {
+	data, err := yaml.Marshal(sc)
+	if err != nil {
+		return fmt.Errorf("serializing sources config: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Export returns a copy of the source config for sharing. Unless
+// includeBuiltin is set, the built-in source is left out when it has no
+// customizations, since loading re-adds it anyway.
+func (sc *SourceConfig) Export(includeBuiltin bool) *SourceConfig {
+	out := &SourceConfig{NoDefault: sc.NoDefault, Sources: make([]Source, 0, len(sc.Sources))}
+	for _, s := range sc.Sources {
+		if !includeBuiltin && isImplicitBuiltin(s) {
+			continue
+		}
+		out.Sources = append(out.Sources, s)
+	}
+	return out
+}
+
+// isImplicitBuiltin reports whether s is the built-in source as
+// ensureBuiltin adds it, regardless of whether it is the default.
+func isImplicitBuiltin(s Source) bool {
+	b := builtinSource()
+	return s.Name == b.Name && s.Registry == b.Registry &&
+		s.Toolchains == "" && s.Personalities == "" && s.Plugins == "" &&
+		len(s.Mirrors) == 0
+}
+
 // Validate checks the source configuration for errors.
 func (sc *SourceConfig) Validate() error {
 	seen := make(map[string]bool, len(sc.Sources))
@@ -159,6 +260,9 @@ func (sc *SourceConfig) Validate() error {
 		if seen[s.Name] {
 			return fmt.Errorf("duplicate source name %q", s.Name)
 		}
+		if err := validateMirrors(s); err != nil {
+			return err
+		}
 		seen[s.Name] = true
 		if s.Default {
 			defaultCount++
@@ -167,11 +271,33 @@ func (sc *SourceConfig) Validate() error {
 	if defaultCount > 1 {
 		return fmt.Errorf("multiple sources marked as default; only one is allowed")
 	}
+	if defaultCount > 0 && sc.NoDefault {
+		return fmt.Errorf("noDefault is set but a source is marked as default")
+	}
+	return nil
+}
+
+// validateMirrors checks that the mirrors of s are set, distinct and differ
+// from its registry.
+func validateMirrors(s Source) error {
+	seen := make(map[string]bool, len(s.Mirrors))
+	for _, m := range s.Mirrors {
+		switch {
+		case m == "":
+			return fmt.Errorf("source %q: mirror must not be empty", s.Name)
+		case m == s.Registry:
+			return fmt.Errorf("source %q: mirror %q is the registry itself", s.Name, m)
+		case seen[m]:
+			return fmt.Errorf("source %q: duplicate mirror %q", s.Name, m)
+		}
+		seen[m] = true
+	}
 	return nil
 }
 
 // ensureBuiltin ensures the built-in Giant Swarm source is always present.
-// If no other source is marked as default, the builtin gets Default: true.
+// If no other source is marked as default, the builtin gets Default: true,
+// unless the default was cleared (NoDefault).
 func (sc *SourceConfig) ensureBuiltin() {
 	hasDefault := false
 	for _, s := range sc.Sources {
@@ -183,7 +309,7 @@ func (sc *SourceConfig) ensureBuiltin() {
 		}
 	}
 	b := builtinSource()
-	if hasDefault {
+	if hasDefault || sc.NoDefault {
 		b.Default = false
 	}
 	sc.Sources = append([]Source{b}, sc.Sources...)
@@ -202,6 +328,9 @@ func (sc *SourceConfig) Add(s Source) error {
 	if s.Registry == "" {
 		return fmt.Errorf("registry is required")
 	}
+	if err := validateMirrors(s); err != nil {
+		return err
+	}
 	sc.Sources = append(sc.Sources, s)
 	return nil
 }
@@ -220,6 +349,27 @@ func (sc *SourceConfig) Remove(name string) error {
 	return fmt.Errorf("source %q not found", name)
 }
 
+// Rename changes the name of a source, keeping its registry, overrides and
+// default status. The built-in source cannot be renamed, and newName must be
+// a valid name that is not already in use.
+func (sc *SourceConfig) Rename(oldName, newName string) error {
+	if oldName == DefaultSourceName {
+		return fmt.Errorf("cannot rename built-in source %q", DefaultSourceName)
+	}
+	if err := ValidateSourceName(newName); err != nil {
+		return err
+	}
+	if sc.Get(newName) != nil {
+		return fmt.Errorf("source %q already exists", newName)
+	}
+	s := sc.Get(oldName)
+	if s == nil {
+		return fmt.Errorf("source %q not found", oldName)
+	}
+	s.Name = newName
+	return nil
+}
+
 // SetDefault marks the named source as default (and clears default on all others).
 func (sc *SourceConfig) SetDefault(name string) error {
 	found := false
@@ -234,6 +384,35 @@ func (sc *SourceConfig) SetDefault(name string) error {
 	if !found {
 		return fmt.Errorf("source %q not found", name)
 	}
+	sc.NoDefault = false
+	return nil
+}
+
+// ClearDefault unmarks the default source, so that short names no longer
+// resolve and must be qualified with a registry.
+func (sc *SourceConfig) ClearDefault() {
+	for i := range sc.Sources {
+		sc.Sources[i].Default = false
+	}
+	sc.NoDefault = true
+}
+
+// Resolver returns a SourceResolver over the sources. Without a default
+// source (NoDefault), it refuses to resolve short names.
+func (sc *SourceConfig) Resolver() *SourceResolver {
+	r := NewSourceResolver(sc.Sources)
+	r.noDefault = sc.NoDefault
+	return r
+}
+
+// Default returns the current default source, or nil if none is marked as
+// default.
+func (sc *SourceConfig) Default() *Source {
+	for i := range sc.Sources {
+		if sc.Sources[i].Default {
+			return &sc.Sources[i]
+		}
+	}
 	return nil
 }
 
@@ -248,21 +427,33 @@ func (sc *SourceConfig) Get(name string) *Source {
 }
 
 // Update modifies an existing source. Only non-empty fields in the
-// provided Source are applied (registry, toolchains, personalities, plugins).
-// Use ClearOverride ("-") as a field value to reset an override back to the
-// convention-based default.
+// provided Source are applied (registry, toolchains, personalities, plugins,
+// mirrors). Use ClearOverride ("-") as a field value to reset an override
+// back to the convention-based default; mirrors of []string{ClearOverride}
+// remove all mirrors.
 // Returns an error if the source is not found.
 func (sc *SourceConfig) Update(name string, patch Source) error {
 	for i := range sc.Sources {
 		if sc.Sources[i].Name != name {
 			continue
 		}
+		updated := sc.Sources[i]
 		if patch.Registry != "" {
-			sc.Sources[i].Registry = patch.Registry
+			updated.Registry = patch.Registry
 		}
-		applyOverride(&sc.Sources[i].Toolchains, patch.Toolchains)
-		applyOverride(&sc.Sources[i].Personalities, patch.Personalities)
-		applyOverride(&sc.Sources[i].Plugins, patch.Plugins)
+		applyOverride(&updated.Toolchains, patch.Toolchains)
+		applyOverride(&updated.Personalities, patch.Personalities)
+		applyOverride(&updated.Plugins, patch.Plugins)
+		switch {
+		case slices.Equal(patch.Mirrors, []string{ClearOverride}):
+			updated.Mirrors = nil
+		case len(patch.Mirrors) > 0:
+			updated.Mirrors = patch.Mirrors
+		}
+		if err := validateMirrors(updated); err != nil {
+			return err
+		}
+		sc.Sources[i] = updated
 		return nil
 	}
 	return fmt.Errorf("source %q not found", name)
@@ -285,6 +476,9 @@ func applyOverride(dst *string, value string) {
 // The default source (if any) is placed first for short-name resolution priority.
 type SourceResolver struct {
 	sources []Source
+	// noDefault makes the resolver refuse short names; see
+	// SourceConfig.NoDefault.
+	noDefault bool
 }
 
 // NewSourceResolver creates a resolver from the given sources.
@@ -325,29 +519,41 @@ func (r *SourceResolver) ForSource(name string) (*SourceResolver, error) {
 }
 
 // DefaultOnly returns a resolver restricted to the default source (the
-// first source after default-first ordering).
+// first source after default-first ordering). Without a default source,
+// the returned resolver still refuses short names.
 // The SourceResolver constructor guarantees at least one source is always
 // present, so this is safe; the guard is purely defensive.
 func (r *SourceResolver) DefaultOnly() *SourceResolver {
 	if len(r.sources) == 0 {
 		return DefaultSourceResolver()
 	}
-	return NewSourceResolver([]Source{r.sources[0]})
+	d := NewSourceResolver([]Source{r.sources[0]})
+	d.noDefault = r.noDefault
+	return d
 }
 
 // ResolvePluginRef expands a short plugin name using the default source.
-func (r *SourceResolver) ResolvePluginRef(ref string) string {
-	return expandArtifactRef(ref, r.sources[0].PluginRegistry())
+func (r *SourceResolver) ResolvePluginRef(ref string) (string, error) {
+	return r.expand(ref, r.sources[0].PluginRegistry())
 }
 
 // ResolvePersonalityRef expands a short personality name using the default source.
-func (r *SourceResolver) ResolvePersonalityRef(ref string) string {
-	return expandArtifactRef(ref, r.sources[0].PersonalityRegistry())
+func (r *SourceResolver) ResolvePersonalityRef(ref string) (string, error) {
+	return r.expand(ref, r.sources[0].PersonalityRegistry())
 }
 
 // ResolveToolchainRef expands a short toolchain name using the default source.
-func (r *SourceResolver) ResolveToolchainRef(ref string) string {
-	return expandArtifactRef(ref, r.sources[0].ToolchainRegistry())
+func (r *SourceResolver) ResolveToolchainRef(ref string) (string, error) {
+	return r.expand(ref, r.sources[0].ToolchainRegistry())
+}
+
+// expand expands ref with base unless the resolver has no default source
+// and ref is a short name.
+func (r *SourceResolver) expand(ref, base string) (string, error) {
+	if r.noDefault && isShortName(ref) {
+		return "", fmt.Errorf("cannot resolve short name %q: no default source is set; use a full reference, choose a source with --source, or set a default with 'klausctl source set-default <name>'", strings.TrimSpace(ref))
+	}
+	return expandArtifactRef(ref, base), nil
 }
 
 // PluginRegistries returns all plugin registry bases with source annotations.
@@ -377,6 +583,18 @@ func (r *SourceResolver) ToolchainRegistries() []SourceRegistry {
 	return result
 }
 
+// MirrorRefs returns the references to try when pulling ref: ref rewritten
+// to each mirror of the source whose registry ref points into, followed by
+// ref itself.
+func (r *SourceResolver) MirrorRefs(ref string) []string {
+	for _, s := range r.sources {
+		if mirrors := s.MirrorRefs(ref); len(mirrors) > 0 {
+			return append(mirrors, ref)
+		}
+	}
+	return []string{ref}
+}
+
 // Sources returns a copy of the underlying list of sources.
 func (r *SourceResolver) Sources() []Source {
 	return slices.Clone(r.sources)
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -151,6 +152,77 @@ func TestSourceConfigSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestLockSourceConfigConcurrentUpdates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "klausctl", "sources.yaml")
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc, err := LockSourceConfig(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer func() { _ = sc.Unlock() }()
+			if err := sc.Add(Source{Name: fmt.Sprintf("team-%d", i), Registry: "reg.example.com/team"}); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := sc.Save(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	loaded, err := LoadSourceConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Sources) != 9 {
+		t.Errorf("got %d sources, want the builtin and all 8 added concurrently", len(loaded.Sources))
+	}
+}
+
+func TestSourceConfigExport(t *testing.T) {
+	sc := DefaultSourceConfig()
+	if err := sc.Add(Source{Name: "team-a", Registry: "reg.example.com/a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	exported := sc.Export(false)
+	if len(exported.Sources) != 1 || exported.Sources[0].Name != "team-a" {
+		t.Fatalf("Export(false) = %+v, want only team-a", exported.Sources)
+	}
+	if got := sc.Export(true); len(got.Sources) != 2 {
+		t.Errorf("Export(true) = %+v, want the builtin and team-a", got.Sources)
+	}
+
+	// A customized builtin is kept, since loading would not restore it.
+	sc.Sources[0].Mirrors = []string{"mirror.example.com/giantswarm"}
+	if got := sc.Export(false); len(got.Sources) != 2 {
+		t.Errorf("Export(false) with customized builtin = %+v, want both sources", got.Sources)
+	}
+
+	var buf strings.Builder
+	if err := exported.Encode(&buf); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	if err := os.WriteFile(path, []byte(buf.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSourceConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSourceConfig() error = %v", err)
+	}
+	if len(loaded.Sources) != 2 || loaded.Default().Name != DefaultSourceName {
+		t.Errorf("loaded export = %+v, want the builtin re-added as default", loaded.Sources)
+	}
+}
+
 func TestSourceConfigAdd_Duplicate(t *testing.T) {
 	sc := DefaultSourceConfig()
 	err := sc.Add(Source{Name: DefaultSourceName, Registry: "whatever"})
@@ -203,6 +275,63 @@ func TestSourceConfigRemove_NotFound(t *testing.T) {
 	}
 }
 
+func TestSourceConfigRename(t *testing.T) {
+	sc := DefaultSourceConfig()
+	_ = sc.Add(Source{Name: "team-a", Registry: "reg.example.com/a", Plugins: "reg.example.com/a/p"})
+	_ = sc.SetDefault("team-a")
+
+	if err := sc.Rename("team-a", "team-b"); err != nil {
+		t.Fatalf("Rename() returned error: %v", err)
+	}
+	if sc.Get("team-a") != nil {
+		t.Error("team-a should no longer exist")
+	}
+	s := sc.Get("team-b")
+	if s == nil {
+		t.Fatal("team-b should exist")
+	}
+	if s.Registry != "reg.example.com/a" || s.Plugins != "reg.example.com/a/p" || !s.Default {
+		t.Errorf("renamed source lost its settings: %+v", *s)
+	}
+}
+
+func TestSourceConfigRename_Builtin(t *testing.T) {
+	sc := DefaultSourceConfig()
+	if err := sc.Rename(DefaultSourceName, "other"); err == nil {
+		t.Fatal("expected error when renaming built-in source")
+	}
+}
+
+func TestSourceConfigRename_Duplicate(t *testing.T) {
+	sc := DefaultSourceConfig()
+	_ = sc.Add(Source{Name: "team-a", Registry: "reg.example.com/a"})
+	_ = sc.Add(Source{Name: "team-b", Registry: "reg.example.com/b"})
+
+	for _, newName := range []string{"team-b", DefaultSourceName} {
+		if err := sc.Rename("team-a", newName); err == nil {
+			t.Errorf("expected error when renaming to existing source %q", newName)
+		}
+	}
+	if sc.Get("team-a") == nil {
+		t.Error("team-a should be unchanged after failed renames")
+	}
+}
+
+func TestSourceConfigRename_InvalidName(t *testing.T) {
+	sc := DefaultSourceConfig()
+	_ = sc.Add(Source{Name: "team-a", Registry: "reg.example.com/a"})
+	if err := sc.Rename("team-a", "bad_name"); err == nil {
+		t.Fatal("expected error for invalid new name")
+	}
+}
+
+func TestSourceConfigRename_NotFound(t *testing.T) {
+	sc := DefaultSourceConfig()
+	if err := sc.Rename("nonexistent", "other"); err == nil {
+		t.Fatal("expected error when renaming nonexistent source")
+	}
+}
+
 func TestSourceConfigSetDefault(t *testing.T) {
 	sc := DefaultSourceConfig()
 	_ = sc.Add(Source{Name: "team-b", Registry: "reg.example.com/b"})
@@ -229,6 +358,57 @@ func TestSourceConfigSetDefault_NotFound(t *testing.T) {
 	}
 }
 
+func TestSourceConfigClearDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	sc := DefaultSourceConfig()
+	_ = sc.Add(Source{Name: "team-b", Registry: "reg.example.com/b"})
+	sc.ClearDefault()
+	if err := sc.SaveTo(path); err != nil {
+		t.Fatalf("SaveTo() returned error: %v", err)
+	}
+
+	loaded, err := LoadSourceConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSourceConfig() returned error: %v", err)
+	}
+	if !loaded.NoDefault || loaded.Default() != nil {
+		t.Fatalf("NoDefault = %v, Default() = %+v; want no default", loaded.NoDefault, loaded.Default())
+	}
+
+	if err := loaded.SetDefault("team-b"); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NoDefault {
+		t.Error("SetDefault() should reset NoDefault")
+	}
+}
+
+func TestSourceConfigValidate_NoDefaultWithDefault(t *testing.T) {
+	sc := DefaultSourceConfig()
+	sc.NoDefault = true
+	if err := sc.Validate(); err == nil {
+		t.Fatal("expected validation error for noDefault with a default source")
+	}
+}
+
+func TestSourceConfigDefault(t *testing.T) {
+	sc := DefaultSourceConfig()
+	if d := sc.Default(); d == nil || d.Name != DefaultSourceName {
+		t.Fatalf("Default() = %v, want %q", d, DefaultSourceName)
+	}
+
+	_ = sc.Add(Source{Name: "team-b", Registry: "reg.example.com/b"})
+	_ = sc.SetDefault("team-b")
+	if d := sc.Default(); d == nil || d.Name != "team-b" {
+		t.Fatalf("Default() = %v, want team-b", d)
+	}
+
+	sc.Sources[1].Default = false
+	if d := sc.Default(); d != nil {
+		t.Fatalf("Default() = %v, want nil", d)
+	}
+}
+
 func TestSourceConfigGet(t *testing.T) {
 	sc := DefaultSourceConfig()
 	s := sc.Get(DefaultSourceName)
@@ -308,9 +488,71 @@ func TestEnsureBuiltin_RespectsExistingDefault(t *testing.T) {
 	}
 }
 
+func TestEnsureBuiltin_RespectsNoDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sources.yaml")
+	content := `noDefault: true
+sources:
+  - name: my-team
+    registry: myregistry.example.com/my-team
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := LoadSourceConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSourceConfig() returned error: %v", err)
+	}
+	if sc.Get(DefaultSourceName) == nil {
+		t.Fatal("built-in source should be injected")
+	}
+	if d := sc.Default(); d != nil {
+		t.Errorf("Default() = %q, want none", d.Name)
+	}
+}
+
+func TestSourceResolverNoDefault(t *testing.T) {
+	sc := DefaultSourceConfig()
+	_ = sc.Add(Source{Name: "team", Registry: "team.io/x"})
+	sc.ClearDefault()
+	r := sc.Resolver()
+
+	for name, fn := range map[string]func(string) (string, error){
+		"plugin":      r.ResolvePluginRef,
+		"personality": r.ResolvePersonalityRef,
+		"toolchain":   r.DefaultOnly().ResolveToolchainRef,
+	} {
+		if _, err := fn("sre"); err == nil || !strings.Contains(err.Error(), "set-default") {
+			t.Errorf("%s: short name error = %v, want a hint to set a default", name, err)
+		}
+		got, err := fn("other.io/repo/sre:v1.0.0")
+		if err != nil || got != "other.io/repo/sre:v1.0.0" {
+			t.Errorf("%s: full ref = %q, %v; want it unchanged", name, got, err)
+		}
+		if got, err := fn(""); err != nil || got != "" {
+			t.Errorf("%s: empty ref = %q, %v", name, got, err)
+		}
+	}
+
+	team, err := r.ForSource("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := team.ResolvePluginRef("my-plugin")
+	if err != nil {
+		t.Fatalf("ForSource().ResolvePluginRef() error = %v", err)
+	}
+	if want := "team.io/x/klaus-plugins/my-plugin"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
 func TestNewSourceResolver_Default(t *testing.T) {
 	r := DefaultSourceResolver()
-	got := r.ResolvePluginRef("gs-base")
+	got, err := r.ResolvePluginRef("gs-base")
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base"
 	if got != want {
 		t.Errorf("ResolvePluginRef(%q) = %q, want %q", "gs-base", got, want)
@@ -352,7 +594,10 @@ func TestSourceResolverResolvePluginRef(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := r.ResolvePluginRef(tt.ref)
+			got, err := r.ResolvePluginRef(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if got != tt.want {
 				t.Errorf("got %q, want %q", got, tt.want)
 			}
@@ -365,7 +610,10 @@ func TestSourceResolverResolvePersonalityRef(t *testing.T) {
 		{Name: "team", Registry: "team.io/x"},
 	})
 
-	got := r.ResolvePersonalityRef("sre")
+	got, err := r.ResolvePersonalityRef("sre")
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := "team.io/x/klaus-personalities/sre"
 	if got != want {
 		t.Errorf("got %q, want %q", got, want)
@@ -377,13 +625,42 @@ func TestSourceResolverResolveToolchainRef(t *testing.T) {
 		{Name: "team", Registry: "team.io/x"},
 	})
 
-	got := r.ResolveToolchainRef("go")
+	got, err := r.ResolveToolchainRef("go")
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := "team.io/x/klaus-toolchains/go"
 	if got != want {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
 
+func TestConfigResolveImageShortName(t *testing.T) {
+	r := NewSourceResolver([]Source{
+		{Name: "team", Registry: "team.io/x"},
+	})
+
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "go", want: "team.io/x/klaus-toolchains/go"},
+		{image: "go:v1.2.0", want: "team.io/x/klaus-toolchains/go:v1.2.0"},
+		{image: "example.com/custom/image:v1", want: "example.com/custom/image:v1"},
+		{image: DefaultImageRepository, want: DefaultImageRepository},
+		{image: "", want: ""},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Image: tt.image}
+		if err := cfg.ResolveImageShortName(r); err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Image != tt.want {
+			t.Errorf("ResolveImageShortName(%q) = %q, want %q", tt.image, cfg.Image, tt.want)
+		}
+	}
+}
+
 func TestSourceResolverForSource(t *testing.T) {
 	r := NewSourceResolver([]Source{
 		{Name: "giantswarm", Registry: "gsoci.azurecr.io/giantswarm"},
@@ -395,7 +672,10 @@ func TestSourceResolverForSource(t *testing.T) {
 		t.Fatalf("ForSource() returned error: %v", err)
 	}
 
-	got := filtered.ResolvePluginRef("my-plugin")
+	got, err := filtered.ResolvePluginRef("my-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := "team.io/x/klaus-plugins/my-plugin"
 	if got != want {
 		t.Errorf("got %q, want %q", got, want)
@@ -451,7 +731,10 @@ func TestSourceResolverDefaultFirst(t *testing.T) {
 		{Name: "last", Registry: "last.io/x"},
 	})
 
-	got := r.ResolvePluginRef("my-plugin")
+	got, err := r.ResolvePluginRef("my-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := "default.io/x/klaus-plugins/my-plugin"
 	if got != want {
 		t.Errorf("ResolvePluginRef() = %q, want default source %q", got, want)
@@ -479,7 +762,10 @@ func TestSourceResolverDefaultOnly(t *testing.T) {
 		t.Errorf("expected default source, got %q", sources[0].Name)
 	}
 
-	got := d.ResolvePluginRef("my-plugin")
+	got, err := d.ResolvePluginRef("my-plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
 	want := "default.io/x/klaus-plugins/my-plugin"
 	if got != want {
 		t.Errorf("DefaultOnly().ResolvePluginRef() = %q, want %q", got, want)
@@ -521,7 +807,7 @@ func TestDefaultSourceResolver_ResolvesBuiltinRefs(t *testing.T) {
 
 	tests := []struct {
 		name string
-		fn   func(string) string
+		fn   func(string) (string, error)
 		ref  string
 		want string
 	}{
@@ -547,7 +833,10 @@ func TestDefaultSourceResolver_ResolvesBuiltinRefs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := tt.fn(tt.ref)
+			got, err := tt.fn(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
 			if got != tt.want {
 				t.Errorf("got %q, want %q", got, tt.want)
 			}
@@ -747,3 +1036,101 @@ func TestSourceConfigUpdate_SaveAndReload(t *testing.T) {
 		t.Errorf("registry not persisted: got %q", s.Registry)
 	}
 }
+
+func TestSourceMirrorRefs(t *testing.T) {
+	s := Source{
+		Name:     "team-a",
+		Registry: "reg.example.com/a",
+		Mirrors:  []string{"mirror-eu.example.com/a", "mirror-us.example.com/a/"},
+	}
+
+	got := s.MirrorRefs("reg.example.com/a/klaus-plugins/gs-base:v1.0.0")
+	want := []string{
+		"mirror-eu.example.com/a/klaus-plugins/gs-base:v1.0.0",
+		"mirror-us.example.com/a/klaus-plugins/gs-base:v1.0.0",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("MirrorRefs() = %v, want %v", got, want)
+	}
+
+	for _, ref := range []string{"other.example.com/a/klaus-plugins/gs-base:v1.0.0", "reg.example.com/ab/klaus-plugins/x:v1"} {
+		if got := s.MirrorRefs(ref); got != nil {
+			t.Errorf("MirrorRefs(%q) = %v, want nil", ref, got)
+		}
+	}
+}
+
+func TestSourceResolverMirrorRefs(t *testing.T) {
+	r := NewSourceResolver([]Source{
+		builtinSource(),
+		{Name: "team-a", Registry: "reg.example.com/a", Mirrors: []string{"mirror.example.com/a"}},
+	})
+
+	got := r.MirrorRefs("reg.example.com/a/klaus-personalities/sre:v1.0.0")
+	want := []string{"mirror.example.com/a/klaus-personalities/sre:v1.0.0", "reg.example.com/a/klaus-personalities/sre:v1.0.0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("MirrorRefs() = %v, want %v", got, want)
+	}
+
+	ref := DefaultSourceRegistry + "/klaus-plugins/gs-base:v1.0.0"
+	if got := r.MirrorRefs(ref); len(got) != 1 || got[0] != ref {
+		t.Errorf("MirrorRefs() without mirrors = %v, want only %q", got, ref)
+	}
+}
+
+func TestSourceConfigMirrorsValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mirrors []string
+		wantErr string
+	}{
+		{"empty mirror", []string{""}, "mirror must not be empty"},
+		{"registry itself", []string{"reg.example.com/a"}, "is the registry itself"},
+		{"duplicate", []string{"m.example.com/a", "m.example.com/a"}, "duplicate mirror"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := DefaultSourceConfig()
+			err := sc.Add(Source{Name: "team-a", Registry: "reg.example.com/a", Mirrors: tt.mirrors})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Add() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSourceConfigUpdate_Mirrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sources.yaml")
+
+	sc := DefaultSourceConfig()
+	_ = sc.Add(Source{Name: "team-a", Registry: "reg.example.com/a"})
+	if err := sc.Update("team-a", Source{Mirrors: []string{"mirror.example.com/a"}}); err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if err := sc.SaveTo(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSourceConfig(path)
+	if err != nil {
+		t.Fatalf("LoadSourceConfig() returned error: %v", err)
+	}
+	if got := loaded.Get("team-a").Mirrors; len(got) != 1 || got[0] != "mirror.example.com/a" {
+		t.Errorf("mirrors not persisted: got %v", got)
+	}
+
+	if err := loaded.Update("team-a", Source{Mirrors: []string{"reg.example.com/a"}}); err == nil {
+		t.Error("expected error for a mirror equal to the registry")
+	}
+	if got := loaded.Get("team-a").Mirrors; len(got) != 1 {
+		t.Errorf("invalid update changed mirrors to %v", got)
+	}
+
+	if err := loaded.Update("team-a", Source{Mirrors: []string{ClearOverride}}); err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	if got := loaded.Get("team-a").Mirrors; got != nil {
+		t.Errorf("mirrors should be cleared, got %v", got)
+	}
+}
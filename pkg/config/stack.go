@@ -0,0 +1,157 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	ws "github.com/giantswarm/klausctl/pkg/workspace"
+)
+
+// DefaultStackFile is the stack file read by up and down when none is given.
+const DefaultStackFile = "klaus-stack.yaml"
+
+// Stack is a set of named instances that are brought up and down together,
+// as read from a stack file:
+//
+//	instances:
+//	  reviewer:
+//	    workspace: ./app
+//	    personality: code-reviewer
+//	  docs:
+//	    workspace: giantswarm/docs
+//	    model: sonnet
+type Stack struct {
+	Instances map[string]StackInstance `yaml:"instances"`
+}
+
+// StackInstance describes one instance of a stack. The fields match the
+// flags of create.
+type StackInstance struct {
+	// Workspace is a directory, relative paths being resolved against the
+	// directory of the stack file, or an owner/repo identifier. When empty,
+	// the defaultWorkspace of the klausctl config is used.
+	Workspace   string   `yaml:"workspace,omitempty"`
+	Personality string   `yaml:"personality,omitempty"`
+	Toolchain   string   `yaml:"toolchain,omitempty"`
+	Plugins     []string `yaml:"plugins,omitempty"`
+	// Port is the host port; when zero a free port is picked.
+	Port      int    `yaml:"port,omitempty"`
+	Network   string `yaml:"network,omitempty"`
+	Mode      string `yaml:"mode,omitempty"`
+	NoIsolate bool   `yaml:"noIsolate,omitempty"`
+
+	EnvVars        map[string]string `yaml:"envVars,omitempty"`
+	EnvForward     []string          `yaml:"envForward,omitempty"`
+	EnvFile        string            `yaml:"envFile,omitempty"`
+	SecretEnvVars  map[string]string `yaml:"secretEnvVars,omitempty"`
+	SecretFiles    map[string]string `yaml:"secretFiles,omitempty"`
+	McpServers     map[string]any    `yaml:"mcpServers,omitempty"`
+	McpServerRefs  []string          `yaml:"mcpServerRefs,omitempty"`
+	Model          string            `yaml:"model,omitempty"`
+	PermissionMode string            `yaml:"permissionMode,omitempty"`
+	SystemPrompt   string            `yaml:"systemPrompt,omitempty"`
+	MaxBudgetUSD   *float64          `yaml:"maxBudgetUsd,omitempty"`
+}
+
+// LoadStack reads and validates the stack file at path. Unknown fields are
+// rejected, and relative workspace and envFile paths are made absolute
+// against the directory of the file.
+func LoadStack(path string) (*Stack, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- user-supplied stack file path
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("stack file not found: %s", path)
+		}
+		return nil, fmt.Errorf("reading stack file: %w", err)
+	}
+
+	var stack Stack
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&stack); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing stack file: %w", err)
+	}
+	if len(stack.Instances) == 0 {
+		return nil, fmt.Errorf("stack file %s defines no instances", path)
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("resolving stack file directory: %w", err)
+	}
+	ports := make(map[int]string)
+	for _, name := range stack.Names() {
+		inst := stack.Instances[name]
+		if err := ValidateInstanceName(name); err != nil {
+			return nil, fmt.Errorf("stack instance %q: %w", name, err)
+		}
+		if inst.Port < 0 || inst.Port > 65535 {
+			return nil, fmt.Errorf("stack instance %q: port must be between 0 and 65535, got %d", name, inst.Port)
+		}
+		if other, ok := ports[inst.Port]; ok && inst.Port > 0 {
+			return nil, fmt.Errorf("stack instances %q and %q use the same port %d", other, name, inst.Port)
+		}
+		ports[inst.Port] = name
+		if inst.Workspace != "" && !ws.IsRepoIdentifier(inst.Workspace) {
+			inst.Workspace = resolveStackPath(baseDir, inst.Workspace)
+		}
+		if inst.EnvFile != "" {
+			inst.EnvFile = resolveStackPath(baseDir, inst.EnvFile)
+		}
+		stack.Instances[name] = inst
+	}
+	return &stack, nil
+}
+
+// resolveStackPath expands "~" in path and makes it absolute against baseDir.
+func resolveStackPath(baseDir, path string) string {
+	path = ExpandPath(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
+
+// Names returns the instance names of the stack in sorted order.
+func (s *Stack) Names() []string {
+	names := make([]string, 0, len(s.Instances))
+	for name := range s.Instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateOptions returns the options to create the stack instance as name.
+// The workspace is passed through as is; callers resolve an empty one.
+func (si StackInstance) CreateOptions(name string) CreateOptions {
+	return CreateOptions{
+		Name:           name,
+		Workspace:      si.Workspace,
+		Personality:    si.Personality,
+		Toolchain:      si.Toolchain,
+		Plugins:        si.Plugins,
+		Port:           si.Port,
+		Network:        si.Network,
+		Mode:           si.Mode,
+		NoIsolate:      si.NoIsolate,
+		EnvVars:        si.EnvVars,
+		EnvForward:     si.EnvForward,
+		EnvFile:        si.EnvFile,
+		McpServers:     si.McpServers,
+		SecretEnvVars:  si.SecretEnvVars,
+		SecretFiles:    si.SecretFiles,
+		McpServerRefs:  si.McpServerRefs,
+		MaxBudgetUSD:   si.MaxBudgetUSD,
+		PermissionMode: si.PermissionMode,
+		Model:          si.Model,
+		SystemPrompt:   si.SystemPrompt,
+	}
+}
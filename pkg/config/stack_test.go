@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeStackFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "klaus-stack.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadStack(t *testing.T) {
+	path := writeStackFile(t, `instances:
+  reviewer:
+    workspace: ./app
+    personality: code-reviewer
+    envFile: reviewer.env
+    maxBudgetUsd: 5
+  docs:
+    workspace: giantswarm/docs
+    port: 8090
+    model: sonnet
+    envVars:
+      DOCS_ONLY: "1"
+  scratch:
+    workspace: /srv/scratch
+`)
+	stack, err := LoadStack(path)
+	if err != nil {
+		t.Fatalf("LoadStack() error = %v", err)
+	}
+
+	if got, want := stack.Names(), []string{"docs", "reviewer", "scratch"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+
+	dir := filepath.Dir(path)
+	reviewer := stack.Instances["reviewer"]
+	if want := filepath.Join(dir, "app"); reviewer.Workspace != want {
+		t.Errorf("reviewer workspace = %q, want %q", reviewer.Workspace, want)
+	}
+	if want := filepath.Join(dir, "reviewer.env"); reviewer.EnvFile != want {
+		t.Errorf("reviewer envFile = %q, want %q", reviewer.EnvFile, want)
+	}
+	if got := stack.Instances["docs"].Workspace; got != "giantswarm/docs" {
+		t.Errorf("docs workspace = %q, want repo identifier kept", got)
+	}
+	if got := stack.Instances["scratch"].Workspace; got != "/srv/scratch" {
+		t.Errorf("scratch workspace = %q, want /srv/scratch", got)
+	}
+
+	opts := stack.Instances["docs"].CreateOptions("docs")
+	if opts.Name != "docs" || opts.Port != 8090 || opts.Model != "sonnet" || opts.EnvVars["DOCS_ONLY"] != "1" {
+		t.Errorf("CreateOptions() = %+v", opts)
+	}
+	if budget := stack.Instances["reviewer"].CreateOptions("reviewer").MaxBudgetUSD; budget == nil || *budget != 5 {
+		t.Errorf("reviewer MaxBudgetUSD = %v, want 5", budget)
+	}
+}
+
+func TestLoadStackErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"empty", "", "defines no instances"},
+		{"no instances", "instances: {}\n", "defines no instances"},
+		{"unknown field", "instances:\n  a:\n    workspce: /tmp\n", "field workspce not found"},
+		{"invalid name", "instances:\n  Bad_Name:\n    workspace: /tmp\n", `stack instance "Bad_Name"`},
+		{"invalid port", "instances:\n  a:\n    port: 70000\n", "port must be between 0 and 65535"},
+		{"duplicate port", "instances:\n  a:\n    port: 8090\n  b:\n    port: 8090\n", `"a" and "b" use the same port 8090`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadStack(writeStackFile(t, tt.content))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadStackMissingFile(t *testing.T) {
+	_, err := LoadStack(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "stack file not found") {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion is the config file schema version written by this
+// klausctl. Files without a version key are version 0.
+const CurrentConfigVersion = 1
+
+// configMigration upgrades the config document root from the version it is
+// registered for to the next version.
+type configMigration func(root *yaml.Node) error
+
+// configMigrations holds the migration from each version to the next,
+// keyed by the version it upgrades from.
+var configMigrations = map[int]configMigration{
+	0: migrateConfigV0,
+}
+
+// v0ClaudeKeys are the Claude settings that unversioned configs may carry at
+// the top level, e.g. when written by hand after the flat layout of the Helm
+// values. Config only reads them under claude and silently ignores them at
+// the top level, so the migration moves them there.
+var v0ClaudeKeys = []string{
+	"model",
+	"systemPrompt",
+	"appendSystemPrompt",
+	"permissionMode",
+	"maxTurns",
+	"maxBudgetUsd",
+	"effort",
+	"fallbackModel",
+}
+
+// migrateConfigV0 moves top-level Claude settings under claude. A value
+// already set under claude wins over the top-level one.
+func migrateConfigV0(root *yaml.Node) error {
+	for _, key := range v0ClaudeKeys {
+		value := removeMappingKey(root, key)
+		if value == nil {
+			continue
+		}
+		claude := mappingValue(root, "claude")
+		if claude == nil {
+			claude = &yaml.Node{Kind: yaml.MappingNode}
+			root.Content = append(root.Content, stringNode("claude"), claude)
+		}
+		if claude.Kind != yaml.MappingNode {
+			return fmt.Errorf("claude must be a mapping")
+		}
+		if mappingValue(claude, key) == nil {
+			claude.Content = append(claude.Content, stringNode(key), value)
+		}
+	}
+	return nil
+}
+
+// MigrateConfig upgrades the config file contents data to
+// CurrentConfigVersion by applying the registered migrations in order, and
+// returns the updated contents and the version data had. Comments are kept.
+// Data already at the current version is returned unchanged; data from a
+// newer klausctl is rejected.
+func MigrateConfig(data []byte) ([]byte, int, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("parsing config: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, 0, fmt.Errorf("parsing config: expected a mapping")
+	}
+
+	from := 0
+	if v := mappingValue(root, "version"); v != nil {
+		n, err := strconv.Atoi(v.Value)
+		if err != nil || n < 0 {
+			return nil, 0, fmt.Errorf("invalid config version %q", v.Value)
+		}
+		from = n
+	}
+	if from > CurrentConfigVersion {
+		return nil, from, fmt.Errorf("config version %d is newer than the supported version %d; upgrade klausctl", from, CurrentConfigVersion)
+	}
+	if from == CurrentConfigVersion {
+		return data, from, nil
+	}
+
+	for v := from; v < CurrentConfigVersion; v++ {
+		migrate, ok := configMigrations[v]
+		if !ok {
+			return nil, from, fmt.Errorf("no migration from config version %d", v)
+		}
+		if err := migrate(root); err != nil {
+			return nil, from, fmt.Errorf("migrating config from version %d: %w", v, err)
+		}
+	}
+
+	version := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CurrentConfigVersion)}
+	if v := mappingValue(root, "version"); v != nil {
+		*v = *version
+	} else {
+		root.Content = append([]*yaml.Node{stringNode("version"), version}, root.Content...)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, from, fmt.Errorf("encoding config: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, from, fmt.Errorf("encoding config: %w", err)
+	}
+	return buf.Bytes(), from, nil
+}
+
+// mappingValue returns the value of key in the mapping node m, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// removeMappingKey removes key from the mapping node m and returns its
+// value, or nil when m has no such key.
+func removeMappingKey(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			value := m.Content[i+1]
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return value
+		}
+	}
+	return nil
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrateConfigV0(t *testing.T) {
+	v0 := `# my agent
+workspace: /tmp/project
+port: 8090
+model: opus # the big one
+permissionMode: plan
+maxBudgetUsd: 5
+claude:
+  permissionMode: default
+`
+
+	migrated, from, err := MigrateConfig([]byte(v0))
+	if err != nil {
+		t.Fatalf("MigrateConfig() error = %v", err)
+	}
+	if from != 0 {
+		t.Errorf("from = %d, want 0", from)
+	}
+
+	cfg, err := Parse(migrated)
+	if err != nil {
+		t.Fatalf("Parse(migrated) error = %v\n%s", err, migrated)
+	}
+	if cfg.Version != CurrentConfigVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, CurrentConfigVersion)
+	}
+	if cfg.Port != 8090 {
+		t.Errorf("Port = %d, want 8090", cfg.Port)
+	}
+	if cfg.Claude.Model != "opus" {
+		t.Errorf("Claude.Model = %q, want opus", cfg.Claude.Model)
+	}
+	if cfg.Claude.MaxBudgetUSD != 5 {
+		t.Errorf("Claude.MaxBudgetUSD = %v, want 5", cfg.Claude.MaxBudgetUSD)
+	}
+	// The value already under claude wins.
+	if cfg.Claude.PermissionMode != "default" {
+		t.Errorf("Claude.PermissionMode = %q, want default", cfg.Claude.PermissionMode)
+	}
+	for _, want := range []string{"# my agent", "# the big one"} {
+		if !strings.Contains(string(migrated), want) {
+			t.Errorf("migrated config lost comment %q:\n%s", want, migrated)
+		}
+	}
+
+	again, from, err := MigrateConfig(migrated)
+	if err != nil {
+		t.Fatalf("MigrateConfig(migrated) error = %v", err)
+	}
+	if from != CurrentConfigVersion || string(again) != string(migrated) {
+		t.Errorf("migrating a current config changed it (from %d):\n%s", from, again)
+	}
+}
+
+func TestMigrateConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"newer version", "version: 99\nworkspace: /tmp\n", "newer than the supported version"},
+		{"invalid version", "version: one\nworkspace: /tmp\n", "invalid config version"},
+		{"claude not a mapping", "model: opus\nclaude: opus\n", "claude must be a mapping"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := MigrateConfig([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package filelock serializes writes to klausctl state files across
+// processes with advisory file locks, e.g. when several agents drive the
+// MCP server at the same time.
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Lock is a held advisory lock.
+type Lock struct {
+	f *os.File
+}
+
+// Acquire blocks until it holds the exclusive lock on the lock file at
+// path, creating the file if needed. The lock is advisory: it only
+// excludes other callers of Acquire.
+func Acquire(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600) // #nosec G304 -- lock file next to a klausctl state file
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Unlock releases the lock. The lock file is left in place so that
+// concurrent callers keep locking the same file.
+func (l *Lock) Unlock() error {
+	err := unlockFile(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LockPath returns the lock file guarding path.
+func LockPath(path string) string {
+	return path + ".lock"
+}
+
+// WriteFile replaces the file at path with data while holding the lock at
+// LockPath(path). The data is written to a temporary file that is renamed
+// into place, so readers never see a partially written file even without
+// taking the lock.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	lock, err := Acquire(LockPath(path))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	return ReplaceFile(path, data, perm)
+}
+
+// ReplaceFile replaces the file at path with data like WriteFile, for
+// callers that already hold the lock at LockPath(path).
+func ReplaceFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("setting permissions of %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("renaming temp file to %s: %w", path, err)
+	}
+	return nil
+}
@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/giantswarm/klausctl/pkg/config"
-	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 // CollisionState describes the state of an existing instance that collides
@@ -39,7 +38,7 @@ func CheckCollision(ctx context.Context, paths *config.Paths) (CollisionState, e
 		return CollisionStopped, nil
 	}
 
-	rt, err := runtime.New(inst.Runtime)
+	rt, err := inst.NewRuntime()
 	if err != nil {
 		// Can't determine runtime — conservative: treat as stopped.
 		return CollisionStopped, nil
@@ -0,0 +1,74 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultConcurrency bounds how many instances ForEach processes at once
+// when no limit is given. Stopping an instance mostly waits on the agent to
+// shut down, so a small pool gets most of the speedup without flooding the
+// container runtime.
+const DefaultConcurrency = 4
+
+// ForEach calls fn for every instance, running at most limit calls at a
+// time (DefaultConcurrency when limit < 1). Every instance is processed even
+// when some fail; the errors are joined in the order of instances.
+func ForEach(ctx context.Context, instances []*Instance, limit int, fn func(context.Context, *Instance) error) error {
+	if limit < 1 {
+		limit = DefaultConcurrency
+	}
+
+	errs := make([]error, len(instances))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, inst := range instances {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, inst)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Waves calls fn for the instances in consecutive waves of at most size
+// instances (1 when size < 1). The calls of a wave run concurrently and the
+// next wave starts only once all of them have returned, so at most size
+// instances are being processed at any time. Instances of waves that did not
+// start because ctx was cancelled get ctx.Err().
+//
+// Unlike ForEach, Waves returns the result of every instance, by index, so
+// callers can report them individually.
+func Waves(ctx context.Context, instances []*Instance, size int, fn func(context.Context, *Instance) error) []error {
+	if size < 1 {
+		size = 1
+	}
+
+	errs := make([]error, len(instances))
+	for start := 0; start < len(instances); start += size {
+		end := min(start+size, len(instances))
+		if err := ctx.Err(); err != nil {
+			for i := start; i < len(instances); i++ {
+				errs[i] = err
+			}
+			break
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = fn(ctx, instances[i])
+			}()
+		}
+		wg.Wait()
+	}
+	return errs
+}
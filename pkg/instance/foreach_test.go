@@ -0,0 +1,156 @@
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func foreachTestInstances(n int) []*Instance {
+	instances := make([]*Instance, n)
+	for i := range instances {
+		instances[i] = &Instance{Name: fmt.Sprintf("inst-%d", i)}
+	}
+	return instances
+}
+
+func TestForEachBoundsConcurrency(t *testing.T) {
+	instances := foreachTestInstances(10)
+
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	err := ForEach(context.Background(), instances, 3, func(_ context.Context, inst *Instance) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		seen[inst.Name] = true
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEach() error = %v", err)
+	}
+	if len(seen) != len(instances) {
+		t.Errorf("processed %d instances, want %d", len(seen), len(instances))
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", p)
+	}
+}
+
+func TestForEachAggregatesErrors(t *testing.T) {
+	instances := foreachTestInstances(4)
+
+	var calls atomic.Int32
+	errBoom := errors.New("boom")
+	err := ForEach(context.Background(), instances, 0, func(_ context.Context, inst *Instance) error {
+		calls.Add(1)
+		if inst.Name == "inst-1" || inst.Name == "inst-3" {
+			return fmt.Errorf("stopping %s: %w", inst.Name, errBoom)
+		}
+		return nil
+	})
+
+	if calls.Load() != 4 {
+		t.Errorf("calls = %d, want every instance processed despite failures", calls.Load())
+	}
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("ForEach() error = %v, want it to wrap the failures", err)
+	}
+	if got, want := err.Error(), "stopping inst-1: boom\nstopping inst-3: boom"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+}
+
+func TestWavesRunsInBoundedWaves(t *testing.T) {
+	instances := foreachTestInstances(7)
+
+	var mu sync.Mutex
+	var running, peak int
+	var order []string
+	errs := Waves(context.Background(), instances, 3, func(_ context.Context, inst *Instance) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		order = append(order, inst.Name)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})
+
+	if len(errs) != len(instances) {
+		t.Fatalf("got %d results, want %d", len(errs), len(instances))
+	}
+	if peak > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak)
+	}
+	// Waves are [0 1 2] [3 4 5] [6]; an instance of a later wave never starts
+	// before every instance of an earlier wave.
+	waveOf := func(name string) int {
+		var i int
+		_, _ = fmt.Sscanf(name, "inst-%d", &i)
+		return i / 3
+	}
+	for i := 1; i < len(order); i++ {
+		if waveOf(order[i]) < waveOf(order[i-1]) {
+			t.Errorf("start order %v mixes waves", order)
+			break
+		}
+	}
+}
+
+func TestWavesReportsPerInstanceResults(t *testing.T) {
+	instances := foreachTestInstances(3)
+
+	errBoom := errors.New("boom")
+	errs := Waves(context.Background(), instances, 2, func(_ context.Context, inst *Instance) error {
+		if inst.Name == "inst-1" {
+			return errBoom
+		}
+		return nil
+	})
+
+	if errs[0] != nil || !errors.Is(errs[1], errBoom) || errs[2] != nil {
+		t.Errorf("results = %v, want only inst-1 to fail", errs)
+	}
+}
+
+func TestWavesSkipsRemainingWavesWhenCancelled(t *testing.T) {
+	instances := foreachTestInstances(4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	errs := Waves(ctx, instances, 2, func(_ context.Context, _ *Instance) error {
+		calls.Add(1)
+		cancel()
+		return nil
+	})
+
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want only the first wave", calls.Load())
+	}
+	if errs[0] != nil || errs[1] != nil {
+		t.Errorf("first wave results = %v, want success", errs[:2])
+	}
+	if !errors.Is(errs[2], context.Canceled) || !errors.Is(errs[3], context.Canceled) {
+		t.Errorf("skipped results = %v, want context.Canceled", errs[2:])
+	}
+}
@@ -0,0 +1,74 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+// CreateRecord is what a create call stores under its idempotency key so
+// that a retry with the same key can return the original result.
+type CreateRecord struct {
+	// Name is the name of the created instance; it is not stored but set
+	// from the instance directory on lookup.
+	Name string `json:"-"`
+	// Key is the client-supplied idempotency key.
+	Key string `json:"key"`
+	// Result is the JSON result of the create call.
+	Result json.RawMessage `json:"result"`
+}
+
+// SaveCreateRecord records the idempotency key and result of the create call
+// that made the instance at paths. The record lives in the instance
+// directory, so the key expires when the instance is deleted.
+func SaveCreateRecord(paths *config.Paths, key string, result any) error {
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshaling create result: %w", err)
+	}
+	data, err := json.MarshalIndent(CreateRecord{Key: key, Result: raw}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling create record: %w", err)
+	}
+	return os.WriteFile(paths.IdempotencyFile, append(data, '\n'), 0o600)
+}
+
+// FindCreateRecord returns the record of the instance created with the
+// idempotency key, or nil when no existing instance was created with it.
+func FindCreateRecord(paths *config.Paths, key string) (*CreateRecord, error) {
+	entries, err := os.ReadDir(paths.InstancesDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading instances directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(paths.ForInstance(entry.Name()).IdempotencyFile)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("reading create record %s: %w", entry.Name(), err)
+		}
+
+		rec := &CreateRecord{}
+		if err := json.Unmarshal(data, rec); err != nil {
+			return nil, fmt.Errorf("parsing create record %s: %w", entry.Name(), err)
+		}
+		if rec.Key == key {
+			rec.Name = entry.Name()
+			return rec, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,85 @@
+package instance
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestCreateRecordRoundTrip(t *testing.T) {
+	paths := &config.Paths{InstancesDir: t.TempDir()}
+
+	type result struct {
+		Instance string `json:"instance"`
+		Port     int    `json:"port"`
+	}
+	for _, name := range []string{"dev-a1b2", "dev-c3d4"} {
+		instPaths := paths.ForInstance(name)
+		if err := config.EnsureDir(instPaths.InstanceDir); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveCreateRecord(instPaths, "key-"+name, result{Instance: name, Port: 8080}); err != nil {
+			t.Fatalf("SaveCreateRecord() error = %v", err)
+		}
+	}
+
+	rec, err := FindCreateRecord(paths, "key-dev-c3d4")
+	if err != nil {
+		t.Fatalf("FindCreateRecord() error = %v", err)
+	}
+	if rec == nil {
+		t.Fatal("expected a record for key-dev-c3d4")
+	}
+	if rec.Name != "dev-c3d4" {
+		t.Errorf("Name = %q, want dev-c3d4", rec.Name)
+	}
+	var got result
+	if err := json.Unmarshal(rec.Result, &got); err != nil {
+		t.Fatalf("parsing recorded result: %v", err)
+	}
+	if want := (result{Instance: "dev-c3d4", Port: 8080}); got != want {
+		t.Errorf("Result = %+v, want %+v", got, want)
+	}
+
+	rec, err = FindCreateRecord(paths, "unknown")
+	if err != nil || rec != nil {
+		t.Errorf("FindCreateRecord(unknown) = %v, %v; want nil, nil", rec, err)
+	}
+}
+
+func TestCreateRecordExpiresWithInstance(t *testing.T) {
+	paths := &config.Paths{InstancesDir: t.TempDir()}
+	instPaths := paths.ForInstance("dev")
+	if err := config.EnsureDir(instPaths.InstanceDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveCreateRecord(instPaths, "retry-1", map[string]string{"instance": "dev"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Stopping clears the instance state but keeps the key.
+	if err := Clear(instPaths); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := FindCreateRecord(paths, "retry-1"); err != nil || rec == nil {
+		t.Fatalf("expected key to survive stop, got %v, %v", rec, err)
+	}
+
+	if err := os.RemoveAll(instPaths.InstanceDir); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := FindCreateRecord(paths, "retry-1"); err != nil || rec != nil {
+		t.Errorf("expected key to expire with the instance, got %v, %v", rec, err)
+	}
+}
+
+func TestFindCreateRecordMissingInstancesDir(t *testing.T) {
+	paths := &config.Paths{InstancesDir: filepath.Join(t.TempDir(), "missing")}
+	rec, err := FindCreateRecord(paths, "key")
+	if err != nil || rec != nil {
+		t.Errorf("FindCreateRecord() = %v, %v; want nil, nil", rec, err)
+	}
+}
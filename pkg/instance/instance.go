@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
 
 	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/filelock"
+	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
 // Instance holds the state of a running klausctl container.
@@ -24,6 +28,9 @@ type Instance struct {
 	ContainerID string `json:"containerID"`
 	// Runtime is the container runtime used ("docker" or "podman").
 	Runtime string `json:"runtime"`
+	// RuntimeHost is the daemon address the container runs on (empty for
+	// the local daemon); see config.Config.RuntimeHost.
+	RuntimeHost string `json:"runtimeHost,omitempty"`
 	// Personality is the OCI reference of the resolved personality (empty when none).
 	Personality string `json:"personality,omitempty"`
 	// Image is the container image reference.
@@ -32,6 +39,9 @@ type Instance struct {
 	Port int `json:"port"`
 	// Workspace is the host workspace directory.
 	Workspace string `json:"workspace"`
+	// RestartPolicy is the container restart policy the instance was
+	// started with (empty when none).
+	RestartPolicy string `json:"restartPolicy,omitempty"`
 	// StartedAt is when the container was started.
 	StartedAt time.Time `json:"startedAt"`
 }
@@ -54,7 +64,32 @@ func (i *Instance) ContainerName() string {
 	return ContainerName(i.Name)
 }
 
-// Save writes the instance state to the instance file.
+// NewRuntime returns the runtime managing the instance's container, on the
+// host it was started on.
+func (i *Instance) NewRuntime() (runtime.Runtime, error) {
+	return runtime.NewWithHost(i.Runtime, i.RuntimeHost)
+}
+
+// AgentURL returns the base URL of the instance's agent, e.g.
+// "http://localhost:8080".
+func (i *Instance) AgentURL() string {
+	return AgentURL(i.RuntimeHost, i.Port)
+}
+
+// AgentURL returns the base URL of an agent published on port of the given
+// runtime host: the remote host name for a tcp:// or ssh:// host and
+// localhost otherwise.
+func AgentURL(runtimeHost string, port int) string {
+	host := runtime.RemoteHostName(runtimeHost)
+	if host == "" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Save writes the instance state to the instance file. Concurrent saves
+// are serialized with a file lock and the file is replaced atomically, so
+// Load never sees a partially written state.
 // The caller is responsible for setting StartedAt before calling Save.
 func (i *Instance) Save(paths *config.Paths) error {
 	if err := config.EnsureDir(paths.InstanceDir); err != nil {
@@ -66,7 +101,7 @@ func (i *Instance) Save(paths *config.Paths) error {
 		return fmt.Errorf("marshaling instance: %w", err)
 	}
 
-	return os.WriteFile(paths.InstanceFile, append(data, '\n'), 0o600)
+	return filelock.WriteFile(paths.InstanceFile, append(data, '\n'), 0o600)
 }
 
 // LoadAll reads instance state files from all per-instance directories.
@@ -126,9 +161,25 @@ func Load(paths *config.Paths) (*Instance, error) {
 	return inst, nil
 }
 
-// Clear removes the instance state file.
+// Clear removes the instance state file and its lock file, holding the
+// same lock as Save.
 func Clear(paths *config.Paths) error {
-	err := os.Remove(paths.InstanceFile)
+	lock, err := filelock.Acquire(filelock.LockPath(paths.InstanceFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	err = os.Remove(paths.InstanceFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// The lock file is removed while it is held; a later Save creates it
+	// again.
+	err = os.Remove(filelock.LockPath(paths.InstanceFile))
 	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return err
 	}
@@ -1,7 +1,10 @@
 package instance
 
 import (
+	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -75,6 +78,44 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+func TestConcurrentSaveAndLoad(t *testing.T) {
+	paths := testPaths(t)
+	if err := (&Instance{Name: "default", Image: "base"}).Save(paths); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			inst := &Instance{Name: "default", Image: fmt.Sprintf("image-%d-%s", i, NewUUID())}
+			errs <- inst.Save(paths)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := Load(paths)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent Save/Load error = %v", err)
+		}
+	}
+
+	got, err := Load(paths)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Name != "default" || got.Image == "" {
+		t.Errorf("Load() = %+v, want a complete instance", got)
+	}
+}
+
 func TestLoadMissing(t *testing.T) {
 	paths := testPaths(t)
 
@@ -114,6 +155,9 @@ func TestClear(t *testing.T) {
 	if _, err := Load(paths); err == nil {
 		t.Fatal("Load() should fail after Clear()")
 	}
+	if _, err := os.Stat(paths.InstanceFile + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind: %v", err)
+	}
 }
 
 func TestClearMissingIsNotError(t *testing.T) {
@@ -137,6 +181,24 @@ func TestContainerName(t *testing.T) {
 	}
 }
 
+func TestAgentURL(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "", want: "http://localhost:8080"},
+		{host: "unix:///var/run/docker.sock", want: "http://localhost:8080"},
+		{host: "ssh://me@build-box", want: "http://build-box:8080"},
+		{host: "tcp://10.0.0.5:2376", want: "http://10.0.0.5:8080"},
+	}
+	for _, tt := range tests {
+		inst := &Instance{Name: "dev", Port: 8080, RuntimeHost: tt.host}
+		if got := inst.AgentURL(); got != tt.want {
+			t.Errorf("AgentURL() with host %q = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
 func TestLoadAll(t *testing.T) {
 	paths := testPaths(t)
 
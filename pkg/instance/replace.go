@@ -0,0 +1,80 @@
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+// Replacement is an existing instance whose directory has been moved aside
+// so that a new instance with the same name can be created in its place.
+// Exactly one of Restore or Discard must be called once the new instance has
+// been created or has failed.
+type Replacement struct {
+	name        string
+	instanceDir string
+	backupDir   string
+}
+
+// ReplacementDir returns the directory an instance is moved to while it is
+// being replaced. It lives outside the instances directory so the instance
+// does not show up in listings and its port is not considered in use.
+func ReplacementDir(paths *config.Paths) string {
+	return filepath.Join(paths.ConfigDir, "replacing", filepath.Base(paths.InstanceDir))
+}
+
+// SetAside moves the instance directory of paths (config, state and
+// workspace clone) to ReplacementDir. The container of the instance must
+// already be removed.
+//
+// SetAside refuses to overwrite a left-over backup from an interrupted
+// replacement, since that may be the only copy of the previous instance.
+func SetAside(paths *config.Paths) (*Replacement, error) {
+	name := filepath.Base(paths.InstanceDir)
+	backupDir := ReplacementDir(paths)
+
+	if _, err := os.Stat(backupDir); err == nil {
+		return nil, fmt.Errorf("a previous replacement of instance %q was interrupted; move %s back to %s or delete it, then retry", name, backupDir, paths.InstanceDir)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err := config.EnsureDir(filepath.Dir(backupDir)); err != nil {
+		return nil, fmt.Errorf("creating replacement directory: %w", err)
+	}
+	if err := os.Rename(paths.InstanceDir, backupDir); err != nil {
+		return nil, fmt.Errorf("moving instance %q aside: %w", name, err)
+	}
+
+	return &Replacement{
+		name:        name,
+		instanceDir: paths.InstanceDir,
+		backupDir:   backupDir,
+	}, nil
+}
+
+// Restore removes whatever was created in place of the previous instance and
+// moves the previous instance back. The restored instance is stopped.
+func (r *Replacement) Restore() error {
+	if err := os.RemoveAll(r.instanceDir); err != nil {
+		return fmt.Errorf("removing new instance %q: %w; the previous instance is kept in %s", r.name, err, r.backupDir)
+	}
+	if err := os.Rename(r.backupDir, r.instanceDir); err != nil {
+		return fmt.Errorf("restoring instance %q: %w; the previous instance is kept in %s", r.name, err, r.backupDir)
+	}
+	_ = os.Remove(filepath.Dir(r.backupDir))
+	return nil
+}
+
+// Discard permanently deletes the previous instance, including its
+// workspace clone.
+func (r *Replacement) Discard() error {
+	if err := os.RemoveAll(r.backupDir); err != nil {
+		return fmt.Errorf("removing previous instance %q: %w", r.name, err)
+	}
+	_ = os.Remove(filepath.Dir(r.backupDir))
+	return nil
+}
@@ -0,0 +1,96 @@
+package instance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func replaceTestPaths(t *testing.T) *config.Paths {
+	t.Helper()
+	dir := t.TempDir()
+	base := &config.Paths{
+		ConfigDir:    dir,
+		InstancesDir: filepath.Join(dir, "instances"),
+	}
+	paths := base.ForInstance("dev")
+	if err := os.MkdirAll(filepath.Join(paths.InstanceDir, "workspace"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.ConfigFile, []byte("port: 8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestSetAsideAndRestore(t *testing.T) {
+	paths := replaceTestPaths(t)
+
+	r, err := SetAside(paths)
+	if err != nil {
+		t.Fatalf("SetAside() error = %v", err)
+	}
+	if _, err := os.Stat(paths.InstanceDir); !os.IsNotExist(err) {
+		t.Fatalf("expected instance dir to be moved aside, stat err = %v", err)
+	}
+
+	// Simulate a partially created new instance.
+	if err := os.MkdirAll(paths.InstanceDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.ConfigFile, []byte("port: 9090\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Restore(); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	data, err := os.ReadFile(paths.ConfigFile)
+	if err != nil {
+		t.Fatalf("reading restored config: %v", err)
+	}
+	if string(data) != "port: 8080\n" {
+		t.Errorf("restored config = %q, want previous config", data)
+	}
+	if _, err := os.Stat(filepath.Join(paths.InstanceDir, "workspace")); err != nil {
+		t.Errorf("expected workspace clone to be restored: %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(ReplacementDir(paths))); !os.IsNotExist(err) {
+		t.Errorf("expected replacement dir to be cleaned up, stat err = %v", err)
+	}
+}
+
+func TestSetAsideAndDiscard(t *testing.T) {
+	paths := replaceTestPaths(t)
+
+	r, err := SetAside(paths)
+	if err != nil {
+		t.Fatalf("SetAside() error = %v", err)
+	}
+	if err := r.Discard(); err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	if _, err := os.Stat(ReplacementDir(paths)); !os.IsNotExist(err) {
+		t.Errorf("expected previous instance to be deleted, stat err = %v", err)
+	}
+}
+
+func TestSetAsideRefusesLeftOverBackup(t *testing.T) {
+	paths := replaceTestPaths(t)
+
+	if err := os.MkdirAll(ReplacementDir(paths), 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := SetAside(paths)
+	if err == nil || !strings.Contains(err.Error(), "interrupted") {
+		t.Fatalf("SetAside() error = %v, want interrupted replacement error", err)
+	}
+	if _, err := os.Stat(paths.ConfigFile); err != nil {
+		t.Errorf("expected instance to stay in place: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Package logparse parses the structured output of the klaus agent, which
+// writes one JSON object per line (e.g. stream-json events), from container
+// logs that may mix them with plain text.
+//
+// The parser is tolerant: lines that are not JSON objects are passed
+// through as text, and a leading RFC 3339 timestamp, as added by
+// `docker logs --timestamps`, is skipped before decoding.
+package logparse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds a single log line; stream-json result events can be large.
+const maxLineSize = 4 * 1024 * 1024
+
+// Line is one log line.
+type Line struct {
+	// Text is the line as read.
+	Text string
+	// Fields is the decoded JSON object, nil when the line is not one.
+	Fields map[string]any
+}
+
+// MarshalJSON encodes a JSON line as its object and any other line as its
+// text, so parsed logs keep every line in order.
+func (l Line) MarshalJSON() ([]byte, error) {
+	if l.Fields != nil {
+		return json.Marshal(l.Fields)
+	}
+	return json.Marshal(l.Text)
+}
+
+// ParseLine decodes line if it is a JSON object.
+func ParseLine(line string) Line {
+	body := strings.TrimSpace(line)
+	if first, rest, ok := strings.Cut(body, " "); ok {
+		if _, err := time.Parse(time.RFC3339Nano, first); err == nil {
+			body = strings.TrimSpace(rest)
+		}
+	}
+	if !strings.HasPrefix(body, "{") {
+		return Line{Text: line}
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return Line{Text: line}
+	}
+	return Line{Text: line, Fields: fields}
+}
+
+// Parse calls fn with every line of r, in order.
+func Parse(r io.Reader, fn func(Line) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if err := fn(ParseLine(scanner.Text())); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading logs: %w", err)
+	}
+	return nil
+}
+
+// Filter matches JSON lines whose field at Path has the value Value.
+type Filter struct {
+	// Path is the field name; dots select nested fields, e.g.
+	// "message.role".
+	Path  string
+	Value string
+}
+
+// ParseFilter parses a "path=value" filter.
+func ParseFilter(s string) (Filter, error) {
+	path, value, ok := strings.Cut(s, "=")
+	if !ok || path == "" {
+		return Filter{}, fmt.Errorf("invalid field filter %q: expected path=value, e.g. type=assistant", s)
+	}
+	return Filter{Path: path, Value: value}, nil
+}
+
+// Match reports whether l is a JSON line whose field at f.Path, formatted
+// as text, equals f.Value.
+func (f Filter) Match(l Line) bool {
+	var v any = l.Fields
+	for _, key := range strings.Split(f.Path, ".") {
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return false
+		}
+		if v, ok = obj[key]; !ok {
+			return false
+		}
+	}
+	switch v := v.(type) {
+	case string:
+		return v == f.Value
+	case map[string]any, []any:
+		return false
+	default:
+		return fmt.Sprint(v) == f.Value
+	}
+}
+
+// MatchAll reports whether l matches every filter. Without filters every
+// line matches.
+func MatchAll(l Line, filters []Filter) bool {
+	for _, f := range filters {
+		if !f.Match(l) {
+			return false
+		}
+	}
+	return true
+}
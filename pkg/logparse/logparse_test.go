@@ -0,0 +1,89 @@
+package logparse
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const agentLog = `starting klaus
+{"type":"system","subtype":"init"}
+2026-01-01T10:00:02.000000000Z {"type":"assistant","message":{"role":"assistant"},"turn":2}
+{not json
+{"type":"result","is_error":false}
+`
+
+func parseAll(t *testing.T, s string) []Line {
+	t.Helper()
+	var lines []Line
+	if err := Parse(strings.NewReader(s), func(l Line) error {
+		lines = append(lines, l)
+		return nil
+	}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return lines
+}
+
+func TestParsePassesThroughNonJSONLines(t *testing.T) {
+	lines := parseAll(t, agentLog)
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 5", len(lines))
+	}
+	for i, wantJSON := range []bool{false, true, true, false, true} {
+		if got := lines[i].Fields != nil; got != wantJSON {
+			t.Errorf("line %d (%q) parsed = %v, want %v", i, lines[i].Text, got, wantJSON)
+		}
+	}
+	if lines[2].Fields["type"] != "assistant" {
+		t.Errorf("timestamped line fields = %v, want type assistant", lines[2].Fields)
+	}
+
+	data, err := json.Marshal(lines[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `["starting klaus",{"subtype":"init","type":"system"}]` {
+		t.Errorf("json = %s", data)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	lines := parseAll(t, agentLog)
+	tests := []struct {
+		filter string
+		want   []int
+	}{
+		{"type=assistant", []int{2}},
+		{"message.role=assistant", []int{2}},
+		{"turn=2", []int{2}},
+		{"is_error=false", []int{4}},
+		{"message=assistant", nil},
+		{"missing=x", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for i, l := range lines {
+				if f.Match(l) {
+					got = append(got, i)
+				}
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("matched lines %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterRejectsMissingValue(t *testing.T) {
+	for _, s := range []string{"type", "=assistant"} {
+		if _, err := ParseFilter(s); err == nil {
+			t.Errorf("ParseFilter(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+// Package logreplay plays back a saved container log file, reproducing the
+// timing between lines from their timestamps to simulate a live stream.
+//
+// Two timestamp forms are recognised:
+//
+//   - a leading RFC 3339 timestamp, as written by `docker logs --timestamps`
+//     and `podman logs --timestamps`;
+//   - a `time=` field, as written by the klaus agent's structured logger.
+//
+// Lines without a timestamp are emitted right after the preceding line.
+package logreplay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds a single log line; stream-json result events can be large.
+const maxLineSize = 4 * 1024 * 1024
+
+// Sleeper waits for d, returning early with ctx.Err() when ctx is done.
+type Sleeper func(ctx context.Context, d time.Duration) error
+
+// Options configures a replay.
+type Options struct {
+	// Speed is the playback speed multiplier: 2 plays twice as fast as the
+	// original run, 0.5 half as fast. Defaults to 1.
+	Speed float64
+	// Sleep waits between lines. Defaults to a timer-based sleeper; tests
+	// inject a fake to observe the delays.
+	Sleep Sleeper
+}
+
+// Replay copies the lines of r to w, waiting between timestamped lines for
+// the time that passed between them in the original run, divided by
+// opts.Speed. Timestamps that go backwards do not cause a wait.
+func Replay(ctx context.Context, r io.Reader, w io.Writer, opts Options) error {
+	speed := opts.Speed
+	if speed == 0 {
+		speed = 1
+	}
+	if speed < 0 {
+		return fmt.Errorf("speed must be > 0, got %g", speed)
+	}
+	sleep := opts.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	var prev time.Time
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if ts, ok := ParseTimestamp(line); ok {
+			if !prev.IsZero() && ts.After(prev) {
+				delay := time.Duration(float64(ts.Sub(prev)) / speed)
+				if err := sleep(ctx, delay); err != nil {
+					return err
+				}
+			}
+			prev = ts
+		}
+
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading log file: %w", err)
+	}
+	return nil
+}
+
+// ParseTimestamp extracts the timestamp of a log line, see the package
+// documentation for the recognised forms.
+func ParseTimestamp(line string) (time.Time, bool) {
+	first, _, _ := strings.Cut(line, " ")
+	if ts, err := time.Parse(time.RFC3339Nano, first); err == nil {
+		return ts, true
+	}
+
+	for _, field := range strings.Fields(line) {
+		value, ok := strings.CutPrefix(field, "time=")
+		if !ok {
+			continue
+		}
+		if ts, err := time.Parse(time.RFC3339Nano, strings.Trim(value, `"`)); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,121 @@
+package logreplay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const savedLog = `2026-01-01T10:00:00.000000000Z starting klaus
+2026-01-01T10:00:02.000000000Z {"type":"system","subtype":"init"}
+no timestamp here
+2026-01-01T10:00:03.500000000Z {"type":"result","subtype":"success"}
+2026-01-01T10:00:01.000000000Z clock went backwards
+`
+
+// recorder is a fake Sleeper that records each delay together with the
+// output written so far, so tests can check timing relative to the lines.
+type recorder struct {
+	out    *bytes.Buffer
+	delays []time.Duration
+	before []int
+}
+
+func (r *recorder) sleep(_ context.Context, d time.Duration) error {
+	r.delays = append(r.delays, d)
+	r.before = append(r.before, strings.Count(r.out.String(), "\n"))
+	return nil
+}
+
+func TestReplayEmitsLinesInOrderWithOriginalTiming(t *testing.T) {
+	var out bytes.Buffer
+	rec := &recorder{out: &out}
+
+	if err := Replay(context.Background(), strings.NewReader(savedLog), &out, Options{Sleep: rec.sleep}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	if out.String() != savedLog {
+		t.Errorf("output = %q, want the input lines in order", out.String())
+	}
+
+	wantDelays := []time.Duration{2 * time.Second, 1500 * time.Millisecond}
+	if len(rec.delays) != len(wantDelays) {
+		t.Fatalf("delays = %v, want %v", rec.delays, wantDelays)
+	}
+	for i, want := range wantDelays {
+		if rec.delays[i] != want {
+			t.Errorf("delay[%d] = %v, want %v", i, rec.delays[i], want)
+		}
+	}
+	// The waits happen before the second line and before the result line.
+	if rec.before[0] != 1 || rec.before[1] != 3 {
+		t.Errorf("lines written before each wait = %v, want [1 3]", rec.before)
+	}
+}
+
+func TestReplaySpeedMultiplier(t *testing.T) {
+	var out bytes.Buffer
+	rec := &recorder{out: &out}
+
+	if err := Replay(context.Background(), strings.NewReader(savedLog), &out, Options{Speed: 4, Sleep: rec.sleep}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	wantDelays := []time.Duration{500 * time.Millisecond, 375 * time.Millisecond}
+	if len(rec.delays) != len(wantDelays) || rec.delays[0] != wantDelays[0] || rec.delays[1] != wantDelays[1] {
+		t.Errorf("delays = %v, want %v", rec.delays, wantDelays)
+	}
+}
+
+func TestReplayStopsWhenSleepFails(t *testing.T) {
+	var out bytes.Buffer
+	sleep := func(ctx context.Context, d time.Duration) error { return context.Canceled }
+
+	err := Replay(context.Background(), strings.NewReader(savedLog), &out, Options{Sleep: sleep})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Replay() error = %v, want context.Canceled", err)
+	}
+	if out.String() != "2026-01-01T10:00:00.000000000Z starting klaus\n" {
+		t.Errorf("output = %q, want only the first line", out.String())
+	}
+}
+
+func TestReplayRejectsNegativeSpeed(t *testing.T) {
+	err := Replay(context.Background(), strings.NewReader(savedLog), &bytes.Buffer{}, Options{Speed: -1})
+	if err == nil {
+		t.Fatal("expected error for negative speed")
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+		ok   bool
+	}{
+		{line: "2026-01-01T10:00:00.5Z hello", want: "2026-01-01T10:00:00.5Z", ok: true},
+		{line: "2026-01-01T10:00:00+02:00", want: "2026-01-01T08:00:00Z", ok: true},
+		{line: `time=2026-01-01T10:00:01Z level=INFO msg="starting"`, want: "2026-01-01T10:00:01Z", ok: true},
+		{line: `level=INFO time="2026-01-01T10:00:01Z" msg=x`, want: "2026-01-01T10:00:01Z", ok: true},
+		{line: `{"type":"assistant"}`},
+		{line: ""},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTimestamp(tt.line)
+		if ok != tt.ok {
+			t.Errorf("ParseTimestamp(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		want, _ := time.Parse(time.RFC3339Nano, tt.want)
+		if !got.Equal(want) {
+			t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.line, got, want)
+		}
+	}
+}
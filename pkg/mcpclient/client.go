@@ -6,11 +6,14 @@ package mcpclient
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 
 	mcpclient "github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/client/transport"
 	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/giantswarm/klausctl/pkg/cabundle"
 )
 
 // Client manages MCP connections to klaus agent instances. It caches sessions
@@ -22,14 +25,18 @@ type Client struct {
 	// headers, when non-empty, are applied to every outgoing MCP HTTP
 	// request (set via NewWithHeaders for the remote-gateway path).
 	headers map[string]string
+	// httpClient, when set, trusts the custom CAs configured via
+	// pkg/cabundle.
+	httpClient *http.Client
 }
 
 // New creates a new Client. The version string is sent during MCP session
 // initialization so the remote agent knows which klausctl build is calling.
 func New(version string) *Client {
 	return &Client{
-		sessions: make(map[string]*mcpclient.Client),
-		version:  version,
+		sessions:   make(map[string]*mcpclient.Client),
+		version:    version,
+		httpClient: cabundle.HTTPClient(),
 	}
 }
 
@@ -42,9 +49,10 @@ func NewWithHeaders(version string, headers map[string]string) *Client {
 		copied[k] = v
 	}
 	return &Client{
-		sessions: make(map[string]*mcpclient.Client),
-		version:  version,
-		headers:  copied,
+		sessions:   make(map[string]*mcpclient.Client),
+		version:    version,
+		headers:    copied,
+		httpClient: cabundle.HTTPClient(),
 	}
 }
 
@@ -72,6 +80,9 @@ func (c *Client) getOrCreateSession(ctx context.Context, instanceName, baseURL s
 	if len(c.headers) > 0 {
 		transportOpts = append(transportOpts, transport.WithHTTPHeaders(c.headers))
 	}
+	if c.httpClient != nil {
+		transportOpts = append(transportOpts, transport.WithHTTPBasicClient(c.httpClient))
+	}
 
 	mc, err := mcpclient.NewStreamableHttpClient(baseURL, transportOpts...)
 	if err != nil {
@@ -147,6 +158,12 @@ func (c *Client) Result(ctx context.Context, instanceName, baseURL string, full
 	return c.callTool(ctx, instanceName, baseURL, "result", args)
 }
 
+// Restart asks the agent to reset its session in place, keeping the
+// container and its mounts.
+func (c *Client) Restart(ctx context.Context, instanceName, baseURL string) (*mcp.CallToolResult, error) {
+	return c.callTool(ctx, instanceName, baseURL, "restart", nil)
+}
+
 // MessagesOpts holds optional parameters for the Messages call.
 type MessagesOpts struct {
 	Offset int
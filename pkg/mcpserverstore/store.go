@@ -1,22 +1,71 @@
 // Package mcpserverstore manages the global registry of managed MCP server
 // definitions for klausctl. Each server has a URL and an optional secret
-// reference that is resolved at instance start time into a Bearer token header.
+// reference that is resolved at instance start time into an authentication
+// header, by default a Bearer token in the Authorization header.
 package mcpserverstore
 
 import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
 	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	// DefaultHeaderName is the header carrying the secret when a server
+	// definition does not set HeaderName.
+	DefaultHeaderName = "Authorization"
+	// DefaultHeaderFormat formats the secret when a server definition does
+	// not set HeaderFormat.
+	DefaultHeaderFormat = "Bearer %s"
+)
+
+// headerNameRegexp matches an HTTP header field name (an RFC 9110 token).
+var headerNameRegexp = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
 // McpServerDef describes a managed MCP server with a URL and optional
 // secret reference used for authentication.
 type McpServerDef struct {
 	URL    string `yaml:"url"`
 	Secret string `yaml:"secret,omitempty"`
+	// HeaderName is the request header the secret is sent in, e.g.
+	// "X-API-Key". Defaults to DefaultHeaderName.
+	HeaderName string `yaml:"headerName,omitempty"`
+	// HeaderFormat is the header value with a single %s standing for the
+	// secret, e.g. "%s" for a bare API key. Defaults to DefaultHeaderFormat.
+	HeaderFormat string `yaml:"headerFormat,omitempty"`
+}
+
+// Validate checks the optional header settings of the definition.
+func (d McpServerDef) Validate() error {
+	if d.HeaderName != "" && !headerNameRegexp.MatchString(d.HeaderName) {
+		return fmt.Errorf("invalid header name %q", d.HeaderName)
+	}
+	if d.HeaderFormat != "" {
+		// Exactly one %s and no other verbs; %% is a literal percent sign.
+		rest := strings.ReplaceAll(d.HeaderFormat, "%%", "")
+		if strings.Count(rest, "%s") != 1 || strings.Count(rest, "%") != 1 {
+			return fmt.Errorf("invalid header format %q: must contain %%s exactly once and no other %% verbs", d.HeaderFormat)
+		}
+	}
+	return nil
+}
+
+// AuthHeader returns the name and value of the header that sends secret to
+// the server. The definition must have passed Validate.
+func (d McpServerDef) AuthHeader(secret string) (name, value string) {
+	name, format := d.HeaderName, d.HeaderFormat
+	if name == "" {
+		name = DefaultHeaderName
+	}
+	if format == "" {
+		format = DefaultHeaderFormat
+	}
+	return name, fmt.Sprintf(format, secret)
 }
 
 // Store manages named MCP server definitions persisted as a YAML file.
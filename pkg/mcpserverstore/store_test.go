@@ -109,3 +109,66 @@ func TestAll(t *testing.T) {
 		t.Errorf("b.Secret = %q", all["b"].Secret)
 	}
 }
+
+func TestSaveAndReloadHeaderSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcpservers.yaml")
+	store, _ := Load(path)
+	store.Add("search", McpServerDef{URL: "https://search.example.com", Secret: "search-key", HeaderName: "X-API-Key", HeaderFormat: "%s"})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	def, err := reloaded.Get("search")
+	if err != nil {
+		t.Fatalf("Get after reload: %v", err)
+	}
+	if def.HeaderName != "X-API-Key" || def.HeaderFormat != "%s" {
+		t.Errorf("header settings = %q/%q, want X-API-Key/%%s", def.HeaderName, def.HeaderFormat)
+	}
+}
+
+func TestAuthHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		def       McpServerDef
+		wantName  string
+		wantValue string
+	}{
+		{name: "defaults", def: McpServerDef{}, wantName: "Authorization", wantValue: "Bearer tok"},
+		{name: "custom header name", def: McpServerDef{HeaderName: "X-API-Key", HeaderFormat: "%s"}, wantName: "X-API-Key", wantValue: "tok"},
+		{name: "custom name keeps default format", def: McpServerDef{HeaderName: "X-Auth"}, wantName: "X-Auth", wantValue: "Bearer tok"},
+		{name: "custom format", def: McpServerDef{HeaderFormat: "Token %s"}, wantName: "Authorization", wantValue: "Token tok"},
+		{name: "literal percent", def: McpServerDef{HeaderFormat: "100%% %s"}, wantName: "Authorization", wantValue: "100% tok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.def.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			name, value := tt.def.AuthHeader("tok")
+			if name != tt.wantName || value != tt.wantValue {
+				t.Errorf("AuthHeader() = %q: %q, want %q: %q", name, value, tt.wantName, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsInvalidHeaderSettings(t *testing.T) {
+	tests := []McpServerDef{
+		{HeaderName: "X API Key"},
+		{HeaderName: "X-Key:"},
+		{HeaderFormat: "Bearer"},
+		{HeaderFormat: "%s %s"},
+		{HeaderFormat: "%d"},
+		{HeaderFormat: "%s %v"},
+	}
+	for _, def := range tests {
+		if err := def.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", def)
+		}
+	}
+}
@@ -41,7 +41,8 @@ const maxEntryReadBytes int64 = 1 << 20 // 1 MiB
 const EnvBypass = "KLAUSCTL_NO_CACHE" // #nosec G101 -- constant identifier, not a credential
 
 // EnvCacheDir is an optional env var that overrides the cache directory.
-// It is the env counterpart of the --cache-dir flag.
+// It is the env counterpart of the --cache-dir flag, and also relocates the
+// pulled plugins and personalities (see config.EnvCacheDir).
 const EnvCacheDir = "KLAUSCTL_CACHE_DIR"
 
 // Layers is the set of index sub-directories the klaus-oci disk cache
@@ -255,6 +256,9 @@ type PruneOptions struct {
 	// Now is the reference time used to evaluate entry freshness.
 	// Defaults to time.Now().
 	Now time.Time
+	// DryRun counts the entries that would be removed without removing
+	// them.
+	DryRun bool
 }
 
 // PruneResult reports what Prune removed.
@@ -292,17 +296,17 @@ func Prune(opts PruneOptions) (*PruneResult, error) {
 	}
 
 	if opts.All {
-		return pruneAll(dir, res)
+		return pruneAll(dir, opts.DryRun, res)
 	}
 
 	now := opts.Now
 	if now.IsZero() {
 		now = time.Now()
 	}
-	return pruneStale(dir, now, res)
+	return pruneStale(dir, now, opts.DryRun, res)
 }
 
-func pruneAll(dir string, res *PruneResult) (*PruneResult, error) {
+func pruneAll(dir string, dryRun bool, res *PruneResult) (*PruneResult, error) {
 	// Only sweep directories that belong to the klaus-oci layout. This
 	// prevents `klausctl --cache-dir $HOME cache prune --all` from nuking
 	// unrelated files — the caller's misconfiguration is bounded to the
@@ -336,8 +340,10 @@ func pruneAll(dir string, res *PruneResult) (*PruneResult, error) {
 				return nil
 			}
 			size := info.Size()
-			if rmErr := os.Remove(path); rmErr != nil { // #nosec G122 -- header values are short, controlled inputs
-				return nil
+			if !dryRun {
+				if rmErr := os.Remove(path); rmErr != nil { // #nosec G122 -- header values are short, controlled inputs
+					return nil
+				}
 			}
 			res.FilesRemoved++
 			res.BytesRemoved += size
@@ -350,12 +356,14 @@ func pruneAll(dir string, res *PruneResult) (*PruneResult, error) {
 		// anything behind (e.g. a skipped symlink), RemoveAll would still
 		// follow it — so we use a RemoveAll on the known-safe layer path only
 		// after confirming it is not a symlink (checked above).
-		_ = os.RemoveAll(sub)
+		if !dryRun {
+			_ = os.RemoveAll(sub)
+		}
 	}
 	return res, nil
 }
 
-func pruneStale(dir string, now time.Time, res *PruneResult) (*PruneResult, error) {
+func pruneStale(dir string, now time.Time, dryRun bool, res *PruneResult) (*PruneResult, error) {
 	staleForLayer := map[string]time.Duration{
 		"catalog": klausoci.DefaultCacheCatalogStaleTTL,
 		"tags":    klausoci.DefaultCacheStaleTTL,
@@ -378,8 +386,10 @@ func pruneStale(dir string, now time.Time, res *PruneResult) (*PruneResult, erro
 				return nil
 			}
 			size := fi.Size()
-			if rmErr := os.Remove(path); rmErr != nil { // #nosec G122 -- header values are short, controlled inputs
-				return nil
+			if !dryRun {
+				if rmErr := os.Remove(path); rmErr != nil { // #nosec G122 -- header values are short, controlled inputs
+					return nil
+				}
 			}
 			res.FilesRemoved++
 			res.BytesRemoved += size
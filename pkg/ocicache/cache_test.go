@@ -240,6 +240,26 @@ func TestPrune_StaleOnly(t *testing.T) {
 	}
 }
 
+func TestPrune_DryRun(t *testing.T) {
+	dir := withCacheDir(t)
+	stale := filepath.Join(dir, "refs", "stale.json")
+	writeIndex(t, stale, map[string]any{"key": "y"},
+		klausoci.DefaultCacheStaleTTL+time.Hour)
+
+	for _, all := range []bool{false, true} {
+		res, err := Prune(PruneOptions{All: all, DryRun: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.FilesRemoved != 1 {
+			t.Errorf("All=%v: FilesRemoved = %d, want 1", all, res.FilesRemoved)
+		}
+		if _, err := os.Stat(stale); err != nil {
+			t.Errorf("All=%v: dry run removed the entry: %v", all, err)
+		}
+	}
+}
+
 func TestPrune_Disabled(t *testing.T) {
 	Configure("", true)
 	t.Cleanup(Reset)
@@ -2,30 +2,71 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	klausoci "github.com/giantswarm/klaus-oci"
+	"gopkg.in/yaml.v3"
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/ocicache"
+	"github.com/giantswarm/klausctl/pkg/secret"
 )
 
 const registryAuthEnvVar = "KLAUSCTL_REGISTRY_AUTH"
 
+// storedRegistryAuthOnce guards loading the registry credentials of the
+// secret store, which happens when the first client is created.
+var storedRegistryAuthOnce sync.Once
+
 // NewDefaultClient creates an OCI client configured with the standard
 // klausctl credential resolution (Docker/Podman config files plus the
-// KLAUSCTL_REGISTRY_AUTH env var) and the persistent on-disk cache
-// managed by pkg/ocicache. Additional options may be supplied; they are
-// applied after the defaults and can override them.
+// KLAUSCTL_REGISTRY_AUTH env var, or the credentials stored with
+// 'klausctl registry login' when it is unset) and the persistent on-disk
+// cache managed by pkg/ocicache. Registry TLS trusts the custom CAs configured
+// via pkg/cabundle, which klaus-oci picks up through http.DefaultClient.
+// Additional options may be supplied; they are applied after the defaults
+// and can override them.
 func NewDefaultClient(opts ...klausoci.ClientOption) *klausoci.Client {
+	storedRegistryAuthOnce.Do(applyStoredRegistryAuth)
 	base := []klausoci.ClientOption{klausoci.WithRegistryAuthEnv(registryAuthEnvVar)}
 	base = append(base, ocicache.Options()...)
 	return klausoci.NewClient(append(base, opts...)...)
 }
 
+// applyStoredRegistryAuth hands the registry credentials of the secret store
+// to the OCI client. klaus-oci only accepts credentials through the env var
+// named by WithRegistryAuthEnv, so they are set there for this process, and
+// only by commands that create a client. An explicitly set env var takes
+// precedence. Errors are ignored so that a broken secret store does not
+// prevent anonymous pulls.
+func applyStoredRegistryAuth() {
+	if _, ok := os.LookupEnv(registryAuthEnvVar); ok {
+		return
+	}
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		return
+	}
+	store, err := secret.Load(paths.SecretsFile)
+	if err != nil {
+		return
+	}
+	data, err := store.DockerConfigJSON()
+	if err != nil || data == nil {
+		return
+	}
+	_ = os.Setenv(registryAuthEnvVar, base64.StdEncoding.EncodeToString(data))
+}
+
 // ResolveCreateRefs resolves personality, toolchain, and plugin short names
 // to full OCI references with proper semver tags from the registry.
 // The resolver is used to expand short names against configured sources;
@@ -37,7 +78,10 @@ func ResolveCreateRefs(ctx context.Context, resolver *config.SourceResolver, per
 	client := NewDefaultClient()
 
 	if personality != "" {
-		expanded := resolver.ResolvePersonalityRef(personality)
+		expanded, err := resolver.ResolvePersonalityRef(personality)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("resolving personality: %w", err)
+		}
 		ref, err := client.ResolvePersonalityRef(ctx, expanded)
 		if err != nil {
 			return "", "", nil, fmt.Errorf("resolving personality: %w", err)
@@ -46,7 +90,10 @@ func ResolveCreateRefs(ctx context.Context, resolver *config.SourceResolver, per
 	}
 
 	if toolchain != "" {
-		expanded := resolver.ResolveToolchainRef(toolchain)
+		expanded, err := resolver.ResolveToolchainRef(toolchain)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("resolving toolchain: %w", err)
+		}
 		ref, err := client.ResolveToolchainRef(ctx, expanded)
 		if err != nil {
 			return "", "", nil, fmt.Errorf("resolving toolchain: %w", err)
@@ -56,7 +103,10 @@ func ResolveCreateRefs(ctx context.Context, resolver *config.SourceResolver, per
 
 	resolved := make([]string, 0, len(plugins))
 	for _, p := range plugins {
-		expanded := resolver.ResolvePluginRef(p)
+		expanded, err := resolver.ResolvePluginRef(p)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("resolving plugin: %w", err)
+		}
 		ref, err := client.ResolvePluginRef(ctx, expanded)
 		if err != nil {
 			return "", "", nil, fmt.Errorf("resolving plugin: %w", err)
@@ -86,35 +136,87 @@ func ResolvePluginRefs(ctx context.Context, client *klausoci.Client, refs []klau
 	return plugins, nil
 }
 
+// TryMirrors calls fn with each of refs in order until it succeeds and
+// returns its result. refs are the candidates of
+// config.SourceResolver.MirrorRefs: the mirrors first and the canonical
+// reference last. When all fail, the error of the canonical reference is
+// returned together with those of the mirrors.
+func TryMirrors[T any](refs []string, fn func(ref string) (T, error)) (T, error) {
+	var (
+		zero T
+		errs []string
+	)
+	for i, ref := range refs {
+		v, err := fn(ref)
+		if err == nil {
+			return v, nil
+		}
+		if i == len(refs)-1 {
+			if len(errs) > 0 {
+				return zero, fmt.Errorf("%w (mirrors failed too: %s)", err, strings.Join(errs, "; "))
+			}
+			return zero, err
+		}
+		errs = append(errs, err.Error())
+	}
+	return zero, fmt.Errorf("no reference to pull")
+}
+
+// mirrorRefs returns the references to try for ref. A nil resolver uses the
+// built-in source, which has no mirrors.
+func mirrorRefs(resolver *config.SourceResolver, ref string) []string {
+	if resolver == nil {
+		resolver = config.DefaultSourceResolver()
+	}
+	return resolver.MirrorRefs(ref)
+}
+
+// pulledPlugin is the outcome of pulling one plugin.
+type pulledPlugin struct {
+	ref    string
+	digest string
+	cached bool
+}
+
 // PullPlugins pulls all configured plugins to the local plugins directory.
-// Each plugin is stored at <pluginsDir>/<shortName>/. Plugins are cached by
-// digest and skipped if already up-to-date. Progress messages are written to w.
+// Plugin contents are shared by digest under <pluginsDir>/blobs/ and
+// <pluginsDir>/<shortName> points at the pulled version. Plugins are skipped
+// if already up-to-date. Progress messages are written to w.
 //
 // Plugins with a "latest" tag or no tag are resolved to the latest semver
-// tag from the registry before pulling.
-func PullPlugins(ctx context.Context, client *klausoci.Client, plugins []config.Plugin, pluginsDir string, w io.Writer) error {
+// tag from the registry before pulling. Mirrors of the plugin's source in
+// resolver are tried before its registry; resolver may be nil. Disabled
+// plugins are skipped.
+func PullPlugins(ctx context.Context, client *klausoci.Client, resolver *config.SourceResolver, plugins []config.Plugin, pluginsDir string, w io.Writer) error {
 	for _, p := range plugins {
-		ref := BuildRef(p)
-
-		resolved, err := client.ResolvePluginRef(ctx, ref)
-		if err != nil {
-			return fmt.Errorf("resolving plugin %s: %w", ref, err)
+		if !p.IsEnabled() {
+			continue
 		}
-
-		shortName := klausoci.ShortName(klausoci.RepositoryFromRef(resolved))
+		shortName := klausoci.ShortName(p.Repository)
 		destDir := filepath.Join(pluginsDir, shortName)
 
-		_, _ = fmt.Fprintf(w, "  Pulling %s...\n", resolved)
+		pulled, err := TryMirrors(mirrorRefs(resolver, BuildRef(p)), func(ref string) (pulledPlugin, error) {
+			resolved, err := client.ResolvePluginRef(ctx, ref)
+			if err != nil {
+				return pulledPlugin{}, fmt.Errorf("resolving plugin %s: %w", ref, err)
+			}
 
-		result, err := client.PullPlugin(ctx, resolved, destDir)
+			_, _ = fmt.Fprintf(w, "  Pulling %s...\n", resolved)
+
+			digest, cached, err := PullPlugin(ctx, client, resolved, destDir)
+			if err != nil {
+				return pulledPlugin{}, fmt.Errorf("pulling plugin %s: %w", resolved, err)
+			}
+			return pulledPlugin{ref: resolved, digest: digest, cached: cached}, nil
+		})
 		if err != nil {
-			return fmt.Errorf("pulling plugin %s: %w", resolved, err)
+			return err
 		}
 
-		if result.Cached {
-			_, _ = fmt.Fprintf(w, "  %s: up-to-date (%s)\n", shortName, klausoci.TruncateDigest(result.Digest))
+		if pulled.cached {
+			_, _ = fmt.Fprintf(w, "  %s: up-to-date (%s)\n", shortName, klausoci.TruncateDigest(pulled.digest))
 		} else {
-			_, _ = fmt.Fprintf(w, "  %s: pulled (%s)\n", shortName, klausoci.TruncateDigest(result.Digest))
+			_, _ = fmt.Fprintf(w, "  %s: pulled (%s)\n", shortName, klausoci.TruncateDigest(pulled.digest))
 		}
 	}
 
@@ -122,10 +224,14 @@ func PullPlugins(ctx context.Context, client *klausoci.Client, plugins []config.
 }
 
 // PluginDirs returns the container-internal mount paths for the given plugins.
-// Each plugin is mounted at /var/lib/klaus/plugins/<shortName>.
+// Each plugin is mounted at /var/lib/klaus/plugins/<shortName>; disabled
+// plugins are left out.
 func PluginDirs(plugins []config.Plugin) []string {
 	dirs := make([]string, 0, len(plugins))
 	for _, p := range plugins {
+		if !p.IsEnabled() {
+			continue
+		}
 		dirs = append(dirs, "/var/lib/klaus/plugins/"+klausoci.ShortName(p.Repository))
 	}
 	return dirs
@@ -146,23 +252,82 @@ func BuildRef(p config.Plugin) string {
 type PersonalityResult struct {
 	// Spec is the parsed personality metadata.
 	Spec klausoci.Personality
+	// Env is the environment the personality declares.
+	Env PersonalityEnv
 	// Dir is the local directory where the personality was pulled.
 	Dir string
 	// ShortName is the short name extracted from the OCI reference.
 	ShortName string
+	// Repository is the OCI repository of the personality, without tag or
+	// digest.
+	Repository string
+	// Extends is the reference of the base personality this one extends,
+	// as declared by `extends` in its personality.yaml.
+	Extends string
+	// Warnings lists non-fatal problems found while resolving the
+	// personality's toolchain and plugins, e.g. a plugin that is not in the
+	// registry.
+	Warnings []string
 }
 
 // ResolvePersonality pulls a personality OCI artifact and parses its spec.
-// The personality is stored at <personalitiesDir>/<shortName>/.
-func ResolvePersonality(ctx context.Context, client *klausoci.Client, ref, personalitiesDir string, w io.Writer) (*PersonalityResult, error) {
+// The personality is stored at <personalitiesDir>/<shortName>/. Mirrors of
+// the personality's source in resolver are tried before its registry;
+// resolver may be nil. When the personality extends a base personality, the
+// bases are pulled as well, each into its own directory, see baseDir, and
+// merged into the result, see resolveExtends. Short names of bases are
+// expanded with the default source of resolver, like the personality
+// itself.
+func ResolvePersonality(ctx context.Context, client *klausoci.Client, resolver *config.SourceResolver, ref, personalitiesDir string, w io.Writer) (*PersonalityResult, error) {
+	result, err := resolveExtends(ref, func(ref string, base bool) (*PersonalityResult, error) {
+		if resolver != nil {
+			expanded, err := resolver.ResolvePersonalityRef(ref)
+			if err != nil {
+				return nil, err
+			}
+			ref = expanded
+		}
+		resolved, err := client.ResolvePersonalityRef(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("resolving personality ref %s: %w", ref, err)
+		}
+		repo := klausoci.RepositoryFromRef(resolved)
+		destDir := filepath.Join(personalitiesDir, klausoci.ShortName(repo))
+		if base {
+			destDir = baseDir(personalitiesDir, repo)
+		}
+		return pullPersonality(ctx, client, resolver, resolved, destDir, w)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, err := client.ResolvePersonalityDeps(ctx, result.Spec)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("resolving dependencies of personality %s: %v", result.ShortName, err))
+	} else {
+		result.Warnings = append(result.Warnings, deps.Warnings...)
+	}
+	return result, nil
+}
+
+// pullPersonality pulls a single personality artifact into destDir and
+// reads its spec, environment and base reference, without following the
+// base.
+func pullPersonality(ctx context.Context, client *klausoci.Client, resolver *config.SourceResolver, ref, destDir string, w io.Writer) (*PersonalityResult, error) {
 	repo := klausoci.RepositoryFromRef(ref)
 	shortName := klausoci.ShortName(repo)
-	destDir := filepath.Join(personalitiesDir, shortName)
 
-	_, _ = fmt.Fprintf(w, "  Pulling personality %s...\n", ref)
-	result, err := client.PullPersonality(ctx, ref, destDir)
+	result, err := TryMirrors(mirrorRefs(resolver, ref), func(ref string) (*klausoci.PulledPersonality, error) {
+		_, _ = fmt.Fprintf(w, "  Pulling personality %s...\n", ref)
+		result, err := client.PullPersonality(ctx, ref, destDir)
+		if err != nil {
+			return nil, fmt.Errorf("pulling personality %s: %w", ref, err)
+		}
+		return result, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("pulling personality %s: %w", ref, err)
+		return nil, err
 	}
 
 	if result.Cached {
@@ -171,13 +336,162 @@ func ResolvePersonality(ctx context.Context, client *klausoci.Client, ref, perso
 		_, _ = fmt.Fprintf(w, "  %s: pulled (%s)\n", shortName, klausoci.TruncateDigest(result.Digest))
 	}
 
+	env, err := LoadPersonalityEnv(destDir)
+	if err != nil {
+		return nil, fmt.Errorf("personality %s: %w", shortName, err)
+	}
+	extends, err := LoadPersonalityExtends(destDir)
+	if err != nil {
+		return nil, fmt.Errorf("personality %s: %w", shortName, err)
+	}
+
 	return &PersonalityResult{
-		Spec:      result.Personality,
-		Dir:       destDir,
-		ShortName: shortName,
+		Spec:       result.Personality,
+		Env:        env,
+		Dir:        destDir,
+		ShortName:  shortName,
+		Repository: repo,
+		Extends:    extends,
 	}, nil
 }
 
+// Depths of personality dependency resolution, see ResolvePersonalityDeps.
+const (
+	ResolveDepthNone       = 0
+	ResolveDepthDirect     = 1
+	ResolveDepthTransitive = 2
+)
+
+// PersonalityDepResolver resolves the toolchain and plugins a personality
+// references and looks up the base personalities it extends, see
+// NewPersonalityDepResolver.
+type PersonalityDepResolver interface {
+	ResolvePersonalityDeps(ctx context.Context, p klausoci.Personality) (*klausoci.ResolvedDependencies, error)
+	DescribePersonality(ctx context.Context, ref string) (*klausoci.DescribedPersonality, error)
+	// PersonalityExtends returns the reference of the base personality
+	// that the personality at ref extends, or "" when it extends none.
+	PersonalityExtends(ctx context.Context, ref string) (string, error)
+}
+
+// NewPersonalityDepResolver returns a PersonalityDepResolver backed by
+// client. Short names of base personalities are expanded with the default
+// source of resolver, which may be nil.
+func NewPersonalityDepResolver(client *klausoci.Client, resolver *config.SourceResolver) PersonalityDepResolver {
+	return &ociDepResolver{Client: client, resolver: resolver}
+}
+
+type ociDepResolver struct {
+	*klausoci.Client
+	resolver *config.SourceResolver
+}
+
+// PersonalityExtends pulls the personality at ref into a temporary
+// directory: `extends` is only recorded in its personality.yaml, not in the
+// metadata DescribePersonality reads.
+func (r *ociDepResolver) PersonalityExtends(ctx context.Context, ref string) (string, error) {
+	dir, err := os.MkdirTemp("", "klausctl-personality-")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if _, err := r.PullPersonality(ctx, ref, dir); err != nil {
+		return "", fmt.Errorf("pulling personality %s: %w", ref, err)
+	}
+	extends, err := LoadPersonalityExtends(dir)
+	if err != nil || extends == "" || r.resolver == nil {
+		return extends, err
+	}
+	return r.resolver.ResolvePersonalityRef(extends)
+}
+
+// ValidateResolveDepth checks that depth is one of the ResolveDepth values.
+func ValidateResolveDepth(depth int) error {
+	if depth < ResolveDepthNone || depth > ResolveDepthTransitive {
+		return fmt.Errorf("resolve depth must be %d (none), %d (direct) or %d (transitive), got %d",
+			ResolveDepthNone, ResolveDepthDirect, ResolveDepthTransitive, depth)
+	}
+	return nil
+}
+
+// ResolvePersonalityDeps resolves the dependencies of the personality p at
+// ref up to maxDepth levels. ResolveDepthNone resolves nothing and returns
+// nil; ResolveDepthDirect describes the toolchain and plugins p references.
+// ResolveDepthTransitive also follows the chain of base personalities p
+// extends and adds the dependencies of each base that the personalities
+// extending it do not override, the same way they are merged on start.
+//
+// A dependency that cannot be resolved does not fail the resolution; it is
+// reported as a warning naming the node, e.g. "plugin gs-sre:v1: not found".
+func ResolvePersonalityDeps(ctx context.Context, r PersonalityDepResolver, ref string, p klausoci.Personality, maxDepth int) (*klausoci.ResolvedDependencies, error) {
+	if err := ValidateResolveDepth(maxDepth); err != nil {
+		return nil, err
+	}
+	if maxDepth == ResolveDepthNone {
+		return nil, nil
+	}
+	deps, err := r.ResolvePersonalityDeps(ctx, p)
+	if err != nil || maxDepth == ResolveDepthDirect {
+		return deps, err
+	}
+
+	declared := klausoci.Personality{Toolchain: p.Toolchain, Plugins: slices.Clone(p.Plugins)}
+	chain := []string{klausoci.RepositoryFromRef(ref)}
+	for {
+		baseRef, err := r.PersonalityExtends(ctx, ref)
+		if err != nil {
+			deps.Warnings = append(deps.Warnings, fmt.Sprintf("personality %s: %v", ref, err))
+			return deps, nil
+		}
+		if baseRef == "" {
+			return deps, nil
+		}
+		repo := klausoci.RepositoryFromRef(baseRef)
+		if slices.Contains(chain, repo) {
+			deps.Warnings = append(deps.Warnings, fmt.Sprintf("personality inheritance cycle: %s", strings.Join(append(chain, repo), " -> ")))
+			return deps, nil
+		}
+		chain = append(chain, repo)
+
+		base, err := r.DescribePersonality(ctx, baseRef)
+		if err != nil {
+			deps.Warnings = append(deps.Warnings, fmt.Sprintf("base personality %s: %v", baseRef, err))
+			return deps, nil
+		}
+		baseDeps, err := r.ResolvePersonalityDeps(ctx, inheritDeps(&declared, base.Personality))
+		if err != nil {
+			deps.Warnings = append(deps.Warnings, fmt.Sprintf("base personality %s: %v", baseRef, err))
+			return deps, nil
+		}
+		if baseDeps.Toolchain != nil {
+			deps.Toolchain = baseDeps.Toolchain
+		}
+		deps.Plugins = append(deps.Plugins, baseDeps.Plugins...)
+		deps.Warnings = append(deps.Warnings, baseDeps.Warnings...)
+		ref = base.Ref
+	}
+}
+
+// inheritDeps returns the toolchain and plugins of base that declared, the
+// dependencies declared so far along an extends chain, does not override,
+// and adds them to declared.
+func inheritDeps(declared *klausoci.Personality, base klausoci.Personality) klausoci.Personality {
+	var inherited klausoci.Personality
+	if declared.Toolchain.Repository == "" {
+		inherited.Toolchain = base.Toolchain
+		declared.Toolchain = base.Toolchain
+	}
+	for _, bp := range base.Plugins {
+		if !slices.ContainsFunc(declared.Plugins, func(p klausoci.PluginReference) bool {
+			return p.Repository == bp.Repository
+		}) {
+			inherited.Plugins = append(inherited.Plugins, bp)
+			declared.Plugins = append(declared.Plugins, bp)
+		}
+	}
+	return inherited
+}
+
 // LoadPersonalitySpec reads and parses a personality.yaml from the given directory.
 func LoadPersonalitySpec(dir string) (klausoci.Personality, error) {
 	p, err := klausoci.ReadPersonalityFromDir(dir)
@@ -187,6 +501,91 @@ func LoadPersonalitySpec(dir string) (klausoci.Personality, error) {
 	return *p, nil
 }
 
+// PersonalityEnv is the environment a personality declares in its
+// personality.yaml, in the same keys as the instance config. klaus-oci does
+// not parse these, so they are read from the pulled personality directly.
+type PersonalityEnv struct {
+	EnvVars    map[string]string `yaml:"envVars,omitempty"`
+	EnvForward []string          `yaml:"envForward,omitempty"`
+}
+
+// LoadPersonalityEnv reads the environment declared by the personality.yaml
+// in dir. A personality without one, or without a personality.yaml, has an
+// empty PersonalityEnv.
+func LoadPersonalityEnv(dir string) (PersonalityEnv, error) {
+	var env PersonalityEnv
+	data, err := os.ReadFile(filepath.Join(dir, "personality.yaml")) // #nosec G304 -- pulled personality directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return env, nil
+		}
+		return env, fmt.Errorf("reading personality.yaml: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &env); err != nil {
+		return env, fmt.Errorf("parsing personality.yaml: %w", err)
+	}
+	return env, nil
+}
+
+// sensitiveEnvSuffixes are the name suffixes of env vars that carry
+// credentials or redirect the agent's API traffic.
+var sensitiveEnvSuffixes = []string{"_BASE_URL", "_TOKEN", "_KEY", "_SECRET", "_PASSWORD"}
+
+// isSensitiveEnv reports whether name ends in one of sensitiveEnvSuffixes.
+func isSensitiveEnv(name string) bool {
+	upper := strings.ToUpper(name)
+	return slices.ContainsFunc(sensitiveEnvSuffixes, func(s string) bool {
+		return strings.HasSuffix(upper, s)
+	})
+}
+
+// MergePersonalityEnv merges the environment of the personality pr into
+// cfg. The instance config takes precedence: env vars it sets keep their
+// value, and the personality's forwarded names are appended after its own.
+// Each name must be a valid env var name, and a personality may only set or
+// forward a sensitive one, such as *_TOKEN or *_BASE_URL, that the instance
+// config sets or forwards itself; otherwise an error is returned and cfg is
+// left unchanged. Overridden and newly forwarded names are reported in
+// pr.Warnings.
+func MergePersonalityEnv(pr *PersonalityResult, cfg *config.Config) error {
+	env := pr.Env
+	keys := slices.Sorted(maps.Keys(env.EnvVars))
+	for _, k := range keys {
+		if !config.IsValidEnvName(k) {
+			return fmt.Errorf("personality %s: invalid env var name %q", pr.ShortName, k)
+		}
+		if _, ok := cfg.EnvVars[k]; !ok && isSensitiveEnv(k) {
+			return fmt.Errorf("personality %s sets sensitive env var %s; set it in envVars of the instance config instead", pr.ShortName, k)
+		}
+	}
+	for _, name := range env.EnvForward {
+		if !config.IsValidEnvName(name) {
+			return fmt.Errorf("personality %s: invalid envForward name %q", pr.ShortName, name)
+		}
+		if !slices.Contains(cfg.EnvForward, name) && isSensitiveEnv(name) {
+			return fmt.Errorf("personality %s forwards sensitive host env var %s; add it to envForward of the instance config to allow it", pr.ShortName, name)
+		}
+	}
+
+	for _, k := range keys {
+		if _, ok := cfg.EnvVars[k]; ok {
+			pr.Warnings = append(pr.Warnings, fmt.Sprintf("instance config overrides env var %s of personality %s", k, pr.ShortName))
+			continue
+		}
+		if cfg.EnvVars == nil {
+			cfg.EnvVars = make(map[string]string, len(env.EnvVars))
+		}
+		cfg.EnvVars[k] = env.EnvVars[k]
+	}
+	for _, name := range env.EnvForward {
+		if !slices.Contains(cfg.EnvForward, name) {
+			cfg.EnvForward = append(cfg.EnvForward, name)
+			pr.Warnings = append(pr.Warnings, fmt.Sprintf("personality %s forwards host env var %s", pr.ShortName, name))
+		}
+	}
+	return nil
+}
+
 // HasSOULFile reports whether a pulled personality directory contains a SOUL.md.
 func HasSOULFile(personalityDir string) bool {
 	_, err := os.Stat(filepath.Join(personalityDir, "SOUL.md"))
@@ -1,13 +1,19 @@
 package orchestrator
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 
 	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/secret"
 )
 
 func TestPluginDirs(t *testing.T) {
@@ -223,6 +229,109 @@ func TestMergePluginsDeduplicatesPersonality(t *testing.T) {
 	}
 }
 
+func TestLoadPersonalityEnv(t *testing.T) {
+	dir := t.TempDir()
+	spec := `
+name: sre
+envVars:
+  LOG_LEVEL: debug
+  KUBECONFIG: /home/klaus/.kube/config
+envForward:
+  - GITHUB_TOKEN
+`
+	if err := os.WriteFile(filepath.Join(dir, "personality.yaml"), []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := LoadPersonalityEnv(dir)
+	if err != nil {
+		t.Fatalf("LoadPersonalityEnv() error = %v", err)
+	}
+	want := PersonalityEnv{
+		EnvVars:    map[string]string{"LOG_LEVEL": "debug", "KUBECONFIG": "/home/klaus/.kube/config"},
+		EnvForward: []string{"GITHUB_TOKEN"},
+	}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("env = %+v, want %+v", env, want)
+	}
+
+	env, err = LoadPersonalityEnv(t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadPersonalityEnv() without personality.yaml error = %v", err)
+	}
+	if !reflect.DeepEqual(env, PersonalityEnv{}) {
+		t.Errorf("env without personality.yaml = %+v, want empty", env)
+	}
+}
+
+func TestMergePersonalityEnvInstanceWins(t *testing.T) {
+	cfg := &config.Config{
+		EnvVars:    map[string]string{"LOG_LEVEL": "info"},
+		EnvForward: []string{"GITHUB_TOKEN"},
+	}
+	pr := &PersonalityResult{ShortName: "sre", Env: PersonalityEnv{
+		EnvVars:    map[string]string{"LOG_LEVEL": "debug", "REGION": "eu"},
+		EnvForward: []string{"GITHUB_TOKEN", "AWS_PROFILE"},
+	}}
+	if err := MergePersonalityEnv(pr, cfg); err != nil {
+		t.Fatalf("MergePersonalityEnv() error = %v", err)
+	}
+
+	if want := map[string]string{"LOG_LEVEL": "info", "REGION": "eu"}; !reflect.DeepEqual(cfg.EnvVars, want) {
+		t.Errorf("EnvVars = %v, want %v", cfg.EnvVars, want)
+	}
+	if want := []string{"GITHUB_TOKEN", "AWS_PROFILE"}; !reflect.DeepEqual(cfg.EnvForward, want) {
+		t.Errorf("EnvForward = %v, want %v", cfg.EnvForward, want)
+	}
+	want := []string{
+		"instance config overrides env var LOG_LEVEL of personality sre",
+		"personality sre forwards host env var AWS_PROFILE",
+	}
+	if !reflect.DeepEqual(pr.Warnings, want) {
+		t.Errorf("Warnings = %q, want %q", pr.Warnings, want)
+	}
+}
+
+func TestMergePersonalityEnvEmptyConfig(t *testing.T) {
+	cfg := &config.Config{}
+	pr := &PersonalityResult{Env: PersonalityEnv{EnvVars: map[string]string{"REGION": "eu"}}}
+	if err := MergePersonalityEnv(pr, cfg); err != nil {
+		t.Fatalf("MergePersonalityEnv() error = %v", err)
+	}
+	if cfg.EnvVars["REGION"] != "eu" {
+		t.Errorf("EnvVars = %v, want REGION=eu", cfg.EnvVars)
+	}
+	if cfg.EnvForward != nil {
+		t.Errorf("EnvForward = %v, want nil", cfg.EnvForward)
+	}
+}
+
+func TestMergePersonalityEnvRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     PersonalityEnv
+		wantErr string
+	}{
+		{"invalid name", PersonalityEnv{EnvVars: map[string]string{"BAD-NAME": "x"}}, "invalid env var name"},
+		{"invalid forward", PersonalityEnv{EnvForward: []string{"1PATH"}}, "invalid envForward name"},
+		{"base url", PersonalityEnv{EnvVars: map[string]string{"ANTHROPIC_BASE_URL": "https://evil.example.com"}}, "sets sensitive env var ANTHROPIC_BASE_URL"},
+		{"token", PersonalityEnv{EnvForward: []string{"GITHUB_TOKEN"}}, "forwards sensitive host env var GITHUB_TOKEN"},
+		{"key", PersonalityEnv{EnvForward: []string{"aws_secret_access_key"}}, "forwards sensitive host env var"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			err := MergePersonalityEnv(&PersonalityResult{ShortName: "sre", Env: tt.env}, cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if cfg.EnvVars != nil || cfg.EnvForward != nil {
+				t.Errorf("cfg changed on error: %v %v", cfg.EnvVars, cfg.EnvForward)
+			}
+		})
+	}
+}
+
 func TestLoadPersonalitySpec(t *testing.T) {
 	dir := t.TempDir()
 	specContent := `
@@ -300,3 +409,317 @@ func TestNewDefaultClientWithOpts(t *testing.T) {
 		t.Fatal("NewDefaultClient(WithPlainHTTP(true)) returned nil")
 	}
 }
+
+func TestApplyStoredRegistryAuth(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	paths, err := config.DefaultPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(paths.SecretsFile), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	store, err := secret.Load(paths.SecretsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetRegistryAuth("ghcr.io", "octocat", "token"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(registryAuthEnvVar, "explicit")
+	applyStoredRegistryAuth()
+	if got := os.Getenv(registryAuthEnvVar); got != "explicit" {
+		t.Errorf("explicit %s overwritten with %q", registryAuthEnvVar, got)
+	}
+
+	if err := os.Unsetenv(registryAuthEnvVar); err != nil {
+		t.Fatal(err)
+	}
+	applyStoredRegistryAuth()
+	data, err := base64.StdEncoding.DecodeString(os.Getenv(registryAuthEnvVar))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", registryAuthEnvVar, err)
+	}
+	if !strings.Contains(string(data), `"ghcr.io"`) {
+		t.Errorf("%s = %s, want the ghcr.io credentials", registryAuthEnvVar, data)
+	}
+}
+
+// fakeDepResolver describes every referenced dependency except those in
+// missing, which it reports as warnings like the OCI client does.
+// personalities describes base personalities by reference, and extends maps
+// personality references to the reference of their base.
+type fakeDepResolver struct {
+	missing       map[string]bool
+	personalities map[string]klausoci.Personality
+	extends       map[string]string
+	calls         int
+}
+
+func (f *fakeDepResolver) ResolvePersonalityDeps(_ context.Context, p klausoci.Personality) (*klausoci.ResolvedDependencies, error) {
+	f.calls++
+	deps := &klausoci.ResolvedDependencies{}
+	if tc := p.Toolchain.Repository; tc != "" {
+		if f.missing[tc] {
+			deps.Warnings = append(deps.Warnings, "toolchain "+tc+": not found")
+		} else {
+			deps.Toolchain = &klausoci.DescribedToolchain{
+				ArtifactInfo: klausoci.ArtifactInfo{Ref: tc + ":" + p.Toolchain.Tag},
+			}
+		}
+	}
+	for _, ref := range p.Plugins {
+		if f.missing[ref.Repository] {
+			deps.Warnings = append(deps.Warnings, "plugin "+ref.Repository+": not found")
+			continue
+		}
+		deps.Plugins = append(deps.Plugins, klausoci.DescribedPlugin{
+			ArtifactInfo: klausoci.ArtifactInfo{Ref: ref.Repository + ":" + ref.Tag},
+		})
+	}
+	return deps, nil
+}
+
+func (f *fakeDepResolver) DescribePersonality(_ context.Context, ref string) (*klausoci.DescribedPersonality, error) {
+	p, ok := f.personalities[ref]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &klausoci.DescribedPersonality{ArtifactInfo: klausoci.ArtifactInfo{Ref: ref}, Personality: p}, nil
+}
+
+func (f *fakeDepResolver) PersonalityExtends(_ context.Context, ref string) (string, error) {
+	return f.extends[ref], nil
+}
+
+func TestResolvePersonalityDeps(t *testing.T) {
+	p := klausoci.Personality{
+		Name:      "sre",
+		Toolchain: klausoci.ToolchainReference{Repository: "example.com/toolchains/go", Tag: "v1.0.0"},
+		Plugins: []klausoci.PluginReference{
+			{Repository: "example.com/plugins/gs-base", Tag: "v1.0.0"},
+			{Repository: "example.com/plugins/gs-sre", Tag: "v2.0.0"},
+			{Repository: "example.com/plugins/retired", Tag: "v0.1.0"},
+		},
+	}
+	// sre extends team, which extends root.
+	personalities := map[string]klausoci.Personality{
+		"example.com/personalities/team:v1.0.0": {
+			Name:      "team",
+			Toolchain: klausoci.ToolchainReference{Repository: "example.com/toolchains/python", Tag: "v1.0.0"},
+			Plugins: []klausoci.PluginReference{
+				{Repository: "example.com/plugins/gs-sre", Tag: "v1.0.0"},
+				{Repository: "example.com/plugins/team", Tag: "v1.0.0"},
+			},
+		},
+		"example.com/personalities/root:v1.0.0": {
+			Name: "root",
+			Plugins: []klausoci.PluginReference{
+				{Repository: "example.com/plugins/root", Tag: "v1.0.0"},
+				{Repository: "example.com/plugins/gone", Tag: "v1.0.0"},
+			},
+		},
+	}
+	extends := map[string]string{
+		"example.com/personalities/sre:v1.0.0":  "example.com/personalities/team:v1.0.0",
+		"example.com/personalities/team:v1.0.0": "example.com/personalities/root:v1.0.0",
+	}
+
+	tests := []struct {
+		name         string
+		depth        int
+		wantNil      bool
+		wantPlugins  []string
+		wantWarnings []string
+	}{
+		{name: "none", depth: ResolveDepthNone, wantNil: true},
+		{
+			name:         "direct",
+			depth:        ResolveDepthDirect,
+			wantPlugins:  []string{"example.com/plugins/gs-base:v1.0.0", "example.com/plugins/gs-sre:v2.0.0"},
+			wantWarnings: []string{"plugin example.com/plugins/retired: not found"},
+		},
+		{
+			name:  "transitive",
+			depth: ResolveDepthTransitive,
+			wantPlugins: []string{
+				"example.com/plugins/gs-base:v1.0.0",
+				"example.com/plugins/gs-sre:v2.0.0",
+				"example.com/plugins/team:v1.0.0",
+				"example.com/plugins/root:v1.0.0",
+			},
+			wantWarnings: []string{
+				"plugin example.com/plugins/retired: not found",
+				"plugin example.com/plugins/gone: not found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &fakeDepResolver{
+				missing:       map[string]bool{"example.com/plugins/retired": true, "example.com/plugins/gone": true},
+				personalities: personalities,
+				extends:       extends,
+			}
+			deps, err := ResolvePersonalityDeps(context.Background(), r, "example.com/personalities/sre:v1.0.0", p, tt.depth)
+			if err != nil {
+				t.Fatalf("ResolvePersonalityDeps() error = %v", err)
+			}
+			if tt.wantNil {
+				if deps != nil || r.calls != 0 {
+					t.Errorf("expected no resolution, got %+v after %d calls", deps, r.calls)
+				}
+				return
+			}
+
+			if deps.Toolchain == nil || deps.Toolchain.Ref != "example.com/toolchains/go:v1.0.0" {
+				t.Errorf("Toolchain = %+v, want the one of sre", deps.Toolchain)
+			}
+			var plugins []string
+			for _, pl := range deps.Plugins {
+				plugins = append(plugins, pl.Ref)
+			}
+			if !reflect.DeepEqual(plugins, tt.wantPlugins) {
+				t.Errorf("Plugins = %v, want %v", plugins, tt.wantPlugins)
+			}
+			if !reflect.DeepEqual(deps.Warnings, tt.wantWarnings) {
+				t.Errorf("Warnings = %v, want %v", deps.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestResolvePersonalityDepsInheritsToolchain(t *testing.T) {
+	r := &fakeDepResolver{
+		personalities: map[string]klausoci.Personality{
+			"example.com/personalities/base:v1": {Toolchain: klausoci.ToolchainReference{Repository: "example.com/toolchains/go", Tag: "v1"}},
+		},
+		extends: map[string]string{"example.com/personalities/sre:v1": "example.com/personalities/base:v1"},
+	}
+	deps, err := ResolvePersonalityDeps(context.Background(), r, "example.com/personalities/sre:v1", klausoci.Personality{}, ResolveDepthTransitive)
+	if err != nil {
+		t.Fatalf("ResolvePersonalityDeps() error = %v", err)
+	}
+	if deps.Toolchain == nil || deps.Toolchain.Ref != "example.com/toolchains/go:v1" {
+		t.Errorf("Toolchain = %+v, want the one of base", deps.Toolchain)
+	}
+}
+
+func TestResolvePersonalityDepsCycle(t *testing.T) {
+	r := &fakeDepResolver{
+		personalities: map[string]klausoci.Personality{
+			"example.com/personalities/a:v1": {},
+			"example.com/personalities/b:v1": {},
+		},
+		extends: map[string]string{
+			"example.com/personalities/a:v1": "example.com/personalities/b:v1",
+			"example.com/personalities/b:v1": "example.com/personalities/a:v1",
+		},
+	}
+	deps, err := ResolvePersonalityDeps(context.Background(), r, "example.com/personalities/a:v1", klausoci.Personality{}, ResolveDepthTransitive)
+	if err != nil {
+		t.Fatalf("ResolvePersonalityDeps() error = %v", err)
+	}
+	want := []string{"personality inheritance cycle: example.com/personalities/a -> example.com/personalities/b -> example.com/personalities/a"}
+	if !reflect.DeepEqual(deps.Warnings, want) {
+		t.Errorf("Warnings = %v, want %v", deps.Warnings, want)
+	}
+}
+
+func TestResolvePersonalityDepsInvalidDepth(t *testing.T) {
+	for _, depth := range []int{-1, 3} {
+		r := &fakeDepResolver{}
+		_, err := ResolvePersonalityDeps(context.Background(), r, "example.com/personalities/sre:v1", klausoci.Personality{}, depth)
+		if err == nil || !strings.Contains(err.Error(), "resolve depth must be") {
+			t.Errorf("depth %d: error = %v, want invalid depth error", depth, err)
+		}
+		if r.calls != 0 {
+			t.Errorf("depth %d: resolver called %d times", depth, r.calls)
+		}
+	}
+}
+
+func TestTryMirrorsFallsBackFromUnreachablePrimary(t *testing.T) {
+	resolver := config.NewSourceResolver([]config.Source{{
+		Name:     "team",
+		Registry: "primary.example.com/team",
+		Mirrors:  []string{"down.example.com/team", "mirror.example.com/team"},
+		Default:  true,
+	}})
+	refs := mirrorRefs(resolver, "primary.example.com/team/klaus-plugins/gs-base:v1.0.0")
+
+	// The primary and the first mirror are unreachable.
+	reachable := map[string]bool{"mirror.example.com/team/klaus-plugins/gs-base:v1.0.0": true}
+	var tried []string
+	got, err := TryMirrors(refs, func(ref string) (string, error) {
+		tried = append(tried, ref)
+		if !reachable[ref] {
+			return "", errors.New("connection refused")
+		}
+		return "sha256:abc", nil
+	})
+	if err != nil {
+		t.Fatalf("TryMirrors() error = %v", err)
+	}
+	if got != "sha256:abc" {
+		t.Errorf("TryMirrors() = %q, want sha256:abc", got)
+	}
+	want := []string{"down.example.com/team/klaus-plugins/gs-base:v1.0.0", "mirror.example.com/team/klaus-plugins/gs-base:v1.0.0"}
+	if !reflect.DeepEqual(tried, want) {
+		t.Errorf("tried %v, want %v", tried, want)
+	}
+}
+
+func TestTryMirrorsAllFail(t *testing.T) {
+	refs := []string{"mirror.example.com/p:v1", "primary.example.com/p:v1"}
+	primaryErr := errors.New("primary down")
+	_, err := TryMirrors(refs, func(ref string) (int, error) {
+		if ref == "primary.example.com/p:v1" {
+			return 0, primaryErr
+		}
+		return 0, errors.New("mirror down")
+	})
+	if !errors.Is(err, primaryErr) {
+		t.Fatalf("error = %v, want it to wrap the primary error", err)
+	}
+	if !strings.Contains(err.Error(), "mirror down") {
+		t.Errorf("error = %v, want it to mention the mirror error", err)
+	}
+}
+
+func TestMirrorRefsNilResolver(t *testing.T) {
+	ref := config.DefaultSourceRegistry + "/klaus-plugins/gs-base:v1.0.0"
+	if got := mirrorRefs(nil, ref); !reflect.DeepEqual(got, []string{ref}) {
+		t.Errorf("mirrorRefs(nil) = %v, want only %q", got, ref)
+	}
+}
+
+func TestResolveCreateRefsForSource(t *testing.T) {
+	resolver, err := config.NewSourceResolver([]config.Source{
+		{Name: "giantswarm", Registry: config.DefaultSourceRegistry, Default: true},
+		{Name: "team", Registry: "team.io/x"},
+	}).ForSource("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pinned tags resolve without contacting the registry.
+	personality, toolchain, plugins, err := ResolveCreateRefs(context.Background(), resolver,
+		"sre:v1.0.0", "go:v1.2.0", []string{"gs-base:v0.1.0"})
+	if err != nil {
+		t.Fatalf("ResolveCreateRefs() error = %v", err)
+	}
+	if want := "team.io/x/klaus-personalities/sre:v1.0.0"; personality != want {
+		t.Errorf("personality = %q, want %q", personality, want)
+	}
+	if want := "team.io/x/klaus-toolchains/go:v1.2.0"; toolchain != want {
+		t.Errorf("toolchain = %q, want %q", toolchain, want)
+	}
+	if want := []string{"team.io/x/klaus-plugins/gs-base:v0.1.0"}; !reflect.DeepEqual(plugins, want) {
+		t.Errorf("plugins = %v, want %v", plugins, want)
+	}
+}
@@ -0,0 +1,138 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	"gopkg.in/yaml.v3"
+)
+
+// personalityBasesDir is the directory under the personalities directory
+// that holds the base personalities pulled for `extends`. Its name cannot
+// be a short name, so local listings skip it.
+const personalityBasesDir = ".bases"
+
+// baseDir returns the directory a base personality of repository repo is
+// pulled into. It is keyed on the full repository so that a base never
+// shares a directory with the personality extending it, even when both have
+// the same short name.
+func baseDir(personalitiesDir, repo string) string {
+	return filepath.Join(personalitiesDir, personalityBasesDir, filepath.FromSlash(repo))
+}
+
+// LoadPersonalityExtends reads the base personality reference declared as
+// `extends` by the personality.yaml in dir. A personality without one, or
+// without a personality.yaml, extends nothing and "" is returned.
+func LoadPersonalityExtends(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "personality.yaml")) // #nosec G304 -- pulled personality directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("reading personality.yaml: %w", err)
+	}
+	var spec struct {
+		Extends string `yaml:"extends,omitempty"`
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return "", fmt.Errorf("parsing personality.yaml: %w", err)
+	}
+	return strings.TrimSpace(spec.Extends), nil
+}
+
+// resolveExtends pulls the personality at ref with pull and, when it
+// extends a base personality, recursively resolves the base and merges it
+// into the result, see mergePersonalityBase. pull is told whether ref is a
+// base. chain holds the repositories of the personalities that extend ref
+// and is used to detect cycles.
+func resolveExtends(ref string, pull func(ref string, base bool) (*PersonalityResult, error), chain []string) (*PersonalityResult, error) {
+	result, err := pull(ref, len(chain) > 0)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(chain, result.Repository) {
+		return nil, fmt.Errorf("personality inheritance cycle: %s", strings.Join(append(chain, result.Repository), " -> "))
+	}
+	if result.Extends == "" {
+		return result, nil
+	}
+
+	base, err := resolveExtends(result.Extends, pull, append(chain, result.Repository))
+	if err != nil {
+		return nil, err
+	}
+	mergePersonalityBase(result, base)
+	return result, nil
+}
+
+// mergePersonalityBase merges the toolchain, plugins and environment of
+// base into r. The values of r take precedence; each value of base that r
+// overrides with a different one is reported as a warning of r, sorted. The
+// warnings of base come first.
+func mergePersonalityBase(r, base *PersonalityResult) {
+	warnings := slices.Clone(base.Warnings)
+	override := func(what, change string) {
+		warnings = append(warnings, fmt.Sprintf("personality %s overrides %s of base personality %s%s",
+			r.ShortName, what, base.ShortName, change))
+	}
+
+	switch {
+	case r.Spec.Toolchain.Repository == "":
+		r.Spec.Toolchain = base.Spec.Toolchain
+	case base.Spec.Toolchain.Repository != "" && base.Spec.Toolchain.Ref() != r.Spec.Toolchain.Ref():
+		override("the toolchain", fmt.Sprintf(" (%s -> %s)", base.Spec.Toolchain.Ref(), r.Spec.Toolchain.Ref()))
+	}
+
+	for _, bp := range base.Spec.Plugins {
+		i := slices.IndexFunc(r.Spec.Plugins, func(p klausoci.PluginReference) bool {
+			return p.Repository == bp.Repository
+		})
+		if i < 0 {
+			r.Spec.Plugins = append(r.Spec.Plugins, bp)
+			continue
+		}
+		if p := r.Spec.Plugins[i]; p.Tag != bp.Tag || p.Digest != bp.Digest {
+			override("plugin "+bp.Repository, fmt.Sprintf(" (%s -> %s)", pluginVersion(bp.Tag, bp.Digest), pluginVersion(p.Tag, p.Digest)))
+		}
+	}
+
+	for k, v := range base.Env.EnvVars {
+		if cur, ok := r.Env.EnvVars[k]; ok {
+			// Env values may be secrets and are not shown.
+			if cur != v {
+				override("env var "+k, "")
+			}
+			continue
+		}
+		if r.Env.EnvVars == nil {
+			r.Env.EnvVars = make(map[string]string, len(base.Env.EnvVars))
+		}
+		r.Env.EnvVars[k] = v
+	}
+	for _, name := range base.Env.EnvForward {
+		if !slices.Contains(r.Env.EnvForward, name) {
+			r.Env.EnvForward = append(r.Env.EnvForward, name)
+		}
+	}
+
+	slices.Sort(warnings[len(base.Warnings):])
+	r.Warnings = append(warnings, r.Warnings...)
+}
+
+// pluginVersion formats the tag or digest of a plugin reference for
+// warnings.
+func pluginVersion(tag, digest string) string {
+	switch {
+	case digest != "":
+		return digest
+	case tag != "":
+		return tag
+	default:
+		return "untagged"
+	}
+}
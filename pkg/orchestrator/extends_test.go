@@ -0,0 +1,173 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+func TestLoadPersonalityExtends(t *testing.T) {
+	dir := t.TempDir()
+	if got, err := LoadPersonalityExtends(dir); err != nil || got != "" {
+		t.Fatalf("without personality.yaml: got %q, %v", got, err)
+	}
+
+	content := "name: sre-k8s\nextends: gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, "personality.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadPersonalityExtends(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0" {
+		t.Errorf("extends = %q", got)
+	}
+}
+
+// fakePersonalities returns a pull function serving the given personalities
+// by reference and records the pulled references, marking bases with a
+// "base:" prefix. Personalities without a repository get
+// example.com/personalities/<ref>.
+func fakePersonalities(personalities map[string]*PersonalityResult) (func(string, bool) (*PersonalityResult, error), *[]string) {
+	var pulled []string
+	return func(ref string, base bool) (*PersonalityResult, error) {
+		if base {
+			pulled = append(pulled, "base:"+ref)
+		} else {
+			pulled = append(pulled, ref)
+		}
+		p, ok := personalities[ref]
+		if !ok {
+			return nil, fmt.Errorf("personality %s not found", ref)
+		}
+		clone := *p
+		clone.Spec.Plugins = slices.Clone(p.Spec.Plugins)
+		if clone.Repository == "" {
+			clone.Repository = "example.com/personalities/" + ref
+		}
+		return &clone, nil
+	}, &pulled
+}
+
+func TestResolveExtendsTwoLevels(t *testing.T) {
+	pull, pulled := fakePersonalities(map[string]*PersonalityResult{
+		"team": {
+			ShortName: "team",
+			Extends:   "sre",
+			Spec: klausoci.Personality{Plugins: []klausoci.PluginReference{
+				{Repository: "example.com/plugins/team"},
+				{Repository: "example.com/plugins/kubectl", Tag: "v2.0.0"},
+			}},
+			Env: PersonalityEnv{EnvVars: map[string]string{"TEAM": "platform", "LOG_LEVEL": "debug"}},
+		},
+		"sre": {
+			ShortName: "sre",
+			Extends:   "base",
+			Spec: klausoci.Personality{Plugins: []klausoci.PluginReference{
+				{Repository: "example.com/plugins/kubectl", Tag: "v1.0.0"},
+				{Repository: "example.com/plugins/sre"},
+			}},
+			Env: PersonalityEnv{EnvVars: map[string]string{"LOG_LEVEL": "info"}, EnvForward: []string{"KUBECONFIG"}},
+		},
+		"base": {
+			ShortName: "base",
+			Spec: klausoci.Personality{
+				Toolchain: klausoci.ToolchainReference{Repository: "example.com/toolchains/go", Tag: "v1.0.0"},
+				Plugins:   []klausoci.PluginReference{{Repository: "example.com/plugins/base"}},
+			},
+			Warnings: []string{"plugin base: not found"},
+		},
+	})
+
+	got, err := resolveExtends("team", pull, nil)
+	if err != nil {
+		t.Fatalf("resolveExtends() error = %v", err)
+	}
+	if !slices.Equal(*pulled, []string{"team", "base:sre", "base:base"}) {
+		t.Errorf("pulled = %v", *pulled)
+	}
+
+	if got.Spec.Toolchain.Ref() != "example.com/toolchains/go:v1.0.0" {
+		t.Errorf("toolchain = %q, want the toolchain of the base", got.Spec.Toolchain.Ref())
+	}
+	var plugins []string
+	for _, p := range got.Spec.Plugins {
+		plugins = append(plugins, p.Repository+":"+p.Tag)
+	}
+	wantPlugins := []string{
+		"example.com/plugins/team:",
+		"example.com/plugins/kubectl:v2.0.0",
+		"example.com/plugins/sre:",
+		"example.com/plugins/base:",
+	}
+	if !slices.Equal(plugins, wantPlugins) {
+		t.Errorf("plugins = %v, want %v", plugins, wantPlugins)
+	}
+	if got.Env.EnvVars["LOG_LEVEL"] != "debug" || got.Env.EnvVars["TEAM"] != "platform" {
+		t.Errorf("env vars = %v, want the values of team", got.Env.EnvVars)
+	}
+	if !slices.Equal(got.Env.EnvForward, []string{"KUBECONFIG"}) {
+		t.Errorf("env forward = %v", got.Env.EnvForward)
+	}
+
+	wantWarnings := []string{
+		"plugin base: not found",
+		"personality team overrides env var LOG_LEVEL of base personality sre",
+		"personality team overrides plugin example.com/plugins/kubectl of base personality sre (v1.0.0 -> v2.0.0)",
+	}
+	if !slices.Equal(got.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q, want %q", got.Warnings, wantWarnings)
+	}
+}
+
+func TestResolveExtendsCycle(t *testing.T) {
+	pull, _ := fakePersonalities(map[string]*PersonalityResult{
+		"a": {ShortName: "a", Extends: "b"},
+		"b": {ShortName: "b", Extends: "c"},
+		"c": {ShortName: "c", Extends: "a"},
+	})
+
+	_, err := resolveExtends("a", pull, nil)
+	want := "cycle: example.com/personalities/a -> example.com/personalities/b -> example.com/personalities/c -> example.com/personalities/a"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Fatalf("error = %v, want the inheritance cycle", err)
+	}
+}
+
+func TestResolveExtendsSameShortName(t *testing.T) {
+	pull, pulled := fakePersonalities(map[string]*PersonalityResult{
+		"team.example.com/personalities/sre": {ShortName: "sre", Repository: "team.example.com/personalities/sre", Extends: "example.com/personalities/sre"},
+		"example.com/personalities/sre":      {ShortName: "sre", Repository: "example.com/personalities/sre"},
+	})
+
+	if _, err := resolveExtends("team.example.com/personalities/sre", pull, nil); err != nil {
+		t.Fatalf("resolveExtends() error = %v", err)
+	}
+	if want := []string{"team.example.com/personalities/sre", "base:example.com/personalities/sre"}; !slices.Equal(*pulled, want) {
+		t.Errorf("pulled = %v, want %v", *pulled, want)
+	}
+}
+
+func TestBaseDirIsKeyedOnRepository(t *testing.T) {
+	a := baseDir("/p", "example.com/personalities/sre")
+	b := baseDir("/p", "team.example.com/personalities/sre")
+	if a == b || a == filepath.Join("/p", "sre") {
+		t.Errorf("baseDir() = %q and %q, want distinct directories apart from the top-level one", a, b)
+	}
+}
+
+func TestResolveExtendsMissingBase(t *testing.T) {
+	pull, _ := fakePersonalities(map[string]*PersonalityResult{
+		"a": {ShortName: "a", Extends: "missing"},
+	})
+
+	if _, err := resolveExtends("a", pull, nil); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("error = %v, want the missing base", err)
+	}
+}
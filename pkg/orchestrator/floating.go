@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+// isFloatingTag reports whether ref names a version that can move: a ref
+// without tag or digest, which resolves to the latest version, or one with
+// the "latest" tag.
+func isFloatingTag(ref string) bool {
+	if strings.Contains(ref, "@") {
+		return false
+	}
+	_, tag := klausoci.SplitNameTag(ref)
+	return tag == "" || tag == "latest"
+}
+
+// FloatingRefWarnings returns a warning for each artifact cfg references by
+// a floating tag, since starting it again may run other versions. The
+// default image is only reported when the config sets it explicitly. Image
+// short names should be resolved first so that the full reference is
+// reported.
+func FloatingRefWarnings(cfg *config.Config) []string {
+	var warnings []string
+	floating := func(what, fix string) {
+		warnings = append(warnings, fmt.Sprintf("%s uses a floating tag, so reproducibility isn't guaranteed; %s", what, fix))
+	}
+	const pinInConfig = "pin a version tag or digest in the config"
+
+	if cfg.ImageExplicitlySet() && isFloatingTag(cfg.Image) {
+		floating("image "+cfg.Image, pinInConfig)
+	}
+	if cfg.Personality != "" && isFloatingTag(cfg.Personality) {
+		floating("personality "+cfg.Personality, pinInConfig)
+	}
+	for _, p := range cfg.Plugins {
+		if !p.IsEnabled() || !isFloatingTag(BuildRef(p)) {
+			continue
+		}
+		name := klausoci.ShortName(p.Repository)
+		floating("plugin "+name, fmt.Sprintf("lock it with 'klausctl plugin pin %s <version>'", name))
+	}
+	return warnings
+}
@@ -0,0 +1,82 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestIsFloatingTag(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:v1.2.0"},
+		{ref: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base@sha256:abc123"},
+		{ref: "localhost:5000/plugins/gs-base:v1.0.0"},
+		{ref: "go:v1.0.0"},
+		{ref: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base:latest", want: true},
+		{ref: "gsoci.azurecr.io/giantswarm/klaus-plugins/gs-base", want: true},
+		{ref: "localhost:5000/plugins/gs-base", want: true},
+		{ref: "go", want: true},
+	}
+	for _, tt := range tests {
+		if got := isFloatingTag(tt.ref); got != tt.want {
+			t.Errorf("isFloatingTag(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestFloatingRefWarnings(t *testing.T) {
+	disabled := false
+	cfg, err := config.Parse([]byte(`workspace: /tmp
+image: gsoci.azurecr.io/giantswarm/klaus-go:latest
+personality: gsoci.azurecr.io/giantswarm/klaus-personalities/sre:v1.0.0
+plugins:
+  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/floating
+  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/tagged
+    tag: v1.0.0
+  - repository: gsoci.azurecr.io/giantswarm/klaus-plugins/locked
+    digest: sha256:abc123
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Plugins = append(cfg.Plugins, config.Plugin{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugins/disabled", Enabled: &disabled})
+
+	warnings := FloatingRefWarnings(cfg)
+	if len(warnings) != 2 {
+		t.Fatalf("warnings = %q, want the image and the floating plugin", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "image gsoci.azurecr.io/giantswarm/klaus-go:latest") {
+		t.Errorf("warnings[0] = %q", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "klausctl plugin pin floating <version>") {
+		t.Errorf("warnings[1] = %q", warnings[1])
+	}
+}
+
+func TestFloatingRefWarningsDefaultImage(t *testing.T) {
+	cfg, err := config.Parse([]byte("workspace: /tmp\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warnings := FloatingRefWarnings(cfg); len(warnings) != 0 {
+		t.Errorf("warnings = %q, want none for the default image", warnings)
+	}
+}
+
+func TestFloatingRefWarningsResolvedShortName(t *testing.T) {
+	cfg, err := config.Parse([]byte("workspace: /tmp\nimage: go\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ResolveImageShortName(config.NewSourceResolver([]config.Source{{Name: "team", Registry: "team.io/x"}})); err != nil {
+		t.Fatal(err)
+	}
+	warnings := FloatingRefWarnings(cfg)
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "image team.io/x/klaus-toolchains/go uses a floating tag") {
+		t.Errorf("warnings = %q, want the resolved image", warnings)
+	}
+}
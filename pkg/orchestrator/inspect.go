@@ -0,0 +1,203 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// RedactedValue replaces secret values in an Inspection.
+const RedactedValue = "<redacted>"
+
+// Inspection is the full resolved state of an instance, as used for
+// debugging. Secret values are redacted.
+type Inspection struct {
+	Name string `json:"name"`
+	// Config is the instance's saved config with defaults applied.
+	Config map[string]any `json:"config"`
+	// State is the saved instance state; nil when the instance has not been
+	// started.
+	State       *instance.Instance    `json:"state,omitempty"`
+	Personality *InspectedPersonality `json:"personality,omitempty"`
+	// Plugins are the references of the enabled plugins, configured ones
+	// merged with those of the personality.
+	Plugins []string `json:"plugins,omitempty"`
+	Image   string   `json:"image"`
+	// ImageID is the ID of the image the container runs, as reported by the
+	// runtime.
+	ImageID   string                 `json:"imageID,omitempty"`
+	Container *runtime.ContainerInfo `json:"container,omitempty"`
+	Volumes   []runtime.Volume       `json:"volumes"`
+	Env       map[string]string      `json:"env"`
+}
+
+// InspectedPersonality is the personality an instance resolved to.
+type InspectedPersonality struct {
+	Ref string `json:"ref"`
+	// Dir is the local cache directory of the personality.
+	Dir string `json:"dir"`
+	// Toolchain is the personality's toolchain reference, if any.
+	Toolchain string `json:"toolchain,omitempty"`
+}
+
+// Inspect assembles the resolved state of the instance whose paths are given.
+// It only uses local state: the saved config and instance state, the
+// personality in the local cache, and the container runtime. Secrets are not
+// resolved; their env vars and files are listed with redacted values.
+// newRuntime creates the container runtime for a runtime name and host;
+// when it fails, or the container does not exist, Container is left empty.
+func Inspect(ctx context.Context, paths *config.Paths, name string, newRuntime func(name, host string) (runtime.Runtime, error)) (*Inspection, error) {
+	cfg, err := config.Load(paths.ConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config of instance %q: %w", name, err)
+	}
+	cfgMap, err := configMap(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Inspection{Name: name, Config: cfgMap, Image: cfg.Image}
+	personalityRef, rtName, rtHost := cfg.Personality, cfg.Runtime, cfg.RuntimeHost
+	if inst, err := instance.Load(paths); err == nil {
+		result.State = inst
+		result.Image = inst.Image
+		personalityRef, rtName, rtHost = inst.Personality, inst.Runtime, inst.RuntimeHost
+	}
+
+	plugins := cfg.Plugins
+	var personalityDir string
+	if personalityRef != "" {
+		dir := filepath.Join(paths.PersonalitiesDir, klausoci.ShortName(klausoci.RepositoryFromRef(personalityRef)))
+		result.Personality = &InspectedPersonality{Ref: personalityRef, Dir: dir}
+		if spec, err := LoadPersonalitySpec(dir); err == nil {
+			personalityDir = dir
+			plugins = MergePlugins(spec.Plugins, cfg.Plugins)
+			if spec.Toolchain.Repository != "" {
+				result.Personality.Toolchain = spec.Toolchain.Ref()
+			}
+		}
+	}
+	for _, p := range plugins {
+		if p.IsEnabled() {
+			result.Plugins = append(result.Plugins, BuildRef(p))
+		}
+	}
+
+	opts, err := BuildRunOptions(inspectRunConfig(cfg, plugins), paths, instance.ContainerName(name), result.Image, personalityDir)
+	if err != nil {
+		return nil, fmt.Errorf("building run options: %w", err)
+	}
+	result.Env = redactEnv(cfg, opts.EnvVars)
+	result.Volumes = append(opts.Volumes, secretFileVolumes(cfg, paths)...)
+
+	if rt, err := newRuntime(rtName, rtHost); err == nil {
+		if info, err := rt.Inspect(ctx, instance.ContainerName(name)); err == nil {
+			result.Container = info
+			result.ImageID = info.Image
+		}
+	}
+
+	return result, nil
+}
+
+// inspectRunConfig returns a copy of cfg for building run options without
+// resolving secrets. Managed MCP servers get placeholder entries so that the
+// mcp-config.json mount is included as it is at start.
+func inspectRunConfig(cfg *config.Config, plugins []config.Plugin) *config.Config {
+	c := *cfg
+	c.Plugins = plugins
+	c.SecretEnvVars = nil
+	c.SecretFiles = nil
+	c.EnvFile = ""
+	if len(c.McpServerRefs) > 0 {
+		servers := make(map[string]any, len(cfg.McpServers)+len(cfg.McpServerRefs))
+		for k, v := range cfg.McpServers {
+			servers[k] = v
+		}
+		for _, ref := range c.McpServerRefs {
+			if _, ok := servers[ref]; !ok {
+				servers[ref] = map[string]any{}
+			}
+		}
+		c.McpServers = servers
+	}
+	return &c
+}
+
+// EffectiveEnv returns the env vars BuildEnvVars resolves for cfg: the API
+// key, forwarded host vars, the env file, envVars and secretEnvVars, in that
+// order of precedence, plus the Git and Claude fallbacks. Unless
+// showSecrets is set, values that may hold a credential are redacted as in
+// Inspect.
+func EffectiveEnv(cfg *config.Config, paths *config.Paths, showSecrets bool) (map[string]string, error) {
+	env, err := BuildEnvVars(cfg, paths)
+	if err != nil {
+		return nil, err
+	}
+	if showSecrets {
+		return env, nil
+	}
+	return redactEnv(cfg, env), nil
+}
+
+// redactEnv adds the secret env vars and env file entries of cfg to env and
+// redacts every value that may hold a credential: secrets, env file values,
+// the API key, and variables forwarded from the host environment.
+func redactEnv(cfg *config.Config, env map[string]string) map[string]string {
+	if cfg.EnvFile != "" {
+		if fileEnv, err := config.LoadEnvFile(cfg.EnvFile); err == nil {
+			for k := range fileEnv {
+				if _, ok := cfg.EnvVars[k]; !ok {
+					env[k] = RedactedValue
+				}
+			}
+		}
+	}
+	for k := range cfg.SecretEnvVars {
+		env[k] = RedactedValue
+	}
+	for _, k := range append([]string{"ANTHROPIC_API_KEY"}, cfg.EnvForward...) {
+		if _, ok := env[k]; ok {
+			env[k] = RedactedValue
+		}
+	}
+	return env
+}
+
+// secretFileVolumes returns the mounts for cfg.SecretFiles as created at
+// start, sorted by container path.
+func secretFileVolumes(cfg *config.Config, paths *config.Paths) []runtime.Volume {
+	vols := make([]runtime.Volume, 0, len(cfg.SecretFiles))
+	for containerPath, secretName := range cfg.SecretFiles {
+		vols = append(vols, runtime.Volume{
+			HostPath:      filepath.Join(paths.RenderedDir, "secrets", secretName),
+			ContainerPath: containerPath,
+			ReadOnly:      true,
+		})
+	}
+	sort.Slice(vols, func(i, j int) bool {
+		return vols[i].ContainerPath < vols[j].ContainerPath
+	})
+	return vols
+}
+
+// configMap converts cfg to a generic map keyed as in the config file.
+func configMap(cfg *config.Config) (map[string]any, error) {
+	data, err := cfg.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("marshaling config: %w", err)
+	}
+	var m map[string]any
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing marshaled config: %w", err)
+	}
+	return m, nil
+}
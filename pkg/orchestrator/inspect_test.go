@@ -0,0 +1,141 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/instance"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+	"github.com/giantswarm/klausctl/pkg/secret"
+)
+
+func noRuntime(_, _ string) (runtime.Runtime, error) {
+	return nil, errors.New("no runtime")
+}
+
+func writeInstanceConfig(t *testing.T, paths *config.Paths, content string) {
+	t.Helper()
+	if err := config.EnsureDir(paths.InstanceDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.ConfigFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInspectRedactsSecrets(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-ant-secret")
+	t.Setenv("KLAUSCTL_TEST_FORWARDED", "host-token")
+	paths := testPaths(t).ForInstance("dev")
+	writeInstanceConfig(t, paths, `workspace: `+t.TempDir()+`
+envForward: [KLAUSCTL_TEST_FORWARDED]
+envVars:
+  PLAIN: visible
+secretEnvVars:
+  GITHUB_TOKEN: gh-token
+secretFiles:
+  /etc/secret/key: api-key
+`)
+
+	got, err := Inspect(context.Background(), paths, "dev", noRuntime)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+
+	for _, k := range []string{"ANTHROPIC_API_KEY", "KLAUSCTL_TEST_FORWARDED", "GITHUB_TOKEN"} {
+		if got.Env[k] != RedactedValue {
+			t.Errorf("env %s = %q, want it redacted", k, got.Env[k])
+		}
+	}
+	if got.Env["PLAIN"] != "visible" {
+		t.Errorf("env PLAIN = %q, want visible", got.Env["PLAIN"])
+	}
+
+	found := false
+	for _, v := range got.Volumes {
+		if v.ContainerPath == "/etc/secret/key" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("volumes %+v missing the secret file mount", got.Volumes)
+	}
+	if got.State != nil || got.Container != nil {
+		t.Errorf("state = %+v, container = %+v; want both empty for a stopped instance", got.State, got.Container)
+	}
+	if got.Config["workspace"] == nil {
+		t.Errorf("config = %v, want the saved config", got.Config)
+	}
+}
+
+func TestInspectUsesInstanceState(t *testing.T) {
+	paths := testPaths(t).ForInstance("dev")
+	writeInstanceConfig(t, paths, "workspace: "+t.TempDir()+"\n")
+	inst := &instance.Instance{Name: "dev", Runtime: "docker", Image: "example.com/klaus:v1.2.3", Port: 8080}
+	if err := inst.Save(paths); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Inspect(context.Background(), paths, "dev", noRuntime)
+	if err != nil {
+		t.Fatalf("Inspect: %v", err)
+	}
+	if got.State == nil || got.Image != "example.com/klaus:v1.2.3" {
+		t.Errorf("state = %+v, image = %q; want the saved state and its image", got.State, got.Image)
+	}
+}
+
+func TestInspectMissingConfig(t *testing.T) {
+	paths := testPaths(t).ForInstance("missing")
+	if _, err := Inspect(context.Background(), paths, "missing", noRuntime); err == nil {
+		t.Fatal("expected error for an instance without config")
+	}
+}
+
+func TestEffectiveEnvRedactsUnlessShowSecrets(t *testing.T) {
+	t.Setenv("KLAUSCTL_TEST_FORWARDED", "host-token")
+	paths := testPaths(t).ForInstance("dev")
+	if err := config.EnsureDir(filepath.Dir(paths.SecretsFile)); err != nil {
+		t.Fatal(err)
+	}
+	store, err := secret.Load(paths.SecretsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("gh-token", "ghp_secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		EnvForward:    []string{"KLAUSCTL_TEST_FORWARDED"},
+		EnvVars:       map[string]string{"PLAIN": "visible"},
+		SecretEnvVars: map[string]string{"GITHUB_TOKEN": "gh-token"},
+	}
+
+	env, err := EffectiveEnv(cfg, paths, false)
+	if err != nil {
+		t.Fatalf("EffectiveEnv() error = %v", err)
+	}
+	for _, k := range []string{"KLAUSCTL_TEST_FORWARDED", "GITHUB_TOKEN"} {
+		if env[k] != RedactedValue {
+			t.Errorf("env %s = %q, want it redacted", k, env[k])
+		}
+	}
+	if env["PLAIN"] != "visible" {
+		t.Errorf("env PLAIN = %q, want visible", env["PLAIN"])
+	}
+
+	env, err = EffectiveEnv(cfg, paths, true)
+	if err != nil {
+		t.Fatalf("EffectiveEnv(showSecrets) error = %v", err)
+	}
+	if env["GITHUB_TOKEN"] != "ghp_secret" || env["KLAUSCTL_TEST_FORWARDED"] != "host-token" {
+		t.Errorf("env = %v, want the secret values", env)
+	}
+}
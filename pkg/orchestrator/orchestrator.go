@@ -9,7 +9,11 @@ import (
 	"os"
 	"path/filepath"
 	goruntime "runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	klausoci "github.com/giantswarm/klaus-oci"
 
@@ -26,6 +30,10 @@ import (
 // env vars and volume mounts. personalityDir is the local path to the
 // resolved personality (empty when no personality is configured).
 func BuildRunOptions(cfg *config.Config, paths *config.Paths, containerName, image, personalityDir string) (runtime.RunOptions, error) {
+	if err := checkRemoteHost(cfg); err != nil {
+		return runtime.RunOptions{}, err
+	}
+
 	env, err := BuildEnvVars(cfg, paths)
 	if err != nil {
 		return runtime.RunOptions{}, err
@@ -37,22 +45,70 @@ func BuildRunOptions(cfg *config.Config, paths *config.Paths, containerName, ima
 	}
 
 	opts := runtime.RunOptions{
-		Name:    containerName,
-		Image:   image,
-		Detach:  true,
-		User:    fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid()),
-		EnvVars: env,
-		Volumes: volumes,
-		Ports:   map[int]int{cfg.Port: 8080},
-	}
-
-	if needsDockerInternalHost(cfg) {
+		Name:          containerName,
+		Image:         image,
+		Detach:        true,
+		Platform:      cfg.Platform,
+		User:          containerUser(cfg),
+		EnvVars:       env,
+		Volumes:       volumes,
+		Network:       cfg.Network,
+		DNS:           cfg.DNS,
+		ExtraHosts:    slices.Clone(cfg.ExtraHosts),
+		StopTimeout:   time.Duration(cfg.StopTimeout) * time.Second,
+		RestartPolicy: cfg.RestartPolicy,
+		ReadOnly:      cfg.SecurityOpts.ReadOnlyRootfs,
+		CapDrop:       cfg.SecurityOpts.CapDrop,
+		CapAdd:        cfg.SecurityOpts.CapAdd,
+	}
+	// The agent needs a writable /tmp, which a read-only root does not
+	// provide.
+	if cfg.SecurityOpts.ReadOnlyRootfs {
+		opts.Tmpfs = []string{"/tmp"}
+	}
+	// With host networking the agent listens on the host port directly and
+	// nothing can be published.
+	if cfg.Network != config.NetworkHost {
+		opts.Ports = map[int]int{cfg.Port: cfg.AgentPort()}
+		opts.HostIP = cfg.PublishAddress
+	}
+
+	if needsDockerInternalHost(cfg) && !hasExtraHost(opts.ExtraHosts, "host.docker.internal") {
 		opts.ExtraHosts = append(opts.ExtraHosts, "host.docker.internal:host-gateway")
 	}
 
 	return opts, nil
 }
 
+// checkRemoteHost refuses to run cfg on a remote runtime host unless it is
+// set up for one: the bind-mounted local paths must be shared with that
+// host, and the agent port must be published on an interface reachable
+// from here rather than the host's loopback.
+func checkRemoteHost(cfg *config.Config) error {
+	host := runtime.ResolveHost(cfg.Runtime, cfg.RuntimeHost)
+	if runtime.RemoteHostName(host) == "" {
+		return nil
+	}
+	if !cfg.RuntimeHostSharedPaths {
+		return fmt.Errorf("runtime host %s is remote: the rendered config, plugins, personality and secrets are bind-mounted from local paths that must exist at the same paths on that host; set runtimeHostSharedPaths once they are shared", host)
+	}
+	if cfg.Network != config.NetworkHost && cfg.PublishAddress == "" {
+		return fmt.Errorf("runtime host %s is remote: the agent port is published on its loopback interface and cannot be reached; set publishAddress, e.g. 0.0.0.0", host)
+	}
+	return nil
+}
+
+// hasExtraHost reports whether hosts maps name, so a configured mapping is
+// not overridden.
+func hasExtraHost(hosts []string, name string) bool {
+	for _, h := range hosts {
+		if hostName, _, _ := strings.Cut(h, ":"); hostName == name {
+			return true
+		}
+	}
+	return false
+}
+
 // needsDockerInternalHost reports whether the container needs an explicit
 // host.docker.internal mapping. On Linux, Docker does not provide this
 // automatically (unlike Docker Desktop on macOS/Windows), so we add
@@ -75,6 +131,21 @@ func needsDockerInternalHost(cfg *config.Config) bool {
 	return false
 }
 
+// containerUser returns the --user value for cfg.RunAsUser. An empty result
+// omits the flag so the user declared by the image applies.
+func containerUser(cfg *config.Config) string {
+	switch cfg.RunAsUser {
+	case "":
+		return fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	case config.RunAsUserRoot:
+		return "0:0"
+	case config.RunAsUserImage:
+		return ""
+	default:
+		return cfg.RunAsUser
+	}
+}
+
 // BuildEnvVars constructs all container environment variables from config.
 // Claude, Git, and Agent settings are now rendered into the container config
 // YAML file (see renderer.BuildContainerConfig). This function only sets
@@ -82,10 +153,12 @@ func needsDockerInternalHost(cfg *config.Config) bool {
 func BuildEnvVars(cfg *config.Config, paths *config.Paths) (map[string]string, error) {
 	env := make(map[string]string)
 
-	env["PORT"] = "8080"
+	env["PORT"] = strconv.Itoa(cfg.AgentPort())
 
-	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
-		env["ANTHROPIC_API_KEY"] = key
+	if cfg.ForwardsAnthropicKey() {
+		if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+			env["ANTHROPIC_API_KEY"] = key
+		}
 	}
 
 	for _, name := range cfg.EnvForward {
@@ -94,6 +167,16 @@ func BuildEnvVars(cfg *config.Config, paths *config.Paths) (map[string]string, e
 		}
 	}
 
+	if cfg.EnvFile != "" {
+		fileEnv, err := config.LoadEnvFile(cfg.EnvFile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileEnv {
+			env[k] = v
+		}
+	}
+
 	for k, v := range cfg.EnvVars {
 		env[k] = v
 	}
@@ -187,10 +270,17 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 	if cfg.WorktreePath != "" {
 		mountPath = cfg.WorktreePath
 	}
-	vols = append(vols, runtime.Volume{
+	workspaceVol := runtime.Volume{
 		HostPath:      mountPath,
 		ContainerPath: "/workspace",
-	})
+	}
+	switch cfg.WorkspaceMode {
+	case config.WorkspaceModeReadOnly:
+		workspaceVol.ReadOnly = true
+	case config.WorkspaceModeCached, config.WorkspaceModeDelegated:
+		workspaceVol.Consistency = cfg.WorkspaceMode
+	}
+	vols = append(vols, workspaceVol)
 	env["CLAUDE_WORKSPACE"] = "/workspace" //nolint:goconst
 
 	// Mount the rendered container config YAML. The container reads this
@@ -213,7 +303,7 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 		env["CLAUDE_MCP_CONFIG"] = "/etc/klaus/mcp-config.json" //nolint:goconst
 	}
 
-	if len(cfg.Hooks) > 0 {
+	if renderer.HasSettings(cfg) {
 		settingsPath := filepath.Join(paths.RenderedDir, "settings.json")
 		vols = append(vols, runtime.Volume{
 			HostPath:      settingsPath,
@@ -242,7 +332,11 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 		})
 	}
 
-	addDirs := buildAddDirs(cfg)
+	addDirs, addDirVols, err := buildAddDirs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	vols = append(vols, addDirVols...)
 	if len(addDirs) > 0 {
 		env["CLAUDE_ADD_DIRS"] = strings.Join(addDirs, ",")
 		if cfg.Claude.LoadAdditionalDirsMemory == nil || *cfg.Claude.LoadAdditionalDirsMemory {
@@ -265,8 +359,11 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 	}
 
 	for _, p := range cfg.Plugins {
+		if !p.IsEnabled() {
+			continue
+		}
 		shortName := klausoci.ShortName(p.Repository)
-		hostPath := filepath.Join(paths.PluginsDir, shortName)
+		hostPath := ResolvePluginDir(filepath.Join(paths.PluginsDir, shortName))
 		vols = append(vols, runtime.Volume{
 			HostPath:      hostPath,
 			ContainerPath: "/var/lib/klaus/plugins/" + shortName,
@@ -274,6 +371,14 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 		})
 	}
 
+	for _, name := range inlinePluginNames(cfg) {
+		vols = append(vols, runtime.Volume{
+			HostPath:      renderer.InlinePluginDir(paths, name),
+			ContainerPath: "/var/lib/klaus/plugins/" + name,
+			ReadOnly:      true,
+		})
+	}
+
 	gitconfigVol, err := buildGitConfigVolume(cfg, paths, env)
 	if err != nil {
 		return nil, err
@@ -288,6 +393,10 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 	}
 	vols = append(vols, gpgVols...)
 
+	if sshVol := buildSSHAgentVolume(cfg, env); sshVol != nil {
+		vols = append(vols, *sshVol)
+	}
+
 	secretVols, err := resolveSecretFiles(cfg, paths)
 	if err != nil {
 		return nil, err
@@ -313,22 +422,72 @@ func BuildVolumes(cfg *config.Config, paths *config.Paths, env map[string]string
 	return vols, nil
 }
 
-func buildAddDirs(cfg *config.Config) []string {
+// hostAddDirPrefix marks a claude.addDirs entry as a host directory to
+// mount, e.g. "host:~/skills".
+const hostAddDirPrefix = "host:"
+
+// buildAddDirs returns the container paths for CLAUDE_ADD_DIRS and the
+// mounts they need. Host directories of claude.addDirs, marked with
+// hostAddDirPrefix, are mounted read-only at the same path, so the agent
+// sees them where the config names them; they must exist and be absolute
+// or ~/ paths. Other entries are container paths and passed unchanged.
+func buildAddDirs(cfg *config.Config) ([]string, []runtime.Volume, error) {
 	var dirs []string
+	var vols []runtime.Volume
 	if renderer.HasExtensions(cfg) {
 		dirs = append(dirs, "/etc/klaus/extensions")
 	}
-	dirs = append(dirs, cfg.Claude.AddDirs...)
-	return dirs
+	for _, entry := range cfg.Claude.AddDirs {
+		dir, ok := strings.CutPrefix(entry, hostAddDirPrefix)
+		if !ok {
+			dirs = append(dirs, entry)
+			continue
+		}
+		if !filepath.IsAbs(dir) && dir != "~" && !strings.HasPrefix(dir, "~/") {
+			return nil, nil, fmt.Errorf("claude.addDirs: host directory %s must be an absolute or ~/ path", dir)
+		}
+		hostPath := filepath.Clean(config.ExpandPath(dir))
+		info, err := os.Stat(hostPath)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil, nil, fmt.Errorf("claude.addDirs: directory %s does not exist", dir)
+			}
+			return nil, nil, fmt.Errorf("claude.addDirs: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, nil, fmt.Errorf("claude.addDirs: %s is not a directory", dir)
+		}
+		vols = append(vols, runtime.Volume{
+			HostPath:      hostPath,
+			ContainerPath: hostPath,
+			ReadOnly:      true,
+		})
+		dirs = append(dirs, hostPath)
+	}
+	return dirs, vols, nil
 }
 
 func buildPluginDirs(cfg *config.Config) []string {
 	var dirs []string
 	dirs = append(dirs, cfg.Claude.PluginDirs...)
 	dirs = append(dirs, PluginDirs(cfg.Plugins)...)
+	for _, name := range inlinePluginNames(cfg) {
+		dirs = append(dirs, "/var/lib/klaus/plugins/"+name)
+	}
 	return dirs
 }
 
+// inlinePluginNames returns the names of the inline plugins in sorted
+// order, so mounts and CLAUDE_PLUGIN_DIRS are deterministic.
+func inlinePluginNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.InlinePlugins))
+	for name := range cfg.InlinePlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func setGitEnvVars(env map[string]string, git *config.GitConfig) {
 	if git.AuthorName != "" {
 		env["GIT_AUTHOR_NAME"] = git.AuthorName
@@ -406,10 +565,57 @@ func buildGitConfigVolume(cfg *config.Config, paths *config.Paths, env map[strin
 	}, nil
 }
 
+// secretRefKey is the key of an McpServers value that references a secret,
+// e.g. {"secretRef": "github-token"}.
+const secretRefKey = "secretRef"
+
 // ResolveSecretRefs resolves all secret-related references in the config:
-// McpServerRefs are merged into McpServers with optional Bearer tokens.
+// {"secretRef": "<name>"} values in McpServers are replaced by the named
+// secret, and McpServerRefs are merged into McpServers with an optional
+// authentication header built from the server's secret (see
+// McpServerDef.AuthHeader) or, without a secret, a Bearer token from a
+// prior OAuth login. cfg.McpServers is replaced rather than modified, so a
+// map shared with another config keeps its references.
 // This must be called before rendering so that the mcp-config.json is complete.
 func ResolveSecretRefs(cfg *config.Config, paths *config.Paths) error {
+	var secretStore *secret.Store
+	loadSecrets := func() (*secret.Store, error) {
+		if secretStore == nil {
+			store, err := secret.Load(paths.SecretsFile)
+			if err != nil {
+				return nil, fmt.Errorf("loading secrets for MCP servers: %w", err)
+			}
+			secretStore = store
+		}
+		return secretStore, nil
+	}
+
+	lookup := func(path, name string) (string, error) {
+		if err := secret.ValidateName(name); err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		store, err := loadSecrets()
+		if err != nil {
+			return "", err
+		}
+		value, err := store.Get(name)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", path, err)
+		}
+		return value, nil
+	}
+	if len(cfg.McpServers) > 0 {
+		servers := make(map[string]any, len(cfg.McpServers)+len(cfg.McpServerRefs))
+		for name, server := range cfg.McpServers {
+			resolved, err := resolveInlineSecretRefs(server, "mcpServers."+name, lookup)
+			if err != nil {
+				return err
+			}
+			servers[name] = resolved
+		}
+		cfg.McpServers = servers
+	}
+
 	if len(cfg.McpServerRefs) == 0 {
 		return nil
 	}
@@ -419,13 +625,15 @@ func ResolveSecretRefs(cfg *config.Config, paths *config.Paths) error {
 		return fmt.Errorf("loading managed MCP servers: %w", err)
 	}
 
-	var secretStore *secret.Store
 	tokenStore := oauth.NewTokenStore(paths.TokensDir)
 	for _, ref := range cfg.McpServerRefs {
 		def, err := mcpStore.Get(ref)
 		if err != nil {
 			return fmt.Errorf("resolving mcpServerRef %q: %w", ref, err)
 		}
+		if err := def.Validate(); err != nil {
+			return fmt.Errorf("mcpServerRef %q: %w", ref, err)
+		}
 
 		entry := map[string]any{
 			"url":  def.URL,
@@ -433,18 +641,17 @@ func ResolveSecretRefs(cfg *config.Config, paths *config.Paths) error {
 		}
 
 		if def.Secret != "" {
-			if secretStore == nil {
-				secretStore, err = secret.Load(paths.SecretsFile)
-				if err != nil {
-					return fmt.Errorf("loading secrets for MCP server refs: %w", err)
-				}
+			store, err := loadSecrets()
+			if err != nil {
+				return err
 			}
-			token, err := secretStore.Get(def.Secret)
+			token, err := store.Get(def.Secret)
 			if err != nil {
 				return fmt.Errorf("resolving secret %q for MCP server %q: %w", def.Secret, ref, err)
 			}
+			name, value := def.AuthHeader(token)
 			entry["headers"] = map[string]string{
-				"Authorization": "Bearer " + token,
+				name: value,
 			}
 		} else if st := tokenStore.GetValidToken(def.URL); st != nil {
 			entry["headers"] = map[string]string{
@@ -461,6 +668,111 @@ func ResolveSecretRefs(cfg *config.Config, paths *config.Paths) error {
 	return nil
 }
 
+// CheckSecrets verifies that every secret cfg references is in the secret
+// store: the secrets of secretEnvVars and secretFiles, the inline
+// {"secretRef": "<name>"} values of mcpServers, and the secrets of the
+// managed MCP servers in mcpServerRefs. Unlike starting an instance, it
+// does not resolve the secrets, and it reports all missing ones at once.
+// The store is only loaded when cfg references a secret.
+func CheckSecrets(cfg *config.Config, paths *config.Paths) error {
+	type secretRef struct{ field, name string }
+	var (
+		refs    []secretRef
+		missing []string
+	)
+	reference := func(field, name string) {
+		refs = append(refs, secretRef{field, name})
+	}
+	for envName, secretName := range cfg.SecretEnvVars {
+		reference("secretEnvVars["+envName+"]", secretName)
+	}
+	for containerPath, secretName := range cfg.SecretFiles {
+		reference("secretFiles["+containerPath+"]", secretName)
+	}
+	for name, server := range cfg.McpServers {
+		if _, err := resolveInlineSecretRefs(server, "mcpServers."+name, func(path, name string) (string, error) {
+			reference(path, name)
+			return "", nil
+		}); err != nil {
+			missing = append(missing, err.Error())
+		}
+	}
+	if len(cfg.McpServerRefs) > 0 {
+		mcpStore, err := mcpserverstore.Load(paths.McpServersFile)
+		if err != nil {
+			return fmt.Errorf("loading managed MCP servers: %w", err)
+		}
+		for _, ref := range cfg.McpServerRefs {
+			def, err := mcpStore.Get(ref)
+			if err != nil {
+				missing = append(missing, fmt.Sprintf("mcpServerRefs[%s]: %v", ref, err))
+				continue
+			}
+			if def.Secret != "" {
+				reference("mcpServerRefs["+ref+"]", def.Secret)
+			}
+		}
+	}
+
+	if len(refs) > 0 {
+		store, err := secret.Load(paths.SecretsFile)
+		if err != nil {
+			return fmt.Errorf("loading secrets: %w", err)
+		}
+		for _, ref := range refs {
+			if err := secret.ValidateName(ref.name); err != nil {
+				missing = append(missing, fmt.Sprintf("%s: %v", ref.field, err))
+			} else if !store.Has(ref.name) {
+				missing = append(missing, fmt.Sprintf("%s: secret %q not found", ref.field, ref.name))
+			}
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("%d unresolved secret references:\n  %s", len(missing), strings.Join(missing, "\n  "))
+}
+
+// resolveInlineSecretRefs returns v with every {"secretRef": "<name>"}
+// value replaced by the value lookup returns for the named secret. Maps and
+// slices are copied rather than modified, so v keeps its references. path
+// locates v in error messages and is passed to lookup.
+func resolveInlineSecretRefs(v any, path string, lookup func(path, name string) (string, error)) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v[secretRefKey]; ok && len(v) == 1 {
+			name, ok := ref.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: %s must be a secret name", path, secretRefKey)
+			}
+			return lookup(path, name)
+		}
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			resolved, err := resolveInlineSecretRefs(child, path+"."+k, lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			resolved, err := resolveInlineSecretRefs(child, fmt.Sprintf("%s[%d]", path, i), lookup)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
 // resolveSecretFiles writes secret values to rendered/secrets/ and returns
 // the volume mounts for them.
 func resolveSecretFiles(cfg *config.Config, paths *config.Paths) ([]runtime.Volume, error) {
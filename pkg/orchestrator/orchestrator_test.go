@@ -1,15 +1,18 @@
 package orchestrator
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	goruntime "runtime"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/giantswarm/klausctl/pkg/config"
 	"github.com/giantswarm/klausctl/pkg/oauth"
+	"github.com/giantswarm/klausctl/pkg/renderer"
 	"github.com/giantswarm/klausctl/pkg/runtime"
 )
 
@@ -64,6 +67,38 @@ func TestBuildEnvVars_ExplicitEnvVars(t *testing.T) {
 	}
 }
 
+func TestBuildEnvVars_EnvFile(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("FOO=from-file\nONLY_FILE=yes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		EnvFile: envFile,
+		EnvVars: map[string]string{"FOO": "explicit"},
+	}
+
+	env, err := BuildEnvVars(cfg, testPaths(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if env["FOO"] != "explicit" {
+		t.Errorf("expected envVars to take precedence, got FOO=%q", env["FOO"])
+	}
+	if env["ONLY_FILE"] != "yes" {
+		t.Errorf("expected ONLY_FILE=yes, got %q", env["ONLY_FILE"])
+	}
+}
+
+func TestBuildEnvVars_EnvFileMissing(t *testing.T) {
+	cfg := &config.Config{EnvFile: filepath.Join(t.TempDir(), "missing.env")}
+
+	_, err := BuildEnvVars(cfg, testPaths(t))
+	if err == nil || !strings.Contains(err.Error(), "env file not found") {
+		t.Errorf("expected env file not found error, got %v", err)
+	}
+}
+
 func TestBuildEnvVars_ClaudeModel(t *testing.T) {
 	cfg := &config.Config{
 		Claude: config.ClaudeConfig{
@@ -173,6 +208,39 @@ func TestBuildVolumes_WorkspaceMount(t *testing.T) {
 	}
 }
 
+func TestBuildVolumes_WorkspaceMode(t *testing.T) {
+	tests := []struct {
+		mode            string
+		wantReadOnly    bool
+		wantConsistency string
+	}{
+		{mode: ""},
+		{mode: "rw"},
+		{mode: "ro", wantReadOnly: true},
+		{mode: "cached", wantConsistency: "cached"},
+		{mode: "delegated", wantConsistency: "delegated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &config.Config{Workspace: t.TempDir(), WorkspaceMode: tt.mode}
+			vols, err := BuildVolumes(cfg, testPaths(t), make(map[string]string), "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, v := range vols {
+				if v.ContainerPath != "/workspace" {
+					continue
+				}
+				if v.ReadOnly != tt.wantReadOnly || v.Consistency != tt.wantConsistency {
+					t.Errorf("workspace mount = %+v, want readOnly %v, consistency %q", v, tt.wantReadOnly, tt.wantConsistency)
+				}
+				return
+			}
+			t.Error("expected /workspace volume mount")
+		})
+	}
+}
+
 func TestBuildVolumes_ContainerConfigMount(t *testing.T) {
 	cfg := &config.Config{Workspace: t.TempDir()}
 	paths := testPaths(t)
@@ -291,6 +359,244 @@ func TestBuildRunOptions_Structure(t *testing.T) {
 	}
 }
 
+func TestBuildRunOptions_HostNetwork(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		Port:      9090,
+		Network:   "host",
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.Network != "host" {
+		t.Errorf("Network = %q, want host", opts.Network)
+	}
+	if len(opts.Ports) != 0 {
+		t.Errorf("expected no port mapping with host network, got %v", opts.Ports)
+	}
+	if opts.EnvVars["PORT"] != "9090" {
+		t.Errorf("expected agent to listen on host port 9090, got PORT=%q", opts.EnvVars["PORT"])
+	}
+}
+
+func TestBuildRunOptions_ContainerPort(t *testing.T) {
+	cfg := &config.Config{
+		Workspace:     t.TempDir(),
+		Port:          9090,
+		ContainerPort: 3000,
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(opts.Ports) != 1 || opts.Ports[9090] != 3000 {
+		t.Errorf("Ports = %v, want map[9090:3000]", opts.Ports)
+	}
+	if opts.EnvVars["PORT"] != "3000" {
+		t.Errorf("expected agent to listen on container port 3000, got PORT=%q", opts.EnvVars["PORT"])
+	}
+}
+
+func TestBuildRunOptions_RuntimeHost(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		dockerHost  string
+		shared      bool
+		publishAddr string
+		network     string
+		wantHostIP  string
+		wantErr     string
+	}{
+		{name: "local", wantHostIP: ""},
+		{name: "local publish address", publishAddr: "0.0.0.0", wantHostIP: "0.0.0.0"},
+		{name: "unix socket", host: "unix:///run/docker.sock", wantHostIP: ""},
+		{name: "ssh", host: "ssh://me@build-box", shared: true, publishAddr: "0.0.0.0", wantHostIP: "0.0.0.0"},
+		{name: "DOCKER_HOST", dockerHost: "tcp://10.0.0.5:2376", shared: true, publishAddr: "10.0.0.5", wantHostIP: "10.0.0.5"},
+		{name: "ssh without shared paths", host: "ssh://me@build-box", publishAddr: "0.0.0.0", wantErr: "runtimeHostSharedPaths"},
+		{name: "DOCKER_HOST without shared paths", dockerHost: "tcp://10.0.0.5:2376", wantErr: "runtimeHostSharedPaths"},
+		{name: "ssh without publish address", host: "ssh://me@build-box", shared: true, wantErr: "publishAddress"},
+		{name: "ssh host network", host: "ssh://me@build-box", shared: true, network: config.NetworkHost, wantHostIP: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(runtime.EnvDockerHost, tt.dockerHost)
+			cfg := &config.Config{
+				Workspace:              t.TempDir(),
+				Port:                   9090,
+				RuntimeHost:            tt.host,
+				RuntimeHostSharedPaths: tt.shared,
+				PublishAddress:         tt.publishAddr,
+				Network:                tt.network,
+			}
+
+			opts, err := BuildRunOptions(cfg, testPaths(t), "test-container", "test-image:latest", "")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.HostIP != tt.wantHostIP {
+				t.Errorf("HostIP = %q, want %q", opts.HostIP, tt.wantHostIP)
+			}
+		})
+	}
+}
+
+func TestBuildRunOptions_RunAsUser(t *testing.T) {
+	tests := []struct {
+		runAsUser string
+		want      string
+	}{
+		{"", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())},
+		{"root", "0:0"},
+		{"0:0", "0:0"},
+		{"1000:1000", "1000:1000"},
+		{"image", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.runAsUser, func(t *testing.T) {
+			cfg := &config.Config{Workspace: t.TempDir(), Port: 8080, RunAsUser: tt.runAsUser}
+
+			opts, err := BuildRunOptions(cfg, testPaths(t), "test-container", "test-image:latest", "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if opts.User != tt.want {
+				t.Errorf("User = %q, want %q", opts.User, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunOptions_StopTimeout(t *testing.T) {
+	cfg := &config.Config{
+		Workspace:   t.TempDir(),
+		Port:        8080,
+		StopTimeout: 30,
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.StopTimeout != 30*time.Second {
+		t.Errorf("StopTimeout = %v, want 30s", opts.StopTimeout)
+	}
+}
+
+func TestBuildRunOptions_RestartPolicy(t *testing.T) {
+	cfg := &config.Config{
+		Workspace:     t.TempDir(),
+		Port:          8080,
+		RestartPolicy: "unless-stopped",
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.RestartPolicy != "unless-stopped" {
+		t.Errorf("RestartPolicy = %q, want unless-stopped", opts.RestartPolicy)
+	}
+}
+
+func TestBuildRunOptions_Platform(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		Port:      8080,
+		Platform:  "linux/amd64",
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opts.Platform != "linux/amd64" {
+		t.Errorf("Platform = %q, want linux/amd64", opts.Platform)
+	}
+}
+
+func TestBuildRunOptions_SecurityOpts(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		Port:      8080,
+		SecurityOpts: config.SecurityOpts{
+			ReadOnlyRootfs: true,
+			CapDrop:        []string{"ALL"},
+			CapAdd:         []string{"NET_BIND_SERVICE"},
+		},
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !opts.ReadOnly {
+		t.Error("ReadOnly = false, want true")
+	}
+	if !slices.Equal(opts.Tmpfs, []string{"/tmp"}) {
+		t.Errorf("Tmpfs = %v, want [/tmp]", opts.Tmpfs)
+	}
+	if !slices.Equal(opts.CapDrop, []string{"ALL"}) || !slices.Equal(opts.CapAdd, []string{"NET_BIND_SERVICE"}) {
+		t.Errorf("CapDrop = %v, CapAdd = %v", opts.CapDrop, opts.CapAdd)
+	}
+
+	cfg.SecurityOpts = config.SecurityOpts{}
+	opts, err = BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.ReadOnly || len(opts.Tmpfs) > 0 {
+		t.Errorf("without securityOpts: ReadOnly = %v, Tmpfs = %v", opts.ReadOnly, opts.Tmpfs)
+	}
+}
+
+func TestBuildRunOptions_DNSAndExtraHosts(t *testing.T) {
+	cfg := &config.Config{
+		Workspace:  t.TempDir(),
+		Port:       8080,
+		DNS:        []string{"10.0.0.53"},
+		ExtraHosts: []string{"git.corp:10.0.0.7", "host.docker.internal:10.0.0.1"},
+		McpServers: map[string]any{
+			"local": map[string]any{"url": "http://host.docker.internal:9000/mcp"},
+		},
+	}
+	paths := testPaths(t)
+
+	opts, err := BuildRunOptions(cfg, paths, "test-container", "test-image:latest", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(opts.DNS, []string{"10.0.0.53"}) {
+		t.Errorf("DNS = %v, want [10.0.0.53]", opts.DNS)
+	}
+	// The configured host.docker.internal mapping replaces the automatic one.
+	if !slices.Equal(opts.ExtraHosts, cfg.ExtraHosts) {
+		t.Errorf("ExtraHosts = %v, want %v", opts.ExtraHosts, cfg.ExtraHosts)
+	}
+}
+
 func TestBuildVolumes_PersonalitySOULMount(t *testing.T) {
 	cfg := &config.Config{Workspace: t.TempDir()}
 	paths := testPaths(t)
@@ -354,6 +660,39 @@ func TestBuildVolumes_SettingsFileFromClaudeConfig(t *testing.T) {
 	}
 }
 
+func TestBuildVolumes_InlineSettingsMount(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		Claude:    config.ClaudeConfig{Settings: map[string]any{"includeCoAuthoredBy": false}},
+	}
+	paths := testPaths(t)
+	env := make(map[string]string)
+
+	vols, err := BuildVolumes(cfg, paths, env, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, v := range vols {
+		if v.ContainerPath == "/etc/klaus/settings.json" {
+			found = true
+			if v.HostPath != filepath.Join(paths.RenderedDir, "settings.json") {
+				t.Errorf("settings mount HostPath = %q", v.HostPath)
+			}
+			if !v.ReadOnly {
+				t.Error("expected settings mount to be read-only")
+			}
+		}
+	}
+	if !found {
+		t.Error("expected /etc/klaus/settings.json volume mount for inline settings")
+	}
+	if env["CLAUDE_SETTINGS_FILE"] != "/etc/klaus/settings.json" {
+		t.Errorf("CLAUDE_SETTINGS_FILE = %q, want /etc/klaus/settings.json", env["CLAUDE_SETTINGS_FILE"])
+	}
+}
+
 func TestBuildVolumes_Plugins(t *testing.T) {
 	cfg := &config.Config{
 		Workspace: t.TempDir(),
@@ -388,6 +727,121 @@ func TestBuildVolumes_Plugins(t *testing.T) {
 	}
 }
 
+func TestBuildVolumes_AddDirsMounted(t *testing.T) {
+	hostDir := t.TempDir()
+	cfg := &config.Config{Workspace: t.TempDir()}
+	cfg.Claude.AddDirs = []string{"host:" + hostDir, "/workspace/skills", "/opt/skills", "agents"}
+	paths := testPaths(t)
+	env := make(map[string]string)
+
+	vols, err := BuildVolumes(cfg, paths, env, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mounts := 0
+	for _, v := range vols {
+		if v.HostPath == hostDir {
+			mounts++
+			if v.ContainerPath != hostDir || !v.ReadOnly {
+				t.Errorf("addDir mount = %+v, want read-only at %s", v, hostDir)
+			}
+		}
+		if v.ContainerPath == "/workspace/skills" || v.ContainerPath == "/opt/skills" || v.ContainerPath == "agents" {
+			t.Errorf("container path %s mounted", v.ContainerPath)
+		}
+	}
+	if mounts != 1 {
+		t.Errorf("expected 1 mount of %s, got %d", hostDir, mounts)
+	}
+	if want := hostDir + ",/workspace/skills,/opt/skills,agents"; env["CLAUDE_ADD_DIRS"] != want {
+		t.Errorf("CLAUDE_ADD_DIRS = %q, want %q", env["CLAUDE_ADD_DIRS"], want)
+	}
+}
+
+func TestBuildVolumes_AddDirsMustExist(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dir := range []string{filepath.Join(t.TempDir(), "missing"), file, "relative"} {
+		cfg := &config.Config{Workspace: t.TempDir()}
+		cfg.Claude.AddDirs = []string{"host:" + dir}
+		_, err := BuildVolumes(cfg, testPaths(t), make(map[string]string), "")
+		if err == nil || !strings.Contains(err.Error(), "claude.addDirs") {
+			t.Errorf("BuildVolumes(addDirs=%s) error = %v, want addDirs error", dir, err)
+		}
+	}
+}
+
+func TestBuildVolumes_DisabledPluginNotMounted(t *testing.T) {
+	disabled := false
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		Plugins: []config.Plugin{
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugin-test"},
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugin-off", Enabled: &disabled},
+		},
+	}
+	paths := testPaths(t)
+	env := make(map[string]string)
+
+	vols, err := BuildVolumes(cfg, paths, env, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, v := range vols {
+		if v.ContainerPath == "/var/lib/klaus/plugins/klaus-plugin-off" {
+			t.Error("disabled plugin is mounted")
+		}
+	}
+	if env["CLAUDE_PLUGIN_DIRS"] != "/var/lib/klaus/plugins/klaus-plugin-test" {
+		t.Errorf("CLAUDE_PLUGIN_DIRS = %q, want only the enabled plugin", env["CLAUDE_PLUGIN_DIRS"])
+	}
+}
+
+func TestBuildVolumes_InlinePlugins(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		Plugins: []config.Plugin{
+			{Repository: "gsoci.azurecr.io/giantswarm/klaus-plugin-test"},
+		},
+		InlinePlugins: map[string]config.InlinePlugin{
+			"local": {Commands: map[string]string{"ship": "Ship it."}},
+		},
+	}
+	paths := testPaths(t)
+	env := make(map[string]string)
+
+	vols, err := BuildVolumes(cfg, paths, env, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, v := range vols {
+		if v.ContainerPath == "/var/lib/klaus/plugins/local" {
+			found = true
+			if v.HostPath != renderer.InlinePluginDir(paths, "local") {
+				t.Errorf("inline plugin HostPath = %q, want %q", v.HostPath, renderer.InlinePluginDir(paths, "local"))
+			}
+			if !v.ReadOnly {
+				t.Error("expected inline plugin mount to be read-only")
+			}
+		}
+	}
+	if !found {
+		t.Error("expected inline plugin volume mount at /var/lib/klaus/plugins/local")
+	}
+
+	want := "/var/lib/klaus/plugins/klaus-plugin-test,/var/lib/klaus/plugins/local"
+	if env["CLAUDE_PLUGIN_DIRS"] != want {
+		t.Errorf("CLAUDE_PLUGIN_DIRS = %q, want %q", env["CLAUDE_PLUGIN_DIRS"], want)
+	}
+}
+
 // testPaths returns config paths rooted in a temp directory.
 func testPaths(t *testing.T) *config.Paths {
 	t.Helper()
@@ -432,6 +886,28 @@ func TestBuildEnvVars_SecretEnvVars(t *testing.T) {
 	}
 }
 
+func TestBuildEnvVars_ForwardAnthropicKeyDisabled(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "sk-host-key")
+	paths := testPaths(t)
+
+	env, err := BuildEnvVars(&config.Config{}, paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if env["ANTHROPIC_API_KEY"] != "sk-host-key" {
+		t.Errorf("ANTHROPIC_API_KEY = %q, want the host key forwarded by default", env["ANTHROPIC_API_KEY"])
+	}
+
+	disabled := false
+	env, err = BuildEnvVars(&config.Config{ForwardAnthropicKey: &disabled}, paths)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v, ok := env["ANTHROPIC_API_KEY"]; ok {
+		t.Errorf("ANTHROPIC_API_KEY = %q, want it absent with forwardAnthropicKey: false", v)
+	}
+}
+
 func TestBuildEnvVars_SecretEnvVars_MissingSecret(t *testing.T) {
 	paths := testPaths(t)
 
@@ -554,6 +1030,67 @@ func TestResolveSecretRefs(t *testing.T) {
 	}
 }
 
+func TestResolveSecretRefs_CustomHeader(t *testing.T) {
+	paths := testPaths(t)
+
+	if err := config.EnsureDir(paths.ConfigDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(paths.SecretsFile, []byte("search-key: key-123\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpContent := "search:\n  url: https://search.example.com/mcp\n  secret: search-key\n  headerName: X-API-Key\n  headerFormat: \"%s\"\n"
+	if err := os.WriteFile(paths.McpServersFile, []byte(mcpContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Workspace:     t.TempDir(),
+		McpServerRefs: []string{"search"},
+	}
+
+	if err := ResolveSecretRefs(cfg, paths); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := cfg.McpServers["search"].(map[string]any)
+	headers, ok := m["headers"].(map[string]string)
+	if !ok {
+		t.Fatalf("headers type = %T", m["headers"])
+	}
+	if headers["X-API-Key"] != "key-123" {
+		t.Errorf("X-API-Key = %q, want key-123", headers["X-API-Key"])
+	}
+	if _, ok := headers["Authorization"]; ok {
+		t.Error("expected no Authorization header with a custom header name")
+	}
+}
+
+func TestResolveSecretRefs_InvalidHeaderFormat(t *testing.T) {
+	paths := testPaths(t)
+
+	if err := config.EnsureDir(paths.ConfigDir); err != nil {
+		t.Fatal(err)
+	}
+
+	mcpContent := "search:\n  url: https://search.example.com/mcp\n  secret: search-key\n  headerFormat: Bearer\n"
+	if err := os.WriteFile(paths.McpServersFile, []byte(mcpContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Workspace:     t.TempDir(),
+		McpServerRefs: []string{"search"},
+	}
+
+	err := ResolveSecretRefs(cfg, paths)
+	if err == nil || !strings.Contains(err.Error(), "invalid header format") {
+		t.Fatalf("ResolveSecretRefs() error = %v, want invalid header format", err)
+	}
+}
+
 func TestResolveSecretRefs_NoSecret(t *testing.T) {
 	paths := testPaths(t)
 
@@ -603,6 +1140,163 @@ func TestResolveSecretRefs_MissingServer(t *testing.T) {
 	}
 }
 
+func TestResolveSecretRefs_InlineSecretRef(t *testing.T) {
+	paths := testPaths(t)
+	if err := config.EnsureDir(paths.ConfigDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.SecretsFile, []byte("github-token: ghp-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	workspace := t.TempDir()
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	cfgContent := "workspace: " + workspace + `
+mcpServers:
+  github:
+    type: http
+    url: https://api.githubcopilot.com/mcp/
+    headers:
+      Authorization:
+        secretRef: github-token
+`
+	if err := os.WriteFile(cfgPath, []byte(cfgContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		t.Fatalf("loading config: %v", err)
+	}
+	loaded := cfg.McpServers
+	if err := ResolveSecretRefs(cfg, paths); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := renderer.New(paths).Render(cfg); err != nil {
+		t.Fatalf("rendering: %v", err)
+	}
+	headers, _ := loaded["github"].(map[string]any)["headers"].(map[string]any)
+	if ref, _ := headers["Authorization"].(map[string]any); ref[secretRefKey] != "github-token" {
+		t.Errorf("loaded mcpServers modified: %v", loaded)
+	}
+
+	rendered, err := os.ReadFile(filepath.Join(paths.RenderedDir, "mcp-config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(rendered), `"Authorization": "ghp-secret"`) {
+		t.Errorf("mcp-config.json should contain the resolved secret, got:\n%s", rendered)
+	}
+	if strings.Contains(string(rendered), "secretRef") {
+		t.Errorf("mcp-config.json should not contain the secretRef, got:\n%s", rendered)
+	}
+
+	source, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(source), "ghp-secret") {
+		t.Error("source config should not contain the secret value")
+	}
+}
+
+func TestResolveSecretRefs_InlineSecretRefMissing(t *testing.T) {
+	paths := testPaths(t)
+	if err := config.EnsureDir(paths.ConfigDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.SecretsFile, []byte("other: value\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Workspace: t.TempDir(),
+		McpServers: map[string]any{
+			"github": map[string]any{
+				"args": []any{"--token", map[string]any{"secretRef": "github-token"}},
+			},
+		},
+	}
+
+	err := ResolveSecretRefs(cfg, paths)
+	if err == nil {
+		t.Fatal("expected error for missing secret")
+	}
+	if !strings.Contains(err.Error(), "mcpServers.github.args[1]") {
+		t.Errorf("error should locate the reference, got: %v", err)
+	}
+}
+
+func TestCheckSecrets(t *testing.T) {
+	paths := testPaths(t)
+	if err := config.EnsureDir(paths.ConfigDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(paths.SecretsFile, []byte("gh-token: abc\nkubeconfig: xyz\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mcpContent := "muster:\n  url: https://muster.example.com/mcp\n  secret: muster-tokn\n" +
+		"public:\n  url: https://public.example.com/mcp\n"
+	if err := os.WriteFile(paths.McpServersFile, []byte(mcpContent), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := &config.Config{
+		SecretEnvVars: map[string]string{"GITHUB_TOKEN": "gh-token"},
+		SecretFiles:   map[string]string{"/home/klaus/.kube/config": "kubeconfig"},
+		McpServerRefs: []string{"public"},
+	}
+	if err := CheckSecrets(valid, paths); err != nil {
+		t.Errorf("CheckSecrets() error = %v", err)
+	}
+
+	invalid := &config.Config{
+		SecretEnvVars: map[string]string{"GITHUB_TOKEN": "gh-tokn", "OK": "gh-token"},
+		SecretFiles:   map[string]string{"/etc/creds": "../creds"},
+		McpServerRefs: []string{"muster", "unknown"},
+		McpServers: map[string]any{
+			"github": map[string]any{
+				"url":     "https://api.githubcopilot.com/mcp/",
+				"headers": map[string]any{"Authorization": map[string]any{"secretRef": "gh-tokn"}},
+			},
+			"kube": map[string]any{"env": map[string]any{"KUBECONFIG": map[string]any{"secretRef": "kubeconfig"}}},
+		},
+	}
+	err := CheckSecrets(invalid, paths)
+	if err == nil {
+		t.Fatal("expected an error for missing secrets")
+	}
+	for _, want := range []string{
+		"5 unresolved secret references",
+		`mcpServers.github.headers.Authorization: secret "gh-tokn" not found`,
+		`secretEnvVars[GITHUB_TOKEN]: secret "gh-tokn" not found`,
+		"secretFiles[/etc/creds]: invalid secret name",
+		`mcpServerRefs[muster]: secret "muster-tokn" not found`,
+		`mcpServerRefs[unknown]: MCP server "unknown" not found`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q, got:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "[OK]") || strings.Contains(err.Error(), "mcpServers.kube") {
+		t.Errorf("error reports a secret that exists:\n%v", err)
+	}
+}
+
+func TestCheckSecrets_NoReferences(t *testing.T) {
+	paths := testPaths(t)
+	if err := config.EnsureDir(paths.ConfigDir); err != nil {
+		t.Fatal(err)
+	}
+	// An unreadable store is not loaded when nothing references it.
+	if err := os.WriteFile(paths.SecretsFile, []byte("x: y\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckSecrets(&config.Config{}, paths); err != nil {
+		t.Errorf("CheckSecrets() error = %v", err)
+	}
+}
+
 func TestResolveSecretRefs_Empty(t *testing.T) {
 	paths := testPaths(t)
 	cfg := &config.Config{Workspace: t.TempDir()}
@@ -0,0 +1,212 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// BlobsDirName is the directory under the plugins directory that holds plugin
+// contents by digest. Each <pluginsDir>/<shortName> entry is a symlink to the
+// blob of the version last pulled for that name, so instances using the same
+// digest share one copy and a moved tag never overwrites an older version.
+const BlobsDirName = "blobs"
+
+// PullPlugin pulls a plugin into the shared plugin cache and points pluginDir,
+// a <pluginsDir>/<shortName> path, at the pulled blob. It reports the digest
+// and whether the plugin was already up-to-date.
+func PullPlugin(ctx context.Context, client *klausoci.Client, ref, pluginDir string) (string, bool, error) {
+	return pullIntoBlobStore(pluginDir, func(destDir string) (string, bool, error) {
+		result, err := client.PullPlugin(ctx, ref, destDir)
+		if err != nil {
+			return "", false, err
+		}
+		return result.Digest, result.Cached, nil
+	})
+}
+
+// ResolvePluginDir returns the blob directory a <pluginsDir>/<shortName>
+// pointer refers to. Paths that are not symlinks, such as plugins pulled
+// before the shared cache existed, are returned unchanged.
+func ResolvePluginDir(pluginDir string) string {
+	resolved, err := filepath.EvalSymlinks(pluginDir)
+	if err != nil {
+		return pluginDir
+	}
+	return resolved
+}
+
+// pullIntoBlobStore runs pull against a staging directory and moves the result
+// into the blob store. The staging directory is seeded with the cache entry of
+// the current version so that pull can skip downloading an unchanged digest.
+func pullIntoBlobStore(pluginDir string, pull func(destDir string) (string, bool, error)) (string, bool, error) {
+	blobsDir := filepath.Join(filepath.Dir(pluginDir), BlobsDirName)
+	if err := os.MkdirAll(blobsDir, 0o750); err != nil {
+		return "", false, fmt.Errorf("creating plugin blobs directory: %w", err)
+	}
+	if err := migrateLegacyPluginDir(pluginDir, blobsDir); err != nil {
+		return "", false, err
+	}
+
+	staging, err := os.MkdirTemp(blobsDir, ".pull-")
+	if err != nil {
+		return "", false, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+
+	current := ""
+	if _, err := os.Stat(pluginDir); err == nil {
+		current = ResolvePluginDir(pluginDir)
+		if entry, err := klausoci.ReadCacheEntry(current); err == nil {
+			seed := klausoci.CacheEntry{Digest: entry.Digest, Ref: entry.Ref, PulledAt: entry.PulledAt}
+			if err := klausoci.WriteCacheEntry(staging, seed); err != nil {
+				return "", false, fmt.Errorf("seeding staging directory: %w", err)
+			}
+		}
+	}
+
+	digest, cached, err := pull(staging)
+	if err != nil {
+		return "", false, err
+	}
+
+	name, err := blobName(digest)
+	if err != nil {
+		return "", false, err
+	}
+	blob := filepath.Join(blobsDir, name)
+	if _, err := os.Stat(blob); errors.Is(err, os.ErrNotExist) {
+		// An up-to-date pull leaves the content where it already was.
+		src := staging
+		if cached && current != "" {
+			src = current
+		}
+		if err := os.Rename(src, blob); err != nil {
+			// Another pull of the same digest may have stored the blob
+			// first; its content is identical.
+			if _, statErr := os.Stat(blob); statErr != nil {
+				return "", false, fmt.Errorf("storing plugin blob: %w", err)
+			}
+		}
+	} else if err != nil {
+		return "", false, fmt.Errorf("checking plugin blob: %w", err)
+	}
+
+	if err := pointPluginDir(pluginDir, name); err != nil {
+		return "", false, err
+	}
+	return digest, cached, nil
+}
+
+// migrateLegacyPluginDir moves a plugin directory from the old layout, where
+// the content lived directly at <pluginsDir>/<shortName>, into the blob store
+// and replaces it with a pointer. Directories without a cache entry are
+// removed since their version is unknown.
+func migrateLegacyPluginDir(pluginDir, blobsDir string) error {
+	info, err := os.Lstat(pluginDir)
+	if err != nil || !info.IsDir() {
+		return nil
+	}
+
+	entry, err := klausoci.ReadCacheEntry(pluginDir)
+	if err != nil {
+		return os.RemoveAll(pluginDir)
+	}
+	name, err := blobName(entry.Digest)
+	if err != nil {
+		return os.RemoveAll(pluginDir)
+	}
+	blob := filepath.Join(blobsDir, name)
+	if _, err := os.Stat(blob); err == nil {
+		if err := os.RemoveAll(pluginDir); err != nil {
+			return fmt.Errorf("removing legacy plugin directory: %w", err)
+		}
+	} else if err := os.Rename(pluginDir, blob); err != nil {
+		return fmt.Errorf("migrating legacy plugin directory: %w", err)
+	}
+	return pointPluginDir(pluginDir, name)
+}
+
+// pointPluginDir atomically replaces pluginDir with a relative symlink to the
+// named blob. The symlink is created in a unique temporary directory next to
+// pluginDir so that concurrent pulls do not race on it.
+func pointPluginDir(pluginDir, blob string) error {
+	tmpDir, err := os.MkdirTemp(filepath.Dir(pluginDir), ".pointer-")
+	if err != nil {
+		return fmt.Errorf("creating plugin pointer: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	tmp := filepath.Join(tmpDir, filepath.Base(pluginDir))
+	if err := os.Symlink(filepath.Join(BlobsDirName, blob), tmp); err != nil {
+		return fmt.Errorf("creating plugin pointer: %w", err)
+	}
+	if err := os.Rename(tmp, pluginDir); err != nil {
+		return fmt.Errorf("updating plugin pointer: %w", err)
+	}
+	return nil
+}
+
+// PrunePluginBlobs removes the blobs under <pluginsDir>/blobs that no
+// <pluginsDir>/<shortName> pointer refers to and that are not in mounted, the
+// host paths mounted by existing containers. It returns the names of the
+// removed blobs, or of the blobs it would remove when dryRun is set.
+func PrunePluginBlobs(pluginsDir string, mounted map[string]bool, dryRun bool) ([]string, error) {
+	blobsDir := filepath.Join(pluginsDir, BlobsDirName)
+	blobs, err := os.ReadDir(blobsDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading plugin blobs directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading plugins directory: %w", err)
+	}
+	referenced := map[string]bool{}
+	for _, entry := range entries {
+		if entry.Name() == BlobsDirName {
+			continue
+		}
+		referenced[ResolvePluginDir(filepath.Join(pluginsDir, entry.Name()))] = true
+	}
+
+	resolvedBlobsDir := ResolvePluginDir(blobsDir)
+	var removed []string
+	var errs []error
+	for _, blob := range blobs {
+		// Staging directories belong to pulls in progress.
+		if strings.HasPrefix(blob.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(resolvedBlobsDir, blob.Name())
+		if referenced[path] || mounted[path] {
+			continue
+		}
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				errs = append(errs, fmt.Errorf("removing plugin blob %s: %w", blob.Name(), err))
+				continue
+			}
+		}
+		removed = append(removed, blob.Name())
+	}
+	return removed, errors.Join(errs...)
+}
+
+// blobName converts a digest such as sha256:abc into the blob directory name
+// sha256-abc, which is safe to use in bind mount specifications.
+func blobName(digest string) (string, error) {
+	algo, hex, ok := strings.Cut(digest, ":")
+	if !ok || algo == "" || hex == "" || strings.ContainsAny(digest, `/\`) || strings.HasPrefix(digest, ".") {
+		return "", fmt.Errorf("invalid plugin digest %q", digest)
+	}
+	return algo + "-" + hex, nil
+}
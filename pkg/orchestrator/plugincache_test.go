@@ -0,0 +1,234 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	klausoci "github.com/giantswarm/klaus-oci"
+)
+
+// fakePluginPull returns a pull function that behaves like the klaus-oci
+// client: it skips the download when destDir already records digest and
+// otherwise writes the plugin content and cache entry.
+func fakePluginPull(t *testing.T, digest, content string, downloads *int) func(string) (string, bool, error) {
+	t.Helper()
+	return func(destDir string) (string, bool, error) {
+		if entry, err := klausoci.ReadCacheEntry(destDir); err == nil && entry.Digest == digest {
+			return digest, true, nil
+		}
+		*downloads++
+		if err := os.WriteFile(filepath.Join(destDir, "plugin.json"), []byte(content), 0o600); err != nil {
+			return "", false, err
+		}
+		return digest, false, klausoci.WriteCacheEntry(destDir, klausoci.CacheEntry{Digest: digest, Ref: "example.com/p:v1"})
+	}
+}
+
+func readPluginContent(t *testing.T, pluginDir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(pluginDir, "plugin.json"))
+	if err != nil {
+		t.Fatalf("reading plugin content: %v", err)
+	}
+	return string(data)
+}
+
+func countBlobs(t *testing.T, pluginsDir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(pluginsDir, BlobsDirName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestPullIntoBlobStoreSharesDigest(t *testing.T) {
+	pluginsDir := t.TempDir()
+	downloads := 0
+	pull := fakePluginPull(t, "sha256:aaa", "v1", &downloads)
+
+	for _, name := range []string{"gs-base", "gs-base-alias"} {
+		if _, _, err := pullIntoBlobStore(filepath.Join(pluginsDir, name), pull); err != nil {
+			t.Fatalf("pull %s: %v", name, err)
+		}
+	}
+
+	if n := countBlobs(t, pluginsDir); n != 1 {
+		t.Errorf("blobs = %d, want 1 shared blob", n)
+	}
+	want := filepath.Join(ResolvePluginDir(pluginsDir), BlobsDirName, "sha256-aaa")
+	for _, name := range []string{"gs-base", "gs-base-alias"} {
+		if got := ResolvePluginDir(filepath.Join(pluginsDir, name)); got != want {
+			t.Errorf("ResolvePluginDir(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestPullIntoBlobStoreSkipsUnchangedDigest(t *testing.T) {
+	pluginsDir := t.TempDir()
+	pluginDir := filepath.Join(pluginsDir, "gs-base")
+	downloads := 0
+	pull := fakePluginPull(t, "sha256:aaa", "v1", &downloads)
+
+	if _, cached, err := pullIntoBlobStore(pluginDir, pull); err != nil || cached {
+		t.Fatalf("first pull: cached=%v err=%v", cached, err)
+	}
+	_, cached, err := pullIntoBlobStore(pluginDir, pull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached || downloads != 1 {
+		t.Errorf("second pull: cached=%v downloads=%d, want cached without download", cached, downloads)
+	}
+	if got := readPluginContent(t, pluginDir); got != "v1" {
+		t.Errorf("content = %q, want v1", got)
+	}
+}
+
+func TestPullIntoBlobStoreKeepsOldVersion(t *testing.T) {
+	pluginsDir := t.TempDir()
+	pluginDir := filepath.Join(pluginsDir, "gs-base")
+	downloads := 0
+
+	if _, _, err := pullIntoBlobStore(pluginDir, fakePluginPull(t, "sha256:aaa", "v1", &downloads)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pullIntoBlobStore(pluginDir, fakePluginPull(t, "sha256:bbb", "v2", &downloads)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readPluginContent(t, pluginDir); got != "v2" {
+		t.Errorf("content = %q, want v2 after the tag moved", got)
+	}
+	if got := readPluginContent(t, filepath.Join(pluginsDir, BlobsDirName, "sha256-aaa")); got != "v1" {
+		t.Errorf("old blob content = %q, want v1 kept", got)
+	}
+}
+
+func TestPullIntoBlobStoreMigratesLegacyDir(t *testing.T) {
+	pluginsDir := t.TempDir()
+	pluginDir := filepath.Join(pluginsDir, "gs-base")
+	if err := os.MkdirAll(pluginDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin.json"), []byte("legacy"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := klausoci.WriteCacheEntry(pluginDir, klausoci.CacheEntry{Digest: "sha256:aaa"}); err != nil {
+		t.Fatal(err)
+	}
+
+	downloads := 0
+	_, cached, err := pullIntoBlobStore(pluginDir, fakePluginPull(t, "sha256:aaa", "v1", &downloads))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached || downloads != 0 {
+		t.Errorf("cached=%v downloads=%d, want the legacy content reused", cached, downloads)
+	}
+	if info, err := os.Lstat(pluginDir); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("plugin dir is not a pointer after migration: %v", err)
+	}
+	if got := readPluginContent(t, pluginDir); got != "legacy" {
+		t.Errorf("content = %q, want legacy", got)
+	}
+}
+
+func TestBlobName(t *testing.T) {
+	if got, err := blobName("sha256:abc"); err != nil || got != "sha256-abc" {
+		t.Errorf("blobName(sha256:abc) = %q, %v", got, err)
+	}
+	for _, digest := range []string{"", "abc", "sha256:", "sha256:../x", "../sha256:x"} {
+		if _, err := blobName(digest); err == nil {
+			t.Errorf("blobName(%q) succeeded, want error", digest)
+		}
+	}
+}
+
+func TestResolvePluginDirNotSymlink(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "missing")
+	if got := ResolvePluginDir(dir); got != dir {
+		t.Errorf("ResolvePluginDir(%q) = %q, want it unchanged", dir, got)
+	}
+}
+
+func TestPointPluginDirLeavesNoTemporaryEntries(t *testing.T) {
+	pluginsDir := t.TempDir()
+	pluginDir := filepath.Join(pluginsDir, "gs-base")
+	for _, blob := range []string{"sha256-aaa", "sha256-bbb"} {
+		if err := pointPluginDir(pluginDir, blob); err != nil {
+			t.Fatalf("pointPluginDir(%s): %v", blob, err)
+		}
+	}
+
+	target, err := os.Readlink(pluginDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(BlobsDirName, "sha256-bbb"); target != want {
+		t.Errorf("pointer = %q, want %q", target, want)
+	}
+	entries, err := os.ReadDir(pluginsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("plugins directory has %d entries, want only the pointer", len(entries))
+	}
+}
+
+func TestPrunePluginBlobs(t *testing.T) {
+	pluginsDir := t.TempDir()
+	downloads := 0
+	if _, _, err := pullIntoBlobStore(filepath.Join(pluginsDir, "gs-base"), fakePluginPull(t, "sha256:aaa", "v1", &downloads)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pullIntoBlobStore(filepath.Join(pluginsDir, "gs-base"), fakePluginPull(t, "sha256:bbb", "v2", &downloads)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := pullIntoBlobStore(filepath.Join(pluginsDir, "gs-extra"), fakePluginPull(t, "sha256:ccc", "v1", &downloads)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(pluginsDir, "gs-extra")); err != nil {
+		t.Fatal(err)
+	}
+	staging := filepath.Join(pluginsDir, BlobsDirName, ".pull-123")
+	if err := os.Mkdir(staging, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	blobsDir := filepath.Join(ResolvePluginDir(pluginsDir), BlobsDirName)
+	mounted := map[string]bool{filepath.Join(blobsDir, "sha256-ccc"): true}
+
+	removed, err := PrunePluginBlobs(pluginsDir, mounted, true)
+	if err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "sha256-aaa" {
+		t.Errorf("dry run removed = %v, want [sha256-aaa]", removed)
+	}
+	if n := countBlobs(t, pluginsDir); n != 4 {
+		t.Errorf("dry run left %d entries, want 4", n)
+	}
+
+	removed, err = PrunePluginBlobs(pluginsDir, nil, false)
+	if err != nil {
+		t.Fatalf("PrunePluginBlobs() error = %v", err)
+	}
+	if len(removed) != 2 || removed[0] != "sha256-aaa" || removed[1] != "sha256-ccc" {
+		t.Errorf("removed = %v, want [sha256-aaa sha256-ccc]", removed)
+	}
+	if got := readPluginContent(t, filepath.Join(pluginsDir, "gs-base")); got != "v2" {
+		t.Errorf("referenced plugin content = %q, want v2", got)
+	}
+	if _, err := os.Stat(staging); err != nil {
+		t.Errorf("staging directory removed: %v", err)
+	}
+}
+
+func TestPrunePluginBlobsWithoutBlobs(t *testing.T) {
+	removed, err := PrunePluginBlobs(t.TempDir(), nil, false)
+	if err != nil || removed != nil {
+		t.Errorf("PrunePluginBlobs() = %v, %v; want nothing", removed, err)
+	}
+}
@@ -0,0 +1,120 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// PullFunc performs one of the pre-start pulls (the container image or the
+// OCI plugins). It must stop promptly when ctx is cancelled.
+type PullFunc func(ctx context.Context) error
+
+// PreStartPull runs the plugin and image pulls that precede container start.
+// Nil functions are skipped.
+//
+// When parallel is true both pulls run concurrently, since they are
+// independent, and the first failure cancels the other pull; that failure is
+// returned. When parallel is false, plugins are pulled first and then the
+// image, which keeps the load on rate-limited registries down.
+func PreStartPull(ctx context.Context, parallel bool, pullPlugins, pullImage PullFunc) error {
+	if !parallel {
+		for _, fn := range []PullFunc{pullPlugins, pullImage} {
+			if fn == nil {
+				continue
+			}
+			if err := fn(ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, fn := range []PullFunc{pullPlugins, pullImage} {
+		if fn == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// PullImage makes image available to rt according to the pull policy (see
+// config.Config.PullPolicy); an empty policy is "always". A non-empty
+// platform pulls the image variant of that platform (see
+// config.Config.Platform). Progress is written to out.
+func PullImage(ctx context.Context, rt runtime.Runtime, image, policy, platform string, out io.Writer) error {
+	if policy == config.PullPolicyIfNotPresent || policy == config.PullPolicyNever {
+		present, err := imagePresent(ctx, rt, image)
+		if err != nil {
+			return fmt.Errorf("checking for local image: %w", err)
+		}
+		if present {
+			_, _ = fmt.Fprintf(out, "Using local image %s.\n", image)
+			return nil
+		}
+		if policy == config.PullPolicyNever {
+			return fmt.Errorf("image %s is not available locally and the pull policy is %q", image, config.PullPolicyNever)
+		}
+	}
+
+	_, _ = fmt.Fprintf(out, "Pulling %s...\n", image)
+	if err := rt.Pull(ctx, image, runtime.PullOptions{Platform: platform}, out); err != nil {
+		// If the pull fails but the image is already cached locally (e.g.
+		// expired registry credentials), continue with the cached copy.
+		if present, imgErr := imagePresent(ctx, rt, image); imgErr != nil || !present {
+			return fmt.Errorf("pulling image: %w", err)
+		}
+		_, _ = fmt.Fprintln(out, "Pull failed, using locally cached image.")
+	}
+	return nil
+}
+
+// imagePresent reports whether rt has image locally.
+func imagePresent(ctx context.Context, rt runtime.Runtime, image string) (bool, error) {
+	images, err := rt.Images(ctx, image)
+	if err != nil {
+		return false, err
+	}
+	return len(images) > 0, nil
+}
+
+// SyncWriter wraps w so that concurrent pulls can share it for progress
+// output. Each Write call is serialized.
+func SyncWriter(w io.Writer) io.Writer {
+	return &syncWriter{w: w}
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,198 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// pullTimeout bounds how long a fake pull waits for its counterpart, so a
+// regression to sequential pulls fails instead of hanging.
+const pullTimeout = 5 * time.Second
+
+func TestPreStartPull_ParallelStartsBothPulls(t *testing.T) {
+	pluginsStarted := make(chan struct{})
+	imageStarted := make(chan struct{})
+
+	// Each fake only returns once it has observed the other pull running,
+	// which can only happen when both are in flight at the same time.
+	pullPlugins := func(ctx context.Context) error {
+		close(pluginsStarted)
+		select {
+		case <-imageStarted:
+			return nil
+		case <-time.After(pullTimeout):
+			return errors.New("image pull was not started concurrently")
+		}
+	}
+	pullImage := func(ctx context.Context) error {
+		close(imageStarted)
+		select {
+		case <-pluginsStarted:
+			return nil
+		case <-time.After(pullTimeout):
+			return errors.New("plugin pull was not started concurrently")
+		}
+	}
+
+	if err := PreStartPull(context.Background(), true, pullPlugins, pullImage); err != nil {
+		t.Fatalf("PreStartPull() error = %v", err)
+	}
+}
+
+func TestPreStartPull_ImageFailureCancelsPlugins(t *testing.T) {
+	imageErr := errors.New("pull access denied")
+	pluginsStarted := make(chan struct{})
+
+	var pluginErr error
+	pullPlugins := func(ctx context.Context) error {
+		close(pluginsStarted)
+		select {
+		case <-ctx.Done():
+			pluginErr = ctx.Err()
+			return pluginErr
+		case <-time.After(pullTimeout):
+			pluginErr = errors.New("plugin pull was not cancelled")
+			return pluginErr
+		}
+	}
+	pullImage := func(ctx context.Context) error {
+		<-pluginsStarted
+		return imageErr
+	}
+
+	err := PreStartPull(context.Background(), true, pullPlugins, pullImage)
+	if !errors.Is(err, imageErr) {
+		t.Fatalf("PreStartPull() error = %v, want %v", err, imageErr)
+	}
+	if !errors.Is(pluginErr, context.Canceled) {
+		t.Errorf("plugin pull error = %v, want context.Canceled", pluginErr)
+	}
+}
+
+func TestPreStartPull_SequentialOrder(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) PullFunc {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+
+	if err := PreStartPull(context.Background(), false, record("plugins"), record("image")); err != nil {
+		t.Fatalf("PreStartPull() error = %v", err)
+	}
+	if len(order) != 2 || order[0] != "plugins" || order[1] != "image" {
+		t.Errorf("pull order = %v, want [plugins image]", order)
+	}
+}
+
+func TestPreStartPull_SequentialStopsOnError(t *testing.T) {
+	pluginsErr := errors.New("plugin not found")
+	imageCalled := false
+
+	err := PreStartPull(context.Background(), false,
+		func(context.Context) error { return pluginsErr },
+		func(context.Context) error { imageCalled = true; return nil },
+	)
+	if !errors.Is(err, pluginsErr) {
+		t.Fatalf("PreStartPull() error = %v, want %v", err, pluginsErr)
+	}
+	if imageCalled {
+		t.Error("expected image pull to be skipped after plugin failure")
+	}
+}
+
+func TestPreStartPull_SkipsNil(t *testing.T) {
+	called := false
+	err := PreStartPull(context.Background(), true, nil, func(context.Context) error {
+		called = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("PreStartPull() error = %v", err)
+	}
+	if !called {
+		t.Error("expected image pull to run")
+	}
+}
+
+// pullRuntime is a runtime.Runtime that records pulls and reports the
+// configured local images.
+type pullRuntime struct {
+	runtime.Runtime
+	local    bool
+	pullErr  error
+	pulls    int
+	platform string
+}
+
+func (r *pullRuntime) Pull(_ context.Context, _ string, opts runtime.PullOptions, _ io.Writer) error {
+	r.pulls++
+	r.platform = opts.Platform
+	return r.pullErr
+}
+
+func (r *pullRuntime) Images(_ context.Context, filter string) ([]runtime.ImageInfo, error) {
+	if !r.local {
+		return nil, nil
+	}
+	return []runtime.ImageInfo{{Repository: filter}}, nil
+}
+
+func TestPullImagePolicies(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    string
+		local     bool
+		pullErr   error
+		wantPulls int
+		wantErr   string
+	}{
+		{name: "default pulls", policy: "", local: true, wantPulls: 1},
+		{name: "always pulls", policy: config.PullPolicyAlways, local: true, wantPulls: 1},
+		{name: "always falls back to local", policy: config.PullPolicyAlways, local: true, pullErr: errors.New("unauthorized"), wantPulls: 1},
+		{name: "always fails without local", policy: config.PullPolicyAlways, pullErr: errors.New("unauthorized"), wantPulls: 1, wantErr: "pulling image"},
+		{name: "ifNotPresent uses local", policy: config.PullPolicyIfNotPresent, local: true, wantPulls: 0},
+		{name: "ifNotPresent pulls missing", policy: config.PullPolicyIfNotPresent, wantPulls: 1},
+		{name: "never uses local", policy: config.PullPolicyNever, local: true, wantPulls: 0},
+		{name: "never fails without local", policy: config.PullPolicyNever, wantPulls: 0, wantErr: "not available locally"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := &pullRuntime{local: tt.local, pullErr: tt.pullErr}
+			err := PullImage(context.Background(), rt, "example.com/klaus:v1", tt.policy, "", io.Discard)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if rt.pulls != tt.wantPulls {
+				t.Errorf("pulls = %d, want %d", rt.pulls, tt.wantPulls)
+			}
+		})
+	}
+}
+
+func TestPullImagePlatform(t *testing.T) {
+	rt := &pullRuntime{}
+	if err := PullImage(context.Background(), rt, "example.com/klaus:v1", "", "linux/amd64", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if rt.platform != "linux/amd64" {
+		t.Errorf("pulled platform = %q, want linux/amd64", rt.platform)
+	}
+}
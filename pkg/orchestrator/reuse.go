@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// RunOrReuse runs the container described by opts and returns its ID. A
+// stopped container of the same name, as left by `klausctl stop --keep`, is
+// started again instead when it was run with the same options (see
+// runtime.SpecHash); otherwise it is removed and a new container is run.
+// reused reports whether an existing container was started. A running or
+// paused container of the same name is an error.
+func RunOrReuse(ctx context.Context, rt runtime.Runtime, opts runtime.RunOptions) (id string, reused bool, err error) {
+	hash := runtime.SpecHash(opts)
+	opts.Labels = maps.Clone(opts.Labels)
+	if opts.Labels == nil {
+		opts.Labels = make(map[string]string, 1)
+	}
+	opts.Labels[runtime.LabelSpecHash] = hash
+
+	status, err := rt.Status(ctx, opts.Name)
+	if err != nil {
+		return "", false, fmt.Errorf("checking container %s: %w", opts.Name, err)
+	}
+	switch status {
+	case "running", "paused":
+		return "", false, fmt.Errorf("container %s is already %s", opts.Name, status)
+	case "exited", "created":
+		info, err := rt.Inspect(ctx, opts.Name)
+		if err == nil && info.Labels[runtime.LabelSpecHash] == hash {
+			if err := rt.Start(ctx, opts.Name); err != nil {
+				return "", false, fmt.Errorf("starting kept container: %w", err)
+			}
+			return info.ID, true, nil
+		}
+	}
+	if status != "" {
+		if err := rt.Remove(ctx, opts.Name); err != nil {
+			return "", false, fmt.Errorf("removing stale container: %w", err)
+		}
+	}
+
+	id, err = rt.Run(ctx, opts)
+	return id, false, err
+}
@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// reuseRuntime is a runtime holding at most one container.
+type reuseRuntime struct {
+	runtime.Runtime
+	container *runtime.ContainerInfo
+	run       []runtime.RunOptions
+	started   int
+	removed   int
+}
+
+func (r *reuseRuntime) Status(_ context.Context, _ string) (string, error) {
+	if r.container == nil {
+		return "", nil
+	}
+	return r.container.Status, nil
+}
+
+func (r *reuseRuntime) Inspect(_ context.Context, _ string) (*runtime.ContainerInfo, error) {
+	return r.container, nil
+}
+
+func (r *reuseRuntime) Start(_ context.Context, _ string) error {
+	r.started++
+	r.container.Status = "running"
+	return nil
+}
+
+func (r *reuseRuntime) Remove(_ context.Context, _ string) error {
+	r.removed++
+	r.container = nil
+	return nil
+}
+
+func (r *reuseRuntime) Run(_ context.Context, opts runtime.RunOptions) (string, error) {
+	r.run = append(r.run, opts)
+	r.container = &runtime.ContainerInfo{ID: "new", Name: opts.Name, Status: "running", Labels: opts.Labels}
+	return "new", nil
+}
+
+func reuseOptions(image string) runtime.RunOptions {
+	return runtime.RunOptions{
+		Name:   "klausctl-dev",
+		Image:  image,
+		Labels: runtime.ManagedLabels("dev"),
+	}
+}
+
+func TestRunOrReuse_StartsCompatibleKeptContainer(t *testing.T) {
+	opts := reuseOptions("klaus:v1")
+	labels := runtime.ManagedLabels("dev")
+	labels[runtime.LabelSpecHash] = runtime.SpecHash(opts)
+	rt := &reuseRuntime{container: &runtime.ContainerInfo{ID: "kept", Status: "exited", Labels: labels}}
+
+	id, reused, err := RunOrReuse(context.Background(), rt, opts)
+	if err != nil {
+		t.Fatalf("RunOrReuse() error = %v", err)
+	}
+	if id != "kept" || !reused {
+		t.Errorf("RunOrReuse() = %q, %v, want kept, true", id, reused)
+	}
+	if rt.started != 1 || rt.removed != 0 || len(rt.run) != 0 {
+		t.Errorf("start/remove/run = %d/%d/%d, want 1/0/0", rt.started, rt.removed, len(rt.run))
+	}
+}
+
+func TestRunOrReuse_ReplacesIncompatibleContainer(t *testing.T) {
+	old := reuseOptions("klaus:v1")
+	labels := runtime.ManagedLabels("dev")
+	labels[runtime.LabelSpecHash] = runtime.SpecHash(old)
+	rt := &reuseRuntime{container: &runtime.ContainerInfo{ID: "kept", Status: "exited", Labels: labels}}
+
+	opts := reuseOptions("klaus:v2")
+	id, reused, err := RunOrReuse(context.Background(), rt, opts)
+	if err != nil {
+		t.Fatalf("RunOrReuse() error = %v", err)
+	}
+	if id != "new" || reused {
+		t.Errorf("RunOrReuse() = %q, %v, want new, false", id, reused)
+	}
+	if rt.started != 0 || rt.removed != 1 || len(rt.run) != 1 {
+		t.Fatalf("start/remove/run = %d/%d/%d, want 0/1/1", rt.started, rt.removed, len(rt.run))
+	}
+	if got := rt.run[0].Labels[runtime.LabelSpecHash]; got != runtime.SpecHash(opts) {
+		t.Errorf("spec hash label = %q, want %q", got, runtime.SpecHash(opts))
+	}
+	if _, ok := opts.Labels[runtime.LabelSpecHash]; ok {
+		t.Error("RunOrReuse() modified the caller's labels")
+	}
+}
+
+func TestRunOrReuse_RunsWithoutContainer(t *testing.T) {
+	rt := &reuseRuntime{}
+
+	_, reused, err := RunOrReuse(context.Background(), rt, reuseOptions("klaus:v1"))
+	if err != nil {
+		t.Fatalf("RunOrReuse() error = %v", err)
+	}
+	if reused || rt.removed != 0 || len(rt.run) != 1 {
+		t.Errorf("reused = %v, remove/run = %d/%d, want false, 0/1", reused, rt.removed, len(rt.run))
+	}
+}
+
+func TestRunOrReuse_RejectsRunningContainer(t *testing.T) {
+	rt := &reuseRuntime{container: &runtime.ContainerInfo{Status: "running"}}
+
+	if _, _, err := RunOrReuse(context.Background(), rt, reuseOptions("klaus:v1")); err == nil {
+		t.Fatal("expected error for a running container")
+	}
+}
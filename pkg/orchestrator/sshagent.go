@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+	"github.com/giantswarm/klausctl/pkg/runtime"
+)
+
+// containerSSHAgentSocket is where the host ssh-agent socket is mounted
+// inside the container.
+const containerSSHAgentSocket = "/run/klaus/ssh-agent.sock"
+
+// hostSSHAgentSocket returns the path of the host ssh-agent socket, or ""
+// when SSH_AUTH_SOCK is unset or does not point to an existing file.
+func hostSSHAgentSocket() string {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return ""
+	}
+	if _, err := os.Stat(socket); err != nil {
+		return ""
+	}
+	return socket
+}
+
+// buildSSHAgentVolume mounts the host ssh-agent socket into the container
+// and sets SSH_AUTH_SOCK in env when forwardSSHAgent is enabled. Returns
+// nil when forwarding is disabled or no agent socket is available; see
+// SSHAgentWarning.
+func buildSSHAgentVolume(cfg *config.Config, env map[string]string) *runtime.Volume {
+	if !cfg.ForwardSSHAgent {
+		return nil
+	}
+	socket := hostSSHAgentSocket()
+	if socket == "" {
+		return nil
+	}
+	env["SSH_AUTH_SOCK"] = containerSSHAgentSocket
+	return &runtime.Volume{HostPath: socket, ContainerPath: containerSSHAgentSocket}
+}
+
+// SSHAgentWarning returns a warning when forwardSSHAgent is enabled but the
+// host ssh-agent socket cannot be forwarded, or "" when there is nothing to
+// warn about.
+func SSHAgentWarning(cfg *config.Config) string {
+	if !cfg.ForwardSSHAgent || hostSSHAgentSocket() != "" {
+		return ""
+	}
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return "forwardSSHAgent is enabled but SSH_AUTH_SOCK is unset; the ssh-agent is not forwarded"
+	}
+	return fmt.Sprintf("forwardSSHAgent is enabled but the ssh-agent socket %s does not exist; the ssh-agent is not forwarded", socket)
+}
@@ -0,0 +1,66 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestBuildVolumes_SSHAgent(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	if err := os.WriteFile(socket, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		enabled     bool
+		authSock    string
+		wantMount   bool
+		wantWarning string
+	}{
+		{name: "socket exists", enabled: true, authSock: socket, wantMount: true},
+		{name: "disabled", enabled: false, authSock: socket},
+		{name: "unset", enabled: true, authSock: "", wantWarning: "SSH_AUTH_SOCK is unset"},
+		{name: "missing socket", enabled: true, authSock: socket + ".gone", wantWarning: "does not exist"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("SSH_AUTH_SOCK", tt.authSock)
+			cfg := &config.Config{Workspace: t.TempDir(), ForwardSSHAgent: tt.enabled}
+			env := make(map[string]string)
+
+			vols, err := BuildVolumes(cfg, testPaths(t), env, "")
+			if err != nil {
+				t.Fatalf("BuildVolumes() error = %v", err)
+			}
+
+			mounted := false
+			for _, v := range vols {
+				if v.ContainerPath == containerSSHAgentSocket {
+					mounted = true
+					if v.HostPath != socket {
+						t.Errorf("ssh-agent host path = %q, want %q", v.HostPath, socket)
+					}
+				}
+			}
+			if mounted != tt.wantMount {
+				t.Errorf("ssh-agent mounted = %v, want %v", mounted, tt.wantMount)
+			}
+			if got, ok := env["SSH_AUTH_SOCK"]; ok != tt.wantMount || (ok && got != containerSSHAgentSocket) {
+				t.Errorf("SSH_AUTH_SOCK = %q (set %v), want set %v", got, ok, tt.wantMount)
+			}
+
+			w := SSHAgentWarning(cfg)
+			if tt.wantWarning == "" && w != "" {
+				t.Errorf("SSHAgentWarning() = %q, want none", w)
+			}
+			if tt.wantWarning != "" && !strings.Contains(w, tt.wantWarning) {
+				t.Errorf("SSHAgentWarning() = %q, want it to contain %q", w, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+// SystemPromptData is the data system prompt templates are executed with.
+type SystemPromptData struct {
+	// Workspace is the workspace as configured: a host directory or an
+	// owner/repo identifier. Inside the container it is mounted at
+	// /workspace.
+	Workspace string
+	// InstanceName is the name of the instance being started.
+	InstanceName string
+}
+
+// systemPromptFuncs returns the functions system prompt templates can call
+// in addition to the allowed built-ins. env only reads the variables cfg
+// passes to the container: the value of an envVars entry, or the host value
+// of an envForward name. Other host variables may hold secrets and are an
+// error.
+func systemPromptFuncs(cfg *config.Config) template.FuncMap {
+	return template.FuncMap{
+		"env": func(name string) (string, error) {
+			if v, ok := cfg.EnvVars[name]; ok {
+				return v, nil
+			}
+			if slices.Contains(cfg.EnvForward, name) {
+				return os.Getenv(name), nil
+			}
+			return "", fmt.Errorf("env %q is not listed in envVars or envForward", name)
+		},
+	}
+}
+
+// allowedSystemPromptFuncs lists every function a system prompt template may
+// use. Other text/template built-ins, such as call, are rejected.
+var allowedSystemPromptFuncs = map[string]bool{
+	"env":    true,
+	"and":    true,
+	"or":     true,
+	"not":    true,
+	"eq":     true,
+	"ne":     true,
+	"print":  true,
+	"printf": true,
+}
+
+// ExpandSystemPrompts expands claude.systemPrompt and
+// claude.appendSystemPrompt of cfg in place as Go templates, so that one
+// personality can adapt its prompt to the instance:
+//
+//	You work on {{.Workspace}} as {{.InstanceName}} for {{env "TEAM"}}.
+//
+// See SystemPromptData for the fields; the only function besides a few
+// built-ins such as eq and printf is env, which reads an environment
+// variable listed in envVars or envForward. Prompts without "{{" are left
+// unchanged.
+func ExpandSystemPrompts(cfg *config.Config, instanceName string) error {
+	data := SystemPromptData{
+		Workspace:    cfg.Workspace,
+		InstanceName: instanceName,
+	}
+
+	funcs := systemPromptFuncs(cfg)
+	var err error
+	if cfg.Claude.SystemPrompt, err = expandSystemPrompt("systemPrompt", cfg.Claude.SystemPrompt, data, funcs); err != nil {
+		return err
+	}
+	if cfg.Claude.AppendSystemPrompt, err = expandSystemPrompt("appendSystemPrompt", cfg.Claude.AppendSystemPrompt, data, funcs); err != nil {
+		return err
+	}
+	return nil
+}
+
+// EscapeSystemPrompt returns text with its template actions escaped, so that
+// ExpandSystemPrompts leaves it unchanged. Prompts from untrusted callers,
+// such as MCP clients, are escaped before they are stored in a config.
+func EscapeSystemPrompt(text string) string {
+	return strings.ReplaceAll(text, "{{", `{{"{{"}}`)
+}
+
+// expandSystemPrompt executes text as a template named after its config
+// field.
+func expandSystemPrompt(field, text string, data SystemPromptData, funcs template.FuncMap) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New(field).Funcs(funcs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parsing claude.%s template: %w", field, err)
+	}
+	for _, t := range tmpl.Templates() {
+		if err := checkSystemPromptNode(t.Root); err != nil {
+			return "", fmt.Errorf("claude.%s: %w", field, err)
+		}
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("expanding claude.%s: %w", field, err)
+	}
+	return b.String(), nil
+}
+
+// checkSystemPromptNode rejects functions that are not in
+// allowedSystemPromptFuncs anywhere below node.
+func checkSystemPromptNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkSystemPromptNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkSystemPromptNode(n.Pipe)
+	case *parse.IfNode:
+		return checkSystemPromptBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		return checkSystemPromptBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkSystemPromptBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return checkSystemPromptNode(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, c := range n.Cmds {
+			if err := checkSystemPromptNode(c); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkSystemPromptNode(arg); err != nil {
+				return err
+			}
+		}
+	case *parse.ChainNode:
+		return checkSystemPromptNode(n.Node)
+	case *parse.IdentifierNode:
+		if !allowedSystemPromptFuncs[n.Ident] {
+			return fmt.Errorf("function %q is not allowed in system prompt templates", n.Ident)
+		}
+	}
+	return nil
+}
+
+func checkSystemPromptBranch(b *parse.BranchNode) error {
+	for _, node := range []parse.Node{b.Pipe, b.List, b.ElseList} {
+		if err := checkSystemPromptNode(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
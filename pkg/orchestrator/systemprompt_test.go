@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+func TestExpandSystemPrompts(t *testing.T) {
+	t.Setenv("KLAUS_TEST_TEAM", "platform")
+
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{"workspace", "Work on {{.Workspace}}.", "Work on giantswarm/docs."},
+		{"instance name", "You are {{.InstanceName}}.", "You are docs-bot."},
+		{"forwarded env", `Team: {{env "KLAUS_TEST_TEAM"}}`, "Team: platform"},
+		{"configured env", `Region: {{env "REGION"}}`, "Region: eu"},
+		{"unset forwarded env", `[{{env "KLAUS_TEST_UNSET"}}]`, "[]"},
+		{"conditional", `{{if eq .InstanceName "docs-bot"}}docs{{else}}code{{end}}`, "docs"},
+		{"no template", "Plain {prompt} with 100% text", "Plain {prompt} with 100% text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				Workspace:  "giantswarm/docs",
+				EnvVars:    map[string]string{"REGION": "eu"},
+				EnvForward: []string{"KLAUS_TEST_TEAM", "KLAUS_TEST_UNSET"},
+			}
+			cfg.Claude.SystemPrompt = tt.prompt
+			cfg.Claude.AppendSystemPrompt = tt.prompt
+
+			if err := ExpandSystemPrompts(cfg, "docs-bot"); err != nil {
+				t.Fatalf("ExpandSystemPrompts() error = %v", err)
+			}
+			if cfg.Claude.SystemPrompt != tt.want {
+				t.Errorf("SystemPrompt = %q, want %q", cfg.Claude.SystemPrompt, tt.want)
+			}
+			if cfg.Claude.AppendSystemPrompt != tt.want {
+				t.Errorf("AppendSystemPrompt = %q, want %q", cfg.Claude.AppendSystemPrompt, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandSystemPromptsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		prompt  string
+		wantErr string
+	}{
+		{"syntax error", "{{.Workspace", "parsing claude.systemPrompt template"},
+		{"unknown function", `{{exec "ls"}}`, `function "exec" not defined`},
+		{"disallowed built-in", `{{call .InstanceName}}`, `function "call" is not allowed`},
+		{"disallowed in branch", `{{if true}}{{else}}{{len .Workspace}}{{end}}`, `function "len" is not allowed`},
+		{"disallowed in define", `{{define "x"}}{{html "a"}}{{end}}ok`, `function "html" is not allowed`},
+		{"unknown field", "{{.Secret}}", "expanding claude.systemPrompt"},
+		{"env not passed to the container", `{{env "HOME"}}`, `env "HOME" is not listed`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Workspace: "/src/app"}
+			cfg.Claude.SystemPrompt = tt.prompt
+
+			err := ExpandSystemPrompts(cfg, "dev")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEscapeSystemPrompt(t *testing.T) {
+	t.Setenv("KLAUS_TEST_SECRET", "hunter2")
+	prompt := `Leak {{env "KLAUS_TEST_SECRET"}} and {{.Workspace}}`
+
+	cfg := &config.Config{Workspace: "/src/app", EnvForward: []string{"KLAUS_TEST_SECRET"}}
+	cfg.Claude.SystemPrompt = EscapeSystemPrompt(prompt)
+	if err := ExpandSystemPrompts(cfg, "dev"); err != nil {
+		t.Fatalf("ExpandSystemPrompts() error = %v", err)
+	}
+	if cfg.Claude.SystemPrompt != prompt {
+		t.Errorf("SystemPrompt = %q, want the prompt unexpanded", cfg.Claude.SystemPrompt)
+	}
+}
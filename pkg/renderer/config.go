@@ -11,9 +11,9 @@ import (
 
 // ContainerClaudeConfig contains the Claude Code settings that the container
 // process needs. This is an explicit projection of config.ClaudeConfig that
-// excludes host-side orchestration fields (SettingsFile, AddDirs, PluginDirs,
-// LoadAdditionalDirsMemory) which are handled by the orchestrator via volume
-// mounts and env vars.
+// excludes host-side orchestration fields (SettingsFile, Settings, AddDirs,
+// PluginDirs, LoadAdditionalDirsMemory) which are handled by the orchestrator
+// via volume mounts and env vars.
 type ContainerClaudeConfig struct {
 	Model                  string   `yaml:"model,omitempty"`
 	SystemPrompt           string   `yaml:"systemPrompt,omitempty"`
@@ -57,13 +57,13 @@ type ContainerConfig struct {
 }
 
 // BuildContainerConfig constructs the container-side config from the full
-// klausctl Config. Workspace is always "/workspace" and Port is always 8080
-// because these are the fixed container-internal values regardless of the
-// host-side configuration.
+// klausctl Config. Workspace is always "/workspace" and Port is the
+// container-internal port (see config.Config.AgentPort) regardless of the
+// host-side port.
 func BuildContainerConfig(cfg *config.Config) *ContainerConfig {
 	cc := &ContainerConfig{
 		Workspace: "/workspace",
-		Port:      8080,
+		Port:      cfg.AgentPort(),
 		Claude: ContainerClaudeConfig{
 			Model:                  cfg.Claude.Model,
 			SystemPrompt:           cfg.Claude.SystemPrompt,
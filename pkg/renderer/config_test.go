@@ -28,6 +28,30 @@ func TestBuildContainerConfig_FixedContainerValues(t *testing.T) {
 	}
 }
 
+func TestBuildContainerConfig_HostNetworkUsesHostPort(t *testing.T) {
+	cfg := &config.Config{
+		Workspace: "/home/user/project",
+		Port:      9090,
+		Network:   config.NetworkHost,
+	}
+
+	if cc := BuildContainerConfig(cfg); cc.Port != 9090 {
+		t.Errorf("Port = %d, want 9090", cc.Port)
+	}
+}
+
+func TestBuildContainerConfig_ContainerPort(t *testing.T) {
+	cfg := &config.Config{
+		Workspace:     "/home/user/project",
+		Port:          9090,
+		ContainerPort: 3000,
+	}
+
+	if cc := BuildContainerConfig(cfg); cc.Port != 3000 {
+		t.Errorf("Port = %d, want 3000", cc.Port)
+	}
+}
+
 func TestBuildContainerConfig_ClaudeSettings(t *testing.T) {
 	cfg := &config.Config{
 		Workspace: "/tmp/ws",
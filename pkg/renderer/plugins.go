@@ -0,0 +1,84 @@
+package renderer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+// pluginManifest is the .claude-plugin/plugin.json of a rendered inline
+// plugin.
+type pluginManifest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// InlinePluginDir returns the host directory an inline plugin is rendered
+// to. It is mounted like a pulled plugin.
+func InlinePluginDir(paths *config.Paths, name string) string {
+	return filepath.Join(paths.RenderedDir, "plugins", name)
+}
+
+// renderInlinePlugins writes each inline plugin in the layout of a pulled
+// plugin: <dir>/.claude-plugin/plugin.json, <dir>/skills/<name>/SKILL.md
+// and <dir>/commands/<name>.md.
+func (r *Renderer) renderInlinePlugins(plugins map[string]config.InlinePlugin) error {
+	for _, name := range sortedKeys(plugins) {
+		if err := validateName(name); err != nil {
+			return fmt.Errorf("invalid inline plugin name: %w", err)
+		}
+		if err := r.renderInlinePlugin(name, plugins[name]); err != nil {
+			return fmt.Errorf("rendering inline plugin %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Renderer) renderInlinePlugin(name string, plugin config.InlinePlugin) error {
+	dir := InlinePluginDir(r.paths, name)
+
+	manifest, err := json.MarshalIndent(pluginManifest{Name: name, Description: plugin.Description}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling manifest: %w", err)
+	}
+	if err := writeFile(filepath.Join(dir, ".claude-plugin", "plugin.json"), append(manifest, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	for _, skillName := range sortedKeys(plugin.Skills) {
+		if err := validateName(skillName); err != nil {
+			return fmt.Errorf("invalid skill name: %w", err)
+		}
+		content, err := renderSkillContent(plugin.Skills[skillName])
+		if err != nil {
+			return fmt.Errorf("rendering skill %q: %w", skillName, err)
+		}
+		if err := writeFile(filepath.Join(dir, "skills", skillName, "SKILL.md"), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("writing skill %q: %w", skillName, err)
+		}
+	}
+
+	for _, cmdName := range sortedKeys(plugin.Commands) {
+		if err := validateName(cmdName); err != nil {
+			return fmt.Errorf("invalid command name: %w", err)
+		}
+		content := ensureTrailingNewline(plugin.Commands[cmdName])
+		if err := writeFile(filepath.Join(dir, "commands", cmdName+".md"), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("writing command %q: %w", cmdName, err)
+		}
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of m in sorted order for deterministic output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -45,6 +45,13 @@ func (r *Renderer) Render(cfg *config.Config) error {
 		}
 	}
 
+	// Render inline plugins.
+	if len(cfg.InlinePlugins) > 0 {
+		if err := r.renderInlinePlugins(cfg.InlinePlugins); err != nil {
+			return fmt.Errorf("rendering inline plugins: %w", err)
+		}
+	}
+
 	// Render agent files.
 	if len(cfg.AgentFiles) > 0 {
 		if err := r.renderAgentFiles(cfg.AgentFiles); err != nil {
@@ -59,9 +66,9 @@ func (r *Renderer) Render(cfg *config.Config) error {
 		}
 	}
 
-	// Render settings (hooks).
-	if len(cfg.Hooks) > 0 {
-		if err := r.renderSettings(cfg.Hooks); err != nil {
+	// Render settings (hooks and inline claude.settings).
+	if HasSettings(cfg) {
+		if err := r.renderSettings(cfg.Hooks, cfg.Claude.Settings); err != nil {
 			return fmt.Errorf("rendering settings: %w", err)
 		}
 	}
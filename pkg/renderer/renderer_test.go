@@ -60,6 +60,56 @@ func TestRenderSkills(t *testing.T) {
 	}
 }
 
+func TestRenderInlinePlugin(t *testing.T) {
+	paths := testPaths(t)
+	r := New(paths)
+
+	cfg := &config.Config{
+		Workspace: "/tmp",
+		Port:      8080,
+		InlinePlugins: map[string]config.InlinePlugin{
+			"local": {
+				Description: "Local experiments",
+				Skills: map[string]config.Skill{
+					"review": {Description: "Review code", Content: "Review the diff."},
+				},
+				Commands: map[string]string{
+					"ship": "Ship it.",
+				},
+			},
+		},
+	}
+
+	if err := r.Render(cfg); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	dir := InlinePluginDir(paths, "local")
+	manifest, err := os.ReadFile(filepath.Join(dir, ".claude-plugin", "plugin.json")) // #nosec G304 -- test path
+	if err != nil {
+		t.Fatalf("manifest not created: %v", err)
+	}
+	if !strings.Contains(string(manifest), `"name": "local"`) || !strings.Contains(string(manifest), "Local experiments") {
+		t.Errorf("unexpected manifest:\n%s", manifest)
+	}
+
+	skill, err := os.ReadFile(filepath.Join(dir, "skills", "review", "SKILL.md")) // #nosec G304 -- test path
+	if err != nil {
+		t.Fatalf("skill not created: %v", err)
+	}
+	if !strings.HasPrefix(string(skill), "---\n") || !strings.Contains(string(skill), "Review the diff.\n") {
+		t.Errorf("unexpected skill:\n%s", skill)
+	}
+
+	command, err := os.ReadFile(filepath.Join(dir, "commands", "ship.md")) // #nosec G304 -- test path
+	if err != nil {
+		t.Fatalf("command not created: %v", err)
+	}
+	if string(command) != "Ship it.\n" {
+		t.Errorf("command = %q, want %q", command, "Ship it.\n")
+	}
+}
+
 func TestRenderSkillFrontmatter(t *testing.T) {
 	skill := config.Skill{
 		Description:            "Test description with 'quotes'",
@@ -355,6 +405,86 @@ func TestRenderSettings(t *testing.T) {
 	}
 }
 
+func TestRenderSettingsMergesInlineSettings(t *testing.T) {
+	paths := testPaths(t)
+	r := New(paths)
+
+	cfg := &config.Config{
+		Workspace: "/tmp",
+		Port:      8080,
+		Hooks: map[string][]config.HookMatcher{
+			"PreToolUse": {
+				{Matcher: "Bash", Hooks: []config.Hook{{Type: "command", Command: "/generated.sh"}}},
+			},
+			"PostToolUse": {
+				{Matcher: "Edit", Hooks: []config.Hook{{Type: "command", Command: "/post.sh"}}},
+			},
+		},
+		Claude: config.ClaudeConfig{
+			Settings: map[string]any{
+				"includeCoAuthoredBy": false,
+				"hooks": map[string]any{
+					"PreToolUse": []any{
+						map[string]any{"matcher": "Write", "hooks": []any{}},
+					},
+				},
+			},
+		},
+	}
+
+	if err := r.Render(cfg); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(paths.RenderedDir, "settings.json")) // #nosec G304 -- test-controlled path
+	if err != nil {
+		t.Fatalf("settings file not created: %v", err)
+	}
+
+	var result struct {
+		IncludeCoAuthoredBy *bool                       `json:"includeCoAuthoredBy"`
+		Hooks               map[string][]map[string]any `json:"hooks"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if result.IncludeCoAuthoredBy == nil || *result.IncludeCoAuthoredBy {
+		t.Error("expected inline includeCoAuthoredBy=false to be rendered")
+	}
+	if got := result.Hooks["PreToolUse"]; len(got) != 1 || got[0]["matcher"] != "Write" {
+		t.Errorf("inline PreToolUse should replace generated matchers, got %v", got)
+	}
+	if got := result.Hooks["PostToolUse"]; len(got) != 1 || got[0]["matcher"] != "Edit" {
+		t.Errorf("generated PostToolUse should be kept, got %v", got)
+	}
+}
+
+func TestRenderSettingsInlineOnly(t *testing.T) {
+	paths := testPaths(t)
+	r := New(paths)
+
+	cfg := &config.Config{
+		Workspace: "/tmp",
+		Port:      8080,
+		Claude: config.ClaudeConfig{
+			Settings: map[string]any{"model": "opus"},
+		},
+	}
+
+	if err := r.Render(cfg); err != nil {
+		t.Fatalf("Render() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(paths.RenderedDir, "settings.json")) // #nosec G304 -- test-controlled path
+	if err != nil {
+		t.Fatalf("settings file not created: %v", err)
+	}
+	if strings.Contains(string(data), "hooks") {
+		t.Errorf("settings.json should not contain hooks when none are configured:\n%s", data)
+	}
+}
+
 func TestRenderHookScripts(t *testing.T) {
 	paths := testPaths(t)
 	r := New(paths)
@@ -457,6 +587,24 @@ func TestRenderRejectsPathTraversal(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "inline plugin with path separator",
+			cfg: &config.Config{
+				Workspace: "/tmp", Port: 8080,
+				InlinePlugins: map[string]config.InlinePlugin{
+					"../evil": {Commands: map[string]string{"x": "pwned\n"}},
+				},
+			},
+		},
+		{
+			name: "inline plugin command with path separator",
+			cfg: &config.Config{
+				Workspace: "/tmp", Port: 8080,
+				InlinePlugins: map[string]config.InlinePlugin{
+					"local": {Commands: map[string]string{"../../evil": "pwned\n"}},
+				},
+			},
+		},
 		{
 			name: "skill named dotdot",
 			cfg: &config.Config{
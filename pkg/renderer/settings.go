@@ -9,12 +9,17 @@ import (
 	"github.com/giantswarm/klausctl/pkg/config"
 )
 
-// renderSettings writes the settings.json file containing hooks configuration.
-// This mirrors the Helm chart's settings.json rendering.
-func (r *Renderer) renderSettings(hooks map[string][]config.HookMatcher) error {
-	data := map[string]any{
-		"hooks": hooks,
-	}
+// HasSettings returns true if a settings.json file is rendered for the
+// config, either from inline hooks or from inline claude.settings.
+func HasSettings(cfg *config.Config) bool {
+	return len(cfg.Hooks) > 0 || len(cfg.Claude.Settings) > 0
+}
+
+// renderSettings writes the settings.json file containing hooks configuration
+// merged with any inline claude.settings. This mirrors the Helm chart's
+// settings.json rendering.
+func (r *Renderer) renderSettings(hooks map[string][]config.HookMatcher, inline map[string]any) error {
+	data := MergeSettings(hooks, inline)
 
 	content, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
@@ -25,6 +30,38 @@ func (r *Renderer) renderSettings(hooks map[string][]config.HookMatcher) error {
 	return writeFile(path, append(content, '\n'), 0o644)
 }
 
+// MergeSettings combines generated hook matchers with inline settings.
+// Top-level inline keys replace generated ones, except "hooks": when both
+// sides define hooks as an object they are merged per event, with the
+// inline entry for an event replacing the generated matchers for it.
+func MergeSettings(hooks map[string][]config.HookMatcher, inline map[string]any) map[string]any {
+	data := make(map[string]any, len(inline)+1)
+
+	if len(hooks) > 0 {
+		generated := make(map[string]any, len(hooks))
+		for event, matchers := range hooks {
+			generated[event] = matchers
+		}
+		data["hooks"] = generated
+	}
+
+	for k, v := range inline {
+		if k == "hooks" {
+			generated, okGen := data["hooks"].(map[string]any)
+			inlineHooks, okInline := v.(map[string]any)
+			if okGen && okInline {
+				for event, matchers := range inlineHooks {
+					generated[event] = matchers
+				}
+				continue
+			}
+		}
+		data[k] = v
+	}
+
+	return data
+}
+
 // renderHookScripts writes hook script files that are referenced by hooks.
 // Scripts are rendered at: <rendered>/hooks/<name>
 // They are mounted to /etc/klaus/hooks/<name> in the container.
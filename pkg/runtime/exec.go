@@ -1,28 +1,75 @@
 package runtime
 
 import (
+	"bufio"
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"os/exec"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // execRuntime implements the Runtime interface using os/exec to call
 // docker or podman CLI commands. Both CLIs share compatible interfaces.
 type execRuntime struct {
 	binary string
+	// host is the runtime host the CLI talks to (see NewWithHost); empty
+	// leaves it to the CLI's own default.
+	host string
+
+	rootlessOnce sync.Once
+	rootless     bool
 }
 
 func (r *execRuntime) Name() string {
 	return r.binary
 }
 
+// command returns the CLI invocation of args, addressed to the runtime host
+// when one is set.
+func (r *execRuntime) command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, r.binary, append(r.hostArgs(), args...)...) // #nosec G204 -- container runtime CLI invocation with controlled args
+}
+
+// hostArgs returns the global flags selecting the runtime host: --host for
+// docker and --url for podman, which implies its remote mode.
+func (r *execRuntime) hostArgs() []string {
+	if r.host == "" {
+		return nil
+	}
+	if r.binary == "podman" {
+		return []string{"--url", r.host}
+	}
+	return []string{"--host", r.host}
+}
+
 func (r *execRuntime) Run(ctx context.Context, opts RunOptions) (string, error) {
+	if r.needsKeepID(ctx, opts) {
+		opts.UserNS = "keep-id"
+	}
+	args := runArgs(opts)
+
+	var stdout, stderr bytes.Buffer
+	cmd := r.command(ctx, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s run failed: %s\n%s", r.binary, err, stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// runArgs builds the arguments of the run command for opts.
+func runArgs(opts RunOptions) []string {
 	args := []string{"run"}
 
 	if opts.Detach {
@@ -33,10 +80,49 @@ func (r *execRuntime) Run(ctx context.Context, opts RunOptions) (string, error)
 		args = append(args, "--name", opts.Name)
 	}
 
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+
 	if opts.User != "" {
 		args = append(args, "--user", opts.User)
 	}
 
+	if opts.UserNS != "" {
+		args = append(args, "--userns", opts.UserNS)
+	}
+
+	if opts.StopTimeout > 0 {
+		args = append(args, "--stop-timeout", strconv.Itoa(timeoutSeconds(opts.StopTimeout)))
+	}
+
+	if opts.RestartPolicy != "" {
+		args = append(args, "--restart", opts.RestartPolicy)
+	}
+
+	if opts.ReadOnly {
+		args = append(args, "--read-only")
+	}
+	for _, p := range opts.Tmpfs {
+		args = append(args, "--tmpfs", p)
+	}
+	for _, c := range opts.CapDrop {
+		args = append(args, "--cap-drop", c)
+	}
+	for _, c := range opts.CapAdd {
+		args = append(args, "--cap-add", c)
+	}
+
+	// Labels (sorted for deterministic output).
+	labelKeys := make([]string, 0, len(opts.Labels))
+	for k := range opts.Labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", k, opts.Labels[k]))
+	}
+
 	// Environment variables (sorted for deterministic output).
 	envKeys := make([]string, 0, len(opts.EnvVars))
 	for k := range opts.EnvVars {
@@ -47,6 +133,14 @@ func (r *execRuntime) Run(ctx context.Context, opts RunOptions) (string, error)
 		args = append(args, "-e", fmt.Sprintf("%s=%s", k, opts.EnvVars[k]))
 	}
 
+	if opts.Network != "" {
+		args = append(args, "--network", opts.Network)
+	}
+
+	for _, d := range opts.DNS {
+		args = append(args, "--dns", d)
+	}
+
 	// Port mappings (sorted for deterministic output).
 	// Default to binding on loopback only so containers aren't reachable
 	// from the LAN unless the caller explicitly opts in by setting HostIP.
@@ -72,29 +166,81 @@ func (r *execRuntime) Run(ctx context.Context, opts RunOptions) (string, error)
 	// Volume mounts.
 	for _, v := range opts.Volumes {
 		mount := fmt.Sprintf("%s:%s", v.HostPath, v.ContainerPath)
+		var mountOpts []string
 		if v.ReadOnly {
-			mount += ":ro"
+			mountOpts = append(mountOpts, "ro")
+		}
+		if v.Consistency != "" {
+			mountOpts = append(mountOpts, v.Consistency)
+		}
+		if len(mountOpts) > 0 {
+			mount += ":" + strings.Join(mountOpts, ",")
 		}
 		args = append(args, "-v", mount)
 	}
 
 	args = append(args, opts.Image)
+	return args
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, args...) // #nosec G204 -- container runtime CLI invocation with controlled args
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// needsKeepID reports whether opts must run in a keep-id user namespace.
+// Rootless podman maps the host user to root inside the container, so the
+// uid:gid given with --user would otherwise not own the bind mounts and
+// writes to /workspace fail with permission denied.
+func (r *execRuntime) needsKeepID(ctx context.Context, opts RunOptions) bool {
+	return r.binary == "podman" && opts.User != "" && opts.UserNS == "" && r.isRootless(ctx)
+}
 
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("%s run failed: %s\n%s", r.binary, err, stderr.String())
+// isRootless reports whether the runtime runs rootless. Only podman is
+// probed; the result is cached for the lifetime of the runtime.
+func (r *execRuntime) isRootless(ctx context.Context) bool {
+	r.rootlessOnce.Do(func() {
+		if r.binary != "podman" {
+			return
+		}
+		var stdout bytes.Buffer
+		cmd := r.command(ctx, "info", "--format", "{{.Host.Security.Rootless}}")
+		cmd.Stdout = &stdout
+		if err := cmd.Run(); err != nil {
+			return
+		}
+		r.rootless = parseRootless(stdout.String())
+	})
+	return r.rootless
+}
+
+// parseRootless parses the output of podman info --format
+// {{.Host.Security.Rootless}}.
+func parseRootless(out string) bool {
+	return strings.TrimSpace(out) == "true"
+}
+
+// stopArgs builds the arguments of the stop command. A negative timeout
+// leaves the timeout to the container.
+func stopArgs(name string, timeout time.Duration) []string {
+	args := []string{"stop"}
+	if timeout >= 0 {
+		args = append(args, "--time", strconv.Itoa(timeoutSeconds(timeout)))
 	}
+	return append(args, name)
+}
 
-	return strings.TrimSpace(stdout.String()), nil
+// removeArgs builds the arguments of the rm command. --force also removes
+// running and restarting containers, e.g. ones started with --restart
+// unless-stopped or always.
+func removeArgs(name string) []string {
+	return []string{"rm", "-f", name}
 }
 
-func (r *execRuntime) Stop(ctx context.Context, name string) error {
+// timeoutSeconds rounds d up to whole seconds, the unit the runtime CLIs
+// accept.
+func timeoutSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+func (r *execRuntime) Stop(ctx context.Context, name string, timeout time.Duration) error {
 	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, "stop", name) // #nosec G204 -- container runtime CLI invocation with controlled args
+	cmd := r.command(ctx, stopArgs(name, timeout)...)
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
@@ -105,7 +251,7 @@ func (r *execRuntime) Stop(ctx context.Context, name string) error {
 
 func (r *execRuntime) Remove(ctx context.Context, name string) error {
 	var stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, "rm", "-f", name) // #nosec G204 -- container runtime CLI invocation with controlled args
+	cmd := r.command(ctx, removeArgs(name)...)
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
@@ -114,9 +260,42 @@ func (r *execRuntime) Remove(ctx context.Context, name string) error {
 	return nil
 }
 
+func (r *execRuntime) Start(ctx context.Context, name string) error {
+	var stderr bytes.Buffer
+	cmd := r.command(ctx, "start", name)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s start failed: %s\n%s", r.binary, err, stderr.String())
+	}
+	return nil
+}
+
+func (r *execRuntime) Pause(ctx context.Context, name string) error {
+	var stderr bytes.Buffer
+	cmd := r.command(ctx, "pause", name)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s pause failed: %s\n%s", r.binary, err, stderr.String())
+	}
+	return nil
+}
+
+func (r *execRuntime) Unpause(ctx context.Context, name string) error {
+	var stderr bytes.Buffer
+	cmd := r.command(ctx, "unpause", name)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s unpause failed: %s\n%s", r.binary, err, stderr.String())
+	}
+	return nil
+}
+
 func (r *execRuntime) Status(ctx context.Context, name string) (string, error) {
 	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, "inspect", "--format", "{{.State.Status}}", name) // #nosec G204 -- container runtime CLI invocation with controlled args
+	cmd := r.command(ctx, "inspect", "--format", "{{.State.Status}}", name)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -132,7 +311,7 @@ func (r *execRuntime) Status(ctx context.Context, name string) (string, error) {
 
 func (r *execRuntime) Inspect(ctx context.Context, name string) (*ContainerInfo, error) {
 	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, "inspect", name) // #nosec G204 -- container runtime CLI invocation with controlled args
+	cmd := r.command(ctx, "inspect", name)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -148,14 +327,55 @@ func (r *execRuntime) Inspect(ctx context.Context, name string) (*ContainerInfo,
 		return nil, fmt.Errorf("no container found with name %q", name)
 	}
 
-	result := results[0]
-	return &ContainerInfo{
-		ID:        result.ID,
-		Name:      strings.TrimPrefix(result.Name, "/"),
-		Image:     result.Image,
-		Status:    result.State.Status,
-		StartedAt: result.State.StartedAt,
-	}, nil
+	info := results[0].containerInfo()
+	return &info, nil
+}
+
+func (r *execRuntime) ListManaged(ctx context.Context) ([]ContainerInfo, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := r.command(ctx, listManagedArgs()...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s ps failed: %s\n%s", r.binary, err, stderr.String())
+	}
+	ids := strings.Fields(stdout.String())
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	cmd = r.command(ctx, append([]string{"inspect"}, ids...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// A container removed between ps and inspect makes inspect fail while
+	// still printing the others, so only fail when nothing was printed.
+	if err := cmd.Run(); err != nil && stdout.Len() == 0 {
+		return nil, fmt.Errorf("%s inspect failed: %s\n%s", r.binary, err, stderr.String())
+	}
+	return parseInspectOutput(stdout.Bytes())
+}
+
+// listManagedArgs builds the arguments of the ps command listing the IDs of
+// all containers labelled as managed by klausctl.
+func listManagedArgs() []string {
+	return []string{"ps", "-a", "-q", "--no-trunc", "--filter", "label=" + LabelManaged + "=true"}
+}
+
+// parseInspectOutput parses the JSON array printed by inspect.
+func parseInspectOutput(data []byte) ([]ContainerInfo, error) {
+	var results []inspectResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("parsing inspect output: %w", err)
+	}
+	infos := make([]ContainerInfo, 0, len(results))
+	for _, r := range results {
+		infos = append(infos, r.containerInfo())
+	}
+	return infos, nil
 }
 
 func (r *execRuntime) Images(ctx context.Context, filter string) ([]ImageInfo, error) {
@@ -166,7 +386,7 @@ func (r *execRuntime) Images(ctx context.Context, filter string) ([]ImageInfo, e
 	args = append(args, "--format", "{{json .}}")
 
 	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, args...) // #nosec G204 -- container runtime CLI invocation with controlled args
+	cmd := r.command(ctx, args...)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -210,8 +430,8 @@ func (r *execRuntime) Images(ctx context.Context, filter string) ([]ImageInfo, e
 	return images, nil
 }
 
-func (r *execRuntime) Pull(ctx context.Context, image string, w io.Writer) error {
-	cmd := exec.CommandContext(ctx, r.binary, "pull", image) // #nosec G204 -- container runtime CLI invocation with controlled args
+func (r *execRuntime) Pull(ctx context.Context, image string, opts PullOptions, w io.Writer) error {
+	cmd := r.command(ctx, pullArgs(image, opts)...)
 	cmd.Stdout = w
 	cmd.Stderr = w
 
@@ -221,7 +441,16 @@ func (r *execRuntime) Pull(ctx context.Context, image string, w io.Writer) error
 	return nil
 }
 
-func (r *execRuntime) Logs(ctx context.Context, name string, follow bool, tail int) error {
+// pullArgs builds the arguments of the pull command.
+func pullArgs(image string, opts PullOptions) []string {
+	args := []string{"pull"}
+	if opts.Platform != "" {
+		args = append(args, "--platform", opts.Platform)
+	}
+	return append(args, image)
+}
+
+func (r *execRuntime) Logs(ctx context.Context, name string, follow bool, tail int, stdout, stderr io.Writer) error {
 	args := []string{"logs"}
 	if follow {
 		args = append(args, "-f")
@@ -231,9 +460,9 @@ func (r *execRuntime) Logs(ctx context.Context, name string, follow bool, tail i
 	}
 	args = append(args, name)
 
-	cmd := exec.CommandContext(ctx, r.binary, args...) // #nosec G204 -- container runtime CLI invocation with controlled args
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd := r.command(ctx, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
 	err := cmd.Run()
 	// Swallow context-cancellation errors -- the user interrupted with Ctrl+C,
@@ -252,7 +481,7 @@ func (r *execRuntime) LogsCapture(ctx context.Context, name string, tail int) (s
 	args = append(args, name)
 
 	var stdout, stderr bytes.Buffer
-	cmd := exec.CommandContext(ctx, r.binary, args...) // #nosec G204 -- container runtime CLI invocation with controlled args
+	cmd := r.command(ctx, args...)
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
@@ -261,3 +490,167 @@ func (r *execRuntime) LogsCapture(ctx context.Context, name string, tail int) (s
 	}
 	return stdout.String(), nil
 }
+
+func (r *execRuntime) Events(ctx context.Context, name string, fn func(Event) error) error {
+	var stderr bytes.Buffer
+	cmd := r.command(ctx, eventsArgs(r.binary, name)...)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s events failed: %w", r.binary, err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	var fnErr error
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		ev, err := parseEvent([]byte(line))
+		if err != nil {
+			fnErr = err
+			break
+		}
+		if fnErr = fn(ev); fnErr != nil {
+			break
+		}
+	}
+	if fnErr != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fnErr
+	}
+
+	err = cmd.Wait()
+	// The events stream only ends when it is interrupted, which is the
+	// normal way to stop it.
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s events failed: %s\n%s", r.binary, err, stderr.String())
+	}
+	return scanner.Err()
+}
+
+// Exec runs the exec command built by execArgs with the streams of opts.
+func (r *execRuntime) Exec(ctx context.Context, name string, opts ExecOptions) error {
+	cmd := r.command(ctx, execArgs(name, opts)...)
+	cmd.Stdin = opts.Stdin
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	return cmd.Run()
+}
+
+// execArgs returns the arguments of an exec of opts.Command in the
+// container name, interactive with a terminal when opts.TTY is set.
+func execArgs(name string, opts ExecOptions) []string {
+	args := []string{"exec"}
+	switch {
+	case opts.TTY:
+		args = append(args, "-i", "-t", "--detach-keys", DetachKeys)
+	case opts.Stdin != nil:
+		args = append(args, "-i")
+	}
+	args = append(args, name)
+	return append(args, opts.Command...)
+}
+
+// eventsArgs builds the arguments of the events command streaming the
+// lifecycle events of the named container as JSON lines. Podman names the
+// exit of a container "died" instead of "die", also in its event filter.
+func eventsArgs(binary, name string) []string {
+	args := []string{"events", "--filter", "container=" + name}
+	for _, action := range EventActions {
+		if action == "die" && binary == "podman" {
+			action = "died"
+		}
+		args = append(args, "--filter", "event="+action)
+	}
+	return append(args, "--format", "{{json .}}")
+}
+
+// rawEvent covers the JSON events of both CLIs. Docker sets Action, Actor
+// and timeNano; Podman sets Status, Name, Time and ContainerExitCode.
+type rawEvent struct {
+	Action string `json:"Action"`
+	Actor  struct {
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+	TimeNano int64 `json:"timeNano"`
+
+	Status            string          `json:"Status"`
+	Name              string          `json:"Name"`
+	Time              json.RawMessage `json:"Time"`
+	ContainerExitCode *int            `json:"ContainerExitCode"`
+}
+
+// parseEvent parses one JSON line of the events command.
+func parseEvent(line []byte) (Event, error) {
+	var raw rawEvent
+	if err := json.Unmarshal(line, &raw); err != nil {
+		return Event{}, fmt.Errorf("parsing event: %w", err)
+	}
+
+	ev := Event{
+		Action:    cmp.Or(raw.Action, raw.Status),
+		Container: cmp.Or(raw.Actor.Attributes["name"], raw.Name),
+		ExitCode:  raw.ContainerExitCode,
+	}
+	// Podman reports a container exit as "died".
+	if ev.Action == "died" {
+		ev.Action = "die"
+	}
+	if code, ok := raw.Actor.Attributes["exitCode"]; ok && ev.ExitCode == nil {
+		if n, err := strconv.Atoi(code); err == nil {
+			ev.ExitCode = &n
+		}
+	}
+	if ev.Action != "die" {
+		ev.ExitCode = nil
+	}
+
+	switch {
+	case raw.TimeNano > 0:
+		ev.Time = time.Unix(0, raw.TimeNano)
+	case len(raw.Time) > 0:
+		var secs int64
+		var ts time.Time
+		if err := json.Unmarshal(raw.Time, &secs); err == nil {
+			ev.Time = time.Unix(secs, 0)
+		} else if err := json.Unmarshal(raw.Time, &ts); err == nil {
+			ev.Time = ts
+		}
+	}
+	return ev, nil
+}
+
+func (r *execRuntime) Version(ctx context.Context) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := r.command(ctx, "--version")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s --version failed: %w (stderr: %s)", r.binary, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseVersionOutput(stdout.String()), nil
+}
+
+// parseVersionOutput extracts the version number from "--version" output.
+// Docker prints "Docker version 27.1.1, build 6312585" and Podman prints
+// "podman version 5.2.0"; anything else is returned trimmed as-is.
+func parseVersionOutput(out string) string {
+	out = strings.TrimSpace(out)
+	fields := strings.Fields(out)
+	for i, f := range fields {
+		if f == "version" && i+1 < len(fields) {
+			return strings.TrimSuffix(fields[i+1], ",")
+		}
+	}
+	return out
+}
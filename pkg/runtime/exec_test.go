@@ -0,0 +1,390 @@
+package runtime
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunArgsStopTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    []string
+	}{
+		{name: "unset", timeout: 0, want: []string{"run", "--name", "klaus-dev", "img"}},
+		{name: "whole seconds", timeout: 30 * time.Second, want: []string{"run", "--name", "klaus-dev", "--stop-timeout", "30", "img"}},
+		{name: "rounded up", timeout: 1500 * time.Millisecond, want: []string{"run", "--name", "klaus-dev", "--stop-timeout", "2", "img"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runArgs(RunOptions{Name: "klaus-dev", Image: "img", StopTimeout: tt.timeout})
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("runArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunArgsRestartPolicy(t *testing.T) {
+	got := runArgs(RunOptions{Name: "klaus-dev", Image: "img", RestartPolicy: "unless-stopped"})
+	want := []string{"run", "--name", "klaus-dev", "--restart", "unless-stopped", "img"}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+
+	if got := runArgs(RunOptions{Name: "klaus-dev", Image: "img"}); slices.Contains(got, "--restart") {
+		t.Errorf("runArgs() without a policy = %v, want no --restart", got)
+	}
+}
+
+func TestRunArgsSecurityOptions(t *testing.T) {
+	got := runArgs(RunOptions{
+		Name:     "klaus-dev",
+		Image:    "img",
+		ReadOnly: true,
+		Tmpfs:    []string{"/tmp"},
+		CapDrop:  []string{"ALL"},
+		CapAdd:   []string{"NET_BIND_SERVICE", "CHOWN"},
+	})
+	want := []string{
+		"run", "--name", "klaus-dev",
+		"--read-only", "--tmpfs", "/tmp",
+		"--cap-drop", "ALL",
+		"--cap-add", "NET_BIND_SERVICE", "--cap-add", "CHOWN",
+		"img",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+
+	got = runArgs(RunOptions{Name: "klaus-dev", Image: "img"})
+	for _, flag := range []string{"--read-only", "--tmpfs", "--cap-drop", "--cap-add"} {
+		if slices.Contains(got, flag) {
+			t.Errorf("runArgs() without security options = %v, want no %s", got, flag)
+		}
+	}
+}
+
+func TestRunArgsDNSAndExtraHosts(t *testing.T) {
+	got := runArgs(RunOptions{
+		Name:       "klaus-dev",
+		Image:      "img",
+		DNS:        []string{"10.0.0.53", "10.0.1.53"},
+		ExtraHosts: []string{"git.corp:10.0.0.7"},
+	})
+	want := []string{
+		"run", "--name", "klaus-dev",
+		"--dns", "10.0.0.53", "--dns", "10.0.1.53",
+		"--add-host", "git.corp:10.0.0.7",
+		"img",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestRunArgsVolumes(t *testing.T) {
+	got := runArgs(RunOptions{
+		Name:  "klaus-dev",
+		Image: "img",
+		Volumes: []Volume{
+			{HostPath: "/src", ContainerPath: "/workspace"},
+			{HostPath: "/cfg", ContainerPath: "/etc/klaus/config.yaml", ReadOnly: true},
+			{HostPath: "/repo", ContainerPath: "/repo", Consistency: "cached"},
+			{HostPath: "/ro", ContainerPath: "/ro", ReadOnly: true, Consistency: "delegated"},
+		},
+	})
+	want := []string{
+		"run", "--name", "klaus-dev",
+		"-v", "/src:/workspace",
+		"-v", "/cfg:/etc/klaus/config.yaml:ro",
+		"-v", "/repo:/repo:cached",
+		"-v", "/ro:/ro:ro,delegated",
+		"img",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestPlatformArgs(t *testing.T) {
+	got := runArgs(RunOptions{Name: "klaus-dev", Image: "img", Platform: "linux/amd64"})
+	want := []string{"run", "--name", "klaus-dev", "--platform", "linux/amd64", "img"}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+
+	got = pullArgs("img", PullOptions{Platform: "linux/amd64"})
+	want = []string{"pull", "--platform", "linux/amd64", "img"}
+	if !slices.Equal(got, want) {
+		t.Errorf("pullArgs() = %v, want %v", got, want)
+	}
+
+	if got := pullArgs("img", PullOptions{}); !slices.Equal(got, []string{"pull", "img"}) {
+		t.Errorf("pullArgs() without a platform = %v, want [pull img]", got)
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ExecOptions
+		want []string
+	}{
+		{"tty", ExecOptions{Command: []string{"sh", "-c", "exec bash"}, TTY: true},
+			[]string{"exec", "-i", "-t", "--detach-keys", "ctrl-p,ctrl-q", "klausctl-dev", "sh", "-c", "exec bash"}},
+		{"stdin", ExecOptions{Command: []string{"cat"}, Stdin: strings.NewReader("")},
+			[]string{"exec", "-i", "klausctl-dev", "cat"}},
+		{"non-interactive", ExecOptions{Command: []string{"kill", "-HUP", "1"}},
+			[]string{"exec", "klausctl-dev", "kill", "-HUP", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execArgs("klausctl-dev", tt.opts); !slices.Equal(got, tt.want) {
+				t.Errorf("execArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunArgsUserNS(t *testing.T) {
+	got := runArgs(RunOptions{Name: "klaus-dev", Image: "img", User: "1000:1000", UserNS: "keep-id"})
+	want := []string{"run", "--name", "klaus-dev", "--user", "1000:1000", "--userns", "keep-id", "img"}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestRunArgsLabels(t *testing.T) {
+	got := runArgs(RunOptions{Name: "klausctl-dev", Image: "img", Labels: ManagedLabels("dev")})
+	want := []string{"run", "--name", "klausctl-dev", "--label", "klausctl.instance=dev", "--label", "klausctl.managed=true", "img"}
+	if !slices.Equal(got, want) {
+		t.Errorf("runArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestListManagedArgs(t *testing.T) {
+	want := []string{"ps", "-a", "-q", "--no-trunc", "--filter", "label=klausctl.managed=true"}
+	if got := listManagedArgs(); !slices.Equal(got, want) {
+		t.Errorf("listManagedArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestParseInspectOutput(t *testing.T) {
+	out := `[
+  {"Id": "abc", "Name": "/klausctl-dev", "Image": "sha256:1", "State": {"Status": "running"}, "Config": {"Labels": {"klausctl.managed": "true", "klausctl.instance": "dev"}}, "Mounts": [{"Source": "/plugins/blobs/sha256-aaa"}]},
+  {"Id": "def", "Name": "/klausctl-old", "Image": "sha256:2", "State": {"Status": "exited"}, "Config": {"Labels": null}}
+]`
+	infos, err := parseInspectOutput([]byte(out))
+	if err != nil {
+		t.Fatalf("parseInspectOutput() error = %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("got %d containers, want 2", len(infos))
+	}
+	if infos[0].Name != "klausctl-dev" || infos[0].Status != "running" || infos[0].Labels[LabelInstance] != "dev" ||
+		len(infos[0].Mounts) != 1 || infos[0].Mounts[0] != "/plugins/blobs/sha256-aaa" {
+		t.Errorf("first container = %+v", infos[0])
+	}
+	if infos[1].ID != "def" || infos[1].Labels != nil {
+		t.Errorf("second container = %+v", infos[1])
+	}
+
+	if _, err := parseInspectOutput([]byte("not json")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
+
+// probedRuntime returns an execRuntime whose rootless probe already ran.
+func probedRuntime(binary string, rootless bool) *execRuntime {
+	r := &execRuntime{binary: binary}
+	r.rootlessOnce.Do(func() { r.rootless = rootless })
+	return r
+}
+
+func TestNeedsKeepID(t *testing.T) {
+	user := RunOptions{User: "1000:1000"}
+	tests := []struct {
+		name string
+		r    *execRuntime
+		opts RunOptions
+		want bool
+	}{
+		{name: "rootless podman", r: probedRuntime("podman", true), opts: user, want: true},
+		{name: "rootful podman", r: probedRuntime("podman", false), opts: user},
+		{name: "docker", r: probedRuntime("docker", true), opts: user},
+		{name: "no user", r: probedRuntime("podman", true), opts: RunOptions{}},
+		{name: "explicit userns", r: probedRuntime("podman", true), opts: RunOptions{User: "1000:1000", UserNS: "host"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.r.needsKeepID(context.Background(), tt.opts); got != tt.want {
+				t.Errorf("needsKeepID() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRootless(t *testing.T) {
+	if !parseRootless("true\n") {
+		t.Error("parseRootless(true) = false")
+	}
+	for _, out := range []string{"false\n", "", "<no value>"} {
+		if parseRootless(out) {
+			t.Errorf("parseRootless(%q) = true", out)
+		}
+	}
+}
+
+func TestRemoveArgsForce(t *testing.T) {
+	// Containers with a restart policy can only be removed with --force
+	// while they are running or restarting.
+	if got, want := removeArgs("klaus-dev"), []string{"rm", "-f", "klaus-dev"}; !slices.Equal(got, want) {
+		t.Errorf("removeArgs() = %v, want %v", got, want)
+	}
+}
+
+func TestStopArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    []string
+	}{
+		{name: "container default", timeout: DefaultStopTimeout, want: []string{"stop", "klaus-dev"}},
+		{name: "immediate kill", timeout: 0, want: []string{"stop", "--time", "0", "klaus-dev"}},
+		{name: "explicit timeout", timeout: time.Minute, want: []string{"stop", "--time", "60", "klaus-dev"}},
+		{name: "rounded up", timeout: 100 * time.Millisecond, want: []string{"stop", "--time", "1", "klaus-dev"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stopArgs("klaus-dev", tt.timeout)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("stopArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventsArgs(t *testing.T) {
+	got := eventsArgs("docker", "klausctl-dev")
+	want := []string{"events", "--filter", "container=klausctl-dev"}
+	for _, action := range EventActions {
+		want = append(want, "--filter", "event="+action)
+	}
+	want = append(want, "--format", "{{json .}}")
+	if !slices.Equal(got, want) {
+		t.Errorf("eventsArgs() = %v, want %v", got, want)
+	}
+
+	if got := eventsArgs("podman", "klausctl-dev"); !slices.Contains(got, "event=died") || slices.Contains(got, "event=die") {
+		t.Errorf("podman eventsArgs() = %v, want the died event", got)
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		want     Event
+		wantCode int
+	}{
+		{
+			name: "docker die",
+			line: `{"status":"die","id":"abc","from":"img","Type":"container","Action":"die","Actor":{"ID":"abc","Attributes":{"exitCode":"137","image":"img","name":"klausctl-dev"}},"scope":"local","time":1700000000,"timeNano":1700000000500000000}`,
+			want: Event{
+				Time:      time.Unix(0, 1700000000500000000),
+				Container: "klausctl-dev",
+				Action:    "die",
+			},
+			wantCode: 137,
+		},
+		{
+			name: "docker oom",
+			line: `{"status":"oom","id":"abc","Type":"container","Action":"oom","Actor":{"ID":"abc","Attributes":{"name":"klausctl-dev"}},"time":1700000000,"timeNano":1700000000000000000}`,
+			want: Event{Time: time.Unix(1700000000, 0), Container: "klausctl-dev", Action: "oom"},
+		},
+		{
+			name:     "podman died",
+			line:     `{"ID":"abc","Image":"img","Name":"klausctl-dev","Status":"died","Time":"2023-11-14T22:13:20.5Z","Type":"container","ContainerExitCode":1,"Attributes":{"image":"img"}}`,
+			want:     Event{Time: time.Date(2023, 11, 14, 22, 13, 20, 500000000, time.UTC), Container: "klausctl-dev", Action: "die"},
+			wantCode: 1,
+		},
+		{
+			name: "podman start",
+			line: `{"ID":"abc","Name":"klausctl-dev","Status":"start","Time":"2023-11-14T22:13:20Z","Type":"container","ContainerExitCode":0}`,
+			want: Event{Time: time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), Container: "klausctl-dev", Action: "start"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEvent([]byte(tt.line))
+			if err != nil {
+				t.Fatalf("parseEvent() error = %v", err)
+			}
+			if !got.Time.Equal(tt.want.Time) || got.Container != tt.want.Container || got.Action != tt.want.Action {
+				t.Errorf("parseEvent() = %+v, want %+v", got, tt.want)
+			}
+			switch {
+			case tt.wantCode == 0 && got.ExitCode != nil:
+				t.Errorf("ExitCode = %d, want none", *got.ExitCode)
+			case tt.wantCode != 0 && (got.ExitCode == nil || *got.ExitCode != tt.wantCode):
+				t.Errorf("ExitCode = %v, want %d", got.ExitCode, tt.wantCode)
+			}
+		})
+	}
+
+	if _, err := parseEvent([]byte("not json")); err == nil {
+		t.Error("expected error for invalid event")
+	}
+}
+
+func TestCommandHostArgs(t *testing.T) {
+	tests := []struct {
+		binary, host string
+		want         []string
+	}{
+		{binary: "docker", want: []string{"docker", "stop", "klaus-dev"}},
+		{binary: "docker", host: "ssh://me@build-box", want: []string{"docker", "--host", "ssh://me@build-box", "stop", "klaus-dev"}},
+		{binary: "podman", host: "tcp://10.0.0.5:2376", want: []string{"podman", "--url", "tcp://10.0.0.5:2376", "stop", "klaus-dev"}},
+	}
+	for _, tt := range tests {
+		rt, err := NewWithHost(tt.binary, tt.host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmd := rt.(*execRuntime).command(context.Background(), "stop", "klaus-dev")
+		if got := append([]string{tt.binary}, cmd.Args[1:]...); !slices.Equal(got, tt.want) {
+			t.Errorf("%s %q: args = %v, want %v", tt.binary, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestValidateHost(t *testing.T) {
+	for _, host := range []string{"", "unix:///var/run/docker.sock", "tcp://10.0.0.5:2376", "ssh://me@build-box"} {
+		if err := ValidateHost(host); err != nil {
+			t.Errorf("ValidateHost(%q) error = %v", host, err)
+		}
+	}
+	for _, host := range []string{"build-box", "http://build-box", "ssh://"} {
+		if err := ValidateHost(host); err == nil {
+			t.Errorf("ValidateHost(%q) succeeded, want an error", host)
+		}
+	}
+}
+
+func TestRemoteHostName(t *testing.T) {
+	tests := map[string]string{
+		"":                            "",
+		"unix:///var/run/docker.sock": "",
+		"tcp://10.0.0.5:2376":         "10.0.0.5",
+		"ssh://me@build-box:2222":     "build-box",
+	}
+	for host, want := range tests {
+		if got := RemoteHostName(host); got != want {
+			t.Errorf("RemoteHostName(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
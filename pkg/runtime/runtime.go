@@ -5,8 +5,14 @@ package runtime
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -16,29 +22,112 @@ type Runtime interface {
 	Name() string
 	// Run starts a new container and returns its ID.
 	Run(ctx context.Context, opts RunOptions) (string, error)
-	// Stop stops a running container.
-	Stop(ctx context.Context, name string) error
-	// Remove removes a container.
+	// Stop stops a running container. The container gets timeout to exit
+	// after SIGTERM before it is killed with SIGKILL; DefaultStopTimeout uses
+	// the stop timeout the container was started with (RunOptions.StopTimeout).
+	Stop(ctx context.Context, name string, timeout time.Duration) error
+	// Start starts an existing, stopped container again with the options it
+	// was created with.
+	Start(ctx context.Context, name string) error
+	// Remove removes a container, killing it if it is still running. This
+	// includes containers with a restart policy, which the runtime would
+	// otherwise bring back.
 	Remove(ctx context.Context, name string) error
-	// Status returns the container status ("running", "exited", "created", etc.)
-	// or an empty string if the container doesn't exist.
+	// Pause freezes all processes of a running container, keeping its state
+	// in memory.
+	Pause(ctx context.Context, name string) error
+	// Unpause resumes the processes of a paused container.
+	Unpause(ctx context.Context, name string) error
+	// Status returns the container status ("running", "paused", "exited",
+	// "created", etc.) or an empty string if the container doesn't exist.
 	Status(ctx context.Context, name string) (string, error)
 	// Inspect returns detailed container information.
 	Inspect(ctx context.Context, name string) (*ContainerInfo, error)
-	// Logs streams container logs to stdout/stderr. If follow is true, it
-	// streams continuously until interrupted. If tail > 0, only the last N
-	// lines are shown.
-	Logs(ctx context.Context, name string, follow bool, tail int) error
+	// Logs streams the container's stdout and stderr logs to stdout and
+	// stderr. If follow is true, it streams continuously until interrupted.
+	// If tail > 0, only the last N lines are shown.
+	Logs(ctx context.Context, name string, follow bool, tail int, stdout, stderr io.Writer) error
 	// LogsCapture returns container log lines as a string instead of
 	// streaming to stdout. Useful for programmatic consumers (e.g. MCP tools).
 	LogsCapture(ctx context.Context, name string, tail int) (string, error)
 	// Pull pulls a container image, streaming progress to w.
-	Pull(ctx context.Context, image string, w io.Writer) error
+	Pull(ctx context.Context, image string, opts PullOptions, w io.Writer) error
 	// Images lists locally cached container images matching the given reference
 	// filter pattern (e.g. "*klaus-*"). If filter is empty, all images are returned.
 	Images(ctx context.Context, filter string) ([]ImageInfo, error)
+	// Version returns the version of the runtime CLI (e.g. "27.1.1").
+	Version(ctx context.Context) (string, error)
+	// ListManaged returns all containers, running or not, labelled as
+	// managed by klausctl (see ManagedLabels).
+	ListManaged(ctx context.Context) ([]ContainerInfo, error)
+	// Events streams the lifecycle events (see EventActions) of the named
+	// container to fn until ctx is canceled or fn returns an error.
+	Events(ctx context.Context, name string, fn func(Event) error) error
+	// Exec runs a command in the running container. With opts.TTY it is
+	// interactive with a terminal: the runtime CLI puts the terminal in raw
+	// mode and forwards its resizes, so opts should carry the terminal of
+	// the klausctl process, and the user can detach with DetachKeys, which
+	// leaves the command running. It returns when the command exits or the
+	// user detaches; a non-zero exit is returned as *exec.ExitError.
+	Exec(ctx context.Context, name string, opts ExecOptions) error
 }
 
+// EventActions are the container lifecycle events Events reports.
+var EventActions = []string{"start", "restart", "stop", "kill", "die", "oom", "pause", "unpause"}
+
+// Event is a container lifecycle event.
+type Event struct {
+	// Time is when the event happened.
+	Time time.Time `json:"time"`
+	// Container is the container name.
+	Container string `json:"container"`
+	// Action is one of EventActions.
+	Action string `json:"action"`
+	// ExitCode is the exit code of the container process on "die".
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// Labels klausctl sets on every container it runs, so that its containers
+// can be found even when the instance state is lost.
+const (
+	// LabelManaged marks a container as managed by klausctl.
+	LabelManaged = "klausctl.managed"
+	// LabelInstance holds the name of the instance a container belongs to.
+	LabelInstance = "klausctl.instance"
+	// LabelSpecHash holds the SpecHash of the options a container was run
+	// with, so that a stopped container can be reused only while it still
+	// matches the current config.
+	LabelSpecHash = "klausctl.spec-hash"
+)
+
+// ManagedLabels returns the labels of the container of instance.
+func ManagedLabels(instance string) map[string]string {
+	return map[string]string{
+		LabelManaged:  "true",
+		LabelInstance: instance,
+	}
+}
+
+// SpecHash returns a hash of opts that changes whenever a container run with
+// opts would differ. The LabelSpecHash label itself is ignored.
+func SpecHash(opts RunOptions) string {
+	labels := make(map[string]string, len(opts.Labels))
+	for k, v := range opts.Labels {
+		if k != LabelSpecHash {
+			labels[k] = v
+		}
+	}
+	opts.Labels = labels
+	// RunOptions only holds JSON-encodable values and encoding/json sorts
+	// map keys, so the encoding is deterministic.
+	data, _ := json.Marshal(opts)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+// DefaultStopTimeout makes Stop use the stop timeout of the container.
+const DefaultStopTimeout time.Duration = -1
+
 // RunOptions configures a container run invocation.
 type RunOptions struct {
 	// Name is the container name.
@@ -47,10 +136,17 @@ type RunOptions struct {
 	Image string
 	// Detach runs the container in background.
 	Detach bool
+	// Platform selects the image platform (--platform), e.g. "linux/amd64"
+	// to run amd64 images under emulation. Empty uses the host platform.
+	Platform string
 	// User overrides the container user (e.g. "1000:1000").
 	// This is essential for bind mounts so the container process matches
 	// the host UID that owns the mounted files.
 	User string
+	// UserNS is the user namespace mode (--userns), e.g. "keep-id". When
+	// empty and User is set, rootless podman uses "keep-id" so that User
+	// maps to the host user owning the bind mounts.
+	UserNS string
 	// EnvVars are environment variables to set.
 	EnvVars map[string]string
 	// Volumes are bind mount specifications.
@@ -62,19 +158,69 @@ type RunOptions struct {
 	// from the local host. Set to "0.0.0.0" to expose ports on all
 	// interfaces (matches Docker's historic default).
 	HostIP string
+	// Network is the network mode (--network): "host", "bridge", "none", or
+	// a network name. Empty uses the runtime default.
+	Network string
+	// StopTimeout is how long the container gets to exit after SIGTERM
+	// before it is killed when stopped (--stop-timeout). Zero uses the
+	// runtime default of 10 seconds.
+	StopTimeout time.Duration
+	// RestartPolicy is the container restart policy (--restart), e.g.
+	// "unless-stopped". Empty uses the runtime default of "no".
+	RestartPolicy string
+	// DNS are DNS server addresses (--dns).
+	DNS []string
 	// ExtraHosts adds custom host-to-IP mappings (--add-host).
 	// Each entry is "hostname:ip" (e.g. "host.docker.internal:host-gateway").
 	ExtraHosts []string
+	// Labels are container labels (--label), e.g. ManagedLabels.
+	Labels map[string]string
+	// ReadOnly mounts the container root filesystem read-only (--read-only).
+	ReadOnly bool
+	// Tmpfs are container paths to mount a tmpfs on (--tmpfs), e.g. to keep
+	// /tmp writable with ReadOnly.
+	Tmpfs []string
+	// CapDrop are Linux capabilities to drop (--cap-drop).
+	CapDrop []string
+	// CapAdd are Linux capabilities to add (--cap-add).
+	CapAdd []string
+}
+
+// PullOptions configures an image pull.
+type PullOptions struct {
+	// Platform pulls the image variant of a platform (--platform), e.g.
+	// "linux/amd64". Empty uses the host platform.
+	Platform string
+}
+
+// DetachKeys is the key sequence that detaches from an interactive Exec.
+const DetachKeys = "ctrl-p,ctrl-q"
+
+// ExecOptions configures an interactive command run with Exec.
+type ExecOptions struct {
+	// Command is the command and its arguments.
+	Command []string
+	// TTY allocates a pseudo-terminal for an interactive session, which
+	// requires Stdin to be a terminal. Without it the command runs
+	// non-interactively, with stdin attached only when Stdin is set.
+	TTY bool
+	// Stdin, Stdout and Stderr are connected to the command.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 // Volume represents a bind mount.
 type Volume struct {
 	// HostPath is the path on the host.
-	HostPath string
+	HostPath string `json:"hostPath"`
 	// ContainerPath is the path inside the container.
-	ContainerPath string
+	ContainerPath string `json:"containerPath"`
 	// ReadOnly marks the mount as read-only.
-	ReadOnly bool
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Consistency is the bind mount consistency of Docker Desktop on macOS,
+	// "cached" or "delegated"; empty uses the runtime default.
+	Consistency string `json:"consistency,omitempty"`
 }
 
 // ImageInfo holds information about a locally cached container image.
@@ -98,11 +244,30 @@ type ContainerInfo struct {
 	Image     string    `json:"image"`
 	Status    string    `json:"status"`
 	StartedAt time.Time `json:"startedAt"`
+	// Labels are the container labels.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Mounts are the host paths mounted into the container.
+	Mounts []string `json:"mounts,omitempty"`
 }
 
+// EnvDockerHost is the env var the docker CLI reads its daemon address
+// from.
+const EnvDockerHost = "DOCKER_HOST"
+
 // New creates a runtime for the given name ("docker" or "podman").
 // If name is empty, it auto-detects the available runtime.
 func New(name string) (Runtime, error) {
+	return NewWithHost(name, "")
+}
+
+// NewWithHost creates a runtime like New whose commands run against the
+// daemon at host, e.g. "ssh://user@build-box" or "tcp://10.0.0.5:2376". An
+// empty host leaves the choice to the CLI, which for docker honors
+// DOCKER_HOST.
+func NewWithHost(name, host string) (Runtime, error) {
+	if err := ValidateHost(host); err != nil {
+		return nil, err
+	}
 	if name == "" {
 		detected, err := Detect()
 		if err != nil {
@@ -113,12 +278,55 @@ func New(name string) (Runtime, error) {
 
 	switch name {
 	case "docker", "podman":
-		return &execRuntime{binary: name}, nil
+		return &execRuntime{binary: name, host: host}, nil
 	default:
 		return nil, fmt.Errorf("unsupported runtime %q; use 'docker' or 'podman'", name)
 	}
 }
 
+// ResolveHost returns the daemon address the named runtime uses: host, or
+// DOCKER_HOST when host is empty and the runtime is not podman.
+func ResolveHost(name, host string) string {
+	if host != "" || name == "podman" {
+		return host
+	}
+	return os.Getenv(EnvDockerHost)
+}
+
+// ValidateHost checks that host is empty or a unix://, tcp:// or ssh://
+// daemon address.
+func ValidateHost(host string) error {
+	if host == "" {
+		return nil
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("invalid runtime host %q: %w", host, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		return nil
+	case "tcp", "ssh":
+		if u.Hostname() == "" {
+			return fmt.Errorf("invalid runtime host %q: missing host name", host)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid runtime host %q: must start with unix://, tcp:// or ssh://", host)
+	}
+}
+
+// RemoteHostName returns the host name of a tcp:// or ssh:// runtime host,
+// where published container ports are reached. It returns "" for a local
+// daemon: an empty host or a unix:// socket.
+func RemoteHostName(host string) string {
+	u, err := url.Parse(host)
+	if err != nil || (u.Scheme != "tcp" && u.Scheme != "ssh") {
+		return ""
+	}
+	return u.Hostname()
+}
+
 // inspectResult is the JSON structure returned by docker/podman inspect.
 type inspectResult struct {
 	ID    string `json:"Id"`
@@ -129,4 +337,28 @@ type inspectResult struct {
 		Running   bool      `json:"Running"`
 		StartedAt time.Time `json:"StartedAt"`
 	} `json:"State"`
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	Mounts []struct {
+		Source string `json:"Source"`
+	} `json:"Mounts"`
+}
+
+// containerInfo converts an inspect result.
+func (r inspectResult) containerInfo() ContainerInfo {
+	info := ContainerInfo{
+		ID:        r.ID,
+		Name:      strings.TrimPrefix(r.Name, "/"),
+		Image:     r.Image,
+		Status:    r.State.Status,
+		StartedAt: r.State.StartedAt,
+		Labels:    r.Config.Labels,
+	}
+	for _, m := range r.Mounts {
+		if m.Source != "" {
+			info.Mounts = append(info.Mounts, m.Source)
+		}
+	}
+	return info
 }
@@ -0,0 +1,129 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/giantswarm/klausctl/pkg/config"
+)
+
+// Provider resolves the value of a secret. Besides literal values stored in
+// the secrets file, a secret can point at an external source so that it never
+// has to be written to disk:
+//
+//	anthropic-key: sk-ant-...
+//	github-token: {env: GITHUB_TOKEN}
+//	db-password: {file: ~/.secrets/db-password}
+//	slack-token: {command: ["op", "read", "op://dev/slack/token"]}
+type Provider interface {
+	// Resolve returns the current value of the secret.
+	Resolve() (string, error)
+}
+
+// Literal is a secret value stored in the secrets file itself.
+type Literal string
+
+// Resolve returns the literal value.
+func (l Literal) Resolve() (string, error) {
+	return string(l), nil
+}
+
+// EnvProvider reads a secret from an environment variable of the klausctl
+// process.
+type EnvProvider struct {
+	Var string `yaml:"env"`
+}
+
+// Resolve returns the value of the environment variable. An unset variable
+// is an error; a variable set to the empty string is not.
+func (p EnvProvider) Resolve() (string, error) {
+	v, ok := os.LookupEnv(p.Var)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", p.Var)
+	}
+	return v, nil
+}
+
+// FileProvider reads a secret from a file, e.g. one managed by a secrets
+// agent. A leading ~ in the path is expanded.
+type FileProvider struct {
+	Path string `yaml:"file"`
+}
+
+// Resolve returns the file content without trailing newlines.
+func (p FileProvider) Resolve() (string, error) {
+	data, err := os.ReadFile(config.ExpandPath(p.Path)) // #nosec G304 -- path from the user's own secrets file
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// CommandProvider runs a command, such as the 1Password or Vault CLI, and
+// uses its output as the secret. The command is executed directly, not
+// through a shell.
+type CommandProvider struct {
+	Command []string `yaml:"command,flow"`
+}
+
+// Resolve runs the command and returns its stdout without trailing newlines.
+func (p CommandProvider) Resolve() (string, error) {
+	if len(p.Command) == 0 {
+		return "", fmt.Errorf("empty secret command")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(p.Command[0], p.Command[1:]...) // #nosec G204 -- command from the user's own secrets file
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("running %s: %w: %s", p.Command[0], err, msg)
+		}
+		return "", fmt.Errorf("running %s: %w", p.Command[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+// parseProvider converts a secrets file value into a Provider: a scalar is a
+// Literal, a mapping must have exactly one of the keys env, file or command.
+func parseProvider(node *yaml.Node) (Provider, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return Literal(node.Value), nil
+	case yaml.MappingNode:
+	default:
+		return nil, fmt.Errorf("line %d: expected a string or a provider mapping", node.Line)
+	}
+
+	if len(node.Content) != 2 {
+		return nil, fmt.Errorf("line %d: a provider mapping must have exactly one of env, file or command", node.Line)
+	}
+	key, value := node.Content[0].Value, node.Content[1]
+	switch key {
+	case "env":
+		var p EnvProvider
+		if err := value.Decode(&p.Var); err != nil || p.Var == "" {
+			return nil, fmt.Errorf("line %d: env must be a variable name", value.Line)
+		}
+		return p, nil
+	case "file":
+		var p FileProvider
+		if err := value.Decode(&p.Path); err != nil || p.Path == "" {
+			return nil, fmt.Errorf("line %d: file must be a path", value.Line)
+		}
+		return p, nil
+	case "command":
+		var p CommandProvider
+		if err := value.Decode(&p.Command); err != nil || len(p.Command) == 0 {
+			return nil, fmt.Errorf("line %d: command must be a non-empty list of arguments", value.Line)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("line %d: unknown secret provider %q (expected env, file or command)", node.Line, key)
+	}
+}
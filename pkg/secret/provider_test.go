@@ -0,0 +1,158 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSecretsFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestGetFromProviders(t *testing.T) {
+	t.Setenv("KLAUSCTL_TEST_SECRET", "from-env")
+
+	valueFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(valueFile, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := writeSecretsFile(t, `plain: sk-123
+env-secret: {env: KLAUSCTL_TEST_SECRET}
+file-secret:
+  file: `+valueFile+`
+command-secret: {command: ["echo", "from-command"]}
+`)
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := map[string]string{
+		"plain":          "sk-123",
+		"env-secret":     "from-env",
+		"file-secret":    "from-file",
+		"command-secret": "from-command",
+	}
+	for name, wantVal := range want {
+		got, err := store.Get(name)
+		if err != nil {
+			t.Errorf("Get(%q): %v", name, err)
+			continue
+		}
+		if got != wantVal {
+			t.Errorf("Get(%q) = %q, want %q", name, got, wantVal)
+		}
+	}
+}
+
+func TestProvidersResolveLazily(t *testing.T) {
+	path := writeSecretsFile(t, "token: {env: KLAUSCTL_TEST_LAZY}\n")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	t.Setenv("KLAUSCTL_TEST_LAZY", "set-after-load")
+	got, err := store.Get("token")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got != "set-after-load" {
+		t.Errorf("Get = %q, want the value at Get time", got)
+	}
+}
+
+func TestProviderErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		p       Provider
+		wantErr string
+	}{
+		{name: "unset env", p: EnvProvider{Var: "KLAUSCTL_TEST_UNSET"}, wantErr: "KLAUSCTL_TEST_UNSET is not set"},
+		{name: "missing file", p: FileProvider{Path: filepath.Join(t.TempDir(), "missing")}, wantErr: "reading secret file"},
+		{name: "failing command", p: CommandProvider{Command: []string{"sh", "-c", "echo not signed in >&2; exit 1"}}, wantErr: "not signed in"},
+		{name: "empty command", p: CommandProvider{}, wantErr: "empty secret command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.p.Resolve()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetWrapsProviderError(t *testing.T) {
+	store, err := Load(writeSecretsFile(t, "token: {env: KLAUSCTL_TEST_UNSET}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.Get("token")
+	if err == nil || !strings.Contains(err.Error(), `resolving secret "token"`) {
+		t.Errorf("Get error = %v, want it to name the secret", err)
+	}
+}
+
+func TestLoadInvalidProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "unknown provider", content: "token: {vault: secret/x}\n", wantErr: `unknown secret provider "vault"`},
+		{name: "two providers", content: "token: {env: A, file: /b}\n", wantErr: "exactly one"},
+		{name: "empty command", content: "token: {command: []}\n", wantErr: "non-empty list"},
+		{name: "list value", content: "token: [a, b]\n", wantErr: "expected a string or a provider mapping"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeSecretsFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveKeepsProviders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("plain", "sk-123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetProvider("op", CommandProvider{Command: []string{"op", "read", "op://dev/item/field"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetProvider("gh", EnvProvider{Var: "GITHUB_TOKEN"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after Save: %v", err)
+	}
+	if got, ok := reloaded.secrets["op"].(CommandProvider); !ok || len(got.Command) != 3 || got.Command[2] != "op://dev/item/field" {
+		t.Errorf("op provider = %#v", reloaded.secrets["op"])
+	}
+	if got := reloaded.secrets["gh"]; got != (EnvProvider{Var: "GITHUB_TOKEN"}) {
+		t.Errorf("gh provider = %#v", got)
+	}
+	if got, _ := reloaded.Get("plain"); got != "sk-123" {
+		t.Errorf("plain = %q, want sk-123", got)
+	}
+}
@@ -0,0 +1,120 @@
+package secret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// registryPrefix namespaces registry credentials among the other secrets.
+// A registry's credential is stored as registry.<host>, with the ':' of a
+// port replaced by '_' since secret names cannot contain colons.
+const registryPrefix = "registry."
+
+// RegistryCredential describes stored auth for a container registry. The
+// password is never exposed.
+type RegistryCredential struct {
+	Registry string `json:"registry"`
+	Username string `json:"username"`
+}
+
+// registrySecretName returns the secret name holding the credential of the
+// registry host.
+func registrySecretName(registry string) (string, error) {
+	if registry == "" || strings.ContainsAny(registry, "/_") {
+		return "", fmt.Errorf("invalid registry %q: expected a host name such as ghcr.io or localhost:5000", registry)
+	}
+	host := strings.ReplaceAll(registry, ":", "_")
+	if err := ValidateName(host); err != nil {
+		return "", fmt.Errorf("invalid registry %q: expected a host name such as ghcr.io or localhost:5000", registry)
+	}
+	return registryPrefix + host, nil
+}
+
+// SetRegistryAuth stores the username and password for a registry host. The
+// value has the format of the auth field of a Docker config file.
+func (s *Store) SetRegistryAuth(registry, username, password string) error {
+	if username == "" || password == "" {
+		return fmt.Errorf("username and password are required")
+	}
+	name, err := registrySecretName(registry)
+	if err != nil {
+		return err
+	}
+	return s.Set(name, base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+}
+
+// DeleteRegistryAuth removes the stored credential of a registry host.
+func (s *Store) DeleteRegistryAuth(registry string) error {
+	name, err := registrySecretName(registry)
+	if err != nil {
+		return err
+	}
+	if _, ok := s.secrets[name]; !ok {
+		return fmt.Errorf("no credentials stored for registry %q", registry)
+	}
+	delete(s.secrets, name)
+	return nil
+}
+
+// RegistryCredentials returns the registries with stored auth, sorted by
+// host.
+func (s *Store) RegistryCredentials() ([]RegistryCredential, error) {
+	auths, err := s.registryAuths()
+	if err != nil {
+		return nil, err
+	}
+	creds := make([]RegistryCredential, 0, len(auths))
+	for registry, auth := range auths {
+		username, _, _ := strings.Cut(auth, ":")
+		creds = append(creds, RegistryCredential{Registry: registry, Username: username})
+	}
+	sort.Slice(creds, func(i, j int) bool {
+		return creds[i].Registry < creds[j].Registry
+	})
+	return creds, nil
+}
+
+// DockerConfigJSON returns the stored registry credentials as a Docker config
+// file ({"auths": {...}}), or nil when there are none.
+func (s *Store) DockerConfigJSON() ([]byte, error) {
+	auths, err := s.registryAuths()
+	if err != nil || len(auths) == 0 {
+		return nil, err
+	}
+	type authEntry struct {
+		Auth string `json:"auth"`
+	}
+	cfg := struct {
+		Auths map[string]authEntry `json:"auths"`
+	}{Auths: make(map[string]authEntry, len(auths))}
+	for registry, auth := range auths {
+		cfg.Auths[registry] = authEntry{Auth: base64.StdEncoding.EncodeToString([]byte(auth))}
+	}
+	return json.Marshal(cfg)
+}
+
+// registryAuths resolves the stored registry credentials to a map of host to
+// "username:password".
+func (s *Store) registryAuths() (map[string]string, error) {
+	auths := make(map[string]string)
+	for name := range s.secrets {
+		host, ok := strings.CutPrefix(name, registryPrefix)
+		if !ok {
+			continue
+		}
+		registry := strings.ReplaceAll(host, "_", ":")
+		value, err := s.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || !strings.Contains(string(decoded), ":") {
+			return nil, fmt.Errorf("secret %q: expected base64-encoded username:password", name)
+		}
+		auths[registry] = string(decoded)
+	}
+	return auths, nil
+}
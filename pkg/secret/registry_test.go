@@ -0,0 +1,115 @@
+package secret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRegistryAuthRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.yaml")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("api-key", "sk-123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetRegistryAuth("ghcr.io", "octocat", "tok"); err != nil {
+		t.Fatalf("SetRegistryAuth: %v", err)
+	}
+	if err := store.SetRegistryAuth("localhost:5000", "admin", "pw"); err != nil {
+		t.Fatalf("SetRegistryAuth: %v", err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := store.RegistryCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RegistryCredential{
+		{Registry: "ghcr.io", Username: "octocat"},
+		{Registry: "localhost:5000", Username: "admin"},
+	}
+	if len(creds) != len(want) || creds[0] != want[0] || creds[1] != want[1] {
+		t.Errorf("RegistryCredentials = %v, want %v", creds, want)
+	}
+
+	if err := store.DeleteRegistryAuth("ghcr.io"); err != nil {
+		t.Fatalf("DeleteRegistryAuth: %v", err)
+	}
+	creds, err = store.RegistryCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(creds) != 1 || creds[0] != want[1] {
+		t.Errorf("RegistryCredentials after delete = %v", creds)
+	}
+	if err := store.DeleteRegistryAuth("ghcr.io"); err == nil {
+		t.Error("expected error deleting missing credentials")
+	}
+	if _, err := store.Get("api-key"); err != nil {
+		t.Errorf("unrelated secret lost: %v", err)
+	}
+}
+
+func TestRegistryCredentialsOmitPassword(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "secrets.yaml"))
+	if err := store.SetRegistryAuth("ghcr.io", "octocat", "hunter2"); err != nil {
+		t.Fatal(err)
+	}
+	creds, err := store.RegistryCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(creds)
+	if strings.Contains(string(data), "hunter2") {
+		t.Errorf("credentials expose the password: %s", data)
+	}
+}
+
+func TestSetRegistryAuthInvalid(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "secrets.yaml"))
+	for _, registry := range []string{"", "ghcr.io/org", "my_registry", "-bad"} {
+		if err := store.SetRegistryAuth(registry, "u", "p"); err == nil {
+			t.Errorf("SetRegistryAuth(%q) succeeded, want error", registry)
+		}
+	}
+	if err := store.SetRegistryAuth("ghcr.io", "", "p"); err == nil {
+		t.Error("expected error for empty username")
+	}
+}
+
+func TestDockerConfigJSON(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "secrets.yaml"))
+	if data, err := store.DockerConfigJSON(); err != nil || data != nil {
+		t.Errorf("empty store: DockerConfigJSON = %s, %v", data, err)
+	}
+	if err := store.SetRegistryAuth("localhost:5000", "admin", "pw"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := store.DockerConfigJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	want := base64.StdEncoding.EncodeToString([]byte("admin:pw"))
+	if got := cfg.Auths["localhost:5000"].Auth; got != want {
+		t.Errorf("auth = %q, want %q", got, want)
+	}
+}
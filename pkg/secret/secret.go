@@ -1,6 +1,7 @@
 // Package secret provides a file-permission-protected secret store for klausctl.
 // Secrets are stored as a flat YAML map in ~/.config/klausctl/secrets.yaml with
-// owner-only (0600) file permissions.
+// owner-only (0600) file permissions. A value is either the secret itself or a
+// reference to an external provider (see Provider).
 package secret
 
 import (
@@ -20,7 +21,7 @@ var validNameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
 // file permissions.
 type Store struct {
 	path    string
-	secrets map[string]string
+	secrets map[string]Provider
 }
 
 // Load reads secrets from the given file path. If the file does not exist,
@@ -29,7 +30,7 @@ type Store struct {
 func Load(path string) (*Store, error) {
 	s := &Store{
 		path:    path,
-		secrets: make(map[string]string),
+		secrets: make(map[string]Provider),
 	}
 
 	f, err := os.Open(path) // #nosec G304 -- user-supplied or trusted local path; not exposed to untrusted input
@@ -54,11 +55,16 @@ func Load(path string) (*Store, error) {
 		return nil, fmt.Errorf("reading secrets file: %w", err)
 	}
 
-	if err := yaml.Unmarshal(data, &s.secrets); err != nil {
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &nodes); err != nil {
 		return nil, fmt.Errorf("parsing secrets file: %w", err)
 	}
-	if s.secrets == nil {
-		s.secrets = make(map[string]string)
+	for name, node := range nodes {
+		p, err := parseProvider(&node)
+		if err != nil {
+			return nil, fmt.Errorf("parsing secret %q: %w", name, err)
+		}
+		s.secrets[name] = p
 	}
 
 	return s, nil
@@ -85,22 +91,40 @@ func ValidateName(name string) error {
 
 // Set stores or updates a named secret. Returns an error if the name is invalid.
 func (s *Store) Set(name, value string) error {
+	return s.SetProvider(name, Literal(value))
+}
+
+// SetProvider stores or updates a named secret that is resolved by p.
+// Returns an error if the name is invalid.
+func (s *Store) SetProvider(name string, p Provider) error {
 	if err := ValidateName(name); err != nil {
 		return err
 	}
-	s.secrets[name] = value
+	s.secrets[name] = p
 	return nil
 }
 
-// Get retrieves a secret by name. Returns an error when the name is not found.
+// Get retrieves a secret by name, resolving it through its provider.
+// Returns an error when the name is not found or the provider fails.
 func (s *Store) Get(name string) (string, error) {
-	v, ok := s.secrets[name]
+	p, ok := s.secrets[name]
 	if !ok {
 		return "", fmt.Errorf("secret %q not found", name)
 	}
+	v, err := p.Resolve()
+	if err != nil {
+		return "", fmt.Errorf("resolving secret %q: %w", name, err)
+	}
 	return v, nil
 }
 
+// Has reports whether a secret of the given name is stored, without
+// resolving it.
+func (s *Store) Has(name string) bool {
+	_, ok := s.secrets[name]
+	return ok
+}
+
 // Delete removes a named secret. Returns an error when the name is not found.
 func (s *Store) Delete(name string) error {
 	if _, ok := s.secrets[name]; !ok {
@@ -54,6 +54,19 @@ func TestSetGetDelete(t *testing.T) {
 	}
 }
 
+func TestHas(t *testing.T) {
+	store, _ := Load(filepath.Join(t.TempDir(), "secrets.yaml"))
+	if err := store.SetProvider("from-env", EnvProvider{Var: "KLAUSCTL_TEST_UNSET_VAR"}); err != nil {
+		t.Fatal(err)
+	}
+	if !store.Has("from-env") {
+		t.Error("expected Has to report a stored secret, even one that does not resolve")
+	}
+	if store.Has("nonexistent") {
+		t.Error("expected Has to be false for a missing key")
+	}
+}
+
 func TestGetNotFound(t *testing.T) {
 	store, _ := Load(filepath.Join(t.TempDir(), "secrets.yaml"))
 	_, err := store.Get("nonexistent")
@@ -0,0 +1,118 @@
+// Package signing signs pushed OCI artifacts and verifies their signatures
+// before they are pulled.
+//
+// The backend is swappable through the Signer and Verifier interfaces.
+// Cosign implements both with the cosign CLI, which stores signatures in
+// the registry next to the artifact, the same way pkg/runtime drives the
+// docker and podman CLIs.
+package signing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Signer signs the manifest digest of an artifact in repository and
+// attaches the signature to the registry.
+type Signer interface {
+	Sign(ctx context.Context, repository, digest string) error
+}
+
+// Verifier checks that the manifest digest of an artifact in repository
+// carries a valid signature.
+type Verifier interface {
+	Verify(ctx context.Context, repository, digest string) error
+}
+
+// Cosign signs and verifies artifacts with the cosign CLI.
+type Cosign struct {
+	// Key is the private key (file path or KMS URI) to sign with, or the
+	// public key to verify against. Without a key, signing is keyless via
+	// the Sigstore OIDC flow and verification requires Identity and Issuer.
+	Key string
+	// Identity is the certificate identity, such as an email address or a
+	// workflow URL, that a keyless signature must have been issued to.
+	Identity string
+	// Issuer is the OIDC issuer that a keyless signature's certificate
+	// must come from, e.g. https://token.actions.githubusercontent.com.
+	Issuer string
+	// Binary is the cosign executable; empty uses "cosign" from PATH.
+	Binary string
+	// Output receives cosign's progress and prompts, such as the login URL
+	// of the keyless flow. When nil, they are only included in errors.
+	Output io.Writer
+}
+
+var (
+	_ Signer   = Cosign{}
+	_ Verifier = Cosign{}
+)
+
+// Sign signs repository@digest and pushes the signature to the registry.
+func (c Cosign) Sign(ctx context.Context, repository, digest string) error {
+	return c.run(ctx, signArgs(repository+"@"+digest, c.Key))
+}
+
+// Verify checks the signatures of repository@digest. Without a Key, only a
+// keyless signature issued to Identity by Issuer is accepted; verification
+// fails when either is unset.
+func (c Cosign) Verify(ctx context.Context, repository, digest string) error {
+	args, err := c.verifyArgs(repository + "@" + digest)
+	if err != nil {
+		return err
+	}
+	return c.run(ctx, args)
+}
+
+func signArgs(ref, key string) []string {
+	args := []string{"sign", "--yes"}
+	if key != "" {
+		args = append(args, "--key", key)
+	}
+	return append(args, ref)
+}
+
+func (c Cosign) verifyArgs(ref string) ([]string, error) {
+	args := []string{"verify"}
+	switch {
+	case c.Key != "":
+		args = append(args, "--key", c.Key)
+	case c.Identity != "" && c.Issuer != "":
+		args = append(args, "--certificate-identity", c.Identity, "--certificate-oidc-issuer", c.Issuer)
+	default:
+		return nil, fmt.Errorf("keyless signature verification of %s requires a certificate identity and OIDC issuer; set both or verify with a public key", ref)
+	}
+	return append(args, ref), nil
+}
+
+func (c Cosign) run(ctx context.Context, args []string) error {
+	bin := c.Binary
+	if bin == "" {
+		bin = "cosign"
+	}
+	path, err := exec.LookPath(bin)
+	if err != nil {
+		return fmt.Errorf("cosign is required to sign and verify artifacts: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...) // #nosec G204 -- args are built from validated references and flags
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = &stderr
+	if c.Output != nil {
+		cmd.Stderr = io.MultiWriter(c.Output, &stderr)
+	}
+	if err := cmd.Run(); err != nil {
+		ref := args[len(args)-1]
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("cosign %s %s: %w: %s", args[0], ref, err, msg)
+		}
+		return fmt.Errorf("cosign %s %s: %w", args[0], ref, err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package signing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeCosign writes a cosign stand-in that records its arguments in a file
+// and exits with code.
+func fakeCosign(t *testing.T, code string) (bin, argsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile = filepath.Join(dir, "args")
+	bin = filepath.Join(dir, "cosign")
+	script := "#!/bin/sh\necho \"$@\" > " + argsFile + "\necho 'no matching signatures' >&2\nexit " + code + "\n"
+	if err := os.WriteFile(bin, []byte(script), 0o700); err != nil { // #nosec G306 -- test executable
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func TestCosignSignAndVerifyArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(Cosign) error
+		c    Cosign
+		want string
+	}{
+		{"keyless sign", func(c Cosign) error { return c.Sign(context.Background(), "example.com/p", "sha256:abc") }, Cosign{},
+			"sign --yes example.com/p@sha256:abc"},
+		{"key sign", func(c Cosign) error { return c.Sign(context.Background(), "example.com/p", "sha256:abc") }, Cosign{Key: "cosign.key"},
+			"sign --yes --key cosign.key example.com/p@sha256:abc"},
+		{"key verify", func(c Cosign) error { return c.Verify(context.Background(), "example.com/p", "sha256:abc") }, Cosign{Key: "cosign.pub"},
+			"verify --key cosign.pub example.com/p@sha256:abc"},
+		{"keyless verify", func(c Cosign) error { return c.Verify(context.Background(), "example.com/p", "sha256:abc") },
+			Cosign{Identity: "dev@example.com", Issuer: "https://accounts.example.com"},
+			"verify --certificate-identity dev@example.com --certificate-oidc-issuer https://accounts.example.com example.com/p@sha256:abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bin, argsFile := fakeCosign(t, "0")
+			tt.c.Binary = bin
+			if err := tt.run(tt.c); err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			got, err := os.ReadFile(argsFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.TrimSpace(string(got)) != tt.want {
+				t.Errorf("args = %q, want %q", strings.TrimSpace(string(got)), tt.want)
+			}
+		})
+	}
+}
+
+func TestCosignVerifyFailureIncludesOutput(t *testing.T) {
+	bin, _ := fakeCosign(t, "1")
+	err := Cosign{Key: "cosign.pub", Binary: bin}.Verify(context.Background(), "example.com/p", "sha256:abc")
+	if err == nil || !strings.Contains(err.Error(), "no matching signatures") {
+		t.Errorf("Verify() error = %v, want cosign's message", err)
+	}
+}
+
+func TestCosignKeylessVerifyRequiresIdentity(t *testing.T) {
+	for _, c := range []Cosign{{}, {Identity: "dev@example.com"}, {Issuer: "https://accounts.example.com"}} {
+		bin, argsFile := fakeCosign(t, "0")
+		c.Binary = bin
+		err := c.Verify(context.Background(), "example.com/p", "sha256:abc")
+		if err == nil || !strings.Contains(err.Error(), "requires a certificate identity and OIDC issuer") {
+			t.Errorf("Verify(%+v) error = %v, want missing identity", c, err)
+		}
+		if _, err := os.Stat(argsFile); !os.IsNotExist(err) {
+			t.Errorf("Verify(%+v) ran cosign", c)
+		}
+	}
+}
+
+func TestCosignMissingBinary(t *testing.T) {
+	err := Cosign{Binary: filepath.Join(t.TempDir(), "missing")}.Sign(context.Background(), "example.com/p", "sha256:abc")
+	if err == nil || !strings.Contains(err.Error(), "cosign is required") {
+		t.Errorf("Sign() error = %v, want missing cosign", err)
+	}
+}
@@ -0,0 +1,155 @@
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/giantswarm/klausctl/pkg/filelock"
+	"github.com/giantswarm/klausctl/pkg/mcpclient"
+)
+
+// Run is the usage of one completed agent run, one line of an instance's
+// usage history.
+type Run struct {
+	Time         time.Time `json:"time"`
+	SessionID    string    `json:"session_id,omitempty"`
+	Status       string    `json:"status"`
+	MessageCount int       `json:"message_count"`
+	// CostUSD is nil when the agent reported no cost.
+	CostUSD *float64 `json:"cost_usd,omitempty"`
+	Tokens
+}
+
+// sameRun reports whether r and o record the same agent run, which is the
+// case when its result was fetched twice.
+func (r *Run) sameRun(o *Run) bool {
+	return r.SessionID == o.SessionID && r.MessageCount == o.MessageCount && r.Status == o.Status
+}
+
+// fullResult is the subset of the agent's full result that carries usage.
+type fullResult struct {
+	Status       string   `json:"status"`
+	SessionID    string   `json:"session_id"`
+	MessageCount int      `json:"message_count"`
+	TotalCostUSD *float64 `json:"total_cost_usd"`
+	TokenUsage   *Tokens  `json:"token_usage"`
+}
+
+// RunFromResult builds a Run from the JSON of the agent's full result.
+// The returned bool is false when the agent has not completed a run, i.e.
+// its status is not terminal.
+func RunFromResult(resultJSON string, now time.Time) (*Run, bool, error) {
+	var res fullResult
+	if err := json.Unmarshal([]byte(resultJSON), &res); err != nil {
+		return nil, false, fmt.Errorf("parsing agent result: %w", err)
+	}
+	if !mcpclient.IsTerminalStatus(res.Status) {
+		return nil, false, nil
+	}
+	run := &Run{
+		Time:         now,
+		SessionID:    res.SessionID,
+		Status:       res.Status,
+		MessageCount: res.MessageCount,
+		CostUSD:      res.TotalCostUSD,
+	}
+	if res.TokenUsage != nil {
+		run.Tokens = *res.TokenUsage
+	}
+	return run, true, nil
+}
+
+// AppendRun appends run to the usage history at path, unless it is the
+// last recorded run again. It reports whether run was appended.
+func AppendRun(path string, run *Run) (bool, error) {
+	lock, err := filelock.Acquire(filelock.LockPath(path))
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	history, err := LoadHistory(path)
+	if err != nil {
+		return false, err
+	}
+	if n := len(history.Runs); n > 0 && history.Runs[n-1].sameRun(run) {
+		return false, nil
+	}
+
+	data, err := json.Marshal(run)
+	if err != nil {
+		return false, fmt.Errorf("marshaling usage: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600) // #nosec G304 -- usage history in the instance directory
+	if err != nil {
+		return false, fmt.Errorf("opening usage history: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return false, fmt.Errorf("writing usage history: %w", err)
+	}
+	return true, f.Close()
+}
+
+// History is the recorded usage of an instance and its totals.
+type History struct {
+	Runs []Run `json:"runs"`
+	Tokens
+	// CostUSD is the sum of the cost reported by the runs.
+	CostUSD float64 `json:"cost_usd"`
+}
+
+// LoadHistory reads the usage history at path. A missing file is an empty
+// history; lines that cannot be parsed are skipped.
+func LoadHistory(path string) (*History, error) {
+	h := &History{Runs: []Run{}}
+	f, err := os.Open(path) // #nosec G304 -- usage history in the instance directory
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("reading usage history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var run Run
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			continue
+		}
+		h.Runs = append(h.Runs, run)
+		h.Tokens.add(run.Tokens)
+		if run.CostUSD != nil {
+			h.CostUSD += *run.CostUSD
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading usage history: %w", err)
+	}
+	return h, nil
+}
+
+// Record fetches the full result of the agent at baseURL and appends its
+// last run to the usage history at path. Nothing is recorded while the
+// agent has not completed a run.
+func Record(ctx context.Context, client *mcpclient.Client, instanceName, baseURL, path string) error {
+	toolResult, err := client.Result(ctx, instanceName, baseURL, true)
+	if err != nil {
+		return fmt.Errorf("fetching result: %w", err)
+	}
+	if toolResult.IsError {
+		return fmt.Errorf("fetching result: %s", mcpclient.ExtractText(toolResult))
+	}
+	run, ok, err := RunFromResult(mcpclient.ExtractText(toolResult), time.Now())
+	if err != nil || !ok {
+		return err
+	}
+	_, err = AppendRun(path, run)
+	return err
+}
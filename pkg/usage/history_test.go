@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const fullResultJSON = `{"status":"completed","session_id":"abc","message_count":4,"result_text":"done",
+"total_cost_usd":0.25,"token_usage":{"input_tokens":150,"output_tokens":30,"cache_read_input_tokens":5}}`
+
+func TestRunFromResult(t *testing.T) {
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	run, ok, err := RunFromResult(fullResultJSON, now)
+	if err != nil || !ok {
+		t.Fatalf("RunFromResult() = %v, %v", ok, err)
+	}
+	if run.SessionID != "abc" || run.MessageCount != 4 || !run.Time.Equal(now) {
+		t.Errorf("run = %+v", run)
+	}
+	if run.InputTokens != 150 || run.OutputTokens != 30 || run.CacheReadInputTokens != 5 {
+		t.Errorf("tokens = %+v", run.Tokens)
+	}
+	if run.CostUSD == nil || *run.CostUSD != 0.25 {
+		t.Errorf("cost = %v, want 0.25", run.CostUSD)
+	}
+
+	if _, ok, err := RunFromResult(`{"status":"busy","message_count":2}`, now); ok || err != nil {
+		t.Errorf("busy agent: ok = %v, err = %v, want no run", ok, err)
+	}
+	if _, _, err := RunFromResult("not json", now); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestAppendRunAndLoadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+
+	h, err := LoadHistory(path)
+	if err != nil || len(h.Runs) != 0 {
+		t.Fatalf("LoadHistory(missing) = %+v, %v", h, err)
+	}
+
+	first, _, err := RunFromResult(fullResultJSON, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := RunFromResult(`{"status":"error","session_id":"abc","message_count":6,"token_usage":{"input_tokens":10,"output_tokens":1}}`, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second append of the same run, e.g. from wait and then stop, is
+	// skipped.
+	for i, run := range []*Run{first, first, second} {
+		appended, err := AppendRun(path, run)
+		if err != nil {
+			t.Fatalf("AppendRun() #%d error = %v", i, err)
+		}
+		if want := i != 1; appended != want {
+			t.Errorf("AppendRun() #%d = %v, want %v", i, appended, want)
+		}
+	}
+
+	h, err = LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(h.Runs))
+	}
+	if h.InputTokens != 160 || h.OutputTokens != 31 {
+		t.Errorf("total tokens = %d/%d, want 160/31", h.InputTokens, h.OutputTokens)
+	}
+	if math.Abs(h.CostUSD-0.25) > 1e-9 {
+		t.Errorf("total cost = %v, want 0.25", h.CostUSD)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("history mode = %o, want 600", perm)
+	}
+}
@@ -0,0 +1,136 @@
+// Package usage sums token usage and spend reported in klaus container logs.
+//
+// The klaus agent emits Claude Code stream-json events in its logs. Two event
+// shapes carry usage information:
+//
+//   - assistant events: {"type":"assistant","message":{"model":...,"usage":{...}}}
+//     report the tokens of a single model response.
+//   - result events: {"type":"result","total_cost_usd":...,"usage":{...}}
+//     close a run and report its cumulative usage and cost.
+//
+// A result event supersedes the assistant events of the same run, so usage is
+// not counted twice. Assistant events of a run that has not finished yet are
+// counted on their own.
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLineSize bounds a single log line. Result events embed the final agent
+// message and can be large.
+const maxLineSize = 4 * 1024 * 1024
+
+// Tokens holds token counts as reported by the Anthropic API.
+type Tokens struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+func (t *Tokens) add(o Tokens) {
+	t.InputTokens += o.InputTokens
+	t.OutputTokens += o.OutputTokens
+	t.CacheCreationInputTokens += o.CacheCreationInputTokens
+	t.CacheReadInputTokens += o.CacheReadInputTokens
+}
+
+// Pricing is the price in USD per million tokens used to estimate the cost of
+// usage for which the logs report no cost.
+type Pricing struct {
+	InputPerMTok  float64
+	OutputPerMTok float64
+}
+
+// DefaultPricing matches the list price of the Claude Sonnet models.
+var DefaultPricing = Pricing{InputPerMTok: 3, OutputPerMTok: 15}
+
+// Summary is the total usage found in a log stream.
+type Summary struct {
+	Tokens
+	// Runs is the number of completed runs (result events).
+	Runs int `json:"runs"`
+	// Responses is the number of model responses (assistant events).
+	Responses int `json:"responses"`
+	// ReportedCostUSD is the sum of the cost reported by result events.
+	ReportedCostUSD float64 `json:"reported_cost_usd"`
+	// Unpriced holds the tokens of runs without a reported cost, i.e. runs
+	// that are still in progress.
+	Unpriced Tokens `json:"unpriced"`
+}
+
+// EstimatedCostUSD returns the reported cost plus an estimate for the
+// unpriced tokens based on p. Cache tokens are priced as input tokens.
+func (s *Summary) EstimatedCostUSD(p Pricing) float64 {
+	input := s.Unpriced.InputTokens + s.Unpriced.CacheCreationInputTokens + s.Unpriced.CacheReadInputTokens
+	return s.ReportedCostUSD +
+		float64(input)*p.InputPerMTok/1e6 +
+		float64(s.Unpriced.OutputTokens)*p.OutputPerMTok/1e6
+}
+
+// event is the subset of a stream-json event that carries usage.
+type event struct {
+	Type         string   `json:"type"`
+	Usage        *Tokens  `json:"usage"`
+	TotalCostUSD *float64 `json:"total_cost_usd"`
+	Message      *struct {
+		Usage *Tokens `json:"usage"`
+	} `json:"message"`
+}
+
+// Parse reads container log output and sums the usage events it contains.
+// Lines that are not JSON, or JSON without usage, are ignored. A prefix
+// before the JSON object (e.g. a timestamp added by the runtime) is skipped.
+func Parse(r io.Reader) (*Summary, error) {
+	s := &Summary{}
+	var pending Tokens
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.IndexByte(line, '{')
+		if i < 0 {
+			continue
+		}
+
+		var ev event
+		if err := json.Unmarshal([]byte(line[i:]), &ev); err != nil {
+			continue
+		}
+
+		switch ev.Type {
+		case "assistant":
+			if ev.Message == nil || ev.Message.Usage == nil {
+				continue
+			}
+			s.Responses++
+			pending.add(*ev.Message.Usage)
+		case "result":
+			s.Runs++
+			run := pending
+			if ev.Usage != nil {
+				run = *ev.Usage
+			}
+			s.Tokens.add(run)
+			if ev.TotalCostUSD != nil {
+				s.ReportedCostUSD += *ev.TotalCostUSD
+			} else {
+				s.Unpriced.add(run)
+			}
+			pending = Tokens{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading logs: %w", err)
+	}
+
+	s.Tokens.add(pending)
+	s.Unpriced.add(pending)
+	return s, nil
+}
@@ -0,0 +1,81 @@
+package usage
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const sampleLogs = `time=2026-01-01T10:00:00Z level=INFO msg="starting klaus"
+{"type":"system","subtype":"init","session_id":"abc"}
+{"type":"assistant","message":{"model":"claude-sonnet-4","usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":5}}}
+{"type":"assistant","message":{"model":"claude-sonnet-4","usage":{"input_tokens":50,"output_tokens":10}}}
+{"type":"result","subtype":"success","total_cost_usd":0.25,"usage":{"input_tokens":150,"output_tokens":30,"cache_read_input_tokens":5}}
+not json at all
+2026-01-01T10:05:00.000000000Z {"type":"assistant","message":{"usage":{"input_tokens":200,"output_tokens":40}}}
+{"type":"result","subtype":"success","total_cost_usd":0.5,"usage":{"input_tokens":210,"output_tokens":45}}
+{"type":"assistant","message":{"usage":{"input_tokens":1000000,"output_tokens":100000}}}
+`
+
+func TestParseSumsUsageEvents(t *testing.T) {
+	s, err := Parse(strings.NewReader(sampleLogs))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if s.Runs != 2 {
+		t.Errorf("Runs = %d, want 2", s.Runs)
+	}
+	if s.Responses != 4 {
+		t.Errorf("Responses = %d, want 4", s.Responses)
+	}
+	// Two result events supersede their assistant events; the trailing
+	// assistant event belongs to a run that has not finished.
+	if s.InputTokens != 150+210+1000000 {
+		t.Errorf("InputTokens = %d", s.InputTokens)
+	}
+	if s.OutputTokens != 30+45+100000 {
+		t.Errorf("OutputTokens = %d", s.OutputTokens)
+	}
+	if s.CacheReadInputTokens != 5 {
+		t.Errorf("CacheReadInputTokens = %d, want 5", s.CacheReadInputTokens)
+	}
+	if math.Abs(s.ReportedCostUSD-0.75) > 1e-9 {
+		t.Errorf("ReportedCostUSD = %f, want 0.75", s.ReportedCostUSD)
+	}
+	if s.Unpriced.InputTokens != 1000000 || s.Unpriced.OutputTokens != 100000 {
+		t.Errorf("Unpriced = %+v", s.Unpriced)
+	}
+
+	// 0.75 reported + 1M input * $3/MTok + 100k output * $15/MTok.
+	if got := s.EstimatedCostUSD(DefaultPricing); math.Abs(got-5.25) > 1e-9 {
+		t.Errorf("EstimatedCostUSD() = %f, want 5.25", got)
+	}
+}
+
+func TestParseResultWithoutUsageFallsBackToAssistantEvents(t *testing.T) {
+	logs := `{"type":"assistant","message":{"usage":{"input_tokens":10,"output_tokens":1}}}
+{"type":"assistant","message":{"usage":{"input_tokens":20,"output_tokens":2}}}
+{"type":"result","subtype":"error_max_turns"}
+`
+	s, err := Parse(strings.NewReader(logs))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if s.InputTokens != 30 || s.OutputTokens != 3 {
+		t.Errorf("Tokens = %+v, want 30 input / 3 output", s.Tokens)
+	}
+	if s.Unpriced.InputTokens != 30 {
+		t.Errorf("expected run without reported cost to be unpriced, got %+v", s.Unpriced)
+	}
+}
+
+func TestParseNoUsage(t *testing.T) {
+	s, err := Parse(strings.NewReader("hello\n{\"type\":\"system\"}\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if s.Runs != 0 || s.Responses != 0 || s.InputTokens != 0 || s.EstimatedCostUSD(DefaultPricing) != 0 {
+		t.Errorf("expected empty summary, got %+v", s)
+	}
+}